@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+)
+
+// client calls the /api/v1 endpoints loadgen needs.
+type client struct {
+	baseURL string
+	apiKey  string
+	http    *http.Client
+}
+
+func newClient(baseURL, apiKey string) *client {
+	return &client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		http: &http.Client{
+			Timeout: 30 * time.Second,
+			// The default of 2 idle connections per host would make most
+			// concurrent publishes open a new connection
+			Transport: &http.Transport{MaxIdleConnsPerHost: 256},
+		},
+	}
+}
+
+// do sends a JSON request and decodes the response into out, if not nil.
+func (c *client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("encoding request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+"/api/v1"+path, reader)
+	if err != nil {
+		return fmt.Errorf("building request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("%s %s: reading response: %w", method, path, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var e struct {
+			Error string `json:"error"`
+		}
+		msg := strings.TrimSpace(string(data))
+		if json.Unmarshal(data, &e) == nil && e.Error != "" {
+			msg = e.Error
+		}
+		return fmt.Errorf("%s %s: server returned %d: %s", method, path, resp.StatusCode, msg)
+	}
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("%s %s: decoding response: %w", method, path, err)
+		}
+	}
+	return nil
+}
+
+// publishResult is the server's response to POST /events.
+type publishResult struct {
+	EventID          string `json:"event_id"`
+	DeliveriesQueued int    `json:"deliveries_queued"`
+	FanOutPending    bool   `json:"fanout_pending"`
+}
+
+func (c *client) publishEvent(ctx context.Context, eventType string, payload json.RawMessage) (publishResult, error) {
+	var res publishResult
+	err := c.do(ctx, http.MethodPost, "/events", map[string]interface{}{
+		"event_type": eventType,
+		"payload":    payload,
+		"source":     "loadgen",
+	}, &res)
+	return res, err
+}
+
+func (c *client) getEvent(ctx context.Context, id string) (domain.Event, error) {
+	var ev domain.Event
+	err := c.do(ctx, http.MethodGet, "/events/"+url.PathEscape(id), nil, &ev)
+	return ev, err
+}
+
+func (c *client) listDeliveries(ctx context.Context, eventID string) ([]domain.DeliveryAttempt, error) {
+	var attempts []domain.DeliveryAttempt
+	q := url.Values{"event_id": {eventID}, "limit": {"1000"}}
+	err := c.do(ctx, http.MethodGet, "/deliveries?"+q.Encode(), nil, &attempts)
+	return attempts, err
+}
+
+// createSubscribers registers cfg.subscribers temporary subscribers for the
+// mix's event types. It returns the IDs created so far even on error, so the
+// caller can clean them up.
+func createSubscribers(ctx context.Context, c *client, cfg config) ([]string, error) {
+	types := make([]string, len(cfg.mix))
+	for i, w := range cfg.mix {
+		types[i] = w.eventType
+	}
+	runID := time.Now().Format("20060102-150405")
+
+	var ids []string
+	for i := 0; i < cfg.subscribers; i++ {
+		var sub domain.CreateSubscriberResponse
+		err := c.do(ctx, http.MethodPost, "/subscribers", map[string]interface{}{
+			"name":         fmt.Sprintf("loadgen %s #%d", runID, i+1),
+			"endpoint_url": cfg.endpoint,
+			"event_types":  types,
+		}, &sub)
+		if err != nil {
+			return ids, fmt.Errorf("creating subscriber: %w", err)
+		}
+		ids = append(ids, sub.ID)
+	}
+	return ids, nil
+}
+
+// deactivateSubscribers switches off the temporary subscribers. The API
+// has no way to delete a subscriber, so they stay listed but inactive. It
+// runs after ctx may have been cancelled by Ctrl-C, so it uses its own
+// deadline.
+func deactivateSubscribers(c *client, ids []string, out io.Writer) {
+	if len(ids) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	deactivated := 0
+	for _, id := range ids {
+		err := c.do(ctx, http.MethodPatch, "/subscribers/"+url.PathEscape(id), map[string]bool{"is_active": false}, nil)
+		if err != nil {
+			fmt.Fprintf(out, "warning: subscriber %s not deactivated: %v\n", id, err)
+			continue
+		}
+		deactivated++
+	}
+	fmt.Fprintf(out, "deactivated %d subscribers\n", deactivated)
+}
@@ -0,0 +1,154 @@
+// Command loadgen publishes synthetic events to a running webhook delivery
+// server at a fixed rate, follows their deliveries, and prints a throughput
+// and end-to-end latency report. Run it against a staging instance before a
+// rollout to measure capacity.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// config holds the command-line flags.
+type config struct {
+	server      string
+	apiKey      string
+	rate        float64
+	duration    time.Duration
+	mix         []weightedType
+	payloadSize int
+	concurrency int
+	watch       string
+	drain       time.Duration
+	subscribers int
+	endpoint    string
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg, err := parseFlags(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(2)
+	}
+	if err := run(ctx, cfg, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+func parseFlags(args []string) (config, error) {
+	var cfg config
+	var mix string
+
+	fs := flag.NewFlagSet("loadgen", flag.ContinueOnError)
+	fs.StringVar(&cfg.server, "server", envOr("WEBHOOK_SERVER", "http://localhost:8080"), "server base URL (env WEBHOOK_SERVER)")
+	fs.StringVar(&cfg.apiKey, "api-key", os.Getenv("WEBHOOK_API_KEY"), "API key with the operator role (env WEBHOOK_API_KEY)")
+	fs.Float64Var(&cfg.rate, "rate", 10, "events published per second")
+	fs.DurationVar(&cfg.duration, "duration", 30*time.Second, "how long to publish for")
+	fs.StringVar(&mix, "types", "load.test", "event type mix as type=weight pairs, e.g. order.created=3,user.signup=1")
+	fs.IntVar(&cfg.payloadSize, "payload-size", 256, "approximate payload size in bytes")
+	fs.IntVar(&cfg.concurrency, "concurrency", 16, "maximum publish requests in flight")
+	fs.StringVar(&cfg.watch, "watch", "ws", "how to follow deliveries: ws (the /ws stream) or poll (the deliveries API)")
+	fs.DurationVar(&cfg.drain, "drain", 60*time.Second, "how long to wait for outstanding deliveries after publishing stops")
+	fs.IntVar(&cfg.subscribers, "subscribers", 0, "create this many temporary subscribers for the event types, deactivated afterwards (needs an admin key)")
+	fs.StringVar(&cfg.endpoint, "endpoint", "http://localhost:9090/webhook/success", "endpoint URL for subscribers created with -subscribers")
+	if err := fs.Parse(args); err != nil {
+		return cfg, err
+	}
+
+	var err error
+	if cfg.mix, err = parseMix(mix); err != nil {
+		return cfg, err
+	}
+	switch {
+	case cfg.rate <= 0:
+		return cfg, fmt.Errorf("-rate must be positive")
+	case cfg.duration <= 0:
+		return cfg, fmt.Errorf("-duration must be positive")
+	case cfg.concurrency <= 0:
+		return cfg, fmt.Errorf("-concurrency must be positive")
+	case cfg.watch != "ws" && cfg.watch != "poll":
+		return cfg, fmt.Errorf("-watch must be ws or poll")
+	}
+	return cfg, nil
+}
+
+func run(ctx context.Context, cfg config, out io.Writer) error {
+	c := newClient(cfg.server, cfg.apiKey)
+	tr := newTracker()
+
+	if cfg.subscribers > 0 {
+		ids, err := createSubscribers(ctx, c, cfg)
+		defer deactivateSubscribers(c, ids, out)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "created %d subscribers -> %s\n", len(ids), cfg.endpoint)
+	}
+
+	watchCtx, stopWatch := context.WithCancel(ctx)
+	defer stopWatch()
+	watchErr := make(chan error, 1)
+	if cfg.watch == "ws" {
+		// Connect before publishing so no delivery is missed
+		conn, err := dialStream(ctx, cfg.server, cfg.apiKey)
+		if err != nil {
+			return err
+		}
+		go func() { watchErr <- watchStream(watchCtx, conn, tr) }()
+	} else {
+		go func() { watchErr <- pollDeliveries(watchCtx, c, tr, time.Second) }()
+	}
+
+	fmt.Fprintf(out, "publishing %.1f events/s for %s (%s)\n", cfg.rate, cfg.duration, describeMix(cfg.mix))
+	publishStart := time.Now()
+	publish(ctx, c, cfg, tr)
+	publishElapsed := time.Since(publishStart)
+
+	fmt.Fprintf(out, "waiting up to %s for deliveries to finish\n", cfg.drain)
+	if err := waitForDeliveries(ctx, tr, cfg.drain, watchErr); err != nil {
+		fmt.Fprintln(out, "warning:", err)
+	}
+	stopWatch()
+
+	tr.report(publishElapsed).print(out)
+	return nil
+}
+
+// waitForDeliveries returns once every published event has a final outcome
+// for each delivery it queued, the drain timeout passes, or the watcher
+// fails.
+func waitForDeliveries(ctx context.Context, tr *tracker, timeout time.Duration, watchErr <-chan error) error {
+	deadline := time.After(timeout)
+	tick := time.NewTicker(200 * time.Millisecond)
+	defer tick.Stop()
+
+	for !tr.complete() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-watchErr:
+			return fmt.Errorf("stopped following deliveries: %w", err)
+		case <-deadline:
+			return fmt.Errorf("drain timeout reached with deliveries outstanding")
+		case <-tick.C:
+		}
+	}
+	return nil
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
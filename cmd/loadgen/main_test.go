@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	ws "github.com/Priya8975/webhook-delivery-system/internal/websocket"
+)
+
+// fakeServer imitates the API loadgen uses. Every event fans out to two
+// subscribers whose deliveries succeed 10ms after the event is created.
+type fakeServer struct {
+	mu     sync.Mutex
+	events map[string]time.Time
+	conns  []*websocket.Conn
+}
+
+func newFakeServer(t *testing.T) *httptest.Server {
+	f := &fakeServer{events: make(map[string]time.Time)}
+	upgrader := websocket.Upgrader{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/events", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		id := fmt.Sprintf("evt-%d", len(f.events)+1)
+		f.events[id] = time.Now()
+		// Announce the deliveries before answering, as a fast server can
+		for _, sub := range []string{"sub-a", "sub-b"} {
+			for _, c := range f.conns {
+				c.WriteJSON(ws.DeliveryEvent{Type: "delivery_success", DeliveryID: id + sub, EventID: id, SubscriberID: sub, Attempt: 1})
+			}
+		}
+		f.mu.Unlock()
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{"event_id": id, "deliveries_queued": 2})
+	})
+	mux.HandleFunc("GET /api/v1/events/{id}", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		created := f.events[r.PathValue("id")]
+		f.mu.Unlock()
+		json.NewEncoder(w).Encode(domain.Event{ID: r.PathValue("id"), CreatedAt: created})
+	})
+	mux.HandleFunc("GET /api/v1/deliveries", func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Query().Get("event_id")
+		f.mu.Lock()
+		created := f.events[id]
+		f.mu.Unlock()
+		var attempts []domain.DeliveryAttempt
+		for _, sub := range []string{"sub-a", "sub-b"} {
+			attempts = append(attempts, domain.DeliveryAttempt{
+				ID: id + sub, EventID: id, SubscriberID: sub, AttemptNumber: 1,
+				Status: "success", CreatedAt: created.Add(10 * time.Millisecond),
+			})
+		}
+		json.NewEncoder(w).Encode(attempts)
+	})
+	mux.HandleFunc("GET /ws", func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade: %v", err)
+			return
+		}
+		f.mu.Lock()
+		f.conns = append(f.conns, conn)
+		f.mu.Unlock()
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRun_ReportsDeliveries(t *testing.T) {
+	for _, watch := range []string{"ws", "poll"} {
+		t.Run(watch, func(t *testing.T) {
+			server := newFakeServer(t)
+			cfg, err := parseFlags([]string{
+				"-server", server.URL, "-rate", "50", "-duration", "200ms",
+				"-watch", watch, "-drain", "5s", "-types", "a=1,b=1",
+			})
+			if err != nil {
+				t.Fatalf("parseFlags: %v", err)
+			}
+
+			var out bytes.Buffer
+			if err := run(context.Background(), cfg, &out); err != nil {
+				t.Fatalf("run: %v", err)
+			}
+			report := out.String()
+			if strings.Contains(report, "warning:") {
+				t.Errorf("expected every delivery to be seen:\n%s", report)
+			}
+			if !regexp.MustCompile(`events outstanding\s+0\n`).MatchString(report) {
+				t.Errorf("expected no outstanding events:\n%s", report)
+			}
+			if watch == "poll" && !strings.Contains(report, "p50=10ms") {
+				t.Errorf("expected server-clock latency of 10ms:\n%s", report)
+			}
+		})
+	}
+}
+
+func TestParseMix(t *testing.T) {
+	mix, err := parseMix("order.created=3, user.signup")
+	if err != nil {
+		t.Fatalf("parseMix: %v", err)
+	}
+	if len(mix) != 2 || mix[0] != (weightedType{"order.created", 3}) || mix[1] != (weightedType{"user.signup", 1}) {
+		t.Errorf("unexpected mix %+v", mix)
+	}
+
+	for _, bad := range []string{"", "a=0", "a=x", "=2"} {
+		if _, err := parseMix(bad); err == nil {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestPickType_FollowsWeights(t *testing.T) {
+	mix := []weightedType{{"heavy", 9}, {"light", 1}}
+	r := rand.New(rand.NewPCG(1, 2))
+	counts := map[string]int{}
+	for i := 0; i < 10000; i++ {
+		counts[pickType(mix, r)]++
+	}
+	if counts["heavy"] < 8500 || counts["heavy"] > 9500 {
+		t.Errorf("expected about 9000 heavy picks, got %v", counts)
+	}
+}
+
+func TestTracker_CountsFirstSuccessPerSubscriber(t *testing.T) {
+	tr := newTracker()
+	start := time.Now()
+
+	// A delivery can be seen before the publish response is processed
+	tr.observe(observation{eventID: "e1", subscriberID: "s1", deliveryID: "d1", outcome: outcomeRetrying, at: start.Add(time.Millisecond)})
+	tr.published(publishResult{EventID: "e1", DeliveriesQueued: 2}, start, time.Millisecond)
+	tr.observe(observation{eventID: "e1", subscriberID: "s1", deliveryID: "d2", outcome: outcomeSuccess, at: start.Add(50 * time.Millisecond)})
+	tr.observe(observation{eventID: "e1", subscriberID: "s1", deliveryID: "d2", outcome: outcomeSuccess, at: start.Add(60 * time.Millisecond)})
+	if tr.complete() {
+		t.Fatal("expected the event to wait for its second subscriber")
+	}
+	tr.observe(observation{eventID: "e1", subscriberID: "s2", deliveryID: "d3", outcome: outcomeDead, at: start.Add(70 * time.Millisecond)})
+	if !tr.complete() {
+		t.Fatal("expected the event to be complete once both subscribers finished")
+	}
+
+	r := tr.report(time.Second)
+	if r.attempts != 3 || r.retries != 1 || r.delivered != 1 || r.dead != 1 {
+		t.Errorf("unexpected counts %+v", r)
+	}
+	if r.e2e.p50 != 50*time.Millisecond {
+		t.Errorf("expected 50ms end-to-end latency, got %s", r.e2e.p50)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	var samples []time.Duration
+	for i := 1; i <= 100; i++ {
+		samples = append(samples, time.Duration(i)*time.Millisecond)
+	}
+	s := summarize(samples)
+	if s.p50 != 50*time.Millisecond || s.p99 != 99*time.Millisecond || s.max != 100*time.Millisecond {
+		t.Errorf("unexpected summary %+v", s)
+	}
+}
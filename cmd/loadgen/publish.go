@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// weightedType is one entry of the event type mix.
+type weightedType struct {
+	eventType string
+	weight    int
+}
+
+// parseMix parses "order.created=3,user.signup=1". A type without a weight
+// counts once.
+func parseMix(s string) ([]weightedType, error) {
+	var mix []weightedType
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, weightStr, hasWeight := strings.Cut(part, "=")
+		w := weightedType{eventType: strings.TrimSpace(name), weight: 1}
+		if hasWeight {
+			n, err := strconv.Atoi(strings.TrimSpace(weightStr))
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("invalid weight in %q: must be a positive integer", part)
+			}
+			w.weight = n
+		}
+		if w.eventType == "" {
+			return nil, fmt.Errorf("missing event type in %q", part)
+		}
+		mix = append(mix, w)
+	}
+	if len(mix) == 0 {
+		return nil, fmt.Errorf("-types must name at least one event type")
+	}
+	return mix, nil
+}
+
+// pickType chooses an event type with probability proportional to its
+// weight.
+func pickType(mix []weightedType, r *rand.Rand) string {
+	total := 0
+	for _, w := range mix {
+		total += w.weight
+	}
+	n := r.IntN(total)
+	for _, w := range mix {
+		if n < w.weight {
+			return w.eventType
+		}
+		n -= w.weight
+	}
+	return mix[len(mix)-1].eventType
+}
+
+func describeMix(mix []weightedType) string {
+	parts := make([]string, len(mix))
+	for i, w := range mix {
+		parts[i] = fmt.Sprintf("%s=%d", w.eventType, w.weight)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// syntheticPayload builds an event payload padded to roughly size bytes.
+func syntheticPayload(seq int64, size int) json.RawMessage {
+	payload := map[string]interface{}{
+		"loadgen": true,
+		"seq":     seq,
+		"sent_at": time.Now().UTC().Format(time.RFC3339Nano),
+	}
+	base, _ := json.Marshal(payload)
+	if pad := size - len(base) - len(`,"padding":""`); pad > 0 {
+		payload["padding"] = strings.Repeat("x", pad)
+	}
+	data, _ := json.Marshal(payload)
+	return data
+}
+
+// publish sends events at cfg.rate until cfg.duration passes or ctx is
+// cancelled. Ticks that find every publish slot busy are counted as skipped
+// rather than queued, so a server that can't keep up shows as a shortfall in
+// the achieved rate instead of a burst at the end.
+func publish(ctx context.Context, c *client, cfg config, tr *tracker) {
+	ctx, cancel := context.WithTimeout(ctx, cfg.duration)
+	defer cancel()
+
+	r := rand.New(rand.NewPCG(uint64(time.Now().UnixNano()), 0))
+	slots := make(chan struct{}, cfg.concurrency)
+	var wg sync.WaitGroup
+
+	tick := time.NewTicker(time.Duration(float64(time.Second) / cfg.rate))
+	defer tick.Stop()
+
+	var seq int64
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case <-tick.C:
+		}
+
+		select {
+		case slots <- struct{}{}:
+		default:
+			tr.publishSkipped()
+			continue
+		}
+
+		seq++
+		eventType := pickType(cfg.mix, r)
+		payload := syntheticPayload(seq, cfg.payloadSize)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			// Let in-flight requests finish after the publish window closes
+			reqCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+			defer cancel()
+
+			start := time.Now()
+			res, err := c.publishEvent(reqCtx, eventType, payload)
+			if err != nil {
+				tr.publishFailed(err)
+				return
+			}
+			tr.published(res, start, time.Since(start))
+		}()
+	}
+}
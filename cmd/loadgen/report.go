@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// report summarizes a run.
+type report struct {
+	publishElapsed time.Duration
+	published      int
+	publishErrors  int
+	lastError      string
+	skipped        int
+	publishLatency latencySummary
+
+	expected    int // deliveries the server queued, where known
+	attempts    int
+	delivered   int
+	retries     int
+	dead        int
+	outstanding int // events still waiting for a final outcome
+	throughput  float64
+	e2e         latencySummary
+}
+
+// latencySummary holds percentiles of a set of durations.
+type latencySummary struct {
+	count                   int
+	p50, p90, p95, p99, max time.Duration
+}
+
+func summarize(samples []time.Duration) latencySummary {
+	if len(samples) == 0 {
+		return latencySummary{}
+	}
+	sorted := append([]time.Duration(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return latencySummary{
+		count: len(sorted),
+		p50:   percentile(sorted, 50),
+		p90:   percentile(sorted, 90),
+		p95:   percentile(sorted, 95),
+		p99:   percentile(sorted, 99),
+		max:   sorted[len(sorted)-1],
+	}
+}
+
+// percentile returns the nearest-rank percentile of sorted samples.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(p/100*float64(len(sorted))+0.5) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
+
+func (t *tracker) report(publishElapsed time.Duration) report {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	r := report{
+		publishElapsed: publishElapsed,
+		published:      len(t.events),
+		publishErrors:  t.publishErrors,
+		lastError:      t.lastError,
+		skipped:        t.skipped,
+		publishLatency: summarize(t.publishLatency),
+		attempts:       t.attempts,
+		delivered:      len(t.e2e),
+		retries:        t.retries,
+		dead:           t.dead,
+		e2e:            summarize(t.e2e),
+	}
+	for _, ev := range t.events {
+		if ev.expected >= 0 {
+			r.expected += ev.expected
+		}
+		if ev.expected < 0 || len(ev.finished) < ev.expected {
+			r.outstanding++
+		}
+	}
+	if span := t.lastSuccess.Sub(t.firstPublish); r.delivered > 0 && span > 0 {
+		r.throughput = float64(r.delivered) / span.Seconds()
+	}
+	return r
+}
+
+func (r report) print(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "PUBLISH")
+	fmt.Fprintf(tw, "  events published\t%d\n", r.published)
+	fmt.Fprintf(tw, "  achieved rate\t%.1f events/s\n", float64(r.published)/r.publishElapsed.Seconds())
+	fmt.Fprintf(tw, "  errors\t%d\n", r.publishErrors)
+	if r.skipped > 0 {
+		fmt.Fprintf(tw, "  skipped (all requests in flight)\t%d\n", r.skipped)
+	}
+	fmt.Fprintf(tw, "  API latency\t%s\n", r.publishLatency)
+
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "DELIVERY")
+	fmt.Fprintf(tw, "  deliveries queued\t%d\n", r.expected)
+	fmt.Fprintf(tw, "  delivered\t%d\n", r.delivered)
+	fmt.Fprintf(tw, "  dead-lettered\t%d\n", r.dead)
+	fmt.Fprintf(tw, "  attempts (retries)\t%d (%d)\n", r.attempts, r.retries)
+	fmt.Fprintf(tw, "  events outstanding\t%d\n", r.outstanding)
+	fmt.Fprintf(tw, "  throughput\t%.1f deliveries/s\n", r.throughput)
+	fmt.Fprintf(tw, "  end-to-end latency\t%s\n", r.e2e)
+	tw.Flush()
+
+	if r.lastError != "" {
+		fmt.Fprintf(w, "\nlast publish error: %s\n", r.lastError)
+	}
+}
+
+func (s latencySummary) String() string {
+	if s.count == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("p50=%s p90=%s p95=%s p99=%s max=%s",
+		round(s.p50), round(s.p90), round(s.p95), round(s.p99), round(s.max))
+}
+
+func round(d time.Duration) time.Duration {
+	if d >= time.Second {
+		return d.Round(time.Millisecond)
+	}
+	return d.Round(100 * time.Microsecond)
+}
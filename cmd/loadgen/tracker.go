@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// outcome classifies one delivery attempt.
+type outcome int
+
+const (
+	outcomeSuccess outcome = iota
+	// outcomeRetrying is a failed attempt that will be retried.
+	outcomeRetrying
+	// outcomeDead is a failed attempt with no retries left.
+	outcomeDead
+)
+
+// observation is a delivery attempt seen by a watcher.
+type observation struct {
+	eventID      string
+	subscriberID string
+	deliveryID   string
+	attempt      int
+	outcome      outcome
+	// at is when the attempt was seen, on the local clock, and is compared
+	// with the local publish time. Watchers that read server timestamps
+	// leave it zero and set latency from the server's clock instead, so
+	// clock skew between loadgen and the server doesn't matter.
+	at      time.Time
+	latency time.Duration
+}
+
+// trackedEvent is an event loadgen published.
+type trackedEvent struct {
+	publishedAt time.Time
+	// expected is how many deliveries the server queued for the event, or
+	// -1 if fan-out was deferred to the outbox and the count is unknown.
+	expected int
+	seen     map[string]bool // delivery IDs already counted
+	finished map[string]bool // subscribers with a final outcome
+}
+
+// tracker collects publish results and delivery observations.
+type tracker struct {
+	mu     sync.Mutex
+	events map[string]*trackedEvent
+	// early holds observations for events whose publish response hasn't
+	// been processed yet; a fast delivery can beat it.
+	early map[string][]observation
+
+	firstPublish   time.Time
+	publishLatency []time.Duration
+	publishErrors  int
+	lastError      string
+	skipped        int
+
+	attempts    int
+	retries     int
+	dead        int
+	e2e         []time.Duration
+	lastSuccess time.Time // local-clock estimate of the latest success
+}
+
+func newTracker() *tracker {
+	return &tracker{
+		events: make(map[string]*trackedEvent),
+		early:  make(map[string][]observation),
+	}
+}
+
+func (t *tracker) published(res publishResult, start time.Time, apiLatency time.Duration) {
+	expected := res.DeliveriesQueued
+	if res.FanOutPending {
+		expected = -1
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.firstPublish.IsZero() || start.Before(t.firstPublish) {
+		t.firstPublish = start
+	}
+	t.publishLatency = append(t.publishLatency, apiLatency)
+	t.events[res.EventID] = &trackedEvent{
+		publishedAt: start,
+		expected:    expected,
+		seen:        make(map[string]bool),
+		finished:    make(map[string]bool),
+	}
+	for _, o := range t.early[res.EventID] {
+		t.observeLocked(o)
+	}
+	delete(t.early, res.EventID)
+}
+
+func (t *tracker) publishFailed(err error) {
+	t.mu.Lock()
+	t.publishErrors++
+	t.lastError = err.Error()
+	t.mu.Unlock()
+}
+
+func (t *tracker) publishSkipped() {
+	t.mu.Lock()
+	t.skipped++
+	t.mu.Unlock()
+}
+
+func (t *tracker) observe(o observation) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.events[o.eventID]; !ok {
+		t.early[o.eventID] = append(t.early[o.eventID], o)
+		return
+	}
+	t.observeLocked(o)
+}
+
+func (t *tracker) observeLocked(o observation) {
+	ev := t.events[o.eventID]
+	key := o.deliveryID
+	if key == "" {
+		// Servers that predate delivery IDs
+		key = fmt.Sprintf("%s/%d", o.subscriberID, o.attempt)
+	}
+	if ev.seen[key] {
+		return
+	}
+	ev.seen[key] = true
+
+	t.attempts++
+	switch o.outcome {
+	case outcomeRetrying:
+		t.retries++
+		return
+	case outcomeDead:
+		t.dead++
+		ev.finished[o.subscriberID] = true
+		return
+	}
+
+	if ev.finished[o.subscriberID] {
+		return
+	}
+	ev.finished[o.subscriberID] = true
+	latency := o.latency
+	if !o.at.IsZero() {
+		latency = o.at.Sub(ev.publishedAt)
+	}
+	t.e2e = append(t.e2e, latency)
+	if done := ev.publishedAt.Add(latency); done.After(t.lastSuccess) {
+		t.lastSuccess = done
+	}
+}
+
+// complete reports whether every published event has a final outcome for
+// each delivery it queued. Events whose fan-out count is unknown are not
+// waited for.
+func (t *tracker) complete() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, ev := range t.events {
+		if ev.expected >= 0 && len(ev.finished) < ev.expected {
+			return false
+		}
+	}
+	return true
+}
+
+// pending returns the IDs of events still waiting for deliveries.
+func (t *tracker) pending() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var ids []string
+	for id, ev := range t.events {
+		if ev.expected < 0 || len(ev.finished) < ev.expected {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
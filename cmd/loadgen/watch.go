@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	ws "github.com/Priya8975/webhook-delivery-system/internal/websocket"
+)
+
+// dialStream opens the server's /ws delivery stream.
+func dialStream(ctx context.Context, server, apiKey string) (*websocket.Conn, error) {
+	u, err := url.Parse(strings.TrimRight(server, "/") + "/ws")
+	if err != nil {
+		return nil, fmt.Errorf("parsing server URL: %w", err)
+	}
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	default:
+		u.Scheme = "ws"
+	}
+	if apiKey != "" {
+		u.RawQuery = url.Values{"token": {apiKey}}.Encode()
+	}
+
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to %s: %w", u.Redacted(), err)
+	}
+	return conn, nil
+}
+
+// watchStream reads delivery events from the stream until ctx is cancelled.
+// Latency is measured on loadgen's clock, from the publish request to the
+// moment the event arrives, so it includes the stream's own small delay.
+func watchStream(ctx context.Context, conn *websocket.Conn, tr *tracker) error {
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("reading stream: %w", err)
+		}
+		at := time.Now()
+
+		var e ws.DeliveryEvent
+		if err := json.Unmarshal(data, &e); err != nil || e.EventID == "" {
+			continue // snapshots and control messages
+		}
+		o := observation{
+			eventID:      e.EventID,
+			subscriberID: e.SubscriberID,
+			deliveryID:   e.DeliveryID,
+			attempt:      e.Attempt,
+			at:           at,
+		}
+		switch e.Type {
+		case "delivery_success", "delivery_simulated":
+			o.outcome = outcomeSuccess
+		case "delivery_retrying":
+			o.outcome = outcomeRetrying
+		default:
+			o.outcome = outcomeDead
+		}
+		tr.observe(o)
+	}
+}
+
+// pollConcurrency caps the deliveries API requests one poll round makes at
+// once.
+const pollConcurrency = 8
+
+// pollDeliveries lists the delivery attempts of every unfinished event each
+// interval until ctx is cancelled. Latency is measured on the server's
+// clock, from the event's creation to the attempt being recorded. Unlike
+// the stream, which drops events for slow clients, polling sees every
+// attempt, at the cost of one request per outstanding event per round.
+func pollDeliveries(ctx context.Context, c *client, tr *tracker, interval time.Duration) error {
+	var mu sync.Mutex
+	created := make(map[string]time.Time) // event ID -> server creation time
+
+	tick := time.NewTicker(interval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-tick.C:
+		}
+
+		sem := make(chan struct{}, pollConcurrency)
+		var wg sync.WaitGroup
+		for _, id := range tr.pending() {
+			sem <- struct{}{}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				mu.Lock()
+				createdAt, ok := created[id]
+				mu.Unlock()
+				if !ok {
+					ev, err := c.getEvent(ctx, id)
+					if err != nil {
+						return
+					}
+					createdAt = ev.CreatedAt
+					mu.Lock()
+					created[id] = createdAt
+					mu.Unlock()
+				}
+
+				attempts, err := c.listDeliveries(ctx, id)
+				if err != nil {
+					return
+				}
+				for _, a := range attempts {
+					o := observation{
+						eventID:      a.EventID,
+						subscriberID: a.SubscriberID,
+						deliveryID:   a.ID,
+						attempt:      a.AttemptNumber,
+						latency:      a.CreatedAt.Sub(createdAt),
+					}
+					switch {
+					case a.Status == "success":
+						o.outcome = outcomeSuccess
+					case a.NextRetryAt != nil:
+						o.outcome = outcomeRetrying
+					default:
+						o.outcome = outcomeDead
+					}
+					tr.observe(o)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+}
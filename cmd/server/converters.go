@@ -0,0 +1,18 @@
+package main
+
+import "github.com/Priya8975/webhook-delivery-system/internal/worker"
+
+// payloadConverters returns the converters that down-convert event payloads
+// for subscribers pinned to an older version. When an event type gets a new
+// payload version, register a converter from it to the version before, so
+// that subscribers can stay pinned until they migrate:
+//
+//	c.Register("order.created", 2, func(p json.RawMessage) (json.RawMessage, error) {
+//		// v2 nested the amount; v1 had it at the top level
+//		...
+//	})
+func payloadConverters() *worker.PayloadConverters {
+	c := worker.NewPayloadConverters()
+	// Register converters here
+	return c
+}
@@ -2,26 +2,48 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
 	"io/fs"
 	"log/slog"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/Priya8975/webhook-delivery-system/internal/alerting"
 	"github.com/Priya8975/webhook-delivery-system/internal/api"
+	"github.com/Priya8975/webhook-delivery-system/internal/archive"
+	"github.com/Priya8975/webhook-delivery-system/internal/chaos"
 	"github.com/Priya8975/webhook-delivery-system/internal/config"
+	"github.com/Priya8975/webhook-delivery-system/internal/deadletter"
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
 	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+	"github.com/Priya8975/webhook-delivery-system/internal/grpcapi"
+	"github.com/Priya8975/webhook-delivery-system/internal/ingest"
+	"github.com/Priya8975/webhook-delivery-system/internal/reload"
+	"github.com/Priya8975/webhook-delivery-system/internal/sink"
 	"github.com/Priya8975/webhook-delivery-system/internal/store"
 	ws "github.com/Priya8975/webhook-delivery-system/internal/websocket"
 	"github.com/Priya8975/webhook-delivery-system/internal/worker"
+	"github.com/Priya8975/webhook-delivery-system/migrations"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 func main() {
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	slog.SetDefault(logger)
 
-	cfg, err := config.Load()
+	configPath := flag.String("config", os.Getenv("CONFIG_FILE"), "path to a YAML or TOML config file (env CONFIG_FILE)")
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
 	if err != nil {
 		logger.Error("failed to load config", "error", err)
 		os.Exit(1)
@@ -30,50 +52,417 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// Initialize PostgreSQL
-	pgStore, err := store.NewPostgres(ctx, cfg.DatabaseURL)
+	// Initialize the database: PostgreSQL, or SQLite for single-node setups
+	db, err := store.Open(ctx, cfg.DatabaseURL, store.PoolConfig{
+		ReplicaURL:        cfg.DatabaseReplicaURL,
+		MaxConns:          cfg.DatabaseMaxConns,
+		MinConns:          cfg.DatabaseMinConns,
+		MaxConnLifetime:   cfg.DatabaseMaxConnLifetime,
+		HealthCheckPeriod: cfg.DatabaseHealthCheckPeriod,
+		StatementTimeout:  cfg.DatabaseStatementTimeout,
+	})
 	if err != nil {
-		logger.Error("failed to connect to postgres", "error", err)
+		logger.Error("failed to connect to database", "error", err)
 		os.Exit(1)
 	}
-	defer pgStore.Close()
-	logger.Info("connected to PostgreSQL")
+	defer db.Close()
+	pgStore, isPostgres := db.(*store.PostgresStore)
+	if isPostgres {
+		logger.Info("connected to PostgreSQL", "read_replica", cfg.DatabaseReplicaURL != "")
+	} else {
+		logger.Info("connected to SQLite")
+	}
 
-	// Run database migrations
-	if err := pgStore.RunMigrations(ctx, "migrations"); err != nil {
-		logger.Error("failed to run migrations", "error", err)
-		os.Exit(1)
+	// Run database migrations, unless they are left to webhookctl migrate
+	if cfg.DatabaseAutoMigrate {
+		applied, err := db.RunMigrations(ctx, migrations.FS)
+		if err != nil {
+			logger.Error("failed to run migrations", "error", err)
+			os.Exit(1)
+		}
+		logger.Info("database migrations applied", "applied", len(applied))
 	}
-	logger.Info("database migrations applied")
 
-	// Initialize Redis
-	redisStore, err := store.NewRedis(ctx, cfg.RedisURL)
+	// Initialize Redis. Without a REDIS_URL (only allowed with the in-memory
+	// queue) an embedded emulation holds breaker, rate limit and live feed state.
+	var redisStore *store.RedisStore
+	if cfg.RedisURL != "" {
+		redisStore, err = store.NewRedis(ctx, cfg.RedisURL)
+	} else {
+		redisStore, err = store.NewEmbeddedRedis(ctx)
+	}
 	if err != nil {
 		logger.Error("failed to connect to redis", "error", err)
 		os.Exit(1)
 	}
 	defer redisStore.Close()
-	logger.Info("connected to Redis")
+	if cfg.RedisURL != "" {
+		logger.Info("connected to Redis")
+	} else {
+		logger.Info("using embedded in-process Redis")
+	}
+
+	// Fault injection for resilience testing, turned on at runtime through
+	// /api/v1/admin/chaos. Without CHAOS_ENABLED the endpoints refuse
+	var chaosInjector *chaos.Injector
+	if cfg.ChaosEnabled {
+		chaosInjector = chaos.New()
+		redisStore.Client().AddHook(chaosInjector.RedisHook())
+		logger.Warn("chaos mode enabled: admins can inject faults into this instance")
+	}
+
+	// Match events against an in-memory index of subscriptions instead of
+	// querying the database for every event
+	if cfg.SubscriberCacheTTL > 0 {
+		cache := store.NewSubscriberCache(db, redisStore.Client(), cfg.SubscriberCacheTTL, logger)
+		go cache.Start(ctx)
+		db = cache
+	}
+
+	// Initialize the delivery queue. Redis-backed instances register with the
+	// cluster so their claimed jobs can be recovered if they die, and schedule
+	// jobs by the Redis clock so skewed instance clocks agree on when they
+	// are due.
+	cluster := engine.NewCluster(redisStore.Client(), cfg.InstanceID, cfg.ClusterHeartbeatTTL, logger)
+	var queue engine.Queue
+	var clock engine.Clock = engine.SystemClock{}
+	if cfg.QueueMode == "memory" {
+		memoryQueue := engine.NewMemoryQueue()
+		memoryQueue.SetFairnessWindow(int64(cfg.DispatcherFairnessWindow))
+		queue = memoryQueue
+		logger.Warn("using in-memory delivery queue: queued jobs and scheduled retries are lost on restart, and the queue is not shared with other instances")
+	} else {
+		if err := cluster.Heartbeat(ctx); err != nil {
+			logger.Error("failed to register instance", "error", err)
+			os.Exit(1)
+		}
+		go cluster.Start(ctx)
+		redisClock := engine.NewRedisClock(redisStore.Client(), logger)
+		if err := redisClock.Sync(ctx); err != nil {
+			logger.Error("failed to read the Redis clock", "error", err)
+			os.Exit(1)
+		}
+		go redisClock.Start(ctx, cfg.ClockSyncInterval)
+		clock = redisClock
+		redisQueue := engine.NewRedisQueue(redisStore.Client(), cluster)
+		redisQueue.SetClock(clock)
+		redisQueue.SetPublishChunking(cfg.FanOutChunkSize, cfg.FanOutParallelism)
+		redisQueue.SetFairnessWindow(int64(cfg.DispatcherFairnessWindow))
+
+		// Retries scheduled while Redis is unreachable wait in the database
+		fallbackQueue := engine.NewFallbackQueue(redisQueue, db, logger)
+		go fallbackQueue.Start(ctx)
+		queue = fallbackQueue
+	}
+
+	// Object storage for archives, usage exports and offloaded payloads
+	// (optional)
+	var archiveS3 *archive.S3Client
+	if cfg.ArchiveS3Bucket != "" {
+		archiveS3 = archive.NewS3Client(archive.S3Config{
+			Endpoint:  cfg.ArchiveS3Endpoint,
+			Region:    cfg.ArchiveS3Region,
+			Bucket:    cfg.ArchiveS3Bucket,
+			AccessKey: cfg.ArchiveS3AccessKey,
+			SecretKey: cfg.ArchiveS3SecretKey,
+		})
+	}
+	var payloadObjects engine.ObjectStore
+	if archiveS3 != nil {
+		payloadObjects = archiveS3
+	}
 
 	// Initialize fan-out engine
-	fanout := engine.NewFanOutEngine(pgStore, redisStore, logger)
+	fanout := engine.NewFanOutEngine(db, queue, redisStore, logger)
+	fanout.SetMaxAttempts(cfg.RetryMaxAttempts)
+	fanout.SetClock(clock)
+	fanout.SetMaxPayloadBytes(cfg.IngestMaxPayloadBytes)
+	if cfg.IngestOffloadThresholdBytes > 0 {
+		fanout.SetPayloadOffload(payloadObjects, cfg.ArchivePrefix, cfg.IngestOffloadThresholdBytes)
+	}
+	receipts := engine.NewDeliveryReceipts(redisStore.Client())
+	fanout.SetReceipts(receipts)
+
+	// Start outbox relay to retry fan-outs that failed at ingestion time
+	outboxRelay := engine.NewOutboxRelay(db, fanout, logger)
+	go outboxRelay.Start(ctx)
+
+	// Queue the deliveries that partly failed fan-outs missed
+	fanOutRepairer := engine.NewFanOutRepairer(fanout, logger)
+	go fanOutRepairer.Start(ctx)
+
+	// End timed subscriber pauses and release their parked jobs
+	pauseExpirer := engine.NewPauseExpirer(queue, logger)
+	pauseExpirer.SetClock(clock)
+	go pauseExpirer.Start(ctx)
+
+	// Keep the hourly metrics rollup current for the dashboard. SQLite
+	// aggregates raw attempts instead.
+	if isPostgres {
+		metricsRollup := engine.NewMetricsRollup(pgStore, logger)
+		go metricsRollup.Start(ctx)
+
+		partitions := engine.NewPartitionMaintainer(pgStore, logger)
+		go partitions.Start(ctx)
+	}
+
+	// Consume events from Kafka (optional)
+	if len(cfg.KafkaBrokers) > 0 {
+		consumer := ingest.NewKafkaConsumer(ingest.KafkaConfig{
+			Brokers: cfg.KafkaBrokers,
+			Topic:   cfg.KafkaTopic,
+			GroupID: cfg.KafkaGroupID,
+		}, fanout, logger)
+		go consumer.Start(ctx)
+	}
+
+	// Consume events from NATS JetStream (optional)
+	if cfg.NATSURL != "" {
+		subjects, err := ingest.ParseSubjectMappings(cfg.NATSSubjects)
+		if err != nil {
+			logger.Error("invalid NATS_SUBJECTS", "error", err)
+			os.Exit(1)
+		}
+		consumer := ingest.NewNATSConsumer(ingest.NATSConfig{
+			URL:      cfg.NATSURL,
+			Stream:   cfg.NATSStream,
+			Durable:  cfg.NATSDurable,
+			Subjects: subjects,
+		}, fanout, db, logger)
+		go consumer.Start(ctx)
+	}
+
+	// Record queue depth samples for dashboard charts
+	queueSampler := engine.NewQueueDepthSampler(redisStore.Client(), queue, logger)
+	go queueSampler.Start(ctx)
 
 	// Initialize circuit breaker and rate limiter
 	circuitBreaker := engine.NewCircuitBreaker(redisStore.Client(), logger)
+	circuitBreaker.SetThresholds(cfg.CircuitBreakerFailureThreshold, cfg.CircuitBreakerCooldown)
+	circuitBreaker.SetClock(clock)
+	circuitBreaker.OnOpen(func(ctx context.Context, subscriberID string, failures int64, openedAt time.Time) {
+		fanout.PublishSystemEvent(ctx, domain.EventSubscriberCircuitOpened, domain.CircuitOpenedEvent{
+			SubscriberID: subscriberID,
+			Failures:     failures,
+			OpenedAt:     openedAt,
+		})
+	})
+	replayer := deadletter.NewReplayer(db, fanout, circuitBreaker, redisStore.Client(), deadletter.ReplayConfig{
+		HealthyPeriod: cfg.DLQAutoReplayHealthyPeriod,
+		Rate:          cfg.DLQAutoReplayRate,
+	}, logger)
+	circuitBreaker.OnClose(replayer.Schedule)
+	go replayer.Start(ctx)
 	rateLimiter := engine.NewRateLimiter(redisStore.Client(), logger)
+	rateLimiter.SetDefaultLimit(cfg.RateLimitDefaultPerSecond)
+	quota := engine.NewDeliveryQuota(redisStore.Client(), logger)
+	ingestLimiter := api.NewIngestLimiter(rateLimiter, cfg.IngestMaxPayloadBytes, cfg.IngestRateLimitPerSecond)
+	cleanup := engine.NewSubscriberCleanup(db, queue, circuitBreaker, rateLimiter, logger)
 
-	// Start WebSocket hub for real-time dashboard
-	hub := ws.NewHub(logger)
+	// Start WebSocket hub for real-time dashboard. The relay shares events
+	// with hubs on other instances.
+	hub := ws.NewHub(
+		ws.NewEventStream(redisStore.Client(), 1000),
+		api.NewSnapshotFunc(db, fanout, circuitBreaker),
+		logger,
+	)
+	hub.AllowOrigins(cfg.WSAllowedOrigins)
+	relay := ws.NewRelay(redisStore.Client(), cluster.InstanceID(), logger)
+	hub.UseRelay(relay)
 	go hub.Run()
+	go relay.Start(ctx, hub)
 	logger.Info("WebSocket hub started")
 
 	// Start worker pool and dispatcher
-	deliverer := worker.NewDeliverer(pgStore, redisStore.Client(), circuitBreaker, rateLimiter, hub, logger)
-	pool := worker.NewPool(cfg.NumWorkers, deliverer, logger)
+	redactor, err := worker.NewRedactor(cfg.DeliveryRedact, cfg.DeliveryRedactPattern)
+	if err != nil {
+		logger.Error("invalid response redaction config", "error", err)
+		os.Exit(1)
+	}
+	recorder := worker.NewAttemptRecorder(db, worker.RecorderConfig{
+		QueueSize:     cfg.DeliveryRecordQueueSize,
+		BatchSize:     cfg.DeliveryRecordBatchSize,
+		FlushInterval: cfg.DeliveryRecordFlushInterval,
+	}, logger)
+	sinks, err := attemptSinks(cfg)
+	if err != nil {
+		logger.Error("invalid attempt sink config", "error", err)
+		os.Exit(1)
+	}
+	var attemptMirror *sink.Mirror
+	if len(sinks) > 0 {
+		attemptMirror = sink.NewMirror(sinks, sink.Config{
+			BufferSize:    cfg.AttemptSinkBufferSize,
+			BatchSize:     cfg.AttemptSinkBatchSize,
+			FlushInterval: cfg.AttemptSinkFlushInterval,
+		}, logger)
+		attemptMirror.Start()
+		recorder.SetMirror(attemptMirror)
+	}
+	recorder.Start()
+	var deliveryLocalAddr net.IP
+	if cfg.DeliveryLocalAddr != "" {
+		deliveryLocalAddr, err = worker.ResolveLocalAddr(cfg.DeliveryLocalAddr)
+		if err != nil {
+			logger.Error("invalid DELIVERY_LOCAL_ADDR", "error", err)
+			os.Exit(1)
+		}
+	}
+	var deliveryProxy *url.URL
+	if cfg.DeliveryProxyURL != "" {
+		deliveryProxy, _ = url.Parse(cfg.DeliveryProxyURL) // validated by config.Load
+	}
+	deliverer := worker.NewDeliverer(db, queue, circuitBreaker, rateLimiter, hub, worker.DelivererConfig{
+		Transport: worker.TransportConfig{
+			MaxIdleConnsPerHost: cfg.DeliveryMaxIdleConnsPerHost,
+			MaxConnsPerHost:     cfg.DeliveryMaxConnsPerHost,
+			IdleConnTimeout:     cfg.DeliveryIdleConnTimeout,
+			TLSHandshakeTimeout: cfg.DeliveryTLSHandshakeTimeout,
+			HTTP2:               cfg.DeliveryHTTP2,
+			ProxyURL:            deliveryProxy,
+			LocalAddr:           deliveryLocalAddr,
+			DNSCacheTTL:         cfg.DeliveryDNSCacheTTL,
+			BlockPrivateIPs:     cfg.DeliveryBlockPrivateIPs,
+			DialTimeout:         cfg.DeliveryDialTimeout,
+			IPPreference:        cfg.DeliveryIPPreference,
+			FallbackDelay:       cfg.DeliveryFallbackDelay,
+		},
+		RedirectPolicy: cfg.DeliveryRedirectPolicy,
+		Timeout:        cfg.DeliveryTimeout,
+		Retry: worker.RetryConfig{
+			BaseDelay:        cfg.RetryBaseDelay,
+			MaxDelay:         cfg.RetryMaxDelay,
+			Jitter:           cfg.RetryJitter,
+			Strategy:         cfg.RetryJitterStrategy,
+			DurationMaxDelay: cfg.RetryDurationMaxDelay,
+		},
+		GzipThresholdBytes: cfg.DeliveryGzipThresholdBytes,
+		PayloadCacheSize:   cfg.DeliveryPayloadCacheSize,
+		CaptureHeaders:     cfg.DeliveryCaptureHeaders,
+		ResponseBodyLimit:  cfg.DeliveryResponseBodyLimit,
+		Redactor:           redactor,
+		Recorder:           recorder,
+		LogSampling: &worker.LogSampling{
+			Success: cfg.DeliveryLogSuccessSampleRate,
+			Failure: cfg.DeliveryLogFailureSampleRate,
+		},
+		SystemEvents: fanout,
+		Receipts:     receipts,
+		Converters:   payloadConverters(),
+		Chaos:        chaosInjector,
+		Quota:        quota,
+		Clock:        clock,
+		Objects:      payloadObjects,
+	}, logger)
+	pool := worker.NewPool(cfg.WorkerPoolMin, deliverer, queue, logger)
+	pool.SetBatcher(worker.NewBatcher(deliverer, queue, logger))
 	pool.Start(ctx)
 
-	dispatcher := worker.NewDispatcher(redisStore.Client(), pool, logger)
-	go dispatcher.Start(ctx)
+	// The autoscaler holds the pool at its size while the bounds are equal,
+	// and always runs so a config reload can change them
+	autoscaler := worker.NewAutoscaler(pool, queue, worker.AutoscalerConfig{
+		MinWorkers: cfg.WorkerPoolMin,
+		MaxWorkers: cfg.WorkerPoolMax,
+		Interval:   cfg.WorkerAutoscaleInterval,
+		DrainTime:  cfg.WorkerAutoscaleDrain,
+		Clock:      clock,
+	}, logger)
+	go autoscaler.Start(ctx)
+
+	dispatcher := worker.NewDispatcher(queue, pool, logger)
+	dispatcher.SetBatchSize(cfg.DispatcherBatchSize)
+	dispatcher.SetChaos(chaosInjector)
+	dispatcher.SetClock(clock)
+	dispatcherDone := make(chan struct{})
+	go func() {
+		dispatcher.Start(ctx)
+		close(dispatcherDone)
+	}()
+
+	// Apply operational settings from the config on SIGHUP or through the API
+	reloader := reload.New(*configPath, cluster.InstanceID(), cfg, reload.Targets{
+		Autoscaler:     autoscaler,
+		Dispatcher:     dispatcher,
+		RateLimiter:    rateLimiter,
+		CircuitBreaker: circuitBreaker,
+		Ingest:         ingestLimiter,
+		Deliverer:      deliverer,
+		FanOut:         fanout,
+	}, logger)
+	go func() {
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+		for range hup {
+			result, err := reloader.Reload()
+			if err != nil {
+				logger.Error("config reload failed, keeping the running configuration", "error", err)
+				continue
+			}
+			details, _ := json.Marshal(result)
+			err = db.InsertAuditEntry(ctx, &domain.AuditEntry{
+				Actor:      domain.SystemActor,
+				Action:     domain.AuditConfigReload,
+				EntityType: domain.AuditEntityConfig,
+				EntityID:   result.InstanceID,
+				Details:    details,
+			})
+			if err != nil {
+				logger.Error("failed to write audit entry", "error", err, "action", domain.AuditConfigReload)
+			}
+		}
+	}()
+
+	// Start retention archiver (optional)
+	if cfg.RetentionDays > 0 && isPostgres {
+		archiver := archive.NewArchiver(pgStore, archiveS3, cfg.ArchivePrefix, time.Duration(cfg.RetentionDays)*24*time.Hour, logger)
+		go archiver.Start(ctx)
+	}
+	if cfg.UsageExportEnabled {
+		go archive.NewUsageExporter(db, archiveS3, cfg.ArchivePrefix, logger).Start(ctx)
+	}
+
+	// Start dead letter expiry (optional)
+	if cfg.DLQExpiryDays > 0 {
+		monitor := deadletter.NewMonitor(db, deadletter.MonitorConfig{
+			Expiry: time.Duration(cfg.DLQExpiryDays) * 24 * time.Hour,
+		}, logger)
+		go monitor.Start(ctx)
+	}
+
+	// Start operational alerting (optional)
+	if cfg.AlertsEnabled() {
+		var notifiers []alerting.Notifier
+		if cfg.AlertSlackWebhookURL != "" {
+			notifiers = append(notifiers, alerting.NewSlackNotifier(cfg.AlertSlackWebhookURL))
+		}
+		if cfg.AlertPagerDutyRoutingKey != "" {
+			notifiers = append(notifiers, alerting.NewPagerDutyNotifier(cfg.AlertPagerDutyRoutingKey))
+		}
+		if len(cfg.AlertEmailTo) > 0 {
+			notifiers = append(notifiers, alerting.NewEmailNotifier(alerting.SMTPConfig{
+				Host:     cfg.SMTPHost,
+				Port:     cfg.SMTPPort,
+				Username: cfg.SMTPUsername,
+				Password: cfg.SMTPPassword,
+				From:     cfg.SMTPFrom,
+				To:       cfg.AlertEmailTo,
+			}))
+		}
+		alertMonitor := alerting.NewMonitor(db, queue, circuitBreaker, redisStore.Client(), notifiers, alerting.Config{
+			QueueDepthThreshold:    cfg.AlertQueueDepthThreshold,
+			QueueDepthDuration:     cfg.AlertQueueDepthDuration,
+			DLQRateThreshold:       cfg.AlertDLQRateThreshold,
+			DLQRateWindow:          cfg.AlertDLQRateWindow,
+			SubscriberDLQThreshold: cfg.AlertSubscriberDLQThreshold,
+			SubscriberDLQWindow:    cfg.AlertSubscriberDLQWindow,
+			CircuitOpenDuration:    cfg.AlertCircuitOpenDuration,
+			SuccessRateThreshold:   cfg.AlertSuccessRateThreshold,
+			SuccessRateWindow:      cfg.AlertSuccessRateWindow,
+			SuccessRateMinAttempts: cfg.AlertSuccessRateMinAttempts,
+			Interval:               cfg.AlertInterval,
+		}, logger)
+		go alertMonitor.Start(ctx)
+	}
 
 	// Load dashboard static files (if available)
 	var dashboardFS fs.FS
@@ -83,11 +472,48 @@ func main() {
 	}
 
 	// Setup router
-	router := api.NewRouter(pgStore, fanout, circuitBreaker, hub, dashboardFS)
+	auth := api.NewAuthenticator(db, cfg.AuthEnabled, cfg.AdminAPIKey)
+	if cfg.AuthEnabled {
+		logger.Info("api key authentication enabled for dashboard endpoints")
+	}
+	health := api.NewHealthChecker(
+		api.ReadinessCheck{Name: "database", Check: db.Ping},
+		api.ReadinessCheck{Name: "redis", Check: redisStore.Ping},
+		api.ReadinessCheck{Name: "migrations", Check: func(ctx context.Context) error {
+			pending, err := db.PendingMigrations(ctx, migrations.FS)
+			if err != nil {
+				return err
+			}
+			if len(pending) > 0 {
+				return fmt.Errorf("%d pending, next is %s", len(pending), pending[0])
+			}
+			return nil
+		}},
+		api.ReadinessCheck{Name: "dispatcher", Check: func(ctx context.Context) error {
+			if !dispatcher.Running() {
+				return errors.New("dispatcher is not running")
+			}
+			return nil
+		}},
+	)
+	router := api.NewRouter(db, fanout, circuitBreaker, quota, cleanup, deliverer, hub, pool, dispatcher, health, auth, ingestLimiter, reloader, chaosInjector, archiveS3, cfg.EgressIPs, dashboardFS)
+
+	// Serve HTTPS directly when a certificate or autocert domains are
+	// configured
+	tlsConfig, certManager, err := newTLSConfig(cfg)
+	if err != nil {
+		logger.Error("failed to configure tls", "error", err)
+		os.Exit(1)
+	}
+	tlsEnabled := tlsConfig != nil
+	if certManager != nil && cfg.TLSAutocertHTTPPort != "" {
+		go serveACMEChallenges(certManager, cfg.TLSAutocertHTTPPort, logger)
+	}
 
 	server := &http.Server{
 		Addr:         ":" + cfg.Port,
 		Handler:      router,
+		TLSConfig:    tlsConfig,
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
@@ -95,13 +521,43 @@ func main() {
 
 	// Start server in a goroutine
 	go func() {
-		logger.Info("server starting", "port", cfg.Port)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		logger.Info("server starting", "port", cfg.Port, "tls", tlsEnabled)
+		var err error
+		if tlsEnabled {
+			err = server.ListenAndServeTLS("", "")
+		} else {
+			err = server.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			logger.Error("server error", "error", err)
 			os.Exit(1)
 		}
 	}()
 
+	// Start the gRPC API on its own port (optional)
+	var grpcServer *grpc.Server
+	if cfg.GRPCPort != "" {
+		lis, err := net.Listen("tcp", ":"+cfg.GRPCPort)
+		if err != nil {
+			logger.Error("failed to listen for grpc", "error", err)
+			os.Exit(1)
+		}
+		// Messages may carry a payload of up to the ingestion limit, beyond
+		// gRPC's default of 4 MiB
+		opts := []grpc.ServerOption{grpc.MaxRecvMsgSize(max(4<<20, cfg.IngestMaxPayloadBytes+64<<10))}
+		if tlsEnabled {
+			opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig.Clone())))
+		}
+		grpcServer = grpcapi.NewGRPCServer(db, fanout, cleanup, opts...)
+		go func() {
+			logger.Info("grpc server starting", "port", cfg.GRPCPort, "tls", tlsEnabled)
+			if err := grpcServer.Serve(lis); err != nil {
+				logger.Error("grpc server error", "error", err)
+				os.Exit(1)
+			}
+		}()
+	}
+
 	// Wait for interrupt signal for graceful shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -109,15 +565,48 @@ func main() {
 
 	logger.Info("shutting down server...")
 
-	// Cancel context to stop dispatcher and workers
+	// Cancel context to stop the dispatcher claiming new jobs
 	cancel()
+	<-dispatcherDone
 
-	// Stop worker pool (waits for in-flight deliveries)
+	// Let in-flight deliveries finish and return buffered jobs to the queue
+	pool.Drain()
 	pool.Stop()
+	deliverer.Close()
+
+	// Write the attempts those deliveries recorded
+	recorder.Stop()
+	if attemptMirror != nil {
+		attemptMirror.Stop()
+	}
+
+	// Hand any jobs this instance claimed but never delivered back to the queue
+	if cfg.QueueMode != "memory" {
+		if n, err := cluster.Release(context.Background()); err != nil {
+			logger.Error("failed to release claimed jobs", "error", err)
+		} else if n > 0 {
+			logger.Info("released claimed jobs", "count", n)
+		}
+	} else if n, _ := queue.Depth(context.Background()); n > 0 {
+		logger.Warn("discarding in-memory delivery queue", "jobs", n)
+	}
 
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer shutdownCancel()
 
+	if grpcServer != nil {
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-shutdownCtx.Done():
+			grpcServer.Stop()
+		}
+	}
+
 	if err := server.Shutdown(shutdownCtx); err != nil {
 		logger.Error("server forced to shutdown", "error", err)
 		os.Exit(1)
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"github.com/Priya8975/webhook-delivery-system/internal/config"
+	"github.com/Priya8975/webhook-delivery-system/internal/sink"
+)
+
+// attemptSinks returns the sinks delivery attempts are mirrored to, none
+// unless one is configured.
+func attemptSinks(cfg *config.Config) ([]sink.Sink, error) {
+	var sinks []sink.Sink
+	if len(cfg.AttemptSinkKafkaBrokers) > 0 {
+		sinks = append(sinks, sink.NewKafkaSink(cfg.AttemptSinkKafkaBrokers, cfg.AttemptSinkKafkaTopic))
+	}
+	if cfg.AttemptSinkElasticsearchURL != "" {
+		sinks = append(sinks, sink.NewElasticsearchSink(cfg.AttemptSinkElasticsearchURL, cfg.AttemptSinkElasticsearchIndex, cfg.AttemptSinkElasticsearchAPIKey))
+	}
+	if cfg.AttemptSinkClickHouseURL != "" {
+		s, err := sink.NewClickHouseSink(cfg.AttemptSinkClickHouseURL, cfg.AttemptSinkClickHouseTable)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, s)
+	}
+	return sinks, nil
+}
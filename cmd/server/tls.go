@@ -0,0 +1,60 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"golang.org/x/crypto/acme/autocert"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/config"
+)
+
+// newTLSConfig returns the TLS configuration shared by the HTTP and gRPC
+// listeners, or nil when TLS is off. Certificates come from TLS_CERT_FILE and
+// TLS_KEY_FILE, or from Let's Encrypt for TLS_AUTOCERT_DOMAINS, in which case
+// the returned manager also answers ACME HTTP-01 challenges. Both offer HTTP/2
+// through ALPN; WebSocket clients still upgrade over HTTP/1.1.
+func newTLSConfig(cfg *config.Config) (*tls.Config, *autocert.Manager, error) {
+	switch {
+	case cfg.TLSCertFile != "":
+		cert, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("loading tls certificate: %w", err)
+		}
+		return &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS12,
+			NextProtos:   []string{"h2", "http/1.1"},
+		}, nil, nil
+
+	case len(cfg.TLSAutocertDomains) > 0:
+		m := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(cfg.TLSAutocertDomains...),
+			Cache:      autocert.DirCache(cfg.TLSAutocertCacheDir),
+			Email:      cfg.TLSAutocertEmail,
+		}
+		tlsConfig := m.TLSConfig()
+		tlsConfig.MinVersion = tls.VersionTLS12
+		return tlsConfig, m, nil
+	}
+	return nil, nil, nil
+}
+
+// serveACMEChallenges answers Let's Encrypt HTTP-01 challenges on port and
+// redirects every other request to HTTPS. Challenges can also be answered
+// over TLS on the API port, so this listener is optional.
+func serveACMEChallenges(m *autocert.Manager, port string, logger *slog.Logger) {
+	server := &http.Server{
+		Addr:              ":" + port,
+		Handler:           m.HTTPHandler(nil),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	logger.Info("acme challenge server starting", "port", port)
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		logger.Error("acme challenge server error", "error", err)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/config"
+)
+
+// writeSelfSigned writes a certificate and key for 127.0.0.1 and returns
+// their paths.
+func writeSelfSigned(t *testing.T) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	certFile = filepath.Join(dir, "tls.crt")
+	keyFile = filepath.Join(dir, "tls.key")
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600)
+	return certFile, keyFile
+}
+
+func TestNewTLSConfig_ServesHTTP2(t *testing.T) {
+	certFile, keyFile := writeSelfSigned(t)
+	tlsConfig, manager, err := newTLSConfig(&config.Config{TLSCertFile: certFile, TLSKeyFile: keyFile})
+	if err != nil {
+		t.Fatalf("newTLSConfig: %v", err)
+	}
+	if manager != nil {
+		t.Error("expected no autocert manager for a certificate file")
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(r.Proto))
+		}),
+		TLSConfig: tlsConfig,
+	}
+	go server.ServeTLS(lis, "", "")
+	t.Cleanup(func() { server.Close() })
+
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+		ForceAttemptHTTP2: true,
+	}}
+	resp, err := client.Get("https://" + lis.Addr().String())
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	resp.Body.Close()
+	if resp.ProtoMajor != 2 {
+		t.Errorf("expected HTTP/2, got %s", resp.Proto)
+	}
+}
+
+func TestNewTLSConfig_Disabled(t *testing.T) {
+	tlsConfig, manager, err := newTLSConfig(&config.Config{})
+	if tlsConfig != nil || manager != nil || err != nil {
+		t.Errorf("expected TLS to be off, got %v %v %v", tlsConfig, manager, err)
+	}
+}
+
+func TestNewTLSConfig_Autocert(t *testing.T) {
+	tlsConfig, manager, err := newTLSConfig(&config.Config{
+		TLSAutocertDomains:  []string{"hooks.example.com"},
+		TLSAutocertCacheDir: t.TempDir(),
+	})
+	if err != nil || manager == nil || tlsConfig.GetCertificate == nil {
+		t.Fatalf("expected an autocert manager, got %v %v", manager, err)
+	}
+	// Certificates are only requested for the configured domains
+	if err := manager.HostPolicy(t.Context(), "other.example.com"); err == nil {
+		t.Error("expected other hosts to be refused")
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+	"github.com/spf13/cobra"
+)
+
+// subscriberHealth mirrors the entries returned by /subscribers-health and
+// /subscribers/{id}/health.
+type subscriberHealth struct {
+	ID             string                     `json:"id"`
+	SubscriberID   string                     `json:"subscriber_id"`
+	Name           string                     `json:"name"`
+	EndpointURL    string                     `json:"endpoint_url"`
+	IsActive       bool                       `json:"is_active"`
+	CircuitBreaker engine.CircuitBreakerState `json:"circuit_breaker"`
+}
+
+func newBreakersCmd(opts *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "breakers [subscriber-id]",
+		Aliases: []string{"breaker", "cb"},
+		Short:   "Show circuit breaker states",
+		Long:    "Show the circuit breaker state of every subscriber, or of one subscriber when an ID is given.",
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var health []subscriberHealth
+			var data []byte
+			var err error
+
+			if len(args) == 1 {
+				var one subscriberHealth
+				data, err = opts.client().get(cmd.Context(), "/subscribers/"+args[0]+"/health", nil, &one)
+				one.ID = one.SubscriberID
+				health = append(health, one)
+			} else {
+				data, err = opts.client().get(cmd.Context(), "/subscribers-health", nil, &health)
+			}
+			if err != nil {
+				return err
+			}
+			if opts.jsonOutput() {
+				return printJSON(opts.out, data)
+			}
+
+			tw := newTable(opts.out, "SUBSCRIBER", "NAME", "ACTIVE", "STATE", "FAILURES", "LAST FAILURE", "OPENED")
+			for _, h := range health {
+				lastFailed := h.CircuitBreaker.LastFailedAt
+				if lastFailed == "" {
+					lastFailed = "-"
+				}
+				openedAt := h.CircuitBreaker.OpenedAt
+				if openedAt == "" {
+					openedAt = "-"
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%t\t%s\t%d\t%s\t%s\n",
+					h.ID, h.Name, h.IsActive, h.CircuitBreaker.State, h.CircuitBreaker.Failures, lastFailed, openedAt)
+			}
+			return tw.Flush()
+		},
+	}
+	return cmd
+}
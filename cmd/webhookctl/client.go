@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// apiClient is a thin JSON client for the /api/v1 management API.
+type apiClient struct {
+	baseURL string
+	apiKey  string
+	http    *http.Client
+}
+
+func newAPIClient(baseURL, apiKey string) *apiClient {
+	return &apiClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		http:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// apiError is returned for non-2xx responses.
+type apiError struct {
+	Status  int
+	Message string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("server returned %d: %s", e.Status, e.Message)
+}
+
+// do sends a request and returns the raw response body. body, if not nil, is
+// encoded as JSON.
+func (c *apiClient) do(ctx context.Context, method, path string, query url.Values, body interface{}) (json.RawMessage, error) {
+	resp, err := c.open(ctx, c.http, method, path, query, body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+	return data, nil
+}
+
+// open sends a request and returns the response for the caller to read. Non-2xx
+// responses are turned into an *apiError.
+func (c *apiClient) open(ctx context.Context, hc *http.Client, method, path string, query url.Values, body interface{}) (*http.Response, error) {
+	if body == nil {
+		return c.send(ctx, hc, method, path, query, "", nil)
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("encoding request: %w", err)
+	}
+	return c.send(ctx, hc, method, path, query, "application/json", bytes.NewReader(data))
+}
+
+// send is open with a body of the given content type that is sent as is.
+func (c *apiClient) send(ctx context.Context, hc *http.Client, method, path string, query url.Values, contentType string, body io.Reader) (*http.Response, error) {
+	u := c.baseURL + "/api/v1" + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, fmt.Errorf("building request: %w", err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", method, path, err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+
+		var e struct {
+			Error string `json:"error"`
+		}
+		msg := strings.TrimSpace(string(data))
+		if json.Unmarshal(data, &e) == nil && e.Error != "" {
+			msg = e.Error
+		}
+		return nil, &apiError{Status: resp.StatusCode, Message: msg}
+	}
+	return resp, nil
+}
+
+// download streams the response to path into w. Exports can take longer than
+// the client timeout, so none is applied.
+func (c *apiClient) download(ctx context.Context, path string, query url.Values, w io.Writer) error {
+	resp, err := c.open(ctx, &http.Client{}, http.MethodGet, path, query, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("downloading %s: %w", path, err)
+	}
+	return nil
+}
+
+// upload posts body to path as is and returns the raw response body.
+func (c *apiClient) upload(ctx context.Context, path string, query url.Values, contentType string, body io.Reader) (json.RawMessage, error) {
+	resp, err := c.send(ctx, &http.Client{}, http.MethodPost, path, query, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+	return data, nil
+}
+
+// get fetches path and decodes the response into out.
+func (c *apiClient) get(ctx context.Context, path string, query url.Values, out interface{}) (json.RawMessage, error) {
+	data, err := c.do(ctx, http.MethodGet, path, query, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := decode(data, out); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func decode(data json.RawMessage, out interface{}) error {
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	return nil
+}
+
+// printJSON pretty-prints a raw JSON response.
+func printJSON(w io.Writer, data json.RawMessage) error {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "", "  "); err != nil {
+		_, err = w.Write(data)
+		return err
+	}
+	buf.WriteByte('\n')
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// newTable returns a tabwriter that prints the given header row.
+func newTable(w io.Writer, headers ...string) *tabwriter.Writer {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	return tw
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
+}
+
+func formatInt(n *int) string {
+	if n == nil {
+		return "-"
+	}
+	return fmt.Sprint(*n)
+}
+
+func formatString(s *string) string {
+	if s == nil || *s == "" {
+		return "-"
+	}
+	return *s
+}
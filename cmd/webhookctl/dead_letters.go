@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+func newDeadLettersCmd(opts *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "dlq",
+		Aliases: []string{"dead-letters"},
+		Short:   "List, replay, and resolve dead letters",
+	}
+	cmd.AddCommand(
+		newDeadLettersListCmd(opts),
+		newDeadLettersExportCmd(opts),
+		newDeadLettersSummaryCmd(opts),
+		newDeadLettersReplayCmd(opts),
+		newDeadLettersResolveCmd(opts),
+	)
+	return cmd
+}
+
+func newDeadLettersListCmd(opts *options) *cobra.Command {
+	var subscriberID string
+	var resolved bool
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List dead letters",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			query := url.Values{}
+			setIfNotEmpty(query, "subscriber_id", subscriberID)
+			query.Set("resolved", strconv.FormatBool(resolved))
+			query.Set("limit", strconv.Itoa(limit))
+
+			var letters []domain.DeadLetter
+			data, err := opts.client().get(cmd.Context(), "/dead-letters", query, &letters)
+			if err != nil {
+				return err
+			}
+			if opts.jsonOutput() {
+				return printJSON(opts.out, data)
+			}
+
+			tw := newTable(opts.out, "ID", "EVENT", "SUBSCRIBER", "ATTEMPTS", "HTTP", "REASON", "ERROR", "CREATED", "RESOLVED")
+			for _, dl := range letters {
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\n",
+					dl.ID, dl.EventID, dl.SubscriberID, dl.TotalAttempts,
+					formatInt(dl.LastHTTPStatus), formatString(dl.FailureReason), truncate(formatString(dl.LastError), 60),
+					formatTime(&dl.CreatedAt), formatTime(dl.ResolvedAt))
+			}
+			return tw.Flush()
+		},
+	}
+
+	cmd.Flags().StringVar(&subscriberID, "subscriber", "", "only dead letters for this subscriber ID")
+	cmd.Flags().BoolVar(&resolved, "resolved", false, "list resolved instead of open dead letters")
+	cmd.Flags().IntVar(&limit, "limit", 50, "maximum number of dead letters")
+	return cmd
+}
+
+func newDeadLettersExportCmd(opts *options) *cobra.Command {
+	var subscriberID, resolved, since, until, format, output string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export dead letters as NDJSON or CSV",
+		Long:  "Export dead letters as NDJSON or CSV. Open and resolved entries are both exported unless --resolved is given.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			query := url.Values{}
+			setIfNotEmpty(query, "subscriber_id", subscriberID)
+			setIfNotEmpty(query, "resolved", resolved)
+			setIfNotEmpty(query, "since", since)
+			setIfNotEmpty(query, "until", until)
+			setIfNotEmpty(query, "format", format)
+			return exportTo(cmd, opts, "/dead-letters/export", query, output)
+		},
+	}
+
+	cmd.Flags().StringVar(&subscriberID, "subscriber", "", "only dead letters for this subscriber ID")
+	cmd.Flags().StringVar(&resolved, "resolved", "", "only resolved (true) or open (false) dead letters")
+	addExportFlags(cmd, &since, &until, &format, &output)
+	return cmd
+}
+
+func newDeadLettersSummaryCmd(opts *options) *cobra.Command {
+	var subscriberID string
+
+	cmd := &cobra.Command{
+		Use:   "summary",
+		Short: "Count open dead letters by subscriber and failure",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			query := url.Values{}
+			setIfNotEmpty(query, "subscriber_id", subscriberID)
+
+			var summary struct {
+				Total  int64                    `json:"total"`
+				Groups []domain.DeadLetterGroup `json:"groups"`
+			}
+			data, err := opts.client().get(cmd.Context(), "/dead-letters/summary", query, &summary)
+			if err != nil {
+				return err
+			}
+			if opts.jsonOutput() {
+				return printJSON(opts.out, data)
+			}
+
+			fmt.Fprintf(opts.out, "%d open dead letters\n\n", summary.Total)
+			tw := newTable(opts.out, "SUBSCRIBER", "NAME", "REASON", "HTTP", "COUNT", "FIRST", "LAST")
+			for _, g := range summary.Groups {
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+					g.SubscriberID, g.SubscriberName, formatString(g.FailureReason), formatInt(g.LastHTTPStatus),
+					g.Count, formatTime(&g.FirstAt), formatTime(&g.LastAt))
+			}
+			return tw.Flush()
+		},
+	}
+
+	cmd.Flags().StringVar(&subscriberID, "subscriber", "", "only dead letters for this subscriber ID")
+	return cmd
+}
+
+func newDeadLettersReplayCmd(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "replay <id>...",
+		Short: "Queue dead-lettered deliveries again",
+		Long: `Queue dead-lettered deliveries again. Each event is redelivered to its
+subscriber from the first attempt and the dead letter is marked resolved.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c := opts.client()
+			var failed int
+			for _, id := range args {
+				if _, err := c.do(cmd.Context(), http.MethodPost, "/dead-letters/"+id+"/replay", nil, nil); err != nil {
+					fmt.Fprintf(cmd.ErrOrStderr(), "%s: %v\n", id, err)
+					failed++
+					continue
+				}
+				fmt.Fprintf(opts.out, "Replayed %s\n", id)
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d of %d replays failed", failed, len(args))
+			}
+			return nil
+		},
+	}
+}
+
+func newDeadLettersResolveCmd(opts *options) *cobra.Command {
+	var resolvedBy string
+
+	cmd := &cobra.Command{
+		Use:   "resolve <id>",
+		Short: "Mark a dead letter as resolved without redelivering it",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			body := map[string]string{"resolved_by": resolvedBy}
+			if _, err := opts.client().do(cmd.Context(), http.MethodPost, "/dead-letters/"+args[0]+"/resolve", nil, body); err != nil {
+				return err
+			}
+			fmt.Fprintf(opts.out, "Resolved %s\n", args[0])
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&resolvedBy, "by", "webhookctl", "who resolved the dead letter")
+	return cmd
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-3] + "..."
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	ws "github.com/Priya8975/webhook-delivery-system/internal/websocket"
+	"github.com/spf13/cobra"
+)
+
+func newDeliveriesCmd(opts *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "deliveries",
+		Aliases: []string{"delivery"},
+		Short:   "Inspect delivery attempts",
+	}
+	cmd.AddCommand(
+		newDeliveriesListCmd(opts),
+		newDeliveriesExportCmd(opts),
+		newDeliveriesTailCmd(opts),
+	)
+	return cmd
+}
+
+func newDeliveriesListCmd(opts *options) *cobra.Command {
+	var eventID, subscriberID, status, reason string
+	var responseHeaders []string
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List recent delivery attempts",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			query := url.Values{}
+			setIfNotEmpty(query, "event_id", eventID)
+			setIfNotEmpty(query, "subscriber_id", subscriberID)
+			setIfNotEmpty(query, "status", status)
+			setIfNotEmpty(query, "failure_reason", reason)
+			for _, h := range responseHeaders {
+				query.Add("response_header", h)
+			}
+			query.Set("limit", strconv.Itoa(limit))
+
+			var attempts []domain.DeliveryAttempt
+			data, err := opts.client().get(cmd.Context(), "/deliveries", query, &attempts)
+			if err != nil {
+				return err
+			}
+			if opts.jsonOutput() {
+				return printJSON(opts.out, data)
+			}
+
+			tw := newTable(opts.out, "ID", "EVENT", "SUBSCRIBER", "ATTEMPT", "STATUS", "HTTP", "REASON", "MS", "CREATED")
+			for _, a := range attempts {
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\n",
+					a.ID, a.EventID, a.SubscriberID, a.AttemptNumber, a.Status,
+					formatInt(a.HTTPStatusCode), formatString(a.FailureReason), formatInt(a.ResponseTimeMs), formatTime(&a.CreatedAt))
+			}
+			return tw.Flush()
+		},
+	}
+
+	cmd.Flags().StringVar(&eventID, "event", "", "only attempts for this event ID")
+	cmd.Flags().StringVar(&subscriberID, "subscriber", "", "only attempts for this subscriber ID")
+	cmd.Flags().StringVar(&status, "status", "", "only attempts with this status (success, failed, retrying)")
+	cmd.Flags().StringVar(&reason, "reason", "", "only attempts that failed for this reason (e.g. dns_error, http_5xx)")
+	cmd.Flags().StringArrayVar(&responseHeaders, "response-header", nil, "only attempts whose response had this header, as Name:value (repeatable)")
+	cmd.Flags().IntVar(&limit, "limit", 50, "maximum number of attempts")
+	return cmd
+}
+
+func newDeliveriesExportCmd(opts *options) *cobra.Command {
+	var eventID, subscriberID, status, reason, since, until, format, output string
+	var responseHeaders []string
+
+	cmd := &cobra.Command{
+		Use:     "export",
+		Short:   "Export every matching delivery attempt as NDJSON or CSV",
+		Example: `  webhookctl deliveries export --subscriber <id> --since 2024-05-01T09:00:00Z --until 2024-05-01T12:00:00Z --format csv --out-file evidence.csv`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			query := url.Values{}
+			setIfNotEmpty(query, "event_id", eventID)
+			setIfNotEmpty(query, "subscriber_id", subscriberID)
+			setIfNotEmpty(query, "status", status)
+			setIfNotEmpty(query, "failure_reason", reason)
+			for _, h := range responseHeaders {
+				query.Add("response_header", h)
+			}
+			setIfNotEmpty(query, "since", since)
+			setIfNotEmpty(query, "until", until)
+			setIfNotEmpty(query, "format", format)
+			return exportTo(cmd, opts, "/deliveries/export", query, output)
+		},
+	}
+
+	cmd.Flags().StringVar(&eventID, "event", "", "only attempts for this event ID")
+	cmd.Flags().StringVar(&subscriberID, "subscriber", "", "only attempts for this subscriber ID")
+	cmd.Flags().StringVar(&status, "status", "", "only attempts with this status (success, failed, retrying)")
+	cmd.Flags().StringVar(&reason, "reason", "", "only attempts that failed for this reason (e.g. dns_error, http_5xx)")
+	cmd.Flags().StringArrayVar(&responseHeaders, "response-header", nil, "only attempts whose response had this header, as Name:value (repeatable)")
+	addExportFlags(cmd, &since, &until, &format, &output)
+	return cmd
+}
+
+func newDeliveriesTailCmd(opts *options) *cobra.Command {
+	var subscriberID string
+	var types []string
+
+	cmd := &cobra.Command{
+		Use:   "tail",
+		Short: "Stream delivery events as they happen",
+		Long: `Stream delivery events as they happen, like tail -f. Press Ctrl-C to stop.
+
+Events come from the server's /api/v1/stream endpoint and include every
+delivery attempt handled by any server instance.`,
+		Example: `  webhookctl deliveries tail
+  webhookctl deliveries tail --subscriber <id> --types delivery_failed,delivery_dlq`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			query := url.Values{}
+			setIfNotEmpty(query, "subscriber_id", subscriberID)
+			setIfNotEmpty(query, "types", strings.Join(types, ","))
+
+			c := opts.client()
+			// The stream stays open indefinitely, so don't apply the client timeout
+			resp, err := c.open(cmd.Context(), &http.Client{}, http.MethodGet, "/stream", query, nil)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+
+			scanner := bufio.NewScanner(resp.Body)
+			scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+			for scanner.Scan() {
+				data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+				if !ok {
+					continue
+				}
+				if opts.jsonOutput() {
+					fmt.Fprintln(opts.out, data)
+					continue
+				}
+
+				var e ws.DeliveryEvent
+				if err := json.Unmarshal([]byte(data), &e); err != nil {
+					continue
+				}
+				fmt.Fprintln(opts.out, formatDeliveryEvent(e))
+			}
+
+			if cmd.Context().Err() != nil {
+				return nil
+			}
+			if err := scanner.Err(); err != nil {
+				return fmt.Errorf("reading stream: %w", err)
+			}
+			return fmt.Errorf("stream closed by server")
+		},
+	}
+
+	cmd.Flags().StringVar(&subscriberID, "subscriber", "", "only events for this subscriber ID")
+	cmd.Flags().StringSliceVar(&types, "types", nil, "only these event types (delivery_success, delivery_failed, delivery_retrying, delivery_dlq, delivery_simulated)")
+	return cmd
+}
+
+// formatDeliveryEvent renders one streamed event as a single log-style line.
+// Lines are printed as they arrive, so columns are padded to fixed widths
+// rather than aligned with a tabwriter.
+func formatDeliveryEvent(e ws.DeliveryEvent) string {
+	line := fmt.Sprintf("%s  %-17s  event=%s subscriber=%s attempt=%d http=%s ms=%d",
+		e.Timestamp.Local().Format("15:04:05"), e.Type, e.EventID, e.SubscriberID,
+		e.Attempt, formatInt(e.StatusCode), e.ResponseMs)
+	if e.DeliveryID != "" {
+		line += " delivery=" + e.DeliveryID
+	}
+	if e.Error != "" {
+		line += fmt.Sprintf(" error=%q", e.Error)
+	}
+	return line
+}
+
+func setIfNotEmpty(query url.Values, key, value string) {
+	if value != "" {
+		query.Set(key, value)
+	}
+}
+
+// addExportFlags adds the time range, format and output flags shared by the
+// export commands.
+func addExportFlags(cmd *cobra.Command, since, until, format, output *string) {
+	cmd.Flags().StringVar(since, "since", "", "only rows created at or after this RFC 3339 time")
+	cmd.Flags().StringVar(until, "until", "", "only rows created before this RFC 3339 time")
+	cmd.Flags().StringVar(format, "format", "ndjson", "ndjson or csv")
+	cmd.Flags().StringVar(output, "out-file", "", "write to this file instead of standard output")
+}
+
+// exportTo downloads an export to the output file, or to standard output
+// when output is empty.
+func exportTo(cmd *cobra.Command, opts *options, path string, query url.Values, output string) error {
+	if output == "" {
+		return opts.client().download(cmd.Context(), path, query, opts.out)
+	}
+	f, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	if err := opts.client().download(cmd.Context(), path, query, f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
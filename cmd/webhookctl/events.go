@@ -0,0 +1,220 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+func newEventsCmd(opts *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "events",
+		Aliases: []string{"event"},
+		Short:   "Publish events and browse the event type catalog",
+	}
+	cmd.AddCommand(newEventsPublishCmd(opts), newEventsSearchCmd(opts), newEventsTypesCmd(opts))
+	return cmd
+}
+
+func newEventsTypesCmd(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "types",
+		Short: "List the event types you can subscribe to",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var types []domain.EventType
+			data, err := opts.client().get(cmd.Context(), "/event-types", nil, &types)
+			if err != nil {
+				return err
+			}
+			if opts.jsonOutput() {
+				return printJSON(opts.out, data)
+			}
+
+			tw := newTable(opts.out, "TYPE", "EVENTS", "LAST SEEN", "DESCRIPTION")
+			for _, t := range types {
+				fmt.Fprintf(tw, "%s\t%d\t%s\t%s\n", t.Name, t.EventCount, formatTime(t.LastSeenAt), t.Description)
+			}
+			return tw.Flush()
+		},
+	}
+}
+
+func newEventsSearchCmd(opts *options) *cobra.Command {
+	var eventType string
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "search <field:value>...",
+		Short: "Find events by values in their payload",
+		Long: `Find events by values in their payload, newest first, with a summary
+of their deliveries.
+
+Every term must match. A term is a dot-separated path into the payload, a
+colon and the value, so a consumer's order ID or customer ID leads straight
+to the events sent about it. Use "webhookctl deliveries list --event" for
+the attempts of one event.`,
+		Example: `  webhookctl events search order_id:abc-123
+  webhookctl events search customer.id:42 --type order.paid
+  webhookctl events search 'customer.name:Jane Doe'`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			query := url.Values{}
+			query.Set("query", searchQuery(args))
+			setIfNotEmpty(query, "event_type", eventType)
+			query.Set("limit", strconv.Itoa(limit))
+
+			var results []domain.EventSearchResult
+			data, err := opts.client().get(cmd.Context(), "/events/search", query, &results)
+			if err != nil {
+				return err
+			}
+			if opts.jsonOutput() {
+				return printJSON(opts.out, data)
+			}
+
+			tw := newTable(opts.out, "EVENT", "TYPE", "CREATED", "ATTEMPTS", "SUBSCRIBERS", "LAST STATUS")
+			for _, r := range results {
+				subscribers := map[string]bool{}
+				for _, a := range r.Deliveries {
+					subscribers[a.SubscriberID] = true
+				}
+				last := "-"
+				if len(r.Deliveries) > 0 {
+					last = r.Deliveries[0].Status
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t%s\n",
+					r.ID, r.EventType, formatTime(&r.CreatedAt), len(r.Deliveries), len(subscribers), last)
+			}
+			return tw.Flush()
+		},
+	}
+
+	cmd.Flags().StringVar(&eventType, "type", "", "only events of this type")
+	cmd.Flags().IntVar(&limit, "limit", 20, "maximum number of events (at most 100)")
+	return cmd
+}
+
+// searchQuery joins search terms given as separate arguments, quoting the
+// values that the shell passed with spaces in them.
+func searchQuery(args []string) string {
+	terms := make([]string, len(args))
+	for i, arg := range args {
+		field, value, ok := strings.Cut(arg, ":")
+		if ok && strings.Contains(value, " ") && !strings.HasPrefix(value, `"`) {
+			arg = field + `:"` + value + `"`
+		}
+		terms[i] = arg
+	}
+	return strings.Join(terms, " ")
+}
+
+func newEventsPublishCmd(opts *options) *cobra.Command {
+	var eventType, data, file, source string
+	var subscriberIDs []string
+
+	cmd := &cobra.Command{
+		Use:   "publish",
+		Short: "Publish an event to every matching subscriber",
+		Long: `Publish an event to every matching subscriber.
+
+Without --data or --file a small test payload is sent, which is handy for
+checking that a new subscriber receives webhooks. With --subscriber the
+event is only delivered to the given subscribers, which must subscribe to
+its type.`,
+		Example: `  webhookctl events publish --type order.created --data '{"order_id": "42"}'
+  webhookctl events publish --type order.created --file order.json
+  webhookctl events publish --type order.created
+  webhookctl events publish --type order.created --subscriber 8c1e7f0a-2b4d-4e6f-9a3c-5d7b9e1f3a2c`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			payload, err := eventPayload(data, file)
+			if err != nil {
+				return err
+			}
+
+			body := map[string]interface{}{
+				"event_type": eventType,
+				"payload":    payload,
+				"source":     source,
+			}
+			if len(subscriberIDs) > 0 {
+				body["subscriber_ids"] = subscriberIDs
+			}
+			resp, err := opts.client().do(cmd.Context(), http.MethodPost, "/events", nil, body)
+			if err != nil {
+				return err
+			}
+			if opts.jsonOutput() {
+				return printJSON(opts.out, resp)
+			}
+
+			var created struct {
+				EventID          string `json:"event_id"`
+				DeliveriesQueued int    `json:"deliveries_queued"`
+				FanOutPending    bool   `json:"fanout_pending"`
+			}
+			if err := decode(resp, &created); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(opts.out, "Published event %s\n", created.EventID)
+			if created.FanOutPending {
+				fmt.Fprintln(opts.out, "Fan-out is pending and will be retried by the server")
+			} else {
+				fmt.Fprintf(opts.out, "Deliveries queued: %d\n", created.DeliveriesQueued)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&eventType, "type", "", "event type, e.g. order.created")
+	cmd.Flags().StringVar(&data, "data", "", "JSON payload")
+	cmd.Flags().StringVar(&file, "file", "", "read the JSON payload from a file (- for stdin)")
+	cmd.Flags().StringVar(&source, "source", "webhookctl", "event source recorded with the event")
+	cmd.Flags().StringSliceVar(&subscriberIDs, "subscriber", nil, "only deliver to this subscriber ID (repeatable)")
+	cmd.MarkFlagRequired("type")
+	cmd.MarkFlagsMutuallyExclusive("data", "file")
+	return cmd
+}
+
+// eventPayload returns the payload given on the command line or in a file,
+// or a test payload when neither is set.
+func eventPayload(data, file string) (json.RawMessage, error) {
+	var raw []byte
+	switch {
+	case data != "":
+		raw = []byte(data)
+	case file == "-":
+		b, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("reading payload from stdin: %w", err)
+		}
+		raw = b
+	case file != "":
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("reading payload: %w", err)
+		}
+		raw = b
+	default:
+		return json.Marshal(map[string]interface{}{
+			"test":    true,
+			"sent_at": time.Now().UTC().Format(time.RFC3339),
+		})
+	}
+
+	if !json.Valid(raw) {
+		return nil, fmt.Errorf("payload is not valid JSON")
+	}
+	return raw, nil
+}
@@ -0,0 +1,82 @@
+// Command webhookctl is an operator CLI for the webhook delivery system's
+// management API. Its migrate commands talk to the database directly.
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := newRootCmd(os.Stdout).ExecuteContext(ctx); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+// options holds the global flags shared by every command.
+type options struct {
+	server string
+	apiKey string
+	output string
+	out    io.Writer
+}
+
+func (o *options) client() *apiClient {
+	return newAPIClient(o.server, o.apiKey)
+}
+
+// jsonOutput reports whether results should be printed as raw JSON.
+func (o *options) jsonOutput() bool {
+	return o.output == "json"
+}
+
+func newRootCmd(out io.Writer) *cobra.Command {
+	opts := &options{out: out}
+
+	root := &cobra.Command{
+		Use:           "webhookctl",
+		Short:         "Manage a webhook delivery server",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if opts.output != "table" && opts.output != "json" {
+				return fmt.Errorf("--output must be table or json")
+			}
+			return nil
+		},
+	}
+	root.SetOut(out)
+
+	root.PersistentFlags().StringVar(&opts.server, "server", envOr("WEBHOOK_SERVER", "http://localhost:8080"), "server base URL (env WEBHOOK_SERVER)")
+	root.PersistentFlags().StringVar(&opts.apiKey, "api-key", os.Getenv("WEBHOOK_API_KEY"), "API key (env WEBHOOK_API_KEY)")
+	root.PersistentFlags().StringVarP(&opts.output, "output", "o", "table", "output format: table or json")
+
+	root.AddCommand(
+		newSubscribersCmd(opts),
+		newEventsCmd(opts),
+		newDeliveriesCmd(opts),
+		newDeadLettersCmd(opts),
+		newBreakersCmd(opts),
+		newQueueCmd(opts),
+		newUsageCmd(opts),
+		newMigrateCmd(opts),
+	)
+	return root
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	ws "github.com/Priya8975/webhook-delivery-system/internal/websocket"
+)
+
+// run executes webhookctl against server with the given arguments.
+func run(t *testing.T, server *httptest.Server, args ...string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	cmd := newRootCmd(&out)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs(append([]string{"--server", server.URL, "--api-key", "whk_test"}, args...))
+	err := cmd.ExecuteContext(context.Background())
+	return out.String(), err
+}
+
+func TestSubscribersList_PrintsTableWithAPIKey(t *testing.T) {
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		if r.URL.Path != "/api/v1/subscribers" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.Write([]byte(`[{"id":"sub-1","name":"orders","endpoint_url":"http://example.com/hook","is_active":true,"rate_limit_per_second":10}]`))
+	}))
+	defer server.Close()
+
+	out, err := run(t, server, "subscribers", "list")
+	if err != nil {
+		t.Fatalf("command failed: %v", err)
+	}
+	if auth != "Bearer whk_test" {
+		t.Errorf("Authorization = %q, want the API key as a bearer token", auth)
+	}
+	if !strings.Contains(out, "ENDPOINT") || !strings.Contains(out, "http://example.com/hook") {
+		t.Errorf("unexpected table output:\n%s", out)
+	}
+}
+
+func TestEventsPublish_SendsPayload(t *testing.T) {
+	var got struct {
+		EventType string          `json:"event_type"`
+		Payload   json.RawMessage `json:"payload"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/events" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"event_id":"evt-1","event_type":"order.created","deliveries_queued":2}`))
+	}))
+	defer server.Close()
+
+	out, err := run(t, server, "events", "publish", "--type", "order.created", "--data", `{"id":1}`)
+	if err != nil {
+		t.Fatalf("command failed: %v", err)
+	}
+	if got.EventType != "order.created" || string(got.Payload) != `{"id":1}` {
+		t.Errorf("unexpected request body: %+v", got)
+	}
+	if !strings.Contains(out, "evt-1") || !strings.Contains(out, "Deliveries queued: 2") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
+
+func TestEventsPublish_RejectsInvalidJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("no request should be sent for an invalid payload")
+	}))
+	defer server.Close()
+
+	if _, err := run(t, server, "events", "publish", "--type", "x", "--data", "{"); err == nil {
+		t.Fatal("expected an error for invalid JSON")
+	}
+}
+
+func TestEventsSearch_QuotesTermsWithSpaces(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/events/search" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		query = r.URL.Query().Get("query")
+		w.Write([]byte(`[{"id":"evt-1","event_type":"order.paid","payload":{},"created_at":"2026-01-02T03:04:05Z",
+			"deliveries":[{"id":"att-2","subscriber_id":"sub-1","status":"success"},{"id":"att-1","subscriber_id":"sub-1","status":"failed"}]}]`))
+	}))
+	defer server.Close()
+
+	out, err := run(t, server, "events", "search", "order_id:abc-123", "customer.name:Jane Doe")
+	if err != nil {
+		t.Fatalf("command failed: %v", err)
+	}
+	if query != `order_id:abc-123 customer.name:"Jane Doe"` {
+		t.Errorf("query = %q", query)
+	}
+	if !strings.Contains(out, "evt-1") || !strings.Contains(out, "success") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
+
+func TestDLQReplay_ReportsServerErrors(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		if strings.Contains(r.URL.Path, "missing") {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"dead letter not found"}`))
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"status":"replayed"}`))
+	}))
+	defer server.Close()
+
+	out, err := run(t, server, "dlq", "replay", "dl-1", "missing")
+	if err == nil || !strings.Contains(err.Error(), "1 of 2") {
+		t.Fatalf("expected a partial failure error, got %v", err)
+	}
+	if !strings.Contains(out, "Replayed dl-1") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+	want := []string{"POST /api/v1/dead-letters/dl-1/replay", "POST /api/v1/dead-letters/missing/replay"}
+	if strings.Join(paths, ",") != strings.Join(want, ",") {
+		t.Errorf("requests = %v, want %v", paths, want)
+	}
+}
+
+func TestDeliveriesExport_WritesFile(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/deliveries/export" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		query = r.URL.RawQuery
+		w.Write([]byte("id,event_id\natt-1,evt-1\n"))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "evidence.csv")
+	if _, err := run(t, server, "deliveries", "export", "--subscriber", "sub-1", "--format", "csv", "--out-file", path); err != nil {
+		t.Fatalf("command failed: %v", err)
+	}
+	if query != "format=csv&subscriber_id=sub-1" {
+		t.Errorf("query = %q", query)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "id,event_id\natt-1,evt-1\n" {
+		t.Errorf("file = %q, %v", data, err)
+	}
+}
+
+func TestSubscribersImport_UploadsCSV(t *testing.T) {
+	var contentType, query, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/subscribers/import" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		contentType, query = r.Header.Get("Content-Type"), r.URL.RawQuery
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.Write([]byte(`{"dry_run":true,"created":1,"skipped":0,"rows":[{"row":1,"name":"orders","action":"create"}]}`))
+	}))
+	defer server.Close()
+
+	manifest := "name,endpoint_url,event_types\norders,https://example.com/hook,order.created\n"
+	path := filepath.Join(t.TempDir(), "subscribers.csv")
+	os.WriteFile(path, []byte(manifest), 0o600)
+
+	out, err := run(t, server, "subscribers", "import", "--file", path, "--dry-run")
+	if err != nil {
+		t.Fatalf("command failed: %v", err)
+	}
+	if contentType != "text/csv" || query != "dry_run=true" || body != manifest {
+		t.Errorf("request = %q %q %q", contentType, query, body)
+	}
+	if !strings.Contains(out, "orders") || !strings.Contains(out, "1 would be created") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
+
+func TestAPIError_UsesErrorMessage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":"invalid API key"}`))
+	}))
+	defer server.Close()
+
+	_, err := run(t, server, "queue")
+	if err == nil || err.Error() != "server returned 401: invalid API key" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestFormatDeliveryEvent(t *testing.T) {
+	status := 500
+	line := formatDeliveryEvent(ws.DeliveryEvent{
+		Type:         "delivery_failed",
+		DeliveryID:   "dlv-1",
+		EventID:      "evt-1",
+		SubscriberID: "sub-1",
+		Attempt:      3,
+		StatusCode:   &status,
+		ResponseMs:   120,
+		Error:        "server error",
+		Timestamp:    time.Now(),
+	})
+
+	for _, want := range []string{"delivery_failed", "event=evt-1", "attempt=3", "http=500", "delivery=dlv-1", `error="server error"`} {
+		if !strings.Contains(line, want) {
+			t.Errorf("line %q does not contain %q", line, want)
+		}
+	}
+}
+
+func TestMigrate_UpStatusDown(t *testing.T) {
+	dbURL := "sqlite:" + filepath.Join(t.TempDir(), "webhooks.db")
+	migrate := func(args ...string) string {
+		t.Helper()
+		var out bytes.Buffer
+		cmd := newRootCmd(&out)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs(append([]string{"migrate", "--database-url", dbURL}, args...))
+		if err := cmd.ExecuteContext(context.Background()); err != nil {
+			t.Fatalf("migrate %v: %v", args, err)
+		}
+		return out.String()
+	}
+
+	if out := migrate("up"); !strings.Contains(out, "applied 000001_init.up.sql") {
+		t.Errorf("unexpected up output:\n%s", out)
+	}
+	if out := migrate("up"); !strings.Contains(out, "already up to date") {
+		t.Errorf("unexpected second up output:\n%s", out)
+	}
+
+	out := migrate("down", "--steps", "2")
+	if strings.Count(out, "reverted ") != 2 {
+		t.Errorf("unexpected down output:\n%s", out)
+	}
+
+	var states []struct {
+		Version   string     `json:"version"`
+		AppliedAt *time.Time `json:"applied_at"`
+	}
+	if err := json.Unmarshal([]byte(migrate("status", "-o", "json")), &states); err != nil {
+		t.Fatalf("decoding status: %v", err)
+	}
+	var pending int
+	for _, s := range states {
+		if s.AppliedAt == nil {
+			pending++
+		}
+	}
+	if len(states) == 0 || pending != 2 {
+		t.Errorf("status lists %d migrations with %d pending, want 2 pending", len(states), pending)
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+	"github.com/Priya8975/webhook-delivery-system/migrations"
+	"github.com/spf13/cobra"
+)
+
+// newMigrateCmd manages the database schema directly, with the migrations
+// built into webhookctl, rather than through the server's API.
+func newMigrateCmd(opts *options) *cobra.Command {
+	var databaseURL string
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Apply, revert and list database migrations",
+		Long: "Apply, revert and list database migrations. These commands connect to the\n" +
+			"database directly rather than to the server.",
+	}
+	cmd.PersistentFlags().StringVar(&databaseURL, "database-url", envOr("DATABASE_URL", ""), "PostgreSQL URL or sqlite:<path> (env DATABASE_URL)")
+
+	open := func(ctx context.Context) (store.Database, error) {
+		if databaseURL == "" {
+			return nil, fmt.Errorf("--database-url or DATABASE_URL is required")
+		}
+		return store.Open(ctx, databaseURL, store.PoolConfig{MaxConns: 1})
+	}
+
+	cmd.AddCommand(
+		newMigrateUpCmd(opts, open),
+		newMigrateDownCmd(opts, open),
+		newMigrateStatusCmd(opts, open),
+	)
+	return cmd
+}
+
+type openDatabase func(ctx context.Context) (store.Database, error)
+
+func newMigrateUpCmd(opts *options, open openDatabase) *cobra.Command {
+	return &cobra.Command{
+		Use:   "up",
+		Short: "Apply all pending migrations",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			db, err := open(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			applied, err := db.RunMigrations(cmd.Context(), migrations.FS)
+			for _, version := range applied {
+				fmt.Fprintf(opts.out, "applied %s\n", version)
+			}
+			if err != nil {
+				return err
+			}
+			if len(applied) == 0 {
+				fmt.Fprintln(opts.out, "already up to date")
+			}
+			return nil
+		},
+	}
+}
+
+func newMigrateDownCmd(opts *options, open openDatabase) *cobra.Command {
+	var steps int
+
+	cmd := &cobra.Command{
+		Use:   "down",
+		Short: "Revert the most recently applied migrations",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if steps < 1 {
+				return fmt.Errorf("--steps must be at least 1")
+			}
+			db, err := open(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			reverted, err := db.RollbackMigrations(cmd.Context(), migrations.FS, steps)
+			for _, version := range reverted {
+				fmt.Fprintf(opts.out, "reverted %s\n", version)
+			}
+			if err != nil {
+				return err
+			}
+			if len(reverted) == 0 {
+				fmt.Fprintln(opts.out, "no migrations applied")
+			}
+			return nil
+		},
+	}
+	cmd.Flags().IntVar(&steps, "steps", 1, "number of migrations to revert")
+	return cmd
+}
+
+func newMigrateStatusCmd(opts *options, open openDatabase) *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "List migrations and whether each has been applied",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			db, err := open(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			states, err := db.MigrationStatus(cmd.Context(), migrations.FS)
+			if err != nil {
+				return err
+			}
+			if opts.jsonOutput() {
+				data, err := json.Marshal(states)
+				if err != nil {
+					return err
+				}
+				return printJSON(opts.out, data)
+			}
+
+			tw := newTable(opts.out, "VERSION", "APPLIED")
+			for _, s := range states {
+				fmt.Fprintf(tw, "%s\t%s\n", s.Version, formatTime(s.AppliedAt))
+			}
+			return tw.Flush()
+		},
+	}
+}
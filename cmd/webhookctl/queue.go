@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+	"github.com/Priya8975/webhook-delivery-system/internal/worker"
+	"github.com/spf13/cobra"
+)
+
+func newQueueCmd(opts *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "queue",
+		Short: "Show delivery queue depth and worker pool load",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var metrics struct {
+				QueueDepth      int64            `json:"queue_depth"`
+				DispatcherLagMs int64            `json:"dispatcher_lag_ms"`
+				WorkerPool      worker.PoolStats `json:"worker_pool"`
+				DeadLetterCount int              `json:"dead_letter_count"`
+			}
+			data, err := opts.client().get(cmd.Context(), "/metrics", nil, &metrics)
+			if err != nil {
+				return err
+			}
+			if opts.jsonOutput() {
+				return printJSON(opts.out, data)
+			}
+
+			tw := newTable(opts.out, "METRIC", "VALUE")
+			fmt.Fprintf(tw, "queue_depth\t%d\n", metrics.QueueDepth)
+			fmt.Fprintf(tw, "dispatcher_lag_ms\t%d\n", metrics.DispatcherLagMs)
+			fmt.Fprintf(tw, "workers\t%d\n", metrics.WorkerPool.Workers)
+			fmt.Fprintf(tw, "busy_workers\t%d\n", metrics.WorkerPool.BusyWorkers)
+			fmt.Fprintf(tw, "buffered_jobs\t%d\n", metrics.WorkerPool.BufferedJobs)
+			fmt.Fprintf(tw, "open_dead_letters\t%d\n", metrics.DeadLetterCount)
+			return tw.Flush()
+		},
+	}
+	cmd.AddCommand(
+		newQueueInspectCmd(opts),
+		newQueuePurgeCmd(opts),
+	)
+	return cmd
+}
+
+func newQueueInspectCmd(opts *options) *cobra.Command {
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "inspect",
+		Short: "Break the delivery queue down by state and subscriber",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var stats engine.QueueStats
+			query := url.Values{"limit": {strconv.Itoa(limit)}}
+			data, err := opts.client().get(cmd.Context(), "/queue", query, &stats)
+			if err != nil {
+				return err
+			}
+			if opts.jsonOutput() {
+				return printJSON(opts.out, data)
+			}
+
+			fmt.Fprintf(opts.out, "depth %d: %d ready, %d scheduled, %d parked\n",
+				stats.Depth, stats.Ready, stats.Scheduled, stats.Parked)
+			if stats.OldestReadyAt != nil {
+				fmt.Fprintf(opts.out, "oldest due job waiting %s\n",
+					time.Duration(stats.OldestAgeSeconds*float64(time.Second)).Round(time.Millisecond))
+			}
+			if len(stats.Subscribers) == 0 {
+				return nil
+			}
+
+			fmt.Fprintln(opts.out)
+			tw := newTable(opts.out, "SUBSCRIBER", "READY", "SCHEDULED", "PARKED")
+			for _, s := range stats.Subscribers {
+				fmt.Fprintf(tw, "%s\t%d\t%d\t%d\n", s.SubscriberID, s.Ready, s.Scheduled, s.Parked)
+			}
+			return tw.Flush()
+		},
+	}
+
+	cmd.Flags().IntVar(&limit, "limit", 20, "maximum number of subscribers to list")
+	return cmd
+}
+
+func newQueuePurgeCmd(opts *options) *cobra.Command {
+	var subscriberID, eventType, state string
+	var minAttempt int
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   "purge",
+		Short: "Remove queued jobs matching filters",
+		Long: `Remove queued jobs matching every given filter. Purged jobs are neither
+delivered nor dead-lettered. Emptying the whole queue requires --all.`,
+		Example: `  webhookctl queue purge --subscriber 4f1c... --state scheduled
+  webhookctl queue purge --event-type 'order.**' --min-attempt 2`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			query := url.Values{}
+			setIfNotEmpty(query, "subscriber_id", subscriberID)
+			setIfNotEmpty(query, "event_type", eventType)
+			setIfNotEmpty(query, "state", state)
+			if minAttempt > 0 {
+				query.Set("min_attempt", strconv.Itoa(minAttempt))
+			}
+			if all {
+				query.Set("all", "true")
+			}
+
+			var purged struct {
+				JobsPurged int64 `json:"jobs_purged"`
+			}
+			data, err := opts.client().do(cmd.Context(), http.MethodDelete, "/queue/jobs", query, nil)
+			if err != nil {
+				return err
+			}
+			if opts.jsonOutput() {
+				return printJSON(opts.out, data)
+			}
+			if err := decode(data, &purged); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(opts.out, "Purged %d jobs\n", purged.JobsPurged)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&subscriberID, "subscriber", "", "only jobs for this subscriber ID")
+	cmd.Flags().StringVar(&eventType, "event-type", "", "only jobs whose event type matches this type or pattern")
+	cmd.Flags().StringVar(&state, "state", "", "only ready or scheduled jobs")
+	cmd.Flags().IntVar(&minAttempt, "min-attempt", 0, "only jobs on this attempt or later")
+	cmd.Flags().BoolVar(&all, "all", false, "purge every queued job when no other filter is given")
+	return cmd
+}
@@ -0,0 +1,545 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+	"github.com/spf13/cobra"
+)
+
+func newSubscribersCmd(opts *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "subscribers",
+		Aliases: []string{"subscriber", "subs"},
+		Short:   "Create and inspect subscribers",
+	}
+	cmd.AddCommand(
+		newSubscribersListCmd(opts),
+		newSubscribersGetCmd(opts),
+		newSubscribersCreateCmd(opts),
+		newSubscribersPauseCmd(opts),
+		newSubscribersResumeCmd(opts),
+		newSubscribersPendingCmd(opts),
+		newSubscribersPurgeCmd(opts),
+		newSubscribersDeleteCmd(opts),
+		newSubscribersRestoreCmd(opts),
+		newSubscribersExportCmd(opts),
+		newSubscribersImportCmd(opts),
+	)
+	return cmd
+}
+
+func newSubscribersListCmd(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List subscribers",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var subs []domain.Subscriber
+			data, err := opts.client().get(cmd.Context(), "/subscribers", nil, &subs)
+			if err != nil {
+				return err
+			}
+			if opts.jsonOutput() {
+				return printJSON(opts.out, data)
+			}
+
+			tw := newTable(opts.out, "ID", "NAME", "ENDPOINT", "ACTIVE", "RATE/S", "COMPRESS")
+			for _, s := range subs {
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%t\t%d\t%t\n",
+					s.ID, s.Name, s.EndpointURL, s.IsActive, s.RateLimitPerSecond, s.CompressPayloads)
+			}
+			return tw.Flush()
+		},
+	}
+}
+
+func newSubscribersGetCmd(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "get <id>",
+		Short: "Show a subscriber and its subscriptions",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var sub struct {
+				domain.Subscriber
+				Subscriptions []domain.Subscription `json:"subscriptions"`
+			}
+			data, err := opts.client().get(cmd.Context(), "/subscribers/"+args[0], nil, &sub)
+			if err != nil {
+				return err
+			}
+			if opts.jsonOutput() {
+				return printJSON(opts.out, data)
+			}
+
+			eventTypes := make([]string, 0, len(sub.Subscriptions))
+			for _, s := range sub.Subscriptions {
+				if s.IsActive {
+					eventTypes = append(eventTypes, s.EventType)
+				}
+			}
+
+			tw := newTable(opts.out, "FIELD", "VALUE")
+			fmt.Fprintf(tw, "id\t%s\n", sub.ID)
+			fmt.Fprintf(tw, "name\t%s\n", sub.Name)
+			fmt.Fprintf(tw, "endpoint_url\t%s\n", sub.EndpointURL)
+			if sub.EndpointType != "" && sub.EndpointType != domain.EndpointHTTP {
+				fmt.Fprintf(tw, "endpoint_type\t%s\n", sub.EndpointType)
+			}
+			fmt.Fprintf(tw, "is_active\t%t\n", sub.IsActive)
+			fmt.Fprintf(tw, "rate_limit_per_second\t%d\n", sub.RateLimitPerSecond)
+			fmt.Fprintf(tw, "compress_payloads\t%t\n", sub.CompressPayloads)
+			fmt.Fprintf(tw, "discard_response_bodies\t%t\n", sub.DiscardResponseBodies)
+			if sub.Batched() {
+				fmt.Fprintf(tw, "batching\tup to %d events every %ds\n", sub.BatchMaxEvents, sub.BatchWindowSeconds)
+			}
+			if sub.ProxyURL != "" {
+				fmt.Fprintf(tw, "proxy_url\t%s\n", sub.ProxyURL)
+			}
+			if sub.SignatureFormat != "" && sub.SignatureFormat != domain.SignatureStandard {
+				fmt.Fprintf(tw, "signature_format\t%s\n", sub.SignatureFormat)
+			}
+			if len(sub.EventVersions) > 0 {
+				var pins []string
+				for _, eventType := range slices.Sorted(maps.Keys(sub.EventVersions)) {
+					pins = append(pins, fmt.Sprintf("%s=v%d", eventType, sub.EventVersions[eventType]))
+				}
+				fmt.Fprintf(tw, "event_versions\t%s\n", strings.Join(pins, ", "))
+			}
+			if sub.Sandbox {
+				fmt.Fprintf(tw, "sandbox\t%t\n", sub.Sandbox)
+			}
+			if sub.DailyQuota > 0 {
+				fmt.Fprintf(tw, "daily_quota\t%d\n", sub.DailyQuota)
+			}
+			if sub.MonthlyQuota > 0 {
+				fmt.Fprintf(tw, "monthly_quota\t%d\n", sub.MonthlyQuota)
+			}
+			if sub.AutoReplayDeadLetters {
+				fmt.Fprintf(tw, "auto_replay_dead_letters\t%t\n", sub.AutoReplayDeadLetters)
+			}
+			if sub.RetryJitter != "" {
+				fmt.Fprintf(tw, "retry_jitter\t%s\n", sub.RetryJitter)
+			}
+			if sub.MaxRetryDurationSeconds > 0 {
+				fmt.Fprintf(tw, "max_retry_duration\t%s\n", time.Duration(sub.MaxRetryDurationSeconds)*time.Second)
+			}
+			if sub.RetryForDuration {
+				fmt.Fprintf(tw, "retry_for_duration\t%t\n", sub.RetryForDuration)
+			}
+			if sub.SuccessBodyContains != "" {
+				fmt.Fprintf(tw, "success_body_contains\t%s\n", sub.SuccessBodyContains)
+			}
+			if sub.SuccessJSONPath != "" {
+				fmt.Fprintf(tw, "success_json_path\t%s\n", sub.SuccessJSONPath)
+			}
+			if sub.RedirectPolicy != "" {
+				fmt.Fprintf(tw, "redirect_policy\t%s\n", sub.RedirectPolicy)
+			}
+			if sub.DebugLogging {
+				fmt.Fprintf(tw, "debug_logging\t%t\n", sub.DebugLogging)
+			}
+			if sub.IsSystem {
+				fmt.Fprintf(tw, "is_system\t%t\n", sub.IsSystem)
+			}
+			fmt.Fprintf(tw, "event_types\t%s\n", strings.Join(eventTypes, ", "))
+			fmt.Fprintf(tw, "created_at\t%s\n", formatTime(&sub.CreatedAt))
+			if sub.Deleted() {
+				fmt.Fprintf(tw, "deleted_at\t%s\n", formatTime(sub.DeletedAt))
+			}
+			return tw.Flush()
+		},
+	}
+}
+
+func newSubscribersCreateCmd(opts *options) *cobra.Command {
+	var req domain.CreateSubscriberRequest
+	var batchWindow, maxRetryDuration time.Duration
+	var credentialsFile string
+
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Register a subscriber",
+		Example: `  webhookctl subscribers create --name orders --url https://example.com/hooks \
+    --events order.created,order.updated
+  webhookctl subscribers create --name metrics --url https://example.com/hooks \
+    --events metric.recorded --batch-max-events 100 --batch-window 5s
+  webhookctl subscribers create --name ledger --url https://example.com/hooks \
+    --events payment.settled --max-retry-duration 24h --retry-for-duration
+  webhookctl subscribers create --name legacy --url https://example.com/hooks \
+    --events order.created --success-json-path '$.status == "ok"'
+  webhookctl subscribers create --name billing-queue \
+    --url https://sqs.eu-west-1.amazonaws.com/123456789012/billing \
+    --events invoice.paid --endpoint-type sqs --endpoint-credentials-file aws.json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			req.BatchWindowSeconds = int(batchWindow / time.Second)
+			req.MaxRetryDurationSeconds = int(maxRetryDuration / time.Second)
+			if credentialsFile != "" {
+				b, err := os.ReadFile(credentialsFile)
+				if err != nil {
+					return err
+				}
+				// Pub/Sub takes the service account's key file as it is
+				req.EndpointCredentials = &domain.EndpointCredentials{ServiceAccountKey: string(b)}
+				if req.EndpointType != domain.EndpointPubSub {
+					req.EndpointCredentials = &domain.EndpointCredentials{}
+					if err := json.Unmarshal(b, req.EndpointCredentials); err != nil {
+						return fmt.Errorf("reading %s: %w", credentialsFile, err)
+					}
+				}
+			}
+
+			var created domain.CreateSubscriberResponse
+			data, err := opts.client().do(cmd.Context(), http.MethodPost, "/subscribers", nil, req)
+			if err != nil {
+				return err
+			}
+			if opts.jsonOutput() {
+				return printJSON(opts.out, data)
+			}
+			if err := decode(data, &created); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(opts.out, "Created subscriber %s (%s)\n", created.Name, created.ID)
+			fmt.Fprintf(opts.out, "Signing secret: %s\n", created.SecretKey)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&req.Name, "name", "", "subscriber name")
+	cmd.Flags().StringVar(&req.EndpointURL, "url", "", "endpoint URL webhooks are POSTed to, or the queue's URL with --endpoint-type; optional with --sandbox")
+	cmd.Flags().StringVar(&req.EndpointType, "endpoint-type", "", "deliver into a queue instead of over HTTP: sqs, pubsub or amqp")
+	cmd.Flags().StringVar(&credentialsFile, "endpoint-credentials-file", "", "JSON file with the credentials of the --endpoint-type queue, e.g. {\"access_key_id\": ..., \"secret_access_key\": ...}, or for pubsub the service account's key file")
+	cmd.Flags().StringSliceVar(&req.EventTypes, "events", nil, "comma-separated event types to subscribe to")
+	cmd.Flags().BoolVar(&req.CompressPayloads, "compress", false, "gzip payloads sent to this subscriber")
+	cmd.Flags().BoolVar(&req.DiscardResponseBodies, "discard-response-bodies", false, "don't store this endpoint's response bodies")
+	cmd.Flags().IntVar(&req.BatchMaxEvents, "batch-max-events", 0, "deliver up to this many events per request as a JSON array")
+	cmd.Flags().DurationVar(&batchWindow, "batch-window", 0, "how long a batch waits for more events, in whole seconds")
+	cmd.Flags().StringVar(&req.ProxyURL, "proxy", "", "deliver through this HTTP(S) or SOCKS5 proxy, e.g. socks5://egress:1080")
+	cmd.Flags().StringVar(&req.SignatureFormat, "signature-format", "", "sign deliveries like another service, for its verification middleware: standard, github, stripe or svix")
+	cmd.Flags().BoolVar(&req.Sandbox, "sandbox", false, "record deliveries as simulated attempts without sending them")
+	cmd.Flags().IntVar(&req.DailyQuota, "daily-quota", 0, "hold deliveries past this many a UTC day until midnight")
+	cmd.Flags().IntVar(&req.MonthlyQuota, "monthly-quota", 0, "hold deliveries past this many a calendar month until the month ends")
+	cmd.Flags().BoolVar(&req.AutoReplayDeadLetters, "auto-replay-dead-letters", false, "replay dead letters once the endpoint has recovered from an outage")
+	cmd.Flags().StringVar(&req.RetryJitter, "retry-jitter", "", "spread retries with this jitter strategy instead of the server's: additive, full, equal or decorrelated")
+	cmd.Flags().DurationVar(&maxRetryDuration, "max-retry-duration", 0, "dead-letter deliveries still failing this long after their first attempt, in whole seconds")
+	cmd.Flags().BoolVar(&req.RetryForDuration, "retry-for-duration", false, "keep retrying for the whole --max-retry-duration, past the attempt count")
+	cmd.Flags().StringVar(&req.SuccessBodyContains, "success-body-contains", "", "only count 2xx responses whose body contains this text as delivered")
+	cmd.Flags().StringVar(&req.SuccessJSONPath, "success-json-path", "", `only count 2xx responses whose JSON body matches this JSONPath condition, e.g. '$.status == "ok"'`)
+	cmd.Flags().StringVar(&req.RedirectPolicy, "redirect-policy", "", "which redirects to follow instead of the server's policy: never, same_host or follow_resign")
+	cmd.Flags().BoolVar(&req.IsSystem, "system", false, "receive system events such as subscriber.circuit_opened and delivery.dead_lettered")
+	cmd.MarkFlagRequired("name")
+	cmd.MarkFlagRequired("events")
+	return cmd
+}
+
+func newSubscribersPauseCmd(opts *options) *cobra.Command {
+	var duration time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "pause <id>",
+		Short: "Pause deliveries to a subscriber",
+		Long: `Pause deliveries to a subscriber. Its jobs are held until it is resumed
+or the pause runs out, then delivered in full.`,
+		Example: "  webhookctl subscribers pause 4f1c... --for 10m",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			body := map[string]string{}
+			if duration > 0 {
+				body["duration"] = duration.String()
+			}
+			var status engine.PauseStatus
+			data, err := opts.client().do(cmd.Context(), http.MethodPost, "/subscribers/"+args[0]+"/pause", nil, body)
+			if err != nil {
+				return err
+			}
+			if opts.jsonOutput() {
+				return printJSON(opts.out, data)
+			}
+			if err := decode(data, &status); err != nil {
+				return err
+			}
+
+			if status.PausedUntil != nil {
+				fmt.Fprintf(opts.out, "Paused %s until %s\n", args[0], formatTime(status.PausedUntil))
+			} else {
+				fmt.Fprintf(opts.out, "Paused %s until resumed\n", args[0])
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().DurationVar(&duration, "for", 0, "resume automatically after this long (default: until resumed)")
+	return cmd
+}
+
+func newSubscribersResumeCmd(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "resume <id>",
+		Short: "Resume deliveries to a paused subscriber",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var resumed struct {
+				JobsResumed int64 `json:"jobs_resumed"`
+			}
+			data, err := opts.client().do(cmd.Context(), http.MethodPost, "/subscribers/"+args[0]+"/resume", nil, nil)
+			if err != nil {
+				return err
+			}
+			if opts.jsonOutput() {
+				return printJSON(opts.out, data)
+			}
+			if err := decode(data, &resumed); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(opts.out, "Resumed %s, %d parked jobs queued for delivery\n", args[0], resumed.JobsResumed)
+			return nil
+		},
+	}
+}
+
+func newSubscribersPendingCmd(opts *options) *cobra.Command {
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "pending <id>",
+		Short: "List jobs waiting to be delivered to a subscriber",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var pending struct {
+				Total int                 `json:"total"`
+				Jobs  []engine.PendingJob `json:"jobs"`
+			}
+			query := url.Values{"limit": {strconv.Itoa(limit)}}
+			data, err := opts.client().get(cmd.Context(), "/subscribers/"+args[0]+"/pending", query, &pending)
+			if err != nil {
+				return err
+			}
+			if opts.jsonOutput() {
+				return printJSON(opts.out, data)
+			}
+
+			tw := newTable(opts.out, "EVENT", "TYPE", "ATTEMPT", "NEXT ATTEMPT")
+			for _, j := range pending.Jobs {
+				next := formatTime(j.NextAttemptAt)
+				if j.Parked {
+					next = "parked"
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%d/%d\t%s\n", j.EventID, j.EventType, j.Attempt, j.MaxRetries, next)
+			}
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+			if pending.Total > len(pending.Jobs) {
+				fmt.Fprintf(opts.out, "(%d more not shown)\n", pending.Total-len(pending.Jobs))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&limit, "limit", 100, "maximum number of jobs to list")
+	return cmd
+}
+
+func newSubscribersPurgeCmd(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "purge <id>",
+		Short: "Drop every job waiting to be delivered to a subscriber",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var purged struct {
+				JobsPurged int64 `json:"jobs_purged"`
+			}
+			data, err := opts.client().do(cmd.Context(), http.MethodDelete, "/subscribers/"+args[0]+"/pending", nil, nil)
+			if err != nil {
+				return err
+			}
+			if opts.jsonOutput() {
+				return printJSON(opts.out, data)
+			}
+			if err := decode(data, &purged); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(opts.out, "Purged %d pending jobs for %s\n", purged.JobsPurged, args[0])
+			return nil
+		},
+	}
+}
+
+func newSubscribersDeleteCmd(opts *options) *cobra.Command {
+	var resolveDeadLetters bool
+
+	cmd := &cobra.Command{
+		Use:   "delete <id>",
+		Short: "Delete a subscriber and drop its pending jobs; restore undoes it",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var query url.Values
+			if resolveDeadLetters {
+				query = url.Values{"resolve_dead_letters": {"true"}}
+			}
+
+			var deleted struct {
+				JobsPurged          int64 `json:"jobs_purged"`
+				DeadLettersResolved int64 `json:"dead_letters_resolved"`
+			}
+			data, err := opts.client().do(cmd.Context(), http.MethodDelete, "/subscribers/"+args[0], query, nil)
+			if err != nil {
+				return err
+			}
+			if opts.jsonOutput() {
+				return printJSON(opts.out, data)
+			}
+			if err := decode(data, &deleted); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(opts.out, "Deleted %s, %d pending jobs purged", args[0], deleted.JobsPurged)
+			if resolveDeadLetters {
+				fmt.Fprintf(opts.out, ", %d dead letters resolved", deleted.DeadLettersResolved)
+			}
+			fmt.Fprintln(opts.out)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&resolveDeadLetters, "resolve-dead-letters", false, "also resolve the subscriber's open dead letters")
+	return cmd
+}
+
+func newSubscribersRestoreCmd(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "restore <id>",
+		Short: "Restore a deleted subscriber",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data, err := opts.client().do(cmd.Context(), http.MethodPost, "/subscribers/"+args[0]+"/restore", nil, nil)
+			if err != nil {
+				return err
+			}
+			if opts.jsonOutput() {
+				return printJSON(opts.out, data)
+			}
+
+			fmt.Fprintf(opts.out, "Restored %s\n", args[0])
+			return nil
+		},
+	}
+}
+
+func newSubscribersExportCmd(opts *options) *cobra.Command {
+	var format, output string
+	var includeSecrets bool
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export every subscriber and its event types for import elsewhere",
+		Long: `Export every subscriber and its event types as NDJSON or CSV, in the form
+"subscribers import" accepts. Signing secrets and queue credentials are left
+out unless --include-secrets is given, which needs an admin key.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			query := url.Values{}
+			setIfNotEmpty(query, "format", format)
+			if includeSecrets {
+				query.Set("include_secrets", "true")
+			}
+			return exportTo(cmd, opts, "/subscribers/export", query, output)
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "ndjson", "ndjson or csv")
+	cmd.Flags().StringVar(&output, "out-file", "", "write to this file instead of standard output")
+	cmd.Flags().BoolVar(&includeSecrets, "include-secrets", false, "include signing secrets and queue credentials")
+	return cmd
+}
+
+func newSubscribersImportCmd(opts *options) *cobra.Command {
+	var file string
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "import --file <manifest>",
+		Short: "Create the subscribers of an exported manifest",
+		Long: `Create the subscribers of a manifest written by "subscribers export". Files
+ending in .csv are sent as CSV, anything else as JSON. Subscribers whose name
+already exists are skipped, and nothing is created if any row is invalid.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			f, err := os.Open(file)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			contentType := "application/json"
+			if strings.EqualFold(filepath.Ext(file), ".csv") {
+				contentType = "text/csv"
+			}
+			var query url.Values
+			if dryRun {
+				query = url.Values{"dry_run": {"true"}}
+			}
+
+			data, err := opts.client().upload(cmd.Context(), "/subscribers/import", query, contentType, f)
+			if err != nil {
+				return err
+			}
+			if opts.jsonOutput() {
+				return printJSON(opts.out, data)
+			}
+
+			var result struct {
+				Created int `json:"created"`
+				Skipped int `json:"skipped"`
+				Rows    []struct {
+					Row          int    `json:"row"`
+					Name         string `json:"name"`
+					Action       string `json:"action"`
+					SubscriberID string `json:"subscriber_id"`
+				} `json:"rows"`
+			}
+			if err := decode(data, &result); err != nil {
+				return err
+			}
+
+			tw := newTable(opts.out, "ROW", "NAME", "ACTION", "SUBSCRIBER")
+			for _, row := range result.Rows {
+				id := row.SubscriberID
+				if id == "" {
+					id = "-"
+				}
+				fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", row.Row, row.Name, row.Action, id)
+			}
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+			if dryRun {
+				fmt.Fprintf(opts.out, "Dry run: %d would be created, %d skipped\n", result.Created, result.Skipped)
+			} else {
+				fmt.Fprintf(opts.out, "%d created, %d skipped\n", result.Created, result.Skipped)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&file, "file", "", "manifest to import (required)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "only validate and report what would be created")
+	cmd.MarkFlagRequired("file")
+	return cmd
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+func newUsageCmd(opts *options) *cobra.Command {
+	var from, to, subscriberID, output string
+	var csv bool
+
+	cmd := &cobra.Command{
+		Use:   "usage",
+		Short: "Show metered usage per subscriber",
+		Long: `Show the deliveries, retries and payload bytes metered per subscriber
+between two UTC days, by default over the current month so far. --csv
+downloads the usage day by day instead, as the scheduled export writes it.`,
+		Example: `  webhookctl usage --from 2024-05-01 --to 2024-05-31
+  webhookctl usage --from 2024-05-01 --to 2024-05-31 --csv --out-file may.csv`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			query := url.Values{}
+			setIfNotEmpty(query, "from", from)
+			setIfNotEmpty(query, "to", to)
+			setIfNotEmpty(query, "subscriber_id", subscriberID)
+			if csv {
+				query.Set("format", "csv")
+				return exportTo(cmd, opts, "/usage", query, output)
+			}
+
+			var report struct {
+				From   string         `json:"from"`
+				To     string         `json:"to"`
+				Totals []domain.Usage `json:"totals"`
+			}
+			data, err := opts.client().get(cmd.Context(), "/usage", query, &report)
+			if err != nil {
+				return err
+			}
+			if opts.jsonOutput() {
+				return printJSON(opts.out, data)
+			}
+
+			fmt.Fprintf(opts.out, "usage from %s to %s\n\n", report.From, report.To)
+			tw := newTable(opts.out, "SUBSCRIBER", "NAME", "DELIVERIES", "SUCCESSFUL", "RETRIES", "PAYLOAD_BYTES")
+			for _, u := range report.Totals {
+				fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%d\n",
+					u.SubscriberID, u.SubscriberName, u.Deliveries, u.Successful, u.Retries, u.PayloadBytes)
+			}
+			return tw.Flush()
+		},
+	}
+
+	cmd.Flags().StringVar(&from, "from", "", "first day, YYYY-MM-DD (default: first of this month)")
+	cmd.Flags().StringVar(&to, "to", "", "last day, included (default: today)")
+	cmd.Flags().StringVar(&subscriberID, "subscriber", "", "only this subscriber's usage")
+	cmd.Flags().BoolVar(&csv, "csv", false, "download the usage per day as CSV")
+	cmd.Flags().StringVar(&output, "out-file", "", "with --csv, write to this file instead of standard output")
+	return cmd
+}
@@ -1,16 +1,27 @@
+import { useState } from 'react'
+import Nav from './components/Nav'
 import MetricsCards from './components/MetricsCards'
+import TimeseriesCharts from './components/TimeseriesCharts'
 import LiveFeed from './components/LiveFeed'
 import SubscriberHealth from './components/SubscriberHealth'
+import SubscriberDetail from './components/SubscriberDetail'
 import DeadLetterQueue from './components/DeadLetterQueue'
+import EventSearch from './components/EventSearch'
 import DemoButton from './components/DemoButton'
 import { useWebSocket } from './hooks/useWebSocket'
+import { useHashRoute } from './hooks/useHashRoute'
 import { useMetrics, useSubscriberHealth, useDeadLetters } from './hooks/useApi'
 
 function App() {
+  const { page, params } = useHashRoute()
   const { events, connected, clearEvents } = useWebSocket()
   const { metrics } = useMetrics()
   const { subscribers } = useSubscriberHealth()
-  const { deadLetters, resolve } = useDeadLetters()
+  const [showResolved, setShowResolved] = useState(false)
+  const { deadLetters, resolve, replay } = useDeadLetters({ resolved: page === 'dead-letters' && showResolved })
+  const [selectedSubscriber, setSelectedSubscriber] = useState(null)
+
+  const subscriber = subscribers.find((sub) => sub.id === selectedSubscriber)
 
   return (
     <div className="min-h-screen bg-gray-50">
@@ -24,15 +35,54 @@ function App() {
           <DemoButton />
         </div>
       </header>
+      <Nav page={page} />
 
       {/* Main content */}
       <main className="max-w-7xl mx-auto px-6 py-6 space-y-6">
-        <MetricsCards metrics={metrics} />
-        <LiveFeed events={events} connected={connected} onClear={clearEvents} />
-        <div className="grid grid-cols-1 lg:grid-cols-2 gap-6">
-          <SubscriberHealth subscribers={subscribers} />
-          <DeadLetterQueue deadLetters={deadLetters} onResolve={resolve} />
-        </div>
+        {page === 'overview' && (
+          <>
+            <MetricsCards metrics={metrics} />
+            <TimeseriesCharts />
+            <div className="grid grid-cols-1 lg:grid-cols-2 gap-6">
+              <LiveFeed events={events.slice(0, 15)} connected={connected} onClear={clearEvents} compact />
+              <SubscriberHealth subscribers={subscribers} />
+            </div>
+          </>
+        )}
+
+        {page === 'deliveries' && (
+          <LiveFeed events={events} connected={connected} onClear={clearEvents} subscribers={subscribers} />
+        )}
+
+        {page === 'subscribers' && (
+          <div className="grid grid-cols-1 lg:grid-cols-2 gap-6 items-start">
+            <SubscriberHealth
+              subscribers={subscribers}
+              selectedId={selectedSubscriber}
+              onSelect={setSelectedSubscriber}
+            />
+            {subscriber ? (
+              <SubscriberDetail key={subscriber.id} subscriber={subscriber} />
+            ) : (
+              <div className="px-5 py-8 text-center text-gray-400 text-sm">
+                Select a subscriber to see its stats and pause its deliveries.
+              </div>
+            )}
+          </div>
+        )}
+
+        {page === 'dead-letters' && (
+          <DeadLetterQueue
+            deadLetters={deadLetters}
+            onResolve={resolve}
+            onReplay={replay}
+            resolved={showResolved}
+            onToggleResolved={setShowResolved}
+            browse
+          />
+        )}
+
+        {page === 'events' && <EventSearch key={params.get('id')} initialId={params.get('id') || ''} />}
       </main>
     </div>
   )
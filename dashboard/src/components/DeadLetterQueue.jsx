@@ -1,18 +1,70 @@
+import { Fragment, useState } from 'react'
+import { usePolling } from '../hooks/useApi'
+
 function truncate(str, len) {
   if (!str) return ''
   return str.length > len ? str.slice(0, len) + '...' : str
 }
 
-export default function DeadLetterQueue({ deadLetters, onResolve }) {
+// DeadLetterDetail loads a single dead letter, which unlike the list
+// includes the snapshotted payload.
+function DeadLetterDetail({ id }) {
+  const { data: dl, error } = usePolling(`/dead-letters/${id}`)
+  if (error) return <p className="px-5 py-3 text-xs text-red-600">{error}</p>
+  if (!dl) return <div className="mx-5 my-3 h-16 bg-gray-100 rounded animate-pulse"></div>
+
+  return (
+    <div className="px-5 py-3 space-y-2 text-xs text-gray-600">
+      <p>
+        <span className="text-gray-400">Event:</span>{' '}
+        <a href={`#/events?id=${dl.event_id}`} className="font-mono hover:text-blue-600">{dl.event_id}</a>
+        {dl.event_type && <span className="ml-3 text-gray-400">Type:</span>} {dl.event_type}
+        <span className="ml-3 text-gray-400">Created:</span> {new Date(dl.created_at).toLocaleString()}
+      </p>
+      {dl.last_error && <p className="text-red-600">{dl.last_error}</p>}
+      {dl.resolved_at && (
+        <p>
+          <span className="text-gray-400">Resolved:</span> {new Date(dl.resolved_at).toLocaleString()} by {dl.resolved_by}
+        </p>
+      )}
+      <pre className="bg-gray-50 rounded p-3 overflow-x-auto max-h-64">
+        {dl.payload ? JSON.stringify(dl.payload, null, 2) : 'No payload snapshot.'}
+      </pre>
+    </div>
+  )
+}
+
+export default function DeadLetterQueue({ deadLetters, onResolve, onReplay, resolved, onToggleResolved, browse = false }) {
+  const [openId, setOpenId] = useState(null)
+  const [actionError, setActionError] = useState(null)
+
+  async function act(fn, id) {
+    setActionError(null)
+    try {
+      await fn(id)
+    } catch (err) {
+      setActionError(err.message)
+    }
+  }
+
   return (
     <div className="bg-white rounded-lg shadow">
-      <div className="px-5 py-3 border-b border-gray-100">
+      <div className="flex items-center justify-between px-5 py-3 border-b border-gray-100">
         <h2 className="text-lg font-semibold text-gray-800">Dead Letter Queue</h2>
+        <div className="flex items-center gap-3">
+          {actionError && <span className="text-xs text-red-600">{actionError}</span>}
+          {browse && (
+            <label className="flex items-center gap-1 text-xs text-gray-500">
+              <input type="checkbox" checked={resolved} onChange={(e) => onToggleResolved(e.target.checked)} />
+              Show resolved
+            </label>
+          )}
+        </div>
       </div>
 
       {deadLetters.length === 0 ? (
         <div className="px-5 py-8 text-center text-gray-400 text-sm">
-          No dead letters. All deliveries are healthy.
+          {resolved ? 'No resolved dead letters.' : 'No dead letters. All deliveries are healthy.'}
         </div>
       ) : (
         <div className="overflow-x-auto">
@@ -29,35 +81,57 @@ export default function DeadLetterQueue({ deadLetters, onResolve }) {
             </thead>
             <tbody className="divide-y divide-gray-50">
               {deadLetters.map((dl) => (
-                <tr key={dl.id} className="hover:bg-gray-50">
-                  <td className="px-5 py-3 font-mono text-xs text-gray-600" title={dl.event_id}>
-                    {truncate(dl.event_id, 8)}
-                  </td>
-                  <td className="px-5 py-3 font-mono text-xs text-gray-600" title={dl.subscriber_id}>
-                    {truncate(dl.subscriber_id, 8)}
-                  </td>
-                  <td className="px-5 py-3 text-gray-600">{dl.total_attempts}</td>
-                  <td className="px-5 py-3">
-                    {dl.last_http_status ? (
-                      <span className="px-2 py-0.5 rounded text-xs font-medium bg-red-100 text-red-800">
-                        {dl.last_http_status}
-                      </span>
-                    ) : (
-                      <span className="text-gray-400">—</span>
-                    )}
-                  </td>
-                  <td className="px-5 py-3 text-gray-500 text-xs truncate max-w-40" title={dl.last_error}>
-                    {dl.last_error || '—'}
-                  </td>
-                  <td className="px-5 py-3">
-                    <button
-                      onClick={() => onResolve(dl.id)}
-                      className="text-xs px-3 py-1 rounded bg-blue-50 text-blue-700 hover:bg-blue-100 font-medium"
-                    >
-                      Resolve
-                    </button>
-                  </td>
-                </tr>
+                <Fragment key={dl.id}>
+                  <tr
+                    onClick={browse ? () => setOpenId(openId === dl.id ? null : dl.id) : undefined}
+                    className={`hover:bg-gray-50 ${browse ? 'cursor-pointer' : ''}`}
+                  >
+                    <td className="px-5 py-3 font-mono text-xs text-gray-600" title={dl.event_id}>
+                      {truncate(dl.event_id, 8)}
+                    </td>
+                    <td className="px-5 py-3 font-mono text-xs text-gray-600" title={dl.subscriber_id}>
+                      {truncate(dl.subscriber_id, 8)}
+                    </td>
+                    <td className="px-5 py-3 text-gray-600">{dl.total_attempts}</td>
+                    <td className="px-5 py-3">
+                      {dl.last_http_status ? (
+                        <span className="px-2 py-0.5 rounded text-xs font-medium bg-red-100 text-red-800">
+                          {dl.last_http_status}
+                        </span>
+                      ) : (
+                        <span className="text-gray-400">—</span>
+                      )}
+                    </td>
+                    <td className="px-5 py-3 text-gray-500 text-xs truncate max-w-40" title={dl.last_error}>
+                      {dl.last_error || '—'}
+                    </td>
+                    <td className="px-5 py-3 space-x-2 whitespace-nowrap" onClick={(e) => e.stopPropagation()}>
+                      {onReplay && (
+                        <button
+                          onClick={() => act(onReplay, dl.id)}
+                          className="text-xs px-3 py-1 rounded bg-green-50 text-green-700 hover:bg-green-100 font-medium"
+                        >
+                          Replay
+                        </button>
+                      )}
+                      {!dl.resolved_at && (
+                        <button
+                          onClick={() => act(onResolve, dl.id)}
+                          className="text-xs px-3 py-1 rounded bg-blue-50 text-blue-700 hover:bg-blue-100 font-medium"
+                        >
+                          Resolve
+                        </button>
+                      )}
+                    </td>
+                  </tr>
+                  {openId === dl.id && (
+                    <tr>
+                      <td colSpan={6} className="bg-gray-50/50">
+                        <DeadLetterDetail id={dl.id} />
+                      </td>
+                    </tr>
+                  )}
+                </Fragment>
               ))}
             </tbody>
           </table>
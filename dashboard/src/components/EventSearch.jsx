@@ -0,0 +1,166 @@
+import { useEffect, useState } from 'react'
+import { apiRequest, usePolling } from '../hooks/useApi'
+
+const UUID_RE = /^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$/i
+
+const statusStyles = {
+  success: 'bg-green-100 text-green-800',
+  failed: 'bg-red-100 text-red-800',
+  simulated: 'bg-blue-100 text-blue-800',
+}
+
+// lookup resolves an ID to an event. Delivery IDs, as shown in the live feed
+// and sent in X-Webhook-Delivery-ID, are resolved to the event they belong to.
+async function lookup(id) {
+  try {
+    return await apiRequest('GET', `/events/${id}`)
+  } catch {
+    const attempt = await apiRequest('GET', `/deliveries/${id}`).catch(() => null)
+    if (!attempt) throw new Error('No event or delivery with that ID')
+    return apiRequest('GET', `/events/${attempt.event_id}`)
+  }
+}
+
+function EventDetail({ event, highlight }) {
+  const { data: attempts } = usePolling(`/deliveries?event_id=${event.id}`, 5000)
+
+  return (
+    <div className="bg-white rounded-lg shadow">
+      <div className="px-5 py-3 border-b border-gray-100">
+        <h2 className="text-lg font-semibold text-gray-800">{event.event_type}</h2>
+        <p className="text-xs text-gray-400">
+          <span className="font-mono">{event.id}</span> · {new Date(event.created_at).toLocaleString()}
+          {event.source && ` · ${event.source}`}
+        </p>
+      </div>
+      <pre className="mx-5 my-3 bg-gray-50 rounded p-3 text-xs overflow-x-auto max-h-64">
+        {JSON.stringify(event.payload, null, 2)}
+      </pre>
+      <table className="w-full text-sm">
+        <thead>
+          <tr className="text-left text-gray-500 border-y border-gray-100">
+            <th className="px-5 py-2 font-medium">Delivery ID</th>
+            <th className="px-5 py-2 font-medium">Subscriber</th>
+            <th className="px-5 py-2 font-medium">Attempt</th>
+            <th className="px-5 py-2 font-medium">Status</th>
+            <th className="px-5 py-2 font-medium">Time</th>
+            <th className="px-5 py-2 font-medium">Error</th>
+          </tr>
+        </thead>
+        <tbody className="divide-y divide-gray-50">
+          {(attempts || []).map((a) => (
+            <tr key={a.id} className={a.id === highlight ? 'bg-blue-50' : 'hover:bg-gray-50'}>
+              <td className="px-5 py-2 font-mono text-xs text-gray-600">{a.id}</td>
+              <td className="px-5 py-2 font-mono text-xs text-gray-600" title={a.subscriber_id}>
+                {a.subscriber_id.slice(0, 8)}
+              </td>
+              <td className="px-5 py-2 text-gray-600">{a.attempt_number}</td>
+              <td className="px-5 py-2">
+                <span className={`px-2 py-0.5 rounded text-xs font-medium ${statusStyles[a.status] || 'bg-gray-100 text-gray-700'}`}>
+                  {a.http_status_code || a.status}
+                </span>
+              </td>
+              <td className="px-5 py-2 text-gray-500 text-xs">
+                {new Date(a.created_at).toLocaleTimeString()}
+                {a.response_time_ms != null && ` · ${a.response_time_ms}ms`}
+              </td>
+              <td className="px-5 py-2 text-red-500 text-xs truncate max-w-48" title={a.error_message}>
+                {a.error_message || ''}
+              </td>
+            </tr>
+          ))}
+          {attempts && attempts.length === 0 && (
+            <tr>
+              <td colSpan={6} className="px-5 py-6 text-center text-gray-400 text-sm">No delivery attempts yet.</td>
+            </tr>
+          )}
+        </tbody>
+      </table>
+    </div>
+  )
+}
+
+// EventSearch finds events by type, or by event or delivery ID. An id in
+// the route (#/events?id=...) is looked up on load, which is how the live
+// feed and DLQ browser link here.
+export default function EventSearch({ initialId = '' }) {
+  const [query, setQuery] = useState(initialId)
+  const [eventType, setEventType] = useState('')
+  const [selected, setSelected] = useState(null)
+  const [error, setError] = useState(null)
+  const { data: events } = usePolling(
+    `/events?limit=50${eventType ? `&event_type=${encodeURIComponent(eventType)}` : ''}`,
+    10000,
+  )
+
+  async function search(value) {
+    setError(null)
+    const term = value.trim()
+    if (UUID_RE.test(term)) {
+      try {
+        setSelected(await lookup(term))
+        setEventType('')
+      } catch (err) {
+        setSelected(null)
+        setError(err.message)
+      }
+      return
+    }
+    setSelected(null)
+    setEventType(term)
+  }
+
+  useEffect(() => {
+    if (!initialId) return
+    lookup(initialId).then(setSelected, (err) => setError(err.message))
+  }, [initialId])
+
+  return (
+    <div className="space-y-4">
+      <form
+        onSubmit={(e) => {
+          e.preventDefault()
+          search(query)
+        }}
+        className="flex gap-2"
+      >
+        <input
+          value={query}
+          onChange={(e) => setQuery(e.target.value)}
+          placeholder="Event type (order.created), event ID or delivery ID"
+          className="flex-1 text-sm border border-gray-200 rounded-lg px-3 py-2"
+        />
+        <button type="submit" className="px-4 py-2 bg-blue-600 text-white text-sm font-medium rounded-lg hover:bg-blue-700">
+          Search
+        </button>
+      </form>
+      {error && <p className="text-sm text-red-600">{error}</p>}
+
+      {selected ? (
+        <EventDetail event={selected} highlight={query.trim()} />
+      ) : (
+        <div className="bg-white rounded-lg shadow">
+          <div className="px-5 py-3 border-b border-gray-100">
+            <h2 className="text-lg font-semibold text-gray-800">{eventType ? `${eventType} Events` : 'Recent Events'}</h2>
+          </div>
+          <div className="divide-y divide-gray-50">
+            {(events || []).map((event) => (
+              <button
+                key={event.id}
+                onClick={() => setSelected(event)}
+                className="w-full px-5 py-3 flex items-center gap-3 text-sm text-left hover:bg-gray-50"
+              >
+                <span className="text-gray-500 w-40 shrink-0">{new Date(event.created_at).toLocaleString()}</span>
+                <span className="font-medium text-gray-800 w-48 shrink-0 truncate">{event.event_type}</span>
+                <span className="font-mono text-xs text-gray-400">{event.id}</span>
+              </button>
+            ))}
+            {events && events.length === 0 && (
+              <div className="px-5 py-8 text-center text-gray-400 text-sm">No events found.</div>
+            )}
+          </div>
+        </div>
+      )}
+    </div>
+  )
+}
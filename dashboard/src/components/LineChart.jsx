@@ -0,0 +1,66 @@
+const WIDTH = 600
+const HEIGHT = 160
+const PAD = { top: 10, right: 10, bottom: 20, left: 40 }
+
+function formatTick(time) {
+  return new Date(time).toLocaleTimeString([], { hour: '2-digit', minute: '2-digit' })
+}
+
+// LineChart draws one or more series over shared x values as an SVG, so the
+// dashboard needs no charting library. Each series is { label, color, values }
+// with one value per point in xs.
+export default function LineChart({ title, xs, series, unit = '', max }) {
+  const top = max ?? Math.max(1, ...series.flatMap((s) => s.values))
+  const plotW = WIDTH - PAD.left - PAD.right
+  const plotH = HEIGHT - PAD.top - PAD.bottom
+
+  const x = (i) => PAD.left + (xs.length > 1 ? (i / (xs.length - 1)) * plotW : plotW / 2)
+  const y = (v) => PAD.top + plotH - (v / top) * plotH
+
+  return (
+    <div className="bg-white rounded-lg shadow p-4">
+      <div className="flex items-center justify-between mb-2">
+        <h3 className="text-sm font-semibold text-gray-700">{title}</h3>
+        <div className="flex gap-3">
+          {series.map((s) => (
+            <span key={s.label} className="flex items-center gap-1 text-xs text-gray-500">
+              <span className="inline-block w-3 h-0.5" style={{ backgroundColor: s.color }}></span>
+              {s.label}
+            </span>
+          ))}
+        </div>
+      </div>
+
+      {xs.length === 0 ? (
+        <div className="py-12 text-center text-gray-400 text-sm">No data in this window.</div>
+      ) : (
+        <svg viewBox={`0 0 ${WIDTH} ${HEIGHT}`} className="w-full h-40">
+          {[0, 0.5, 1].map((f) => (
+            <g key={f}>
+              <line
+                x1={PAD.left} x2={WIDTH - PAD.right} y1={y(top * f)} y2={y(top * f)}
+                stroke="#f3f4f6"
+              />
+              <text x={PAD.left - 4} y={y(top * f) + 3} textAnchor="end" fontSize="9" fill="#9ca3af">
+                {Math.round(top * f)}{unit}
+              </text>
+            </g>
+          ))}
+          <text x={PAD.left} y={HEIGHT - 4} fontSize="9" fill="#9ca3af">{formatTick(xs[0])}</text>
+          <text x={WIDTH - PAD.right} y={HEIGHT - 4} textAnchor="end" fontSize="9" fill="#9ca3af">
+            {formatTick(xs[xs.length - 1])}
+          </text>
+          {series.map((s) => (
+            <polyline
+              key={s.label}
+              fill="none"
+              stroke={s.color}
+              strokeWidth="1.5"
+              points={s.values.map((v, i) => `${x(i)},${y(v)}`).join(' ')}
+            />
+          ))}
+        </svg>
+      )}
+    </div>
+  )
+}
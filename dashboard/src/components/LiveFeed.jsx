@@ -1,8 +1,11 @@
+import { useState } from 'react'
+
 const typeStyles = {
   delivery_success: { label: 'SUCCESS', bg: 'bg-green-100', text: 'text-green-800' },
   delivery_retrying: { label: 'RETRY', bg: 'bg-yellow-100', text: 'text-yellow-800' },
   delivery_failed: { label: 'FAILED', bg: 'bg-red-100', text: 'text-red-800' },
   delivery_dlq: { label: 'DLQ', bg: 'bg-red-200', text: 'text-red-900' },
+  delivery_simulated: { label: 'SANDBOX', bg: 'bg-blue-100', text: 'text-blue-800' },
 }
 
 function formatTime(timestamp) {
@@ -14,7 +17,14 @@ function truncate(str, len) {
   return str.length > len ? str.slice(0, len) + '...' : str
 }
 
-export default function LiveFeed({ events, connected, onClear }) {
+export default function LiveFeed({ events, connected, onClear, subscribers = [], compact = false }) {
+  const [type, setType] = useState('')
+  const [subscriberId, setSubscriberId] = useState('')
+
+  const visible = events.filter(
+    (event) => (!type || event.type === type) && (!subscriberId || event.subscriber_id === subscriberId),
+  )
+
   return (
     <div className="bg-white rounded-lg shadow">
       <div className="flex items-center justify-between px-5 py-3 border-b border-gray-100">
@@ -23,36 +33,77 @@ export default function LiveFeed({ events, connected, onClear }) {
           <span className={`inline-block w-2 h-2 rounded-full ${connected ? 'bg-green-500' : 'bg-red-500'}`}></span>
           <span className="text-xs text-gray-400">{connected ? 'Connected' : 'Disconnected'}</span>
         </div>
-        <button
-          onClick={onClear}
-          className="text-xs text-gray-400 hover:text-gray-600 px-2 py-1 rounded hover:bg-gray-100"
-        >
-          Clear
-        </button>
+        <div className="flex items-center gap-2">
+          {!compact && (
+            <>
+              <select
+                value={type}
+                onChange={(e) => setType(e.target.value)}
+                className="text-xs border border-gray-200 rounded px-2 py-1 text-gray-600"
+              >
+                <option value="">All outcomes</option>
+                {Object.entries(typeStyles).map(([value, style]) => (
+                  <option key={value} value={value}>{style.label}</option>
+                ))}
+              </select>
+              <select
+                value={subscriberId}
+                onChange={(e) => setSubscriberId(e.target.value)}
+                className="text-xs border border-gray-200 rounded px-2 py-1 text-gray-600"
+              >
+                <option value="">All subscribers</option>
+                {subscribers.map((sub) => (
+                  <option key={sub.id} value={sub.id}>{sub.name}</option>
+                ))}
+              </select>
+            </>
+          )}
+          <button
+            onClick={onClear}
+            className="text-xs text-gray-400 hover:text-gray-600 px-2 py-1 rounded hover:bg-gray-100"
+          >
+            Clear
+          </button>
+        </div>
       </div>
 
-      <div className="divide-y divide-gray-50 max-h-96 overflow-y-auto">
-        {events.length === 0 ? (
+      <div className={`divide-y divide-gray-50 overflow-y-auto ${compact ? 'max-h-96' : 'max-h-[70vh]'}`}>
+        {visible.length === 0 ? (
           <div className="px-5 py-8 text-center text-gray-400 text-sm">
             Waiting for delivery events...
           </div>
         ) : (
-          events.map((event, i) => {
+          visible.map((event, i) => {
             const style = typeStyles[event.type] || typeStyles.delivery_failed
             return (
-              <div key={`${event.event_id}-${event.attempt}-${i}`} className="px-5 py-3 flex items-center gap-3 text-sm hover:bg-gray-50">
+              <div key={event.delivery_id || `${event.event_id}-${event.attempt}-${i}`} className="px-5 py-3 flex items-center gap-3 text-sm hover:bg-gray-50">
                 <span className={`px-2 py-0.5 rounded text-xs font-medium ${style.bg} ${style.text}`}>
                   {style.label}
                 </span>
                 <span className="text-gray-500 w-20 shrink-0">{formatTime(event.timestamp)}</span>
-                <span className="text-gray-700 font-mono text-xs truncate" title={event.event_id}>
+                {!compact && (
+                  <span className="text-gray-500 text-xs w-32 shrink-0 truncate" title={event.event_type}>
+                    {event.event_type}
+                  </span>
+                )}
+                <a
+                  href={`#/events?id=${event.delivery_id || event.event_id}`}
+                  className="text-gray-700 font-mono text-xs truncate hover:text-blue-600"
+                  title={event.delivery_id ? `delivery ${event.delivery_id}` : event.event_id}
+                >
                   {truncate(event.event_id, 8)}
-                </span>
+                </a>
                 <span className="text-gray-400">→</span>
                 <span className="text-gray-600 truncate" title={event.endpoint_url}>
                   {truncate(event.endpoint_url, 35)}
                 </span>
+                {!compact && event.error && (
+                  <span className="text-red-500 text-xs truncate max-w-48" title={event.error}>
+                    {event.error}
+                  </span>
+                )}
                 <span className="ml-auto text-gray-400 text-xs shrink-0">
+                  {event.status_code && `${event.status_code} · `}
                   {event.response_ms}ms
                   {event.attempt > 1 && ` (attempt ${event.attempt})`}
                 </span>
@@ -0,0 +1,29 @@
+const pages = [
+  { route: 'overview', label: 'Overview' },
+  { route: 'deliveries', label: 'Live Feed' },
+  { route: 'subscribers', label: 'Subscribers' },
+  { route: 'dead-letters', label: 'Dead Letters' },
+  { route: 'events', label: 'Events' },
+]
+
+export default function Nav({ page }) {
+  return (
+    <nav className="bg-white border-b border-gray-200 px-6">
+      <div className="max-w-7xl mx-auto flex gap-1">
+        {pages.map((item) => (
+          <a
+            key={item.route}
+            href={`#/${item.route}`}
+            className={`px-3 py-2 text-sm font-medium border-b-2 ${
+              page === item.route
+                ? 'border-blue-600 text-blue-700'
+                : 'border-transparent text-gray-500 hover:text-gray-700'
+            }`}
+          >
+            {item.label}
+          </a>
+        ))}
+      </div>
+    </nav>
+  )
+}
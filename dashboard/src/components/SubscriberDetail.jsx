@@ -0,0 +1,112 @@
+import { useState } from 'react'
+import { apiRequest, usePolling } from '../hooks/useApi'
+
+const statsWindows = ['1h', '24h', '7d']
+
+function Stat({ label, value }) {
+  return (
+    <div>
+      <p className="text-xs text-gray-500">{label}</p>
+      <p className="text-lg font-semibold text-gray-800">{value}</p>
+    </div>
+  )
+}
+
+// SubscriberDetail shows delivery stats, pause state and waiting jobs for one
+// subscriber, with controls to pause and resume its deliveries.
+export default function SubscriberDetail({ subscriber }) {
+  const [statsWindow, setStatsWindow] = useState('24h')
+  const [actionError, setActionError] = useState(null)
+  const { data: stats } = usePolling(`/subscribers/${subscriber.id}/stats?window=${statsWindow}`, 10000)
+  const { data: pause, refresh: refreshPause } = usePolling(`/subscribers/${subscriber.id}/pause`, 5000)
+  const { data: pending, refresh: refreshPending } = usePolling(`/subscribers/${subscriber.id}/pending?limit=1`, 5000)
+
+  async function act(method, path, body) {
+    setActionError(null)
+    try {
+      await apiRequest(method, `/subscribers/${subscriber.id}${path}`, body)
+    } catch (err) {
+      setActionError(err.message)
+    }
+    refreshPause()
+    refreshPending()
+  }
+
+  return (
+    <div className="bg-white rounded-lg shadow">
+      <div className="flex items-center justify-between px-5 py-3 border-b border-gray-100">
+        <div>
+          <h2 className="text-lg font-semibold text-gray-800">{subscriber.name}</h2>
+          <p className="text-xs text-gray-400 font-mono">{subscriber.id}</p>
+        </div>
+        <div className="flex gap-1">
+          {statsWindows.map((w) => (
+            <button
+              key={w}
+              onClick={() => setStatsWindow(w)}
+              className={`text-xs px-2 py-1 rounded font-medium ${
+                statsWindow === w ? 'bg-blue-600 text-white' : 'text-gray-600 hover:bg-gray-100'
+              }`}
+            >
+              {w}
+            </button>
+          ))}
+        </div>
+      </div>
+
+      <div className="px-5 py-4 space-y-4">
+        {stats ? (
+          <div className="grid grid-cols-3 gap-4">
+            <Stat label="Attempts" value={stats.total_attempts} />
+            <Stat label="Success Rate" value={`${stats.success_rate.toFixed(1)}%`} />
+            <Stat label="Retries" value={stats.retries} />
+            <Stat label="Latency p50" value={`${Math.round(stats.latency_p50_ms)}ms`} />
+            <Stat label="Latency p95" value={`${Math.round(stats.latency_p95_ms)}ms`} />
+            <Stat label="Open Dead Letters" value={stats.open_dead_letters} />
+          </div>
+        ) : (
+          <div className="h-24 bg-gray-100 rounded animate-pulse"></div>
+        )}
+
+        <div className="flex items-center justify-between border-t border-gray-100 pt-4">
+          <div className="text-sm text-gray-600">
+            {pause?.paused ? (
+              <span className="px-2 py-0.5 rounded text-xs font-medium bg-yellow-100 text-yellow-800">
+                Paused{pause.paused_until && ` until ${new Date(pause.paused_until).toLocaleTimeString()}`}
+              </span>
+            ) : (
+              <span className="px-2 py-0.5 rounded text-xs font-medium bg-green-100 text-green-800">Delivering</span>
+            )}
+            <span className="ml-3">{pending ? pending.total : '—'} pending jobs</span>
+          </div>
+          <div className="flex gap-2">
+            {pause?.paused ? (
+              <button
+                onClick={() => act('POST', '/resume')}
+                className="text-xs px-3 py-1 rounded bg-green-50 text-green-700 hover:bg-green-100 font-medium"
+              >
+                Resume
+              </button>
+            ) : (
+              <>
+                <button
+                  onClick={() => act('POST', '/pause', { duration: '10m' })}
+                  className="text-xs px-3 py-1 rounded bg-yellow-50 text-yellow-700 hover:bg-yellow-100 font-medium"
+                >
+                  Pause 10m
+                </button>
+                <button
+                  onClick={() => act('POST', '/pause')}
+                  className="text-xs px-3 py-1 rounded bg-yellow-50 text-yellow-700 hover:bg-yellow-100 font-medium"
+                >
+                  Pause
+                </button>
+              </>
+            )}
+          </div>
+        </div>
+        {actionError && <p className="text-xs text-red-600">{actionError}</p>}
+      </div>
+    </div>
+  )
+}
@@ -4,7 +4,7 @@ const stateColors = {
   'half-open': { bg: 'bg-yellow-100', text: 'text-yellow-800', label: 'Half-Open' },
 }
 
-export default function SubscriberHealth({ subscribers }) {
+export default function SubscriberHealth({ subscribers, selectedId, onSelect }) {
   if (subscribers.length === 0) {
     return (
       <div className="bg-white rounded-lg shadow">
@@ -38,7 +38,11 @@ export default function SubscriberHealth({ subscribers }) {
             {subscribers.map((sub) => {
               const cbStyle = stateColors[sub.circuit_breaker.state] || stateColors.closed
               return (
-                <tr key={sub.id} className="hover:bg-gray-50">
+                <tr
+                  key={sub.id}
+                  onClick={onSelect && (() => onSelect(sub.id))}
+                  className={`hover:bg-gray-50 ${onSelect ? 'cursor-pointer' : ''} ${selectedId === sub.id ? 'bg-blue-50' : ''}`}
+                >
                   <td className="px-5 py-3 font-medium text-gray-800">{sub.name}</td>
                   <td className="px-5 py-3 text-gray-500 font-mono text-xs truncate max-w-48"
                       title={sub.endpoint_url}>
@@ -0,0 +1,76 @@
+import { useState } from 'react'
+import LineChart from './LineChart'
+import { useTimeseries } from '../hooks/useApi'
+
+// Windows offered by the selector, with a bucket interval that keeps each
+// chart to a few dozen points.
+const windows = [
+  { window: '1h', interval: '1m' },
+  { window: '6h', interval: '10m' },
+  { window: '24h', interval: '1h' },
+  { window: '7d', interval: '6h' },
+]
+
+export default function TimeseriesCharts() {
+  const [selected, setSelected] = useState(windows[0])
+  const { timeseries, error } = useTimeseries(selected.window, selected.interval)
+
+  const buckets = timeseries?.buckets || []
+  const starts = buckets.map((b) => b.start)
+  const depth = timeseries?.queue_depth || []
+
+  return (
+    <div className="space-y-4">
+      <div className="flex items-center justify-between">
+        <h2 className="text-lg font-semibold text-gray-800">Delivery Trends</h2>
+        <div className="flex items-center gap-2">
+          {error && <span className="text-xs text-red-600">{error}</span>}
+          {windows.map((w) => (
+            <button
+              key={w.window}
+              onClick={() => setSelected(w)}
+              className={`text-xs px-2 py-1 rounded font-medium ${
+                selected.window === w.window ? 'bg-blue-600 text-white' : 'bg-white text-gray-600 hover:bg-gray-100'
+              }`}
+            >
+              {w.window}
+            </button>
+          ))}
+        </div>
+      </div>
+
+      <div className="grid grid-cols-1 lg:grid-cols-2 gap-4">
+        <LineChart
+          title="Deliveries"
+          xs={starts}
+          series={[
+            { label: 'Succeeded', color: '#16a34a', values: buckets.map((b) => b.success_count) },
+            { label: 'Failed', color: '#dc2626', values: buckets.map((b) => b.failed_count) },
+          ]}
+        />
+        <LineChart
+          title="Success Rate"
+          xs={starts}
+          unit="%"
+          max={100}
+          series={[{ label: 'Success rate', color: '#2563eb', values: buckets.map((b) => b.success_rate) }]}
+        />
+        <LineChart
+          title="Latency"
+          xs={starts}
+          unit="ms"
+          series={[
+            { label: 'p50', color: '#16a34a', values: buckets.map((b) => b.latency_p50_ms) },
+            { label: 'p95', color: '#ca8a04', values: buckets.map((b) => b.latency_p95_ms) },
+            { label: 'p99', color: '#dc2626', values: buckets.map((b) => b.latency_p99_ms) },
+          ]}
+        />
+        <LineChart
+          title="Queue Depth"
+          xs={depth.map((d) => d.at)}
+          series={[{ label: 'Depth', color: '#9333ea', values: depth.map((d) => d.depth) }]}
+        />
+      </div>
+    </div>
+  )
+}
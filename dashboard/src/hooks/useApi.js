@@ -1,100 +1,110 @@
 import { useState, useEffect, useCallback } from 'react'
 
 const API_BASE = '/api/v1'
+const TOKEN_STORAGE_KEY = 'webhookApiKey'
+
+// apiToken returns the API key used when the server has AUTH_ENABLED set.
+// Opening the dashboard with ?token=<key> stores it for later visits.
+export function apiToken() {
+  const fromUrl = new URLSearchParams(window.location.search).get('token')
+  if (fromUrl) {
+    localStorage.setItem(TOKEN_STORAGE_KEY, fromUrl)
+    return fromUrl
+  }
+  return localStorage.getItem(TOKEN_STORAGE_KEY) || ''
+}
+
+function authHeaders(headers = {}) {
+  const token = apiToken()
+  return token ? { ...headers, Authorization: `Bearer ${token}` } : headers
+}
 
 async function fetchJSON(url) {
-  const res = await fetch(url)
+  const res = await fetch(url, { headers: authHeaders() })
   if (!res.ok) throw new Error(`HTTP ${res.status}`)
   return res.json()
 }
 
-export function useMetrics(refreshInterval = 2000) {
-  const [metrics, setMetrics] = useState(null)
+// apiRequest calls a management endpoint and returns the decoded response,
+// throwing with the server's error message on failure.
+export async function apiRequest(method, path, body) {
+  const res = await fetch(`${API_BASE}${path}`, {
+    method,
+    headers: authHeaders(body ? { 'Content-Type': 'application/json' } : {}),
+    body: body ? JSON.stringify(body) : undefined,
+  })
+  const data = await res.json().catch(() => null)
+  if (!res.ok) throw new Error(data?.error || `HTTP ${res.status}`)
+  return data
+}
+
+// usePolling fetches an API path and refetches it every refreshInterval ms.
+// A null path fetches nothing; a refreshInterval of 0 fetches once.
+export function usePolling(path, refreshInterval = 0) {
+  const [data, setData] = useState(null)
   const [error, setError] = useState(null)
 
   const refresh = useCallback(async () => {
+    if (!path) return
     try {
-      const data = await fetchJSON(`${API_BASE}/metrics`)
-      setMetrics(data)
+      setData(await fetchJSON(`${API_BASE}${path}`))
       setError(null)
     } catch (err) {
       setError(err.message)
     }
-  }, [])
+  }, [path])
 
   useEffect(() => {
     refresh()
+    if (!refreshInterval) return undefined
     const interval = setInterval(refresh, refreshInterval)
     return () => clearInterval(interval)
   }, [refresh, refreshInterval])
 
-  return { metrics, error, refresh }
+  return { data, error, refresh }
 }
 
-export function useSubscriberHealth(refreshInterval = 3000) {
-  const [subscribers, setSubscribers] = useState([])
-  const [error, setError] = useState(null)
-
-  const refresh = useCallback(async () => {
-    try {
-      const data = await fetchJSON(`${API_BASE}/subscribers-health`)
-      setSubscribers(data)
-      setError(null)
-    } catch (err) {
-      setError(err.message)
-    }
-  }, [])
-
-  useEffect(() => {
-    refresh()
-    const interval = setInterval(refresh, refreshInterval)
-    return () => clearInterval(interval)
-  }, [refresh, refreshInterval])
+export function useMetrics(refreshInterval = 2000) {
+  const { data, error, refresh } = usePolling('/metrics', refreshInterval)
+  return { metrics: data, error, refresh }
+}
 
-  return { subscribers, error, refresh }
+export function useTimeseries(span, interval, refreshInterval = 30000) {
+  const { data, error, refresh } = usePolling(
+    `/metrics/timeseries?window=${span}&interval=${interval}`,
+    refreshInterval,
+  )
+  return { timeseries: data, error, refresh }
 }
 
-export function useDeadLetters(refreshInterval = 5000) {
-  const [deadLetters, setDeadLetters] = useState([])
-  const [error, setError] = useState(null)
+export function useSubscriberHealth(refreshInterval = 3000) {
+  const { data, error, refresh } = usePolling('/subscribers-health', refreshInterval)
+  return { subscribers: data || [], error, refresh }
+}
 
-  const refresh = useCallback(async () => {
-    try {
-      const data = await fetchJSON(`${API_BASE}/dead-letters`)
-      setDeadLetters(data)
-      setError(null)
-    } catch (err) {
-      setError(err.message)
-    }
-  }, [])
+export function useDeadLetters({ resolved = false, subscriberId = '' } = {}, refreshInterval = 5000) {
+  const query = new URLSearchParams({ resolved: String(resolved), limit: '100' })
+  if (subscriberId) query.set('subscriber_id', subscriberId)
+  const { data, error, refresh } = usePolling(`/dead-letters?${query}`, refreshInterval)
 
   const resolve = useCallback(async (id) => {
-    try {
-      await fetch(`${API_BASE}/dead-letters/${id}/resolve`, {
-        method: 'POST',
-        headers: { 'Content-Type': 'application/json' },
-        body: JSON.stringify({ resolved_by: 'dashboard' }),
-      })
-      refresh()
-    } catch (err) {
-      setError(err.message)
-    }
+    await apiRequest('POST', `/dead-letters/${id}/resolve`, { resolved_by: 'dashboard' })
+    refresh()
   }, [refresh])
 
-  useEffect(() => {
+  const replay = useCallback(async (id) => {
+    await apiRequest('POST', `/dead-letters/${id}/replay`)
     refresh()
-    const interval = setInterval(refresh, refreshInterval)
-    return () => clearInterval(interval)
-  }, [refresh, refreshInterval])
+  }, [refresh])
 
-  return { deadLetters, error, refresh, resolve }
+  return { deadLetters: data || [], error, refresh, resolve, replay }
 }
 
 export async function runDemo() {
   // Step 1: Create a test subscriber pointing to mock endpoint
   const subRes = await fetch(`${API_BASE}/subscribers`, {
     method: 'POST',
-    headers: { 'Content-Type': 'application/json' },
+    headers: authHeaders({ 'Content-Type': 'application/json' }),
     body: JSON.stringify({
       name: `Demo Subscriber ${Date.now().toString(36)}`,
       endpoint_url: 'http://localhost:9090/webhook/success',
@@ -107,7 +117,7 @@ export async function runDemo() {
   // Step 2: Create a failing subscriber to demonstrate retries + DLQ
   await fetch(`${API_BASE}/subscribers`, {
     method: 'POST',
-    headers: { 'Content-Type': 'application/json' },
+    headers: authHeaders({ 'Content-Type': 'application/json' }),
     body: JSON.stringify({
       name: `Demo Flaky ${Date.now().toString(36)}`,
       endpoint_url: 'http://localhost:9090/webhook/flaky',
@@ -120,7 +130,7 @@ export async function runDemo() {
   for (let i = 0; i < 3; i++) {
     await fetch(`${API_BASE}/events`, {
       method: 'POST',
-      headers: { 'Content-Type': 'application/json' },
+      headers: authHeaders({ 'Content-Type': 'application/json' }),
       body: JSON.stringify({
         event_type: 'demo.event',
         payload: {
@@ -0,0 +1,21 @@
+import { useEffect, useState } from 'react'
+
+function currentRoute() {
+  const [page, query = ''] = window.location.hash.replace(/^#\/?/, '').split('?')
+  return { page: page || 'overview', params: new URLSearchParams(query) }
+}
+
+// useHashRoute returns the page named in the URL fragment (#/events?id=...)
+// and its query parameters, so views can be linked to and survive reloads
+// without the server needing to know about client-side routes.
+export function useHashRoute() {
+  const [route, setRoute] = useState(currentRoute)
+
+  useEffect(() => {
+    const onChange = () => setRoute(currentRoute())
+    window.addEventListener('hashchange', onChange)
+    return () => window.removeEventListener('hashchange', onChange)
+  }, [])
+
+  return route
+}
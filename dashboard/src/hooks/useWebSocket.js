@@ -1,14 +1,20 @@
 import { useEffect, useRef, useState, useCallback } from 'react'
+import { apiToken } from './useApi'
 
 export function useWebSocket() {
   const [events, setEvents] = useState([])
+  const [snapshot, setSnapshot] = useState(null)
   const [connected, setConnected] = useState(false)
   const wsRef = useRef(null)
   const reconnectTimer = useRef(null)
 
   const connect = useCallback(() => {
     const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:'
-    const wsUrl = `${protocol}//${window.location.host}/ws`
+    // Browsers can't set headers on WebSocket connections, so the API key
+    // goes in the query string
+    const token = apiToken()
+    const query = token ? `?token=${encodeURIComponent(token)}` : ''
+    const wsUrl = `${protocol}//${window.location.host}/ws${query}`
 
     const ws = new WebSocket(wsUrl)
     wsRef.current = ws
@@ -20,6 +26,13 @@ export function useWebSocket() {
     ws.onmessage = (event) => {
       try {
         const data = JSON.parse(event.data)
+        if (data.type === 'snapshot') {
+          // Sent first on every (re)connect, followed by a replay of recent events
+          setSnapshot(data)
+          setEvents([])
+          return
+        }
+        if (data.type === 'subscribed' || data.type === 'error') return
         setEvents((prev) => [data, ...prev].slice(0, 100)) // Keep last 100 events
       } catch (err) {
         console.error('Failed to parse WebSocket message:', err)
@@ -47,5 +60,5 @@ export function useWebSocket() {
 
   const clearEvents = useCallback(() => setEvents([]), [])
 
-  return { events, connected, clearEvents }
+  return { events, snapshot, connected, clearEvents }
 }
@@ -0,0 +1,311 @@
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+type fakeStore struct {
+	subscribers []domain.Subscriber
+	deadLetters []store.SubscriberDeadLetterCount
+	buckets     []store.TimeseriesBucket
+	err         error
+}
+
+func (s *fakeStore) ListSubscribers(context.Context) ([]domain.Subscriber, error) {
+	return s.subscribers, s.err
+}
+
+func (s *fakeStore) CountRecentDeadLetters(context.Context, time.Time, int) ([]store.SubscriberDeadLetterCount, error) {
+	return s.deadLetters, s.err
+}
+
+func (s *fakeStore) GetDeliveryTimeseries(context.Context, time.Time, time.Duration) ([]store.TimeseriesBucket, error) {
+	return s.buckets, s.err
+}
+
+type recordingNotifier struct {
+	alerts []Alert
+	err    error
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, a Alert) error {
+	n.alerts = append(n.alerts, a)
+	return n.err
+}
+
+func setupMonitor(t *testing.T, s Store, queue engine.Queue, cfg Config) (*Monitor, *recordingNotifier, *redis.Client) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	n := &recordingNotifier{}
+	m := NewMonitor(s, queue, engine.NewCircuitBreaker(client, logger), client, []Notifier{n}, cfg, logger)
+	return m, n, client
+}
+
+func TestMonitor_FiresOnceAndResolvesOnceAcrossReplicas(t *testing.T) {
+	s := &fakeStore{deadLetters: []store.SubscriberDeadLetterCount{
+		{SubscriberID: "sub-1", Name: "orders", Count: 30},
+		{SubscriberID: "sub-2", Name: "billing", Count: 25},
+	}}
+	m, n, client := setupMonitor(t, s, nil, Config{DLQRateThreshold: 50})
+	replica := NewMonitor(s, nil, m.breaker, client, m.notifiers, m.cfg, m.logger)
+	ctx := context.Background()
+
+	for _, mon := range []*Monitor{m, replica, m} {
+		if err := mon.RunOnce(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(n.alerts) != 1 || n.alerts[0].Key != RuleDLQRate || n.alerts[0].Resolved {
+		t.Fatalf("alerts = %+v, want one firing dlq_rate alert", n.alerts)
+	}
+	if !strings.Contains(n.alerts[0].Summary, "55 deliveries") || !strings.Contains(n.alerts[0].Details, "orders (30)") {
+		t.Errorf("alert = %+v", n.alerts[0])
+	}
+
+	s.deadLetters = nil
+	for _, mon := range []*Monitor{replica, m} {
+		if err := mon.RunOnce(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(n.alerts) != 2 || !n.alerts[1].Resolved || n.alerts[1].Summary != n.alerts[0].Summary {
+		t.Errorf("alerts = %+v, want the alert resolved once", n.alerts)
+	}
+}
+
+func TestMonitor_KeepsAlertsOfFailedRules(t *testing.T) {
+	s := &fakeStore{buckets: []store.TimeseriesBucket{{Total: 200, SuccessCount: 100}}}
+	m, n, _ := setupMonitor(t, s, nil, Config{SuccessRateThreshold: 95, SuccessRateMinAttempts: 100})
+	ctx := context.Background()
+
+	if err := m.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(n.alerts) != 1 || !strings.Contains(n.alerts[0].Summary, "50.0%") {
+		t.Fatalf("alerts = %+v", n.alerts)
+	}
+
+	s.err = errors.New("database down")
+	if err := m.RunOnce(ctx); err == nil {
+		t.Fatal("expected the rule's error")
+	}
+	if len(n.alerts) != 1 {
+		t.Errorf("alert resolved although its rule could not be evaluated: %+v", n.alerts)
+	}
+}
+
+func TestMonitor_SuccessRateNeedsMinimumAttempts(t *testing.T) {
+	s := &fakeStore{buckets: []store.TimeseriesBucket{{Total: 10, SuccessCount: 0}}}
+	m, n, _ := setupMonitor(t, s, nil, Config{SuccessRateThreshold: 95, SuccessRateMinAttempts: 100})
+
+	if err := m.RunOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(n.alerts) != 0 {
+		t.Errorf("alerted on %d attempts: %+v", 10, n.alerts)
+	}
+}
+
+func TestMonitor_QueueDepthMustStayHigh(t *testing.T) {
+	queue := engine.NewMemoryQueue()
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if err := queue.Enqueue(ctx, engine.DeliveryJob{EventID: "evt", SubscriberID: "sub-1", Attempt: i + 1}, time.Now().Add(time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m, n, _ := setupMonitor(t, &fakeStore{}, queue, Config{QueueDepthThreshold: 2, QueueDepthDuration: 5 * time.Minute})
+	now := time.Now()
+	m.now = func() time.Time { return now }
+
+	if err := m.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(n.alerts) != 0 {
+		t.Fatalf("alerted before the depth stayed high: %+v", n.alerts)
+	}
+
+	now = now.Add(5 * time.Minute)
+	if err := m.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(n.alerts) != 1 || n.alerts[0].Summary != "Delivery queue depth has been above 2 for 5m (now 3)" {
+		t.Errorf("alerts = %+v", n.alerts)
+	}
+}
+
+func TestMonitor_CircuitOpenTooLong(t *testing.T) {
+	s := &fakeStore{subscribers: []domain.Subscriber{
+		{ID: "sub-1", Name: "orders", EndpointURL: "https://example.com/hook"},
+		{ID: "sub-2", Name: "billing"},
+	}}
+	m, n, client := setupMonitor(t, s, nil, Config{CircuitOpenDuration: time.Hour})
+	ctx := context.Background()
+
+	for _, sub := range []string{"sub-1", "sub-2"} {
+		for i := 0; i < 5; i++ {
+			m.breaker.RecordFailure(ctx, sub)
+		}
+	}
+	client.HSet(ctx, "cb:sub-1", "opened_at", time.Now().Add(-2*time.Hour).Unix())
+
+	if err := m.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(n.alerts) != 1 || n.alerts[0].Key != "circuit_open:sub-1" || !strings.Contains(n.alerts[0].Details, "webhookctl breakers sub-1") {
+		t.Fatalf("alerts = %+v", n.alerts)
+	}
+
+	m.breaker.RecordSuccess(ctx, "sub-1")
+	if err := m.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(n.alerts) != 2 || !n.alerts[1].Resolved || n.alerts[1].Key != "circuit_open:sub-1" {
+		t.Errorf("alerts = %+v", n.alerts)
+	}
+}
+
+func TestMonitor_SubscriberDLQ(t *testing.T) {
+	s := &fakeStore{deadLetters: []store.SubscriberDeadLetterCount{
+		{SubscriberID: "sub-1", Name: "orders", EndpointURL: "https://example.com/hook", Count: 12},
+	}}
+	m, n, _ := setupMonitor(t, s, nil, Config{SubscriberDLQThreshold: 10})
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if err := m.RunOnce(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(n.alerts) != 1 || n.alerts[0].Key != "subscriber_dlq:sub-1" ||
+		n.alerts[0].Summary != `Subscriber "orders" had 12 new dead letters in the last 1h (threshold 10)` ||
+		!strings.Contains(n.alerts[0].Details, "webhookctl dlq list --subscriber sub-1") {
+		t.Fatalf("alerts = %+v", n.alerts)
+	}
+
+	s.deadLetters = nil
+	if err := m.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(n.alerts) != 2 || !n.alerts[1].Resolved || n.alerts[1].Key != "subscriber_dlq:sub-1" {
+		t.Errorf("alerts = %+v", n.alerts)
+	}
+}
+
+func TestPagerDutyNotifier_TriggersAndResolves(t *testing.T) {
+	var events []pagerDutyEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e pagerDutyEvent
+		if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+			t.Errorf("decoding event: %v", err)
+		}
+		events = append(events, e)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	n := NewPagerDutyNotifier("routing-key")
+	n.eventsURL = server.URL
+	ctx := context.Background()
+	alert := Alert{Key: "circuit_open:sub-1", Rule: RuleCircuitOpen, Summary: "open", Details: "Subscriber ID: sub-1"}
+
+	if err := n.Notify(ctx, alert); err != nil {
+		t.Fatal(err)
+	}
+	alert.Resolved = true
+	if err := n.Notify(ctx, alert); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("sent %d events, want 2", len(events))
+	}
+	trigger, resolve := events[0], events[1]
+	if trigger.EventAction != "trigger" || trigger.RoutingKey != "routing-key" || trigger.Payload == nil || trigger.Payload.Summary != "open" {
+		t.Errorf("trigger = %+v", trigger)
+	}
+	if resolve.EventAction != "resolve" || resolve.DedupKey != trigger.DedupKey || resolve.Payload != nil {
+		t.Errorf("resolve = %+v", resolve)
+	}
+}
+
+func TestSlackNotifier_ReportsErrorStatus(t *testing.T) {
+	var text string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		text = body["text"]
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	err := NewSlackNotifier(server.URL).Notify(context.Background(), Alert{Summary: "queue backed up", Resolved: true})
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("err = %v", err)
+	}
+	if text != ":white_check_mark: Resolved: queue backed up" {
+		t.Errorf("text = %q", text)
+	}
+}
+
+func TestEmailNotifier_SendsMessage(t *testing.T) {
+	n := NewEmailNotifier(SMTPConfig{
+		Host: "mail.example.com",
+		Port: 587,
+		From: "alerts@example.com",
+		To:   []string{"oncall@example.com", "ops@example.com"},
+	})
+
+	var gotAddr string
+	var gotTo []string
+	var msg string
+	n.sendMail = func(addr string, a smtp.Auth, from string, to []string, m []byte) error {
+		gotAddr, gotTo, msg = addr, to, string(m)
+		return nil
+	}
+
+	alert := Alert{Key: "subscriber_dlq:sub-1", Rule: RuleSubscriberDLQ, Summary: "orders is dead-lettering", Details: "Subscriber ID: sub-1"}
+	if err := n.Notify(context.Background(), alert); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if gotAddr != "mail.example.com:587" || len(gotTo) != 2 {
+		t.Errorf("sent to %s %v", gotAddr, gotTo)
+	}
+	for _, want := range []string{
+		"To: oncall@example.com, ops@example.com\r\n",
+		"Subject: [webhook-delivery] orders is dead-lettering\r\n",
+		"Subscriber ID: sub-1\r\n",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("message does not contain %q:\n%s", want, msg)
+		}
+	}
+
+	alert.Resolved = true
+	if err := n.Notify(context.Background(), alert); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(msg, "Subject: [webhook-delivery] Resolved: orders is dead-lettering\r\n") {
+		t.Errorf("resolved message:\n%s", msg)
+	}
+}
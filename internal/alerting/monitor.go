@@ -0,0 +1,366 @@
+// Package alerting watches operational thresholds — queue depth, the dead
+// letter rate overall and per subscriber, long-open circuit breakers and the
+// delivery success rate — and notifies operators on Slack, PagerDuty or by
+// email when one is crossed and again when it clears.
+package alerting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+	"github.com/redis/go-redis/v9"
+)
+
+// firingKey is a Redis hash of the alerts currently firing across the
+// cluster, from alert key to summary.
+const firingKey = "alerts:firing"
+
+// depthHighKey holds the Unix time the delivery queue depth went above the
+// threshold, shared so every replica times the same stretch.
+const depthHighKey = "alerts:queue_depth_high_since"
+
+// Rule names.
+const (
+	RuleQueueDepth    = "queue_depth"
+	RuleDLQRate       = "dlq_rate"
+	RuleSubscriberDLQ = "subscriber_dlq"
+	RuleCircuitOpen   = "circuit_open"
+	RuleSuccessRate   = "success_rate"
+)
+
+// Config sets the alert rules. A rule with a zero threshold is disabled.
+type Config struct {
+	// QueueDepthThreshold alerts when the delivery queue holds more jobs
+	// than this for QueueDepthDuration.
+	QueueDepthThreshold int
+	QueueDepthDuration  time.Duration
+
+	// DLQRateThreshold alerts when more deliveries than this are
+	// dead-lettered, across all subscribers, within DLQRateWindow.
+	DLQRateThreshold int
+	DLQRateWindow    time.Duration
+
+	// SubscriberDLQThreshold alerts for each subscriber with more dead
+	// letters than this within SubscriberDLQWindow.
+	SubscriberDLQThreshold int
+	SubscriberDLQWindow    time.Duration
+
+	// CircuitOpenDuration alerts for each subscriber whose circuit breaker
+	// has not closed for this long since it opened.
+	CircuitOpenDuration time.Duration
+
+	// SuccessRateThreshold alerts when the percentage of successful
+	// delivery attempts within SuccessRateWindow falls below it. Windows
+	// with fewer than SuccessRateMinAttempts attempts are not judged.
+	SuccessRateThreshold   float64
+	SuccessRateWindow      time.Duration
+	SuccessRateMinAttempts int
+
+	Interval time.Duration
+}
+
+// Store is the data the alert rules are evaluated against.
+type Store interface {
+	ListSubscribers(ctx context.Context) ([]domain.Subscriber, error)
+	CountRecentDeadLetters(ctx context.Context, since time.Time, threshold int) ([]store.SubscriberDeadLetterCount, error)
+	GetDeliveryTimeseries(ctx context.Context, since time.Time, interval time.Duration) ([]store.TimeseriesBucket, error)
+}
+
+// Monitor evaluates the alert rules on an interval.
+//
+// Every replica runs a monitor. The alerts firing are kept in Redis, and an
+// alert is only sent by the replica that adds it there (or, once resolved,
+// removes it), so each condition is reported once when it starts and once
+// when it ends across the cluster.
+type Monitor struct {
+	store       Store
+	queue       engine.Queue
+	breaker     *engine.CircuitBreaker
+	redisClient *redis.Client
+	notifiers   []Notifier
+	cfg         Config
+	logger      *slog.Logger
+	now         func() time.Time
+}
+
+func NewMonitor(s Store, queue engine.Queue, breaker *engine.CircuitBreaker, redisClient *redis.Client, notifiers []Notifier, cfg Config, logger *slog.Logger) *Monitor {
+	if cfg.QueueDepthDuration <= 0 {
+		cfg.QueueDepthDuration = 5 * time.Minute
+	}
+	if cfg.DLQRateWindow <= 0 {
+		cfg.DLQRateWindow = 15 * time.Minute
+	}
+	if cfg.SubscriberDLQWindow <= 0 {
+		cfg.SubscriberDLQWindow = time.Hour
+	}
+	if cfg.SuccessRateWindow <= 0 {
+		cfg.SuccessRateWindow = 15 * time.Minute
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Minute
+	}
+	return &Monitor{
+		store:       s,
+		queue:       queue,
+		breaker:     breaker,
+		redisClient: redisClient,
+		notifiers:   notifiers,
+		cfg:         cfg,
+		logger:      logger,
+		now:         time.Now,
+	}
+}
+
+// Start evaluates the rules immediately and then on every interval until
+// the context is cancelled.
+func (m *Monitor) Start(ctx context.Context) {
+	m.logger.Info("alert monitor started",
+		"interval", m.cfg.Interval.String(),
+		"notifiers", len(m.notifiers),
+	)
+
+	ticker := time.NewTicker(m.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := m.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			m.logger.Error("alert monitor pass failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			m.logger.Info("alert monitor stopping")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+type rule struct {
+	name     string
+	enabled  bool
+	evaluate func(ctx context.Context) ([]Alert, error)
+}
+
+// RunOnce evaluates every rule, sends the alerts that started firing and
+// resolves those that stopped. Alerts of a rule that could not be evaluated
+// are left as they are.
+func (m *Monitor) RunOnce(ctx context.Context) error {
+	rules := []rule{
+		{RuleQueueDepth, m.cfg.QueueDepthThreshold > 0, m.checkQueueDepth},
+		{RuleDLQRate, m.cfg.DLQRateThreshold > 0, m.checkDLQRate},
+		{RuleSubscriberDLQ, m.cfg.SubscriberDLQThreshold > 0, m.checkSubscriberDLQ},
+		{RuleCircuitOpen, m.cfg.CircuitOpenDuration > 0, m.checkCircuits},
+		{RuleSuccessRate, m.cfg.SuccessRateThreshold > 0, m.checkSuccessRate},
+	}
+
+	var errs []error
+	firing := make(map[string]Alert)
+	failed := make(map[string]bool)
+	for _, r := range rules {
+		if !r.enabled {
+			continue
+		}
+		alerts, err := r.evaluate(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("evaluating %s: %w", r.name, err))
+			failed[r.name] = true
+			continue
+		}
+		for _, a := range alerts {
+			firing[a.Key] = a
+		}
+	}
+
+	for _, a := range firing {
+		claimed, err := m.redisClient.HSetNX(ctx, firingKey, a.Key, a.Summary).Result()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("claiming alert %s: %w", a.Key, err))
+			continue
+		}
+		if claimed {
+			m.logger.Warn("alert firing", "alert", a.Key, "summary", a.Summary)
+			m.notify(ctx, a)
+		}
+	}
+
+	previous, err := m.redisClient.HGetAll(ctx, firingKey).Result()
+	if err != nil {
+		errs = append(errs, fmt.Errorf("reading firing alerts: %w", err))
+		return errors.Join(errs...)
+	}
+	for key, summary := range previous {
+		name, _, _ := strings.Cut(key, ":")
+		if _, ok := firing[key]; ok || failed[name] {
+			continue
+		}
+		removed, err := m.redisClient.HDel(ctx, firingKey, key).Result()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("resolving alert %s: %w", key, err))
+			continue
+		}
+		if removed > 0 {
+			m.logger.Info("alert resolved", "alert", key)
+			m.notify(ctx, Alert{Key: key, Rule: name, Summary: summary, Resolved: true})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// notify sends an alert to every channel. A failing channel does not stop
+// the others.
+func (m *Monitor) notify(ctx context.Context, a Alert) {
+	for _, n := range m.notifiers {
+		if err := n.Notify(ctx, a); err != nil {
+			m.logger.Error("failed to send alert", "error", err, "alert", a.Key)
+		}
+	}
+}
+
+func (m *Monitor) checkQueueDepth(ctx context.Context) ([]Alert, error) {
+	depth, err := m.queue.Depth(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if depth <= int64(m.cfg.QueueDepthThreshold) {
+		return nil, m.redisClient.Del(ctx, depthHighKey).Err()
+	}
+	now := m.now()
+	if err := m.redisClient.SetNX(ctx, depthHighKey, now.Unix(), 0).Err(); err != nil {
+		return nil, err
+	}
+	since, err := m.redisClient.Get(ctx, depthHighKey).Int64()
+	if err != nil {
+		return nil, err
+	}
+	if now.Sub(time.Unix(since, 0)) < m.cfg.QueueDepthDuration {
+		return nil, nil
+	}
+	return []Alert{{
+		Key:  RuleQueueDepth,
+		Rule: RuleQueueDepth,
+		Summary: fmt.Sprintf("Delivery queue depth has been above %d for %s (now %d)",
+			m.cfg.QueueDepthThreshold, formatDuration(m.cfg.QueueDepthDuration), depth),
+		Details: "Inspect the queue with: webhookctl queue inspect",
+	}}, nil
+}
+
+func (m *Monitor) checkDLQRate(ctx context.Context) ([]Alert, error) {
+	counts, err := m.store.CountRecentDeadLetters(ctx, m.now().Add(-m.cfg.DLQRateWindow), 0)
+	if err != nil {
+		return nil, err
+	}
+	total := 0
+	var top []string
+	for _, c := range counts {
+		total += c.Count
+		if len(top) < 5 {
+			top = append(top, fmt.Sprintf("%s (%d)", c.Name, c.Count))
+		}
+	}
+	if total <= m.cfg.DLQRateThreshold {
+		return nil, nil
+	}
+	return []Alert{{
+		Key:  RuleDLQRate,
+		Rule: RuleDLQRate,
+		Summary: fmt.Sprintf("%d deliveries were dead-lettered in the last %s (threshold %d)",
+			total, formatDuration(m.cfg.DLQRateWindow), m.cfg.DLQRateThreshold),
+		Details: "Most affected subscribers: " + strings.Join(top, ", "),
+	}}, nil
+}
+
+func (m *Monitor) checkSubscriberDLQ(ctx context.Context) ([]Alert, error) {
+	window := m.cfg.SubscriberDLQWindow
+	counts, err := m.store.CountRecentDeadLetters(ctx, m.now().Add(-window), m.cfg.SubscriberDLQThreshold)
+	if err != nil {
+		return nil, err
+	}
+	alerts := make([]Alert, 0, len(counts))
+	for _, c := range counts {
+		alerts = append(alerts, Alert{
+			Key:  RuleSubscriberDLQ + ":" + c.SubscriberID,
+			Rule: RuleSubscriberDLQ,
+			Summary: fmt.Sprintf("Subscriber %q had %d new dead letters in the last %s (threshold %d)",
+				c.Name, c.Count, formatDuration(window), m.cfg.SubscriberDLQThreshold),
+			Details: fmt.Sprintf("Subscriber ID: %s\nEndpoint: %s\nInspect with: webhookctl dlq list --subscriber %s",
+				c.SubscriberID, c.EndpointURL, c.SubscriberID),
+		})
+	}
+	return alerts, nil
+}
+
+func (m *Monitor) checkCircuits(ctx context.Context) ([]Alert, error) {
+	subs, err := m.store.ListSubscribers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := m.now()
+	var alerts []Alert
+	for _, sub := range subs {
+		state := m.breaker.GetState(ctx, sub.ID)
+		if state.State == engine.StateClosed || state.OpenedAt == "" {
+			continue
+		}
+		openedAt, err := time.Parse(time.RFC3339, state.OpenedAt)
+		if err != nil || now.Sub(openedAt) < m.cfg.CircuitOpenDuration {
+			continue
+		}
+		alerts = append(alerts, Alert{
+			Key:  RuleCircuitOpen + ":" + sub.ID,
+			Rule: RuleCircuitOpen,
+			Summary: fmt.Sprintf("Circuit breaker for subscriber %q has been open for over %s (since %s)",
+				sub.Name, formatDuration(m.cfg.CircuitOpenDuration), state.OpenedAt),
+			Details: fmt.Sprintf("Subscriber ID: %s\nEndpoint: %s\nInspect with: webhookctl breakers %s",
+				sub.ID, sub.EndpointURL, sub.ID),
+		})
+	}
+	return alerts, nil
+}
+
+func (m *Monitor) checkSuccessRate(ctx context.Context) ([]Alert, error) {
+	window := m.cfg.SuccessRateWindow
+	buckets, err := m.store.GetDeliveryTimeseries(ctx, m.now().Add(-window), window)
+	if err != nil {
+		return nil, err
+	}
+	total, succeeded := 0, 0
+	for _, b := range buckets {
+		total += b.Total
+		succeeded += b.SuccessCount
+	}
+	if total == 0 || total < m.cfg.SuccessRateMinAttempts {
+		return nil, nil
+	}
+	rate := float64(succeeded) / float64(total) * 100
+	if rate >= m.cfg.SuccessRateThreshold {
+		return nil, nil
+	}
+	return []Alert{{
+		Key:  RuleSuccessRate,
+		Rule: RuleSuccessRate,
+		Summary: fmt.Sprintf("Delivery success rate was %.1f%% over the last %s (threshold %.1f%%, %d attempts)",
+			rate, formatDuration(window), m.cfg.SuccessRateThreshold, total),
+		Details: "Failures by reason: GET /api/v1/metrics/timeseries",
+	}}, nil
+}
+
+// formatDuration drops the zero minutes and seconds of d, so 1h0m0s reads
+// 1h and 5m0s reads 5m.
+func formatDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
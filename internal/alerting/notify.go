@@ -0,0 +1,193 @@
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Alert is a threshold crossing, or the end of one when Resolved is set.
+type Alert struct {
+	// Key identifies the condition: the rule name, followed for
+	// per-subscriber rules by ":" and the subscriber ID.
+	Key      string
+	Rule     string
+	Summary  string
+	Details  string
+	Resolved bool
+}
+
+// Notifier delivers alerts to operators.
+type Notifier interface {
+	Notify(ctx context.Context, alert Alert) error
+}
+
+// SlackNotifier posts alerts to a Slack incoming webhook.
+type SlackNotifier struct {
+	webhookURL string
+	client     *http.Client
+}
+
+func NewSlackNotifier(webhookURL string) *SlackNotifier {
+	return &SlackNotifier{
+		webhookURL: webhookURL,
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (n *SlackNotifier) Notify(ctx context.Context, alert Alert) error {
+	text := ":rotating_light: " + alert.Summary
+	if alert.Resolved {
+		text = ":white_check_mark: Resolved: " + alert.Summary
+	} else if alert.Details != "" {
+		text += "\n" + alert.Details
+	}
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return err
+	}
+
+	return post(ctx, n.client, n.webhookURL, body, "slack")
+}
+
+// pagerDutyEventsURL is the PagerDuty Events API v2 endpoint.
+const pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// PagerDutyNotifier triggers PagerDuty incidents through the Events API v2
+// and resolves them when the condition clears. Each alert key maps to one
+// incident through the event's dedup key.
+type PagerDutyNotifier struct {
+	routingKey string
+	eventsURL  string
+	client     *http.Client
+}
+
+func NewPagerDutyNotifier(routingKey string) *PagerDutyNotifier {
+	return &PagerDutyNotifier{
+		routingKey: routingKey,
+		eventsURL:  pagerDutyEventsURL,
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	Component     string            `json:"component"`
+	CustomDetails map[string]string `json:"custom_details,omitempty"`
+}
+
+func (n *PagerDutyNotifier) Notify(ctx context.Context, alert Alert) error {
+	event := pagerDutyEvent{
+		RoutingKey:  n.routingKey,
+		EventAction: "trigger",
+		DedupKey:    "webhook-delivery:" + alert.Key,
+	}
+	if alert.Resolved {
+		event.EventAction = "resolve"
+	} else {
+		event.Payload = &pagerDutyPayload{
+			Summary:   alert.Summary,
+			Source:    "webhook-delivery-system",
+			Severity:  "error",
+			Component: alert.Rule,
+		}
+		if alert.Details != "" {
+			event.Payload.CustomDetails = map[string]string{"details": alert.Details}
+		}
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return post(ctx, n.client, n.eventsURL, body, "pagerduty")
+}
+
+// SMTPConfig configures the email notifier. Username and Password are
+// optional; when set, PLAIN auth is used (which net/smtp only allows over
+// TLS or to localhost).
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+// EmailNotifier sends alerts by email through an SMTP relay.
+type EmailNotifier struct {
+	cfg      SMTPConfig
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+func NewEmailNotifier(cfg SMTPConfig) *EmailNotifier {
+	return &EmailNotifier{cfg: cfg, sendMail: smtp.SendMail}
+}
+
+func (n *EmailNotifier) Notify(ctx context.Context, alert Alert) error {
+	var auth smtp.Auth
+	if n.cfg.Username != "" {
+		auth = smtp.PlainAuth("", n.cfg.Username, n.cfg.Password, n.cfg.Host)
+	}
+
+	addr := net.JoinHostPort(n.cfg.Host, strconv.Itoa(n.cfg.Port))
+	if err := n.sendMail(addr, auth, n.cfg.From, n.cfg.To, n.message(alert)); err != nil {
+		return fmt.Errorf("sending alert email: %w", err)
+	}
+	return nil
+}
+
+func (n *EmailNotifier) message(alert Alert) []byte {
+	subject := alert.Summary
+	if alert.Resolved {
+		subject = "Resolved: " + subject
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", n.cfg.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(n.cfg.To, ", "))
+	fmt.Fprintf(&b, "Subject: [webhook-delivery] %s\r\n", subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(subject + ".\r\n")
+	if !alert.Resolved && alert.Details != "" {
+		b.WriteString("\r\n" + strings.ReplaceAll(alert.Details, "\n", "\r\n") + "\r\n")
+	}
+	return []byte(b.String())
+}
+
+func post(ctx context.Context, client *http.Client, url string, body []byte, service string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("building %s request: %w", service, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("posting to %s: %w", service, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned status %d", service, resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/chaos"
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+)
+
+// ConfigReloader re-reads the server configuration and applies the settings
+// that can change at runtime.
+type ConfigReloader interface {
+	Reload() (*domain.ConfigReload, error)
+}
+
+// AdminHandler serves server administration endpoints.
+type AdminHandler struct {
+	store    store.AuditStore
+	reloader ConfigReloader
+	chaos    *chaos.Injector
+}
+
+func NewAdminHandler(s store.AuditStore, reloader ConfigReloader, chaos *chaos.Injector) *AdminHandler {
+	return &AdminHandler{store: s, reloader: reloader, chaos: chaos}
+}
+
+// Reload re-reads the configuration of the instance that serves the request
+// and applies worker pool, dispatcher, rate limiter and circuit breaker
+// settings. An invalid configuration is rejected and the running one kept.
+func (h *AdminHandler) Reload(w http.ResponseWriter, r *http.Request) {
+	if h.reloader == nil {
+		respondError(w, http.StatusServiceUnavailable, "configuration reload is not available")
+		return
+	}
+
+	result, err := h.reloader.Reload()
+	if err != nil {
+		respondError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	recordAudit(r, h.store, domain.AuditConfigReload, domain.AuditEntityConfig, result.InstanceID, result)
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// Chaos returns the faults injected into the instance that serves the
+// request.
+func (h *AdminHandler) Chaos(w http.ResponseWriter, r *http.Request) {
+	if h.chaos == nil {
+		respondError(w, http.StatusServiceUnavailable, "chaos mode is not enabled")
+		return
+	}
+	respondJSON(w, http.StatusOK, h.chaos.Settings())
+}
+
+// SetChaos replaces the faults injected into the instance that serves the
+// request. Faults left out of the body are turned off.
+func (h *AdminHandler) SetChaos(w http.ResponseWriter, r *http.Request) {
+	if h.chaos == nil {
+		respondError(w, http.StatusServiceUnavailable, "chaos mode is not enabled")
+		return
+	}
+
+	var req domain.ChaosSettings
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := h.chaos.Set(req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	recordAudit(r, h.store, domain.AuditChaosUpdate, domain.AuditEntityConfig, "chaos", req)
+
+	respondJSON(w, http.StatusOK, req)
+}
+
+// ClearChaos stops injecting faults into the instance that serves the
+// request.
+func (h *AdminHandler) ClearChaos(w http.ResponseWriter, r *http.Request) {
+	if h.chaos == nil {
+		respondError(w, http.StatusServiceUnavailable, "chaos mode is not enabled")
+		return
+	}
+
+	h.chaos.Set(domain.ChaosSettings{})
+	recordAudit(r, h.store, domain.AuditChaosUpdate, domain.AuditEntityConfig, "chaos", domain.ChaosSettings{})
+
+	w.WriteHeader(http.StatusNoContent)
+}
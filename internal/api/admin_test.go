@@ -0,0 +1,100 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/chaos"
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+)
+
+type fakeReloader struct {
+	result *domain.ConfigReload
+	err    error
+}
+
+func (f fakeReloader) Reload() (*domain.ConfigReload, error) {
+	return f.result, f.err
+}
+
+func TestAdminHandler_Reload(t *testing.T) {
+	s := store.NewMemoryStore()
+	result := &domain.ConfigReload{
+		InstanceID:      "instance-1",
+		Applied:         []domain.ConfigChange{{Setting: "DISPATCHER_BATCH_SIZE", From: "100", To: "250"}},
+		RestartRequired: []string{},
+	}
+
+	rec := httptest.NewRecorder()
+	NewAdminHandler(s, fakeReloader{result: result}, nil).Reload(rec, httptest.NewRequest(http.MethodPost, "/admin/reload", nil))
+	var got domain.ConfigReload
+	json.NewDecoder(rec.Body).Decode(&got)
+	if rec.Code != http.StatusOK || len(got.Applied) != 1 || got.Applied[0].To != "250" {
+		t.Errorf("reload = %d %+v", rec.Code, got)
+	}
+
+	entries, _ := s.ListAuditEntries(context.Background(), domain.AuditFilter{Action: domain.AuditConfigReload})
+	if len(entries) != 1 || entries[0].EntityID != "instance-1" {
+		t.Errorf("audit entries = %+v, want one for instance-1", entries)
+	}
+
+	rec = httptest.NewRecorder()
+	NewAdminHandler(s, fakeReloader{err: errors.New("invalid configuration: RETRY_JITTER")}, nil).Reload(rec, httptest.NewRequest(http.MethodPost, "/admin/reload", nil))
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("invalid config: status = %d, want 422", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	NewAdminHandler(s, nil, nil).Reload(rec, httptest.NewRequest(http.MethodPost, "/admin/reload", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("no reloader: status = %d, want 503", rec.Code)
+	}
+}
+
+func TestAdminHandler_Chaos(t *testing.T) {
+	s := store.NewMemoryStore()
+	h := NewAdminHandler(s, nil, chaos.New())
+
+	rec := httptest.NewRecorder()
+	h.SetChaos(rec, httptest.NewRequest(http.MethodPut, "/admin/chaos", strings.NewReader(`{"redis_error_rate": 0.2, "delivery_timeout_rate": 0.5}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("set: status = %d, body %s", rec.Code, rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	h.Chaos(rec, httptest.NewRequest(http.MethodGet, "/admin/chaos", nil))
+	var got domain.ChaosSettings
+	json.NewDecoder(rec.Body).Decode(&got)
+	if got != (domain.ChaosSettings{RedisErrorRate: 0.2, DeliveryTimeoutRate: 0.5}) {
+		t.Errorf("settings = %+v", got)
+	}
+
+	rec = httptest.NewRecorder()
+	h.SetChaos(rec, httptest.NewRequest(http.MethodPut, "/admin/chaos", strings.NewReader(`{"delivery_timeout_rate": 2}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("rate of 2: status = %d, want 400", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ClearChaos(rec, httptest.NewRequest(http.MethodDelete, "/admin/chaos", nil))
+	if rec.Code != http.StatusNoContent || h.chaos.Settings() != (domain.ChaosSettings{}) {
+		t.Errorf("clear: status = %d, settings %+v", rec.Code, h.chaos.Settings())
+	}
+
+	entries, _ := s.ListAuditEntries(context.Background(), domain.AuditFilter{Action: domain.AuditChaosUpdate})
+	if len(entries) != 2 {
+		t.Errorf("audit entries = %d, want the set and the clear", len(entries))
+	}
+
+	rec = httptest.NewRecorder()
+	NewAdminHandler(s, nil, nil).Chaos(rec, httptest.NewRequest(http.MethodGet, "/admin/chaos", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("chaos mode off: status = %d, want 503", rec.Code)
+	}
+}
@@ -0,0 +1,150 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+type APIKeyHandler struct {
+	store store.Store
+}
+
+func NewAPIKeyHandler(s store.Store) *APIKeyHandler {
+	return &APIKeyHandler{store: s}
+}
+
+func (h *APIKeyHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req domain.CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if req.Name == "" {
+		respondError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+	if req.Role == "" {
+		req.Role = domain.RoleViewer
+	}
+	if !req.Role.Valid() {
+		respondError(w, http.StatusBadRequest, "role must be viewer, operator, or admin")
+		return
+	}
+
+	// Scoped keys only reach the read-mostly portal routes, so they are
+	// always viewers
+	var subscriberID *string
+	if req.SubscriberID != "" {
+		if req.Role != domain.RoleViewer {
+			respondError(w, http.StatusBadRequest, "keys scoped to a subscriber must have the viewer role")
+			return
+		}
+		sub, err := h.store.GetSubscriber(r.Context(), req.SubscriberID)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "failed to get subscriber")
+			return
+		}
+		if sub == nil {
+			respondError(w, http.StatusBadRequest, "subscriber not found")
+			return
+		}
+		subscriberID = &sub.ID
+	}
+
+	key, plaintext, err := h.store.CreateAPIKey(r.Context(), req.Name, req.Role, subscriberID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to create api key")
+		return
+	}
+
+	details := map[string]string{
+		"name":       key.Name,
+		"key_prefix": key.KeyPrefix,
+		"role":       string(key.Role),
+	}
+	if key.SubscriberID != nil {
+		details["subscriber_id"] = *key.SubscriberID
+	}
+	recordAudit(r, h.store, domain.AuditAPIKeyCreate, domain.AuditEntityAPIKey, key.ID, details)
+
+	respondJSON(w, http.StatusCreated, domain.CreateAPIKeyResponse{APIKey: *key, Key: plaintext})
+}
+
+func (h *APIKeyHandler) List(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.store.ListAPIKeys(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list api keys")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, keys)
+}
+
+func (h *APIKeyHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	revoked, err := h.store.RevokeAPIKey(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to revoke api key")
+		return
+	}
+	if !revoked {
+		respondError(w, http.StatusNotFound, "api key not found")
+		return
+	}
+
+	recordAudit(r, h.store, domain.AuditAPIKeyRevoke, domain.AuditEntityAPIKey, id, nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SetRole changes the role of an API key.
+func (h *APIKeyHandler) SetRole(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	var req domain.UpdateAPIKeyRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if !req.Role.Valid() {
+		respondError(w, http.StatusBadRequest, "role must be viewer, operator, or admin")
+		return
+	}
+
+	before, err := h.store.GetAPIKey(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get api key")
+		return
+	}
+	if before == nil || before.RevokedAt != nil {
+		respondError(w, http.StatusNotFound, "api key not found")
+		return
+	}
+	if before.SubscriberID != nil {
+		respondError(w, http.StatusBadRequest, "the role of a key scoped to a subscriber cannot be changed")
+		return
+	}
+
+	key, err := h.store.SetAPIKeyRole(r.Context(), id, req.Role)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to update api key role")
+		return
+	}
+	if key == nil {
+		respondError(w, http.StatusNotFound, "api key not found")
+		return
+	}
+
+	recordAudit(r, h.store, domain.AuditAPIKeyRoleChange, domain.AuditEntityAPIKey, id, map[string]domain.Role{
+		"before": before.Role,
+		"after":  key.Role,
+	})
+
+	respondJSON(w, http.StatusOK, key)
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/archive"
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+type ArchiveHandler struct {
+	store store.ArchiveStore
+	s3    *archive.S3Client
+}
+
+func NewArchiveHandler(s store.ArchiveStore, s3 *archive.S3Client) *ArchiveHandler {
+	return &ArchiveHandler{store: s, s3: s3}
+}
+
+func (h *ArchiveHandler) List(w http.ResponseWriter, r *http.Request) {
+	tableName := r.URL.Query().Get("table")
+	limitStr := r.URL.Query().Get("limit")
+
+	limit := 50
+	if limitStr != "" {
+		if n, err := strconv.Atoi(limitStr); err == nil && n > 0 {
+			limit = n
+		}
+	}
+
+	manifests, err := h.store.ListArchiveManifests(r.Context(), tableName, limit)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list archives")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, manifests)
+}
+
+func (h *ArchiveHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	manifest, err := h.store.GetArchiveManifest(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get archive")
+		return
+	}
+	if manifest == nil {
+		respondError(w, http.StatusNotFound, "archive not found")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, manifest)
+}
+
+// Download streams the archived gzip NDJSON object back from object storage.
+func (h *ArchiveHandler) Download(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	if h.s3 == nil {
+		respondError(w, http.StatusServiceUnavailable, "archive storage is not configured")
+		return
+	}
+
+	manifest, err := h.store.GetArchiveManifest(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get archive")
+		return
+	}
+	if manifest == nil {
+		respondError(w, http.StatusNotFound, "archive not found")
+		return
+	}
+
+	body, err := h.s3.GetObject(r.Context(), manifest.ObjectKey)
+	if err != nil {
+		respondError(w, http.StatusBadGateway, "failed to fetch archive from storage")
+		return
+	}
+	defer body.Close()
+
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+path.Base(manifest.ObjectKey)+`"`)
+	w.Header().Set("X-Archive-SHA256", manifest.SHA256)
+	w.WriteHeader(http.StatusOK)
+	io.Copy(w, body)
+}
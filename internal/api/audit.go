@@ -0,0 +1,111 @@
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// anonymousActor is recorded for mutations made without an API key, i.e.
+// when authentication is disabled.
+const anonymousActor = "anonymous"
+
+// maxAuditLimit caps how many entries one audit log request returns.
+const maxAuditLimit = 1000
+
+// recordAudit writes an audit entry for a mutation made by r. The change has
+// already been applied, so a failed write is logged rather than failing the
+// request.
+func recordAudit(r *http.Request, s store.AuditStore, action, entityType, entityID string, details interface{}) {
+	entry := newAuditEntry(r, action, entityType, entityID, details)
+	if err := s.InsertAuditEntry(r.Context(), entry); err != nil {
+		slog.Error("failed to write audit entry",
+			"error", err,
+			"action", action,
+			"entity_id", entityID,
+			"actor", entry.Actor,
+		)
+	}
+}
+
+// newAuditEntry describes a mutation made by r, attributing it to the API
+// key that authenticated the request.
+func newAuditEntry(r *http.Request, action, entityType, entityID string, details interface{}) *domain.AuditEntry {
+	entry := &domain.AuditEntry{
+		Actor:      anonymousActor,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		RemoteAddr: r.RemoteAddr,
+		RequestID:  middleware.GetReqID(r.Context()),
+	}
+
+	if key := APIKeyFromContext(r.Context()); key != nil {
+		entry.Actor = key.Name
+		if key.ID != "" {
+			id := key.ID
+			entry.ActorKeyID = &id
+		}
+	}
+
+	if details != nil {
+		if data, err := json.Marshal(details); err == nil {
+			entry.Details = data
+		}
+	}
+	return entry
+}
+
+type AuditHandler struct {
+	store store.AuditStore
+}
+
+func NewAuditHandler(s store.AuditStore) *AuditHandler {
+	return &AuditHandler{store: s}
+}
+
+// List returns audit entries, newest first. It filters on actor, action,
+// entity_type, entity_id, and an RFC 3339 since/until time range.
+func (h *AuditHandler) List(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAuditFilter(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	entries, err := h.store.ListAuditEntries(r.Context(), filter)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list audit log")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, entries)
+}
+
+func parseAuditFilter(r *http.Request) (domain.AuditFilter, error) {
+	q := r.URL.Query()
+	filter := domain.AuditFilter{
+		Actor:      q.Get("actor"),
+		Action:     q.Get("action"),
+		EntityType: q.Get("entity_type"),
+		EntityID:   q.Get("entity_id"),
+		Limit:      100,
+	}
+
+	var err error
+	if filter.Since, filter.Until, err = parseTimeRange(q); err != nil {
+		return filter, err
+	}
+
+	if v := q.Get("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			filter.Limit = min(n, maxAuditLimit)
+		}
+	}
+	return filter, nil
+}
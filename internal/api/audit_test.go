@@ -0,0 +1,90 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+)
+
+func TestNewAuditEntry_AttributesAPIKey(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/subscribers/sub-1", nil)
+	req.RemoteAddr = "10.0.0.7:5000"
+	ctx := context.WithValue(req.Context(), apiKeyContextKey{}, &domain.APIKey{ID: "key-1", Name: "ops"})
+	req = req.WithContext(ctx)
+
+	name := "renamed"
+	entry := newAuditEntry(req, domain.AuditSubscriberUpdate, domain.AuditEntitySubscriber, "sub-1",
+		domain.UpdateSubscriberRequest{Name: &name})
+
+	if entry.Actor != "ops" || entry.ActorKeyID == nil || *entry.ActorKeyID != "key-1" {
+		t.Errorf("actor = %q (key %v), want ops/key-1", entry.Actor, entry.ActorKeyID)
+	}
+	if entry.RemoteAddr != "10.0.0.7:5000" {
+		t.Errorf("remote addr = %q", entry.RemoteAddr)
+	}
+	if string(entry.Details) != `{"name":"renamed"}` {
+		t.Errorf("details = %s", entry.Details)
+	}
+}
+
+func TestNewAuditEntry_AnonymousAndAdminKey(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/subscribers", nil)
+	if entry := newAuditEntry(req, "a", "b", "c", nil); entry.Actor != anonymousActor || entry.Details != nil {
+		t.Errorf("unauthenticated entry = %+v, want anonymous with no details", entry)
+	}
+
+	// The bootstrap admin key has no stored ID
+	ctx := context.WithValue(req.Context(), apiKeyContextKey{}, &domain.APIKey{Name: "admin"})
+	entry := newAuditEntry(req.WithContext(ctx), "a", "b", "c", nil)
+	if entry.Actor != "admin" || entry.ActorKeyID != nil {
+		t.Errorf("admin entry = %+v, want actor admin without key ID", entry)
+	}
+}
+
+func TestParseAuditFilter(t *testing.T) {
+	tests := []struct {
+		query     string
+		wantErr   bool
+		wantLimit int
+	}{
+		{"", false, 100},
+		{"actor=ops&entity_type=subscriber&entity_id=sub-1", false, 100},
+		{"since=2024-05-01T00:00:00Z&until=2024-05-02T00:00:00Z", false, 100},
+		{"limit=5000", false, maxAuditLimit},
+		{"limit=abc", false, 100},
+		{"since=yesterday", true, 0},
+		{"until=2024-05-01", true, 0},
+		{"since=2024-05-02T00:00:00Z&until=2024-05-01T00:00:00Z", true, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			f, err := parseAuditFilter(httptest.NewRequest(http.MethodGet, "/api/v1/audit-log?"+tt.query, nil))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && f.Limit != tt.wantLimit {
+				t.Errorf("limit = %d, want %d", f.Limit, tt.wantLimit)
+			}
+		})
+	}
+}
+
+func TestSubscriberChange_RecordsOnlyChangedFields(t *testing.T) {
+	sub := &domain.Subscriber{Name: "orders", EndpointURL: "http://old", IsActive: true, RateLimitPerSecond: 10}
+	url := "http://new"
+	update := domain.UpdateSubscriberRequest{EndpointURL: &url}
+
+	data, err := json.Marshal(domain.SubscriberChange{Before: update.Previous(sub), After: update})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	want := `{"before":{"endpoint_url":"http://old"},"after":{"endpoint_url":"http://new"}}`
+	if string(data) != want {
+		t.Errorf("change = %s, want %s", data, want)
+	}
+}
@@ -0,0 +1,195 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+)
+
+type apiKeyContextKey struct{}
+
+// Authenticator checks API keys on protected routes. A key may be sent as
+// "Authorization: Bearer <key>", in an X-API-Key header, or as a token query
+// parameter. The query parameter exists because browsers cannot set headers
+// on WebSocket and EventSource connections; other clients should prefer a
+// header so keys stay out of access logs.
+type Authenticator struct {
+	enabled  bool
+	adminKey string
+	lookup   func(ctx context.Context, hash string) (*domain.APIKey, error)
+	touch    func(ctx context.Context, id string) error
+}
+
+// NewAuthenticator creates an authenticator backed by the api_keys table.
+// When enabled is false every request is let through. adminKey, if set, is
+// accepted in addition to stored keys so the first keys can be created.
+func NewAuthenticator(s store.APIKeyStore, enabled bool, adminKey string) *Authenticator {
+	return &Authenticator{
+		enabled:  enabled,
+		adminKey: adminKey,
+		lookup:   s.GetAPIKeyByHash,
+		touch:    s.TouchAPIKey,
+	}
+}
+
+// Enabled reports whether API keys are checked.
+func (a *Authenticator) Enabled() bool {
+	return a.enabled
+}
+
+// Require rejects requests that don't carry a valid API key. Any role is
+// accepted. The key is available to handlers through APIKeyFromContext.
+func (a *Authenticator) Require(next http.Handler) http.Handler {
+	return a.RequireRole(domain.RoleViewer)(next)
+}
+
+// RequireRole returns middleware that rejects requests without a valid API
+// key (401) or whose key's role does not include role (403). Keys scoped to
+// a subscriber are rejected too; they may only use the portal routes.
+func (a *Authenticator) RequireRole(role domain.Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !a.enabled {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			r, key := a.requireKey(w, r)
+			if key == nil {
+				return
+			}
+			if key.SubscriberID != nil {
+				respondError(w, http.StatusForbidden, "api key is scoped to a subscriber and can only use /api/v1/portal")
+				return
+			}
+			if !key.Role.Includes(role) {
+				respondError(w, http.StatusForbidden, fmt.Sprintf("api key role %q cannot perform this action; %q is required", key.Role, role))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireSubscriberScope rejects requests that don't carry a valid API key
+// scoped to a subscriber. The portal routes it guards are answered from the
+// key's subscriber, so a key is needed even when authentication is disabled.
+func (a *Authenticator) RequireSubscriberScope(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r, key := a.requireKey(w, r)
+		if key == nil {
+			return
+		}
+		if key.SubscriberID == nil {
+			respondError(w, http.StatusForbidden, "the portal requires an api key scoped to a subscriber")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireKey returns the request's API key, along with the request carrying
+// it in its context. Without a valid key it responds with an error and
+// returns a nil key.
+func (a *Authenticator) requireKey(w http.ResponseWriter, r *http.Request) (*http.Request, *domain.APIKey) {
+	// Identify further up the chain may already have found the key
+	if key := APIKeyFromContext(r.Context()); key != nil {
+		return r, key
+	}
+
+	token := tokenFromRequest(r)
+	if token == "" {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		respondError(w, http.StatusUnauthorized, "api key required")
+		return r, nil
+	}
+
+	key, err := a.authenticate(r.Context(), token)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to verify api key")
+		return r, nil
+	}
+	if key == nil {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		respondError(w, http.StatusUnauthorized, "invalid api key")
+		return r, nil
+	}
+	return r.WithContext(context.WithValue(r.Context(), apiKeyContextKey{}, key)), key
+}
+
+// Identify attaches the request's API key to its context when a valid one
+// is sent, without rejecting requests that have none. It lets mutations on
+// routes that don't require a key still be attributed in the audit log.
+func (a *Authenticator) Identify(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := tokenFromRequest(r)
+		if token == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Unknown keys and lookup errors are left for Require to report on
+		// the routes that need a key
+		key, err := a.authenticate(r.Context(), token)
+		if err != nil || key == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), apiKeyContextKey{}, key)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// authenticate returns the key matching token, or nil if it is unknown or
+// revoked.
+func (a *Authenticator) authenticate(ctx context.Context, token string) (*domain.APIKey, error) {
+	if a.adminKey != "" && subtle.ConstantTimeCompare([]byte(token), []byte(a.adminKey)) == 1 {
+		return &domain.APIKey{Name: "admin", Role: domain.RoleAdmin}, nil
+	}
+	if a.lookup == nil {
+		return nil, nil
+	}
+
+	key, err := a.lookup(ctx, store.HashAPIKey(token))
+	if err != nil || key == nil {
+		return nil, err
+	}
+	// Usage tracking is best effort and shouldn't fail the request
+	_ = a.touch(ctx, key.ID)
+	return key, nil
+}
+
+// canSeeSecrets reports whether the caller may read subscriber signing
+// secrets. Requests without a key only reach handlers when authentication is
+// disabled, in which case every caller is trusted.
+func canSeeSecrets(r *http.Request) bool {
+	key := APIKeyFromContext(r.Context())
+	return key == nil || key.Role.Includes(domain.RoleAdmin)
+}
+
+// APIKeyFromContext returns the key that authenticated the request, or nil
+// if authentication is disabled.
+func APIKeyFromContext(ctx context.Context) *domain.APIKey {
+	key, _ := ctx.Value(apiKeyContextKey{}).(*domain.APIKey)
+	return key
+}
+
+func tokenFromRequest(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		if scheme, token, ok := strings.Cut(auth, " "); ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+	}
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	return r.URL.Query().Get("token")
+}
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+)
+
+func newTestAuthenticator(keys map[string]*domain.APIKey) (*Authenticator, *[]string) {
+	var touched []string
+	return &Authenticator{
+		enabled:  true,
+		adminKey: "admin-secret",
+		lookup: func(ctx context.Context, hash string) (*domain.APIKey, error) {
+			if hash == store.HashAPIKey("broken") {
+				return nil, errors.New("database unavailable")
+			}
+			return keys[hash], nil
+		},
+		touch: func(ctx context.Context, id string) error {
+			touched = append(touched, id)
+			return nil
+		},
+	}, &touched
+}
+
+func TestAuthenticator_Require(t *testing.T) {
+	key := &domain.APIKey{ID: "key-1", Name: "dashboard", Role: domain.RoleViewer}
+	auth, touched := newTestAuthenticator(map[string]*domain.APIKey{
+		store.HashAPIKey("whk_valid"): key,
+	})
+
+	var gotKey *domain.APIKey
+	handler := auth.Require(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotKey = APIKeyFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name     string
+		target   string
+		header   map[string]string
+		want     int
+		wantName string
+	}{
+		{"missing key", "/ws", nil, http.StatusUnauthorized, ""},
+		{"unknown key", "/ws", map[string]string{"Authorization": "Bearer whk_unknown"}, http.StatusUnauthorized, ""},
+		{"bearer header", "/ws", map[string]string{"Authorization": "Bearer whk_valid"}, http.StatusOK, "dashboard"},
+		{"x-api-key header", "/ws", map[string]string{"X-API-Key": "whk_valid"}, http.StatusOK, "dashboard"},
+		{"token query param", "/ws?token=whk_valid", nil, http.StatusOK, "dashboard"},
+		{"admin key", "/ws", map[string]string{"Authorization": "Bearer admin-secret"}, http.StatusOK, "admin"},
+		{"non-bearer scheme", "/ws", map[string]string{"Authorization": "Basic whk_valid"}, http.StatusUnauthorized, ""},
+		{"lookup error", "/ws?token=broken", nil, http.StatusInternalServerError, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotKey = nil
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if tt.want != http.StatusOK {
+				return
+			}
+			if gotKey == nil || gotKey.Name != tt.wantName {
+				t.Errorf("key in context = %+v, want name %q", gotKey, tt.wantName)
+			}
+		})
+	}
+
+	if len(*touched) != 3 {
+		t.Errorf("expected stored key to be touched 3 times, got %v", *touched)
+	}
+}
+
+func TestAuthenticator_Disabled(t *testing.T) {
+	auth, _ := newTestAuthenticator(nil)
+	auth.enabled = false
+
+	handler := auth.Require(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200 when auth is disabled", rec.Code)
+	}
+}
+
+func TestAuthenticator_Identify(t *testing.T) {
+	key := &domain.APIKey{ID: "key-1", Name: "ops"}
+	auth, _ := newTestAuthenticator(map[string]*domain.APIKey{
+		store.HashAPIKey("whk_valid"): key,
+	})
+	auth.enabled = false
+
+	var gotKey *domain.APIKey
+	handler := auth.Identify(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotKey = APIKeyFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for _, tt := range []struct {
+		name   string
+		token  string
+		wantID string
+	}{
+		{"no key", "", ""},
+		{"valid key", "whk_valid", "key-1"},
+		{"unknown key", "whk_unknown", ""},
+		{"lookup error", "broken", ""},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			gotKey = nil
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/subscribers", nil)
+			if tt.token != "" {
+				req.Header.Set("X-API-Key", tt.token)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200; Identify never rejects", rec.Code)
+			}
+			gotID := ""
+			if gotKey != nil {
+				gotID = gotKey.ID
+			}
+			if gotID != tt.wantID {
+				t.Errorf("key in context = %q, want %q", gotID, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestAuthenticator_RequireRole(t *testing.T) {
+	auth, _ := newTestAuthenticator(map[string]*domain.APIKey{
+		store.HashAPIKey("whk_viewer"):   {ID: "k1", Name: "viewer", Role: domain.RoleViewer},
+		store.HashAPIKey("whk_operator"): {ID: "k2", Name: "operator", Role: domain.RoleOperator},
+		store.HashAPIKey("whk_legacy"):   {ID: "k3", Name: "legacy"},
+	})
+	handler := auth.RequireRole(domain.RoleOperator)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		token string
+		want  int
+	}{
+		{"whk_viewer", http.StatusForbidden},
+		{"whk_operator", http.StatusOK},
+		{"admin-secret", http.StatusOK},
+		{"whk_legacy", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/events", nil)
+		req.Header.Set("Authorization", "Bearer "+tt.token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.token, rec.Code, tt.want)
+		}
+	}
+}
@@ -1,22 +1,39 @@
 package api
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
 	"github.com/Priya8975/webhook-delivery-system/internal/engine"
 	"github.com/Priya8975/webhook-delivery-system/internal/store"
 	ws "github.com/Priya8975/webhook-delivery-system/internal/websocket"
+	"github.com/Priya8975/webhook-delivery-system/internal/worker"
 )
 
+// DashboardStore is what the dashboard reads: aggregate metrics and the
+// subscriber list.
+type DashboardStore interface {
+	store.MetricsStore
+	ListSubscribers(ctx context.Context) ([]domain.Subscriber, error)
+	CountPendingJobs(ctx context.Context) (int64, error)
+}
+
 type DashboardHandler struct {
-	store  *store.PostgresStore
+	store  DashboardStore
 	fanout *engine.FanOutEngine
 	cb     *engine.CircuitBreaker
 	hub    *ws.Hub
+	pool   *worker.Pool
+	disp   *worker.Dispatcher
 }
 
-func NewDashboardHandler(s *store.PostgresStore, f *engine.FanOutEngine, cb *engine.CircuitBreaker, hub *ws.Hub) *DashboardHandler {
-	return &DashboardHandler{store: s, fanout: f, cb: cb, hub: hub}
+func NewDashboardHandler(s DashboardStore, f *engine.FanOutEngine, cb *engine.CircuitBreaker, hub *ws.Hub, pool *worker.Pool, disp *worker.Dispatcher) *DashboardHandler {
+	return &DashboardHandler{store: s, fanout: f, cb: cb, hub: hub, pool: pool, disp: disp}
 }
 
 // Metrics returns aggregated system metrics for the dashboard.
@@ -33,19 +50,158 @@ func (h *DashboardHandler) Metrics(w http.ResponseWriter, r *http.Request) {
 		queueDepth = 0
 	}
 
+	// Jobs saved to the database while the queue was unavailable
+	pendingJobs, err := h.store.CountPendingJobs(r.Context())
+	if err != nil {
+		pendingJobs = 0
+	}
+
 	type metricsResponse struct {
 		store.DeliveryMetrics
-		QueueDepth       int64 `json:"queue_depth"`
-		WebSocketClients int   `json:"websocket_clients"`
+		QueueDepth       int64            `json:"queue_depth"`
+		PendingJobs      int64            `json:"pending_jobs"`
+		DispatcherLagMs  int64            `json:"dispatcher_lag_ms"`
+		WorkerPool       worker.PoolStats `json:"worker_pool"`
+		WebSocketClients int              `json:"websocket_clients"`
 	}
 
 	respondJSON(w, http.StatusOK, metricsResponse{
 		DeliveryMetrics:  *metrics,
 		QueueDepth:       queueDepth,
+		PendingJobs:      pendingJobs,
+		DispatcherLagMs:  h.disp.LagMs(),
+		WorkerPool:       h.pool.Stats(),
 		WebSocketClients: h.hub.ClientCount(),
 	})
 }
 
+const (
+	maxTimeseriesWindow  = 7 * 24 * time.Hour
+	minTimeseriesStep    = 1 * time.Minute
+	maxTimeseriesBuckets = 2000
+)
+
+// Timeseries returns bucketed delivery counts, success rate and latency
+// percentiles plus queue depth samples for ?window=24h&interval=5m.
+func (h *DashboardHandler) Timeseries(w http.ResponseWriter, r *http.Request) {
+	window, err := parseSpan(r.URL.Query().Get("window"), 24*time.Hour)
+	if err != nil || window <= 0 || window > maxTimeseriesWindow {
+		respondError(w, http.StatusBadRequest, "window must be a duration up to 7d, e.g. 1h, 24h, 7d")
+		return
+	}
+	interval, err := parseSpan(r.URL.Query().Get("interval"), 5*time.Minute)
+	if err != nil || interval < minTimeseriesStep {
+		respondError(w, http.StatusBadRequest, "interval must be a duration of at least 1m")
+		return
+	}
+	if window/interval > maxTimeseriesBuckets {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("window/interval yields more than %d buckets", maxTimeseriesBuckets))
+		return
+	}
+
+	now := time.Now().UTC()
+	step := int64(interval.Seconds())
+	since := time.Unix(now.Add(-window).Unix()/step*step, 0).UTC() // aligned like the SQL buckets
+
+	rows, err := h.store.GetDeliveryTimeseries(r.Context(), since, interval)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get delivery timeseries")
+		return
+	}
+
+	samples, err := h.fanout.QueueDepthSamples(r.Context(), since)
+	if err != nil {
+		samples = []engine.QueueDepthSample{}
+	}
+
+	type timeseriesResponse struct {
+		Window     string                    `json:"window"`
+		Interval   string                    `json:"interval"`
+		Buckets    []store.TimeseriesBucket  `json:"buckets"`
+		QueueDepth []engine.QueueDepthSample `json:"queue_depth"`
+	}
+
+	respondJSON(w, http.StatusOK, timeseriesResponse{
+		Window:     window.String(),
+		Interval:   interval.String(),
+		Buckets:    fillBuckets(rows, since, now, interval),
+		QueueDepth: samples,
+	})
+}
+
+// fillBuckets returns one bucket per interval from since through now,
+// inserting zero-valued buckets where there were no attempts so charts have
+// an evenly spaced x-axis.
+func fillBuckets(rows []store.TimeseriesBucket, since, now time.Time, interval time.Duration) []store.TimeseriesBucket {
+	byStart := make(map[int64]store.TimeseriesBucket, len(rows))
+	for _, b := range rows {
+		byStart[b.Start.Unix()] = b
+	}
+
+	buckets := make([]store.TimeseriesBucket, 0, int(now.Sub(since)/interval)+1)
+	for t := since; !t.After(now); t = t.Add(interval) {
+		b, ok := byStart[t.Unix()]
+		if !ok {
+			b = store.TimeseriesBucket{Start: t}
+		}
+		buckets = append(buckets, b)
+	}
+	return buckets
+}
+
+// parseSpan parses a Go duration, additionally accepting whole days such as
+// "7d". An empty string yields fallback.
+func parseSpan(s string, fallback time.Duration) (time.Duration, error) {
+	if s == "" {
+		return fallback, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// NewSnapshotFunc returns the snapshot the WebSocket hub sends to newly
+// connected dashboard clients: queue depth plus every active subscriber's
+// circuit breaker state.
+func NewSnapshotFunc(s DashboardStore, f *engine.FanOutEngine, cb *engine.CircuitBreaker) ws.SnapshotFunc {
+	return func(ctx context.Context) (*ws.Snapshot, error) {
+		depth, err := f.QueueDepth(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("reading queue depth: %w", err)
+		}
+
+		subscribers, err := s.ListSubscribers(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		breakers := make([]ws.BreakerState, 0, len(subscribers))
+		for _, sub := range subscribers {
+			if !sub.IsActive {
+				continue
+			}
+			state := cb.GetState(ctx, sub.ID)
+			breakers = append(breakers, ws.BreakerState{
+				SubscriberID: sub.ID,
+				Name:         sub.Name,
+				State:        state.State,
+				Failures:     state.Failures,
+			})
+		}
+
+		return &ws.Snapshot{
+			QueueDepth:      depth,
+			CircuitBreakers: breakers,
+			Timestamp:       time.Now(),
+		}, nil
+	}
+}
+
 // SubscriberHealth returns health info for all active subscribers including circuit breaker state.
 func (h *DashboardHandler) SubscriberHealth(w http.ResponseWriter, r *http.Request) {
 	subscribers, err := h.store.ListSubscribers(r.Context())
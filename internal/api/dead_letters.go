@@ -5,16 +5,19 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
 	"github.com/Priya8975/webhook-delivery-system/internal/store"
 	"github.com/go-chi/chi/v5"
 )
 
 type DeadLetterHandler struct {
-	store *store.PostgresStore
+	store  store.Store
+	fanout *engine.FanOutEngine
 }
 
-func NewDeadLetterHandler(s *store.PostgresStore) *DeadLetterHandler {
-	return &DeadLetterHandler{store: s}
+func NewDeadLetterHandler(s store.Store, f *engine.FanOutEngine) *DeadLetterHandler {
+	return &DeadLetterHandler{store: s, fanout: f}
 }
 
 func (h *DeadLetterHandler) List(w http.ResponseWriter, r *http.Request) {
@@ -34,7 +37,11 @@ func (h *DeadLetterHandler) List(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	letters, err := h.store.ListDeadLetters(r.Context(), subscriberID, resolved, limit)
+	letters, err := h.store.ListDeadLetters(r.Context(), store.DeadLetterFilter{
+		SubscriberID: subscriberID,
+		Resolved:     &resolved,
+		Limit:        limit,
+	})
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "failed to list dead letters")
 		return
@@ -43,6 +50,92 @@ func (h *DeadLetterHandler) List(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, letters)
 }
 
+// deadLetterSummary is the unresolved dead letters grouped by subscriber
+// and how they failed.
+type deadLetterSummary struct {
+	Total  int64                    `json:"total"`
+	Groups []domain.DeadLetterGroup `json:"groups"`
+}
+
+// Summary groups the unresolved dead letters, of one subscriber_id if
+// given, by subscriber, failure reason and last HTTP status, largest group
+// first, so the cause of a pile of dead letters shows at a glance.
+func (h *DeadLetterHandler) Summary(w http.ResponseWriter, r *http.Request) {
+	groups, err := h.store.SummarizeDeadLetters(r.Context(), r.URL.Query().Get("subscriber_id"))
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to summarize dead letters")
+		return
+	}
+
+	summary := deadLetterSummary{Groups: groups}
+	for _, g := range groups {
+		summary.Total += g.Count
+	}
+	respondJSON(w, http.StatusOK, summary)
+}
+
+// deadLetterExportHeader names the CSV columns of a dead letter export.
+var deadLetterExportHeader = []string{
+	"id", "event_id", "subscriber_id", "event_type", "event_source", "total_attempts",
+	"last_http_status", "failure_reason", "last_error", "created_at", "resolved_at", "resolved_by",
+}
+
+// Export streams the dead letters of a subscriber_id and RFC 3339
+// since/until range, newest first. Unlike List, resolved and unresolved
+// entries are both exported unless resolved=true or resolved=false is given.
+func (h *DeadLetterHandler) Export(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	since, until, err := parseTimeRange(q)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter := store.DeadLetterFilter{
+		SubscriberID: q.Get("subscriber_id"),
+		Since:        since,
+		Until:        until,
+		Limit:        exportPageSize,
+	}
+	if v := q.Get("resolved"); v != "" {
+		resolved, err := strconv.ParseBool(v)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "resolved must be true or false")
+			return
+		}
+		filter.Resolved = &resolved
+	}
+	out, err := newExportWriter(w, r, "dead-letters", deadLetterExportHeader)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	for {
+		letters, err := h.store.ListDeadLetters(r.Context(), filter)
+		if err != nil {
+			out.fail(w, "failed to list dead letters")
+			return
+		}
+		for _, dl := range letters {
+			if err := out.write(dl, deadLetterRecord(dl)); err != nil {
+				return
+			}
+		}
+		if err := out.flush(); err != nil || len(letters) < exportPageSize {
+			return
+		}
+		last := letters[len(letters)-1]
+		filter.After = &store.PageCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+}
+
+func deadLetterRecord(dl domain.DeadLetter) []string {
+	return []string{
+		dl.ID, dl.EventID, dl.SubscriberID, csvString(dl.EventType), csvString(dl.EventSource), strconv.Itoa(dl.TotalAttempts),
+		csvInt(dl.LastHTTPStatus), csvString(dl.FailureReason), csvString(dl.LastError), csvTime(&dl.CreatedAt), csvTime(dl.ResolvedAt), csvString(dl.ResolvedBy),
+	}
+}
+
 func (h *DeadLetterHandler) Get(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
@@ -81,5 +174,71 @@ func (h *DeadLetterHandler) Resolve(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r, h.store, domain.AuditDeadLetterResolve, domain.AuditEntityDeadLetter, id, req)
+
 	respondJSON(w, http.StatusOK, map[string]string{"status": "resolved"})
 }
+
+// Replay queues the dead-lettered event for its subscriber again, starting
+// from the first attempt, and resolves the dead letter.
+func (h *DeadLetterHandler) Replay(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	letter, err := h.store.GetDeadLetter(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get dead letter")
+		return
+	}
+	if letter == nil {
+		respondError(w, http.StatusNotFound, "dead letter not found")
+		return
+	}
+	if letter.ResolvedAt != nil {
+		respondError(w, http.StatusConflict, "dead letter already resolved")
+		return
+	}
+
+	// Prefer the live event row and fall back to the dead letter's snapshot
+	// once the event has been pruned
+	event, err := h.store.GetEvent(r.Context(), letter.EventID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get event")
+		return
+	}
+	if event == nil {
+		event = letter.SnapshotEvent()
+	}
+	if event == nil {
+		respondError(w, http.StatusGone, "event no longer exists")
+		return
+	}
+
+	sub, err := h.store.GetSubscriber(r.Context(), letter.SubscriberID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get subscriber")
+		return
+	}
+	if sub == nil {
+		respondError(w, http.StatusGone, "subscriber no longer exists")
+		return
+	}
+	if sub.Deleted() {
+		respondError(w, http.StatusConflict, "subscriber is deleted; restore it first")
+		return
+	}
+
+	if err := h.fanout.Redeliver(r.Context(), event, sub); err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to queue redelivery")
+		return
+	}
+
+	// A concurrent resolve may win the race; the redelivery is queued either way.
+	h.store.ResolveDeadLetter(r.Context(), id, "replay")
+
+	recordAudit(r, h.store, domain.AuditDeadLetterReplay, domain.AuditEntityDeadLetter, id, map[string]string{
+		"event_id":      letter.EventID,
+		"subscriber_id": letter.SubscriberID,
+	})
+
+	respondJSON(w, http.StatusAccepted, map[string]string{"status": "replayed"})
+}
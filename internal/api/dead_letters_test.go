@@ -0,0 +1,67 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+)
+
+func TestDeadLetterHandler_Summary(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewMemoryStore()
+	orders, _ := s.CreateSubscriber(ctx, domain.CreateSubscriberRequest{Name: "orders", EndpointURL: "https://orders.example.com/hook"})
+	billing, _ := s.CreateSubscriber(ctx, domain.CreateSubscriberRequest{Name: "billing", EndpointURL: "https://billing.example.com/hook"})
+	notFound, unavailable := 404, 503
+	for _, rec := range []store.DeadLetterRecord{
+		{SubscriberID: orders.ID, LastHTTPStatus: &notFound, FailureReason: domain.FailureHTTP4xx},
+		{SubscriberID: orders.ID, LastHTTPStatus: &notFound, FailureReason: domain.FailureHTTP4xx},
+		{SubscriberID: orders.ID, LastHTTPStatus: &notFound, FailureReason: domain.FailureHTTP4xx},
+		{SubscriberID: orders.ID, LastHTTPStatus: &unavailable, FailureReason: domain.FailureHTTP5xx},
+		{SubscriberID: billing.ID, FailureReason: domain.FailureConnectTimeout},
+		{SubscriberID: billing.ID, FailureReason: domain.FailureConnectTimeout},
+	} {
+		rec.EventID, rec.TotalAttempts = "evt-1", 5
+		s.InsertDeadLetter(ctx, rec)
+	}
+	// Resolved dead letters are left out
+	letters, _ := s.ListDeadLetters(ctx, store.DeadLetterFilter{SubscriberID: billing.ID, Limit: 1})
+	s.ResolveDeadLetter(ctx, letters[0].ID, "manual")
+
+	h := NewDeadLetterHandler(s, nil)
+	get := func(query string) deadLetterSummary {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		h.Summary(rec, httptest.NewRequest(http.MethodGet, "/dead-letters/summary"+query, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+		}
+		var summary deadLetterSummary
+		json.NewDecoder(rec.Body).Decode(&summary)
+		return summary
+	}
+
+	summary := get("")
+	if summary.Total != 5 || len(summary.Groups) != 3 {
+		t.Fatalf("summary = %+v, want 5 dead letters in 3 groups", summary)
+	}
+	top := summary.Groups[0]
+	if top.SubscriberName != "orders" || top.Count != 3 || top.LastHTTPStatus == nil || *top.LastHTTPStatus != 404 ||
+		top.FailureReason == nil || *top.FailureReason != string(domain.FailureHTTP4xx) {
+		t.Errorf("largest group = %+v, want orders' three 404s", top)
+	}
+	if top.FirstAt.IsZero() || top.LastAt.Before(top.FirstAt) {
+		t.Errorf("largest group spans %v to %v", top.FirstAt, top.LastAt)
+	}
+	if g := summary.Groups[1]; g.SubscriberName != "billing" || g.Count != 1 || g.LastHTTPStatus != nil {
+		t.Errorf("second group = %+v, want billing's open connect timeout", g)
+	}
+
+	if summary := get("?subscriber_id=" + orders.ID); summary.Total != 4 || len(summary.Groups) != 2 {
+		t.Errorf("orders summary = %+v, want 4 dead letters in 2 groups", summary)
+	}
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+	"github.com/Priya8975/webhook-delivery-system/internal/worker"
+	"github.com/go-chi/chi/v5"
+)
+
+// RuntimeDiagnostics is returned by GET /debug/runtime.
+type RuntimeDiagnostics struct {
+	Goroutines     int                     `json:"goroutines"`
+	GOMAXPROCS     int                     `json:"gomaxprocs"`
+	HeapAllocBytes uint64                  `json:"heap_alloc_bytes"`
+	HeapObjects    uint64                  `json:"heap_objects"`
+	GCCycles       uint32                  `json:"gc_cycles"`
+	LastGCPauseMs  float64                 `json:"last_gc_pause_ms"`
+	WorkerPool     *worker.PoolStats       `json:"worker_pool,omitempty"`
+	Dispatcher     *worker.DispatcherStats `json:"dispatcher,omitempty"`
+	// DatabasePool is only reported for PostgreSQL.
+	DatabasePool *store.PoolStats `json:"database_pool,omitempty"`
+}
+
+// DebugHandler serves profiling and runtime diagnostics of the instance
+// that handles the request. With several replicas, each must be queried.
+type DebugHandler struct {
+	db   store.Database
+	pool *worker.Pool
+	disp *worker.Dispatcher
+}
+
+func NewDebugHandler(db store.Database, pool *worker.Pool, disp *worker.Dispatcher) *DebugHandler {
+	return &DebugHandler{db: db, pool: pool, disp: disp}
+}
+
+// Runtime reports goroutine and memory figures along with the worker pool's
+// occupancy, the dispatch loop's timing and database connection use.
+func (h *DebugHandler) Runtime(w http.ResponseWriter, r *http.Request) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	diag := RuntimeDiagnostics{
+		Goroutines:     runtime.NumGoroutine(),
+		GOMAXPROCS:     runtime.GOMAXPROCS(0),
+		HeapAllocBytes: mem.HeapAlloc,
+		HeapObjects:    mem.HeapObjects,
+		GCCycles:       mem.NumGC,
+	}
+	if mem.NumGC > 0 {
+		pause := time.Duration(mem.PauseNs[(mem.NumGC+255)%256])
+		diag.LastGCPauseMs = float64(pause) / float64(time.Millisecond)
+	}
+	if h.pool != nil {
+		stats := h.pool.Stats()
+		diag.WorkerPool = &stats
+	}
+	if h.disp != nil {
+		stats := h.disp.Stats()
+		diag.Dispatcher = &stats
+	}
+	if stats, ok := store.DatabasePoolStats(h.db); ok {
+		diag.DatabasePool = &stats
+	}
+
+	respondJSON(w, http.StatusOK, diag)
+}
+
+// Profile serves the net/http/pprof handlers: the index at /debug/pprof/,
+// and the named profiles, cmdline, profile (CPU), symbol and trace below it.
+func (h *DebugHandler) Profile(w http.ResponseWriter, r *http.Request) {
+	switch chi.URLParam(r, "profile") {
+	case "cmdline":
+		pprof.Cmdline(w, r)
+	case "profile":
+		pprof.Profile(w, r)
+	case "symbol":
+		pprof.Symbol(w, r)
+	case "trace":
+		pprof.Trace(w, r)
+	default:
+		// The index, and named profiles such as heap and goroutine
+		pprof.Index(w, r)
+	}
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+	"github.com/Priya8975/webhook-delivery-system/internal/worker"
+)
+
+func TestDebugHandler_Runtime(t *testing.T) {
+	pool := worker.NewPool(4, nil, nil, slog.Default())
+	h := NewDebugHandler(nil, pool, worker.NewDispatcher(engine.NewMemoryQueue(), pool, slog.Default()))
+
+	rec := httptest.NewRecorder()
+	h.Runtime(rec, httptest.NewRequest(http.MethodGet, "/debug/runtime", nil))
+
+	var diag RuntimeDiagnostics
+	if err := json.NewDecoder(rec.Body).Decode(&diag); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if rec.Code != http.StatusOK || diag.Goroutines == 0 || diag.GOMAXPROCS == 0 || diag.HeapAllocBytes == 0 {
+		t.Errorf("status = %d, diagnostics = %+v", rec.Code, diag)
+	}
+	if diag.WorkerPool == nil || diag.WorkerPool.Workers != 4 || diag.WorkerPool.BufferCapacity != 8 {
+		t.Errorf("worker pool = %+v", diag.WorkerPool)
+	}
+	if diag.Dispatcher == nil || diag.Dispatcher.Running {
+		t.Errorf("dispatcher = %+v", diag.Dispatcher)
+	}
+}
+
+func TestDebugHandler_Profile(t *testing.T) {
+	auth, _ := newTestAuthenticator(nil)
+	router := NewRouter(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, auth, nil, nil, nil, nil, nil, nil)
+
+	for _, tc := range []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/debug/pprof/", http.StatusOK, "Types of profiles available"},
+		{"/debug/pprof/goroutine?debug=1", http.StatusOK, "goroutine profile:"},
+		{"/debug/pprof/heap?debug=1", http.StatusOK, "heap profile:"},
+		{"/debug/pprof/cmdline", http.StatusOK, ""},
+		{"/debug/pprof/nonexistent", http.StatusNotFound, "Unknown profile"},
+	} {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		req.Header.Set("Authorization", "Bearer admin-secret")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != tc.status || !strings.Contains(rec.Body.String(), tc.body) {
+			t.Errorf("GET %s: status = %d, body starts %.80q", tc.path, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestDebugRoutes_NotServedWithoutAuth(t *testing.T) {
+	auth := &Authenticator{enabled: false}
+	router := NewRouter(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, auth, nil, nil, nil, nil, nil, nil)
+
+	for _, path := range []string{"/debug/runtime", "/debug/pprof/", "/debug/pprof/heap?debug=1", "/debug/pprof/goroutine?debug=2"} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("GET %s with authentication disabled: status = %d, want 404", path, rec.Code)
+		}
+	}
+}
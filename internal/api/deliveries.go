@@ -1,18 +1,22 @@
 package api
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
 	"github.com/Priya8975/webhook-delivery-system/internal/store"
 	"github.com/go-chi/chi/v5"
 )
 
 type DeliveryHandler struct {
-	store *store.PostgresStore
+	store store.DeliveryStore
 }
 
-func NewDeliveryHandler(s *store.PostgresStore) *DeliveryHandler {
+func NewDeliveryHandler(s store.DeliveryStore) *DeliveryHandler {
 	return &DeliveryHandler{store: s}
 }
 
@@ -20,6 +24,7 @@ func (h *DeliveryHandler) List(w http.ResponseWriter, r *http.Request) {
 	eventID := r.URL.Query().Get("event_id")
 	subscriberID := r.URL.Query().Get("subscriber_id")
 	status := r.URL.Query().Get("status")
+	failureReason := r.URL.Query().Get("failure_reason")
 	limitStr := r.URL.Query().Get("limit")
 
 	limit := 50
@@ -29,7 +34,20 @@ func (h *DeliveryHandler) List(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	attempts, err := h.store.ListDeliveryAttempts(r.Context(), eventID, subscriberID, status, limit)
+	responseHeaders, err := parseResponseHeaderFilter(r.URL.Query()["response_header"])
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	attempts, err := h.store.ListDeliveryAttempts(r.Context(), store.DeliveryAttemptFilter{
+		EventID:         eventID,
+		SubscriberID:    subscriberID,
+		Status:          status,
+		FailureReason:   failureReason,
+		ResponseHeaders: responseHeaders,
+		Limit:           limit,
+	})
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "failed to list delivery attempts")
 		return
@@ -38,6 +56,81 @@ func (h *DeliveryHandler) List(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, attempts)
 }
 
+// deliveryExportHeader names the CSV columns of a delivery export.
+var deliveryExportHeader = []string{
+	"id", "event_id", "subscriber_id", "attempt_number", "status", "http_status_code",
+	"failure_reason", "error_message", "response_time_ms", "timings", "response_headers", "response_body",
+	"next_retry_at", "final_url", "created_at",
+}
+
+// Export streams every delivery attempt matching the List filters, plus an
+// RFC 3339 since/until range, newest first. The store is read a page at a
+// time, so exports are not capped like List.
+func (h *DeliveryHandler) Export(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	since, until, err := parseTimeRange(q)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	responseHeaders, err := parseResponseHeaderFilter(q["response_header"])
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	out, err := newExportWriter(w, r, "deliveries", deliveryExportHeader)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filter := store.DeliveryAttemptFilter{
+		EventID:         q.Get("event_id"),
+		SubscriberID:    q.Get("subscriber_id"),
+		Status:          q.Get("status"),
+		FailureReason:   q.Get("failure_reason"),
+		ResponseHeaders: responseHeaders,
+		Since:           since,
+		Until:           until,
+		Limit:           exportPageSize,
+	}
+	for {
+		attempts, err := h.store.ListDeliveryAttempts(r.Context(), filter)
+		if err != nil {
+			out.fail(w, "failed to list delivery attempts")
+			return
+		}
+		for _, a := range attempts {
+			if err := out.write(a, deliveryRecord(a)); err != nil {
+				return
+			}
+		}
+		if err := out.flush(); err != nil || len(attempts) < exportPageSize {
+			return
+		}
+		last := attempts[len(attempts)-1]
+		filter.After = &store.PageCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+}
+
+func deliveryRecord(a domain.DeliveryAttempt) []string {
+	headers := ""
+	if len(a.ResponseHeaders) > 0 {
+		b, _ := json.Marshal(a.ResponseHeaders)
+		headers = string(b)
+	}
+	timings := ""
+	if a.Timings != nil {
+		b, _ := json.Marshal(a.Timings)
+		timings = string(b)
+	}
+	return []string{
+		a.ID, a.EventID, a.SubscriberID, strconv.Itoa(a.AttemptNumber), a.Status, csvInt(a.HTTPStatusCode),
+		csvString(a.FailureReason), csvString(a.ErrorMessage), csvInt(a.ResponseTimeMs), timings, headers, csvString(a.ResponseBody),
+		csvTime(a.NextRetryAt), csvString(a.FinalURL), csvTime(&a.CreatedAt),
+	}
+}
+
 func (h *DeliveryHandler) Get(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
@@ -53,3 +146,21 @@ func (h *DeliveryHandler) Get(w http.ResponseWriter, r *http.Request) {
 
 	respondJSON(w, http.StatusOK, attempt)
 }
+
+// parseResponseHeaderFilter parses response_header values of the form
+// "Name:value", e.g. "X-Request-Id:abc123", into a header match.
+func parseResponseHeaderFilter(values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	headers := make(map[string]string, len(values))
+	for _, v := range values {
+		name, value, ok := strings.Cut(v, ":")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if !ok || name == "" || value == "" {
+			return nil, fmt.Errorf("response_header must look like Name:value, got %q", v)
+		}
+		headers[http.CanonicalHeaderKey(name)] = value
+	}
+	return headers, nil
+}
@@ -0,0 +1,26 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseResponseHeaderFilter(t *testing.T) {
+	got, err := parseResponseHeaderFilter([]string{"x-request-id: abc:123", "Content-Type:text/plain"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]string{"X-Request-Id": "abc:123", "Content-Type": "text/plain"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("filter = %v, want %v", got, want)
+	}
+
+	if got, err := parseResponseHeaderFilter(nil); got != nil || err != nil {
+		t.Errorf("no values = %v, %v; want nil", got, err)
+	}
+	for _, bad := range []string{"X-Request-Id", ":abc", "X-Request-Id:"} {
+		if _, err := parseResponseHeaderFilter([]string{bad}); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package api
+
+import "net/http"
+
+// EgressInfo is returned by GET /api/v1/egress-info.
+type EgressInfo struct {
+	// IPs lists the addresses and CIDR ranges deliveries are sent from,
+	// as configured with EGRESS_IPS. Empty when none are configured.
+	IPs []string `json:"ips"`
+}
+
+// EgressInfoHandler publishes the configured egress addresses so that
+// subscribers can allowlist them. It is public: the people configuring a
+// firewall for a webhook endpoint rarely hold an API key.
+func EgressInfoHandler(ips []string) http.HandlerFunc {
+	info := EgressInfo{IPs: append([]string{}, ips...)}
+	return func(w http.ResponseWriter, r *http.Request) {
+		respondJSON(w, http.StatusOK, info)
+	}
+}
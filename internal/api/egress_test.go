@@ -0,0 +1,25 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestEgressInfoHandler(t *testing.T) {
+	for _, ips := range [][]string{nil, {"203.0.113.10", "198.51.100.0/28"}} {
+		rec := httptest.NewRecorder()
+		EgressInfoHandler(ips)(rec, httptest.NewRequest(http.MethodGet, "/api/v1/egress-info", nil))
+
+		var info EgressInfo
+		if err := json.NewDecoder(rec.Body).Decode(&info); err != nil {
+			t.Fatalf("decoding response: %v", err)
+		}
+		// An empty list is returned as [] rather than null
+		if rec.Code != http.StatusOK || info.IPs == nil || (len(ips) > 0 && !reflect.DeepEqual(info.IPs, ips)) {
+			t.Errorf("EgressInfoHandler(%v): status = %d, ips = %#v", ips, rec.Code, info.IPs)
+		}
+	}
+}
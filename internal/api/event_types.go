@@ -0,0 +1,80 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+// maxEventTypeDescription caps how long a catalog description can be.
+const maxEventTypeDescription = 2000
+
+// EventTypeHandler serves the event type catalog, which tells teams building
+// subscribers what they can subscribe to.
+type EventTypeHandler struct {
+	store eventTypeStore
+}
+
+type eventTypeStore interface {
+	store.EventTypeStore
+	store.AuditStore
+}
+
+func NewEventTypeHandler(s eventTypeStore) *EventTypeHandler {
+	return &EventTypeHandler{store: s}
+}
+
+// List returns every documented or published event type, sorted by name.
+func (h *EventTypeHandler) List(w http.ResponseWriter, r *http.Request) {
+	types, err := h.store.ListEventTypes(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list event types")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, types)
+}
+
+// Describe creates or replaces the catalog entry for an event type.
+func (h *EventTypeHandler) Describe(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	if err := domain.ValidateEventPattern(name); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if domain.IsEventPattern(name) {
+		respondError(w, http.StatusBadRequest, "event type must not contain wildcards")
+		return
+	}
+
+	var req domain.DescribeEventTypeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if len(req.Description) > maxEventTypeDescription {
+		respondError(w, http.StatusBadRequest, "description is too long")
+		return
+	}
+	if req.CurrentVersion < 0 {
+		respondError(w, http.StatusBadRequest, "current_version must be at least 1")
+		return
+	}
+	if bytes.Equal(bytes.TrimSpace(req.ExamplePayload), []byte("null")) {
+		req.ExamplePayload = nil
+	}
+
+	et, err := h.store.DescribeEventType(r.Context(), name, req)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to describe event type")
+		return
+	}
+
+	recordAudit(r, h.store, domain.AuditEventTypeDescribe, domain.AuditEntityEventType, name, req)
+
+	respondJSON(w, http.StatusOK, et)
+}
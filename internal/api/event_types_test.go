@@ -0,0 +1,70 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+func TestEventTypeHandler_ListAndDescribe(t *testing.T) {
+	s := store.NewMemoryStore()
+	h := NewEventTypeHandler(s)
+
+	r := chi.NewRouter()
+	r.Get("/event-types", h.List)
+	r.Put("/event-types/{name}", h.Describe)
+
+	ctx := context.Background()
+	s.CreateEvent(ctx, "order.created", 1, []byte(`{"id":1}`), nil, "test", nil)
+	s.CreateEvent(ctx, "order.created", 2, []byte(`{"id":2}`), nil, "test", nil)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/event-types/user.created",
+		strings.NewReader(`{"description":"A user signed up","example_payload":{"user_id":"u1"},"current_version":3}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("describe status = %d: %s", rec.Code, rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/event-types/order.*", strings.NewReader(`{}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("describing a pattern: status = %d, want 400", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/event-types/user.deleted", strings.NewReader(`{"current_version":-1}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("negative current_version: status = %d, want 400", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/event-types", nil))
+	var types []domain.EventType
+	json.NewDecoder(rec.Body).Decode(&types)
+	if len(types) != 2 {
+		t.Fatalf("catalog = %+v, want 2 types", types)
+	}
+
+	order, user := types[0], types[1]
+	if order.Name != "order.created" || order.Documented || order.EventCount != 2 || string(order.ExamplePayload) != `{"id":2}` {
+		t.Errorf("published type = %+v, want 2 events with the latest payload as example", order)
+	}
+	if order.CurrentVersion != 2 {
+		t.Errorf("published type current_version = %d, want the latest published, 2", order.CurrentVersion)
+	}
+	if user.Name != "user.created" || !user.Documented || user.EventCount != 0 || user.Description != "A user signed up" || user.CurrentVersion != 3 {
+		t.Errorf("documented type = %+v", user)
+	}
+
+	entries, _ := s.ListAuditEntries(ctx, domain.AuditFilter{Action: domain.AuditEventTypeDescribe})
+	if len(entries) != 1 || entries[0].EntityID != "user.created" {
+		t.Errorf("audit entries = %+v", entries)
+	}
+}
@@ -1,21 +1,31 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
 	"github.com/Priya8975/webhook-delivery-system/internal/engine"
 	"github.com/Priya8975/webhook-delivery-system/internal/store"
 	"github.com/go-chi/chi/v5"
 )
 
 type EventHandler struct {
-	store  *store.PostgresStore
+	store  eventStore
 	fanout *engine.FanOutEngine
 }
 
-func NewEventHandler(s *store.PostgresStore, f *engine.FanOutEngine) *EventHandler {
+type eventStore interface {
+	store.EventStore
+	store.FanOutStatusStore
+	ListDeliveryAttempts(ctx context.Context, f store.DeliveryAttemptFilter) ([]domain.DeliveryAttempt, error)
+	OutboxEntryPending(ctx context.Context, eventID string) (bool, error)
+}
+
+func NewEventHandler(s eventStore, f *engine.FanOutEngine) *EventHandler {
 	return &EventHandler{store: s, fanout: f}
 }
 
@@ -23,17 +33,30 @@ type createEventRequest struct {
 	EventType string          `json:"event_type"`
 	Payload   json.RawMessage `json:"payload"`
 	Source    string          `json:"source,omitempty"`
+	// Version is the version of the event type's payload, 1 if unset
+	Version int `json:"version,omitempty"`
+	// SubscriberIDs restricts fan-out to these subscribers
+	SubscriberIDs []string `json:"subscriber_ids,omitempty"`
 }
 
 type createEventResponse struct {
 	EventID          string `json:"event_id"`
 	EventType        string `json:"event_type"`
 	DeliveriesQueued int    `json:"deliveries_queued"`
+	FanOutPending    bool   `json:"fanout_pending,omitempty"`
+	// FailedSubscribers lists the subscribers that could not be queued
+	// when fan-out partly failed
+	FailedSubscribers []string `json:"failed_subscribers,omitempty"`
 }
 
 func (h *EventHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var req createEventRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respondError(w, http.StatusRequestEntityTooLarge, payloadTooLarge(tooLarge.Limit))
+			return
+		}
 		respondError(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
@@ -42,6 +65,10 @@ func (h *EventHandler) Create(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusBadRequest, "event_type is required")
 		return
 	}
+	if err := domain.ValidatePublishedEventType(req.EventType); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	if len(req.Payload) == 0 {
 		respondError(w, http.StatusBadRequest, "payload is required")
 		return
@@ -52,31 +79,35 @@ func (h *EventHandler) Create(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusBadRequest, "payload must be valid JSON")
 		return
 	}
-
-	// Save event to PostgreSQL
-	event, err := h.store.CreateEvent(r.Context(), req.EventType, req.Payload, req.Source)
-	if err != nil {
-		respondError(w, http.StatusInternalServerError, "failed to create event")
+	if req.Version < 0 {
+		respondError(w, http.StatusBadRequest, "version must be at least 1")
 		return
 	}
 
-	// Fan-out: find matching subscribers and queue delivery jobs
-	queued, err := h.fanout.FanOut(r.Context(), event)
+	// Save the event and fan out. If fan-out fails, the event and its
+	// outbox entry are saved and the outbox relay retries in the background
+	result, err := h.fanout.Publish(r.Context(), req.EventType, req.Version, req.Payload, req.Source, req.SubscriberIDs)
 	if err != nil {
-		// Event is saved but fan-out failed — log but don't fail the request
-		// The event can be replayed later
-		respondJSON(w, http.StatusCreated, createEventResponse{
-			EventID:          event.ID,
-			EventType:        event.EventType,
-			DeliveriesQueued: 0,
-		})
+		var unmatched *engine.UnmatchedSubscribersError
+		if errors.As(err, &unmatched) {
+			respondError(w, http.StatusBadRequest, unmatched.Error())
+			return
+		}
+		var tooLarge *engine.PayloadTooLargeError
+		if errors.As(err, &tooLarge) {
+			respondError(w, http.StatusRequestEntityTooLarge, tooLarge.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to create event")
 		return
 	}
 
 	respondJSON(w, http.StatusCreated, createEventResponse{
-		EventID:          event.ID,
-		EventType:        event.EventType,
-		DeliveriesQueued: queued,
+		EventID:           result.Event.ID,
+		EventType:         result.Event.EventType,
+		DeliveriesQueued:  result.DeliveriesQueued,
+		FanOutPending:     result.FanOutPending,
+		FailedSubscribers: result.FailedSubscribers,
 	})
 }
 
@@ -100,6 +131,48 @@ func (h *EventHandler) List(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, events)
 }
 
+// maxSearchResults caps the events of one payload search, since each comes
+// with its delivery attempts.
+const maxSearchResults = 100
+
+// Search finds events by a business identifier in their payload, such as
+// query=order_id:abc-123, and returns each with its delivery attempts, so
+// support can go from what a consumer reports to what was sent to them.
+func (h *EventHandler) Search(w http.ResponseWriter, r *http.Request) {
+	terms, err := domain.ParsePayloadQuery(r.URL.Query().Get("query"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	limit := 20
+	if n, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && n > 0 {
+		limit = min(n, maxSearchResults)
+	}
+
+	events, err := h.store.SearchEvents(r.Context(), store.EventSearchFilter{
+		Terms:     terms,
+		EventType: r.URL.Query().Get("event_type"),
+		Limit:     limit,
+	})
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to search events")
+		return
+	}
+
+	results := make([]domain.EventSearchResult, len(events))
+	for i, e := range events {
+		attempts, err := h.store.ListDeliveryAttempts(r.Context(), store.DeliveryAttemptFilter{EventID: e.ID})
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "failed to list delivery attempts")
+			return
+		}
+		results[i] = domain.EventSearchResult{Event: e, Deliveries: attempts}
+	}
+
+	respondJSON(w, http.StatusOK, results)
+}
+
 func (h *EventHandler) Get(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
@@ -115,3 +188,44 @@ func (h *EventHandler) Get(w http.ResponseWriter, r *http.Request) {
 
 	respondJSON(w, http.StatusOK, event)
 }
+
+// FanOut shows which subscribers an event's deliveries were queued for,
+// which failed and are waiting for the repair job, and whether the whole
+// fan-out is still waiting in the outbox.
+func (h *EventHandler) FanOut(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	event, err := h.store.GetEvent(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get event")
+		return
+	}
+	if event == nil {
+		respondError(w, http.StatusNotFound, "event not found")
+		return
+	}
+
+	pending, err := h.store.OutboxEntryPending(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to check fan-out outbox")
+		return
+	}
+	statuses, err := h.store.ListFanOutStatuses(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get fan-out status")
+		return
+	}
+
+	fanOut := domain.EventFanOut{EventID: id, OutboxPending: pending, Subscribers: statuses}
+	for _, st := range statuses {
+		switch st.Status {
+		case domain.FanOutQueued:
+			fanOut.Queued++
+		case domain.FanOutFailed:
+			fanOut.Failed++
+		case domain.FanOutSkipped:
+			fanOut.Skipped++
+		}
+	}
+	respondJSON(w, http.StatusOK, fanOut)
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+// outboxStubStore reports every event's outbox entry as cleared.
+type outboxStubStore struct {
+	*store.MemoryStore
+}
+
+func (outboxStubStore) OutboxEntryPending(ctx context.Context, eventID string) (bool, error) {
+	return false, nil
+}
+
+func (outboxStubStore) ClaimPendingOutbox(ctx context.Context, limit int, lease time.Duration) ([]store.OutboxEntry, error) {
+	return nil, nil
+}
+
+func (outboxStubStore) CompleteOutboxEntry(ctx context.Context, eventID string) error {
+	return nil
+}
+
+func (outboxStubStore) FailOutboxEntry(ctx context.Context, eventID string, errMsg string, retryAt time.Time) error {
+	return nil
+}
+
+func TestEventHandler_FanOut(t *testing.T) {
+	s := outboxStubStore{store.NewMemoryStore()}
+	r := chi.NewRouter()
+	r.Get("/events/{id}/fanout", NewEventHandler(s, nil).FanOut)
+
+	ctx := context.Background()
+	event, _ := s.CreateEvent(ctx, "order.created", 1, []byte(`{}`), nil, "", nil)
+	errMsg := "connection reset"
+	s.SaveFanOutStatuses(ctx, []domain.FanOutStatus{
+		{EventID: event.ID, SubscriberID: "sub-a", Status: domain.FanOutQueued, Attempts: 1},
+		{EventID: event.ID, SubscriberID: "sub-b", Status: domain.FanOutQueued, Attempts: 1},
+		{EventID: event.ID, SubscriberID: "sub-c", Status: domain.FanOutFailed, Error: &errMsg, Attempts: 1},
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events/"+event.ID+"/fanout", nil))
+	var got domain.EventFanOut
+	json.NewDecoder(rec.Body).Decode(&got)
+	if rec.Code != http.StatusOK || got.Queued != 2 || got.Failed != 1 || len(got.Subscribers) != 3 {
+		t.Fatalf("fanout = %d %+v", rec.Code, got)
+	}
+	if got.Subscribers[0].SubscriberID != "sub-c" {
+		t.Errorf("expected the failed subscriber first, got %+v", got.Subscribers)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events/missing/fanout", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("missing event: status = %d, want 404", rec.Code)
+	}
+}
+
+func TestEventHandler_Search(t *testing.T) {
+	s := outboxStubStore{store.NewMemoryStore()}
+	h := NewEventHandler(s, nil)
+
+	ctx := context.Background()
+	event, _ := s.CreateEvent(ctx, "order.paid", 1, []byte(`{"order_id":"abc-123","total":42}`), nil, "", nil)
+	s.CreateEvent(ctx, "order.paid", 1, []byte(`{"order_id":"abc-124"}`), nil, "", nil)
+	s.InsertDeliveryAttempts(ctx, []store.DeliveryAttemptRecord{
+		{EventID: event.ID, SubscriberID: "sub-1", AttemptNumber: 1, Status: "success"},
+	})
+
+	rec := httptest.NewRecorder()
+	h.Search(rec, httptest.NewRequest(http.MethodGet, "/events/search?query=order_id:abc-123", nil))
+	var got []domain.EventSearchResult
+	json.NewDecoder(rec.Body).Decode(&got)
+	if rec.Code != http.StatusOK || len(got) != 1 || got[0].ID != event.ID {
+		t.Fatalf("search = %d %+v, want the abc-123 event", rec.Code, got)
+	}
+	if len(got[0].Deliveries) != 1 || got[0].Deliveries[0].SubscriberID != "sub-1" {
+		t.Errorf("deliveries = %+v, want the event's attempt", got[0].Deliveries)
+	}
+
+	for _, query := range []string{"", "abc-123", "order_id:"} {
+		rec = httptest.NewRecorder()
+		h.Search(rec, httptest.NewRequest(http.MethodGet, "/events/search?query="+query, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("query %q: status = %d, want 400", query, rec.Code)
+		}
+	}
+}
+
+func TestEventHandler_RejectsSystemEventTypes(t *testing.T) {
+	h := NewEventHandler(outboxStubStore{store.NewMemoryStore()}, nil)
+
+	rec := httptest.NewRecorder()
+	h.Create(rec, httptest.NewRequest(http.MethodPost, "/events", strings.NewReader(`{"event_type":"delivery.dead_lettered","payload":{}}`)))
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "reserved") {
+		t.Errorf("status = %d %s, want 400", rec.Code, rec.Body)
+	}
+}
+
+func TestEventHandler_RejectsOversizedPayloads(t *testing.T) {
+	s := outboxStubStore{store.NewMemoryStore()}
+	fanout := engine.NewFanOutEngine(s, engine.NewMemoryQueue(), nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	fanout.SetMaxPayloadBytes(16)
+	h := NewEventHandler(s, fanout)
+
+	rec := httptest.NewRecorder()
+	h.Create(rec, httptest.NewRequest(http.MethodPost, "/events", strings.NewReader(`{"event_type":"order.created","payload":{"order_id":"abc-123"}}`)))
+	if rec.Code != http.StatusRequestEntityTooLarge || !strings.Contains(rec.Body.String(), "payload is 22 bytes, over the limit of 16 bytes") {
+		t.Errorf("status = %d %s, want 413", rec.Code, rec.Body)
+	}
+}
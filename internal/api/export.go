@@ -0,0 +1,135 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// exportPageSize is how many rows an export reads from the store at a time.
+const exportPageSize = 500
+
+// exportWriter streams the rows of an export as NDJSON or CSV.
+type exportWriter struct {
+	rc      *http.ResponseController
+	json    *json.Encoder
+	csv     *csv.Writer
+	started bool
+}
+
+// newExportWriter parses the format query parameter, "ndjson" (the default)
+// or "csv", and prepares w for a download named after name and the current
+// time. Nothing is written until the first row, so errors reading the first
+// page can still be reported with a status code.
+func newExportWriter(w http.ResponseWriter, r *http.Request, name string, header []string) (*exportWriter, error) {
+	format := r.URL.Query().Get("format")
+	filename := fmt.Sprintf("%s-%s", name, time.Now().UTC().Format("20060102T150405Z"))
+
+	e := &exportWriter{rc: http.NewResponseController(w)}
+	switch format {
+	case "", "ndjson":
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		filename += ".ndjson"
+		e.json = json.NewEncoder(w)
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		filename += ".csv"
+		e.csv = csv.NewWriter(w)
+		e.csv.Write(header)
+	default:
+		return nil, fmt.Errorf("format must be ndjson or csv, got %q", format)
+	}
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	return e, nil
+}
+
+// write adds a row: v as a JSON line, or record as a CSV line.
+func (e *exportWriter) write(v any, record []string) error {
+	e.start()
+	if e.json != nil {
+		return e.json.Encode(v)
+	}
+	return e.csv.Write(record)
+}
+
+// flush sends the rows written so far to the client.
+func (e *exportWriter) flush() error {
+	e.start()
+	if e.csv != nil {
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return err
+		}
+	}
+	if err := e.rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	return nil
+}
+
+func (e *exportWriter) start() {
+	if !e.started {
+		// The server's WriteTimeout would otherwise cut a long export off
+		e.rc.SetWriteDeadline(time.Time{})
+		e.started = true
+	}
+}
+
+// fail ends an export that could not be completed. Before any row has been
+// sent it responds with an error; afterwards it aborts the response, so the
+// client sees a broken download instead of a file that looks complete.
+func (e *exportWriter) fail(w http.ResponseWriter, message string) {
+	if !e.started {
+		w.Header().Del("Content-Disposition")
+		respondError(w, http.StatusInternalServerError, message)
+		return
+	}
+	panic(http.ErrAbortHandler)
+}
+
+// parseTimeRange reads an RFC 3339 since/until time range from q. Either
+// bound may be left out.
+func parseTimeRange(q url.Values) (since, until time.Time, err error) {
+	if v := q.Get("since"); v != "" {
+		if since, err = time.Parse(time.RFC3339, v); err != nil {
+			return since, until, errors.New("since must be an RFC 3339 timestamp")
+		}
+	}
+	if v := q.Get("until"); v != "" {
+		if until, err = time.Parse(time.RFC3339, v); err != nil {
+			return since, until, errors.New("until must be an RFC 3339 timestamp")
+		}
+	}
+	if !since.IsZero() && !until.IsZero() && !until.After(since) {
+		return since, until, errors.New("until must be after since")
+	}
+	return since, until, nil
+}
+
+// csvString, csvInt and csvTime render optional fields as CSV cells, with
+// empty cells for nil.
+func csvString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func csvInt(n *int) string {
+	if n == nil {
+		return ""
+	}
+	return strconv.Itoa(*n)
+}
+
+func csvTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
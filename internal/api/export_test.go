@@ -0,0 +1,101 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+)
+
+func TestDeliveryHandler_ExportCSV(t *testing.T) {
+	s := store.NewMemoryStore()
+	ctx := context.Background()
+	total := 2*exportPageSize + 1
+	var recs []store.DeliveryAttemptRecord
+	for i := 0; i < total; i++ {
+		recs = append(recs, store.DeliveryAttemptRecord{
+			ID: fmt.Sprintf("att-%05d", i), EventID: "evt-1", SubscriberID: "sub-1", AttemptNumber: 1, Status: "failed",
+			ErrorMessage: "connection refused, \"twice\"", FailureReason: domain.FailureConnection,
+		})
+	}
+	recs = append(recs, store.DeliveryAttemptRecord{EventID: "evt-1", SubscriberID: "sub-2", AttemptNumber: 1, Status: "success"})
+	if err := s.InsertDeliveryAttempts(ctx, recs); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	NewDeliveryHandler(s).Export(rec, httptest.NewRequest(http.MethodGet, "/deliveries/export?format=csv&subscriber_id=sub-1", nil))
+
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "text/csv; charset=utf-8" ||
+		!strings.HasPrefix(rec.Header().Get("Content-Disposition"), `attachment; filename="deliveries-`) {
+		t.Fatalf("status = %d, headers = %v", rec.Code, rec.Header())
+	}
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("reading CSV: %v", err)
+	}
+	if len(rows) != total+1 || rows[0][0] != "id" {
+		t.Fatalf("got %d rows, want a header and %d attempts", len(rows), total)
+	}
+	for i, row := range rows[1:] {
+		if want := fmt.Sprintf("att-%05d", total-1-i); row[0] != want {
+			t.Fatalf("row %d is %s, want %s (newest first, no repeats)", i, row[0], want)
+		}
+	}
+	if got := rows[1][7]; got != `connection refused, "twice"` {
+		t.Errorf("error_message cell = %q", got)
+	}
+}
+
+func TestDeadLetterHandler_ExportNDJSON(t *testing.T) {
+	s := store.NewMemoryStore()
+	ctx := context.Background()
+	for _, sub := range []string{"sub-1", "sub-1", "sub-2"} {
+		if err := s.InsertDeadLetter(ctx, store.DeadLetterRecord{EventID: "evt-1", SubscriberID: sub, TotalAttempts: 5}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	letters, _ := s.ListDeadLetters(ctx, store.DeadLetterFilter{SubscriberID: "sub-1", Limit: 1})
+	if err := s.ResolveDeadLetter(ctx, letters[0].ID, "manual"); err != nil {
+		t.Fatal(err)
+	}
+	h := NewDeadLetterHandler(s, nil)
+
+	for query, want := range map[string]int{
+		"subscriber_id=sub-1":                2,
+		"subscriber_id=sub-1&resolved=false": 1,
+		"":                                   3,
+	} {
+		rec := httptest.NewRecorder()
+		h.Export(rec, httptest.NewRequest(http.MethodGet, "/dead-letters/export?"+query, nil))
+		if rec.Header().Get("Content-Type") != "application/x-ndjson" {
+			t.Errorf("%s: content type %q", query, rec.Header().Get("Content-Type"))
+		}
+		n := 0
+		for sc := bufio.NewScanner(rec.Body); sc.Scan(); n++ {
+			var dl domain.DeadLetter
+			if err := json.Unmarshal(sc.Bytes(), &dl); err != nil || dl.ID == "" {
+				t.Errorf("%s: line %q: %v", query, sc.Text(), err)
+			}
+		}
+		if n != want {
+			t.Errorf("%s: exported %d dead letters, want %d", query, n, want)
+		}
+	}
+
+	for _, query := range []string{"format=xml", "resolved=maybe", "since=yesterday"} {
+		rec := httptest.NewRecorder()
+		h.Export(rec, httptest.NewRequest(http.MethodGet, "/dead-letters/export?"+query, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", query, rec.Code)
+		}
+	}
+}
@@ -1,26 +1,112 @@
 package api
 
 import (
-	"encoding/json"
+	"context"
 	"net/http"
+	"sync"
+	"time"
 )
 
-// HealthResponse represents the health check response.
-type HealthResponse struct {
-	Status  string `json:"status"`
-	Version string `json:"version"`
+// readinessCheckTimeout bounds each dependency check so a hung dependency
+// fails the probe instead of stalling it.
+const readinessCheckTimeout = 2 * time.Second
+
+// ReadinessCheck is one dependency the instance needs before it can take
+// traffic. Check returns nil when the dependency is usable.
+type ReadinessCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// HealthChecker serves the liveness and readiness probes.
+type HealthChecker struct {
+	checks []ReadinessCheck
+}
+
+// NewHealthChecker creates a checker that runs the given readiness checks.
+func NewHealthChecker(checks ...ReadinessCheck) *HealthChecker {
+	return &HealthChecker{checks: checks}
+}
+
+// LivenessResponse is returned by /healthz.
+type LivenessResponse struct {
+	Status string `json:"status"`
+}
+
+// ReadinessResponse is returned by /readyz.
+type ReadinessResponse struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// CheckResult is the outcome of one readiness check.
+type CheckResult struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Liveness reports that the process is up and serving HTTP. It checks no
+// dependencies, so an outage elsewhere doesn't get healthy pods restarted.
+func (h *HealthChecker) Liveness() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		respondJSON(w, http.StatusOK, LivenessResponse{Status: "ok"})
+	}
 }
 
-// HealthHandler returns the health check handler.
-func HealthHandler() http.HandlerFunc {
+// Readiness runs every check concurrently and returns 503 if any of them
+// fails, so load balancers stop routing to the instance until it recovers.
+func (h *HealthChecker) Readiness() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		resp := HealthResponse{
-			Status:  "healthy",
-			Version: "1.0.0",
+		resp := h.check(r.Context())
+
+		status := http.StatusOK
+		if resp.Status != "ready" {
+			status = http.StatusServiceUnavailable
+		}
+		respondJSON(w, status, resp)
+	}
+}
+
+func (h *HealthChecker) check(ctx context.Context) ReadinessResponse {
+	resp := ReadinessResponse{Status: "ready", Checks: map[string]CheckResult{}}
+	if h == nil {
+		return resp
+	}
+
+	results := make([]CheckResult, len(h.checks))
+	var wg sync.WaitGroup
+	for i, c := range h.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = runCheck(ctx, c)
+		}()
+	}
+	wg.Wait()
+
+	for i, c := range h.checks {
+		resp.Checks[c.Name] = results[i]
+		if results[i].Status != "ok" {
+			resp.Status = "unavailable"
 		}
+	}
+	return resp
+}
+
+func runCheck(ctx context.Context, c ReadinessCheck) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, readinessCheckTimeout)
+	defer cancel()
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(resp)
+	start := time.Now()
+	err := c.Check(ctx)
+	result := CheckResult{
+		Status:    "ok",
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = "error"
+		result.Error = err.Error()
 	}
+	return result
 }
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHealthChecker_Readiness(t *testing.T) {
+	ok := ReadinessCheck{Name: "database", Check: func(ctx context.Context) error { return nil }}
+	down := ReadinessCheck{Name: "redis", Check: func(ctx context.Context) error { return errors.New("connection refused") }}
+
+	tests := []struct {
+		name       string
+		checks     []ReadinessCheck
+		wantCode   int
+		wantStatus string
+	}{
+		{"all passing", []ReadinessCheck{ok}, http.StatusOK, "ready"},
+		{"one failing", []ReadinessCheck{ok, down}, http.StatusServiceUnavailable, "unavailable"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			NewHealthChecker(tt.checks...).Readiness()(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("status code = %d, want %d", rec.Code, tt.wantCode)
+			}
+			var resp ReadinessResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if resp.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", resp.Status, tt.wantStatus)
+			}
+			if len(resp.Checks) != len(tt.checks) {
+				t.Errorf("got %d check results, want %d", len(resp.Checks), len(tt.checks))
+			}
+			if got := resp.Checks["database"]; got.Status != "ok" || got.Error != "" {
+				t.Errorf("database check = %+v, want ok", got)
+			}
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	NewHealthChecker(down).Readiness()(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	var resp ReadinessResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if got := resp.Checks["redis"]; got.Status != "error" || got.Error != "connection refused" {
+		t.Errorf("redis check = %+v, want the connection error", got)
+	}
+}
+
+func TestHealthChecker_LivenessIgnoresDependencies(t *testing.T) {
+	down := ReadinessCheck{Name: "redis", Check: func(ctx context.Context) error { return errors.New("connection refused") }}
+
+	rec := httptest.NewRecorder()
+	NewHealthChecker(down).Liveness()(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("status code = %d, want 200", rec.Code)
+	}
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+)
+
+// IngestLimiter protects the database and queue from a runaway producer. It
+// caps the size of event requests and how many events each API key may
+// publish a second. Requests without a key, which only get this far when
+// authentication is disabled, are limited by client address.
+type IngestLimiter struct {
+	limiter      *engine.RateLimiter
+	maxBodyBytes atomic.Int64
+	perSecond    atomic.Int64
+}
+
+// NewIngestLimiter creates a limiter that counts requests in the Redis
+// sliding window also used for subscriber rate limits. perSecond of 0 leaves
+// ingestion unlimited.
+func NewIngestLimiter(rl *engine.RateLimiter, maxBodyBytes, perSecond int) *IngestLimiter {
+	l := &IngestLimiter{limiter: rl}
+	l.SetLimits(maxBodyBytes, perSecond)
+	return l
+}
+
+// SetLimits changes the limits. It is safe to call while requests are
+// being served.
+func (l *IngestLimiter) SetLimits(maxBodyBytes, perSecond int) {
+	l.maxBodyBytes.Store(int64(maxBodyBytes))
+	l.perSecond.Store(int64(perSecond))
+}
+
+// Limit rejects requests over the rate limit with 429 and a Retry-After
+// header, and requests whose body is larger than the cap with 413. A nil
+// limiter lets every request through.
+func (l *IngestLimiter) Limit(next http.Handler) http.Handler {
+	if l == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if limit := int(l.perSecond.Load()); limit > 0 {
+			if !l.limiter.Allow(r.Context(), "ingest:"+ingestClient(r), limit) {
+				// The window is one second long
+				w.Header().Set("Retry-After", "1")
+				respondError(w, http.StatusTooManyRequests, fmt.Sprintf("ingestion rate limit of %d events per second exceeded", limit))
+				return
+			}
+		}
+
+		maxBytes := l.maxBodyBytes.Load()
+		if r.ContentLength > maxBytes {
+			respondError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body is %d bytes, over the limit of %d bytes", r.ContentLength, maxBytes))
+			return
+		}
+		// Chunked bodies have no length up front; the handler reports a
+		// *http.MaxBytesError once the cap is reached
+		r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ingestClient identifies who a request counts against.
+func ingestClient(r *http.Request) string {
+	if key := APIKeyFromContext(r.Context()); key != nil {
+		if key.ID == "" {
+			return "key:" + key.Name // ADMIN_API_KEY
+		}
+		return "key:" + key.ID
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr // already a bare address when set by RealIP
+	}
+	return "ip:" + host
+}
+
+func payloadTooLarge(limit int64) string {
+	return fmt.Sprintf("request body exceeds the limit of %d bytes", limit)
+}
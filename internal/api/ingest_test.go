@@ -0,0 +1,79 @@
+package api
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+)
+
+func newTestIngestLimiter(t *testing.T, maxBodyBytes, perSecond int) *IngestLimiter {
+	t.Helper()
+	client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { client.Close() })
+	rl := engine.NewRateLimiter(client, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	return NewIngestLimiter(rl, maxBodyBytes, perSecond)
+}
+
+func TestIngestLimiter_RateLimitsEachKey(t *testing.T) {
+	l := newTestIngestLimiter(t, 1024, 2)
+	h := l.Limit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	publish := func(keyID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/events", strings.NewReader(`{}`))
+		req = req.WithContext(context.WithValue(req.Context(), apiKeyContextKey{}, &domain.APIKey{ID: keyID}))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for i := 0; i < 2; i++ {
+		if rec := publish("key-a"); rec.Code != http.StatusCreated {
+			t.Fatalf("request %d: status = %d, want 201", i+1, rec.Code)
+		}
+	}
+	rec := publish("key-a")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "1" {
+		t.Errorf("over the limit: status = %d, Retry-After = %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	if rec := publish("key-b"); rec.Code != http.StatusCreated {
+		t.Errorf("another key: status = %d, want 201", rec.Code)
+	}
+
+	l.SetLimits(1024, 0)
+	if rec := publish("key-a"); rec.Code != http.StatusCreated {
+		t.Errorf("limit removed: status = %d, want 201", rec.Code)
+	}
+}
+
+func TestIngestLimiter_CapsPayloadSize(t *testing.T) {
+	h := newTestIngestLimiter(t, 64, 0).Limit(http.HandlerFunc(NewEventHandler(nil, nil).Create))
+	body := `{"event_type":"order.created","payload":{"note":"` + strings.Repeat("x", 100) + `"}}`
+
+	// With a Content-Length the request is rejected before it is read
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/events", strings.NewReader(body)))
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want 413", rec.Code)
+	}
+
+	// A chunked body is cut off while it is decoded
+	req := httptest.NewRequest(http.MethodPost, "/events", io.MultiReader(strings.NewReader(body)))
+	req.ContentLength = -1
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusRequestEntityTooLarge || !strings.Contains(rec.Body.String(), "64 bytes") {
+		t.Errorf("chunked: status = %d, body %s", rec.Code, rec.Body)
+	}
+}
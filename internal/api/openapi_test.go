@@ -0,0 +1,71 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type openAPIDoc struct {
+	OpenAPI string                                `json:"openapi"`
+	Paths   map[string]map[string]json.RawMessage `json:"paths"`
+}
+
+func TestOpenAPISpec_CoversRoutes(t *testing.T) {
+	var doc openAPIDoc
+	if err := json.Unmarshal(openAPISpec, &doc); err != nil {
+		t.Fatalf("embedded spec is not valid JSON: %v", err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		t.Fatalf("openapi version = %q, want 3.x", doc.OpenAPI)
+	}
+
+	router := NewRouter(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, &Authenticator{enabled: true}, nil, nil, nil, nil, nil, nil)
+	routes, ok := router.(chi.Routes)
+	if !ok {
+		t.Fatal("router does not expose its routes")
+	}
+
+	documented := map[string]bool{}
+	err := chi.Walk(routes, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		path := strings.TrimSuffix(route, "/")
+		ops, ok := doc.Paths[path]
+		if !ok {
+			t.Errorf("route %s %s is not documented in openapi.json", method, path)
+			return nil
+		}
+		if _, ok := ops[strings.ToLower(method)]; !ok {
+			t.Errorf("route %s %s is missing its %s operation in openapi.json", method, path, method)
+		}
+		documented[path] = true
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("walking routes: %v", err)
+	}
+
+	for path := range doc.Paths {
+		if !documented[path] {
+			t.Errorf("openapi.json documents %s but the router does not serve it", path)
+		}
+	}
+}
+
+func TestOpenAPIHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	OpenAPIHandler()(rec, httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+	if !json.Valid(rec.Body.Bytes()) {
+		t.Error("response body is not valid JSON")
+	}
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+// PortalHandler serves the self-service portal: the API subscribers call
+// with a key scoped to them. Every route answers for the key's subscriber
+// only, reusing the management handlers with the subscriber filled in, and
+// records of other subscribers are reported as not found.
+type PortalHandler struct {
+	store       store.Store
+	fanout      *engine.FanOutEngine
+	subscribers *SubscriberHandler
+	deliveries  *DeliveryHandler
+	deadLetters *DeadLetterHandler
+}
+
+func NewPortalHandler(s store.Store, f *engine.FanOutEngine, subscribers *SubscriberHandler, deliveries *DeliveryHandler, deadLetters *DeadLetterHandler) *PortalHandler {
+	return &PortalHandler{
+		store:       s,
+		fanout:      f,
+		subscribers: subscribers,
+		deliveries:  deliveries,
+		deadLetters: deadLetters,
+	}
+}
+
+// portalSubscriberID returns the subscriber the request's key is scoped to.
+// Routes using it sit behind Authenticator.RequireSubscriberScope.
+func portalSubscriberID(r *http.Request) string {
+	return *APIKeyFromContext(r.Context()).SubscriberID
+}
+
+// asSubscriber points the request's id URL parameter at the key's
+// subscriber, for the management handlers of a single subscriber.
+func asSubscriber(r *http.Request) *http.Request {
+	chi.RouteContext(r.Context()).URLParams.Add("id", portalSubscriberID(r))
+	return r
+}
+
+// filteredToSubscriber replaces any subscriber_id query parameter with the
+// key's subscriber, for the management list handlers.
+func filteredToSubscriber(r *http.Request) *http.Request {
+	q := r.URL.Query()
+	q.Set("subscriber_id", portalSubscriberID(r))
+	r2 := r.Clone(r.Context())
+	r2.URL.RawQuery = q.Encode()
+	return r2
+}
+
+// Subscriber returns the key's subscriber and its subscriptions.
+func (h *PortalHandler) Subscriber(w http.ResponseWriter, r *http.Request) {
+	h.subscribers.Get(w, asSubscriber(r))
+}
+
+// Stats returns the key's subscriber's delivery statistics.
+func (h *PortalHandler) Stats(w http.ResponseWriter, r *http.Request) {
+	h.subscribers.Stats(w, asSubscriber(r))
+}
+
+// ListDeliveries lists the key's subscriber's delivery attempts, with the
+// filters of the management endpoint.
+func (h *PortalHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	h.deliveries.List(w, filteredToSubscriber(r))
+}
+
+func (h *PortalHandler) GetDelivery(w http.ResponseWriter, r *http.Request) {
+	attempt, err := h.store.GetDeliveryAttempt(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get delivery attempt")
+		return
+	}
+	if attempt == nil || attempt.SubscriberID != portalSubscriberID(r) {
+		respondError(w, http.StatusNotFound, "delivery attempt not found")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, attempt)
+}
+
+// ListDeadLetters lists the key's subscriber's dead letters, with the
+// filters of the management endpoint.
+func (h *PortalHandler) ListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	h.deadLetters.List(w, filteredToSubscriber(r))
+}
+
+func (h *PortalHandler) GetDeadLetter(w http.ResponseWriter, r *http.Request) {
+	letter, err := h.store.GetDeadLetter(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get dead letter")
+		return
+	}
+	if letter == nil || letter.SubscriberID != portalSubscriberID(r) {
+		respondError(w, http.StatusNotFound, "dead letter not found")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, letter)
+}
+
+// Ping queues a test event for the key's subscriber, so its team can check
+// their endpoint without waiting for real traffic.
+func (h *PortalHandler) Ping(w http.ResponseWriter, r *http.Request) {
+	id := portalSubscriberID(r)
+
+	sub, err := h.store.GetSubscriber(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get subscriber")
+		return
+	}
+	if sub == nil || sub.Deleted() {
+		respondError(w, http.StatusNotFound, "subscriber not found")
+		return
+	}
+	if !sub.IsActive {
+		respondError(w, http.StatusConflict, "subscriber is inactive")
+		return
+	}
+
+	event, err := h.fanout.Ping(r.Context(), sub, "portal")
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to queue ping")
+		return
+	}
+
+	recordAudit(r, h.store, domain.AuditSubscriberPing, domain.AuditEntitySubscriber, id, map[string]string{
+		"event_id": event.ID,
+	})
+
+	respondJSON(w, http.StatusAccepted, map[string]string{
+		"status":   "queued",
+		"event_id": event.ID,
+	})
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+func TestPortalHandler_OnlyShowsTheKeysSubscriber(t *testing.T) {
+	s := outboxStubStore{store.NewMemoryStore()}
+	queue := engine.NewMemoryQueue()
+	fanout := engine.NewFanOutEngine(s, queue, nil, slog.Default())
+	ctx := context.Background()
+
+	var subs []*domain.Subscriber
+	for _, name := range []string{"orders", "billing"} {
+		sub, err := s.CreateSubscriber(ctx, domain.CreateSubscriberRequest{
+			Name: name, EndpointURL: "https://example.com/" + name, EventTypes: []string{"order.created"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		subs = append(subs, sub)
+		if err := s.InsertDeliveryAttempts(ctx, []store.DeliveryAttemptRecord{
+			{EventID: "evt-1", SubscriberID: sub.ID, AttemptNumber: 1, Status: "failed"},
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.InsertDeadLetter(ctx, store.DeadLetterRecord{EventID: "evt-1", SubscriberID: sub.ID, TotalAttempts: 5}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	orders, billing := subs[0], subs[1]
+	_, token, err := s.CreateAPIKey(ctx, "orders team", domain.RoleViewer, &orders.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, unscoped, _ := s.CreateAPIKey(ctx, "dashboard", domain.RoleAdmin, nil)
+
+	// Authentication is off, as in development, but the portal still needs
+	// a key to know whose data to show
+	auth := NewAuthenticator(s, false, "")
+	subHandler := NewSubscriberHandler(s, nil, nil, nil, nil, nil)
+	h := NewPortalHandler(s, fanout, subHandler, NewDeliveryHandler(s), NewDeadLetterHandler(s, fanout))
+	r := chi.NewRouter()
+	r.Route("/portal", func(r chi.Router) {
+		r.Use(auth.RequireSubscriberScope)
+		r.Get("/subscriber", h.Subscriber)
+		r.Get("/stats", h.Stats)
+		r.Get("/deliveries", h.ListDeliveries)
+		r.Get("/deliveries/{id}", h.GetDelivery)
+		r.Get("/dead-letters", h.ListDeadLetters)
+		r.Get("/dead-letters/{id}", h.GetDeadLetter)
+		r.Post("/ping", h.Ping)
+	})
+	get := func(method, path, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := get(http.MethodGet, "/portal/subscriber", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("without a key: status = %d, want 401", rec.Code)
+	}
+	if rec := get(http.MethodGet, "/portal/subscriber", unscoped); rec.Code != http.StatusForbidden {
+		t.Errorf("unscoped key: status = %d, want 403", rec.Code)
+	}
+
+	rec := get(http.MethodGet, "/portal/subscriber", token)
+	var detail domain.Subscriber
+	json.NewDecoder(rec.Body).Decode(&detail)
+	if rec.Code != http.StatusOK || detail.ID != orders.ID || detail.SecretKey != "" {
+		t.Errorf("subscriber = %d %+v, want orders without its secret", rec.Code, detail)
+	}
+	if rec := get(http.MethodGet, "/portal/stats?window=1h", token); rec.Code != http.StatusOK {
+		t.Errorf("stats status = %d: %s", rec.Code, rec.Body)
+	}
+
+	var attempts []domain.DeliveryAttempt
+	rec = get(http.MethodGet, "/portal/deliveries?subscriber_id="+billing.ID, token)
+	json.NewDecoder(rec.Body).Decode(&attempts)
+	if len(attempts) != 1 || attempts[0].SubscriberID != orders.ID {
+		t.Fatalf("deliveries = %+v, want only the orders attempt", attempts)
+	}
+	if rec := get(http.MethodGet, "/portal/deliveries/"+attempts[0].ID, token); rec.Code != http.StatusOK {
+		t.Errorf("own attempt: status = %d", rec.Code)
+	}
+	other, _ := s.ListDeliveryAttempts(ctx, store.DeliveryAttemptFilter{SubscriberID: billing.ID, Limit: 1})
+	if rec := get(http.MethodGet, "/portal/deliveries/"+other[0].ID, token); rec.Code != http.StatusNotFound {
+		t.Errorf("another subscriber's attempt: status = %d, want 404", rec.Code)
+	}
+
+	var letters []domain.DeadLetter
+	rec = get(http.MethodGet, "/portal/dead-letters?subscriber_id="+billing.ID, token)
+	json.NewDecoder(rec.Body).Decode(&letters)
+	if len(letters) != 1 || letters[0].SubscriberID != orders.ID {
+		t.Fatalf("dead letters = %+v, want only the orders dead letter", letters)
+	}
+	otherLetters, _ := s.ListDeadLetters(ctx, store.DeadLetterFilter{SubscriberID: billing.ID, Limit: 1})
+	if rec := get(http.MethodGet, "/portal/dead-letters/"+otherLetters[0].ID, token); rec.Code != http.StatusNotFound {
+		t.Errorf("another subscriber's dead letter: status = %d, want 404", rec.Code)
+	}
+
+	rec = get(http.MethodPost, "/portal/ping", token)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("ping status = %d: %s", rec.Code, rec.Body)
+	}
+	pending, _ := queue.PendingJobs(ctx, orders.ID)
+	if len(pending) != 1 || pending[0].EventType != domain.PingEventType {
+		t.Errorf("pending jobs = %+v, want one ping", pending)
+	}
+	entries, _ := s.ListAuditEntries(ctx, domain.AuditFilter{Action: domain.AuditSubscriberPing})
+	if len(entries) != 1 || entries[0].Actor != "orders team" || !strings.Contains(string(entries[0].Details), "event_id") {
+		t.Errorf("audit entries = %+v", entries)
+	}
+}
+
+func TestAPIKeyHandler_ScopedKeys(t *testing.T) {
+	s := store.NewMemoryStore()
+	ctx := context.Background()
+	sub, err := s.CreateSubscriber(ctx, domain.CreateSubscriberRequest{
+		Name: "orders", EndpointURL: "https://example.com/hook", EventTypes: []string{"order.created"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewAPIKeyHandler(s)
+	r := chi.NewRouter()
+	r.Post("/api-keys", h.Create)
+	r.Put("/api-keys/{id}/role", h.SetRole)
+
+	for body, want := range map[string]int{
+		`{"name":"orders team","subscriber_id":"missing"}`:                       http.StatusBadRequest,
+		`{"name":"orders team","subscriber_id":"` + sub.ID + `","role":"admin"}`: http.StatusBadRequest,
+	} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api-keys", strings.NewReader(body)))
+		if rec.Code != want {
+			t.Errorf("%s: status = %d, want %d", body, rec.Code, want)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api-keys", strings.NewReader(`{"name":"orders team","subscriber_id":"`+sub.ID+`"}`)))
+	var created domain.CreateAPIKeyResponse
+	json.NewDecoder(rec.Body).Decode(&created)
+	if rec.Code != http.StatusCreated || created.Role != domain.RoleViewer || created.SubscriberID == nil || *created.SubscriberID != sub.ID {
+		t.Fatalf("create = %d %+v", rec.Code, created)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/api-keys/"+created.ID+"/role", strings.NewReader(`{"role":"admin"}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("promoting a scoped key: status = %d, want 400", rec.Code)
+	}
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+)
+
+// QueueHandler inspects the delivery queue and purges jobs from it.
+type QueueHandler struct {
+	store  store.Store
+	fanout *engine.FanOutEngine
+}
+
+func NewQueueHandler(s store.Store, f *engine.FanOutEngine) *QueueHandler {
+	return &QueueHandler{store: s, fanout: f}
+}
+
+// Stats reports queue depth, ready and scheduled counts, the age of the
+// oldest due job, and the subscribers with the most waiting jobs. limit
+// (default 20) caps how many subscribers are listed.
+func (h *QueueHandler) Stats(w http.ResponseWriter, r *http.Request) {
+	limit := 20
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if n, err := strconv.Atoi(limitStr); err == nil && n > 0 {
+			limit = n
+		}
+	}
+
+	stats, err := h.fanout.InspectQueue(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to inspect queue")
+		return
+	}
+	if len(stats.Subscribers) > limit {
+		stats.Subscribers = stats.Subscribers[:limit]
+	}
+	respondJSON(w, http.StatusOK, stats)
+}
+
+type purgeJobsResponse struct {
+	JobsPurged int64 `json:"jobs_purged"`
+}
+
+// Purge removes the queued jobs matching the subscriber_id, event_type,
+// state and min_attempt filters. Purging the whole queue takes an explicit
+// all=true, so a request with a mistyped filter can't empty it.
+func (h *QueueHandler) Purge(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := engine.JobFilter{
+		SubscriberID: q.Get("subscriber_id"),
+		EventType:    q.Get("event_type"),
+		State:        q.Get("state"),
+	}
+	if v := q.Get("min_attempt"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "min_attempt must be an integer")
+			return
+		}
+		filter.MinAttempt = n
+	}
+	if err := filter.Validate(); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if filter.IsEmpty() && q.Get("all") != "true" {
+		respondError(w, http.StatusBadRequest, "pass at least one filter, or all=true to purge every queued job")
+		return
+	}
+
+	purged, err := h.fanout.PurgeJobs(r.Context(), filter)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to purge jobs")
+		return
+	}
+
+	recordAudit(r, h.store, domain.AuditQueuePurge, domain.AuditEntityQueue, engine.DeliveryQueueKey, map[string]interface{}{
+		"filter":      q,
+		"jobs_purged": purged,
+	})
+
+	respondJSON(w, http.StatusOK, purgeJobsResponse{JobsPurged: purged})
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+)
+
+func TestQueueHandler_StatsAndPurge(t *testing.T) {
+	s := store.NewMemoryStore()
+	queue := engine.NewMemoryQueue()
+	h := NewQueueHandler(s, engine.NewFanOutEngine(nil, queue, nil, slog.Default()))
+
+	ctx := context.Background()
+	for _, sub := range []string{"a", "a", "b"} {
+		queue.Enqueue(ctx, engine.DeliveryJob{SubscriberID: sub, EventType: "order.created"}, time.Now())
+	}
+
+	rec := httptest.NewRecorder()
+	h.Stats(rec, httptest.NewRequest(http.MethodGet, "/queue?limit=1", nil))
+	var stats engine.QueueStats
+	json.NewDecoder(rec.Body).Decode(&stats)
+	if rec.Code != http.StatusOK || stats.Depth != 3 || len(stats.Subscribers) != 1 || stats.Subscribers[0].SubscriberID != "a" {
+		t.Errorf("stats = %d %+v, want depth 3 with only the busiest subscriber listed", rec.Code, stats)
+	}
+
+	for _, query := range []string{"", "?state=running", "?min_attempt=x", "?event_type=order.*x"} {
+		rec = httptest.NewRecorder()
+		h.Purge(rec, httptest.NewRequest(http.MethodDelete, "/queue/jobs"+query, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("purge%s: status = %d, want 400", query, rec.Code)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	h.Purge(rec, httptest.NewRequest(http.MethodDelete, "/queue/jobs?subscriber_id=a", nil))
+	var purged purgeJobsResponse
+	json.NewDecoder(rec.Body).Decode(&purged)
+	if rec.Code != http.StatusOK || purged.JobsPurged != 2 {
+		t.Errorf("purge = %d %+v, want 2 jobs purged", rec.Code, purged)
+	}
+
+	rec = httptest.NewRecorder()
+	h.Purge(rec, httptest.NewRequest(http.MethodDelete, "/queue/jobs?all=true", nil))
+	if depth, _ := queue.Depth(ctx); rec.Code != http.StatusOK || depth != 0 {
+		t.Errorf("purge all: status = %d, depth = %d", rec.Code, depth)
+	}
+
+	entries, _ := s.ListAuditEntries(ctx, domain.AuditFilter{Action: domain.AuditQueuePurge})
+	if len(entries) != 2 {
+		t.Errorf("audit entries = %+v, want one per purge", entries)
+	}
+}
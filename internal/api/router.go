@@ -4,15 +4,19 @@ import (
 	"io/fs"
 	"net/http"
 
+	"github.com/Priya8975/webhook-delivery-system/internal/archive"
+	"github.com/Priya8975/webhook-delivery-system/internal/chaos"
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
 	"github.com/Priya8975/webhook-delivery-system/internal/engine"
 	"github.com/Priya8975/webhook-delivery-system/internal/store"
 	ws "github.com/Priya8975/webhook-delivery-system/internal/websocket"
+	"github.com/Priya8975/webhook-delivery-system/internal/worker"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 )
 
 // NewRouter creates and configures the HTTP router.
-func NewRouter(pgStore *store.PostgresStore, fanout *engine.FanOutEngine, cb *engine.CircuitBreaker, hub *ws.Hub, dashboardFS fs.FS) http.Handler {
+func NewRouter(db store.Database, fanout *engine.FanOutEngine, cb *engine.CircuitBreaker, quota *engine.DeliveryQuota, cleanup *engine.SubscriberCleanup, verifier EndpointVerifier, hub *ws.Hub, pool *worker.Pool, dispatcher *worker.Dispatcher, health *HealthChecker, auth *Authenticator, ingest *IngestLimiter, reloader ConfigReloader, chaosInjector *chaos.Injector, archiveS3 *archive.S3Client, egressIPs []string, dashboardFS fs.FS) http.Handler {
 	r := chi.NewRouter()
 
 	// Middleware stack
@@ -26,46 +30,153 @@ func NewRouter(pgStore *store.PostgresStore, fanout *engine.FanOutEngine, cb *en
 	r.Use(corsMiddleware)
 
 	// Handlers
-	subHandler := NewSubscriberHandler(pgStore, cb)
-	eventHandler := NewEventHandler(pgStore, fanout)
-	deliveryHandler := NewDeliveryHandler(pgStore)
-	dlqHandler := NewDeadLetterHandler(pgStore)
-	dashHandler := NewDashboardHandler(pgStore, fanout, cb, hub)
+	subHandler := NewSubscriberHandler(db, cb, quota, fanout, cleanup, verifier)
+	subQueueHandler := NewSubscriberQueueHandler(db, fanout)
+	queueHandler := NewQueueHandler(db, fanout)
+	eventHandler := NewEventHandler(db, fanout)
+	eventTypeHandler := NewEventTypeHandler(db)
+	deliveryHandler := NewDeliveryHandler(db)
+	dlqHandler := NewDeadLetterHandler(db, fanout)
+	dashHandler := NewDashboardHandler(db, fanout, cb, hub, pool, dispatcher)
+	archiveHandler := NewArchiveHandler(db, archiveS3)
+	usageHandler := NewUsageHandler(db)
+	apiKeyHandler := NewAPIKeyHandler(db)
+	auditHandler := NewAuditHandler(db)
+	adminHandler := NewAdminHandler(db, reloader, chaosInjector)
+	debugHandler := NewDebugHandler(db, pool, dispatcher)
+	portalHandler := NewPortalHandler(db, fanout, subHandler, deliveryHandler, dlqHandler)
+
+	// Role required by each route when authentication is enabled. Viewers
+	// can read, operators can also publish events and act on dead letters,
+	// and admins can also manage subscribers and API keys.
+	viewer := auth.RequireRole(domain.RoleViewer)
+	operator := auth.RequireRole(domain.RoleOperator)
+	admin := auth.RequireRole(domain.RoleAdmin)
+
+	// Kubernetes probes: liveness checks only the process, readiness checks
+	// the dependencies needed to serve traffic
+	r.Get("/healthz", health.Liveness())
+	r.Get("/readyz", health.Readiness())
 
 	// WebSocket endpoint
-	r.Get("/ws", hub.HandleWebSocket)
+	r.With(viewer).Get("/ws", hub.HandleWebSocket)
+
+	// Profiling and runtime diagnostics of this instance. Profiles expose
+	// heap contents and stack traces, so without authentication there is no
+	// one to restrict them to and they aren't served at all
+	if auth.Enabled() {
+		r.Route("/debug", func(r chi.Router) {
+			r.Use(admin)
+			r.Get("/runtime", debugHandler.Runtime)
+			r.Get("/pprof/", debugHandler.Profile)
+			r.Get("/pprof/{profile}", debugHandler.Profile)
+			r.Post("/pprof/{profile}", debugHandler.Profile)
+		})
+	}
 
 	// API routes
 	r.Route("/api/v1", func(r chi.Router) {
-		r.Get("/health", HealthHandler())
+		r.Use(auth.Identify)
+
+		r.Get("/openapi.json", OpenAPIHandler())
+		r.Get("/docs", SwaggerUIHandler())
+		r.Get("/egress-info", EgressInfoHandler(egressIPs))
 
 		r.Route("/subscribers", func(r chi.Router) {
-			r.Post("/", subHandler.Create)
-			r.Get("/", subHandler.List)
-			r.Get("/{id}", subHandler.Get)
-			r.Patch("/{id}", subHandler.Update)
-			r.Get("/{id}/health", subHandler.Health)
+			r.With(admin).Post("/", subHandler.Create)
+			r.With(viewer).Get("/", subHandler.List)
+			r.With(viewer).Get("/export", subHandler.Export)
+			r.With(admin).Post("/import", subHandler.Import)
+			r.With(viewer).Get("/{id}", subHandler.Get)
+			r.With(admin).Patch("/{id}", subHandler.Update)
+			r.With(admin).Delete("/{id}", subHandler.Delete)
+			r.With(admin).Post("/{id}/restore", subHandler.Restore)
+			r.With(viewer).Get("/{id}/health", subHandler.Health)
+			r.With(viewer).Get("/{id}/stats", subHandler.Stats)
+			r.With(viewer).Get("/{id}/pause", subQueueHandler.Status)
+			r.With(operator).Post("/{id}/pause", subQueueHandler.Pause)
+			r.With(operator).Post("/{id}/resume", subQueueHandler.Resume)
+			r.With(viewer).Get("/{id}/pending", subQueueHandler.Pending)
+			r.With(admin).Delete("/{id}/pending", subQueueHandler.Purge)
 		})
 
 		r.Route("/events", func(r chi.Router) {
-			r.Post("/", eventHandler.Create)
-			r.Get("/", eventHandler.List)
-			r.Get("/{id}", eventHandler.Get)
+			r.With(operator, ingest.Limit).Post("/", eventHandler.Create)
+			r.With(viewer).Get("/", eventHandler.List)
+			r.With(viewer).Get("/search", eventHandler.Search)
+			r.With(viewer).Get("/{id}", eventHandler.Get)
+			r.With(viewer).Get("/{id}/fanout", eventHandler.FanOut)
+		})
+
+		r.Route("/event-types", func(r chi.Router) {
+			r.With(viewer).Get("/", eventTypeHandler.List)
+			r.With(admin).Put("/{name}", eventTypeHandler.Describe)
 		})
 
 		r.Route("/deliveries", func(r chi.Router) {
+			r.Use(viewer)
 			r.Get("/", deliveryHandler.List)
+			r.Get("/export", deliveryHandler.Export)
 			r.Get("/{id}", deliveryHandler.Get)
 		})
 
 		r.Route("/dead-letters", func(r chi.Router) {
-			r.Get("/", dlqHandler.List)
-			r.Get("/{id}", dlqHandler.Get)
-			r.Post("/{id}/resolve", dlqHandler.Resolve)
+			r.With(viewer).Get("/", dlqHandler.List)
+			r.With(viewer).Get("/export", dlqHandler.Export)
+			r.With(viewer).Get("/summary", dlqHandler.Summary)
+			r.With(viewer).Get("/{id}", dlqHandler.Get)
+			r.With(operator).Post("/{id}/resolve", dlqHandler.Resolve)
+			r.With(operator).Post("/{id}/replay", dlqHandler.Replay)
+		})
+
+		r.Route("/archives", func(r chi.Router) {
+			r.Use(viewer)
+			r.Get("/", archiveHandler.List)
+			r.Get("/{id}", archiveHandler.Get)
+			r.Get("/{id}/download", archiveHandler.Download)
 		})
 
-		r.Get("/metrics", dashHandler.Metrics)
-		r.Get("/subscribers-health", dashHandler.SubscriberHealth)
+		r.With(viewer).Get("/usage", usageHandler.List)
+
+		r.Route("/queue", func(r chi.Router) {
+			r.With(viewer).Get("/", queueHandler.Stats)
+			r.With(admin).Delete("/jobs", queueHandler.Purge)
+		})
+
+		r.With(admin).Get("/audit-log", auditHandler.List)
+		r.With(admin).Post("/admin/reload", adminHandler.Reload)
+		r.With(admin).Get("/admin/chaos", adminHandler.Chaos)
+		r.With(admin).Put("/admin/chaos", adminHandler.SetChaos)
+		r.With(admin).Delete("/admin/chaos", adminHandler.ClearChaos)
+
+		r.Route("/api-keys", func(r chi.Router) {
+			r.Use(admin)
+			r.Post("/", apiKeyHandler.Create)
+			r.Get("/", apiKeyHandler.List)
+			r.Put("/{id}/role", apiKeyHandler.SetRole)
+			r.Delete("/{id}", apiKeyHandler.Revoke)
+		})
+
+		// Self-service portal for subscribers, with keys scoped to them
+		r.Route("/portal", func(r chi.Router) {
+			r.Use(auth.RequireSubscriberScope)
+			r.Get("/subscriber", portalHandler.Subscriber)
+			r.Get("/stats", portalHandler.Stats)
+			r.Get("/deliveries", portalHandler.ListDeliveries)
+			r.Get("/deliveries/{id}", portalHandler.GetDelivery)
+			r.Get("/dead-letters", portalHandler.ListDeadLetters)
+			r.Get("/dead-letters/{id}", portalHandler.GetDeadLetter)
+			r.Post("/ping", portalHandler.Ping)
+		})
+
+		// Dashboard endpoints
+		r.Group(func(r chi.Router) {
+			r.Use(viewer)
+			r.Get("/stream", hub.HandleSSE)
+			r.Get("/metrics", dashHandler.Metrics)
+			r.Get("/metrics/timeseries", dashHandler.Timeseries)
+			r.Get("/subscribers-health", dashHandler.SubscriberHealth)
+		})
 	})
 
 	// Serve dashboard static files
@@ -81,8 +192,9 @@ func NewRouter(pgStore *store.PostgresStore, fanout *engine.FanOutEngine, cb *en
 func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PATCH, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key, If-Match")
+		w.Header().Set("Access-Control-Expose-Headers", "ETag")
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
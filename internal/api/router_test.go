@@ -0,0 +1,180 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+// subscriberScope marks the portal routes in routePolicies, which take
+// only keys scoped to a subscriber.
+const subscriberScope domain.Role = "subscriber scope"
+
+// routePolicies lists the role each route requires when authentication is
+// enabled. An empty role means the route is public.
+var routePolicies = []struct {
+	method string
+	path   string
+	role   domain.Role
+}{
+	{"GET", "/ws", domain.RoleViewer},
+	{"GET", "/healthz", ""},
+	{"GET", "/readyz", ""},
+	{"GET", "/api/v1/openapi.json", ""},
+	{"GET", "/api/v1/docs", ""},
+	{"GET", "/api/v1/egress-info", ""},
+
+	{"GET", "/debug/runtime", domain.RoleAdmin},
+	{"GET", "/debug/pprof", domain.RoleAdmin},
+	{"GET", "/debug/pprof/{profile}", domain.RoleAdmin},
+	{"POST", "/debug/pprof/{profile}", domain.RoleAdmin},
+
+	{"POST", "/api/v1/subscribers", domain.RoleAdmin},
+	{"GET", "/api/v1/subscribers", domain.RoleViewer},
+	{"GET", "/api/v1/subscribers/export", domain.RoleViewer},
+	{"POST", "/api/v1/subscribers/import", domain.RoleAdmin},
+	{"GET", "/api/v1/subscribers/{id}", domain.RoleViewer},
+	{"PATCH", "/api/v1/subscribers/{id}", domain.RoleAdmin},
+	{"DELETE", "/api/v1/subscribers/{id}", domain.RoleAdmin},
+	{"POST", "/api/v1/subscribers/{id}/restore", domain.RoleAdmin},
+	{"GET", "/api/v1/subscribers/{id}/health", domain.RoleViewer},
+	{"GET", "/api/v1/subscribers/{id}/stats", domain.RoleViewer},
+	{"GET", "/api/v1/subscribers/{id}/pause", domain.RoleViewer},
+	{"POST", "/api/v1/subscribers/{id}/pause", domain.RoleOperator},
+	{"POST", "/api/v1/subscribers/{id}/resume", domain.RoleOperator},
+	{"GET", "/api/v1/subscribers/{id}/pending", domain.RoleViewer},
+	{"DELETE", "/api/v1/subscribers/{id}/pending", domain.RoleAdmin},
+
+	{"POST", "/api/v1/events", domain.RoleOperator},
+	{"GET", "/api/v1/events", domain.RoleViewer},
+	{"GET", "/api/v1/events/search", domain.RoleViewer},
+	{"GET", "/api/v1/events/{id}", domain.RoleViewer},
+	{"GET", "/api/v1/events/{id}/fanout", domain.RoleViewer},
+	{"GET", "/api/v1/event-types", domain.RoleViewer},
+	{"PUT", "/api/v1/event-types/{name}", domain.RoleAdmin},
+
+	{"GET", "/api/v1/deliveries", domain.RoleViewer},
+	{"GET", "/api/v1/deliveries/export", domain.RoleViewer},
+	{"GET", "/api/v1/deliveries/{id}", domain.RoleViewer},
+
+	{"GET", "/api/v1/dead-letters", domain.RoleViewer},
+	{"GET", "/api/v1/dead-letters/export", domain.RoleViewer},
+	{"GET", "/api/v1/dead-letters/summary", domain.RoleViewer},
+	{"GET", "/api/v1/dead-letters/{id}", domain.RoleViewer},
+	{"POST", "/api/v1/dead-letters/{id}/resolve", domain.RoleOperator},
+	{"POST", "/api/v1/dead-letters/{id}/replay", domain.RoleOperator},
+
+	{"GET", "/api/v1/archives", domain.RoleViewer},
+	{"GET", "/api/v1/archives/{id}", domain.RoleViewer},
+	{"GET", "/api/v1/archives/{id}/download", domain.RoleViewer},
+	{"GET", "/api/v1/usage", domain.RoleViewer},
+
+	{"GET", "/api/v1/queue", domain.RoleViewer},
+	{"DELETE", "/api/v1/queue/jobs", domain.RoleAdmin},
+	{"GET", "/api/v1/audit-log", domain.RoleAdmin},
+	{"POST", "/api/v1/admin/reload", domain.RoleAdmin},
+	{"GET", "/api/v1/admin/chaos", domain.RoleAdmin},
+	{"PUT", "/api/v1/admin/chaos", domain.RoleAdmin},
+	{"DELETE", "/api/v1/admin/chaos", domain.RoleAdmin},
+
+	{"POST", "/api/v1/api-keys", domain.RoleAdmin},
+	{"GET", "/api/v1/api-keys", domain.RoleAdmin},
+	{"PUT", "/api/v1/api-keys/{id}/role", domain.RoleAdmin},
+	{"DELETE", "/api/v1/api-keys/{id}", domain.RoleAdmin},
+
+	{"GET", "/api/v1/portal/subscriber", subscriberScope},
+	{"GET", "/api/v1/portal/stats", subscriberScope},
+	{"GET", "/api/v1/portal/deliveries", subscriberScope},
+	{"GET", "/api/v1/portal/deliveries/{id}", subscriberScope},
+	{"GET", "/api/v1/portal/dead-letters", subscriberScope},
+	{"GET", "/api/v1/portal/dead-letters/{id}", subscriberScope},
+	{"POST", "/api/v1/portal/ping", subscriberScope},
+
+	{"GET", "/api/v1/stream", domain.RoleViewer},
+	{"GET", "/api/v1/metrics", domain.RoleViewer},
+	{"GET", "/api/v1/metrics/timeseries", domain.RoleViewer},
+	{"GET", "/api/v1/subscribers-health", domain.RoleViewer},
+}
+
+var scopedSubscriber = "00000000-0000-0000-0000-000000000001"
+
+func newPolicyTestRouter(t *testing.T) http.Handler {
+	t.Helper()
+	auth, _ := newTestAuthenticator(map[string]*domain.APIKey{
+		store.HashAPIKey("whk_viewer"):   {ID: "k1", Name: "viewer", Role: domain.RoleViewer},
+		store.HashAPIKey("whk_operator"): {ID: "k2", Name: "operator", Role: domain.RoleOperator},
+		store.HashAPIKey("whk_admin"):    {ID: "k3", Name: "admin", Role: domain.RoleAdmin},
+		store.HashAPIKey("whk_scoped"):   {ID: "k4", Name: "orders portal", Role: domain.RoleViewer, SubscriberID: &scopedSubscriber},
+	})
+	return NewRouter(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, auth, nil, nil, nil, nil, nil, nil)
+}
+
+func TestRouter_EveryRouteHasAPolicy(t *testing.T) {
+	listed := map[string]bool{}
+	for _, p := range routePolicies {
+		listed[p.method+" "+p.path] = true
+	}
+
+	router := newPolicyTestRouter(t).(chi.Routes)
+	chi.Walk(router, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		route = strings.TrimSuffix(route, "/")
+		if route == "" || route == "/*" {
+			return nil
+		}
+		if !listed[method+" "+route] {
+			t.Errorf("%s %s has no entry in routePolicies", method, route)
+		}
+		return nil
+	})
+}
+
+func TestRouter_RolePolicies(t *testing.T) {
+	router := newPolicyTestRouter(t)
+	roles := []domain.Role{domain.RoleViewer, domain.RoleOperator, domain.RoleAdmin}
+
+	for _, p := range routePolicies {
+		path := strings.ReplaceAll(p.path, "{id}", "00000000-0000-0000-0000-000000000000")
+
+		t.Run(p.method+" "+p.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(p.method, path, strings.NewReader("{}")))
+			if p.role == "" {
+				if rec.Code == http.StatusUnauthorized || rec.Code == http.StatusForbidden {
+					t.Errorf("public route returned %d without a key", rec.Code)
+				}
+				return
+			}
+			if rec.Code != http.StatusUnauthorized {
+				t.Errorf("without a key: status = %d, want 401", rec.Code)
+			}
+
+			for _, role := range roles {
+				req := httptest.NewRequest(p.method, path, strings.NewReader("{}"))
+				req.Header.Set("Authorization", "Bearer whk_"+string(role))
+				rec := httptest.NewRecorder()
+				router.ServeHTTP(rec, req)
+
+				// Allowed requests reach handlers with no backing stores, so
+				// any status other than 401/403 means the policy let them in
+				allowed := rec.Code != http.StatusUnauthorized && rec.Code != http.StatusForbidden
+				if want := p.role != subscriberScope && role.Includes(p.role); allowed != want {
+					t.Errorf("%s key: status = %d, allowed = %v, want %v", role, rec.Code, allowed, want)
+				}
+			}
+
+			req := httptest.NewRequest(p.method, path, strings.NewReader("{}"))
+			req.Header.Set("Authorization", "Bearer whk_scoped")
+			rec = httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			allowed := rec.Code != http.StatusUnauthorized && rec.Code != http.StatusForbidden
+			if want := p.role == subscriberScope; allowed != want {
+				t.Errorf("scoped key: status = %d, allowed = %v, want %v", rec.Code, allowed, want)
+			}
+		})
+	}
+}
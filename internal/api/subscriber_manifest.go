@@ -0,0 +1,565 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+)
+
+// Limits on a subscriber import.
+const (
+	maxImportBytes       = 10 << 20
+	maxImportSubscribers = 1000
+)
+
+// subscriberManifest is one subscriber of an import or export: its settings
+// and the event types it subscribes to, without IDs, so it can be recreated
+// in another environment.
+type subscriberManifest struct {
+	Name        string   `json:"name"`
+	EndpointURL string   `json:"endpoint_url"`
+	EventTypes  []string `json:"event_types"`
+	// IsActive defaults to true when left out of an import.
+	IsActive *bool `json:"is_active,omitempty"`
+	// RateLimitPerSecond keeps the default when left out of an import.
+	RateLimitPerSecond      int            `json:"rate_limit_per_second,omitempty"`
+	CompressPayloads        bool           `json:"compress_payloads,omitempty"`
+	DiscardResponseBodies   bool           `json:"discard_response_bodies,omitempty"`
+	BatchMaxEvents          int            `json:"batch_max_events,omitempty"`
+	BatchWindowSeconds      int            `json:"batch_window_seconds,omitempty"`
+	ProxyURL                string         `json:"proxy_url,omitempty"`
+	SignatureFormat         string         `json:"signature_format,omitempty"`
+	EventVersions           map[string]int `json:"event_versions,omitempty"`
+	Sandbox                 bool           `json:"sandbox,omitempty"`
+	DailyQuota              int            `json:"daily_quota,omitempty"`
+	MonthlyQuota            int            `json:"monthly_quota,omitempty"`
+	AutoReplayDeadLetters   bool           `json:"auto_replay_dead_letters,omitempty"`
+	RetryJitter             string         `json:"retry_jitter,omitempty"`
+	MaxRetryDurationSeconds int            `json:"max_retry_duration_seconds,omitempty"`
+	RetryForDuration        bool           `json:"retry_for_duration,omitempty"`
+	SuccessBodyContains     string         `json:"success_body_contains,omitempty"`
+	SuccessJSONPath         string         `json:"success_json_path,omitempty"`
+	RedirectPolicy          string         `json:"redirect_policy,omitempty"`
+	EndpointType            string         `json:"endpoint_type,omitempty"`
+	DebugLogging            bool           `json:"debug_logging,omitempty"`
+	IsSystem                bool           `json:"is_system,omitempty"`
+	// SecretKey is only exported with include_secrets. Imported
+	// subscribers without one get a new secret.
+	SecretKey string `json:"secret_key,omitempty"`
+	// EndpointCredentials, like SecretKey, are only exported with
+	// include_secrets. They are a JSON object in CSV.
+	EndpointCredentials *domain.EndpointCredentials `json:"endpoint_credentials,omitempty"`
+}
+
+// manifestHeader is the CSV header of a manifest. Event types are separated
+// by spaces within their cell, as are event versions, written as
+// type=version.
+var manifestHeader = []string{
+	"name", "endpoint_url", "event_types", "is_active", "rate_limit_per_second", "compress_payloads",
+	"discard_response_bodies", "batch_max_events", "batch_window_seconds", "proxy_url", "signature_format",
+	"event_versions", "sandbox", "daily_quota", "monthly_quota", "auto_replay_dead_letters", "retry_jitter",
+	"max_retry_duration_seconds", "retry_for_duration", "success_body_contains", "success_json_path", "redirect_policy", "endpoint_type", "debug_logging",
+	"is_system", "secret_key", "endpoint_credentials",
+}
+
+func (m subscriberManifest) record() []string {
+	active := true
+	if m.IsActive != nil {
+		active = *m.IsActive
+	}
+	return []string{
+		m.Name, m.EndpointURL, strings.Join(m.EventTypes, " "), strconv.FormatBool(active),
+		strconv.Itoa(m.RateLimitPerSecond), strconv.FormatBool(m.CompressPayloads),
+		strconv.FormatBool(m.DiscardResponseBodies), strconv.Itoa(m.BatchMaxEvents),
+		strconv.Itoa(m.BatchWindowSeconds), m.ProxyURL, m.SignatureFormat, formatEventVersions(m.EventVersions),
+		strconv.FormatBool(m.Sandbox), strconv.Itoa(m.DailyQuota), strconv.Itoa(m.MonthlyQuota),
+		strconv.FormatBool(m.AutoReplayDeadLetters), m.RetryJitter,
+		strconv.Itoa(m.MaxRetryDurationSeconds), strconv.FormatBool(m.RetryForDuration), m.SuccessBodyContains, m.SuccessJSONPath, m.RedirectPolicy,
+		m.EndpointType, strconv.FormatBool(m.DebugLogging),
+		strconv.FormatBool(m.IsSystem), m.SecretKey, formatEndpointCredentials(m.EndpointCredentials),
+	}
+}
+
+// createRequest returns the request creating the subscriber. Being active,
+// the rate limit and debug logging are set by an update afterwards.
+func (m subscriberManifest) createRequest() domain.CreateSubscriberRequest {
+	return domain.CreateSubscriberRequest{
+		Name:                    m.Name,
+		EndpointURL:             m.EndpointURL,
+		EventTypes:              m.EventTypes,
+		CompressPayloads:        m.CompressPayloads,
+		DiscardResponseBodies:   m.DiscardResponseBodies,
+		BatchMaxEvents:          m.BatchMaxEvents,
+		BatchWindowSeconds:      m.BatchWindowSeconds,
+		ProxyURL:                m.ProxyURL,
+		SignatureFormat:         m.SignatureFormat,
+		EventVersions:           m.EventVersions,
+		Sandbox:                 m.Sandbox,
+		DailyQuota:              m.DailyQuota,
+		MonthlyQuota:            m.MonthlyQuota,
+		AutoReplayDeadLetters:   m.AutoReplayDeadLetters,
+		RetryJitter:             m.RetryJitter,
+		MaxRetryDurationSeconds: m.MaxRetryDurationSeconds,
+		RetryForDuration:        m.RetryForDuration,
+		SuccessBodyContains:     m.SuccessBodyContains,
+		SuccessJSONPath:         m.SuccessJSONPath,
+		RedirectPolicy:          m.RedirectPolicy,
+		EndpointType:            m.EndpointType,
+		EndpointCredentials:     m.EndpointCredentials,
+		IsSystem:                m.IsSystem,
+		SecretKey:               m.SecretKey,
+	}
+}
+
+// updateRequest returns the update applying the settings createRequest
+// leaves out, or false if they all keep their defaults.
+func (m subscriberManifest) updateRequest() (domain.UpdateSubscriberRequest, bool) {
+	var req domain.UpdateSubscriberRequest
+	if m.IsActive != nil && !*m.IsActive {
+		req.IsActive = m.IsActive
+	}
+	if m.RateLimitPerSecond > 0 {
+		req.RateLimitPerSecond = &m.RateLimitPerSecond
+	}
+	if m.DebugLogging {
+		req.DebugLogging = &m.DebugLogging
+	}
+	return req, req.IsActive != nil || req.RateLimitPerSecond != nil || req.DebugLogging != nil
+}
+
+func (m subscriberManifest) validate() error {
+	if err := validateCreateSubscriber(m.createRequest()); err != nil {
+		return err
+	}
+	if m.RateLimitPerSecond < 0 {
+		return errors.New("rate_limit_per_second must not be negative")
+	}
+	return nil
+}
+
+// manifestFor returns sub's manifest, with its secret if includeSecret is
+// set.
+func (h *SubscriberHandler) manifestFor(ctx context.Context, sub domain.Subscriber, includeSecret bool) (subscriberManifest, error) {
+	subscriptions, err := h.store.GetSubscriberSubscriptions(ctx, sub.ID)
+	if err != nil {
+		return subscriberManifest{}, err
+	}
+	m := subscriberManifest{
+		Name:                    sub.Name,
+		EndpointURL:             sub.EndpointURL,
+		EventTypes:              []string{},
+		IsActive:                &sub.IsActive,
+		RateLimitPerSecond:      sub.RateLimitPerSecond,
+		CompressPayloads:        sub.CompressPayloads,
+		DiscardResponseBodies:   sub.DiscardResponseBodies,
+		BatchMaxEvents:          sub.BatchMaxEvents,
+		BatchWindowSeconds:      sub.BatchWindowSeconds,
+		ProxyURL:                sub.ProxyURL,
+		SignatureFormat:         sub.SignatureFormat,
+		EventVersions:           sub.EventVersions,
+		Sandbox:                 sub.Sandbox,
+		DailyQuota:              sub.DailyQuota,
+		MonthlyQuota:            sub.MonthlyQuota,
+		AutoReplayDeadLetters:   sub.AutoReplayDeadLetters,
+		RetryJitter:             sub.RetryJitter,
+		MaxRetryDurationSeconds: sub.MaxRetryDurationSeconds,
+		RetryForDuration:        sub.RetryForDuration,
+		SuccessBodyContains:     sub.SuccessBodyContains,
+		SuccessJSONPath:         sub.SuccessJSONPath,
+		RedirectPolicy:          sub.RedirectPolicy,
+		EndpointType:            sub.EndpointType,
+		DebugLogging:            sub.DebugLogging,
+		IsSystem:                sub.IsSystem,
+	}
+	for _, subscription := range subscriptions {
+		if subscription.IsActive {
+			m.EventTypes = append(m.EventTypes, subscription.EventType)
+		}
+	}
+	if includeSecret {
+		// Listings leave secrets out, so fetch the subscriber again
+		full, err := h.store.GetSubscriber(ctx, sub.ID)
+		if err != nil {
+			return subscriberManifest{}, err
+		}
+		if full != nil {
+			m.SecretKey = full.SecretKey
+			m.EndpointCredentials = full.EndpointCredentials
+		}
+	}
+	return m, nil
+}
+
+// readManifest reads the subscribers of an import: CSV with a header row
+// when the request's Content-Type is text/csv, and otherwise JSON, either an
+// array or one object per line as exported.
+func readManifest(r *http.Request) ([]subscriberManifest, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "text/csv" {
+		return readManifestCSV(r.Body)
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimSpace(data)
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	var manifests []subscriberManifest
+	if bytes.HasPrefix(data, []byte("[")) {
+		if err := dec.Decode(&manifests); err != nil {
+			return nil, fmt.Errorf("invalid manifest: %w", err)
+		}
+		return manifests, nil
+	}
+	for dec.More() {
+		var m subscriberManifest
+		if err := dec.Decode(&m); err != nil {
+			return nil, fmt.Errorf("row %d: %w", len(manifests)+1, err)
+		}
+		manifests = append(manifests, m)
+	}
+	return manifests, nil
+}
+
+func readManifestCSV(body io.Reader) ([]subscriberManifest, error) {
+	reader := csv.NewReader(body)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading CSV header: %w", err)
+	}
+	known := make(map[string]bool, len(manifestHeader))
+	for _, name := range manifestHeader {
+		known[name] = true
+	}
+	for _, name := range header {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown CSV column %q", name)
+		}
+	}
+
+	var manifests []subscriberManifest
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return manifests, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		m, err := parseManifestRecord(header, record)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", len(manifests)+1, err)
+		}
+		manifests = append(manifests, m)
+	}
+}
+
+// parseManifestRecord reads a CSV row. Empty cells keep their defaults.
+func parseManifestRecord(header, record []string) (subscriberManifest, error) {
+	var m subscriberManifest
+	for i, name := range header {
+		value := strings.TrimSpace(record[i])
+		if value == "" {
+			continue
+		}
+		var err error
+		switch name {
+		case "name":
+			m.Name = value
+		case "endpoint_url":
+			m.EndpointURL = value
+		case "event_types":
+			m.EventTypes = strings.Fields(value)
+		case "is_active":
+			var active bool
+			active, err = strconv.ParseBool(value)
+			m.IsActive = &active
+		case "rate_limit_per_second":
+			m.RateLimitPerSecond, err = strconv.Atoi(value)
+		case "compress_payloads":
+			m.CompressPayloads, err = strconv.ParseBool(value)
+		case "discard_response_bodies":
+			m.DiscardResponseBodies, err = strconv.ParseBool(value)
+		case "batch_max_events":
+			m.BatchMaxEvents, err = strconv.Atoi(value)
+		case "batch_window_seconds":
+			m.BatchWindowSeconds, err = strconv.Atoi(value)
+		case "proxy_url":
+			m.ProxyURL = value
+		case "signature_format":
+			m.SignatureFormat = value
+		case "event_versions":
+			m.EventVersions, err = parseEventVersions(value)
+		case "sandbox":
+			m.Sandbox, err = strconv.ParseBool(value)
+		case "daily_quota":
+			m.DailyQuota, err = strconv.Atoi(value)
+		case "monthly_quota":
+			m.MonthlyQuota, err = strconv.Atoi(value)
+		case "auto_replay_dead_letters":
+			m.AutoReplayDeadLetters, err = strconv.ParseBool(value)
+		case "retry_jitter":
+			m.RetryJitter = value
+		case "max_retry_duration_seconds":
+			m.MaxRetryDurationSeconds, err = strconv.Atoi(value)
+		case "retry_for_duration":
+			m.RetryForDuration, err = strconv.ParseBool(value)
+		case "success_body_contains":
+			m.SuccessBodyContains = value
+		case "success_json_path":
+			m.SuccessJSONPath = value
+		case "redirect_policy":
+			m.RedirectPolicy = value
+		case "endpoint_type":
+			m.EndpointType = value
+		case "debug_logging":
+			m.DebugLogging, err = strconv.ParseBool(value)
+		case "is_system":
+			m.IsSystem, err = strconv.ParseBool(value)
+		case "secret_key":
+			m.SecretKey = value
+		case "endpoint_credentials":
+			m.EndpointCredentials = &domain.EndpointCredentials{}
+			err = json.Unmarshal([]byte(value), m.EndpointCredentials)
+		}
+		if err != nil {
+			return m, fmt.Errorf("invalid %s %q", name, value)
+		}
+	}
+	return m, nil
+}
+
+// formatEndpointCredentials writes a manifest's endpoint credentials for
+// their CSV cell, empty when there are none.
+func formatEndpointCredentials(creds *domain.EndpointCredentials) string {
+	if creds.IsZero() {
+		return ""
+	}
+	data, _ := json.Marshal(creds)
+	return string(data)
+}
+
+// formatEventVersions writes a manifest's event versions for its CSV cell,
+// sorted by event type.
+func formatEventVersions(versions map[string]int) string {
+	pins := make([]string, 0, len(versions))
+	for _, eventType := range slices.Sorted(maps.Keys(versions)) {
+		pins = append(pins, eventType+"="+strconv.Itoa(versions[eventType]))
+	}
+	return strings.Join(pins, " ")
+}
+
+// parseEventVersions reads a CSV cell written by formatEventVersions.
+func parseEventVersions(value string) (map[string]int, error) {
+	versions := make(map[string]int)
+	for _, pin := range strings.Fields(value) {
+		eventType, version, ok := strings.Cut(pin, "=")
+		if !ok {
+			return nil, fmt.Errorf("missing version")
+		}
+		n, err := strconv.Atoi(version)
+		if err != nil {
+			return nil, err
+		}
+		versions[eventType] = n
+	}
+	return versions, nil
+}
+
+// Export streams every subscriber, with its subscriptions, as a manifest
+// that Import accepts: NDJSON by default or CSV with format=csv. Secrets
+// are left out unless include_secrets is set, which needs the admin role.
+func (h *SubscriberHandler) Export(w http.ResponseWriter, r *http.Request) {
+	includeSecrets, ok := parseBoolQuery(w, r, "include_secrets")
+	if !ok {
+		return
+	}
+	if includeSecrets && !canSeeSecrets(r) {
+		respondError(w, http.StatusForbidden, "include_secrets requires the admin role")
+		return
+	}
+	out, err := newExportWriter(w, r, "subscribers", manifestHeader)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	subscribers, err := h.store.ListSubscribers(r.Context())
+	if err != nil {
+		out.fail(w, "failed to list subscribers")
+		return
+	}
+	if includeSecrets {
+		recordAudit(r, h.store, domain.AuditSubscriberExport, domain.AuditEntitySubscriber, "", map[string]interface{}{
+			"subscribers":     len(subscribers),
+			"include_secrets": true,
+		})
+	}
+
+	for _, sub := range subscribers {
+		m, err := h.manifestFor(r.Context(), sub, includeSecrets)
+		if err != nil {
+			out.fail(w, "failed to export subscribers")
+			return
+		}
+		if err := out.write(m, m.record()); err != nil {
+			return
+		}
+	}
+	out.flush()
+}
+
+// Import actions.
+const (
+	importCreate  = "create"
+	importSkip    = "skip"
+	importInvalid = "invalid"
+)
+
+type importRow struct {
+	Row  int    `json:"row"`
+	Name string `json:"name"`
+	// Action is "create", "skip" when a subscriber of that name already
+	// exists, or "invalid".
+	Action       string `json:"action"`
+	SubscriberID string `json:"subscriber_id,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+type importResponse struct {
+	DryRun bool `json:"dry_run"`
+	// Created counts the subscribers created, or that would be on a dry
+	// run.
+	Created int         `json:"created"`
+	Skipped int         `json:"skipped"`
+	Invalid int         `json:"invalid"`
+	Error   string      `json:"error,omitempty"`
+	Rows    []importRow `json:"rows"`
+}
+
+// Import creates the subscribers of a manifest, as exported by Export.
+// Subscribers are matched by name, and those that already exist are
+// skipped, so an import can be run again after a partial failure. The whole
+// manifest is validated first and nothing is created if any row is invalid;
+// with dry_run=true the import stops there and reports what it would do.
+func (h *SubscriberHandler) Import(w http.ResponseWriter, r *http.Request) {
+	dryRun, ok := parseBoolQuery(w, r, "dry_run")
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportBytes)
+	manifests, err := readManifest(r)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respondError(w, http.StatusRequestEntityTooLarge, payloadTooLarge(tooLarge.Limit))
+			return
+		}
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(manifests) == 0 {
+		respondError(w, http.StatusBadRequest, "manifest has no subscribers")
+		return
+	}
+	if len(manifests) > maxImportSubscribers {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("manifest has more than %d subscribers", maxImportSubscribers))
+		return
+	}
+
+	existing, err := h.store.ListSubscribers(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list subscribers")
+		return
+	}
+	existingIDs := make(map[string]string, len(existing))
+	for _, sub := range existing {
+		existingIDs[sub.Name] = sub.ID
+	}
+
+	resp := importResponse{DryRun: dryRun, Rows: make([]importRow, len(manifests))}
+	seen := make(map[string]bool, len(manifests))
+	var problems []string
+	for i, m := range manifests {
+		row := importRow{Row: i + 1, Name: m.Name, Action: importCreate}
+		err := m.validate()
+		if err == nil && seen[m.Name] {
+			err = fmt.Errorf("name %q is listed more than once", m.Name)
+		}
+		seen[m.Name] = true
+
+		switch {
+		case err != nil:
+			row.Action, row.Error = importInvalid, err.Error()
+			resp.Invalid++
+			problems = append(problems, fmt.Sprintf("row %d: %s", row.Row, row.Error))
+		case existingIDs[m.Name] != "":
+			row.Action, row.SubscriberID = importSkip, existingIDs[m.Name]
+			resp.Skipped++
+		default:
+			resp.Created++
+		}
+		resp.Rows[i] = row
+	}
+
+	if resp.Invalid > 0 {
+		if len(problems) > 5 {
+			problems = append(problems[:5], "...")
+		}
+		resp.Error = fmt.Sprintf("%d of %d rows are invalid, nothing was imported: %s", resp.Invalid, len(manifests), strings.Join(problems, "; "))
+		respondJSON(w, http.StatusBadRequest, resp)
+		return
+	}
+	if dryRun {
+		respondJSON(w, http.StatusOK, resp)
+		return
+	}
+
+	for i, m := range manifests {
+		if resp.Rows[i].Action != importCreate {
+			continue
+		}
+		id, err := h.importSubscriber(r, m)
+		if err != nil {
+			resp.Error = fmt.Sprintf("failed to create subscriber %q; run the import again to create the rest", m.Name)
+			respondJSON(w, http.StatusInternalServerError, resp)
+			return
+		}
+		resp.Rows[i].SubscriberID = id
+	}
+
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// importSubscriber creates a subscriber from its manifest, returning its ID.
+func (h *SubscriberHandler) importSubscriber(r *http.Request, m subscriberManifest) (string, error) {
+	req := m.createRequest()
+	sub, err := h.store.CreateSubscriber(r.Context(), req)
+	if err != nil {
+		return "", err
+	}
+	h.created(r, sub, req)
+
+	if update, ok := m.updateRequest(); ok {
+		if _, err := h.store.UpdateSubscriber(r.Context(), sub.ID, update); err != nil {
+			return sub.ID, err
+		}
+	}
+	return sub.ID, nil
+}
@@ -0,0 +1,154 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+func manifestRouter(h *SubscriberHandler) chi.Router {
+	r := chi.NewRouter()
+	r.Get("/subscribers/export", h.Export)
+	r.Post("/subscribers/import", h.Import)
+	return r
+}
+
+func importManifest(t *testing.T, r http.Handler, query, contentType string, body []byte) (int, importResponse) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/subscribers/import"+query, bytes.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	var resp importResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decoding import response: %v", err)
+	}
+	return rec.Code, resp
+}
+
+func TestSubscriberHandler_ExportAndImport(t *testing.T) {
+	ctx := context.Background()
+	source := store.NewMemoryStore()
+	orders, _ := source.CreateSubscriber(ctx, domain.CreateSubscriberRequest{
+		Name: "orders", EndpointURL: "https://example.com/orders", EventTypes: []string{"order.*", "refund.created"},
+		CompressPayloads: true, BatchMaxEvents: 10, BatchWindowSeconds: 5,
+	})
+	paused, _ := source.CreateSubscriber(ctx, domain.CreateSubscriberRequest{
+		Name: "paused", EndpointURL: "https://example.com/paused", EventTypes: []string{"user.created"},
+	})
+	inactive, limit := false, 25
+	source.UpdateSubscriber(ctx, paused.ID, domain.UpdateSubscriberRequest{IsActive: &inactive, RateLimitPerSecond: &limit})
+
+	for _, format := range []string{"ndjson", "csv"} {
+		t.Run(format, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			manifestRouter(NewSubscriberHandler(source, nil, nil, nil, nil, nil)).ServeHTTP(rec,
+				httptest.NewRequest(http.MethodGet, "/subscribers/export?include_secrets=true&format="+format, nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("export status = %d: %s", rec.Code, rec.Body)
+			}
+			manifest := rec.Body.Bytes()
+			contentType := "application/x-ndjson"
+			if format == "csv" {
+				contentType = "text/csv"
+			}
+
+			target := store.NewMemoryStore()
+			r := manifestRouter(NewSubscriberHandler(target, nil, nil, nil, nil, nil))
+
+			code, resp := importManifest(t, r, "?dry_run=true", contentType, manifest)
+			if code != http.StatusOK || !resp.DryRun || resp.Created != 2 || len(resp.Rows) != 2 {
+				t.Fatalf("dry run = %d %+v, want 2 to create", code, resp)
+			}
+			if subs, _ := target.ListSubscribers(ctx); len(subs) != 0 {
+				t.Fatalf("dry run created %d subscribers", len(subs))
+			}
+
+			code, resp = importManifest(t, r, "", contentType, manifest)
+			if code != http.StatusOK || resp.Created != 2 || resp.Rows[0].SubscriberID == "" {
+				t.Fatalf("import = %d %+v, want 2 created", code, resp)
+			}
+
+			imported := map[string]domain.Subscriber{}
+			subs, _ := target.ListSubscribers(ctx)
+			for _, sub := range subs {
+				full, _ := target.GetSubscriber(ctx, sub.ID)
+				imported[sub.Name] = *full
+			}
+			got := imported["orders"]
+			if got.EndpointURL != orders.EndpointURL || got.SecretKey != orders.SecretKey || !got.CompressPayloads ||
+				got.BatchMaxEvents != 10 || got.BatchWindowSeconds != 5 || !got.IsActive {
+				t.Errorf("imported orders = %+v", got)
+			}
+			matches, _ := target.FindMatchingSubscribers(ctx, "refund.created")
+			if len(matches) != 1 || matches[0].Name != "orders" {
+				t.Errorf("refund.created matched %+v, want orders", matches)
+			}
+			if got := imported["paused"]; got.IsActive || got.RateLimitPerSecond != 25 {
+				t.Errorf("imported paused = %+v, want inactive with rate limit 25", got)
+			}
+
+			code, resp = importManifest(t, r, "", contentType, manifest)
+			if code != http.StatusOK || resp.Created != 0 || resp.Skipped != 2 || resp.Rows[0].Action != importSkip {
+				t.Errorf("second import = %d %+v, want both skipped", code, resp)
+			}
+		})
+	}
+}
+
+func TestSubscriberHandler_ExportLeavesSecretsOut(t *testing.T) {
+	s := store.NewMemoryStore()
+	s.CreateSubscriber(context.Background(), domain.CreateSubscriberRequest{
+		Name: "orders", EndpointURL: "https://example.com/orders", EventTypes: []string{"order.created"},
+	})
+	r := manifestRouter(NewSubscriberHandler(s, nil, nil, nil, nil, nil))
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/subscribers/export", nil))
+	if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "secret_key") {
+		t.Errorf("export = %d %s, want no secrets", rec.Code, rec.Body)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/subscribers/export?include_secrets=true", nil)
+	req = req.WithContext(context.WithValue(req.Context(), apiKeyContextKey{}, &domain.APIKey{Role: domain.RoleViewer}))
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("include_secrets as viewer: status = %d, want 403", rec.Code)
+	}
+}
+
+func TestSubscriberHandler_ImportRejectsInvalidManifests(t *testing.T) {
+	s := store.NewMemoryStore()
+	r := manifestRouter(NewSubscriberHandler(s, nil, nil, nil, nil, nil))
+
+	manifest := `[
+		{"name":"orders","endpoint_url":"https://example.com/orders","event_types":["order.created"]},
+		{"name":"orders","endpoint_url":"https://example.com/other","event_types":["order.created"]},
+		{"name":"bad","endpoint_url":"https://example.com/bad","event_types":[]}
+	]`
+	code, resp := importManifest(t, r, "", "application/json", []byte(manifest))
+	if code != http.StatusBadRequest || resp.Invalid != 2 || resp.Rows[0].Action != importCreate ||
+		resp.Rows[1].Action != importInvalid || !strings.Contains(resp.Error, "row 3") {
+		t.Errorf("import = %d %+v, want rows 2 and 3 invalid", code, resp)
+	}
+	if subs, _ := s.ListSubscribers(context.Background()); len(subs) != 0 {
+		t.Errorf("invalid manifest created %d subscribers", len(subs))
+	}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/subscribers/import", strings.NewReader("name,colour\norders,red\n"))
+	req.Header.Set("Content-Type", "text/csv")
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "colour") {
+		t.Errorf("unknown CSV column: %d %s, want 400", rec.Code, rec.Body)
+	}
+}
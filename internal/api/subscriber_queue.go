@@ -0,0 +1,192 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+// SubscriberQueueHandler manages the jobs waiting for one subscriber: listing
+// and purging them, and pausing and resuming their delivery. Unlike
+// deactivating a subscriber, pausing keeps fanning events out to it: its jobs
+// are parked and delivered in full on resume.
+type SubscriberQueueHandler struct {
+	store  store.Store
+	fanout *engine.FanOutEngine
+}
+
+func NewSubscriberQueueHandler(s store.Store, f *engine.FanOutEngine) *SubscriberQueueHandler {
+	return &SubscriberQueueHandler{store: s, fanout: f}
+}
+
+type pauseRequest struct {
+	// Duration is how long to pause for, e.g. "10m". Empty pauses until
+	// resumed.
+	Duration string `json:"duration,omitempty"`
+}
+
+type resumeResponse struct {
+	engine.PauseStatus
+	JobsResumed int64 `json:"jobs_resumed"`
+}
+
+// Status reports whether the subscriber is paused and how many jobs wait.
+func (h *SubscriberQueueHandler) Status(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !h.subscriberExists(w, r, id) {
+		return
+	}
+
+	h.respondStatus(w, r, id)
+}
+
+// Pause stops deliveries to the subscriber, optionally for a fixed duration.
+func (h *SubscriberQueueHandler) Pause(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	var req pauseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	var until time.Time
+	if req.Duration != "" {
+		d, err := time.ParseDuration(req.Duration)
+		if err != nil || d <= 0 {
+			respondError(w, http.StatusBadRequest, "duration must be a positive duration such as 10m")
+			return
+		}
+		until = time.Now().Add(d)
+	}
+
+	if !h.subscriberExists(w, r, id) {
+		return
+	}
+	if err := h.fanout.PauseSubscriber(r.Context(), id, until); err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to pause subscriber")
+		return
+	}
+
+	recordAudit(r, h.store, domain.AuditSubscriberPause, domain.AuditEntitySubscriber, id, req)
+
+	h.respondStatus(w, r, id)
+}
+
+// Resume lifts the pause and queues every parked job.
+func (h *SubscriberQueueHandler) Resume(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !h.subscriberExists(w, r, id) {
+		return
+	}
+
+	resumed, err := h.fanout.ResumeSubscriber(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to resume subscriber")
+		return
+	}
+
+	recordAudit(r, h.store, domain.AuditSubscriberResume, domain.AuditEntitySubscriber, id, map[string]int64{
+		"jobs_resumed": resumed,
+	})
+
+	status, err := h.fanout.SubscriberPause(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get pause status")
+		return
+	}
+	respondJSON(w, http.StatusOK, resumeResponse{PauseStatus: status, JobsResumed: resumed})
+}
+
+type pendingResponse struct {
+	SubscriberID string              `json:"subscriber_id"`
+	Total        int                 `json:"total"`
+	Jobs         []engine.PendingJob `json:"jobs"`
+}
+
+// Pending lists the jobs queued, scheduled for retry or parked for the
+// subscriber, soonest first. limit caps how many are returned; total counts
+// them all.
+func (h *SubscriberQueueHandler) Pending(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	limit := 100
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if n, err := strconv.Atoi(limitStr); err == nil && n > 0 {
+			limit = n
+		}
+	}
+
+	if !h.subscriberExists(w, r, id) {
+		return
+	}
+	jobs, err := h.fanout.PendingJobs(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list pending jobs")
+		return
+	}
+
+	resp := pendingResponse{SubscriberID: id, Total: len(jobs), Jobs: jobs}
+	if len(jobs) > limit {
+		resp.Jobs = jobs[:limit]
+	}
+	if resp.Jobs == nil {
+		resp.Jobs = []engine.PendingJob{}
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+type purgeResponse struct {
+	SubscriberID string `json:"subscriber_id"`
+	JobsPurged   int64  `json:"jobs_purged"`
+}
+
+// Purge drops every job waiting for the subscriber. Jobs a worker is already
+// delivering are not affected.
+func (h *SubscriberQueueHandler) Purge(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !h.subscriberExists(w, r, id) {
+		return
+	}
+
+	purged, err := h.fanout.PurgePending(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to purge pending jobs")
+		return
+	}
+
+	recordAudit(r, h.store, domain.AuditSubscriberPurge, domain.AuditEntitySubscriber, id, map[string]int64{
+		"jobs_purged": purged,
+	})
+
+	respondJSON(w, http.StatusOK, purgeResponse{SubscriberID: id, JobsPurged: purged})
+}
+
+func (h *SubscriberQueueHandler) subscriberExists(w http.ResponseWriter, r *http.Request, id string) bool {
+	sub, err := h.store.GetSubscriber(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get subscriber")
+		return false
+	}
+	if sub == nil {
+		respondError(w, http.StatusNotFound, "subscriber not found")
+		return false
+	}
+	return true
+}
+
+func (h *SubscriberQueueHandler) respondStatus(w http.ResponseWriter, r *http.Request, id string) {
+	status, err := h.fanout.SubscriberPause(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get pause status")
+		return
+	}
+	respondJSON(w, http.StatusOK, status)
+}
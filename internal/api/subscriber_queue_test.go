@@ -0,0 +1,123 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+func TestSubscriberQueueHandler_PauseAndResume(t *testing.T) {
+	s := store.NewMemoryStore()
+	queue := engine.NewMemoryQueue()
+	h := NewSubscriberQueueHandler(s, engine.NewFanOutEngine(nil, queue, nil, slog.Default()))
+
+	r := chi.NewRouter()
+	r.Get("/subscribers/{id}/pause", h.Status)
+	r.Post("/subscribers/{id}/pause", h.Pause)
+	r.Post("/subscribers/{id}/resume", h.Resume)
+
+	ctx := context.Background()
+	sub, err := s.CreateSubscriber(ctx, domain.CreateSubscriberRequest{
+		Name: "orders", EndpointURL: "https://example.com/hook", EventTypes: []string{"order.created"},
+	})
+	if err != nil {
+		t.Fatalf("CreateSubscriber failed: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/subscribers/"+sub.ID+"/pause", strings.NewReader(`{"duration":"soon"}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid duration: status = %d, want 400", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/subscribers/missing/pause", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown subscriber: status = %d, want 404", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/subscribers/"+sub.ID+"/pause", strings.NewReader(`{"duration":"10m"}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("pause status = %d: %s", rec.Code, rec.Body)
+	}
+	var status engine.PauseStatus
+	json.NewDecoder(rec.Body).Decode(&status)
+	if !status.Paused || status.PausedUntil == nil || time.Until(*status.PausedUntil) < 9*time.Minute {
+		t.Errorf("paused status = %+v, want paused for 10 minutes", status)
+	}
+
+	queue.ParkIfPaused(ctx, engine.DeliveryJob{EventID: "evt-1", SubscriberID: sub.ID})
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/subscribers/"+sub.ID+"/resume", nil))
+	var resumed resumeResponse
+	json.NewDecoder(rec.Body).Decode(&resumed)
+	if rec.Code != http.StatusOK || resumed.Paused || resumed.JobsResumed != 1 {
+		t.Errorf("resume = %d %+v, want 1 job resumed", rec.Code, resumed)
+	}
+	if depth, _ := queue.Depth(ctx); depth != 1 {
+		t.Errorf("queue depth after resume = %d, want 1", depth)
+	}
+
+	entries, _ := s.ListAuditEntries(ctx, domain.AuditFilter{EntityID: sub.ID})
+	if len(entries) != 2 {
+		t.Errorf("audit entries = %+v, want pause and resume", entries)
+	}
+}
+
+func TestSubscriberQueueHandler_PendingAndPurge(t *testing.T) {
+	s := store.NewMemoryStore()
+	queue := engine.NewMemoryQueue()
+	h := NewSubscriberQueueHandler(s, engine.NewFanOutEngine(nil, queue, nil, slog.Default()))
+
+	r := chi.NewRouter()
+	r.Get("/subscribers/{id}/pending", h.Pending)
+	r.Delete("/subscribers/{id}/pending", h.Purge)
+
+	ctx := context.Background()
+	sub, _ := s.CreateSubscriber(ctx, domain.CreateSubscriberRequest{
+		Name: "orders", EndpointURL: "https://example.com/hook", EventTypes: []string{"order.created"},
+	})
+	for i := 0; i < 3; i++ {
+		queue.Enqueue(ctx, engine.DeliveryJob{EventID: "evt", SubscriberID: sub.ID, SecretKey: "whsec_secret"}, time.Now())
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/subscribers/"+sub.ID+"/pending?limit=2", nil))
+	if strings.Contains(rec.Body.String(), "whsec_secret") {
+		t.Error("pending jobs expose the signing secret")
+	}
+	var pending pendingResponse
+	json.NewDecoder(rec.Body).Decode(&pending)
+	if rec.Code != http.StatusOK || pending.Total != 3 || len(pending.Jobs) != 2 {
+		t.Errorf("pending = %d %+v, want 2 of 3 jobs", rec.Code, pending)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/subscribers/"+sub.ID+"/pending", nil))
+	var purged purgeResponse
+	json.NewDecoder(rec.Body).Decode(&purged)
+	if rec.Code != http.StatusOK || purged.JobsPurged != 3 {
+		t.Errorf("purge = %d %+v, want 3 jobs purged", rec.Code, purged)
+	}
+	if depth, _ := queue.Depth(ctx); depth != 0 {
+		t.Errorf("queue depth after purge = %d", depth)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/subscribers/missing/pending", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown subscriber: status = %d, want 404", rec.Code)
+	}
+}
@@ -1,8 +1,14 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Priya8975/webhook-delivery-system/internal/domain"
 	"github.com/Priya8975/webhook-delivery-system/internal/engine"
@@ -10,13 +16,23 @@ import (
 	"github.com/go-chi/chi/v5"
 )
 
+// EndpointVerifier runs the verification handshake against a subscriber's
+// new endpoint URL. It is implemented by worker.Deliverer.
+type EndpointVerifier interface {
+	VerifyEndpoint(ctx context.Context, sub *domain.Subscriber, endpointURL string) error
+}
+
 type SubscriberHandler struct {
-	store          *store.PostgresStore
+	store          store.Store
 	circuitBreaker *engine.CircuitBreaker
+	quota          *engine.DeliveryQuota
+	fanout         *engine.FanOutEngine
+	cleanup        *engine.SubscriberCleanup
+	verifier       EndpointVerifier
 }
 
-func NewSubscriberHandler(s *store.PostgresStore, cb *engine.CircuitBreaker) *SubscriberHandler {
-	return &SubscriberHandler{store: s, circuitBreaker: cb}
+func NewSubscriberHandler(s store.Store, cb *engine.CircuitBreaker, q *engine.DeliveryQuota, f *engine.FanOutEngine, c *engine.SubscriberCleanup, v EndpointVerifier) *SubscriberHandler {
+	return &SubscriberHandler{store: s, circuitBreaker: cb, quota: q, fanout: f, cleanup: c, verifier: v}
 }
 
 func (h *SubscriberHandler) Create(w http.ResponseWriter, r *http.Request) {
@@ -26,16 +42,8 @@ func (h *SubscriberHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.Name == "" {
-		respondError(w, http.StatusBadRequest, "name is required")
-		return
-	}
-	if req.EndpointURL == "" {
-		respondError(w, http.StatusBadRequest, "endpoint_url is required")
-		return
-	}
-	if len(req.EventTypes) == 0 {
-		respondError(w, http.StatusBadRequest, "at least one event_type is required")
+	if err := validateCreateSubscriber(req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
@@ -44,6 +52,7 @@ func (h *SubscriberHandler) Create(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusInternalServerError, "failed to create subscriber")
 		return
 	}
+	h.created(r, sub, req)
 
 	respondJSON(w, http.StatusCreated, domain.CreateSubscriberResponse{
 		ID:        sub.ID,
@@ -52,6 +61,66 @@ func (h *SubscriberHandler) Create(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// validateCreateSubscriber checks a new subscriber's settings.
+func validateCreateSubscriber(req domain.CreateSubscriberRequest) error {
+	if req.Name == "" {
+		return errors.New("name is required")
+	}
+	if req.EndpointURL == "" && !req.Sandbox {
+		return errors.New("endpoint_url is required unless sandbox is set")
+	}
+	if len(req.EventTypes) == 0 {
+		return errors.New("at least one event_type is required")
+	}
+	if err := domain.ValidateEventPatterns(req.EventTypes); err != nil {
+		return err
+	}
+	if err := domain.ValidateBatching(req.BatchMaxEvents, req.BatchWindowSeconds); err != nil {
+		return err
+	}
+	if err := domain.ValidateProxyURL(req.ProxyURL); err != nil {
+		return err
+	}
+	if err := domain.ValidateSignatureFormat(req.SignatureFormat); err != nil {
+		return err
+	}
+	if err := domain.ValidateRetryJitter(req.RetryJitter); err != nil {
+		return err
+	}
+	if err := domain.ValidateRetryDuration(req.MaxRetryDurationSeconds, req.RetryForDuration); err != nil {
+		return err
+	}
+	if err := domain.ValidateSuccessCondition(domain.SuccessCondition{BodyContains: req.SuccessBodyContains, JSONPath: req.SuccessJSONPath}); err != nil {
+		return err
+	}
+	if err := domain.ValidateRedirectPolicy(req.RedirectPolicy); err != nil {
+		return err
+	}
+	if err := domain.ValidateEndpoint(req.EndpointType, req.EndpointURL, req.EndpointCredentials); err != nil {
+		return err
+	}
+	if err := domain.ValidateEventVersions(req.EventVersions); err != nil {
+		return err
+	}
+	return domain.ValidateQuotas(req.DailyQuota, req.MonthlyQuota)
+}
+
+// created audits and announces a new subscriber.
+func (h *SubscriberHandler) created(r *http.Request, sub *domain.Subscriber, req domain.CreateSubscriberRequest) {
+	audited := req
+	audited.EndpointCredentials = req.EndpointCredentials.Redacted()
+	recordAudit(r, h.store, domain.AuditSubscriberCreate, domain.AuditEntitySubscriber, sub.ID, audited)
+	if h.fanout != nil {
+		h.fanout.PublishSystemEvent(r.Context(), domain.EventSubscriberCreated, domain.SubscriberCreatedEvent{
+			SubscriberID: sub.ID,
+			Name:         sub.Name,
+			EndpointURL:  sub.EndpointURL,
+			EventTypes:   req.EventTypes,
+			IsSystem:     sub.IsSystem,
+		})
+	}
+}
+
 func (h *SubscriberHandler) List(w http.ResponseWriter, r *http.Request) {
 	subscribers, err := h.store.ListSubscribers(r.Context())
 	if err != nil {
@@ -82,6 +151,13 @@ func (h *SubscriberHandler) Get(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !canSeeSecrets(r) {
+		sub.SecretKey = ""
+		sub.EndpointCredentials = nil
+	}
+
+	w.Header().Set("ETag", subscriberETag(sub.Version))
+
 	type subscriberDetail struct {
 		domain.Subscriber
 		Subscriptions []domain.Subscription `json:"subscriptions"`
@@ -125,6 +201,63 @@ func (h *SubscriberHandler) Health(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// statsWindows are the lookback windows accepted by the stats endpoint.
+var statsWindows = map[string]time.Duration{
+	"1h":  time.Hour,
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+}
+
+// Stats returns delivery statistics for a subscriber over ?window=1h|24h|7d
+// (default 24h).
+func (h *SubscriberHandler) Stats(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	window := r.URL.Query().Get("window")
+	if window == "" {
+		window = "24h"
+	}
+	lookback, ok := statsWindows[window]
+	if !ok {
+		respondError(w, http.StatusBadRequest, "window must be one of 1h, 24h, 7d")
+		return
+	}
+
+	sub, err := h.store.GetSubscriber(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get subscriber")
+		return
+	}
+	if sub == nil {
+		respondError(w, http.StatusNotFound, "subscriber not found")
+		return
+	}
+
+	stats, err := h.store.GetSubscriberStats(r.Context(), id, time.Now().Add(-lookback))
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get subscriber stats")
+		return
+	}
+
+	quota, err := h.quota.Usage(r.Context(), id, sub.DailyQuota, sub.MonthlyQuota)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get delivery quota usage")
+		return
+	}
+
+	type statsResponse struct {
+		Window string `json:"window"`
+		*store.SubscriberStats
+		Quota *domain.QuotaUsage `json:"quota,omitempty"`
+	}
+
+	respondJSON(w, http.StatusOK, statsResponse{
+		Window:          window,
+		SubscriberStats: stats,
+		Quota:           quota,
+	})
+}
+
 func (h *SubscriberHandler) Update(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
@@ -133,8 +266,176 @@ func (h *SubscriberHandler) Update(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
+	resolveDeadLetters, ok := parseBoolQuery(w, r, "resolve_dead_letters")
+	if !ok {
+		return
+	}
+	verifyEndpoint, ok := parseBoolQuery(w, r, "verify_endpoint")
+	if !ok {
+		return
+	}
+	if !parseIfMatch(w, r, &req) {
+		return
+	}
+
+	before, err := h.store.GetSubscriber(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get subscriber")
+		return
+	}
+	if before == nil {
+		respondError(w, http.StatusNotFound, "subscriber not found")
+		return
+	}
+	if before.Deleted() {
+		respondError(w, http.StatusConflict, "subscriber is deleted; restore it first")
+		return
+	}
+	if req.Version != nil && *req.Version != before.Version {
+		respondVersionConflict(w, *req.Version)
+		return
+	}
+
+	// Batching settings are validated together with the ones kept
+	if req.BatchMaxEvents != nil || req.BatchWindowSeconds != nil {
+		maxEvents, window := before.BatchMaxEvents, before.BatchWindowSeconds
+		if req.BatchMaxEvents != nil {
+			maxEvents = *req.BatchMaxEvents
+		}
+		if req.BatchWindowSeconds != nil {
+			window = *req.BatchWindowSeconds
+		}
+		if err := domain.ValidateBatching(maxEvents, window); err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if req.ProxyURL != nil {
+		if err := domain.ValidateProxyURL(*req.ProxyURL); err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if req.SignatureFormat != nil {
+		if err := domain.ValidateSignatureFormat(*req.SignatureFormat); err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if req.RetryJitter != nil {
+		if err := domain.ValidateRetryJitter(*req.RetryJitter); err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if req.MaxRetryDurationSeconds != nil || req.RetryForDuration != nil {
+		seconds, forDuration := before.MaxRetryDurationSeconds, before.RetryForDuration
+		if req.MaxRetryDurationSeconds != nil {
+			seconds = *req.MaxRetryDurationSeconds
+		}
+		if req.RetryForDuration != nil {
+			forDuration = *req.RetryForDuration
+		}
+		if err := domain.ValidateRetryDuration(seconds, forDuration); err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if req.SuccessBodyContains != nil || req.SuccessJSONPath != nil {
+		condition := before.SuccessCondition()
+		if req.SuccessBodyContains != nil {
+			condition.BodyContains = *req.SuccessBodyContains
+		}
+		if req.SuccessJSONPath != nil {
+			condition.JSONPath = *req.SuccessJSONPath
+		}
+		if err := domain.ValidateSuccessCondition(condition); err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if req.RedirectPolicy != nil {
+		if err := domain.ValidateRedirectPolicy(*req.RedirectPolicy); err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if err := domain.ValidateEventVersions(req.EventVersions); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.DailyQuota != nil || req.MonthlyQuota != nil {
+		daily, monthly := before.DailyQuota, before.MonthlyQuota
+		if req.DailyQuota != nil {
+			daily = *req.DailyQuota
+		}
+		if req.MonthlyQuota != nil {
+			monthly = *req.MonthlyQuota
+		}
+		if err := domain.ValidateQuotas(daily, monthly); err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	// Only sandbox subscribers can go without an endpoint
+	if req.EndpointURL != nil || req.Sandbox != nil {
+		endpoint, sandbox := before.EndpointURL, before.Sandbox
+		if req.EndpointURL != nil {
+			endpoint = *req.EndpointURL
+		}
+		if req.Sandbox != nil {
+			sandbox = *req.Sandbox
+		}
+		if endpoint == "" && !sandbox {
+			respondError(w, http.StatusBadRequest, "endpoint_url is required unless sandbox is set")
+			return
+		}
+	}
+	// The endpoint type, URL and credentials are validated together. Going
+	// back to HTTP drops credentials the request doesn't replace
+	endpointType := before.EndpointType
+	if req.EndpointType != nil || req.EndpointURL != nil || req.EndpointCredentials != nil {
+		endpoint, creds := before.EndpointURL, before.EndpointCredentials
+		if req.EndpointType != nil {
+			endpointType = domain.EndpointTypeOrDefault(*req.EndpointType)
+			if endpointType == domain.EndpointHTTP && req.EndpointCredentials == nil && !creds.IsZero() {
+				req.EndpointCredentials = &domain.EndpointCredentials{}
+			}
+		}
+		if req.EndpointURL != nil {
+			endpoint = *req.EndpointURL
+		}
+		if req.EndpointCredentials != nil {
+			creds = req.EndpointCredentials
+		}
+		if err := domain.ValidateEndpoint(endpointType, endpoint, creds); err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	// Only switch deliveries to a new endpoint once it has answered the
+	// handshake. Jobs already queued keep the endpoint they were queued for
+	if verifyEndpoint && req.EndpointURL != nil && *req.EndpointURL != before.EndpointURL {
+		if h.verifier == nil {
+			respondError(w, http.StatusBadRequest, "endpoint verification is not available")
+			return
+		}
+		if domain.EndpointTypeOrDefault(endpointType) != domain.EndpointHTTP {
+			respondError(w, http.StatusBadRequest, "endpoint verification is only available for http endpoints")
+			return
+		}
+		if err := h.verifier.VerifyEndpoint(r.Context(), before, *req.EndpointURL); err != nil {
+			respondError(w, http.StatusUnprocessableEntity, "endpoint verification failed: "+err.Error())
+			return
+		}
+	}
 
 	sub, err := h.store.UpdateSubscriber(r.Context(), id, req)
+	if errors.Is(err, store.ErrVersionConflict) {
+		respondVersionConflict(w, *req.Version)
+		return
+	}
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "failed to update subscriber")
 		return
@@ -144,5 +445,170 @@ func (h *SubscriberHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The version was a precondition, not part of the change
+	change := req
+	change.Version = nil
+	change.EndpointCredentials = req.EndpointCredentials.Redacted()
+	previous := req.Previous(before)
+	previous.EndpointCredentials = previous.EndpointCredentials.Redacted()
+	recordAudit(r, h.store, domain.AuditSubscriberUpdate, domain.AuditEntitySubscriber, id, domain.SubscriberChange{
+		Before: previous,
+		After:  change,
+	})
+
+	if before.IsActive && !sub.IsActive && h.cleanup != nil {
+		result, err := h.cleanup.Cleanup(r.Context(), id, resolveDeadLetters)
+		recordAudit(r, h.store, domain.AuditSubscriberCleanup, domain.AuditEntitySubscriber, id, result)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "subscriber deactivated, but cleanup failed")
+			return
+		}
+	}
+
+	w.Header().Set("ETag", subscriberETag(sub.Version))
+	respondJSON(w, http.StatusOK, sub)
+}
+
+// subscriberETag is the ETag of a subscriber at version, which clients send
+// back in If-Match to update it only if nobody changed it since.
+func subscriberETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// parseIfMatch reads an update's If-Match header into req.Version. A
+// version in the body must agree with it, and "*" sets no precondition. It
+// responds with 400 and returns false if the header is invalid.
+func parseIfMatch(w http.ResponseWriter, r *http.Request, req *domain.UpdateSubscriberRequest) bool {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return true
+	}
+	version, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(header, "W/"), `"`))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "If-Match must be the subscriber's ETag")
+		return false
+	}
+	if req.Version != nil && *req.Version != version {
+		respondError(w, http.StatusBadRequest, "If-Match and version disagree")
+		return false
+	}
+	req.Version = &version
+	return true
+}
+
+func respondVersionConflict(w http.ResponseWriter, version int) {
+	respondError(w, http.StatusConflict, fmt.Sprintf("subscriber was changed since version %d; fetch it and retry", version))
+}
+
+type deleteSubscriberResponse struct {
+	SubscriberID string    `json:"subscriber_id"`
+	DeletedAt    time.Time `json:"deleted_at"`
+	engine.CleanupResult
+}
+
+// parseBoolQuery reads an optional boolean query parameter, such as
+// resolve_dead_letters on the requests that remove a subscriber. It
+// responds with 400 and returns false if the value isn't a boolean.
+func parseBoolQuery(w http.ResponseWriter, r *http.Request, name string) (bool, bool) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return false, true
+	}
+	value, err := strconv.ParseBool(v)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, name+" must be true or false")
+		return false, false
+	}
+	return value, true
+}
+
+// Delete soft-deletes the subscriber: it stops receiving events and leaves
+// the listings, and its delivery state is cleaned up, resolving its dead
+// letters too with ?resolve_dead_letters=true. Its delivery history is kept
+// and it can be restored.
+func (h *SubscriberHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	resolveDeadLetters, ok := parseBoolQuery(w, r, "resolve_dead_letters")
+	if !ok {
+		return
+	}
+
+	before, err := h.store.GetSubscriber(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get subscriber")
+		return
+	}
+	if before == nil {
+		respondError(w, http.StatusNotFound, "subscriber not found")
+		return
+	}
+	if before.Deleted() {
+		respondError(w, http.StatusConflict, "subscriber is already deleted")
+		return
+	}
+
+	sub, err := h.store.DeleteSubscriber(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to delete subscriber")
+		return
+	}
+	if sub == nil {
+		respondError(w, http.StatusNotFound, "subscriber not found")
+		return
+	}
+
+	// Clean up after deleting, so no new fan-out can queue jobs behind it
+	var result engine.CleanupResult
+	var cleanupErr error
+	if h.cleanup != nil {
+		result, cleanupErr = h.cleanup.Cleanup(r.Context(), id, resolveDeadLetters)
+	}
+
+	recordAudit(r, h.store, domain.AuditSubscriberDelete, domain.AuditEntitySubscriber, id, result)
+
+	if cleanupErr != nil {
+		respondError(w, http.StatusInternalServerError, "subscriber deleted, but cleanup failed")
+		return
+	}
+	respondJSON(w, http.StatusOK, deleteSubscriberResponse{
+		SubscriberID:  id,
+		DeletedAt:     *sub.DeletedAt,
+		CleanupResult: result,
+	})
+}
+
+// Restore undoes Delete. Events published while the subscriber was deleted
+// are not delivered.
+func (h *SubscriberHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	before, err := h.store.GetSubscriber(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get subscriber")
+		return
+	}
+	if before == nil {
+		respondError(w, http.StatusNotFound, "subscriber not found")
+		return
+	}
+	if !before.Deleted() {
+		respondError(w, http.StatusConflict, "subscriber is not deleted")
+		return
+	}
+
+	sub, err := h.store.RestoreSubscriber(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to restore subscriber")
+		return
+	}
+	if sub == nil {
+		respondError(w, http.StatusNotFound, "subscriber not found")
+		return
+	}
+
+	recordAudit(r, h.store, domain.AuditSubscriberRestore, domain.AuditEntitySubscriber, id, map[string]time.Time{
+		"deleted_at": *before.DeletedAt,
+	})
+
 	respondJSON(w, http.StatusOK, sub)
 }
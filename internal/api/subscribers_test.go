@@ -0,0 +1,756 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-chi/chi/v5"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestSubscriberHandler_CreateAndUpdate(t *testing.T) {
+	s := store.NewMemoryStore()
+	h := NewSubscriberHandler(s, nil, nil, nil, nil, nil)
+
+	r := chi.NewRouter()
+	r.Post("/subscribers", h.Create)
+	r.Get("/subscribers/{id}", h.Get)
+	r.Patch("/subscribers/{id}", h.Update)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/subscribers",
+		strings.NewReader(`{"name":"orders","endpoint_url":"https://example.com/hook","event_types":["order.*"]}`)))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d: %s", rec.Code, rec.Body)
+	}
+	bad := httptest.NewRecorder()
+	r.ServeHTTP(bad, httptest.NewRequest(http.MethodPost, "/subscribers",
+		strings.NewReader(`{"name":"bad","endpoint_url":"https://example.com/hook","event_types":["order.cre*"]}`)))
+	if bad.Code != http.StatusBadRequest {
+		t.Errorf("create with invalid pattern: status = %d, want 400", bad.Code)
+	}
+	var created domain.CreateSubscriberResponse
+	json.NewDecoder(rec.Body).Decode(&created)
+	if created.ID == "" || !strings.HasPrefix(created.SecretKey, "whdlv_") {
+		t.Fatalf("unexpected create response %+v", created)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/subscribers/"+created.ID,
+		strings.NewReader(`{"is_active":false}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update status = %d: %s", rec.Code, rec.Body)
+	}
+	var updated domain.Subscriber
+	json.NewDecoder(rec.Body).Decode(&updated)
+	if updated.IsActive || updated.SecretKey != "" {
+		t.Errorf("update returned %+v, want inactive without secret", updated)
+	}
+
+	matches, _ := s.FindMatchingSubscribers(context.Background(), "order.created")
+	if len(matches) != 0 {
+		t.Errorf("inactive subscriber matched %d times", len(matches))
+	}
+
+	entries, _ := s.ListAuditEntries(context.Background(), domain.AuditFilter{EntityID: created.ID})
+	if len(entries) != 2 || entries[0].Action != domain.AuditSubscriberUpdate || entries[1].Action != domain.AuditSubscriberCreate {
+		t.Fatalf("audit entries = %+v, want update then create", entries)
+	}
+	var change domain.SubscriberChange
+	json.Unmarshal(entries[0].Details, &change)
+	if change.Before.IsActive == nil || !*change.Before.IsActive {
+		t.Errorf("audit before = %+v, want is_active true", change.Before)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/subscribers/missing", strings.NewReader(`{}`)))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("update of unknown subscriber status = %d, want 404", rec.Code)
+	}
+}
+
+type fakeVerifier struct {
+	verified []string
+	err      error
+}
+
+func (v *fakeVerifier) VerifyEndpoint(_ context.Context, _ *domain.Subscriber, endpointURL string) error {
+	v.verified = append(v.verified, endpointURL)
+	return v.err
+}
+
+func TestSubscriberHandler_VerifiesNewEndpoint(t *testing.T) {
+	s := store.NewMemoryStore()
+	verifier := &fakeVerifier{err: errors.New("endpoint responded with status 404")}
+	h := NewSubscriberHandler(s, nil, nil, nil, nil, verifier)
+	r := chi.NewRouter()
+	r.Patch("/subscribers/{id}", h.Update)
+	sub, err := s.CreateSubscriber(context.Background(), domain.CreateSubscriberRequest{
+		Name: "orders", EndpointURL: "https://example.com/hook", EventTypes: []string{"order.created"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	patch := func(query, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/subscribers/"+sub.ID+query, strings.NewReader(body)))
+		return rec
+	}
+
+	rec := patch("?verify_endpoint=true", `{"endpoint_url":"https://example.com/hokk"}`)
+	if rec.Code != http.StatusUnprocessableEntity || !strings.Contains(rec.Body.String(), "status 404") {
+		t.Errorf("failed verification = %d: %s", rec.Code, rec.Body)
+	}
+	if got, _ := s.GetSubscriber(context.Background(), sub.ID); got.EndpointURL != sub.EndpointURL {
+		t.Errorf("endpoint = %q, want it unchanged after a failed verification", got.EndpointURL)
+	}
+
+	// Other changes, and updates without verify_endpoint, skip the handshake
+	if rec := patch("?verify_endpoint=true", `{"rate_limit_per_second":20}`); rec.Code != http.StatusOK {
+		t.Errorf("update without an endpoint change = %d: %s", rec.Code, rec.Body)
+	}
+	if rec := patch("?verify_endpoint=maybe", `{"endpoint_url":"https://example.com/v2"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid verify_endpoint: status = %d, want 400", rec.Code)
+	}
+	if len(verifier.verified) != 1 {
+		t.Errorf("verified %v, want only the endpoint change", verifier.verified)
+	}
+
+	verifier.err = nil
+	if rec := patch("?verify_endpoint=true", `{"endpoint_url":"https://example.com/v2"}`); rec.Code != http.StatusOK {
+		t.Errorf("verified update = %d: %s", rec.Code, rec.Body)
+	}
+	if got, _ := s.GetSubscriber(context.Background(), sub.ID); got.EndpointURL != "https://example.com/v2" {
+		t.Errorf("endpoint = %q, want the verified one", got.EndpointURL)
+	}
+}
+
+func TestSubscriberHandler_UpdateRejectsStaleVersions(t *testing.T) {
+	s := store.NewMemoryStore()
+	h := NewSubscriberHandler(s, nil, nil, nil, nil, nil)
+	r := chi.NewRouter()
+	r.Get("/subscribers/{id}", h.Get)
+	r.Patch("/subscribers/{id}", h.Update)
+	sub, err := s.CreateSubscriber(context.Background(), domain.CreateSubscriberRequest{
+		Name: "orders", EndpointURL: "https://example.com/hook", EventTypes: []string{"order.created"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	patch := func(ifMatch, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPatch, "/subscribers/"+sub.ID, strings.NewReader(body))
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/subscribers/"+sub.ID, nil))
+	etag := rec.Header().Get("ETag")
+	if etag != `"1"` {
+		t.Fatalf("ETag = %q, want \"1\"", etag)
+	}
+
+	// Two operators edit from the same version; the second one loses
+	if rec := patch(etag, `{"rate_limit_per_second":50}`); rec.Code != http.StatusOK || rec.Header().Get("ETag") != `"2"` {
+		t.Fatalf("first update = %d %q: %s", rec.Code, rec.Header().Get("ETag"), rec.Body)
+	}
+	if rec := patch(etag, `{"endpoint_url":"https://example.com/other"}`); rec.Code != http.StatusConflict {
+		t.Errorf("update with a stale If-Match: status = %d, want 409", rec.Code)
+	}
+	if rec := patch("", `{"endpoint_url":"https://example.com/other","version":1}`); rec.Code != http.StatusConflict {
+		t.Errorf("update with a stale version: status = %d, want 409", rec.Code)
+	}
+	got, _ := s.GetSubscriber(context.Background(), sub.ID)
+	if got.RateLimitPerSecond != 50 || got.EndpointURL != sub.EndpointURL {
+		t.Errorf("subscriber = %+v, want only the first update applied", got)
+	}
+
+	for _, tc := range []struct {
+		ifMatch, body string
+		want          int
+	}{
+		{"abc", `{"name":"orders"}`, http.StatusBadRequest},
+		{`"3"`, `{"name":"orders","version":2}`, http.StatusBadRequest},
+		{`W/"2"`, `{"name":"orders"}`, http.StatusOK},
+		{"*", `{"name":"orders"}`, http.StatusOK},
+	} {
+		if rec := patch(tc.ifMatch, tc.body); rec.Code != tc.want {
+			t.Errorf("If-Match %s: status = %d, want %d", tc.ifMatch, rec.Code, tc.want)
+		}
+	}
+
+	entries, _ := s.ListAuditEntries(context.Background(), domain.AuditFilter{EntityID: sub.ID, Action: domain.AuditSubscriberUpdate})
+	if len(entries) == 0 || strings.Contains(string(entries[0].Details), "version") {
+		t.Errorf("audit entries = %+v, want the version left out", entries)
+	}
+}
+
+func TestSubscriberHandler_ValidatesBatching(t *testing.T) {
+	s := store.NewMemoryStore()
+	h := NewSubscriberHandler(s, nil, nil, nil, nil, nil)
+	r := chi.NewRouter()
+	r.Post("/subscribers", h.Create)
+	r.Patch("/subscribers/{id}", h.Update)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/subscribers",
+		strings.NewReader(`{"name":"metrics","endpoint_url":"https://example.com/hook","event_types":["metric.*"],"batch_max_events":50}`)))
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "batch_window_seconds") {
+		t.Errorf("batching without a window: status = %d %s", rec.Code, rec.Body)
+	}
+
+	sub, _ := s.CreateSubscriber(context.Background(), domain.CreateSubscriberRequest{
+		Name: "metrics", EndpointURL: "https://example.com/hook", EventTypes: []string{"metric.*"}, BatchWindowSeconds: 5,
+	})
+	// The window already stored is enough to turn batching on
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/subscribers/"+sub.ID, strings.NewReader(`{"batch_max_events":50}`)))
+	var updated domain.Subscriber
+	json.NewDecoder(rec.Body).Decode(&updated)
+	if rec.Code != http.StatusOK || !updated.Batched() {
+		t.Errorf("enable batching: status = %d %+v", rec.Code, updated)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/subscribers/"+sub.ID, strings.NewReader(`{"batch_window_seconds":301}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("window over the cap: status = %d, want 400", rec.Code)
+	}
+}
+
+func TestSubscriberHandler_ValidatesProxyURL(t *testing.T) {
+	s := store.NewMemoryStore()
+	h := NewSubscriberHandler(s, nil, nil, nil, nil, nil)
+	r := chi.NewRouter()
+	r.Post("/subscribers", h.Create)
+	r.Patch("/subscribers/{id}", h.Update)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/subscribers",
+		strings.NewReader(`{"name":"partner","endpoint_url":"https://partner.example.com/hook","event_types":["order.*"],"proxy_url":"ftp://egress.internal"}`)))
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "scheme") {
+		t.Errorf("unsupported scheme: status = %d %s", rec.Code, rec.Body)
+	}
+
+	sub, _ := s.CreateSubscriber(context.Background(), domain.CreateSubscriberRequest{
+		Name: "partner", EndpointURL: "https://partner.example.com/hook", EventTypes: []string{"order.*"},
+	})
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/subscribers/"+sub.ID, strings.NewReader(`{"proxy_url":"socks5://egress.internal:1080"}`)))
+	var updated domain.Subscriber
+	json.NewDecoder(rec.Body).Decode(&updated)
+	if rec.Code != http.StatusOK || updated.ProxyURL != "socks5://egress.internal:1080" {
+		t.Errorf("set proxy: status = %d %+v", rec.Code, updated)
+	}
+
+	// An empty URL removes the proxy
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/subscribers/"+sub.ID, strings.NewReader(`{"proxy_url":""}`)))
+	updated = domain.Subscriber{}
+	json.NewDecoder(rec.Body).Decode(&updated)
+	if rec.Code != http.StatusOK || updated.ProxyURL != "" {
+		t.Errorf("remove proxy: status = %d %+v", rec.Code, updated)
+	}
+}
+
+func TestSubscriberHandler_SignatureFormat(t *testing.T) {
+	s := store.NewMemoryStore()
+	h := NewSubscriberHandler(s, nil, nil, nil, nil, nil)
+	r := chi.NewRouter()
+	r.Post("/subscribers", h.Create)
+	r.Patch("/subscribers/{id}", h.Update)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/subscribers",
+		strings.NewReader(`{"name":"partner","endpoint_url":"https://partner.example.com/hook","event_types":["order.*"],"signature_format":"shopify"}`)))
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "signature_format") {
+		t.Errorf("unknown format: status = %d %s", rec.Code, rec.Body)
+	}
+
+	sub, _ := s.CreateSubscriber(context.Background(), domain.CreateSubscriberRequest{
+		Name: "partner", EndpointURL: "https://partner.example.com/hook", EventTypes: []string{"order.*"},
+	})
+	if sub.SignatureFormat != domain.SignatureStandard {
+		t.Errorf("default signature format = %q, want standard", sub.SignatureFormat)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/subscribers/"+sub.ID, strings.NewReader(`{"signature_format":"github"}`)))
+	var updated domain.Subscriber
+	json.NewDecoder(rec.Body).Decode(&updated)
+	if rec.Code != http.StatusOK || updated.SignatureFormat != domain.SignatureGitHub {
+		t.Errorf("set format: status = %d %+v", rec.Code, updated)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/subscribers/"+sub.ID, strings.NewReader(`{"signature_format":"hmac"}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("update to unknown format: status = %d, want 400", rec.Code)
+	}
+}
+
+func TestSubscriberHandler_RetryJitter(t *testing.T) {
+	s := store.NewMemoryStore()
+	h := NewSubscriberHandler(s, nil, nil, nil, nil, nil)
+	r := chi.NewRouter()
+	r.Post("/subscribers", h.Create)
+	r.Patch("/subscribers/{id}", h.Update)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/subscribers",
+		strings.NewReader(`{"name":"partner","endpoint_url":"https://partner.example.com/hook","event_types":["order.*"],"retry_jitter":"random"}`)))
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "retry_jitter") {
+		t.Errorf("unknown strategy: status = %d %s", rec.Code, rec.Body)
+	}
+
+	sub, _ := s.CreateSubscriber(context.Background(), domain.CreateSubscriberRequest{
+		Name: "partner", EndpointURL: "https://partner.example.com/hook", EventTypes: []string{"order.*"},
+		RetryJitter: domain.JitterFull,
+	})
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/subscribers/"+sub.ID, strings.NewReader(`{"retry_jitter":"decorrelated"}`)))
+	var updated domain.Subscriber
+	json.NewDecoder(rec.Body).Decode(&updated)
+	if rec.Code != http.StatusOK || updated.RetryJitter != domain.JitterDecorrelated {
+		t.Errorf("set strategy: status = %d %+v", rec.Code, updated)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/subscribers/"+sub.ID, strings.NewReader(`{"retry_jitter":""}`)))
+	updated = domain.Subscriber{}
+	json.NewDecoder(rec.Body).Decode(&updated)
+	if rec.Code != http.StatusOK || updated.RetryJitter != "" {
+		t.Errorf("back to the default: status = %d %+v", rec.Code, updated)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/subscribers/"+sub.ID, strings.NewReader(`{"retry_jitter":"none"}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("update to unknown strategy: status = %d, want 400", rec.Code)
+	}
+}
+
+func TestSubscriberHandler_MaxRetryDuration(t *testing.T) {
+	s := store.NewMemoryStore()
+	h := NewSubscriberHandler(s, nil, nil, nil, nil, nil)
+	r := chi.NewRouter()
+	r.Post("/subscribers", h.Create)
+	r.Patch("/subscribers/{id}", h.Update)
+
+	for _, body := range []string{
+		`{"name":"ledger","endpoint_url":"https://ledger.example.com/hook","event_types":["payment.*"],"max_retry_duration_seconds":-1}`,
+		`{"name":"ledger","endpoint_url":"https://ledger.example.com/hook","event_types":["payment.*"],"retry_for_duration":true}`,
+	} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/subscribers", strings.NewReader(body)))
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "max_retry_duration_seconds") {
+			t.Errorf("%s: status = %d %s", body, rec.Code, rec.Body)
+		}
+	}
+
+	sub, _ := s.CreateSubscriber(context.Background(), domain.CreateSubscriberRequest{
+		Name: "ledger", EndpointURL: "https://ledger.example.com/hook", EventTypes: []string{"payment.*"},
+		MaxRetryDurationSeconds: 86400,
+	})
+
+	// Retrying for the duration is checked against the duration kept
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/subscribers/"+sub.ID, strings.NewReader(`{"retry_for_duration":true}`)))
+	var updated domain.Subscriber
+	json.NewDecoder(rec.Body).Decode(&updated)
+	if rec.Code != http.StatusOK || !updated.RetryForDuration || updated.MaxRetryDurationSeconds != 86400 {
+		t.Errorf("retry for the duration: status = %d %+v", rec.Code, updated)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/subscribers/"+sub.ID, strings.NewReader(`{"max_retry_duration_seconds":0}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("removing the duration while retrying for it: status = %d, want 400", rec.Code)
+	}
+}
+
+func TestSubscriberHandler_SuccessCondition(t *testing.T) {
+	s := store.NewMemoryStore()
+	h := NewSubscriberHandler(s, nil, nil, nil, nil, nil)
+	r := chi.NewRouter()
+	r.Post("/subscribers", h.Create)
+	r.Patch("/subscribers/{id}", h.Update)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/subscribers",
+		strings.NewReader(`{"name":"legacy","endpoint_url":"https://legacy.example.com/hook","event_types":["order.*"],"success_json_path":"status == 'ok'"}`)))
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "success_json_path") {
+		t.Errorf("invalid JSONPath: status = %d %s", rec.Code, rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/subscribers",
+		strings.NewReader(`{"name":"legacy","endpoint_url":"https://legacy.example.com/hook","event_types":["order.*"],"success_json_path":"$.status == \"ok\""}`)))
+	var created domain.CreateSubscriberResponse
+	json.NewDecoder(rec.Body).Decode(&created)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status = %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/subscribers/"+created.ID, strings.NewReader(`{"success_json_path":"","success_body_contains":"ACK"}`)))
+	var updated domain.Subscriber
+	json.NewDecoder(rec.Body).Decode(&updated)
+	if rec.Code != http.StatusOK || updated.SuccessJSONPath != "" || updated.SuccessBodyContains != "ACK" {
+		t.Errorf("switch to a substring: status = %d %+v", rec.Code, updated)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/subscribers/"+created.ID, strings.NewReader(`{"success_json_path":"$.status =="}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("update to an invalid JSONPath: status = %d, want 400", rec.Code)
+	}
+}
+
+func TestSubscriberHandler_RedirectPolicy(t *testing.T) {
+	s := store.NewMemoryStore()
+	h := NewSubscriberHandler(s, nil, nil, nil, nil, nil)
+	r := chi.NewRouter()
+	r.Post("/subscribers", h.Create)
+	r.Patch("/subscribers/{id}", h.Update)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/subscribers",
+		strings.NewReader(`{"name":"moved","endpoint_url":"https://moved.example.com/hook","event_types":["order.*"],"redirect_policy":"always"}`)))
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "redirect_policy") {
+		t.Errorf("unknown policy: status = %d %s", rec.Code, rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/subscribers",
+		strings.NewReader(`{"name":"moved","endpoint_url":"https://moved.example.com/hook","event_types":["order.*"],"redirect_policy":"never"}`)))
+	var created domain.CreateSubscriberResponse
+	json.NewDecoder(rec.Body).Decode(&created)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status = %d", rec.Code)
+	}
+	if sub, _ := s.GetSubscriber(context.Background(), created.ID); sub == nil || sub.RedirectPolicy != domain.RedirectNever {
+		t.Errorf("created subscriber = %+v, want redirect_policy never", sub)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/subscribers/"+created.ID, strings.NewReader(`{"redirect_policy":""}`)))
+	var updated domain.Subscriber
+	json.NewDecoder(rec.Body).Decode(&updated)
+	if rec.Code != http.StatusOK || updated.RedirectPolicy != "" {
+		t.Errorf("back to the default: status = %d %+v", rec.Code, updated)
+	}
+}
+
+func TestSubscriberHandler_EndpointTypes(t *testing.T) {
+	s := store.NewMemoryStore()
+	h := NewSubscriberHandler(s, nil, nil, nil, nil, nil)
+	r := chi.NewRouter()
+	r.Post("/subscribers", h.Create)
+	r.Get("/subscribers/{id}", h.Get)
+	r.Patch("/subscribers/{id}", h.Update)
+
+	for _, body := range []string{
+		`{"name":"q","endpoint_url":"https://sqs.eu-west-1.amazonaws.com/123456789012/orders","event_types":["order.*"],"endpoint_type":"kafka"}`,
+		`{"name":"q","endpoint_url":"https://sqs.eu-west-1.amazonaws.com/123456789012/orders","event_types":["order.*"],"endpoint_type":"sqs"}`,
+		`{"name":"q","endpoint_url":"https://sqs.eu-west-1.amazonaws.com/orders","event_types":["order.*"],"endpoint_type":"sqs","endpoint_credentials":{"access_key_id":"AKID","secret_access_key":"secret"}}`,
+		`{"name":"q","endpoint_url":"https://hooks.example.com","event_types":["order.*"],"endpoint_credentials":{"username":"guest"}}`,
+	} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/subscribers", strings.NewReader(body)))
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "endpoint_") {
+			t.Errorf("%s: status = %d %s, want 400", body, rec.Code, rec.Body)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/subscribers", strings.NewReader(
+		`{"name":"q","endpoint_url":"https://sqs.eu-west-1.amazonaws.com/123456789012/orders","event_types":["order.*"],"endpoint_type":"sqs","endpoint_credentials":{"access_key_id":"AKID","secret_access_key":"secret"}}`)))
+	var created domain.CreateSubscriberResponse
+	json.NewDecoder(rec.Body).Decode(&created)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status = %d %s", rec.Code, rec.Body)
+	}
+
+	// Credentials are secrets: only admins see them, and the audit log never does
+	get := func(role domain.Role) domain.Subscriber {
+		req := httptest.NewRequest(http.MethodGet, "/subscribers/"+created.ID, nil)
+		req = req.WithContext(context.WithValue(req.Context(), apiKeyContextKey{}, &domain.APIKey{Role: role}))
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		var sub domain.Subscriber
+		json.NewDecoder(rec.Body).Decode(&sub)
+		return sub
+	}
+	if sub := get(domain.RoleAdmin); sub.EndpointType != domain.EndpointSQS || sub.EndpointCredentials == nil || sub.EndpointCredentials.SecretAccessKey != "secret" {
+		t.Errorf("admin sees %+v", sub)
+	}
+	if sub := get(domain.RoleViewer); sub.EndpointType != domain.EndpointSQS || sub.EndpointCredentials != nil {
+		t.Errorf("viewer sees credentials %+v", sub.EndpointCredentials)
+	}
+	entries, _ := s.ListAuditEntries(context.Background(), domain.AuditFilter{EntityID: created.ID})
+	if len(entries) != 1 || strings.Contains(string(entries[0].Details), `"secret"`) || !strings.Contains(string(entries[0].Details), "AKID") {
+		t.Errorf("audit entries = %s, want the secret access key redacted", entries[0].Details)
+	}
+
+	// Changes are validated against the settings kept
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/subscribers/"+created.ID, strings.NewReader(`{"endpoint_url":"https://hooks.example.com"}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("HTTP URL on an sqs endpoint: status = %d, want 400", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/subscribers/"+created.ID+"?verify_endpoint=true",
+		strings.NewReader(`{"endpoint_url":"https://sqs.eu-west-1.amazonaws.com/123456789012/invoices"}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("verifying a queue: status = %d, want 400", rec.Code)
+	}
+
+	// Going back to HTTP drops the credentials
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/subscribers/"+created.ID, strings.NewReader(`{"endpoint_type":"http","endpoint_url":"https://hooks.example.com"}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("back to http: status = %d %s", rec.Code, rec.Body)
+	}
+	if sub, _ := s.GetSubscriber(context.Background(), created.ID); sub.EndpointType != domain.EndpointHTTP || sub.EndpointCredentials != nil {
+		t.Errorf("subscriber = %+v, want an http endpoint without credentials", sub)
+	}
+	entries, _ = s.ListAuditEntries(context.Background(), domain.AuditFilter{EntityID: created.ID, Action: domain.AuditSubscriberUpdate})
+	if len(entries) != 1 || strings.Contains(string(entries[0].Details), `"secret"`) {
+		t.Errorf("audit entries = %+v, want the update without the secret", entries)
+	}
+}
+
+func TestSubscriberHandler_EventVersions(t *testing.T) {
+	s := store.NewMemoryStore()
+	h := NewSubscriberHandler(s, nil, nil, nil, nil, nil)
+	r := chi.NewRouter()
+	r.Post("/subscribers", h.Create)
+	r.Patch("/subscribers/{id}", h.Update)
+
+	for _, versions := range []string{`{"order.*":1}`, `{"order.created":0}`} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/subscribers",
+			strings.NewReader(`{"name":"partner","endpoint_url":"https://partner.example.com/hook","event_types":["order.*"],"event_versions":`+versions+`}`)))
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "event_versions") {
+			t.Errorf("event_versions %s: status = %d %s, want 400", versions, rec.Code, rec.Body)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/subscribers",
+		strings.NewReader(`{"name":"partner","endpoint_url":"https://partner.example.com/hook","event_types":["order.*"],"event_versions":{"order.created":1}}`)))
+	var created domain.CreateSubscriberResponse
+	json.NewDecoder(rec.Body).Decode(&created)
+	if sub, _ := s.GetSubscriber(context.Background(), created.ID); rec.Code != http.StatusCreated || sub.EventVersions["order.created"] != 1 {
+		t.Fatalf("create: status = %d, subscriber = %+v", rec.Code, sub)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/subscribers/"+created.ID, strings.NewReader(`{"event_versions":{}}`)))
+	var updated domain.Subscriber
+	json.NewDecoder(rec.Body).Decode(&updated)
+	if rec.Code != http.StatusOK || len(updated.EventVersions) != 0 {
+		t.Errorf("unpin: status = %d, event versions = %v, want none", rec.Code, updated.EventVersions)
+	}
+}
+
+func TestSubscriberHandler_Sandbox(t *testing.T) {
+	s := store.NewMemoryStore()
+	h := NewSubscriberHandler(s, nil, nil, nil, nil, nil)
+	r := chi.NewRouter()
+	r.Post("/subscribers", h.Create)
+	r.Patch("/subscribers/{id}", h.Update)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/subscribers",
+		strings.NewReader(`{"name":"partner","event_types":["order.*"]}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("no endpoint outside the sandbox: status = %d, want 400", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/subscribers",
+		strings.NewReader(`{"name":"partner","event_types":["order.*"],"sandbox":true}`)))
+	var created domain.CreateSubscriberResponse
+	json.NewDecoder(rec.Body).Decode(&created)
+	if sub, _ := s.GetSubscriber(context.Background(), created.ID); rec.Code != http.StatusCreated || !sub.Sandbox {
+		t.Fatalf("sandbox without an endpoint: status = %d, subscriber = %+v", rec.Code, sub)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/subscribers/"+created.ID, strings.NewReader(`{"sandbox":false}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("leaving the sandbox without an endpoint: status = %d, want 400", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/subscribers/"+created.ID,
+		strings.NewReader(`{"sandbox":false,"endpoint_url":"https://partner.example.com/hook"}`)))
+	var updated domain.Subscriber
+	json.NewDecoder(rec.Body).Decode(&updated)
+	if rec.Code != http.StatusOK || updated.Sandbox {
+		t.Errorf("leaving the sandbox: status = %d %+v", rec.Code, updated)
+	}
+}
+
+func TestSubscriberHandler_Quotas(t *testing.T) {
+	s := store.NewMemoryStore()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	quota := engine.NewDeliveryQuota(client, slog.Default())
+	h := NewSubscriberHandler(s, nil, quota, nil, nil, nil)
+	r := chi.NewRouter()
+	r.Post("/subscribers", h.Create)
+	r.Patch("/subscribers/{id}", h.Update)
+	r.Get("/subscribers/{id}/stats", h.Stats)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/subscribers",
+		strings.NewReader(`{"name":"partner","endpoint_url":"https://partner.example.com/hook","event_types":["order.*"],"daily_quota":-1}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("negative quota: status = %d, want 400", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/subscribers",
+		strings.NewReader(`{"name":"partner","endpoint_url":"https://partner.example.com/hook","event_types":["order.*"],"daily_quota":1000}`)))
+	var created domain.CreateSubscriberResponse
+	json.NewDecoder(rec.Body).Decode(&created)
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/subscribers/"+created.ID, strings.NewReader(`{"monthly_quota":20000}`)))
+	var updated domain.Subscriber
+	json.NewDecoder(rec.Body).Decode(&updated)
+	if rec.Code != http.StatusOK || updated.DailyQuota != 1000 || updated.MonthlyQuota != 20000 {
+		t.Fatalf("update = %d %+v", rec.Code, updated)
+	}
+
+	quota.Take(context.Background(), created.ID, 1000, 20000, 3)
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/subscribers/"+created.ID+"/stats", nil))
+	var stats struct {
+		Quota *domain.QuotaUsage `json:"quota"`
+	}
+	json.NewDecoder(rec.Body).Decode(&stats)
+	if rec.Code != http.StatusOK || stats.Quota == nil || stats.Quota.Daily.Used != 3 || stats.Quota.Monthly.Limit != 20000 {
+		t.Errorf("stats = %d %+v", rec.Code, stats.Quota)
+	}
+}
+
+func TestSubscriberHandler_DeleteAndRestore(t *testing.T) {
+	s := outboxStubStore{store.NewMemoryStore()}
+	queue := engine.NewMemoryQueue()
+	client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	cb := engine.NewCircuitBreaker(client, slog.Default())
+	cleanup := engine.NewSubscriberCleanup(s, queue, cb, engine.NewRateLimiter(client, slog.Default()), slog.Default())
+	h := NewSubscriberHandler(s, cb, nil, engine.NewFanOutEngine(s, queue, nil, slog.Default()), cleanup, nil)
+	ctx := context.Background()
+
+	r := chi.NewRouter()
+	r.Get("/subscribers", h.List)
+	r.Patch("/subscribers/{id}", h.Update)
+	r.Delete("/subscribers/{id}", h.Delete)
+	r.Post("/subscribers/{id}/restore", h.Restore)
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	sub, _ := s.CreateSubscriber(ctx, domain.CreateSubscriberRequest{
+		Name: "orders", EndpointURL: "https://example.com/hook", EventTypes: []string{"order.*"},
+	})
+	queue.Enqueue(ctx, engine.DeliveryJob{EventID: "evt-1", SubscriberID: sub.ID, Attempt: 1}, time.Now().Add(time.Hour))
+	s.InsertDeadLetter(ctx, store.DeadLetterRecord{EventID: "evt-0", SubscriberID: sub.ID, TotalAttempts: 5})
+	for i := 0; i < 5; i++ {
+		cb.RecordFailure(ctx, sub.ID)
+	}
+
+	if rec := do(http.MethodDelete, "/subscribers/"+sub.ID+"?resolve_dead_letters=maybe", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid resolve_dead_letters: status = %d, want 400", rec.Code)
+	}
+	rec := do(http.MethodDelete, "/subscribers/"+sub.ID+"?resolve_dead_letters=true", "")
+	var deleted deleteSubscriberResponse
+	json.NewDecoder(rec.Body).Decode(&deleted)
+	if rec.Code != http.StatusOK || deleted.JobsPurged != 1 || deleted.DeadLettersResolved != 1 || deleted.DeletedAt.IsZero() {
+		t.Fatalf("delete = %d %+v", rec.Code, deleted)
+	}
+	if pending, _ := queue.PendingJobs(ctx, sub.ID); len(pending) != 0 {
+		t.Errorf("pending jobs = %+v, want them purged", pending)
+	}
+	if state := cb.GetState(ctx, sub.ID); state.State != engine.StateClosed || state.Failures != 0 {
+		t.Errorf("circuit = %+v, want it reset", state)
+	}
+	letters, _ := s.ListDeadLetters(ctx, store.DeadLetterFilter{SubscriberID: sub.ID})
+	if len(letters) != 1 || letters[0].ResolvedBy == nil || *letters[0].ResolvedBy != domain.DeadLetterSubscriberRemoved {
+		t.Errorf("dead letters = %+v, want them resolved", letters)
+	}
+	if matches, _ := s.FindMatchingSubscribers(ctx, "order.created"); len(matches) != 0 {
+		t.Errorf("deleted subscriber matched %d times", len(matches))
+	}
+	var listed []domain.Subscriber
+	json.NewDecoder(do(http.MethodGet, "/subscribers", "").Body).Decode(&listed)
+	if len(listed) != 0 {
+		t.Errorf("list = %+v, want the deleted subscriber left out", listed)
+	}
+	if rec := do(http.MethodDelete, "/subscribers/"+sub.ID, ""); rec.Code != http.StatusConflict {
+		t.Errorf("second delete: status = %d, want 409", rec.Code)
+	}
+	if rec := do(http.MethodPatch, "/subscribers/"+sub.ID, `{"name":"renamed"}`); rec.Code != http.StatusConflict {
+		t.Errorf("update while deleted: status = %d, want 409", rec.Code)
+	}
+
+	rec = do(http.MethodPost, "/subscribers/"+sub.ID+"/restore", "")
+	var restored domain.Subscriber
+	json.NewDecoder(rec.Body).Decode(&restored)
+	if rec.Code != http.StatusOK || restored.DeletedAt != nil {
+		t.Fatalf("restore = %d %+v", rec.Code, restored)
+	}
+	if matches, _ := s.FindMatchingSubscribers(ctx, "order.created"); len(matches) != 1 {
+		t.Errorf("restored subscriber matched %d times, want 1", len(matches))
+	}
+	if rec := do(http.MethodPost, "/subscribers/"+sub.ID+"/restore", ""); rec.Code != http.StatusConflict {
+		t.Errorf("restoring a live subscriber: status = %d, want 409", rec.Code)
+	}
+
+	// Deactivating cleans up too, without resolving dead letters by default
+	queue.Enqueue(ctx, engine.DeliveryJob{EventID: "evt-2", SubscriberID: sub.ID, Attempt: 1}, time.Now().Add(time.Hour))
+	if rec := do(http.MethodPatch, "/subscribers/"+sub.ID, `{"is_active":false}`); rec.Code != http.StatusOK {
+		t.Fatalf("deactivate status = %d: %s", rec.Code, rec.Body)
+	}
+	if pending, _ := queue.PendingJobs(ctx, sub.ID); len(pending) != 0 {
+		t.Errorf("pending jobs after deactivation = %+v, want them purged", pending)
+	}
+
+	entries, _ := s.ListAuditEntries(ctx, domain.AuditFilter{EntityID: sub.ID})
+	var actions []string
+	for _, e := range entries {
+		actions = append(actions, e.Action)
+	}
+	want := []string{domain.AuditSubscriberCleanup, domain.AuditSubscriberUpdate, domain.AuditSubscriberRestore, domain.AuditSubscriberDelete}
+	if !slices.Equal(actions, want) {
+		t.Errorf("audit actions = %v, want %v", actions, want)
+	}
+	if !strings.Contains(string(entries[3].Details), `"dead_letters_resolved":1`) {
+		t.Errorf("delete audit details = %s", entries[3].Details)
+	}
+}
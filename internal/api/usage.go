@@ -0,0 +1,99 @@
+package api
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+)
+
+// maxUsageDays caps the days one usage request covers.
+const maxUsageDays = 366
+
+type UsageHandler struct {
+	store store.UsageStore
+	now   func() time.Time
+}
+
+func NewUsageHandler(s store.UsageStore) *UsageHandler {
+	return &UsageHandler{store: s, now: time.Now}
+}
+
+// usageResponse is the usage of each subscriber over a range of days, both
+// summed and day by day.
+type usageResponse struct {
+	From   string            `json:"from"`
+	To     string            `json:"to"`
+	Totals []domain.Usage    `json:"totals"`
+	Days   []domain.UsageDay `json:"days"`
+}
+
+// List reports metered usage between the from and to days (YYYY-MM-DD,
+// both included, UTC), by default the current month so far. format=csv
+// downloads the days as CSV instead, in the layout of the scheduled export.
+func (h *UsageHandler) List(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	today := h.now().UTC().Truncate(24 * time.Hour)
+	from := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := today
+
+	var err error
+	if v := q.Get("from"); v != "" {
+		if from, err = time.Parse(time.DateOnly, v); err != nil {
+			respondError(w, http.StatusBadRequest, "from must be a date like 2006-01-02")
+			return
+		}
+	}
+	if v := q.Get("to"); v != "" {
+		if to, err = time.Parse(time.DateOnly, v); err != nil {
+			respondError(w, http.StatusBadRequest, "to must be a date like 2006-01-02")
+			return
+		}
+	}
+	if to.Before(from) {
+		respondError(w, http.StatusBadRequest, "to must not be before from")
+		return
+	}
+	if days := int(to.Sub(from).Hours()/24) + 1; days > maxUsageDays {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("range covers %d days, at most %d are allowed", days, maxUsageDays))
+		return
+	}
+	format := q.Get("format")
+	if format != "" && format != "json" && format != "csv" {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("format must be json or csv, got %q", format))
+		return
+	}
+
+	days, err := h.store.ListUsage(r.Context(), store.UsageFilter{
+		SubscriberID: q.Get("subscriber_id"),
+		From:         from,
+		To:           to,
+	})
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list usage")
+		return
+	}
+
+	if format == "csv" {
+		filename := fmt.Sprintf("usage-%s-%s.csv", from.Format(time.DateOnly), to.Format(time.DateOnly))
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+		out := csv.NewWriter(w)
+		out.Write(domain.UsageCSVHeader)
+		for _, d := range days {
+			out.Write(d.CSVRecord())
+		}
+		out.Flush()
+		return
+	}
+
+	respondJSON(w, http.StatusOK, usageResponse{
+		From:   from.Format(time.DateOnly),
+		To:     to.Format(time.DateOnly),
+		Totals: domain.UsageTotals(days),
+		Days:   days,
+	})
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+)
+
+func TestUsageHandler_List(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewMemoryStore()
+	billing, _ := s.CreateSubscriber(ctx, domain.CreateSubscriberRequest{Name: "billing", EndpointURL: "https://billing.example.com/hook"})
+	search, _ := s.CreateSubscriber(ctx, domain.CreateSubscriberRequest{Name: "search", EndpointURL: "https://search.example.com/hook"})
+	s.InsertDeliveryAttempts(ctx, []store.DeliveryAttemptRecord{
+		{SubscriberID: search.ID, AttemptNumber: 1, Status: "failed", PayloadBytes: 10},
+		{SubscriberID: search.ID, AttemptNumber: 2, Status: "success", PayloadBytes: 10},
+		{SubscriberID: billing.ID, AttemptNumber: 1, Status: "success", PayloadBytes: 25},
+	})
+	h := NewUsageHandler(s)
+
+	get := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.List(rec, httptest.NewRequest(http.MethodGet, "/api/v1/usage"+query, nil))
+		return rec
+	}
+
+	rec := get("")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	var report usageResponse
+	json.NewDecoder(rec.Body).Decode(&report)
+	today := time.Now().UTC()
+	if report.From != today.Format("2006-01")+"-01" || report.To != today.Format(time.DateOnly) {
+		t.Errorf("range = %s to %s, want the month so far", report.From, report.To)
+	}
+	if len(report.Days) != 2 || len(report.Totals) != 2 {
+		t.Fatalf("report = %+v, want a day and a total per subscriber", report)
+	}
+	want := domain.Usage{SubscriberID: search.ID, SubscriberName: "search", Deliveries: 2, Successful: 1, Retries: 1, PayloadBytes: 20}
+	if report.Totals[0].SubscriberName != "billing" || report.Totals[1] != want {
+		t.Errorf("totals = %+v, want billing then %+v", report.Totals, want)
+	}
+
+	rec = get("?format=csv&subscriber_id=" + billing.ID)
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	if rec.Header().Get("Content-Type") != "text/csv; charset=utf-8" || len(lines) != 2 || !strings.HasSuffix(lines[1], ",billing,1,1,0,25") {
+		t.Errorf("csv = %q", rec.Body)
+	}
+
+	for _, query := range []string{"?from=yesterday", "?from=2024-05-02&to=2024-05-01", "?from=2023-01-01&to=2024-05-01", "?format=xml"} {
+		if rec := get(query); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", query, rec.Code)
+		}
+	}
+}
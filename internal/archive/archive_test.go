@@ -0,0 +1,239 @@
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/sigv4"
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+)
+
+var testLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func TestS3Client_PutAndGetObject(t *testing.T) {
+	objects := map[string][]byte{}
+	var authHeader, contentHash string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeader = r.Header.Get("Authorization")
+		contentHash = r.Header.Get("X-Amz-Content-Sha256")
+
+		switch r.Method {
+		case http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			objects[r.URL.Path] = body
+			w.WriteHeader(http.StatusOK)
+		case http.MethodGet:
+			body, ok := objects[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write(body)
+		}
+	}))
+	defer server.Close()
+
+	client := NewS3Client(S3Config{
+		Endpoint:  server.URL + "/",
+		Bucket:    "audit",
+		AccessKey: "AKIDEXAMPLE",
+		SecretKey: "secret",
+	})
+	client.now = func() time.Time { return time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC) }
+
+	ctx := context.Background()
+	body := []byte("hello archive")
+
+	if err := client.PutObject(ctx, "prefix/events/a b.ndjson.gz", body, "application/x-ndjson"); err != nil {
+		t.Fatalf("PutObject failed: %v", err)
+	}
+
+	if _, ok := objects["/audit/prefix/events/a b.ndjson.gz"]; !ok {
+		t.Fatalf("object not stored at path-style URL, got keys %v", objects)
+	}
+	if !strings.HasPrefix(authHeader, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20240501/us-east-1/s3/aws4_request") {
+		t.Errorf("unexpected Authorization header: %s", authHeader)
+	}
+	if contentHash != sigv4.PayloadHash(body) {
+		t.Errorf("X-Amz-Content-Sha256 = %q, want %q", contentHash, sigv4.PayloadHash(body))
+	}
+
+	rc, err := client.GetObject(ctx, "prefix/events/a b.ndjson.gz")
+	if err != nil {
+		t.Fatalf("GetObject failed: %v", err)
+	}
+	defer rc.Close()
+
+	got, _ := io.ReadAll(rc)
+	if !bytes.Equal(got, body) {
+		t.Errorf("GetObject body = %q, want %q", got, body)
+	}
+}
+
+func TestS3Client_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte("<Error>AccessDenied</Error>"))
+	}))
+	defer server.Close()
+
+	client := NewS3Client(S3Config{Endpoint: server.URL, Bucket: "audit"})
+
+	err := client.PutObject(context.Background(), "key", []byte("x"), "")
+	if err == nil {
+		t.Fatal("expected error for 403 response")
+	}
+	if !strings.Contains(err.Error(), "AccessDenied") {
+		t.Errorf("error should include response body, got: %v", err)
+	}
+}
+
+func TestEncodeNDJSON_RoundTrip(t *testing.T) {
+	type row struct {
+		ID    string `json:"id"`
+		Count int    `json:"count"`
+	}
+	rows := []row{{"a", 1}, {"b", 2}, {"c", 3}}
+
+	data, err := encodeNDJSON(rows)
+	if err != nil {
+		t.Fatalf("encodeNDJSON failed: %v", err)
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("output is not gzip: %v", err)
+	}
+
+	scanner := bufio.NewScanner(gz)
+	var decoded []row
+	for scanner.Scan() {
+		var r row
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			t.Fatalf("line is not valid JSON: %v", err)
+		}
+		decoded = append(decoded, r)
+	}
+
+	if len(decoded) != len(rows) {
+		t.Fatalf("expected %d lines, got %d", len(rows), len(decoded))
+	}
+	for i := range rows {
+		if decoded[i] != rows[i] {
+			t.Errorf("row %d: got %+v, want %+v", i, decoded[i], rows[i])
+		}
+	}
+}
+
+func TestObjectKey(t *testing.T) {
+	newest := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	key := objectKey("webhook-archive", "events", newest, "evt-1")
+
+	want := "webhook-archive/events/2024/05/01/20240501T123000Z-evt-1.ndjson.gz"
+	if key != want {
+		t.Errorf("objectKey = %q, want %q", key, want)
+	}
+}
+
+func TestExpiredPartitions(t *testing.T) {
+	month := func(m time.Month) *time.Time {
+		d := time.Date(2026, m, 1, 0, 0, 0, 0, time.UTC)
+		return &d
+	}
+	legacy := store.Partition{Table: "events", Name: "events_legacy", To: month(3)}
+	march := store.Partition{Table: "events", Name: "events_p2026_03", From: month(3), To: month(4)}
+	april := store.Partition{Table: "events", Name: "events_p2026_04", From: month(4), To: month(5)}
+	partitions := []store.Partition{legacy, march, april}
+
+	tests := []struct {
+		name       string
+		partitions []store.Partition
+		cutoff     time.Time
+		expired    []string
+		rowCutoff  time.Time
+	}{
+		{"unpartitioned", nil, *month(2), nil, *month(2)},
+		{"legacy rows only", partitions, month(2).AddDate(0, 0, 10), nil, month(2).AddDate(0, 0, 10)},
+		{"legacy expired", partitions, month(3).AddDate(0, 0, 10), []string{"events_legacy"}, time.Time{}},
+		{"whole months", partitions, *month(4), []string{"events_legacy", "events_p2026_03"}, time.Time{}},
+		{"legacy already dropped", []store.Partition{march, april}, *month(2), nil, time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expired, rowCutoff := expiredPartitions(tt.partitions, tt.cutoff)
+			var names []string
+			for _, p := range expired {
+				names = append(names, p.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.expired, ",") {
+				t.Errorf("expired = %v, want %v", names, tt.expired)
+			}
+			if !rowCutoff.Equal(tt.rowCutoff) {
+				t.Errorf("rowCutoff = %v, want %v", rowCutoff, tt.rowCutoff)
+			}
+		})
+	}
+}
+
+func TestUsageExporter_ExportsCompletedDays(t *testing.T) {
+	objects := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		objects[r.URL.Path] = string(body)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	s := store.NewMemoryStore()
+	sub, _ := s.CreateSubscriber(ctx, domain.CreateSubscriberRequest{Name: "billing", EndpointURL: "https://billing.example.com/hook"})
+	s.RecordDeliveryAttempt(ctx, store.DeliveryAttemptRecord{SubscriberID: sub.ID, AttemptNumber: 2, Status: "success", PayloadBytes: 512})
+
+	client := NewS3Client(S3Config{Endpoint: server.URL, Bucket: "finance", AccessKey: "k", SecretKey: "s"})
+	e := NewUsageExporter(s, client, "exports", testLogger)
+
+	// Today's usage is exported once the day is over and the delay passed
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	e.now = func() time.Time { return today.AddDate(0, 0, 1).Add(usageExportDelay - time.Second) }
+	if err := e.RunOnce(ctx); err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+	if len(objects) != usageExportBackfill {
+		t.Fatalf("first pass exported %d days, want the %d before today", len(objects), usageExportBackfill)
+	}
+	key := "/finance/" + usageObjectKey("exports", today)
+	if _, ok := objects[key]; ok {
+		t.Fatal("today exported before it was over")
+	}
+
+	e.now = func() time.Time { return today.AddDate(0, 0, 1).Add(usageExportDelay) }
+	if err := e.RunOnce(ctx); err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+	if len(objects) != usageExportBackfill+1 {
+		t.Fatalf("second pass left %d objects, want only today added", len(objects))
+	}
+	want := "day,subscriber_id,subscriber_name,deliveries,successful,retries,payload_bytes\n" +
+		today.Format(time.DateOnly) + "," + sub.ID + ",billing,1,1,1,512\n"
+	if objects[key] != want {
+		t.Errorf("export of today = %q, want %q", objects[key], want)
+	}
+}
+
+func TestUsageObjectKey(t *testing.T) {
+	got := usageObjectKey("webhook-archive", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC))
+	if want := "webhook-archive/usage/2024/05/2024-05-01.csv"; got != want {
+		t.Errorf("usageObjectKey = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,365 @@
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+)
+
+// Archiver periodically prunes events and delivery attempts that are older than
+// the retention window. When an object store is configured, each batch is first
+// exported as gzip-compressed NDJSON and a manifest row is recorded so the data
+// can still be retrieved after it leaves Postgres.
+//
+// Rows are only deleted after their archive object has been uploaded and its
+// manifest committed. If deletion fails, the next run re-archives the same rows
+// into a new object, so archives are at-least-once.
+type Archiver struct {
+	pgStore   *store.PostgresStore
+	s3        *S3Client
+	prefix    string
+	retention time.Duration
+	interval  time.Duration
+	batchSize int
+	logger    *slog.Logger
+}
+
+// NewArchiver creates an archiver. s3 may be nil, in which case aged rows are
+// pruned without being exported.
+func NewArchiver(pgStore *store.PostgresStore, s3 *S3Client, prefix string, retention time.Duration, logger *slog.Logger) *Archiver {
+	return &Archiver{
+		pgStore:   pgStore,
+		s3:        s3,
+		prefix:    prefix,
+		retention: retention,
+		interval:  1 * time.Hour,
+		batchSize: 1000,
+		logger:    logger,
+	}
+}
+
+// Start runs an archive pass immediately and then on every interval until the
+// context is cancelled.
+func (a *Archiver) Start(ctx context.Context) {
+	a.logger.Info("archiver started",
+		"retention", a.retention.String(),
+		"export_enabled", a.s3 != nil,
+	)
+
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := a.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			a.logger.Error("archive pass failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			a.logger.Info("archiver stopping")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce archives and prunes everything older than the retention window.
+// Delivery attempts are processed first so that events they reference become
+// eligible for pruning in the same pass.
+//
+// Monthly partitions are dropped whole once their month is entirely older
+// than the window, so their rows are kept up to a month longer. Only rows
+// from before the tables were partitioned are deleted row by row.
+func (a *Archiver) RunOnce(ctx context.Context) error {
+	cutoff := time.Now().Add(-a.retention)
+
+	var attempts, events int64
+	var dropped []string
+	for _, table := range store.PartitionedTables {
+		rowCutoff, names, rows, err := a.dropPartitions(ctx, table, cutoff)
+		if err != nil {
+			return err
+		}
+		dropped = append(dropped, names...)
+
+		var pruned int64
+		if !rowCutoff.IsZero() {
+			if table == "events" {
+				pruned, err = a.pruneEvents(ctx, rowCutoff)
+			} else {
+				pruned, err = a.pruneDeliveryAttempts(ctx, rowCutoff)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if table == "events" {
+			events = rows + pruned
+		} else {
+			attempts = rows + pruned
+		}
+	}
+
+	if attempts > 0 || events > 0 || len(dropped) > 0 {
+		a.logger.Info("archive pass complete",
+			"cutoff", cutoff.Format(time.RFC3339),
+			"delivery_attempts_pruned", attempts,
+			"events_pruned", events,
+			"partitions_dropped", dropped,
+		)
+	}
+	return nil
+}
+
+// dropPartitions drops the partitions of table that are entirely older than
+// cutoff, exporting their rows first, and returns how many rows were
+// exported. rowCutoff is what is left to prune row by row: rows before
+// cutoff in the partition holding the rows from before partitioning, or
+// zero if there are none.
+func (a *Archiver) dropPartitions(ctx context.Context, table string, cutoff time.Time) (rowCutoff time.Time, dropped []string, rows int64, err error) {
+	partitions, err := a.pgStore.ListPartitions(ctx, table)
+	if err != nil {
+		return time.Time{}, nil, 0, err
+	}
+	expired, rowCutoff := expiredPartitions(partitions, cutoff)
+
+	for _, p := range expired {
+		n, err := a.dropPartition(ctx, p)
+		if errors.Is(err, store.ErrPartitionInUse) {
+			a.logger.Warn("keeping expired partition until its fan-outs finish", "partition", p.Name)
+			continue
+		}
+		if err != nil {
+			return time.Time{}, dropped, rows, err
+		}
+		dropped = append(dropped, p.Name)
+		rows += n
+	}
+	return rowCutoff, dropped, rows, nil
+}
+
+// expiredPartitions returns the partitions entirely older than cutoff, and
+// the cutoff for pruning the rest row by row. An unpartitioned table is
+// pruned up to cutoff; otherwise only the partition holding the rows from
+// before partitioning is, and rowCutoff is zero once it has been dropped.
+func expiredPartitions(partitions []store.Partition, cutoff time.Time) (expired []store.Partition, rowCutoff time.Time) {
+	if len(partitions) == 0 {
+		return nil, cutoff
+	}
+	for _, p := range partitions {
+		if p.To != nil && !p.To.After(cutoff) {
+			expired = append(expired, p)
+			continue
+		}
+		if p.From == nil {
+			rowCutoff = cutoff
+		}
+	}
+	return expired, rowCutoff
+}
+
+// dropPartition exports a partition's rows, when an object store is
+// configured, and drops it. It returns the number of rows exported.
+func (a *Archiver) dropPartition(ctx context.Context, p store.Partition) (int64, error) {
+	var n int64
+	var err error
+	if a.s3 != nil {
+		if p.Table == "events" {
+			n, err = exportPartition(ctx, a, p, a.pgStore.ListPartitionEvents, func(e domain.Event) (time.Time, string) {
+				return e.CreatedAt, e.ID
+			})
+		} else {
+			n, err = exportPartition(ctx, a, p, a.pgStore.ListPartitionDeliveryAttempts, func(at domain.DeliveryAttempt) (time.Time, string) {
+				return at.CreatedAt, at.ID
+			})
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err := a.pgStore.DropPartition(ctx, p); err != nil {
+		return 0, err
+	}
+	a.logger.Info("dropped expired partition", "partition", p.Name, "rows_exported", n)
+	return n, nil
+}
+
+// exportPartition exports every row of a partition in batches.
+func exportPartition[T any](ctx context.Context, a *Archiver, p store.Partition,
+	list func(ctx context.Context, p store.Partition, afterTime time.Time, afterID string, limit int) ([]T, error),
+	key func(T) (time.Time, string),
+) (int64, error) {
+	var total int64
+	var afterTime time.Time
+	var afterID string
+	for {
+		rows, err := list(ctx, p, afterTime, afterID, a.batchSize)
+		if err != nil {
+			return total, err
+		}
+		if len(rows) == 0 {
+			return total, nil
+		}
+
+		oldest, firstID := key(rows[0])
+		afterTime, afterID = key(rows[len(rows)-1])
+		if err := export(ctx, a, p.Table, rows, firstID, oldest, afterTime); err != nil {
+			return total, err
+		}
+		total += int64(len(rows))
+
+		if len(rows) < a.batchSize {
+			return total, nil
+		}
+	}
+}
+
+func (a *Archiver) pruneDeliveryAttempts(ctx context.Context, cutoff time.Time) (int64, error) {
+	var total int64
+	for {
+		attempts, err := a.pgStore.ListDeliveryAttemptsBefore(ctx, cutoff, a.batchSize)
+		if err != nil {
+			return total, err
+		}
+		if len(attempts) == 0 {
+			return total, nil
+		}
+
+		ids := make([]string, len(attempts))
+		for i, at := range attempts {
+			ids[i] = at.ID
+		}
+
+		oldest, newest := attempts[0].CreatedAt, attempts[len(attempts)-1].CreatedAt
+		if err := export(ctx, a, "delivery_attempts", attempts, ids[0], oldest, newest); err != nil {
+			return total, err
+		}
+
+		deleted, err := a.pgStore.DeleteDeliveryAttempts(ctx, ids)
+		if err != nil {
+			return total, err
+		}
+		total += deleted
+
+		if len(attempts) < a.batchSize {
+			return total, nil
+		}
+	}
+}
+
+func (a *Archiver) pruneEvents(ctx context.Context, cutoff time.Time) (int64, error) {
+	var total int64
+	for {
+		events, err := a.pgStore.ListPrunableEvents(ctx, cutoff, a.batchSize)
+		if err != nil {
+			return total, err
+		}
+		if len(events) == 0 {
+			return total, nil
+		}
+
+		ids := make([]string, len(events))
+		for i, e := range events {
+			ids[i] = e.ID
+		}
+
+		oldest, newest := events[0].CreatedAt, events[len(events)-1].CreatedAt
+		if err := export(ctx, a, "events", events, ids[0], oldest, newest); err != nil {
+			return total, err
+		}
+
+		deleted, err := a.pgStore.DeleteEvents(ctx, ids)
+		if err != nil {
+			return total, err
+		}
+		total += deleted
+
+		if len(events) < a.batchSize {
+			return total, nil
+		}
+	}
+}
+
+// export uploads a batch of rows as gzip-compressed NDJSON and records its
+// manifest. It is a no-op when no object store is configured.
+func export[T any](ctx context.Context, a *Archiver, table string, rows []T, firstID string, oldest, newest time.Time) error {
+	if a.s3 == nil {
+		return nil
+	}
+
+	data, err := encodeNDJSON(rows)
+	if err != nil {
+		return err
+	}
+
+	key := objectKey(a.prefix, table, newest, firstID)
+	if err := a.s3.PutObject(ctx, key, data, "application/x-ndjson"); err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(data)
+	manifest := &domain.ArchiveManifest{
+		TableName: table,
+		Bucket:    a.s3.Bucket(),
+		ObjectKey: key,
+		RowCount:  len(rows),
+		ByteSize:  int64(len(data)),
+		SHA256:    hex.EncodeToString(sum[:]),
+		OldestAt:  oldest,
+		NewestAt:  newest,
+	}
+	if err := a.pgStore.InsertArchiveManifest(ctx, manifest); err != nil {
+		return err
+	}
+
+	a.logger.Info("archived batch",
+		"table", table,
+		"object_key", key,
+		"rows", len(rows),
+		"bytes", len(data),
+	)
+	return nil
+}
+
+// encodeNDJSON writes one JSON document per line and gzips the result.
+func encodeNDJSON[T any](rows []T) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	w := bufio.NewWriter(gz)
+	enc := json.NewEncoder(w)
+
+	for _, row := range rows {
+		if err := enc.Encode(row); err != nil {
+			return nil, fmt.Errorf("encoding row: %w", err)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return nil, fmt.Errorf("flushing archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("compressing archive: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// objectKey builds a date-partitioned key such as
+// webhook-archive/events/2024/05/01/20240501T120000Z-<id>.ndjson.gz.
+func objectKey(prefix, table string, newest time.Time, firstID string) string {
+	newest = newest.UTC()
+	name := fmt.Sprintf("%s-%s.ndjson.gz", newest.Format("20060102T150405Z"), firstID)
+	return path.Join(prefix, table, newest.Format("2006/01/02"), name)
+}
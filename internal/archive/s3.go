@@ -0,0 +1,118 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/sigv4"
+)
+
+// S3Config holds connection settings for an S3-compatible object store.
+type S3Config struct {
+	Endpoint  string // e.g. https://s3.us-east-1.amazonaws.com or http://minio:9000
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+}
+
+// S3Client is a minimal S3-compatible client that supports the two operations
+// the archiver needs: uploading an object and reading it back. Requests use
+// path-style addressing and are signed with AWS Signature Version 4, which is
+// accepted by AWS S3, MinIO, Ceph, R2 and most other compatible stores.
+type S3Client struct {
+	cfg        S3Config
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+func NewS3Client(cfg S3Config) *S3Client {
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	cfg.Endpoint = strings.TrimRight(cfg.Endpoint, "/")
+
+	return &S3Client{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+		now:        time.Now,
+	}
+}
+
+// Bucket returns the bucket objects are written to.
+func (c *S3Client) Bucket() string {
+	return c.cfg.Bucket
+}
+
+// PutObject uploads body under the given key.
+func (c *S3Client) PutObject(ctx context.Context, key string, body []byte, contentType string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, c.objectURL(key), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating put request: %w", err)
+	}
+	req.ContentLength = int64(len(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	c.sign(req, body)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("uploading object %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("uploading object %s: status %d: %s", key, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// GetObject returns a reader for the object stored under key.
+// The caller must close the returned reader.
+func (c *S3Client) GetObject(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.objectURL(key), nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating get request: %w", err)
+	}
+	c.sign(req, nil)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching object %s: %w", key, err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("fetching object %s: status %d: %s", key, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return resp.Body, nil
+}
+
+func (c *S3Client) objectURL(key string) string {
+	return c.cfg.Endpoint + "/" + c.cfg.Bucket + "/" + escapePath(key)
+}
+
+// sign adds SigV4 authentication headers to the request.
+func (c *S3Client) sign(req *http.Request, body []byte) {
+	req.Header.Set("X-Amz-Content-Sha256", sigv4.PayloadHash(body))
+	creds := sigv4.Credentials{AccessKeyID: c.cfg.AccessKey, SecretAccessKey: c.cfg.SecretKey}
+	sigv4.Sign(req, body, "s3", c.cfg.Region, creds, c.now())
+}
+
+// escapePath URI-encodes each segment of an object key, preserving slashes.
+func escapePath(key string) string {
+	segments := strings.Split(key, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,122 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"log/slog"
+	"path"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+)
+
+const (
+	// usageExportDelay is how long after midnight a day is exported, so
+	// attempts still buffered by the recorders at midnight are metered.
+	usageExportDelay = 15 * time.Minute
+	// usageExportBackfill is how many completed days the first pass after
+	// startup exports, covering days missed while no server was running.
+	usageExportBackfill = 7
+)
+
+// UsageExporter writes the usage metered on each completed UTC day to
+// object storage as a CSV file, for billing internal teams by consumption.
+//
+// Every instance exports every day. That is harmless: the object for a day
+// is overwritten with the same rows.
+type UsageExporter struct {
+	store    store.UsageStore
+	s3       *S3Client
+	prefix   string
+	interval time.Duration
+	logger   *slog.Logger
+	now      func() time.Time
+
+	exported time.Time // last day exported, zero before the first pass
+}
+
+func NewUsageExporter(s store.UsageStore, s3 *S3Client, prefix string, logger *slog.Logger) *UsageExporter {
+	return &UsageExporter{
+		store:    s,
+		s3:       s3,
+		prefix:   prefix,
+		interval: 1 * time.Hour,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// Start runs an export pass immediately and then on every interval until
+// the context is cancelled.
+func (e *UsageExporter) Start(ctx context.Context) {
+	e.logger.Info("usage exporter started", "bucket", e.s3.Bucket())
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := e.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			e.logger.Error("usage export failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			e.logger.Info("usage exporter stopping")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce exports the completed days not exported yet, oldest first. A day
+// that fails is retried on the next pass.
+func (e *UsageExporter) RunOnce(ctx context.Context) error {
+	last := e.now().UTC().Add(-usageExportDelay).Truncate(24*time.Hour).AddDate(0, 0, -1)
+	day := e.exported.AddDate(0, 0, 1)
+	if e.exported.IsZero() {
+		day = last.AddDate(0, 0, 1-usageExportBackfill)
+	}
+
+	for ; !day.After(last); day = day.AddDate(0, 0, 1) {
+		n, err := e.exportDay(ctx, day)
+		if err != nil {
+			return err
+		}
+		e.exported = day
+		e.logger.Info("usage exported", "day", day.Format(time.DateOnly), "rows", n)
+	}
+	return nil
+}
+
+// exportDay uploads the usage of day and returns how many rows it has.
+func (e *UsageExporter) exportDay(ctx context.Context, day time.Time) (int, error) {
+	days, err := e.store.ListUsage(ctx, store.UsageFilter{From: day, To: day})
+	if err != nil {
+		return 0, err
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(domain.UsageCSVHeader)
+	for _, u := range days {
+		w.Write(u.CSVRecord())
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return 0, fmt.Errorf("encoding usage of %s: %w", day.Format(time.DateOnly), err)
+	}
+
+	if err := e.s3.PutObject(ctx, usageObjectKey(e.prefix, day), buf.Bytes(), "text/csv"); err != nil {
+		return 0, fmt.Errorf("uploading usage of %s: %w", day.Format(time.DateOnly), err)
+	}
+	return len(days), nil
+}
+
+// usageObjectKey names a day's export, e.g.
+// webhook-archive/usage/2024/05/2024-05-01.csv.
+func usageObjectKey(prefix string, day time.Time) string {
+	return path.Join(prefix, "usage", day.Format("2006/01"), day.Format(time.DateOnly)+".csv")
+}
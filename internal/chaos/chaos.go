@@ -0,0 +1,128 @@
+// Package chaos injects faults into the delivery pipeline so that retries,
+// circuit breakers and dead-lettering can be exercised in staging against
+// real endpoints. It is only wired in when CHAOS_ENABLED is set.
+package chaos
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrInjectedRedis is returned by Redis commands failed on purpose.
+var ErrInjectedRedis = errors.New("chaos: injected redis error")
+
+// Injector holds the faults currently injected into one instance. Its
+// settings can change while the pipeline runs, and start out injecting
+// nothing. A nil *Injector injects nothing.
+type Injector struct {
+	settings atomic.Pointer[domain.ChaosSettings]
+	// chance reports whether an event of probability p happens.
+	chance func(p float64) bool
+}
+
+func New() *Injector {
+	i := &Injector{chance: func(p float64) bool { return p > 0 && rand.Float64() < p }}
+	i.settings.Store(&domain.ChaosSettings{})
+	return i
+}
+
+// Settings returns the faults being injected.
+func (i *Injector) Settings() domain.ChaosSettings {
+	if i == nil {
+		return domain.ChaosSettings{}
+	}
+	return *i.settings.Load()
+}
+
+// Set replaces the faults being injected. Invalid settings are rejected and
+// the current ones kept.
+func (i *Injector) Set(s domain.ChaosSettings) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	i.settings.Store(&s)
+	return nil
+}
+
+// DispatchDelay is how long the dispatcher holds a batch of claimed jobs.
+func (i *Injector) DispatchDelay() time.Duration {
+	return time.Duration(i.Settings().DispatchDelayMs) * time.Millisecond
+}
+
+// RedisHook returns a go-redis hook that fails commands at the configured
+// rate. A failed pipeline fails as a whole.
+func (i *Injector) RedisHook() redis.Hook {
+	return redisHook{i}
+}
+
+type redisHook struct{ i *Injector }
+
+func (h redisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h redisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if h.i.chance(h.i.Settings().RedisErrorRate) {
+			return ErrInjectedRedis
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (h redisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if h.i.chance(h.i.Settings().RedisErrorRate) {
+			for _, cmd := range cmds {
+				cmd.SetErr(ErrInjectedRedis)
+			}
+			return ErrInjectedRedis
+		}
+		return next(ctx, cmds)
+	}
+}
+
+// Transport wraps next so that requests hang at the configured rate until
+// their context ends, as if the endpoint never answered, and then fail with
+// a timeout. With a nil Injector it returns next.
+func (i *Injector) Transport(next http.RoundTripper) http.RoundTripper {
+	if i == nil {
+		return next
+	}
+	return &transport{i: i, next: next}
+}
+
+type transport struct {
+	i    *Injector
+	next http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.i.chance(t.i.Settings().DeliveryTimeoutRate) {
+		return t.next.RoundTrip(req)
+	}
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	<-req.Context().Done()
+	return nil, &timeoutError{cause: req.Context().Err()}
+}
+
+// timeoutError is the error of a request hung on purpose. It reports a
+// timeout, like a read from an endpoint that never answers.
+type timeoutError struct{ cause error }
+
+var _ net.Error = (*timeoutError)(nil)
+
+func (e *timeoutError) Error() string   { return "chaos: injected timeout: " + e.cause.Error() }
+func (e *timeoutError) Unwrap() error   { return e.cause }
+func (e *timeoutError) Timeout() bool   { return true }
+func (e *timeoutError) Temporary() bool { return true }
@@ -0,0 +1,112 @@
+package chaos
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestInjector_Set(t *testing.T) {
+	i := New()
+	if got := i.Settings(); got != (domain.ChaosSettings{}) {
+		t.Errorf("new injector settings = %+v, want none", got)
+	}
+
+	want := domain.ChaosSettings{RedisErrorRate: 0.5, DispatchDelayMs: 200, DeliveryTimeoutRate: 1}
+	if err := i.Set(want); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if i.Settings() != want || i.DispatchDelay() != 200*time.Millisecond {
+		t.Errorf("settings = %+v, delay %v, want %+v", i.Settings(), i.DispatchDelay(), want)
+	}
+
+	for _, bad := range []domain.ChaosSettings{
+		{RedisErrorRate: 1.5},
+		{DeliveryTimeoutRate: -0.1},
+		{DispatchDelayMs: domain.MaxChaosDispatchDelayMs + 1},
+	} {
+		if err := i.Set(bad); err == nil {
+			t.Errorf("Set(%+v) accepted", bad)
+		}
+	}
+	if i.Settings() != want {
+		t.Errorf("settings after rejected updates = %+v, want %+v", i.Settings(), want)
+	}
+
+	var none *Injector
+	if none.Settings() != (domain.ChaosSettings{}) || none.DispatchDelay() != 0 {
+		t.Error("nil injector injects faults")
+	}
+}
+
+func TestInjector_RedisHook(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	i := New()
+	client.AddHook(i.RedisHook())
+	ctx := context.Background()
+
+	if err := client.Set(ctx, "key", "1", 0).Err(); err != nil {
+		t.Fatalf("without faults: %v", err)
+	}
+
+	i.Set(domain.ChaosSettings{RedisErrorRate: 1})
+	if err := client.Set(ctx, "key", "2", 0).Err(); !errors.Is(err, ErrInjectedRedis) {
+		t.Errorf("command err = %v, want the injected error", err)
+	}
+	cmds, err := client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		p.Incr(ctx, "counter")
+		return nil
+	})
+	if !errors.Is(err, ErrInjectedRedis) || !errors.Is(cmds[0].Err(), ErrInjectedRedis) {
+		t.Errorf("pipeline err = %v, want the injected error", err)
+	}
+	if v, _ := mr.Get("key"); v != "1" || mr.Exists("counter") {
+		t.Error("failed commands reached Redis")
+	}
+}
+
+func TestInjector_Transport(t *testing.T) {
+	var sent int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	i := New()
+	client := &http.Client{Timeout: 50 * time.Millisecond, Transport: i.Transport(http.DefaultTransport)}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("without faults: %v", err)
+	}
+	resp.Body.Close()
+
+	i.Set(domain.ChaosSettings{DeliveryTimeoutRate: 1})
+	start := time.Now()
+	_, err = client.Get(server.URL)
+	var netErr interface{ Timeout() bool }
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Errorf("err = %v, want a timeout", err)
+	}
+	if time.Since(start) < 50*time.Millisecond {
+		t.Error("request failed before the client timeout")
+	}
+	if sent != 1 {
+		t.Errorf("endpoint got %d requests, want only the one sent without faults", sent)
+	}
+
+	if (*Injector)(nil).Transport(http.DefaultTransport) != http.DefaultTransport {
+		t.Error("nil injector wrapped the transport")
+	}
+}
@@ -2,53 +2,630 @@ package config
 
 import (
 	"fmt"
-	"os"
-	"strconv"
+	"net"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
 )
 
 // Config holds all configuration for the application.
 type Config struct {
 	Port        string
-	DatabaseURL string
-	RedisURL    string
+	GRPCPort    string // empty disables the gRPC API
+	DatabaseURL string // postgres:// or sqlite:<path>
+	RedisURL    string // optional with QueueMode "memory"
 	NumWorkers  int
+
+	// PostgreSQL connection pool. Queries running longer than
+	// DatabaseStatementTimeout, including time spent waiting for a free
+	// connection, are cancelled; 0 lets them run. List, export and analytics
+	// queries go to DatabaseReplicaURL when it is set, with a pool of the
+	// same size.
+	DatabaseReplicaURL        string
+	DatabaseMaxConns          int
+	DatabaseMinConns          int
+	DatabaseMaxConnLifetime   time.Duration
+	DatabaseHealthCheckPeriod time.Duration
+	DatabaseStatementTimeout  time.Duration
+
+	// DatabaseAutoMigrate applies pending migrations at startup. Without it,
+	// they are applied with webhookctl migrate and /readyz fails until then.
+	DatabaseAutoMigrate bool
+
+	// TLS. When both files are set, or TLSAutocertDomains lists domains to
+	// obtain Let's Encrypt certificates for, the HTTP and gRPC APIs are
+	// served over TLS. Certificates are cached in TLSAutocertCacheDir. When
+	// TLSAutocertHTTPPort is set, ACME HTTP-01 challenges are answered there
+	// and other plain HTTP requests are redirected to HTTPS.
+	TLSCertFile         string
+	TLSKeyFile          string
+	TLSAutocertDomains  []string
+	TLSAutocertEmail    string
+	TLSAutocertCacheDir string
+	TLSAutocertHTTPPort string
+
+	// QueueMode selects the delivery queue: "redis" (default) or "memory".
+	// The in-memory queue loses queued jobs and scheduled retries on restart
+	// and cannot be shared between instances.
+	QueueMode string
+
+	// Worker pool autoscaling. The pool starts at WorkerPoolMin and is only
+	// autoscaled when WorkerPoolMax is larger; both default to NumWorkers.
+	WorkerPoolMin           int
+	WorkerPoolMax           int
+	WorkerAutoscaleInterval time.Duration
+	WorkerAutoscaleDrain    time.Duration
+
+	// DispatcherBatchSize caps the jobs claimed from the queue at once.
+	DispatcherBatchSize int
+	// DispatcherFairnessWindow is how many of the oldest ready jobs a claim
+	// shares out round-robin across subscribers. A window no larger than
+	// the batch size claims strictly oldest first.
+	DispatcherFairnessWindow int
+
+	// Fan-out queues an event's deliveries to Redis in pipelines of up to
+	// FanOutChunkSize jobs, FanOutParallelism of them at a time.
+	FanOutChunkSize   int
+	FanOutParallelism int
+
+	// Delivery retries. A delivery is attempted up to RetryMaxAttempts
+	// times. Attempt n is retried after a backoff of RetryBaseDelay * 2^n,
+	// capped at RetryMaxDelay when it is set, jittered by
+	// RetryJitterStrategy: with domain.JitterAdditive, a random jitter of
+	// up to RetryJitter is added to it. Subscribers can pick another
+	// strategy. Subscribers with a maximum retry duration that retry for
+	// all of it wait no longer than RetryDurationMaxDelay between attempts.
+	RetryMaxAttempts      int
+	RetryBaseDelay        time.Duration
+	RetryMaxDelay         time.Duration
+	RetryJitter           time.Duration
+	RetryJitterStrategy   string
+	RetryDurationMaxDelay time.Duration
+
+	// A subscriber's circuit opens after CircuitBreakerFailureThreshold
+	// consecutive failures and lets a test delivery through after
+	// CircuitBreakerCooldown.
+	CircuitBreakerFailureThreshold int
+	CircuitBreakerCooldown         time.Duration
+
+	// RateLimitDefaultPerSecond applies to subscribers without a rate limit
+	// of their own. 0 leaves them unlimited.
+	RateLimitDefaultPerSecond int
+
+	// Event ingestion through the HTTP API. Request bodies larger than
+	// IngestMaxPayloadBytes are rejected with 413, and payloads over it
+	// arriving over gRPC, Kafka or NATS are refused too. Each API key, or
+	// client address when authentication is disabled, may publish at most
+	// IngestRateLimitPerSecond events a second; 0 leaves ingestion unlimited.
+	IngestMaxPayloadBytes    int
+	IngestRateLimitPerSecond int
+	// IngestOffloadThresholdBytes moves payloads larger than this to
+	// ArchivePrefix/payloads in ArchiveS3Bucket, from where deliveries
+	// stream them. 0 keeps every payload in the database.
+	IngestOffloadThresholdBytes int
+
+	// Clustering. InstanceID identifies this replica's job claims; an empty
+	// value generates one. Claims of an instance whose heartbeat has not been
+	// refreshed within ClusterHeartbeatTTL are reassigned to the queue.
+	InstanceID          string
+	ClusterHeartbeatTTL time.Duration
+	// ClockSyncInterval is how often a replica with the Redis queue measures
+	// its clock against Redis, whose clock every replica schedules and
+	// claims jobs by.
+	ClockSyncInterval time.Duration
+
+	// SubscriberCacheTTL is the longest fan-out serves subscriptions from its
+	// in-memory index before reloading them; changes made through the API
+	// invalidate it immediately. 0 disables the cache.
+	SubscriberCacheTTL time.Duration
+
+	// Authentication. When AuthEnabled is set, the WebSocket, SSE and
+	// dashboard endpoints require an API key. AdminAPIKey is accepted in
+	// addition to stored keys so the first keys can be created.
+	// WSAllowedOrigins lists browser origins allowed to open WebSocket
+	// connections; empty means same-origin only.
+	AuthEnabled      bool
+	AdminAPIKey      string
+	WSAllowedOrigins []string
+
+	// Kafka ingestion. When KafkaBrokers is set, events are also consumed
+	// from KafkaTopic as part of consumer group KafkaGroupID.
+	KafkaBrokers []string
+	KafkaTopic   string
+	KafkaGroupID string
+
+	// NATS JetStream ingestion. When NATSURL is set, a durable consumer on
+	// NATSStream receives NATSSubjects, each "subject=event.type" or just
+	// "subject" to use the subject as the event type.
+	NATSURL      string
+	NATSStream   string
+	NATSDurable  string
+	NATSSubjects []string
+
+	// Outbound HTTP client tuning for webhook deliveries. DeliveryTimeout
+	// bounds each request, including reading the response.
+	DeliveryTimeout             time.Duration
+	DeliveryMaxIdleConnsPerHost int
+	DeliveryMaxConnsPerHost     int
+	DeliveryIdleConnTimeout     time.Duration
+	DeliveryTLSHandshakeTimeout time.Duration
+	DeliveryHTTP2               bool
+	DeliveryProxyURL            string        // HTTP(S) or SOCKS5 proxy; empty uses the environment
+	DeliveryLocalAddr           string        // local IP or network interface deliveries are sent from
+	DeliveryDNSCacheTTL         time.Duration // 0 resolves endpoints on every new connection
+	DeliveryBlockPrivateIPs     bool          // refuse endpoints resolving to internal addresses
+	DeliveryDialTimeout         time.Duration // per address an endpoint resolves to
+	DeliveryIPPreference        string        // IP family dialed first, one of domain.IPPreferences
+	DeliveryFallbackDelay       time.Duration // head start of the preferred family; 0 dials addresses one by one
+	DeliveryRedirectPolicy      string        // redirects followed for subscribers without a policy of their own
+	DeliveryGzipThresholdBytes  int
+	DeliveryPayloadCacheSize    int
+	DeliveryCaptureHeaders      []string // nil records the default set
+
+	// EgressIPs are the addresses and CIDR ranges deliveries leave from,
+	// published at GET /api/v1/egress-info for subscribers to allowlist.
+	// They are documentation only; routing is up to the network.
+	EgressIPs []string
+
+	// Stored response bodies. DeliveryResponseBodyLimit of 0 stores none.
+	// Matches of the DeliveryRedact presets (email, card, ssn, bearer) and
+	// of DeliveryRedactPattern are masked before bodies and headers are
+	// stored.
+	DeliveryResponseBodyLimit int
+	DeliveryRedact            []string
+	DeliveryRedactPattern     string
+
+	// Delivery attempts are written in batches of up to
+	// DeliveryRecordBatchSize, at least every DeliveryRecordFlushInterval.
+	// At most DeliveryRecordQueueSize attempts wait in memory; workers block
+	// when it is full.
+	DeliveryRecordBatchSize     int
+	DeliveryRecordFlushInterval time.Duration
+	DeliveryRecordQueueSize     int
+
+	// Every recorded delivery attempt is also mirrored to the configured
+	// sinks: a Kafka topic, an Elasticsearch index and/or a ClickHouse table.
+	// Each sink gets its own buffer of AttemptSinkBufferSize attempts,
+	// written in batches of up to AttemptSinkBatchSize at least every
+	// AttemptSinkFlushInterval; attempts that don't fit are dropped.
+	AttemptSinkKafkaBrokers        []string
+	AttemptSinkKafkaTopic          string
+	AttemptSinkElasticsearchURL    string
+	AttemptSinkElasticsearchIndex  string
+	AttemptSinkElasticsearchAPIKey string
+	AttemptSinkClickHouseURL       string
+	AttemptSinkClickHouseTable     string
+	AttemptSinkBufferSize          int
+	AttemptSinkBatchSize           int
+	AttemptSinkFlushInterval       time.Duration
+
+	// Fractions of successful deliveries and of retried failures that are
+	// logged, from 0 to 1. Dead-lettered deliveries are always logged.
+	DeliveryLogSuccessSampleRate float64
+	DeliveryLogFailureSampleRate float64
+
+	// Retention and archiving. RetentionDays of 0 disables pruning entirely.
+	// Aged rows are exported to ArchiveS3Bucket before deletion when it is set.
+	RetentionDays      int
+	ArchiveS3Endpoint  string
+	ArchiveS3Region    string
+	ArchiveS3Bucket    string
+	ArchiveS3AccessKey string
+	ArchiveS3SecretKey string
+	ArchivePrefix      string
+	// UsageExportEnabled writes each day's metered usage as CSV under
+	// ArchivePrefix/usage in ArchiveS3Bucket.
+	UsageExportEnabled bool
+
+	// Dead letter maintenance. DLQExpiryDays of 0 keeps unresolved dead
+	// letters forever; otherwise older ones are resolved as "expired".
+	DLQExpiryDays int
+	// Subscribers with auto_replay_dead_letters have their dead letters
+	// replayed once their circuit has stayed closed for
+	// DLQAutoReplayHealthyPeriod after an outage, DLQAutoReplayRate a
+	// second.
+	DLQAutoReplayHealthyPeriod time.Duration
+	DLQAutoReplayRate          int
+
+	// Operational alerts, sent to Slack, PagerDuty and/or by email through
+	// the SMTP relay when a rule's threshold is crossed and again when it
+	// clears. A zero threshold disables its rule: AlertQueueDepthThreshold
+	// (jobs, held for AlertQueueDepthDuration), AlertDLQRateThreshold (dead
+	// letters within AlertDLQRateWindow), AlertSubscriberDLQThreshold (one
+	// subscriber's dead letters within AlertSubscriberDLQWindow),
+	// AlertCircuitOpenDuration and AlertSuccessRateThreshold (percent within
+	// AlertSuccessRateWindow).
+	AlertSlackWebhookURL        string
+	AlertPagerDutyRoutingKey    string
+	AlertEmailTo                []string
+	SMTPHost                    string
+	SMTPPort                    int
+	SMTPUsername                string
+	SMTPPassword                string
+	SMTPFrom                    string
+	AlertInterval               time.Duration
+	AlertQueueDepthThreshold    int
+	AlertQueueDepthDuration     time.Duration
+	AlertDLQRateThreshold       int
+	AlertDLQRateWindow          time.Duration
+	AlertSubscriberDLQThreshold int
+	AlertSubscriberDLQWindow    time.Duration
+	AlertCircuitOpenDuration    time.Duration
+	AlertSuccessRateThreshold   float64
+	AlertSuccessRateWindow      time.Duration
+	AlertSuccessRateMinAttempts int
+
+	// ChaosEnabled lets admins inject Redis errors, dispatch delays and
+	// delivery timeouts through /api/v1/admin/chaos, for resilience testing
+	// in staging. It must stay off in production.
+	ChaosEnabled bool
+
+	// settings holds the effective value of each setting by its environment
+	// variable name, for Changed.
+	settings map[string]string
 }
 
-// Load reads configuration from environment variables.
-func Load() (*Config, error) {
-	port := getEnv("PORT", "8080")
-	dbURL := getEnv("DATABASE_URL", "")
-	redisURL := getEnv("REDIS_URL", "")
-	numWorkers := getEnvInt("NUM_WORKERS", 50)
+// AlertsEnabled reports whether any operational alert rule is set.
+func (c *Config) AlertsEnabled() bool {
+	return c.AlertQueueDepthThreshold > 0 || c.AlertDLQRateThreshold > 0 ||
+		c.AlertSubscriberDLQThreshold > 0 || c.AlertCircuitOpenDuration > 0 ||
+		c.AlertSuccessRateThreshold > 0
+}
 
-	if dbURL == "" {
-		return nil, fmt.Errorf("DATABASE_URL is required")
+// Changed returns the names of the settings, as environment variables, whose
+// values differ between prev and c.
+func (c *Config) Changed(prev *Config) []string {
+	var names []string
+	for name, value := range c.settings {
+		if prev.settings[name] != value {
+			names = append(names, name)
+		}
 	}
-	if redisURL == "" {
-		return nil, fmt.Errorf("REDIS_URL is required")
+	sort.Strings(names)
+	return names
+}
+
+// Setting returns the effective value of a setting by its environment
+// variable name.
+func (c *Config) Setting(name string) string {
+	return c.settings[name]
+}
+
+// Load reads configuration from environment variables and, when path is not
+// empty, from a YAML or TOML file. Environment variables override the file.
+// All invalid settings are reported together in a *ValidationError.
+func Load(path string) (*Config, error) {
+	l := &loader{}
+	if path != "" {
+		if err := l.readFile(path); err != nil {
+			return nil, err
+		}
 	}
 
-	return &Config{
+	port := l.str("PORT", "8080")
+	dbURL := l.str("DATABASE_URL", "")
+	redisURL := l.str("REDIS_URL", "")
+	queueMode := l.str("QUEUE_MODE", "redis")
+	numWorkers := l.int("NUM_WORKERS", 50)
+	poolMin := l.int("WORKER_POOL_MIN", numWorkers)
+	poolMax := l.int("WORKER_POOL_MAX", numWorkers)
+	tlsCert := l.str("TLS_CERT_FILE", "")
+	tlsKey := l.str("TLS_KEY_FILE", "")
+	autocertDomains := l.list("TLS_AUTOCERT_DOMAINS")
+	autocertHTTPPort := l.str("TLS_AUTOCERT_HTTP_PORT", "")
+	archiveBucket := l.str("ARCHIVE_S3_BUCKET", "")
+	archiveEndpoint := l.str("ARCHIVE_S3_ENDPOINT", "")
+	kafkaBrokers := l.list("KAFKA_BROKERS")
+	kafkaTopic := l.str("KAFKA_TOPIC", "")
+	natsURL := l.str("NATS_URL", "")
+	natsStream := l.str("NATS_STREAM", "")
+	natsSubjects := l.list("NATS_SUBJECTS")
+	alertEmailTo := l.list("ALERT_EMAIL_TO")
+	smtpHost := l.str("SMTP_HOST", "")
+	smtpFrom := l.str("SMTP_FROM", "")
+	retentionDays := l.int("RETENTION_DAYS", 0)
+	alertSlack := l.str("ALERT_SLACK_WEBHOOK_URL", "")
+	alertPagerDuty := l.str("ALERT_PAGERDUTY_ROUTING_KEY", "")
+
+	cfg := &Config{
 		Port:        port,
+		GRPCPort:    l.str("GRPC_PORT", ""),
 		DatabaseURL: dbURL,
 		RedisURL:    redisURL,
 		NumWorkers:  numWorkers,
-	}, nil
-}
 
-func getEnv(key, fallback string) string {
-	if val := os.Getenv(key); val != "" {
-		return val
+		DatabaseReplicaURL:        l.str("DATABASE_REPLICA_URL", ""),
+		DatabaseMaxConns:          l.int("DATABASE_MAX_CONNS", 25),
+		DatabaseMinConns:          l.int("DATABASE_MIN_CONNS", 2),
+		DatabaseMaxConnLifetime:   l.duration("DATABASE_MAX_CONN_LIFETIME", time.Hour),
+		DatabaseHealthCheckPeriod: l.duration("DATABASE_HEALTH_CHECK_PERIOD", time.Minute),
+		DatabaseStatementTimeout:  l.duration("DATABASE_STATEMENT_TIMEOUT", 30*time.Second),
+		DatabaseAutoMigrate:       l.bool("DATABASE_AUTO_MIGRATE", true),
+
+		TLSCertFile:         tlsCert,
+		TLSKeyFile:          tlsKey,
+		TLSAutocertDomains:  autocertDomains,
+		TLSAutocertEmail:    l.str("TLS_AUTOCERT_EMAIL", ""),
+		TLSAutocertCacheDir: l.str("TLS_AUTOCERT_CACHE_DIR", "autocert-cache"),
+		TLSAutocertHTTPPort: autocertHTTPPort,
+
+		QueueMode: queueMode,
+
+		WorkerPoolMin:           poolMin,
+		WorkerPoolMax:           poolMax,
+		WorkerAutoscaleInterval: l.duration("WORKER_AUTOSCALE_INTERVAL", 5*time.Second),
+		WorkerAutoscaleDrain:    l.duration("WORKER_AUTOSCALE_DRAIN_TIME", 30*time.Second),
+
+		DispatcherBatchSize:      l.int("DISPATCHER_BATCH_SIZE", 100),
+		DispatcherFairnessWindow: l.int("DISPATCHER_FAIRNESS_WINDOW", 1000),
+
+		FanOutChunkSize:   l.int("FANOUT_CHUNK_SIZE", 500),
+		FanOutParallelism: l.int("FANOUT_PARALLELISM", 4),
+
+		RetryMaxAttempts:      l.int("RETRY_MAX_ATTEMPTS", 5),
+		RetryBaseDelay:        l.duration("RETRY_BASE_DELAY", time.Second),
+		RetryMaxDelay:         l.duration("RETRY_MAX_DELAY", 0),
+		RetryJitter:           l.duration("RETRY_JITTER", time.Second),
+		RetryJitterStrategy:   l.str("RETRY_JITTER_STRATEGY", domain.JitterAdditive),
+		RetryDurationMaxDelay: l.duration("RETRY_DURATION_MAX_DELAY", 5*time.Minute),
+
+		CircuitBreakerFailureThreshold: l.int("CIRCUIT_BREAKER_FAILURE_THRESHOLD", 5),
+		CircuitBreakerCooldown:         l.duration("CIRCUIT_BREAKER_COOLDOWN", 30*time.Second),
+
+		RateLimitDefaultPerSecond: l.int("RATE_LIMIT_DEFAULT_PER_SECOND", 0),
+
+		IngestMaxPayloadBytes:       l.int("INGEST_MAX_PAYLOAD_BYTES", 256*1024),
+		IngestRateLimitPerSecond:    l.int("INGEST_RATE_LIMIT_PER_SECOND", 0),
+		IngestOffloadThresholdBytes: l.int("INGEST_OFFLOAD_THRESHOLD_BYTES", 0),
+
+		InstanceID:          l.str("INSTANCE_ID", ""),
+		ClusterHeartbeatTTL: l.duration("CLUSTER_HEARTBEAT_TTL", 15*time.Second),
+		ClockSyncInterval:   l.duration("CLOCK_SYNC_INTERVAL", 30*time.Second),
+
+		SubscriberCacheTTL: l.duration("SUBSCRIBER_CACHE_TTL", 30*time.Second),
+
+		AuthEnabled:      l.bool("AUTH_ENABLED", false),
+		AdminAPIKey:      l.str("ADMIN_API_KEY", ""),
+		WSAllowedOrigins: l.list("WS_ALLOWED_ORIGINS"),
+
+		KafkaBrokers: kafkaBrokers,
+		KafkaTopic:   kafkaTopic,
+		KafkaGroupID: l.str("KAFKA_GROUP_ID", "webhook-delivery"),
+
+		NATSURL:      natsURL,
+		NATSStream:   natsStream,
+		NATSDurable:  l.str("NATS_DURABLE", "webhook-delivery"),
+		NATSSubjects: natsSubjects,
+
+		DeliveryTimeout:             l.duration("DELIVERY_TIMEOUT", 10*time.Second),
+		DeliveryMaxIdleConnsPerHost: l.int("DELIVERY_MAX_IDLE_CONNS_PER_HOST", 32),
+		DeliveryMaxConnsPerHost:     l.int("DELIVERY_MAX_CONNS_PER_HOST", 0),
+		DeliveryIdleConnTimeout:     l.duration("DELIVERY_IDLE_CONN_TIMEOUT", 90*time.Second),
+		DeliveryTLSHandshakeTimeout: l.duration("DELIVERY_TLS_HANDSHAKE_TIMEOUT", 10*time.Second),
+		DeliveryHTTP2:               l.bool("DELIVERY_HTTP2", true),
+		DeliveryProxyURL:            l.str("DELIVERY_PROXY_URL", ""),
+		DeliveryLocalAddr:           l.str("DELIVERY_LOCAL_ADDR", ""),
+		DeliveryDNSCacheTTL:         l.duration("DELIVERY_DNS_CACHE_TTL", 30*time.Second),
+		DeliveryBlockPrivateIPs:     l.bool("DELIVERY_BLOCK_PRIVATE_IPS", false),
+		DeliveryDialTimeout:         l.duration("DELIVERY_DIAL_TIMEOUT", 5*time.Second),
+		DeliveryIPPreference:        l.str("DELIVERY_IP_PREFERENCE", domain.IPPreferenceAuto),
+		DeliveryFallbackDelay:       l.duration("DELIVERY_FALLBACK_DELAY", 300*time.Millisecond),
+		DeliveryRedirectPolicy:      l.str("DELIVERY_REDIRECT_POLICY", domain.RedirectSameHost),
+		DeliveryGzipThresholdBytes:  l.int("DELIVERY_GZIP_THRESHOLD_BYTES", 16384),
+		DeliveryPayloadCacheSize:    l.int("DELIVERY_PAYLOAD_CACHE_SIZE", 1000),
+		DeliveryCaptureHeaders:      l.list("DELIVERY_CAPTURE_HEADERS"),
+		EgressIPs:                   l.list("EGRESS_IPS"),
+		DeliveryResponseBodyLimit:   l.int("DELIVERY_RESPONSE_BODY_LIMIT", 1024),
+		DeliveryRedact:              l.list("DELIVERY_REDACT"),
+		DeliveryRedactPattern:       l.str("DELIVERY_REDACT_PATTERN", ""),
+		DeliveryRecordBatchSize:     l.int("DELIVERY_RECORD_BATCH_SIZE", 500),
+		DeliveryRecordFlushInterval: l.duration("DELIVERY_RECORD_FLUSH_INTERVAL", 200*time.Millisecond),
+		DeliveryRecordQueueSize:     l.int("DELIVERY_RECORD_QUEUE_SIZE", 10000),
+
+		AttemptSinkKafkaBrokers:        l.list("ATTEMPT_SINK_KAFKA_BROKERS"),
+		AttemptSinkKafkaTopic:          l.str("ATTEMPT_SINK_KAFKA_TOPIC", "delivery-attempts"),
+		AttemptSinkElasticsearchURL:    l.str("ATTEMPT_SINK_ELASTICSEARCH_URL", ""),
+		AttemptSinkElasticsearchIndex:  l.str("ATTEMPT_SINK_ELASTICSEARCH_INDEX", "delivery-attempts"),
+		AttemptSinkElasticsearchAPIKey: l.str("ATTEMPT_SINK_ELASTICSEARCH_API_KEY", ""),
+		AttemptSinkClickHouseURL:       l.str("ATTEMPT_SINK_CLICKHOUSE_URL", ""),
+		AttemptSinkClickHouseTable:     l.str("ATTEMPT_SINK_CLICKHOUSE_TABLE", "delivery_attempts"),
+		AttemptSinkBufferSize:          l.int("ATTEMPT_SINK_BUFFER_SIZE", 10000),
+		AttemptSinkBatchSize:           l.int("ATTEMPT_SINK_BATCH_SIZE", 500),
+		AttemptSinkFlushInterval:       l.duration("ATTEMPT_SINK_FLUSH_INTERVAL", time.Second),
+
+		DeliveryLogSuccessSampleRate: l.float("DELIVERY_LOG_SUCCESS_SAMPLE_RATE", 1),
+		DeliveryLogFailureSampleRate: l.float("DELIVERY_LOG_FAILURE_SAMPLE_RATE", 1),
+
+		RetentionDays:      retentionDays,
+		ArchiveS3Endpoint:  archiveEndpoint,
+		ArchiveS3Region:    l.str("ARCHIVE_S3_REGION", "us-east-1"),
+		ArchiveS3Bucket:    archiveBucket,
+		ArchiveS3AccessKey: l.str("ARCHIVE_S3_ACCESS_KEY", ""),
+		ArchiveS3SecretKey: l.str("ARCHIVE_S3_SECRET_KEY", ""),
+		ArchivePrefix:      l.str("ARCHIVE_PREFIX", "webhook-archive"),
+		UsageExportEnabled: l.bool("USAGE_EXPORT_ENABLED", false),
+
+		DLQExpiryDays: l.int("DLQ_EXPIRY_DAYS", 0),
+
+		DLQAutoReplayHealthyPeriod: l.duration("DLQ_AUTO_REPLAY_HEALTHY_PERIOD", 5*time.Minute),
+		DLQAutoReplayRate:          l.int("DLQ_AUTO_REPLAY_RATE", 10),
+
+		AlertSlackWebhookURL:        alertSlack,
+		AlertPagerDutyRoutingKey:    alertPagerDuty,
+		AlertEmailTo:                alertEmailTo,
+		SMTPHost:                    smtpHost,
+		SMTPPort:                    l.int("SMTP_PORT", 587),
+		SMTPUsername:                l.str("SMTP_USERNAME", ""),
+		SMTPPassword:                l.str("SMTP_PASSWORD", ""),
+		SMTPFrom:                    smtpFrom,
+		AlertInterval:               l.duration("ALERT_INTERVAL", time.Minute),
+		AlertQueueDepthThreshold:    l.int("ALERT_QUEUE_DEPTH_THRESHOLD", 0),
+		AlertQueueDepthDuration:     l.duration("ALERT_QUEUE_DEPTH_DURATION", 5*time.Minute),
+		AlertDLQRateThreshold:       l.int("ALERT_DLQ_RATE_THRESHOLD", 0),
+		AlertDLQRateWindow:          l.duration("ALERT_DLQ_RATE_WINDOW", 15*time.Minute),
+		AlertSubscriberDLQThreshold: l.int("ALERT_SUBSCRIBER_DLQ_THRESHOLD", 0),
+		AlertSubscriberDLQWindow:    l.duration("ALERT_SUBSCRIBER_DLQ_WINDOW", time.Hour),
+		AlertCircuitOpenDuration:    l.duration("ALERT_CIRCUIT_OPEN_DURATION", 0),
+		AlertSuccessRateThreshold:   l.float("ALERT_SUCCESS_RATE_THRESHOLD", 0),
+		AlertSuccessRateWindow:      l.duration("ALERT_SUCCESS_RATE_WINDOW", 15*time.Minute),
+		AlertSuccessRateMinAttempts: l.int("ALERT_SUCCESS_RATE_MIN_ATTEMPTS", 100),
+
+		ChaosEnabled: l.bool("CHAOS_ENABLED", false),
 	}
-	return fallback
-}
+	cfg.settings = l.resolved
+	l.rejectUnknownKeys()
 
-func getEnvInt(key string, fallback int) int {
-	if val := os.Getenv(key); val != "" {
-		n, err := strconv.Atoi(val)
-		if err == nil {
-			return n
+	if dbURL == "" {
+		l.fail("DATABASE_URL is required")
+	}
+	if queueMode != "redis" && queueMode != "memory" {
+		l.fail("QUEUE_MODE must be redis or memory, got %q", queueMode)
+	}
+	if redisURL == "" && queueMode != "memory" {
+		l.fail("REDIS_URL is required")
+	}
+	if cfg.DatabaseReplicaURL != "" && (strings.HasPrefix(dbURL, "sqlite:") || strings.HasPrefix(cfg.DatabaseReplicaURL, "sqlite:")) {
+		l.fail("DATABASE_REPLICA_URL requires PostgreSQL for both the primary and the replica")
+	}
+	l.atLeast("DATABASE_MAX_CONNS", cfg.DatabaseMaxConns, 1)
+	if cfg.DatabaseMinConns < 0 || cfg.DatabaseMinConns > cfg.DatabaseMaxConns {
+		l.fail("DATABASE_MIN_CONNS must be between 0 and DATABASE_MAX_CONNS")
+	}
+	l.positive("DATABASE_MAX_CONN_LIFETIME", cfg.DatabaseMaxConnLifetime)
+	l.positive("DATABASE_HEALTH_CHECK_PERIOD", cfg.DatabaseHealthCheckPeriod)
+	if cfg.DatabaseStatementTimeout < 0 {
+		l.fail("DATABASE_STATEMENT_TIMEOUT must not be negative")
+	}
+	if poolMin < 1 || poolMax < poolMin {
+		l.fail("WORKER_POOL_MIN must be at least 1 and no greater than WORKER_POOL_MAX")
+	}
+	if (tlsCert == "") != (tlsKey == "") {
+		l.fail("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+	l.fileExists("TLS_CERT_FILE", tlsCert)
+	if tlsCert != "" && len(autocertDomains) > 0 {
+		l.fail("TLS_CERT_FILE and TLS_AUTOCERT_DOMAINS cannot both be set")
+	}
+	if autocertHTTPPort != "" && len(autocertDomains) == 0 {
+		l.fail("TLS_AUTOCERT_DOMAINS is required when TLS_AUTOCERT_HTTP_PORT is set")
+	}
+	l.fileExists("TLS_KEY_FILE", tlsKey)
+	l.atLeast("DISPATCHER_BATCH_SIZE", cfg.DispatcherBatchSize, 1)
+	l.atLeast("DISPATCHER_FAIRNESS_WINDOW", cfg.DispatcherFairnessWindow, 0)
+	l.atLeast("FANOUT_CHUNK_SIZE", cfg.FanOutChunkSize, 1)
+	l.atLeast("FANOUT_PARALLELISM", cfg.FanOutParallelism, 1)
+	l.positive("CLOCK_SYNC_INTERVAL", cfg.ClockSyncInterval)
+	l.atLeast("RETRY_MAX_ATTEMPTS", cfg.RetryMaxAttempts, 1)
+	l.positive("RETRY_BASE_DELAY", cfg.RetryBaseDelay)
+	if cfg.RetryMaxDelay < 0 || cfg.RetryJitter < 0 {
+		l.fail("RETRY_MAX_DELAY and RETRY_JITTER must not be negative")
+	}
+	if !slices.Contains(domain.JitterStrategies, cfg.RetryJitterStrategy) {
+		l.fail("RETRY_JITTER_STRATEGY must be one of %s, got %q", strings.Join(domain.JitterStrategies, ", "), cfg.RetryJitterStrategy)
+	}
+	l.positive("RETRY_DURATION_MAX_DELAY", cfg.RetryDurationMaxDelay)
+	if !slices.Contains(domain.RedirectPolicies, cfg.DeliveryRedirectPolicy) {
+		l.fail("DELIVERY_REDIRECT_POLICY must be one of %s, got %q", strings.Join(domain.RedirectPolicies, ", "), cfg.DeliveryRedirectPolicy)
+	}
+	l.atLeast("CIRCUIT_BREAKER_FAILURE_THRESHOLD", cfg.CircuitBreakerFailureThreshold, 1)
+	l.positive("CIRCUIT_BREAKER_COOLDOWN", cfg.CircuitBreakerCooldown)
+	l.atLeast("RATE_LIMIT_DEFAULT_PER_SECOND", cfg.RateLimitDefaultPerSecond, 0)
+	l.atLeast("INGEST_MAX_PAYLOAD_BYTES", cfg.IngestMaxPayloadBytes, 1)
+	l.atLeast("INGEST_RATE_LIMIT_PER_SECOND", cfg.IngestRateLimitPerSecond, 0)
+	l.atLeast("INGEST_OFFLOAD_THRESHOLD_BYTES", cfg.IngestOffloadThresholdBytes, 0)
+	l.positive("DELIVERY_TIMEOUT", cfg.DeliveryTimeout)
+	if cfg.DeliveryDNSCacheTTL < 0 {
+		l.fail("DELIVERY_DNS_CACHE_TTL must not be negative")
+	}
+	l.positive("DELIVERY_DIAL_TIMEOUT", cfg.DeliveryDialTimeout)
+	if !slices.Contains(domain.IPPreferences, cfg.DeliveryIPPreference) {
+		l.fail("DELIVERY_IP_PREFERENCE must be one of %s, got %q", strings.Join(domain.IPPreferences, ", "), cfg.DeliveryIPPreference)
+	}
+	if cfg.DeliveryFallbackDelay < 0 {
+		l.fail("DELIVERY_FALLBACK_DELAY must not be negative")
+	}
+	l.fraction("DELIVERY_LOG_SUCCESS_SAMPLE_RATE", cfg.DeliveryLogSuccessSampleRate)
+	l.fraction("DELIVERY_LOG_FAILURE_SAMPLE_RATE", cfg.DeliveryLogFailureSampleRate)
+	if err := domain.ValidateProxyURL(cfg.DeliveryProxyURL); err != nil {
+		l.fail("DELIVERY_PROXY_URL: %v", err)
+	}
+	for _, ip := range cfg.EgressIPs {
+		if net.ParseIP(ip) == nil {
+			if _, _, err := net.ParseCIDR(ip); err != nil {
+				l.fail("EGRESS_IPS: %q is not an IP address or CIDR range", ip)
+			}
+		}
+	}
+	if len(kafkaBrokers) > 0 && kafkaTopic == "" {
+		l.fail("KAFKA_TOPIC is required when KAFKA_BROKERS is set")
+	}
+	if natsURL != "" && (natsStream == "" || len(natsSubjects) == 0) {
+		l.fail("NATS_STREAM and NATS_SUBJECTS are required when NATS_URL is set")
+	}
+	if len(cfg.AttemptSinkKafkaBrokers) > 0 && cfg.AttemptSinkKafkaTopic == "" {
+		l.fail("ATTEMPT_SINK_KAFKA_TOPIC is required when ATTEMPT_SINK_KAFKA_BROKERS is set")
+	}
+	if cfg.AttemptSinkElasticsearchURL != "" && cfg.AttemptSinkElasticsearchIndex == "" {
+		l.fail("ATTEMPT_SINK_ELASTICSEARCH_INDEX is required when ATTEMPT_SINK_ELASTICSEARCH_URL is set")
+	}
+	l.atLeast("ATTEMPT_SINK_BUFFER_SIZE", cfg.AttemptSinkBufferSize, 1)
+	l.atLeast("ATTEMPT_SINK_BATCH_SIZE", cfg.AttemptSinkBatchSize, 1)
+	l.positive("ATTEMPT_SINK_FLUSH_INTERVAL", cfg.AttemptSinkFlushInterval)
+	if archiveBucket != "" && archiveEndpoint == "" {
+		l.fail("ARCHIVE_S3_ENDPOINT is required when ARCHIVE_S3_BUCKET is set")
+	}
+	if cfg.UsageExportEnabled && archiveBucket == "" {
+		l.fail("ARCHIVE_S3_BUCKET is required when USAGE_EXPORT_ENABLED is set")
+	}
+	if cfg.IngestOffloadThresholdBytes > 0 && archiveBucket == "" {
+		l.fail("ARCHIVE_S3_BUCKET is required when INGEST_OFFLOAD_THRESHOLD_BYTES is set")
+	}
+	// The per-subscriber dead letter alerts became an alert rule; refuse the
+	// old settings rather than silently dropping the alerts they configured.
+	// A threshold of 0 configured none, so it is still accepted.
+	for _, renamed := range [][2]string{
+		{"DLQ_ALERT_THRESHOLD", "ALERT_SUBSCRIBER_DLQ_THRESHOLD"},
+		{"DLQ_ALERT_SLACK_WEBHOOK_URL", "ALERT_SLACK_WEBHOOK_URL"},
+		{"DLQ_ALERT_EMAIL_TO", "ALERT_EMAIL_TO"},
+	} {
+		if val, source, ok := l.lookup(renamed[0]); ok && val != "0" {
+			l.fail("%s was replaced by %s", source, renamed[1])
 		}
 	}
-	return fallback
+	if len(alertEmailTo) > 0 && (smtpHost == "" || smtpFrom == "") {
+		l.fail("SMTP_HOST and SMTP_FROM are required when ALERT_EMAIL_TO is set")
+	}
+	l.positive("DLQ_AUTO_REPLAY_HEALTHY_PERIOD", cfg.DLQAutoReplayHealthyPeriod)
+	l.atLeast("DLQ_AUTO_REPLAY_RATE", cfg.DLQAutoReplayRate, 1)
+	l.positive("ALERT_INTERVAL", cfg.AlertInterval)
+	l.positive("ALERT_QUEUE_DEPTH_DURATION", cfg.AlertQueueDepthDuration)
+	l.positive("ALERT_DLQ_RATE_WINDOW", cfg.AlertDLQRateWindow)
+	l.positive("ALERT_SUBSCRIBER_DLQ_WINDOW", cfg.AlertSubscriberDLQWindow)
+	l.positive("ALERT_SUCCESS_RATE_WINDOW", cfg.AlertSuccessRateWindow)
+	if cfg.AlertSuccessRateThreshold < 0 || cfg.AlertSuccessRateThreshold > 100 {
+		l.fail("ALERT_SUCCESS_RATE_THRESHOLD must be a percentage between 0 and 100, got %g", cfg.AlertSuccessRateThreshold)
+	}
+	if cfg.AlertsEnabled() && alertSlack == "" && alertPagerDuty == "" && len(alertEmailTo) == 0 {
+		l.fail("ALERT_SLACK_WEBHOOK_URL, ALERT_PAGERDUTY_ROUTING_KEY or ALERT_EMAIL_TO is required when an alert rule is set")
+	}
+	if retentionDays > 0 && strings.HasPrefix(dbURL, "sqlite:") {
+		l.fail("RETENTION_DAYS requires PostgreSQL; archiving is not supported with a sqlite DATABASE_URL")
+	}
+
+	if len(l.problems) > 0 {
+		return nil, &ValidationError{Problems: l.problems}
+	}
+	return cfg, nil
+}
+
+// ValidationError lists every invalid setting found by Load.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid configuration: %s", strings.Join(e.Problems, "; "))
 }
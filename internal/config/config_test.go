@@ -0,0 +1,251 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad_YAMLFileWithEnvOverrides(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+database_url: sqlite:test.db
+queue_mode: memory
+num_workers: 8
+retry:
+  max_attempts: 7
+  base_delay: 500ms
+circuit_breaker:
+  failure_threshold: 3
+delivery:
+  timeout: 5s
+  capture_headers: [Retry-After, X-Request-Id]
+`)
+	t.Setenv("NUM_WORKERS", "12")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.NumWorkers != 12 || cfg.WorkerPoolMin != 12 {
+		t.Errorf("expected the environment to override num_workers, got %d/%d", cfg.NumWorkers, cfg.WorkerPoolMin)
+	}
+	if cfg.RetryMaxAttempts != 7 || cfg.RetryBaseDelay != 500*time.Millisecond {
+		t.Errorf("unexpected retry settings %d %s", cfg.RetryMaxAttempts, cfg.RetryBaseDelay)
+	}
+	if cfg.CircuitBreakerFailureThreshold != 3 || cfg.CircuitBreakerCooldown != 30*time.Second {
+		t.Errorf("unexpected breaker settings %d %s", cfg.CircuitBreakerFailureThreshold, cfg.CircuitBreakerCooldown)
+	}
+	if cfg.DeliveryTimeout != 5*time.Second {
+		t.Errorf("expected a 5s delivery timeout, got %s", cfg.DeliveryTimeout)
+	}
+	if !reflect.DeepEqual(cfg.DeliveryCaptureHeaders, []string{"Retry-After", "X-Request-Id"}) {
+		t.Errorf("unexpected capture headers %v", cfg.DeliveryCaptureHeaders)
+	}
+}
+
+func TestLoad_TOMLFile(t *testing.T) {
+	path := writeFile(t, "config.toml", `
+database_url = "sqlite:test.db"
+queue_mode = "memory"
+
+[rate_limit]
+default_per_second = 20
+
+[dispatcher]
+batch_size = 50
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.RateLimitDefaultPerSecond != 20 || cfg.DispatcherBatchSize != 50 {
+		t.Errorf("unexpected settings %d %d", cfg.RateLimitDefaultPerSecond, cfg.DispatcherBatchSize)
+	}
+}
+
+func TestLoad_ReportsEveryProblem(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+database_url: sqlite:test.db
+database:
+  replica_url: postgres://replica/webhooks
+  min_conns: 50
+queue_mode: memory
+retry:
+  base_delay: 5
+circuit_breaker:
+  failure_threshold: 0
+delivery:
+  timeout_ms: 100
+  proxy_url: ftp://proxy.internal
+  log_success_sample_rate: 5
+  log_failure_sample_rate: half
+egress_ips: [203.0.113.10, 198.51.100.0/28, egress.example.com]
+tls_cert_file: cert.pem
+`)
+	t.Setenv("NUM_WORKERS", "many")
+	t.Setenv("AUTH_ENABLED", "yes please")
+
+	_, err := Load(path)
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a ValidationError, got %v", err)
+	}
+
+	want := []string{
+		`NUM_WORKERS (environment): "many" is not an integer`,
+		`AUTH_ENABLED (environment): "yes please" is not a boolean`,
+		`retry.base_delay (config.yaml): "5" is not a duration`,
+		`delivery.timeout_ms (config.yaml): unknown setting`,
+		`TLS_CERT_FILE and TLS_KEY_FILE must be set together`,
+		`TLS_CERT_FILE: stat cert.pem`,
+		`DATABASE_REPLICA_URL requires PostgreSQL for both the primary and the replica`,
+		`DATABASE_MIN_CONNS must be between 0 and DATABASE_MAX_CONNS`,
+		`CIRCUIT_BREAKER_FAILURE_THRESHOLD must be at least 1, got 0`,
+		`DELIVERY_PROXY_URL: proxy URL scheme must be http, https, socks5 or socks5h`,
+		`EGRESS_IPS: "egress.example.com" is not an IP address or CIDR range`,
+		`delivery.log_failure_sample_rate (config.yaml): "half" is not a number`,
+		`DELIVERY_LOG_SUCCESS_SAMPLE_RATE must be between 0 and 1, got 5`,
+	}
+	for _, w := range want {
+		found := false
+		for _, p := range verr.Problems {
+			if strings.HasPrefix(p, w) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected a problem starting %q in %q", w, verr.Problems)
+		}
+	}
+	if len(verr.Problems) != len(want) {
+		t.Errorf("expected %d problems, got %q", len(want), verr.Problems)
+	}
+}
+
+func TestLoad_AutocertValidation(t *testing.T) {
+	t.Setenv("DATABASE_URL", "sqlite:test.db")
+	t.Setenv("QUEUE_MODE", "memory")
+	t.Setenv("TLS_AUTOCERT_HTTP_PORT", "80")
+
+	_, err := Load("")
+	if err == nil || !strings.Contains(err.Error(), "TLS_AUTOCERT_DOMAINS is required") {
+		t.Errorf("expected the HTTP port to need domains, got %v", err)
+	}
+
+	t.Setenv("TLS_AUTOCERT_DOMAINS", "hooks.example.com, www.hooks.example.com")
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(cfg.TLSAutocertDomains) != 2 || cfg.TLSAutocertCacheDir != "autocert-cache" {
+		t.Errorf("unexpected autocert settings %v %q", cfg.TLSAutocertDomains, cfg.TLSAutocertCacheDir)
+	}
+}
+
+func TestLoad_AlertRules(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+database_url: sqlite:test.db
+queue_mode: memory
+alert:
+  circuit_open_duration: 1h
+  success_rate_threshold: 95
+`)
+
+	_, err := Load(path)
+	if err == nil || !strings.Contains(err.Error(), "ALERT_SLACK_WEBHOOK_URL, ALERT_PAGERDUTY_ROUTING_KEY or ALERT_EMAIL_TO is required") {
+		t.Errorf("expected alert rules to need a channel, got %v", err)
+	}
+
+	t.Setenv("ALERT_PAGERDUTY_ROUTING_KEY", "routing-key")
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.AlertCircuitOpenDuration != time.Hour || cfg.AlertSuccessRateThreshold != 95 || cfg.AlertSuccessRateWindow != 15*time.Minute || cfg.AlertQueueDepthThreshold != 0 {
+		t.Errorf("unexpected alert settings %+v", cfg)
+	}
+
+	t.Setenv("ALERT_QUEUE_DEPTH_DURATION", "0s")
+	if _, err := Load(path); err == nil {
+		t.Error("expected a zero queue depth duration to be rejected")
+	}
+}
+
+func TestLoad_SubscriberDLQAlerts(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+database_url: sqlite:test.db
+queue_mode: memory
+alert:
+  subscriber_dlq_threshold: 10
+  email_to: oncall@example.com
+`)
+
+	_, err := Load(path)
+	if err == nil || !strings.Contains(err.Error(), "SMTP_HOST and SMTP_FROM are required when ALERT_EMAIL_TO is set") {
+		t.Errorf("expected email alerts to need an SMTP relay, got %v", err)
+	}
+
+	t.Setenv("SMTP_HOST", "mail.example.com")
+	t.Setenv("SMTP_FROM", "alerts@example.com")
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !cfg.AlertsEnabled() || cfg.AlertSubscriberDLQThreshold != 10 || cfg.AlertSubscriberDLQWindow != time.Hour || len(cfg.AlertEmailTo) != 1 {
+		t.Errorf("unexpected alert settings %+v", cfg)
+	}
+
+	t.Setenv("DLQ_ALERT_THRESHOLD", "0")
+	if _, err := Load(path); err != nil {
+		t.Errorf("a zero DLQ_ALERT_THRESHOLD should still load: %v", err)
+	}
+	t.Setenv("DLQ_ALERT_THRESHOLD", "5")
+	_, err = Load(path)
+	if err == nil || !strings.Contains(err.Error(), "DLQ_ALERT_THRESHOLD (environment) was replaced by ALERT_SUBSCRIBER_DLQ_THRESHOLD") {
+		t.Errorf("expected the old setting to be refused, got %v", err)
+	}
+}
+
+func TestConfig_Changed(t *testing.T) {
+	path := writeFile(t, "config.yaml", "database_url: sqlite:test.db\nqueue_mode: memory\n")
+	before, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	t.Setenv("DISPATCHER_BATCH_SIZE", "250")
+	t.Setenv("WS_ALLOWED_ORIGINS", "https://a.example, https://b.example")
+	after, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	want := []string{"DISPATCHER_BATCH_SIZE", "WS_ALLOWED_ORIGINS"}
+	if got := after.Changed(before); !reflect.DeepEqual(got, want) {
+		t.Errorf("Changed = %v, want %v", got, want)
+	}
+	if got := after.Setting("WS_ALLOWED_ORIGINS"); got != "https://a.example,https://b.example" {
+		t.Errorf("Setting = %q", got)
+	}
+}
+
+func TestLoad_RejectsUnknownExtension(t *testing.T) {
+	path := writeFile(t, "config.json", `{}`)
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "unsupported extension") {
+		t.Errorf("expected an unsupported extension error, got %v", err)
+	}
+}
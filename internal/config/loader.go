@@ -0,0 +1,263 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// fileValue is a setting read from the config file.
+type fileValue struct {
+	key   string // as written in the file, e.g. "retry.max_attempts"
+	value string
+}
+
+// loader looks settings up by their environment variable name, falling back
+// to the config file and then to the default. Problems are collected rather
+// than returned so Load can report all of them at once.
+type loader struct {
+	file     string               // base name of the config file, for messages
+	values   map[string]fileValue // keyed by environment variable name
+	used     map[string]bool
+	resolved map[string]string // effective value of every setting looked up
+	problems []string
+}
+
+// readFile loads a YAML or TOML file, chosen by its extension. Nested tables
+// are flattened into environment variable names, so
+//
+//	delivery:
+//	  max_idle_conns_per_host: 32
+//
+// sets DELIVERY_MAX_IDLE_CONNS_PER_HOST. Lists are joined with commas.
+func (l *loader) readFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+
+	var raw map[string]interface{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".toml":
+		err = toml.Unmarshal(data, &raw)
+	default:
+		return fmt.Errorf("config file %s: unsupported extension %q, use .yaml, .yml or .toml", path, ext)
+	}
+	if err != nil {
+		return fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+
+	l.file = filepath.Base(path)
+	l.values = make(map[string]fileValue)
+	l.used = make(map[string]bool)
+	l.flatten("", raw)
+	return nil
+}
+
+func (l *loader) flatten(prefix string, m map[string]interface{}) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		v := m[k]
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		if nested, ok := v.(map[string]interface{}); ok {
+			l.flatten(key, nested)
+			continue
+		}
+
+		value, ok := scalarString(v)
+		if !ok {
+			l.fail("%s (%s): expected a value or a list of values", key, l.file)
+			continue
+		}
+		name := strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(key))
+		if prev, dup := l.values[name]; dup {
+			l.fail("%s (%s): set twice, also as %s", key, l.file, prev.key)
+			continue
+		}
+		l.values[name] = fileValue{key: key, value: value}
+	}
+}
+
+func scalarString(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case nil:
+		return "", true
+	case string:
+		return v, true
+	case bool, int, int64, uint64, float64:
+		return fmt.Sprint(v), true
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := scalarString(item)
+			if !ok {
+				return "", false
+			}
+			items = append(items, s)
+		}
+		return strings.Join(items, ","), true
+	}
+	return "", false
+}
+
+// lookup returns the raw value of a setting and where it came from. An empty
+// value counts as unset.
+func (l *loader) lookup(name string) (value, source string, ok bool) {
+	fv, inFile := l.values[name]
+	if inFile {
+		l.used[name] = true
+	}
+	if val := os.Getenv(name); val != "" {
+		return val, name + " (environment)", true
+	}
+	if inFile && fv.value != "" {
+		return fv.value, fmt.Sprintf("%s (%s)", fv.key, l.file), true
+	}
+	return "", "", false
+}
+
+// resolve records the value a setting ended up with.
+func (l *loader) resolve(name string, value interface{}) {
+	if l.resolved == nil {
+		l.resolved = make(map[string]string)
+	}
+	l.resolved[name] = fmt.Sprint(value)
+}
+
+func (l *loader) str(name, fallback string) string {
+	val, _, ok := l.lookup(name)
+	if !ok {
+		val = fallback
+	}
+	l.resolve(name, val)
+	return val
+}
+
+func (l *loader) int(name string, fallback int) int {
+	n := fallback
+	if val, source, ok := l.lookup(name); ok {
+		var err error
+		if n, err = strconv.Atoi(val); err != nil {
+			l.fail("%s: %q is not an integer", source, val)
+			n = fallback
+		}
+	}
+	l.resolve(name, n)
+	return n
+}
+
+func (l *loader) bool(name string, fallback bool) bool {
+	b := fallback
+	if val, source, ok := l.lookup(name); ok {
+		var err error
+		if b, err = strconv.ParseBool(val); err != nil {
+			l.fail("%s: %q is not a boolean", source, val)
+			b = fallback
+		}
+	}
+	l.resolve(name, b)
+	return b
+}
+
+func (l *loader) float(name string, fallback float64) float64 {
+	f := fallback
+	if val, source, ok := l.lookup(name); ok {
+		var err error
+		if f, err = strconv.ParseFloat(val, 64); err != nil {
+			l.fail("%s: %q is not a number", source, val)
+			f = fallback
+		}
+	}
+	l.resolve(name, f)
+	return f
+}
+
+// list splits a comma-separated value, dropping empty entries.
+func (l *loader) list(name string) []string {
+	val, _, _ := l.lookup(name)
+	var list []string
+	for _, item := range strings.Split(val, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	l.resolve(name, strings.Join(list, ","))
+	return list
+}
+
+// duration parses values like "30s" or "5m".
+func (l *loader) duration(name string, fallback time.Duration) time.Duration {
+	d := fallback
+	if val, source, ok := l.lookup(name); ok {
+		var err error
+		if d, err = time.ParseDuration(val); err != nil {
+			l.fail("%s: %q is not a duration like 30s or 5m", source, val)
+			d = fallback
+		}
+	}
+	l.resolve(name, d)
+	return d
+}
+
+func (l *loader) fail(format string, args ...interface{}) {
+	l.problems = append(l.problems, fmt.Sprintf(format, args...))
+}
+
+func (l *loader) atLeast(name string, n, min int) {
+	if n < min {
+		l.fail("%s must be at least %d, got %d", name, min, n)
+	}
+}
+
+func (l *loader) positive(name string, d time.Duration) {
+	if d <= 0 {
+		l.fail("%s must be positive, got %s", name, d)
+	}
+}
+
+func (l *loader) fraction(name string, f float64) {
+	if f < 0 || f > 1 {
+		l.fail("%s must be between 0 and 1, got %g", name, f)
+	}
+}
+
+func (l *loader) fileExists(name, path string) {
+	if path == "" {
+		return
+	}
+	if _, err := os.Stat(path); err != nil {
+		l.fail("%s: %v", name, err)
+	}
+}
+
+// rejectUnknownKeys reports file settings no lookup asked for, which are
+// most likely typos.
+func (l *loader) rejectUnknownKeys() {
+	var unknown []string
+	for name, fv := range l.values {
+		if !l.used[name] {
+			unknown = append(unknown, fv.key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		l.fail("%s (%s): unknown setting", key, l.file)
+	}
+}
@@ -0,0 +1,50 @@
+package deadletter
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+)
+
+type expiringStore struct {
+	remaining int64
+	calls     int
+	cutoff    time.Time
+}
+
+func (s *expiringStore) ExpireDeadLetters(_ context.Context, cutoff time.Time, limit int) (int64, error) {
+	s.calls++
+	s.cutoff = cutoff
+	n := min(s.remaining, int64(limit))
+	s.remaining -= n
+	return n, nil
+}
+
+func TestMonitor_ExpiresInBatches(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	s := &expiringStore{remaining: 2500}
+	m := NewMonitor(s, MonitorConfig{Expiry: 24 * time.Hour}, logger)
+
+	if err := m.RunOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if s.remaining != 0 || s.calls != 3 {
+		t.Errorf("remaining = %d after %d calls, want 0 after 3", s.remaining, s.calls)
+	}
+	if age := time.Since(s.cutoff); age < 24*time.Hour || age > 25*time.Hour {
+		t.Errorf("cutoff is %s old, want 24h", age)
+	}
+}
+
+func TestMonitor_ZeroExpiryDoesNothing(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	s := &expiringStore{remaining: 10}
+	if err := NewMonitor(s, MonitorConfig{}, logger).RunOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if s.calls != 0 {
+		t.Errorf("ExpireDeadLetters called %d times", s.calls)
+	}
+}
@@ -0,0 +1,95 @@
+// Package deadletter runs background maintenance on the dead letter queue:
+// expiring entries nobody resolved and replaying the dead letters of
+// subscribers that recovered. Alerts on dead letters are raised by the
+// alerting package.
+package deadletter
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// MonitorConfig configures a Monitor. A zero Expiry disables auto-expiry.
+type MonitorConfig struct {
+	Expiry   time.Duration
+	Interval time.Duration
+}
+
+// MonitorStore is the dead letter maintenance a Monitor runs against.
+type MonitorStore interface {
+	ExpireDeadLetters(ctx context.Context, cutoff time.Time, limit int) (int64, error)
+}
+
+// Monitor periodically auto-resolves dead letters older than the expiry.
+//
+// Every replica runs a monitor. Expiry claims rows with SKIP LOCKED, so
+// replicas never resolve the same dead letter twice.
+type Monitor struct {
+	store     MonitorStore
+	cfg       MonitorConfig
+	batchSize int
+	logger    *slog.Logger
+}
+
+func NewMonitor(s MonitorStore, cfg MonitorConfig, logger *slog.Logger) *Monitor {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Minute
+	}
+	return &Monitor{
+		store:     s,
+		cfg:       cfg,
+		batchSize: 1000,
+		logger:    logger,
+	}
+}
+
+// Start runs a pass immediately and then on every interval until the context
+// is cancelled.
+func (m *Monitor) Start(ctx context.Context) {
+	m.logger.Info("dead letter monitor started", "expiry", m.cfg.Expiry.String())
+
+	ticker := time.NewTicker(m.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := m.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			m.logger.Error("dead letter monitor pass failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			m.logger.Info("dead letter monitor stopping")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce expires aged dead letters.
+func (m *Monitor) RunOnce(ctx context.Context) error {
+	if m.cfg.Expiry <= 0 {
+		return nil
+	}
+	return m.expire(ctx)
+}
+
+func (m *Monitor) expire(ctx context.Context) error {
+	cutoff := time.Now().Add(-m.cfg.Expiry)
+	var total int64
+	for {
+		n, err := m.store.ExpireDeadLetters(ctx, cutoff, m.batchSize)
+		if err != nil {
+			return err
+		}
+		total += n
+		if n < int64(m.batchSize) {
+			break
+		}
+	}
+
+	if total > 0 {
+		m.logger.Info("expired dead letters", "count", total, "cutoff", cutoff)
+	}
+	return nil
+}
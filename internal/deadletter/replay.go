@@ -0,0 +1,358 @@
+package deadletter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// replayScheduleKey is a sorted set of the subscribers with a replay
+	// under way, scored by when their next batch is due (Unix ms).
+	replayScheduleKey = "dlq_replay:schedule"
+	// replayLease is how long a claimed batch is hidden from other
+	// instances. A batch that outlives it, or whose instance dies, is run
+	// again once it expires.
+	replayLease = 30 * time.Second
+	// replayClaimLimit caps the subscribers one pass replays to.
+	replayClaimLimit = 100
+)
+
+// replayStateKey holds a replay's progress: the creation time (Unix ns)
+// that dead letters must predate, the cursor of the last one replayed and
+// how many have been replayed so far.
+func replayStateKey(subscriberID string) string {
+	return "dlq_replay:" + subscriberID
+}
+
+// claimReplaysScript claims up to ARGV[3] subscribers whose batch is due by
+// ARGV[1] in the schedule (KEYS[1]) by moving them to ARGV[2], the end of
+// their lease, and returns them.
+var claimReplaysScript = redis.NewScript(`
+local due = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, tonumber(ARGV[3]))
+for _, id in ipairs(due) do
+	redis.call('ZADD', KEYS[1], ARGV[2], id)
+end
+return due
+`)
+
+// releaseReplayScript ends a claim on the replay of subscriber ARGV[1] that
+// is still scored at its lease ARGV[2] in the schedule (KEYS[1]): it saves
+// the cursor ARGV[4], ARGV[5] and the ARGV[6] dead letters replayed to the
+// state (KEYS[2]) and schedules the next batch at ARGV[3], or removes the
+// replay when ARGV[3] is 0. A replay scheduled again meanwhile is left as
+// it is.
+var releaseReplayScript = redis.NewScript(`
+if tonumber(redis.call('ZSCORE', KEYS[1], ARGV[1])) ~= tonumber(ARGV[2]) then
+	return 0
+end
+if ARGV[3] == '0' then
+	redis.call('ZREM', KEYS[1], ARGV[1])
+	redis.call('DEL', KEYS[2])
+	return 1
+end
+redis.call('ZADD', KEYS[1], ARGV[3], ARGV[1])
+if ARGV[5] ~= '' then
+	redis.call('HSET', KEYS[2], 'after_at', ARGV[4], 'after_id', ARGV[5])
+end
+redis.call('HINCRBY', KEYS[2], 'replayed', ARGV[6])
+return 1
+`)
+
+// ReplayConfig configures a Replayer.
+type ReplayConfig struct {
+	// HealthyPeriod is how long a circuit must stay closed after an outage
+	// before the subscriber's dead letters are replayed.
+	HealthyPeriod time.Duration
+	// Rate is how many dead letters of each subscriber are replayed per
+	// Interval.
+	Rate     int
+	Interval time.Duration
+}
+
+// ReplayStore is what a Replayer reads and resolves dead letters with.
+type ReplayStore interface {
+	GetSubscriber(ctx context.Context, id string) (*domain.Subscriber, error)
+	GetEvent(ctx context.Context, id string) (*domain.Event, error)
+	ListDeadLetters(ctx context.Context, f store.DeadLetterFilter) ([]domain.DeadLetter, error)
+	GetDeadLetter(ctx context.Context, id string) (*domain.DeadLetter, error)
+	ResolveDeadLetter(ctx context.Context, id string, resolvedBy string) error
+	InsertAuditEntry(ctx context.Context, e *domain.AuditEntry) error
+}
+
+// Redeliverer queues a fresh delivery of an event to one subscriber, as
+// engine.FanOutEngine does.
+type Redeliverer interface {
+	Redeliver(ctx context.Context, event *domain.Event, sub *domain.Subscriber) error
+}
+
+// Replayer replays the unresolved dead letters of subscribers that opted in
+// with auto_replay_dead_letters once they recover from an outage. Schedule
+// is registered with CircuitBreaker.OnClose; when the circuit has then
+// stayed closed for the healthy period, the dead letters created before it
+// closed are queued again, oldest first, Rate at a time, and resolved as
+// "auto_replay". The replay stops if the circuit opens again or the
+// subscriber opts out, is deactivated or is deleted.
+//
+// Every replica runs a replayer. Replays are kept in Redis and each batch is
+// claimed for a lease, so a batch is run by one instance at a time and a
+// replay carries on when the instance running it stops.
+type Replayer struct {
+	store          ReplayStore
+	redeliverer    Redeliverer
+	circuitBreaker *engine.CircuitBreaker
+	redisClient    *redis.Client
+	cfg            ReplayConfig
+	logger         *slog.Logger
+	now            func() time.Time
+}
+
+func NewReplayer(s ReplayStore, r Redeliverer, cb *engine.CircuitBreaker, redisClient *redis.Client, cfg ReplayConfig, logger *slog.Logger) *Replayer {
+	if cfg.Rate <= 0 {
+		cfg.Rate = 10
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Second
+	}
+	return &Replayer{
+		store:          s,
+		redeliverer:    r,
+		circuitBreaker: cb,
+		redisClient:    redisClient,
+		cfg:            cfg,
+		logger:         logger,
+		now:            time.Now,
+	}
+}
+
+// Schedule starts a replay of the subscriber's dead letters once its circuit
+// has stayed closed for the healthy period after closedAt. Dead letters
+// created after closedAt are not replayed. A replay already scheduled or
+// under way for the subscriber starts over. Schedule fits
+// CircuitBreaker.OnClose; whether the subscriber opted in is checked when
+// the replay is due, so it can still opt in or out until then.
+func (r *Replayer) Schedule(ctx context.Context, subscriberID string, closedAt time.Time) {
+	key := replayStateKey(subscriberID)
+	pipe := r.redisClient.TxPipeline()
+	pipe.Del(ctx, key)
+	pipe.HSet(ctx, key, "until", closedAt.UnixNano())
+	pipe.ZAdd(ctx, replayScheduleKey, redis.Z{
+		Score:  float64(closedAt.Add(r.cfg.HealthyPeriod).UnixMilli()),
+		Member: subscriberID,
+	})
+	if _, err := pipe.Exec(ctx); err != nil {
+		r.logger.Error("failed to schedule dead letter replay", "error", err, "subscriber_id", subscriberID)
+	}
+}
+
+// Start runs a pass immediately and then on every interval until the
+// context is cancelled.
+func (r *Replayer) Start(ctx context.Context) {
+	r.logger.Info("dead letter replayer started",
+		"healthy_period", r.cfg.HealthyPeriod.String(),
+		"rate", r.cfg.Rate,
+	)
+
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			r.logger.Error("dead letter replay pass failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			r.logger.Info("dead letter replayer stopping")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce replays the next batch of every replay that is due.
+func (r *Replayer) RunOnce(ctx context.Context) error {
+	now := r.now()
+	lease := now.Add(replayLease).UnixMilli()
+	due, err := claimReplaysScript.Run(ctx, r.redisClient, []string{replayScheduleKey},
+		now.UnixMilli(), lease, replayClaimLimit).StringSlice()
+	if err != nil {
+		return fmt.Errorf("claiming dead letter replays: %w", err)
+	}
+
+	var errs []error
+	for _, subscriberID := range due {
+		errs = append(errs, r.replayBatch(ctx, subscriberID, lease))
+	}
+	return errors.Join(errs...)
+}
+
+// replayBatch replays the next batch of a subscriber's dead letters, then
+// releases its claim, leased until lease, with the batch due next or the
+// replay ended. A batch that fails is retried one lease later, after the
+// dead letters it did replay.
+func (r *Replayer) replayBatch(ctx context.Context, subscriberID string, lease int64) error {
+	state, err := r.redisClient.HGetAll(ctx, replayStateKey(subscriberID)).Result()
+	if err != nil {
+		return fmt.Errorf("loading dead letter replay: %w", err)
+	}
+	until, _ := strconv.ParseInt(state["until"], 10, 64)
+	replayed, _ := strconv.Atoi(state["replayed"])
+
+	sub, err := r.store.GetSubscriber(ctx, subscriberID)
+	if err != nil {
+		return err
+	}
+	if reason := r.stopReason(ctx, sub, until); reason != "" {
+		if replayed > 0 {
+			r.logger.Info("dead letter auto-replay stopped",
+				"subscriber_id", subscriberID,
+				"reason", reason,
+				"replayed", replayed,
+			)
+		}
+		return r.release(ctx, subscriberID, lease, time.Time{}, nil, 0)
+	}
+
+	unresolved := false
+	filter := store.DeadLetterFilter{
+		SubscriberID: subscriberID,
+		Resolved:     &unresolved,
+		Until:        time.Unix(0, until),
+		OldestFirst:  true,
+		Limit:        r.cfg.Rate,
+	}
+	if id := state["after_id"]; id != "" {
+		afterAt, _ := strconv.ParseInt(state["after_at"], 10, 64)
+		filter.After = &store.PageCursor{CreatedAt: time.Unix(0, afterAt), ID: id}
+	}
+	letters, err := r.store.ListDeadLetters(ctx, filter)
+	if err != nil {
+		return err
+	}
+	var after *store.PageCursor
+	n := 0
+	for _, dl := range letters {
+		ok, err := r.replay(ctx, sub, dl)
+		if err != nil {
+			r.release(ctx, subscriberID, lease, r.now().Add(replayLease), after, n)
+			return err
+		}
+		if ok {
+			n++
+		}
+		after = &store.PageCursor{CreatedAt: dl.CreatedAt, ID: dl.ID}
+	}
+
+	if len(letters) < r.cfg.Rate {
+		if replayed+n > 0 {
+			r.logger.Info("dead letter auto-replay finished",
+				"subscriber_id", subscriberID,
+				"replayed", replayed+n,
+			)
+		}
+		return r.release(ctx, subscriberID, lease, time.Time{}, nil, 0)
+	}
+	return r.release(ctx, subscriberID, lease, r.now().Add(r.cfg.Interval), after, n)
+}
+
+// stopReason explains why a replay of sub's dead letters should not go on,
+// or returns "" if it should.
+func (r *Replayer) stopReason(ctx context.Context, sub *domain.Subscriber, until int64) string {
+	switch {
+	case until == 0:
+		return "replay state lost"
+	case sub == nil || sub.Deleted():
+		return "subscriber deleted"
+	case !sub.IsActive:
+		return "subscriber deactivated"
+	case !sub.AutoReplayDeadLetters:
+		return "auto-replay turned off"
+	}
+	if state := r.circuitBreaker.GetState(ctx, sub.ID); state.State != engine.StateClosed {
+		return "circuit " + state.State
+	}
+	return ""
+}
+
+// replay queues a dead letter's event for sub again and resolves the dead
+// letter. It reports false for a dead letter whose event is gone, which is
+// left unresolved.
+func (r *Replayer) replay(ctx context.Context, sub *domain.Subscriber, dl domain.DeadLetter) (bool, error) {
+	// Prefer the live event row and fall back to the dead letter's snapshot
+	// once the event has been pruned, as manual replays do
+	event, err := r.store.GetEvent(ctx, dl.EventID)
+	if err != nil {
+		return false, err
+	}
+	if event == nil {
+		full, err := r.store.GetDeadLetter(ctx, dl.ID)
+		if err != nil {
+			return false, err
+		}
+		if full != nil {
+			event = full.SnapshotEvent()
+		}
+	}
+	if event == nil {
+		r.logger.Warn("skipping dead letter replay, event no longer exists",
+			"dead_letter_id", dl.ID,
+			"event_id", dl.EventID,
+			"subscriber_id", sub.ID,
+		)
+		return false, nil
+	}
+
+	if err := r.redeliverer.Redeliver(ctx, event, sub); err != nil {
+		return false, err
+	}
+
+	// A concurrent resolve may win the race; the redelivery is queued either way
+	r.store.ResolveDeadLetter(ctx, dl.ID, domain.DeadLetterAutoReplayed)
+
+	details, _ := json.Marshal(map[string]string{
+		"event_id":      dl.EventID,
+		"subscriber_id": sub.ID,
+	})
+	err = r.store.InsertAuditEntry(ctx, &domain.AuditEntry{
+		Actor:      domain.SystemActor,
+		Action:     domain.AuditDeadLetterReplay,
+		EntityType: domain.AuditEntityDeadLetter,
+		EntityID:   dl.ID,
+		Details:    details,
+	})
+	if err != nil {
+		r.logger.Error("failed to write audit entry", "error", err, "action", domain.AuditDeadLetterReplay)
+	}
+	return true, nil
+}
+
+// release ends the claim on a subscriber's replay leased until lease. A
+// zero next ends the replay; otherwise its next batch is due at next, after
+// the cursor, with n more dead letters replayed.
+func (r *Replayer) release(ctx context.Context, subscriberID string, lease int64, next time.Time, after *store.PageCursor, n int) error {
+	var nextScore, afterAt int64
+	var afterID string
+	if !next.IsZero() {
+		nextScore = next.UnixMilli()
+	}
+	if after != nil {
+		afterAt, afterID = after.CreatedAt.UnixNano(), after.ID
+	}
+	err := releaseReplayScript.Run(ctx, r.redisClient,
+		[]string{replayScheduleKey, replayStateKey(subscriberID)},
+		subscriberID, lease, nextScore, afterAt, afterID, n).Err()
+	if err != nil {
+		return fmt.Errorf("releasing dead letter replay: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package deadletter
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+type recordingRedeliverer struct {
+	events []string
+}
+
+func (r *recordingRedeliverer) Redeliver(_ context.Context, event *domain.Event, _ *domain.Subscriber) error {
+	r.events = append(r.events, event.ID)
+	return nil
+}
+
+// setupReplay returns a replayer replaying two dead letters a pass after a
+// minute of health, with a subscriber that opted in and three of its dead
+// letters, oldest first.
+func setupReplay(t *testing.T) (*Replayer, *recordingRedeliverer, *store.MemoryStore, *engine.CircuitBreaker, *domain.Subscriber, []string) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	ctx := context.Background()
+	s := store.NewMemoryStore()
+	sub, _ := s.CreateSubscriber(ctx, domain.CreateSubscriberRequest{
+		Name:                  "orders",
+		EndpointURL:           "https://orders.example.com/hook",
+		AutoReplayDeadLetters: true,
+	})
+	var eventIDs []string
+	for i := 0; i < 3; i++ {
+		event, _ := s.CreateEvent(ctx, "order.created", 1, []byte(`{}`), nil, "test", nil)
+		s.InsertDeadLetter(ctx, store.DeadLetterRecord{EventID: event.ID, SubscriberID: sub.ID, TotalAttempts: 5})
+		eventIDs = append(eventIDs, event.ID)
+	}
+
+	cb := engine.NewCircuitBreaker(client, logger)
+	redeliverer := &recordingRedeliverer{}
+	r := NewReplayer(s, redeliverer, cb, client, ReplayConfig{HealthyPeriod: time.Minute, Rate: 2}, logger)
+	return r, redeliverer, s, cb, sub, eventIDs
+}
+
+func TestReplayer_ReplaysAfterHealthyPeriod(t *testing.T) {
+	r, redeliverer, s, _, sub, eventIDs := setupReplay(t)
+	ctx := context.Background()
+
+	closedAt := time.Now()
+	r.Schedule(ctx, sub.ID, closedAt)
+	// Dead-lettered after the circuit closed, so not part of the outage
+	later, _ := s.CreateEvent(ctx, "order.created", 1, []byte(`{}`), nil, "test", nil)
+	s.InsertDeadLetter(ctx, store.DeadLetterRecord{EventID: later.ID, SubscriberID: sub.ID, TotalAttempts: 5})
+
+	r.now = func() time.Time { return closedAt.Add(30 * time.Second) }
+	r.RunOnce(ctx)
+	if len(redeliverer.events) != 0 {
+		t.Fatalf("replayed %v before the healthy period", redeliverer.events)
+	}
+
+	r.now = func() time.Time { return closedAt.Add(time.Minute) }
+	r.RunOnce(ctx)
+	if len(redeliverer.events) != 2 || redeliverer.events[0] != eventIDs[0] || redeliverer.events[1] != eventIDs[1] {
+		t.Fatalf("first batch replayed %v, want the two oldest of %v", redeliverer.events, eventIDs)
+	}
+	r.RunOnce(ctx)
+	if len(redeliverer.events) != 2 {
+		t.Fatalf("replayed %v before the next batch was due", redeliverer.events)
+	}
+
+	r.now = func() time.Time { return closedAt.Add(time.Minute + time.Second) }
+	r.RunOnce(ctx)
+	r.now = func() time.Time { return closedAt.Add(time.Minute + 2*time.Second) }
+	r.RunOnce(ctx)
+	if len(redeliverer.events) != 3 || redeliverer.events[2] != eventIDs[2] {
+		t.Fatalf("replayed %v, want every dead letter of the outage once", redeliverer.events)
+	}
+
+	unresolved := false
+	left, _ := s.ListDeadLetters(ctx, store.DeadLetterFilter{SubscriberID: sub.ID, Resolved: &unresolved})
+	if len(left) != 1 || left[0].EventID != later.ID {
+		t.Errorf("unresolved dead letters = %+v, want only the later one", left)
+	}
+	if n, _ := r.redisClient.Exists(ctx, replayScheduleKey, replayStateKey(sub.ID)).Result(); n != 0 {
+		t.Errorf("%d replay keys left after the replay finished", n)
+	}
+	entries, _ := s.ListAuditEntries(ctx, domain.AuditFilter{Action: domain.AuditDeadLetterReplay})
+	if len(entries) != 3 || entries[0].Actor != domain.SystemActor {
+		t.Errorf("audit entries = %+v, want one by the system per replay", entries)
+	}
+}
+
+func TestReplayer_StopsWhenCircuitReopens(t *testing.T) {
+	r, redeliverer, _, cb, sub, _ := setupReplay(t)
+	ctx := context.Background()
+
+	closedAt := time.Now()
+	r.Schedule(ctx, sub.ID, closedAt)
+	for i := 0; i < 5; i++ {
+		cb.RecordFailure(ctx, sub.ID)
+	}
+
+	r.now = func() time.Time { return closedAt.Add(time.Minute) }
+	r.RunOnce(ctx)
+	if len(redeliverer.events) != 0 {
+		t.Errorf("replayed %v while the circuit was open", redeliverer.events)
+	}
+	if n, _ := r.redisClient.Exists(ctx, replayScheduleKey).Result(); n != 0 {
+		t.Error("replay still scheduled after the circuit reopened")
+	}
+}
@@ -0,0 +1,65 @@
+package domain
+
+import "time"
+
+// Role controls what an API key may do. Each role includes the permissions
+// of the roles below it.
+type Role string
+
+const (
+	// RoleViewer can only read.
+	RoleViewer Role = "viewer"
+	// RoleOperator can also publish events and resolve or replay dead letters.
+	RoleOperator Role = "operator"
+	// RoleAdmin can also manage subscribers and API keys and read the audit log.
+	RoleAdmin Role = "admin"
+)
+
+var roleRank = map[Role]int{
+	RoleViewer:   1,
+	RoleOperator: 2,
+	RoleAdmin:    3,
+}
+
+// Valid reports whether r is a known role.
+func (r Role) Valid() bool {
+	_, ok := roleRank[r]
+	return ok
+}
+
+// Includes reports whether r grants everything required grants.
+func (r Role) Includes(required Role) bool {
+	return r.Valid() && roleRank[r] >= roleRank[required]
+}
+
+// APIKey is a credential for the API. Only a SHA-256 hash of the key is
+// stored; the plaintext is returned once, when the key is created.
+//
+// A key with a SubscriberID is scoped to that subscriber. Scoped keys are
+// handed to the subscriber's own team and can only call the self-service
+// portal API, which shows nothing but the subscriber's own data.
+type APIKey struct {
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	KeyPrefix    string     `json:"key_prefix"`
+	Role         Role       `json:"role"`
+	SubscriberID *string    `json:"subscriber_id,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	LastUsedAt   *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name         string `json:"name"`
+	Role         Role   `json:"role,omitempty"`
+	SubscriberID string `json:"subscriber_id,omitempty"`
+}
+
+type CreateAPIKeyResponse struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+type UpdateAPIKeyRoleRequest struct {
+	Role Role `json:"role"`
+}
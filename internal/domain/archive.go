@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+type ArchiveManifest struct {
+	ID        string    `json:"id"`
+	TableName string    `json:"table_name"`
+	Bucket    string    `json:"bucket"`
+	ObjectKey string    `json:"object_key"`
+	RowCount  int       `json:"row_count"`
+	ByteSize  int64     `json:"byte_size"`
+	SHA256    string    `json:"sha256"`
+	OldestAt  time.Time `json:"oldest_at"`
+	NewestAt  time.Time `json:"newest_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Audit actions recorded for management mutations.
+const (
+	AuditSubscriberCreate  = "subscriber.create"
+	AuditSubscriberUpdate  = "subscriber.update"
+	AuditSubscriberPause   = "subscriber.pause"
+	AuditSubscriberResume  = "subscriber.resume"
+	AuditSubscriberPurge   = "subscriber.purge"
+	AuditSubscriberPing    = "subscriber.ping"
+	AuditSubscriberDelete  = "subscriber.delete"
+	AuditSubscriberRestore = "subscriber.restore"
+	AuditSubscriberCleanup = "subscriber.cleanup"
+	AuditSubscriberExport  = "subscriber.export"
+	AuditDeadLetterResolve = "dead_letter.resolve"
+	AuditDeadLetterReplay  = "dead_letter.replay"
+	AuditDeadLetterExpire  = "dead_letter.expire"
+	AuditAPIKeyCreate      = "api_key.create"
+	AuditAPIKeyRevoke      = "api_key.revoke"
+	AuditAPIKeyRoleChange  = "api_key.role_change"
+	AuditEventTypeDescribe = "event_type.describe"
+	AuditQueuePurge        = "queue.purge"
+	AuditConfigReload      = "config.reload"
+	AuditChaosUpdate       = "chaos.update"
+)
+
+// SystemActor is recorded for changes made by background jobs rather than
+// through the API.
+const SystemActor = "system"
+
+// Audited entity types.
+const (
+	AuditEntitySubscriber = "subscriber"
+	AuditEntityDeadLetter = "dead_letter"
+	AuditEntityAPIKey     = "api_key"
+	AuditEntityEventType  = "event_type"
+	AuditEntityQueue      = "queue"
+	AuditEntityConfig     = "config"
+)
+
+// AuditEntry records who changed what through the management API.
+type AuditEntry struct {
+	ID         string          `json:"id"`
+	Actor      string          `json:"actor"`
+	ActorKeyID *string         `json:"actor_key_id,omitempty"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   string          `json:"entity_id"`
+	Details    json.RawMessage `json:"details,omitempty"`
+	RemoteAddr string          `json:"remote_addr,omitempty"`
+	RequestID  string          `json:"request_id,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// SubscriberChange is the audit detail recorded for a subscriber update.
+type SubscriberChange struct {
+	Before UpdateSubscriberRequest `json:"before"`
+	After  UpdateSubscriberRequest `json:"after"`
+}
+
+// AuditFilter narrows an audit log query. Zero values match everything.
+type AuditFilter struct {
+	Actor      string
+	Action     string
+	EntityType string
+	EntityID   string
+	Since      time.Time
+	Until      time.Time
+	Limit      int
+}
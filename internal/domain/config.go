@@ -0,0 +1,53 @@
+package domain
+
+import "fmt"
+
+// ConfigReload reports what re-reading the configuration changed on one
+// instance. Settings are named by their environment variables.
+type ConfigReload struct {
+	InstanceID string `json:"instance_id"`
+	// Applied lists the changes now in effect.
+	Applied []ConfigChange `json:"applied"`
+	// RestartRequired lists settings that differ from the running
+	// configuration but only take effect on restart.
+	RestartRequired []string `json:"restart_required"`
+}
+
+// ConfigChange is a runtime setting that changed on reload.
+type ConfigChange struct {
+	Setting string `json:"setting"`
+	From    string `json:"from"`
+	To      string `json:"to"`
+}
+
+// MaxChaosDispatchDelayMs caps the dispatch delay chaos mode can inject.
+const MaxChaosDispatchDelayMs = 60_000
+
+// ChaosSettings are the faults injected into one instance's pipeline in
+// chaos mode, for testing retries, circuit breakers and dead-lettering.
+// Zero values inject nothing.
+type ChaosSettings struct {
+	// RedisErrorRate is the fraction of Redis commands, from 0 to 1, that
+	// fail without being sent.
+	RedisErrorRate float64 `json:"redis_error_rate"`
+	// DispatchDelayMs holds every batch of claimed jobs this long before
+	// workers get it.
+	DispatchDelayMs int `json:"dispatch_delay_ms"`
+	// DeliveryTimeoutRate is the fraction of delivery requests, from 0 to 1,
+	// that hang until they time out without being sent.
+	DeliveryTimeoutRate float64 `json:"delivery_timeout_rate"`
+}
+
+// Validate checks that the rates are fractions and the delay is in range.
+func (s ChaosSettings) Validate() error {
+	if s.RedisErrorRate < 0 || s.RedisErrorRate > 1 {
+		return fmt.Errorf("redis_error_rate must be between 0 and 1")
+	}
+	if s.DeliveryTimeoutRate < 0 || s.DeliveryTimeoutRate > 1 {
+		return fmt.Errorf("delivery_timeout_rate must be between 0 and 1")
+	}
+	if s.DispatchDelayMs < 0 || s.DispatchDelayMs > MaxChaosDispatchDelayMs {
+		return fmt.Errorf("dispatch_delay_ms must be between 0 and %d", MaxChaosDispatchDelayMs)
+	}
+	return nil
+}
@@ -1,31 +1,162 @@
 package domain
 
 import (
+	"encoding/json"
 	"time"
 )
 
+// DeliveryAttempt is one try at delivering an event to a subscriber.
+// ResponseHeaders holds the captured subset of the endpoint's response
+// headers, keyed by canonical header name.
 type DeliveryAttempt struct {
-	ID             string     `json:"id"`
-	EventID        string     `json:"event_id"`
-	SubscriberID   string     `json:"subscriber_id"`
-	AttemptNumber  int        `json:"attempt_number"`
-	Status         string     `json:"status"`
-	HTTPStatusCode *int       `json:"http_status_code,omitempty"`
-	ResponseBody   *string    `json:"response_body,omitempty"`
-	ResponseTimeMs *int       `json:"response_time_ms,omitempty"`
-	ErrorMessage   *string    `json:"error_message,omitempty"`
-	NextRetryAt    *time.Time `json:"next_retry_at,omitempty"`
-	CreatedAt      time.Time  `json:"created_at"`
+	ID              string            `json:"id"`
+	EventID         string            `json:"event_id"`
+	SubscriberID    string            `json:"subscriber_id"`
+	AttemptNumber   int               `json:"attempt_number"`
+	Status          string            `json:"status"`
+	HTTPStatusCode  *int              `json:"http_status_code,omitempty"`
+	ResponseBody    *string           `json:"response_body,omitempty"`
+	ResponseHeaders map[string]string `json:"response_headers,omitempty"`
+	ResponseTimeMs  *int              `json:"response_time_ms,omitempty"`
+	Timings         *DeliveryTimings  `json:"timings,omitempty"`
+	ErrorMessage    *string           `json:"error_message,omitempty"`
+	FailureReason   *string           `json:"failure_reason,omitempty"`
+	NextRetryAt     *time.Time        `json:"next_retry_at,omitempty"`
+	// FinalURL is the URL the response came from, set when the attempt
+	// followed redirects away from the endpoint.
+	FinalURL  *string   `json:"final_url,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
+// AttemptSimulated is the status of the attempts of sandbox subscribers,
+// which are prepared but never sent. Delivery metrics leave them out.
+const AttemptSimulated = "simulated"
+
+// DeliveryTimings breaks an attempt's request down into phases, in
+// milliseconds. Phases the request didn't go through are left out: DNS,
+// connect and TLS on a reused connection, and everything after the phase
+// a failed request stopped in. A phase still running when the request
+// failed is timed until the failure.
+type DeliveryTimings struct {
+	DNSMs     *int `json:"dns_ms,omitempty"`
+	ConnectMs *int `json:"connect_ms,omitempty"`
+	TLSMs     *int `json:"tls_ms,omitempty"`
+	// TTFBMs is the time from the request being sent to the first byte
+	// of the response, which is mostly the endpoint's processing time.
+	TTFBMs *int `json:"ttfb_ms,omitempty"`
+	// BodyReadMs is the time taken to read the response body.
+	BodyReadMs *int `json:"body_read_ms,omitempty"`
+	// ConnReused is set when the request went over a kept-alive connection.
+	ConnReused bool `json:"conn_reused"`
+}
+
+// FailureReason classifies why a delivery attempt failed, so failures can
+// be counted by cause instead of by their free-text error message.
+type FailureReason string
+
+const (
+	FailureDNS             FailureReason = "dns_error"         // the endpoint's hostname did not resolve
+	FailureConnectTimeout  FailureReason = "connect_timeout"   // no connection within the dial timeout
+	FailureConnection      FailureReason = "connection_error"  // refused, reset or unreachable
+	FailureBlockedAddress  FailureReason = "blocked_address"   // refused by DELIVERY_BLOCK_PRIVATE_IPS
+	FailureTLS             FailureReason = "tls_error"         // handshake or certificate failure
+	FailureReadTimeout     FailureReason = "read_timeout"      // connected, but no response in time
+	FailureHTTP4xx         FailureReason = "http_4xx"          // other 4xx responses
+	FailureHTTP5xx         FailureReason = "http_5xx"          // 5xx responses
+	FailureHTTPOther       FailureReason = "http_other"        // 1xx or unfollowed 3xx responses
+	FailurePayloadTooLarge FailureReason = "payload_too_large" // 413 responses
+	FailureRateLimited     FailureReason = "rate_limited"      // 429 responses
+	FailureInternal        FailureReason = "internal_error"    // the request could not be built
+	FailurePanic           FailureReason = "panic"             // delivering the job kept crashing the worker
+	FailureUnexpectedBody  FailureReason = "unexpected_body"   // 2xx responses failing the subscriber's success condition
+)
+
+// StatusFailureReason classifies a response whose status code is not 2xx.
+func StatusFailureReason(statusCode int) FailureReason {
+	switch {
+	case statusCode == 413:
+		return FailurePayloadTooLarge
+	case statusCode == 429:
+		return FailureRateLimited
+	case statusCode >= 500:
+		return FailureHTTP5xx
+	case statusCode >= 400:
+		return FailureHTTP4xx
+	default:
+		return FailureHTTPOther
+	}
+}
+
+// DeadLetterExpired is the resolved_by value given to dead letters that were
+// auto-resolved after sitting unresolved past the configured expiry.
+const DeadLetterExpired = "expired"
+
+// DeadLetterSubscriberRemoved is the resolved_by value given to dead letters
+// auto-resolved when their subscriber was deleted or deactivated.
+const DeadLetterSubscriberRemoved = "subscriber_removed"
+
+// DeadLetterAutoReplayed is the resolved_by value given to dead letters
+// replayed automatically after their subscriber recovered from an outage.
+const DeadLetterAutoReplayed = "auto_replay"
+
+// DeadLetter is a delivery that exhausted its retries. It keeps a snapshot of
+// the event so it can still be inspected and replayed once the event row has
+// been pruned. Payload is only loaded for single dead letter lookups.
 type DeadLetter struct {
-	ID             string     `json:"id"`
-	EventID        string     `json:"event_id"`
-	SubscriberID   string     `json:"subscriber_id"`
-	TotalAttempts  int        `json:"total_attempts"`
-	LastError      *string    `json:"last_error,omitempty"`
-	LastHTTPStatus *int       `json:"last_http_status,omitempty"`
-	CreatedAt      time.Time  `json:"created_at"`
-	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
-	ResolvedBy     *string    `json:"resolved_by,omitempty"`
+	ID             string          `json:"id"`
+	EventID        string          `json:"event_id"`
+	SubscriberID   string          `json:"subscriber_id"`
+	EventType      *string         `json:"event_type,omitempty"`
+	EventSource    *string         `json:"event_source,omitempty"`
+	Payload        json.RawMessage `json:"payload,omitempty"`
+	TotalAttempts  int             `json:"total_attempts"`
+	LastError      *string         `json:"last_error,omitempty"`
+	LastHTTPStatus *int            `json:"last_http_status,omitempty"`
+	FailureReason  *string         `json:"failure_reason,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	ResolvedAt     *time.Time      `json:"resolved_at,omitempty"`
+	ResolvedBy     *string         `json:"resolved_by,omitempty"`
 }
+
+// DeadLetterGroup counts a subscriber's unresolved dead letters that failed
+// the same way: with the same failure reason and last HTTP status.
+type DeadLetterGroup struct {
+	SubscriberID   string    `json:"subscriber_id"`
+	SubscriberName string    `json:"subscriber_name"`
+	FailureReason  *string   `json:"failure_reason,omitempty"`
+	LastHTTPStatus *int      `json:"last_http_status,omitempty"`
+	Count          int64     `json:"count"`
+	FirstAt        time.Time `json:"first_at"`
+	LastAt         time.Time `json:"last_at"`
+}
+
+// SnapshotEvent rebuilds the dead-lettered event from the stored snapshot,
+// or returns nil if the dead letter predates snapshots and has none.
+func (dl *DeadLetter) SnapshotEvent() *Event {
+	if len(dl.Payload) == 0 || dl.EventType == nil {
+		return nil
+	}
+	event := &Event{
+		ID:        dl.EventID,
+		EventType: *dl.EventType,
+		Payload:   dl.Payload,
+	}
+	if dl.EventSource != nil {
+		event.Source = *dl.EventSource
+	}
+	return event
+}
+
+// IP address family preferences for delivery connections, set with
+// DELIVERY_IP_PREFERENCE. Endpoints resolving to both families are dialed
+// in the preferred one first, falling back to the other.
+const (
+	IPPreferenceAuto     = "auto"      // the resolver's order (RFC 6724)
+	IPPreferenceIPv4     = "ipv4"      // IPv4 first, then IPv6
+	IPPreferenceIPv6     = "ipv6"      // IPv6 first, then IPv4
+	IPPreferenceIPv4Only = "ipv4_only" // IPv4 addresses only
+	IPPreferenceIPv6Only = "ipv6_only" // IPv6 addresses only
+)
+
+// IPPreferences lists the valid IP address family preferences.
+var IPPreferences = []string{IPPreferenceAuto, IPPreferenceIPv4, IPPreferenceIPv6, IPPreferenceIPv4Only, IPPreferenceIPv6Only}
@@ -0,0 +1,253 @@
+package domain
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Endpoint types: what a subscriber's endpoint_url points at. Deliveries to
+// the queue types carry the same signed payload and X-Webhook-* headers as
+// HTTP ones, the headers as message attributes.
+const (
+	EndpointHTTP   = "http"   // an HTTP(S) webhook receiver
+	EndpointSQS    = "sqs"    // an Amazon SQS queue URL
+	EndpointPubSub = "pubsub" // a Google Cloud Pub/Sub topic
+	EndpointAMQP   = "amqp"   // an exchange on an AMQP 0-9-1 broker, such as RabbitMQ
+)
+
+// EndpointTypes lists the valid endpoint types.
+var EndpointTypes = []string{EndpointHTTP, EndpointSQS, EndpointPubSub, EndpointAMQP}
+
+// EndpointTypeOrDefault returns endpointType, or EndpointHTTP when it is
+// empty.
+func EndpointTypeOrDefault(endpointType string) string {
+	if endpointType == "" {
+		return EndpointHTTP
+	}
+	return endpointType
+}
+
+// EndpointCredentials are what deliveries to a queue endpoint authenticate
+// with; which fields apply depends on the endpoint type. They are secrets,
+// shown only to admins and never audited.
+type EndpointCredentials struct {
+	// AccessKeyID, SecretAccessKey and, for temporary credentials,
+	// SessionToken sign requests to SQS.
+	AccessKeyID     string `json:"access_key_id,omitempty"`
+	SecretAccessKey string `json:"secret_access_key,omitempty"`
+	SessionToken    string `json:"session_token,omitempty"`
+	// ServiceAccountKey is the JSON key of the Google service account that
+	// publishes to Pub/Sub.
+	ServiceAccountKey string `json:"service_account_key,omitempty"`
+	// Username and Password log in to an AMQP broker.
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// IsZero reports whether no credentials are set.
+func (c *EndpointCredentials) IsZero() bool {
+	return c == nil || *c == EndpointCredentials{}
+}
+
+// Redacted returns a copy of c with every secret replaced, for the audit
+// log. Identifiers that aren't secret are kept.
+func (c *EndpointCredentials) Redacted() *EndpointCredentials {
+	if c == nil {
+		return nil
+	}
+	redact := func(s string) string {
+		if s == "" {
+			return ""
+		}
+		return "[REDACTED]"
+	}
+	return &EndpointCredentials{
+		AccessKeyID:       c.AccessKeyID,
+		SecretAccessKey:   redact(c.SecretAccessKey),
+		SessionToken:      redact(c.SessionToken),
+		ServiceAccountKey: redact(c.ServiceAccountKey),
+		Username:          c.Username,
+		Password:          redact(c.Password),
+	}
+}
+
+// ValidateEndpoint checks a subscriber's endpoint type, URL and
+// credentials together. An empty endpointURL, allowed for sandbox
+// subscribers, is left to the caller.
+func ValidateEndpoint(endpointType, endpointURL string, creds *EndpointCredentials) error {
+	endpointType = EndpointTypeOrDefault(endpointType)
+	if !slices.Contains(EndpointTypes, endpointType) {
+		return fmt.Errorf("endpoint_type must be one of %s", strings.Join(EndpointTypes, ", "))
+	}
+	if creds == nil {
+		creds = &EndpointCredentials{}
+	}
+
+	switch endpointType {
+	case EndpointHTTP:
+		if !creds.IsZero() {
+			return errors.New("endpoint_credentials are only used by queue endpoint types")
+		}
+		return nil
+	case EndpointSQS:
+		if endpointURL != "" {
+			if _, err := ParseSQSQueueURL(endpointURL); err != nil {
+				return err
+			}
+		}
+		if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+			return errors.New("endpoint_credentials.access_key_id and secret_access_key are required for sqs endpoints")
+		}
+		if creds.ServiceAccountKey != "" || creds.Username != "" || creds.Password != "" {
+			return errors.New("sqs endpoints only take access_key_id, secret_access_key and session_token credentials")
+		}
+	case EndpointPubSub:
+		if endpointURL != "" {
+			if _, err := ParsePubSubTopicURL(endpointURL); err != nil {
+				return err
+			}
+		}
+		if creds.ServiceAccountKey == "" {
+			return errors.New("endpoint_credentials.service_account_key is required for pubsub endpoints")
+		}
+		if _, err := ParseServiceAccountKey(creds.ServiceAccountKey); err != nil {
+			return err
+		}
+		if creds.AccessKeyID != "" || creds.SecretAccessKey != "" || creds.SessionToken != "" || creds.Username != "" || creds.Password != "" {
+			return errors.New("pubsub endpoints only take service_account_key credentials")
+		}
+	case EndpointAMQP:
+		if endpointURL != "" {
+			if _, err := ParseAMQPEndpoint(endpointURL); err != nil {
+				return err
+			}
+		}
+		if creds.AccessKeyID != "" || creds.SecretAccessKey != "" || creds.SessionToken != "" || creds.ServiceAccountKey != "" {
+			return errors.New("amqp endpoints only take username and password credentials")
+		}
+	}
+	return nil
+}
+
+// SQSQueue is a parsed SQS queue URL.
+type SQSQueue struct {
+	URL    string
+	Region string
+	// FIFO queues need every message to have a group and deduplication ID.
+	FIFO bool
+}
+
+// sqsHost matches the hostnames of SQS's regional endpoints.
+var sqsHost = regexp.MustCompile(`^sqs\.([a-z0-9-]+)\.amazonaws\.com(\.cn)?$`)
+
+// ParseSQSQueueURL parses a queue URL such as
+// https://sqs.eu-west-1.amazonaws.com/123456789012/orders. The region is
+// taken from the hostname; other hosts, such as a local ElasticMQ or
+// LocalStack, are signed for us-east-1.
+func ParseSQSQueueURL(raw string) (SQSQueue, error) {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return SQSQueue{}, errors.New("endpoint_url of an sqs endpoint must be an http(s) queue URL")
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" || u.RawQuery != "" {
+		return SQSQueue{}, errors.New("endpoint_url of an sqs endpoint must be a queue URL ending in /<account>/<queue>")
+	}
+	queue := SQSQueue{URL: raw, Region: "us-east-1", FIFO: strings.HasSuffix(parts[1], ".fifo")}
+	if m := sqsHost.FindStringSubmatch(u.Hostname()); m != nil {
+		queue.Region = m[1]
+	}
+	return queue, nil
+}
+
+// pubsubTopicPath matches the path of a Pub/Sub topic's REST resource.
+var pubsubTopicPath = regexp.MustCompile(`^/v1/projects/[^/]+/topics/[^/:]+$`)
+
+// ParsePubSubTopicURL parses the URL of a topic's REST resource, such as
+// https://pubsub.googleapis.com/v1/projects/acme/topics/orders, and returns
+// the URL messages are published to.
+func ParsePubSubTopicURL(raw string) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" || u.RawQuery != "" || !pubsubTopicPath.MatchString(u.Path) {
+		return "", errors.New("endpoint_url of a pubsub endpoint must be a topic URL such as https://pubsub.googleapis.com/v1/projects/<project>/topics/<topic>")
+	}
+	return raw + ":publish", nil
+}
+
+// ServiceAccountKey is the part of a Google service account's JSON key
+// needed to sign access tokens.
+type ServiceAccountKey struct {
+	ClientEmail  string
+	PrivateKeyID string
+	PrivateKey   *rsa.PrivateKey
+}
+
+// ParseServiceAccountKey parses a service account's JSON key file.
+func ParseServiceAccountKey(raw string) (*ServiceAccountKey, error) {
+	var file struct {
+		Type         string `json:"type"`
+		ClientEmail  string `json:"client_email"`
+		PrivateKeyID string `json:"private_key_id"`
+		PrivateKey   string `json:"private_key"`
+	}
+	if err := json.Unmarshal([]byte(raw), &file); err != nil || file.Type != "service_account" || file.ClientEmail == "" {
+		return nil, errors.New("endpoint_credentials.service_account_key must be a service account's JSON key")
+	}
+	block, _ := pem.Decode([]byte(file.PrivateKey))
+	if block == nil {
+		return nil, errors.New("endpoint_credentials.service_account_key has no PEM private_key")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if err != nil || !ok {
+		return nil, errors.New("endpoint_credentials.service_account_key has an invalid RSA private_key")
+	}
+	return &ServiceAccountKey{ClientEmail: file.ClientEmail, PrivateKeyID: file.PrivateKeyID, PrivateKey: key}, nil
+}
+
+// AMQPEndpoint is a parsed AMQP endpoint URL.
+type AMQPEndpoint struct {
+	// URL is the broker and virtual host, without the exchange and
+	// routing key.
+	URL      string
+	Exchange string
+	// RoutingKey is empty to route each message by its event type.
+	RoutingKey string
+}
+
+// ParseAMQPEndpoint parses an endpoint URL such as
+// amqps://mq.example.com/orders?exchange=webhooks&routing_key=order.created.
+// The path is the virtual host. Without exchange, messages go to the
+// default exchange, which routes them to the queue named by the routing
+// key. Credentials go in endpoint_credentials rather than the URL, which
+// every viewer can see.
+func ParseAMQPEndpoint(raw string) (AMQPEndpoint, error) {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "amqp" && u.Scheme != "amqps") || u.Host == "" {
+		return AMQPEndpoint{}, errors.New("endpoint_url of an amqp endpoint must be an amqp:// or amqps:// URL")
+	}
+	if u.User != nil {
+		return AMQPEndpoint{}, errors.New("endpoint_url of an amqp endpoint must not hold credentials; set endpoint_credentials instead")
+	}
+	query := u.Query()
+	for name := range query {
+		if name != "exchange" && name != "routing_key" {
+			return AMQPEndpoint{}, fmt.Errorf("endpoint_url of an amqp endpoint takes exchange and routing_key parameters, not %s", name)
+		}
+	}
+	endpoint := AMQPEndpoint{Exchange: query.Get("exchange"), RoutingKey: query.Get("routing_key")}
+	u.RawQuery = ""
+	endpoint.URL = u.String()
+	return endpoint, nil
+}
@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"strings"
+	"testing"
+)
+
+func testServiceAccountKey(t *testing.T) string {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, _ := json.Marshal(map[string]string{
+		"type":         "service_account",
+		"client_email": "webhooks@acme.iam.gserviceaccount.com",
+		"private_key":  string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})),
+	})
+	return string(file)
+}
+
+func TestValidateEndpoint(t *testing.T) {
+	serviceAccountKey := testServiceAccountKey(t)
+	aws := &EndpointCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret"}
+	tests := []struct {
+		endpointType string
+		url          string
+		creds        *EndpointCredentials
+		wantErr      string
+	}{
+		{"", "https://hooks.example.com", nil, ""},
+		{EndpointHTTP, "https://hooks.example.com", &EndpointCredentials{}, ""},
+		{EndpointHTTP, "https://hooks.example.com", aws, "only used by queue endpoint types"},
+		{"kafka", "https://hooks.example.com", nil, "endpoint_type must be one of"},
+		{EndpointSQS, "https://sqs.eu-west-1.amazonaws.com/123456789012/orders", aws, ""},
+		{EndpointSQS, "", aws, ""},
+		{EndpointSQS, "https://sqs.eu-west-1.amazonaws.com/123456789012/orders", nil, "access_key_id and secret_access_key are required"},
+		{EndpointSQS, "https://sqs.eu-west-1.amazonaws.com/orders", aws, "/<account>/<queue>"},
+		{EndpointSQS, "https://sqs.eu-west-1.amazonaws.com/123456789012/orders", &EndpointCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret", Password: "x"}, "only take"},
+		{EndpointPubSub, "https://pubsub.googleapis.com/v1/projects/acme/topics/orders", &EndpointCredentials{ServiceAccountKey: serviceAccountKey}, ""},
+		{EndpointPubSub, "https://pubsub.googleapis.com/v1/projects/acme/subscriptions/orders", &EndpointCredentials{ServiceAccountKey: serviceAccountKey}, "topic URL"},
+		{EndpointPubSub, "https://pubsub.googleapis.com/v1/projects/acme/topics/orders", nil, "service_account_key is required"},
+		{EndpointPubSub, "https://pubsub.googleapis.com/v1/projects/acme/topics/orders", &EndpointCredentials{ServiceAccountKey: `{"type":"authorized_user"}`}, "service account's JSON key"},
+		{EndpointAMQP, "amqps://mq.example.com/orders?exchange=webhooks", &EndpointCredentials{Username: "u", Password: "p"}, ""},
+		{EndpointAMQP, "amqp://localhost", nil, ""},
+		{EndpointAMQP, "amqps://u:p@mq.example.com/", nil, "must not hold credentials"},
+		{EndpointAMQP, "https://mq.example.com/", nil, "amqp:// or amqps://"},
+		{EndpointAMQP, "amqps://mq.example.com/?queue=orders", nil, "not queue"},
+		{EndpointAMQP, "amqps://mq.example.com/", aws, "only take username and password"},
+	}
+	for _, tt := range tests {
+		err := ValidateEndpoint(tt.endpointType, tt.url, tt.creds)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("ValidateEndpoint(%q, %q): %v", tt.endpointType, tt.url, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("ValidateEndpoint(%q, %q) = %v, want %q", tt.endpointType, tt.url, err, tt.wantErr)
+		}
+	}
+}
+
+func TestParseEndpointURLs(t *testing.T) {
+	queue, err := ParseSQSQueueURL("https://sqs.eu-west-1.amazonaws.com/123456789012/orders.fifo")
+	if err != nil || queue.Region != "eu-west-1" || !queue.FIFO {
+		t.Errorf("ParseSQSQueueURL = %+v, %v", queue, err)
+	}
+	if queue, _ := ParseSQSQueueURL("http://localhost:9324/000000000000/orders"); queue.Region != "us-east-1" || queue.FIFO {
+		t.Errorf("local queue = %+v, want us-east-1 and not FIFO", queue)
+	}
+
+	publish, err := ParsePubSubTopicURL("https://pubsub.googleapis.com/v1/projects/acme/topics/orders")
+	if err != nil || publish != "https://pubsub.googleapis.com/v1/projects/acme/topics/orders:publish" {
+		t.Errorf("ParsePubSubTopicURL = %q, %v", publish, err)
+	}
+
+	endpoint, err := ParseAMQPEndpoint("amqps://mq.example.com:5671/orders?exchange=webhooks&routing_key=orders.all")
+	if err != nil || endpoint.URL != "amqps://mq.example.com:5671/orders" || endpoint.Exchange != "webhooks" || endpoint.RoutingKey != "orders.all" {
+		t.Errorf("ParseAMQPEndpoint = %+v, %v", endpoint, err)
+	}
+}
+
+func TestEndpointCredentials_Redacted(t *testing.T) {
+	creds := &EndpointCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret", Username: "u"}
+	redacted := creds.Redacted()
+	if redacted.AccessKeyID != "AKID" || redacted.SecretAccessKey != "[REDACTED]" || redacted.Username != "u" || redacted.Password != "" {
+		t.Errorf("Redacted = %+v", redacted)
+	}
+	if creds.SecretAccessKey != "secret" {
+		t.Error("Redacted changed the credentials")
+	}
+	var none *EndpointCredentials
+	if none.Redacted() != nil || !none.IsZero() {
+		t.Error("nil credentials aren't zero and redacted to nil")
+	}
+}
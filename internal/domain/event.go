@@ -10,5 +10,90 @@ type Event struct {
 	EventType string          `json:"event_type"`
 	Payload   json.RawMessage `json:"payload"`
 	Source    string          `json:"source,omitempty"`
-	CreatedAt time.Time       `json:"created_at"`
+	// Version is the version of the event type's payload the event was
+	// published in, from 1.
+	Version int `json:"version"`
+	// SubscriberIDs restricts fan-out to these subscribers when set. They
+	// still only receive the event if they subscribe to its type.
+	SubscriberIDs []string `json:"subscriber_ids,omitempty"`
+	// PayloadRef is set when the payload was too large to keep with the
+	// event, which then has a null Payload.
+	PayloadRef *PayloadRef `json:"payload_ref,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+// PayloadRef points at an event payload kept in object storage rather than
+// the database and queue. Deliveries stream it from there.
+type PayloadRef struct {
+	Key  string `json:"key"`
+	Size int64  `json:"size"`
+}
+
+// PingEventType is the type of the test events a subscriber sends itself
+// through the portal. They are delivered whether or not the subscriber
+// subscribes to the type.
+const PingEventType = "webhook.ping"
+
+// VerificationEventType is sent as X-Webhook-Event on the handshake an
+// endpoint must pass before a subscriber is switched to it. It is never
+// stored as an event.
+const VerificationEventType = "webhook.verification"
+
+// EventType is an entry in the event type catalog. A type appears once it
+// has been documented, published, or both; published types without an
+// example of their own show the payload of their latest event.
+type EventType struct {
+	Name           string          `json:"name"`
+	Description    string          `json:"description,omitempty"`
+	ExamplePayload json.RawMessage `json:"example_payload,omitempty"`
+	// Documented is set when someone has described the type in the catalog.
+	Documented bool `json:"documented"`
+	// CurrentVersion is the latest version of the type's payload: the
+	// documented one, or else the latest published. Subscribers can pin an
+	// older one.
+	CurrentVersion int        `json:"current_version"`
+	EventCount     int64      `json:"event_count"`
+	LastSeenAt     *time.Time `json:"last_seen_at,omitempty"`
+}
+
+// DescribeEventTypeRequest documents an event type in the catalog.
+type DescribeEventTypeRequest struct {
+	Description    string          `json:"description"`
+	ExamplePayload json.RawMessage `json:"example_payload,omitempty"`
+	// CurrentVersion is the latest version of the type's payload. 0 means 1.
+	CurrentVersion int `json:"current_version,omitempty"`
+}
+
+// Fan-out statuses of an event's delivery to one subscriber.
+const (
+	FanOutQueued = "queued"
+	FanOutFailed = "failed"
+	// FanOutSkipped is given to failed deliveries whose subscriber was
+	// deactivated before the repair job could queue them.
+	FanOutSkipped = "skipped"
+)
+
+// FanOutStatus records whether an event's delivery to a subscriber made it
+// onto the delivery queue. Failed deliveries are queued again by the repair
+// job at NextRepairAt.
+type FanOutStatus struct {
+	EventID      string     `json:"event_id"`
+	SubscriberID string     `json:"subscriber_id"`
+	Status       string     `json:"status"`
+	Error        *string    `json:"error,omitempty"`
+	Attempts     int        `json:"attempts"`
+	NextRepairAt *time.Time `json:"next_repair_at,omitempty"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// EventFanOut summarizes how an event was fanned out. OutboxPending is set
+// while the whole fan-out is still waiting to be retried, before any
+// per-subscriber status is known.
+type EventFanOut struct {
+	EventID       string         `json:"event_id"`
+	OutboxPending bool           `json:"outbox_pending"`
+	Queued        int            `json:"queued"`
+	Failed        int            `json:"failed"`
+	Skipped       int            `json:"skipped"`
+	Subscribers   []FanOutStatus `json:"subscribers"`
 }
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// QuotaUsage is how much of its delivery quotas a subscriber has used in
+// the current windows. Windows without a quota are left out.
+type QuotaUsage struct {
+	Daily   *QuotaWindow `json:"daily,omitempty"`
+	Monthly *QuotaWindow `json:"monthly,omitempty"`
+}
+
+// QuotaWindow is one quota window: a UTC day or calendar month.
+type QuotaWindow struct {
+	Limit int `json:"limit"`
+	Used  int `json:"used"`
+	// ResetsAt is when the window ends and its deliveries that waited are
+	// sent.
+	ResetsAt time.Time `json:"resets_at"`
+}
+
+// Exhausted reports whether the window's deliveries have reached its
+// limit.
+func (w *QuotaWindow) Exhausted() bool {
+	return w != nil && w.Used >= w.Limit
+}
+
+// ValidateQuotas checks a subscriber's delivery quotas, where 0 means
+// unlimited.
+func ValidateQuotas(daily, monthly int) error {
+	if daily < 0 {
+		return errors.New("daily_quota must not be negative")
+	}
+	if monthly < 0 {
+		return errors.New("monthly_quota must not be negative")
+	}
+	return nil
+}
+
+// DayEnd returns the start of the UTC day after t's.
+func DayEnd(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, time.UTC)
+}
+
+// MonthEnd returns the start of the UTC month after t's.
+func MonthEnd(t time.Time) time.Time {
+	y, m, _ := t.UTC().Date()
+	return time.Date(y, m+1, 1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,145 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MaxPayloadTerms caps the terms of one event search.
+const MaxPayloadTerms = 5
+
+// PayloadTerm matches events whose payload holds Value at Path, e.g. the
+// term customer.id:42 has the path [customer id].
+type PayloadTerm struct {
+	Path  []string
+	Value string
+}
+
+// EventSearchResult is an event found by a payload search, with its
+// delivery attempts, newest first.
+type EventSearchResult struct {
+	Event
+	Deliveries []DeliveryAttempt `json:"deliveries"`
+}
+
+// ParsePayloadQuery parses a search like `order_id:abc-123 status:paid`
+// into terms that must all match. Each term is a dot-separated path into
+// the payload, a colon and the value; values with spaces can be quoted, as
+// in `customer.name:"Jane Doe"`.
+func ParsePayloadQuery(query string) ([]PayloadTerm, error) {
+	fields, err := splitQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, errors.New("query is required, e.g. order_id:abc-123")
+	}
+	if len(fields) > MaxPayloadTerms {
+		return nil, fmt.Errorf("query has %d terms, at most %d are allowed", len(fields), MaxPayloadTerms)
+	}
+
+	terms := make([]PayloadTerm, len(fields))
+	for i, f := range fields {
+		path, value, ok := strings.Cut(f, ":")
+		if !ok || path == "" || value == "" {
+			return nil, fmt.Errorf("term %q must be field:value", f)
+		}
+		segments := strings.Split(path, ".")
+		for _, s := range segments {
+			if s == "" {
+				return nil, fmt.Errorf("term %q has an empty field name", f)
+			}
+		}
+		terms[i] = PayloadTerm{Path: segments, Value: strings.Trim(value, `"`)}
+	}
+	return terms, nil
+}
+
+// splitQuery splits a query on spaces outside double quotes.
+func splitQuery(query string) ([]string, error) {
+	var fields []string
+	var current strings.Builder
+	quoted := false
+	for _, r := range query {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			current.WriteRune(r)
+		case r == ' ' && !quoted:
+			if current.Len() > 0 {
+				fields = append(fields, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if quoted {
+		return nil, errors.New("query has an unterminated quote")
+	}
+	if current.Len() > 0 {
+		fields = append(fields, current.String())
+	}
+	return fields, nil
+}
+
+// Candidates returns the JSON values the term matches: its value as a
+// string, and also as a number, boolean or null when it reads as one, so
+// that order_id:42 finds both "42" and 42.
+func (t PayloadTerm) Candidates() []json.RawMessage {
+	str, _ := json.Marshal(t.Value)
+	candidates := []json.RawMessage{str}
+
+	var literal any
+	if json.Unmarshal([]byte(t.Value), &literal) == nil {
+		switch literal.(type) {
+		case float64, bool, nil:
+			candidates = append(candidates, json.RawMessage(t.Value))
+		}
+	}
+	return candidates
+}
+
+// Document returns the JSON object that has candidate at the term's path,
+// for containment queries.
+func (t PayloadTerm) Document(candidate json.RawMessage) json.RawMessage {
+	var doc any = candidate
+	for i := len(t.Path) - 1; i >= 0; i-- {
+		doc = map[string]any{t.Path[i]: doc}
+	}
+	data, _ := json.Marshal(doc)
+	return data
+}
+
+// Matches reports whether payload holds one of the term's candidates at
+// its path.
+func (t PayloadTerm) Matches(payload json.RawMessage) bool {
+	dec := json.NewDecoder(strings.NewReader(string(payload)))
+	dec.UseNumber()
+	var value any
+	if dec.Decode(&value) != nil {
+		return false
+	}
+	for _, key := range t.Path {
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return false
+		}
+		if value, ok = obj[key]; !ok {
+			return false
+		}
+	}
+
+	found, err := json.Marshal(value)
+	if err != nil {
+		return false
+	}
+	for _, c := range t.Candidates() {
+		if string(found) == string(c) {
+			return true
+		}
+	}
+	return false
+}
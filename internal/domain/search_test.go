@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePayloadQuery(t *testing.T) {
+	terms, err := ParsePayloadQuery(`order_id:abc-123  customer.name:"Jane Doe"`)
+	if err != nil {
+		t.Fatalf("ParsePayloadQuery: %v", err)
+	}
+	want := []PayloadTerm{
+		{Path: []string{"order_id"}, Value: "abc-123"},
+		{Path: []string{"customer", "name"}, Value: "Jane Doe"},
+	}
+	if !reflect.DeepEqual(terms, want) {
+		t.Errorf("terms = %+v, want %+v", terms, want)
+	}
+
+	invalid := []string{"", "   ", "abc-123", "order_id:", ":abc", "customer..id:1", `name:"Jane`, "a:1 b:2 c:3 d:4 e:5 f:6"}
+	for _, q := range invalid {
+		if _, err := ParsePayloadQuery(q); err == nil {
+			t.Errorf("ParsePayloadQuery(%q) = nil error, want an error", q)
+		}
+	}
+}
+
+func TestPayloadTermMatches(t *testing.T) {
+	payload := []byte(`{"order_id":"abc-123","total":42,"paid":true,"customer":{"id":"7","name":"Jane Doe"}}`)
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"order_id:abc-123", true},
+		{"order_id:abc-124", false},
+		{"total:42", true},
+		{"total:42.5", false},
+		{"paid:true", true},
+		{"customer.id:7", true},
+		{`customer.name:"Jane Doe"`, true},
+		{"customer:7", false},
+		{"order_id.x:abc-123", false},
+		{"missing:1", false},
+	}
+	for _, tt := range tests {
+		terms, err := ParsePayloadQuery(tt.query)
+		if err != nil {
+			t.Fatalf("ParsePayloadQuery(%q): %v", tt.query, err)
+		}
+		if got := terms[0].Matches(payload); got != tt.want {
+			t.Errorf("%q matches = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestPayloadTermDocument(t *testing.T) {
+	term := PayloadTerm{Path: []string{"customer", "id"}, Value: "7"}
+	candidates := term.Candidates()
+	if len(candidates) != 2 {
+		t.Fatalf("candidates = %s, want the string and the number", candidates)
+	}
+	if got := string(term.Document(candidates[0])); got != `{"customer":{"id":"7"}}` {
+		t.Errorf("Document = %s", got)
+	}
+	if got := string(term.Document(candidates[1])); got != `{"customer":{"id":7}}` {
+		t.Errorf("Document = %s", got)
+	}
+}
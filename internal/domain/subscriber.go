@@ -1,31 +1,412 @@
 package domain
 
 import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
 	"time"
 )
 
 type Subscriber struct {
-	ID                 string    `json:"id"`
-	Name               string    `json:"name"`
-	EndpointURL        string    `json:"endpoint_url"`
-	SecretKey          string    `json:"secret_key,omitempty"`
-	IsActive           bool      `json:"is_active"`
-	RateLimitPerSecond int       `json:"rate_limit_per_second"`
-	CreatedAt          time.Time `json:"created_at"`
-	UpdatedAt          time.Time `json:"updated_at"`
+	ID                 string `json:"id"`
+	Name               string `json:"name"`
+	EndpointURL        string `json:"endpoint_url"`
+	SecretKey          string `json:"secret_key,omitempty"`
+	IsActive           bool   `json:"is_active"`
+	RateLimitPerSecond int    `json:"rate_limit_per_second"`
+	CompressPayloads   bool   `json:"compress_payloads"`
+	// EndpointType is what EndpointURL points at, one of EndpointTypes:
+	// an HTTP receiver, or a queue deliveries are pushed into with
+	// EndpointCredentials.
+	EndpointType        string               `json:"endpoint_type"`
+	EndpointCredentials *EndpointCredentials `json:"endpoint_credentials,omitempty"`
+	// DiscardResponseBodies stops response bodies from this subscriber's
+	// endpoint being stored with its delivery attempts.
+	DiscardResponseBodies bool `json:"discard_response_bodies"`
+	// BatchMaxEvents opts the subscriber into batched delivery: up to this
+	// many events are sent in one request as a JSON array. 0 or 1 sends
+	// each event on its own.
+	BatchMaxEvents int `json:"batch_max_events"`
+	// BatchWindowSeconds is how long the first event of a batch waits for
+	// more to join it.
+	BatchWindowSeconds int `json:"batch_window_seconds"`
+	// ProxyURL routes deliveries to this subscriber through an HTTP, HTTPS
+	// or SOCKS5 proxy instead of the server's default route.
+	ProxyURL string `json:"proxy_url,omitempty"`
+	// DebugLogging logs every delivery to this subscriber, whatever the
+	// log sampling rates, with its request and response details.
+	DebugLogging bool `json:"debug_logging"`
+	// SignatureFormat is how deliveries to this subscriber are signed, one
+	// of SignatureFormats.
+	SignatureFormat string `json:"signature_format"`
+	// EventVersions pins event types to the payload version the subscriber
+	// receives. Events published in a newer version are down-converted to
+	// it; unpinned types are delivered as published.
+	EventVersions map[string]int `json:"event_versions"`
+	// Sandbox has the subscriber's deliveries prepared and recorded as
+	// simulated attempts, but never sent, so consumers can see what they
+	// would receive before exposing an endpoint. Sandbox subscribers need
+	// no endpoint_url.
+	Sandbox bool `json:"sandbox"`
+	// DailyQuota and MonthlyQuota cap the deliveries made to the subscriber
+	// in a UTC day and calendar month. Deliveries over a quota wait for its
+	// window to reset. 0 leaves the window unlimited.
+	DailyQuota   int `json:"daily_quota"`
+	MonthlyQuota int `json:"monthly_quota"`
+	// AutoReplayDeadLetters replays the subscriber's unresolved dead letters
+	// once its circuit breaker has closed after an outage and stayed closed
+	// for the configured healthy period.
+	AutoReplayDeadLetters bool `json:"auto_replay_dead_letters"`
+	// RetryJitter is the jitter strategy of the subscriber's retries, one of
+	// JitterStrategies. Empty uses the server's RETRY_JITTER_STRATEGY.
+	RetryJitter string `json:"retry_jitter,omitempty"`
+	// MaxRetryDurationSeconds dead-letters a delivery once this long has
+	// passed since its first failed attempt, even with attempts left. 0
+	// leaves only the attempt count.
+	MaxRetryDurationSeconds int `json:"max_retry_duration_seconds"`
+	// RetryForDuration keeps retrying past the attempt count for the whole
+	// MaxRetryDurationSeconds, waiting no longer than the server's
+	// RETRY_DURATION_MAX_DELAY between attempts.
+	RetryForDuration bool `json:"retry_for_duration"`
+	// SuccessBodyContains and SuccessJSONPath make up the subscriber's
+	// success condition, which 2xx responses must also meet to count as
+	// delivered.
+	SuccessBodyContains string `json:"success_body_contains,omitempty"`
+	SuccessJSONPath     string `json:"success_json_path,omitempty"`
+	// RedirectPolicy is which redirects deliveries to the subscriber
+	// follow, one of RedirectPolicies. Empty uses the server's
+	// DELIVERY_REDIRECT_POLICY.
+	RedirectPolicy string `json:"redirect_policy,omitempty"`
+	// IsSystem makes the subscriber a system subscriber, the only kind that
+	// receives the system event types.
+	IsSystem bool `json:"is_system"`
+	// Version goes up with every change to the subscriber. Updates can
+	// pass the version they were based on to fail instead of overwriting
+	// a change made in between.
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// DeletedAt is set while the subscriber is deleted. Deleted subscribers
+	// are left out of listings and fan-out until they are restored.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// Limits on batched delivery.
+const (
+	MaxBatchEvents        = 1000
+	MaxBatchWindowSeconds = 300
+)
+
+// MaxRetryDurationSeconds caps a subscriber's max_retry_duration_seconds
+// at 30 days.
+const MaxRetryDurationSeconds = 30 * 24 * 60 * 60
+
+// Deleted reports whether the subscriber has been deleted.
+func (s *Subscriber) Deleted() bool {
+	return s.DeletedAt != nil
+}
+
+// Batched reports whether deliveries to the subscriber are sent in batches.
+func (s *Subscriber) Batched() bool {
+	return s.BatchMaxEvents > 1
+}
+
+// SuccessCondition returns what the subscriber's 2xx responses must hold.
+func (s *Subscriber) SuccessCondition() SuccessCondition {
+	return SuccessCondition{BodyContains: s.SuccessBodyContains, JSONPath: s.SuccessJSONPath}
+}
+
+// ValidateBatching checks a subscriber's batching settings. The window is
+// only required once batching is enabled.
+func ValidateBatching(maxEvents, windowSeconds int) error {
+	if maxEvents < 0 || maxEvents > MaxBatchEvents {
+		return fmt.Errorf("batch_max_events must be between 0 and %d", MaxBatchEvents)
+	}
+	if windowSeconds < 0 || windowSeconds > MaxBatchWindowSeconds {
+		return fmt.Errorf("batch_window_seconds must be between 0 and %d", MaxBatchWindowSeconds)
+	}
+	if maxEvents > 1 && windowSeconds == 0 {
+		return fmt.Errorf("batch_window_seconds is required when batch_max_events is set")
+	}
+	return nil
+}
+
+// ValidateProxyURL checks a delivery proxy URL, for a subscriber or the
+// server as a whole. The empty string means no proxy.
+func ValidateProxyURL(raw string) error {
+	if raw == "" {
+		return nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("proxy URL is not a valid URL")
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return fmt.Errorf("proxy URL scheme must be http, https, socks5 or socks5h")
+	}
+	if u.Host == "" {
+		return fmt.Errorf("proxy URL must include a host")
+	}
+	return nil
+}
+
+// Signature formats, named after the services whose verification
+// middleware they are compatible with.
+const (
+	SignatureStandard = "standard" // X-Webhook-Signature: <hex>
+	SignatureGitHub   = "github"   // X-Hub-Signature-256: sha256=<hex>
+	SignatureStripe   = "stripe"   // Stripe-Signature: t=<unix>,v1=<hex>
+	SignatureSvix     = "svix"     // svix-id, svix-timestamp and svix-signature: v1,<base64>
+)
+
+// SignatureFormats lists the valid signature formats.
+var SignatureFormats = []string{SignatureStandard, SignatureGitHub, SignatureStripe, SignatureSvix}
+
+// SignatureFormatOrDefault returns format, or SignatureStandard when it is
+// empty.
+func SignatureFormatOrDefault(format string) string {
+	if format == "" {
+		return SignatureStandard
+	}
+	return format
+}
+
+// ValidateSignatureFormat checks a subscriber's signature format. The
+// empty string means SignatureStandard.
+func ValidateSignatureFormat(format string) error {
+	if format == "" || slices.Contains(SignatureFormats, format) {
+		return nil
+	}
+	return fmt.Errorf("signature_format must be one of %s", strings.Join(SignatureFormats, ", "))
+}
+
+// Retry jitter strategies. Each spreads the retries of deliveries that
+// failed together, so they don't all reach an endpoint the moment it
+// recovers. The backoff they jitter doubles with each attempt, up to the
+// maximum retry delay.
+const (
+	JitterAdditive     = "additive"     // the backoff, plus up to RETRY_JITTER
+	JitterFull         = "full"         // anywhere from nothing up to the backoff
+	JitterEqual        = "equal"        // half the backoff, plus up to the other half
+	JitterDecorrelated = "decorrelated" // from the base delay up to three times the previous delay
+)
+
+// JitterStrategies lists the valid retry jitter strategies.
+var JitterStrategies = []string{JitterAdditive, JitterFull, JitterEqual, JitterDecorrelated}
+
+// ValidateRetryJitter checks a subscriber's retry jitter strategy. The empty
+// string means the server's default.
+func ValidateRetryJitter(strategy string) error {
+	if strategy == "" || slices.Contains(JitterStrategies, strategy) {
+		return nil
+	}
+	return fmt.Errorf("retry_jitter must be one of %s", strings.Join(JitterStrategies, ", "))
+}
+
+// ValidateRetryDuration checks a subscriber's retry duration settings.
+// Retrying for the duration needs one to be set.
+func ValidateRetryDuration(seconds int, forDuration bool) error {
+	if seconds < 0 || seconds > MaxRetryDurationSeconds {
+		return fmt.Errorf("max_retry_duration_seconds must be between 0 and %d", MaxRetryDurationSeconds)
+	}
+	if forDuration && seconds == 0 {
+		return fmt.Errorf("max_retry_duration_seconds is required when retry_for_duration is set")
+	}
+	return nil
+}
+
+// Redirect policies. A followed redirect sends the same POST, payload and
+// all, to its target.
+const (
+	RedirectNever        = "never"         // redirects fail the attempt
+	RedirectSameHost     = "same_host"     // follows redirects to the same host, never from https to http
+	RedirectFollowResign = "follow_resign" // follows any redirect, signing the request to each target anew
+)
+
+// RedirectPolicies lists the valid redirect policies.
+var RedirectPolicies = []string{RedirectNever, RedirectSameHost, RedirectFollowResign}
+
+// ValidateRedirectPolicy checks a subscriber's redirect policy. The empty
+// string means the server's default.
+func ValidateRedirectPolicy(policy string) error {
+	if policy == "" || slices.Contains(RedirectPolicies, policy) {
+		return nil
+	}
+	return fmt.Errorf("redirect_policy must be one of %s", strings.Join(RedirectPolicies, ", "))
+}
+
+// ValidateEventVersions checks a subscriber's event version pins. Each pins
+// a single event type, not a pattern, to a version from 1.
+func ValidateEventVersions(versions map[string]int) error {
+	for eventType, version := range versions {
+		if err := ValidateEventPattern(eventType); err != nil {
+			return err
+		}
+		if IsEventPattern(eventType) {
+			return fmt.Errorf("event_versions: %q is a pattern; versions are pinned per event type", eventType)
+		}
+		if version < 1 {
+			return fmt.Errorf("event_versions: version of %s must be at least 1", eventType)
+		}
+	}
+	return nil
 }
 
 type CreateSubscriberRequest struct {
-	Name        string   `json:"name"`
-	EndpointURL string   `json:"endpoint_url"`
-	EventTypes  []string `json:"event_types"`
+	Name                    string         `json:"name"`
+	EndpointURL             string         `json:"endpoint_url"`
+	EventTypes              []string       `json:"event_types"`
+	CompressPayloads        bool           `json:"compress_payloads,omitempty"`
+	DiscardResponseBodies   bool           `json:"discard_response_bodies,omitempty"`
+	BatchMaxEvents          int            `json:"batch_max_events,omitempty"`
+	BatchWindowSeconds      int            `json:"batch_window_seconds,omitempty"`
+	ProxyURL                string         `json:"proxy_url,omitempty"`
+	SignatureFormat         string         `json:"signature_format,omitempty"`
+	EventVersions           map[string]int `json:"event_versions,omitempty"`
+	Sandbox                 bool           `json:"sandbox,omitempty"`
+	DailyQuota              int            `json:"daily_quota,omitempty"`
+	MonthlyQuota            int            `json:"monthly_quota,omitempty"`
+	AutoReplayDeadLetters   bool           `json:"auto_replay_dead_letters,omitempty"`
+	RetryJitter             string         `json:"retry_jitter,omitempty"`
+	MaxRetryDurationSeconds int            `json:"max_retry_duration_seconds,omitempty"`
+	RetryForDuration        bool           `json:"retry_for_duration,omitempty"`
+	SuccessBodyContains     string         `json:"success_body_contains,omitempty"`
+	SuccessJSONPath         string         `json:"success_json_path,omitempty"`
+	RedirectPolicy          string         `json:"redirect_policy,omitempty"`
+	IsSystem                bool           `json:"is_system,omitempty"`
+	// EndpointType defaults to EndpointHTTP.
+	EndpointType        string               `json:"endpoint_type,omitempty"`
+	EndpointCredentials *EndpointCredentials `json:"endpoint_credentials,omitempty"`
+	// SecretKey is used instead of a generated secret when set, so that
+	// imported subscribers keep signing with the secret their receivers
+	// already verify. The create endpoint never sets it.
+	SecretKey string `json:"-"`
 }
 
 type UpdateSubscriberRequest struct {
-	Name               *string `json:"name,omitempty"`
-	EndpointURL        *string `json:"endpoint_url,omitempty"`
-	IsActive           *bool   `json:"is_active,omitempty"`
-	RateLimitPerSecond *int    `json:"rate_limit_per_second,omitempty"`
+	Name                  *string `json:"name,omitempty"`
+	EndpointURL           *string `json:"endpoint_url,omitempty"`
+	IsActive              *bool   `json:"is_active,omitempty"`
+	RateLimitPerSecond    *int    `json:"rate_limit_per_second,omitempty"`
+	CompressPayloads      *bool   `json:"compress_payloads,omitempty"`
+	DiscardResponseBodies *bool   `json:"discard_response_bodies,omitempty"`
+	BatchMaxEvents        *int    `json:"batch_max_events,omitempty"`
+	BatchWindowSeconds    *int    `json:"batch_window_seconds,omitempty"`
+	ProxyURL              *string `json:"proxy_url,omitempty"` // "" removes the proxy
+	DebugLogging          *bool   `json:"debug_logging,omitempty"`
+	SignatureFormat       *string `json:"signature_format,omitempty"`
+	// EventVersions replaces the subscriber's version pins; an empty map
+	// removes them all.
+	EventVersions map[string]int `json:"event_versions,omitempty"`
+	Sandbox       *bool          `json:"sandbox,omitempty"`
+	DailyQuota    *int           `json:"daily_quota,omitempty"`
+	MonthlyQuota  *int           `json:"monthly_quota,omitempty"`
+	// AutoReplayDeadLetters turns the replay of dead letters after an
+	// outage on or off.
+	AutoReplayDeadLetters *bool `json:"auto_replay_dead_letters,omitempty"`
+	// RetryJitter changes the jitter strategy of retries; "" goes back to
+	// the server's default.
+	RetryJitter *string `json:"retry_jitter,omitempty"`
+	// MaxRetryDurationSeconds changes how long a delivery may be retried;
+	// 0 removes the limit.
+	MaxRetryDurationSeconds *int  `json:"max_retry_duration_seconds,omitempty"`
+	RetryForDuration        *bool `json:"retry_for_duration,omitempty"`
+	// SuccessBodyContains and SuccessJSONPath change the success
+	// condition; "" removes either part.
+	SuccessBodyContains *string `json:"success_body_contains,omitempty"`
+	SuccessJSONPath     *string `json:"success_json_path,omitempty"`
+	// RedirectPolicy changes which redirects are followed; "" goes back
+	// to the server's default.
+	RedirectPolicy *string `json:"redirect_policy,omitempty"`
+	// EndpointType and EndpointCredentials change where deliveries go,
+	// usually along with EndpointURL. Credentials are replaced whole.
+	EndpointType        *string              `json:"endpoint_type,omitempty"`
+	EndpointCredentials *EndpointCredentials `json:"endpoint_credentials,omitempty"`
+	// Version, if set, makes the update conditional: it fails with
+	// store.ErrVersionConflict unless the subscriber is still at this
+	// version. It is not a change itself.
+	Version *int `json:"version,omitempty"`
+}
+
+// Previous returns sub's current values for the fields that r changes.
+func (r UpdateSubscriberRequest) Previous(sub *Subscriber) UpdateSubscriberRequest {
+	var prev UpdateSubscriberRequest
+	if r.Name != nil {
+		prev.Name = &sub.Name
+	}
+	if r.EndpointURL != nil {
+		prev.EndpointURL = &sub.EndpointURL
+	}
+	if r.IsActive != nil {
+		prev.IsActive = &sub.IsActive
+	}
+	if r.RateLimitPerSecond != nil {
+		prev.RateLimitPerSecond = &sub.RateLimitPerSecond
+	}
+	if r.CompressPayloads != nil {
+		prev.CompressPayloads = &sub.CompressPayloads
+	}
+	if r.DiscardResponseBodies != nil {
+		prev.DiscardResponseBodies = &sub.DiscardResponseBodies
+	}
+	if r.BatchMaxEvents != nil {
+		prev.BatchMaxEvents = &sub.BatchMaxEvents
+	}
+	if r.BatchWindowSeconds != nil {
+		prev.BatchWindowSeconds = &sub.BatchWindowSeconds
+	}
+	if r.ProxyURL != nil {
+		prev.ProxyURL = &sub.ProxyURL
+	}
+	if r.DebugLogging != nil {
+		prev.DebugLogging = &sub.DebugLogging
+	}
+	if r.SignatureFormat != nil {
+		prev.SignatureFormat = &sub.SignatureFormat
+	}
+	if r.EventVersions != nil {
+		prev.EventVersions = sub.EventVersions
+	}
+	if r.Sandbox != nil {
+		prev.Sandbox = &sub.Sandbox
+	}
+	if r.DailyQuota != nil {
+		prev.DailyQuota = &sub.DailyQuota
+	}
+	if r.MonthlyQuota != nil {
+		prev.MonthlyQuota = &sub.MonthlyQuota
+	}
+	if r.AutoReplayDeadLetters != nil {
+		prev.AutoReplayDeadLetters = &sub.AutoReplayDeadLetters
+	}
+	if r.RetryJitter != nil {
+		prev.RetryJitter = &sub.RetryJitter
+	}
+	if r.MaxRetryDurationSeconds != nil {
+		prev.MaxRetryDurationSeconds = &sub.MaxRetryDurationSeconds
+	}
+	if r.RetryForDuration != nil {
+		prev.RetryForDuration = &sub.RetryForDuration
+	}
+	if r.SuccessBodyContains != nil {
+		prev.SuccessBodyContains = &sub.SuccessBodyContains
+	}
+	if r.SuccessJSONPath != nil {
+		prev.SuccessJSONPath = &sub.SuccessJSONPath
+	}
+	if r.RedirectPolicy != nil {
+		prev.RedirectPolicy = &sub.RedirectPolicy
+	}
+	if r.EndpointType != nil {
+		prev.EndpointType = &sub.EndpointType
+	}
+	if r.EndpointCredentials != nil {
+		prev.EndpointCredentials = sub.EndpointCredentials
+	}
+	return prev
 }
 
 type CreateSubscriberResponse struct {
@@ -1,6 +1,10 @@
 package domain
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 type Subscription struct {
 	ID           string    `json:"id"`
@@ -9,3 +13,90 @@ type Subscription struct {
 	IsActive     bool      `json:"is_active"`
 	CreatedAt    time.Time `json:"created_at"`
 }
+
+// MaxEventPatternLength is the longest event type pattern a subscription can
+// store.
+const MaxEventPatternLength = 100
+
+// Event type patterns are dot-separated segments. A "*" segment matches
+// exactly one segment of the event type and a "**" segment matches one or
+// more, so "order.*" matches order.created but not order.item.added, while
+// "order.**" matches both. A pattern of just "*" matches every event type.
+
+// ValidateEventPattern reports why pattern can't be used as a subscription's
+// event type, or nil if it can.
+func ValidateEventPattern(pattern string) error {
+	if pattern == "" {
+		return fmt.Errorf("event type must not be empty")
+	}
+	if len(pattern) > MaxEventPatternLength {
+		return fmt.Errorf("event type %q is longer than %d characters", pattern, MaxEventPatternLength)
+	}
+	for _, seg := range strings.Split(pattern, ".") {
+		if seg == "" {
+			return fmt.Errorf("event type %q has an empty segment", pattern)
+		}
+		if seg != "*" && seg != "**" && strings.Contains(seg, "*") {
+			return fmt.Errorf("event type %q: wildcards must be a whole segment (* or **)", pattern)
+		}
+	}
+	return nil
+}
+
+// ValidateEventPatterns validates every pattern and rejects duplicates.
+func ValidateEventPatterns(patterns []string) error {
+	seen := make(map[string]bool, len(patterns))
+	for _, p := range patterns {
+		if err := ValidateEventPattern(p); err != nil {
+			return err
+		}
+		if seen[p] {
+			return fmt.Errorf("event type %q is listed more than once", p)
+		}
+		seen[p] = true
+	}
+	return nil
+}
+
+// IsEventPattern reports whether pattern contains a wildcard, as opposed to
+// naming a single event type.
+func IsEventPattern(pattern string) bool {
+	return strings.Contains(pattern, "*")
+}
+
+// MatchEventType reports whether a subscription pattern matches an event
+// type.
+func MatchEventType(pattern, eventType string) bool {
+	if pattern == eventType || pattern == "*" {
+		return true
+	}
+	if !IsEventPattern(pattern) {
+		return false
+	}
+	return matchSegments(strings.Split(pattern, "."), strings.Split(eventType, "."))
+}
+
+func matchSegments(pattern, segs []string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case "**":
+			// Consume one segment, then try every possible end of the run
+			for i := 1; i <= len(segs); i++ {
+				if matchSegments(pattern[1:], segs[i:]) {
+					return true
+				}
+			}
+			return false
+		case "*":
+			if len(segs) == 0 || segs[0] == "" {
+				return false
+			}
+		default:
+			if len(segs) == 0 || segs[0] != pattern[0] {
+				return false
+			}
+		}
+		pattern, segs = pattern[1:], segs[1:]
+	}
+	return len(segs) == 0
+}
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMatchEventType(t *testing.T) {
+	tests := []struct {
+		pattern   string
+		eventType string
+		want      bool
+	}{
+		{"order.created", "order.created", true},
+		{"order.created", "order.updated", false},
+		{"*", "order.item.added", true},
+		{"order.*", "order.created", true},
+		{"order.*", "order.item.added", false},
+		{"order.*", "order", false},
+		{"*.created", "order.created", true},
+		{"*.created", "order.item.created", false},
+		{"*.created", "created", false},
+		{"order.**", "order.created", true},
+		{"order.**", "order.item.added", true},
+		{"order.**", "order", false},
+		{"order.**", "orders.created", false},
+		{"**.created", "order.item.created", true},
+		{"order.**.added", "order.item.added", true},
+		{"order.**.added", "order.item.line.added", true},
+		{"order.**.added", "order.added", false},
+		{"*.item.*", "order.item.added", true},
+		{"**", "user", true},
+	}
+
+	for _, tt := range tests {
+		if got := MatchEventType(tt.pattern, tt.eventType); got != tt.want {
+			t.Errorf("MatchEventType(%q, %q) = %v, want %v", tt.pattern, tt.eventType, got, tt.want)
+		}
+	}
+}
+
+func TestValidateEventPattern(t *testing.T) {
+	valid := []string{"order.created", "*", "order.*", "*.created", "order.**", "order.**.added"}
+	for _, p := range valid {
+		if err := ValidateEventPattern(p); err != nil {
+			t.Errorf("ValidateEventPattern(%q) = %v, want nil", p, err)
+		}
+	}
+
+	invalid := []string{"", "order.", ".created", "order..created", "order.cre*", "order.***", strings.Repeat("a", 101)}
+	for _, p := range invalid {
+		if err := ValidateEventPattern(p); err == nil {
+			t.Errorf("ValidateEventPattern(%q) = nil, want an error", p)
+		}
+	}
+
+	if err := ValidateEventPatterns([]string{"order.*", "order.*"}); err == nil {
+		t.Error("duplicate patterns were accepted")
+	}
+}
@@ -0,0 +1,196 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// MaxSuccessBodyBytes is how much of a response body is checked against a
+// subscriber's success condition. A body cut off here holds no complete
+// JSON document, so it fails a JSONPath condition.
+const MaxSuccessBodyBytes = 64 << 10
+
+// SuccessCondition is what a 2xx response body must hold for a delivery to
+// count as successful, for endpoints that answer 200 with an error in the
+// body. A response failing it is retried like any other failure.
+type SuccessCondition struct {
+	// BodyContains must appear in the body as is.
+	BodyContains string
+	// JSONPath is a JSONPath expression the body must satisfy, parsed by
+	// ParseJSONPathCondition.
+	JSONPath string
+}
+
+// IsZero reports whether the condition is empty, so every 2xx response
+// succeeds.
+func (c SuccessCondition) IsZero() bool {
+	return c.BodyContains == "" && c.JSONPath == ""
+}
+
+// Check returns why body fails the condition, or "" if it holds. An
+// invalid JSONPath expression, which validation keeps out, fails every
+// body.
+func (c SuccessCondition) Check(body []byte) string {
+	if c.BodyContains != "" && !bytes.Contains(body, []byte(c.BodyContains)) {
+		return fmt.Sprintf("response body does not contain %q", c.BodyContains)
+	}
+	if c.JSONPath != "" {
+		cond, err := ParseJSONPathCondition(c.JSONPath)
+		if err != nil || !cond.Match(body) {
+			return fmt.Sprintf("response body does not match %s", c.JSONPath)
+		}
+	}
+	return ""
+}
+
+// JSONPathCondition is a parsed success_json_path: a path into the JSON
+// response body, optionally compared with a JSON value. Without a
+// comparison, the path must lead to a value other than null or false.
+type JSONPathCondition struct {
+	path  []any // object keys (string) and array indexes (int)
+	op    string
+	value any
+}
+
+// ParseJSONPathCondition parses a JSONPath expression such as
+// `$.status == "ok"`, `$.errors[0] == null` or `$.data.accepted`. Paths
+// start at $ and step through object keys, written .name or ["name"], and
+// array indexes, written [n]. The comparison, == or !=, is with a JSON
+// value.
+func ParseJSONPathCondition(expr string) (JSONPathCondition, error) {
+	var cond JSONPathCondition
+	rest := strings.TrimSpace(expr)
+	if !strings.HasPrefix(rest, "$") {
+		return cond, fmt.Errorf("JSONPath %q must start at $", expr)
+	}
+	rest = rest[1:]
+
+	for rest != "" && (rest[0] == '.' || rest[0] == '[') {
+		if rest[0] == '.' {
+			end := 1
+			for end < len(rest) && isPathNameByte(rest[end]) {
+				end++
+			}
+			if end == 1 {
+				return cond, fmt.Errorf("JSONPath %q has an empty field name", expr)
+			}
+			cond.path = append(cond.path, rest[1:end])
+			rest = rest[end:]
+			continue
+		}
+
+		end := strings.IndexByte(rest, ']')
+		if end < 0 {
+			return cond, fmt.Errorf("JSONPath %q has an unclosed [", expr)
+		}
+		inner := strings.TrimSpace(rest[1:end])
+		if strings.HasPrefix(inner, `"`) {
+			// A quoted key may itself hold a ], so it is read as a JSON
+			// string rather than up to the first ]
+			dec := json.NewDecoder(strings.NewReader(rest[1:]))
+			var key string
+			if err := dec.Decode(&key); err != nil {
+				return cond, fmt.Errorf("JSONPath %q has an invalid quoted field name", expr)
+			}
+			after := strings.TrimLeft(rest[1+int(dec.InputOffset()):], " ")
+			if !strings.HasPrefix(after, "]") {
+				return cond, fmt.Errorf("JSONPath %q has an unclosed [", expr)
+			}
+			cond.path = append(cond.path, key)
+			rest = after[1:]
+			continue
+		}
+		index, err := strconv.Atoi(inner)
+		if err != nil || index < 0 {
+			return cond, fmt.Errorf("JSONPath %q: [%s] must be an array index or a quoted field name", expr, inner)
+		}
+		cond.path = append(cond.path, index)
+		rest = rest[end+1:]
+	}
+
+	rest = strings.TrimSpace(rest)
+	if rest == "" {
+		return cond, nil
+	}
+	switch {
+	case strings.HasPrefix(rest, "=="):
+		cond.op = "=="
+	case strings.HasPrefix(rest, "!="):
+		cond.op = "!="
+	default:
+		return cond, fmt.Errorf("JSONPath %q: expected == or != after the path, got %q", expr, rest)
+	}
+	literal := strings.TrimSpace(rest[2:])
+	if literal == "" {
+		return cond, fmt.Errorf("JSONPath %q has nothing to compare with", expr)
+	}
+	if err := json.Unmarshal([]byte(literal), &cond.value); err != nil {
+		return cond, fmt.Errorf("JSONPath %q: %s is not a JSON value; quote strings with \"", expr, literal)
+	}
+	return cond, nil
+}
+
+func isPathNameByte(c byte) bool {
+	return c == '_' || c == '-' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// Match reports whether body, a JSON document, satisfies the condition. A
+// body that isn't JSON never does. With !=, a path leading nowhere differs
+// from every value.
+func (c JSONPathCondition) Match(body []byte) bool {
+	var doc any
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return false
+	}
+	value, found := c.lookup(doc)
+	switch c.op {
+	case "==":
+		return found && reflect.DeepEqual(value, c.value)
+	case "!=":
+		return !found || !reflect.DeepEqual(value, c.value)
+	default:
+		return found && value != nil && value != false
+	}
+}
+
+// lookup follows the path through doc.
+func (c JSONPathCondition) lookup(doc any) (any, bool) {
+	for _, step := range c.path {
+		switch step := step.(type) {
+		case string:
+			obj, ok := doc.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+			if doc, ok = obj[step]; !ok {
+				return nil, false
+			}
+		case int:
+			arr, ok := doc.([]any)
+			if !ok || step >= len(arr) {
+				return nil, false
+			}
+			doc = arr[step]
+		}
+	}
+	return doc, true
+}
+
+// ValidateSuccessCondition checks a subscriber's success condition.
+func ValidateSuccessCondition(c SuccessCondition) error {
+	if len(c.BodyContains) > MaxSuccessBodyBytes {
+		return errors.New("success_body_contains is longer than the part of the body checked")
+	}
+	if c.JSONPath == "" {
+		return nil
+	}
+	if _, err := ParseJSONPathCondition(c.JSONPath); err != nil {
+		return fmt.Errorf("success_json_path: %w", err)
+	}
+	return nil
+}
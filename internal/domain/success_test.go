@@ -0,0 +1,67 @@
+package domain
+
+import "testing"
+
+func TestJSONPathCondition(t *testing.T) {
+	body := `{"status":"ok","data":{"accepted":true,"ids":[7,8]},"errors":[],"odd key]":null}`
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`$.status == "ok"`, true},
+		{`$.status=="error"`, false},
+		{`$.status != "error"`, true},
+		{`$.missing != "error"`, true},
+		{`$.missing == null`, false},
+		{`$.data.accepted`, true},
+		{`$.data.rejected`, false},
+		{`$.data.ids[1] == 8`, true},
+		{`$.data.ids[2]`, false},
+		{`$["data"]["accepted"] == true`, true},
+		{`$["odd key]"] == null`, true},
+		{`$["odd key]"]`, false},
+		{`$.errors == []`, true},
+		{`$.data == {"accepted":true,"ids":[7,8]}`, true},
+		{`$`, true},
+	}
+	for _, tt := range tests {
+		cond, err := ParseJSONPathCondition(tt.expr)
+		if err != nil {
+			t.Errorf("ParseJSONPathCondition(%s): %v", tt.expr, err)
+			continue
+		}
+		if got := cond.Match([]byte(body)); got != tt.want {
+			t.Errorf("%s matched = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+
+	// A body that isn't JSON matches nothing, even with !=
+	cond, _ := ParseJSONPathCondition(`$.status != "error"`)
+	if cond.Match([]byte("OK")) {
+		t.Error("a plain-text body matched a JSONPath condition")
+	}
+
+	for _, expr := range []string{
+		`status == "ok"`, `$.`, `$.status ==`, `$.status = "ok"`, `$.status == ok`, `$[x]`, `$[-1]`, `$["a"`, `$.a[0`,
+	} {
+		if _, err := ParseJSONPathCondition(expr); err == nil {
+			t.Errorf("ParseJSONPathCondition(%s) succeeded, want an error", expr)
+		}
+	}
+}
+
+func TestSuccessCondition_Check(t *testing.T) {
+	cond := SuccessCondition{BodyContains: `"ok"`, JSONPath: `$.status == "ok"`}
+	if why := cond.Check([]byte(`{"status":"ok"}`)); why != "" {
+		t.Errorf("passing body failed: %s", why)
+	}
+	if why := cond.Check([]byte(`{"status":"error"}`)); why == "" {
+		t.Error("error body passed")
+	}
+	if why := cond.Check([]byte(`{"status":"error","detail":"ok"}`)); why == "" {
+		t.Error("body with the substring but failing the JSONPath passed")
+	}
+	if !(SuccessCondition{}).IsZero() || (SuccessCondition{}).Check([]byte("anything")) != "" {
+		t.Error("the empty condition isn't met by every body")
+	}
+}
@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// System event types. The delivery system publishes these about itself to
+// the subscribers created with IsSystem, so it can be wired into existing
+// automation without polling the API. No other subscriber receives them,
+// and they cannot be published through the API or the broker consumers.
+const (
+	EventSubscriberCreated       = "subscriber.created"
+	EventSubscriberCircuitOpened = "subscriber.circuit_opened"
+	EventDeliveryDeadLettered    = "delivery.dead_lettered"
+)
+
+// SystemEventSource is the source recorded on system events.
+const SystemEventSource = "system"
+
+var systemEventTypes = map[string]bool{
+	EventSubscriberCreated:       true,
+	EventSubscriberCircuitOpened: true,
+	EventDeliveryDeadLettered:    true,
+}
+
+// IsSystemEventType reports whether eventType is one of the system event
+// types.
+func IsSystemEventType(eventType string) bool {
+	return systemEventTypes[eventType]
+}
+
+// ValidatePublishedEventType rejects the system event types for events
+// published by clients.
+func ValidatePublishedEventType(eventType string) error {
+	if IsSystemEventType(eventType) {
+		return fmt.Errorf("event type %q is reserved for system events", eventType)
+	}
+	return nil
+}
+
+// SubscriberCreatedEvent is the payload of a subscriber.created event.
+type SubscriberCreatedEvent struct {
+	SubscriberID string   `json:"subscriber_id"`
+	Name         string   `json:"name"`
+	EndpointURL  string   `json:"endpoint_url"`
+	EventTypes   []string `json:"event_types"`
+	IsSystem     bool     `json:"is_system"`
+}
+
+// CircuitOpenedEvent is the payload of a subscriber.circuit_opened event.
+type CircuitOpenedEvent struct {
+	SubscriberID string    `json:"subscriber_id"`
+	Failures     int64     `json:"failures"`
+	OpenedAt     time.Time `json:"opened_at"`
+}
+
+// DeadLetteredEvent is the payload of a delivery.dead_lettered event.
+type DeadLetteredEvent struct {
+	EventID        string        `json:"event_id"`
+	EventType      string        `json:"event_type"`
+	SubscriberID   string        `json:"subscriber_id"`
+	TotalAttempts  int           `json:"total_attempts"`
+	LastHTTPStatus *int          `json:"last_http_status,omitempty"`
+	FailureReason  FailureReason `json:"failure_reason,omitempty"`
+	LastError      string        `json:"last_error,omitempty"`
+}
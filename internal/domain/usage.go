@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Usage is what a subscriber consumed: delivery attempts made to it, how
+// many of them succeeded or were retries, and the payload bytes sent.
+// Simulated attempts to sandbox subscribers are not metered.
+type Usage struct {
+	SubscriberID   string `json:"subscriber_id"`
+	SubscriberName string `json:"subscriber_name"`
+	Deliveries     int64  `json:"deliveries"`
+	Successful     int64  `json:"successful"`
+	Retries        int64  `json:"retries"`
+	PayloadBytes   int64  `json:"payload_bytes"`
+}
+
+// UsageDay is a subscriber's usage on one UTC day.
+type UsageDay struct {
+	Day time.Time `json:"day"`
+	Usage
+}
+
+// UsageCSVHeader names the columns of UsageDay.CSVRecord.
+var UsageCSVHeader = []string{
+	"day", "subscriber_id", "subscriber_name", "deliveries", "successful", "retries", "payload_bytes",
+}
+
+// CSVRecord renders the day as a row of a usage CSV export.
+func (u UsageDay) CSVRecord() []string {
+	return []string{
+		u.Day.Format(time.DateOnly),
+		u.SubscriberID,
+		u.SubscriberName,
+		strconv.FormatInt(u.Deliveries, 10),
+		strconv.FormatInt(u.Successful, 10),
+		strconv.FormatInt(u.Retries, 10),
+		strconv.FormatInt(u.PayloadBytes, 10),
+	}
+}
+
+// UsageTotals sums days per subscriber, ordered by subscriber name.
+func UsageTotals(days []UsageDay) []Usage {
+	bySubscriber := map[string]*Usage{}
+	for _, d := range days {
+		u, ok := bySubscriber[d.SubscriberID]
+		if !ok {
+			u = &Usage{SubscriberID: d.SubscriberID, SubscriberName: d.SubscriberName}
+			bySubscriber[d.SubscriberID] = u
+		}
+		u.Deliveries += d.Deliveries
+		u.Successful += d.Successful
+		u.Retries += d.Retries
+		u.PayloadBytes += d.PayloadBytes
+	}
+
+	totals := make([]Usage, 0, len(bySubscriber))
+	for _, u := range bySubscriber {
+		totals = append(totals, *u)
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		if totals[i].SubscriberName != totals[j].SubscriberName {
+			return totals[i].SubscriberName < totals[j].SubscriberName
+		}
+		return totals[i].SubscriberID < totals[j].SubscriberID
+	})
+	return totals
+}
@@ -0,0 +1,84 @@
+package e2e
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// CapturedRequest is a delivery received by a Capture.
+type CapturedRequest struct {
+	Header     http.Header
+	Body       []byte
+	ReceivedAt time.Time
+}
+
+// Capture is an in-process webhook endpoint that records every request it
+// receives and answers with a configurable status, 200 by default.
+type Capture struct {
+	// URL is the endpoint to subscribe.
+	URL string
+
+	server   *httptest.Server
+	status   atomic.Int32
+	mu       sync.Mutex
+	requests []CapturedRequest
+	received chan struct{}
+}
+
+// NewCapture starts a capture endpoint that is closed when the test ends.
+func NewCapture(t testing.TB) *Capture {
+	t.Helper()
+	c := &Capture{received: make(chan struct{}, 1)}
+	c.status.Store(http.StatusOK)
+	c.server = httptest.NewServer(http.HandlerFunc(c.handle))
+	c.URL = c.server.URL
+	t.Cleanup(c.server.Close)
+	return c
+}
+
+func (c *Capture) handle(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	c.mu.Lock()
+	c.requests = append(c.requests, CapturedRequest{Header: r.Header.Clone(), Body: body, ReceivedAt: time.Now()})
+	c.mu.Unlock()
+	select {
+	case c.received <- struct{}{}:
+	default:
+	}
+	w.WriteHeader(int(c.status.Load()))
+}
+
+// RespondWith sets the status answered from now on.
+func (c *Capture) RespondWith(status int) {
+	c.status.Store(int32(status))
+}
+
+// Requests returns the requests received so far, oldest first.
+func (c *Capture) Requests() []CapturedRequest {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]CapturedRequest(nil), c.requests...)
+}
+
+// WaitFor waits until at least n requests have been received and returns
+// them, failing the test if they don't arrive within timeout.
+func (c *Capture) WaitFor(t testing.TB, n int, timeout time.Duration) []CapturedRequest {
+	t.Helper()
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	for {
+		if got := c.Requests(); len(got) >= n {
+			return got
+		}
+		select {
+		case <-c.received:
+		case <-deadline.C:
+			t.Fatalf("capture endpoint got %d requests within %v, want %d", len(c.Requests()), timeout, n)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+)
+
+// PublishedEvent is the server's answer to publishing an event.
+type PublishedEvent struct {
+	EventID          string `json:"event_id"`
+	EventType        string `json:"event_type"`
+	DeliveriesQueued int    `json:"deliveries_queued"`
+}
+
+// Do sends a request with a JSON body, unless body is nil, and decodes a
+// JSON answer into out, unless out is nil. It fails the test on any other
+// status than want.
+func (e *Environment) Do(t testing.TB, method, path string, body, out any, want int) {
+	t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("%s %s: encoding body: %v", method, path, err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, e.URL+path, reader)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != want {
+		t.Fatalf("%s %s: status %d, want %d: %s", method, path, resp.StatusCode, want, data)
+	}
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			t.Fatalf("%s %s: decoding %s: %v", method, path, data, err)
+		}
+	}
+}
+
+// CreateSubscriber creates a subscriber and returns its ID and secret.
+func (e *Environment) CreateSubscriber(t testing.TB, req domain.CreateSubscriberRequest) domain.CreateSubscriberResponse {
+	t.Helper()
+	var sub domain.CreateSubscriberResponse
+	e.Do(t, http.MethodPost, "/api/v1/subscribers", req, &sub, http.StatusCreated)
+	return sub
+}
+
+// PublishEvent publishes an event with payload, encoded as JSON.
+func (e *Environment) PublishEvent(t testing.TB, eventType string, payload any) PublishedEvent {
+	t.Helper()
+	data, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("encoding payload: %v", err)
+	}
+	var event PublishedEvent
+	e.Do(t, http.MethodPost, "/api/v1/events", map[string]any{
+		"event_type": eventType,
+		"payload":    json.RawMessage(data),
+	}, &event, http.StatusCreated)
+	return event
+}
+
+// Deliveries returns the recorded delivery attempts of an event, newest
+// first.
+func (e *Environment) Deliveries(t testing.TB, eventID string) []domain.DeliveryAttempt {
+	t.Helper()
+	var attempts []domain.DeliveryAttempt
+	e.Do(t, http.MethodGet, "/api/v1/deliveries?event_id="+url.QueryEscape(eventID), nil, &attempts, http.StatusOK)
+	return attempts
+}
+
+// DeadLetters returns the unresolved dead letters of a subscriber.
+func (e *Environment) DeadLetters(t testing.TB, subscriberID string) []domain.DeadLetter {
+	t.Helper()
+	var letters []domain.DeadLetter
+	e.Do(t, http.MethodGet, "/api/v1/dead-letters?subscriber_id="+url.QueryEscape(subscriberID), nil, &letters, http.StatusOK)
+	return letters
+}
+
+// WaitForAttempts waits until an event has at least n recorded delivery
+// attempts and returns them. Attempts are written in batches, so they can
+// lag behind the requests a Capture receives.
+func (e *Environment) WaitForAttempts(t testing.TB, eventID string, n int, timeout time.Duration) []domain.DeliveryAttempt {
+	t.Helper()
+	var attempts []domain.DeliveryAttempt
+	Eventually(t, timeout, func() bool {
+		attempts = e.Deliveries(t, eventID)
+		return len(attempts) >= n
+	}, fmt.Sprintf("%d delivery attempts of event %s", n, eventID))
+	return attempts
+}
+
+// Eventually polls cond until it holds, failing the test with what it was
+// waiting for if it doesn't within timeout.
+func Eventually(t testing.TB, timeout time.Duration, cond func() bool, waitingFor string) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out after %v waiting for %s", timeout, waitingFor)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
@@ -0,0 +1,128 @@
+package e2e_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/e2e"
+	"github.com/Priya8975/webhook-delivery-system/pkg/webhook"
+)
+
+// env is shared by the tests, which keep out of each other's way by
+// publishing event types of their own.
+var env *e2e.Environment
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	if !testing.Short() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		var err error
+		env, err = e2e.Start(ctx)
+		cancel()
+		if err != nil && !errors.Is(err, e2e.ErrNoDocker) {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	code := m.Run()
+	env.Close()
+	os.Exit(code)
+}
+
+func requireEnv(t *testing.T) {
+	t.Helper()
+	if env == nil {
+		t.Skip("end-to-end tests need Docker and are skipped with -short")
+	}
+}
+
+func TestPipeline_DeliversSignedEvent(t *testing.T) {
+	requireEnv(t)
+	capture := e2e.NewCapture(t)
+	sub := env.CreateSubscriber(t, domain.CreateSubscriberRequest{
+		Name:        "e2e-signed",
+		EndpointURL: capture.URL,
+		EventTypes:  []string{"e2e.signed"},
+	})
+
+	event := env.PublishEvent(t, "e2e.signed", map[string]any{"order_id": 42})
+	if event.DeliveriesQueued != 1 {
+		t.Fatalf("deliveries queued = %d, want 1", event.DeliveriesQueued)
+	}
+
+	req := capture.WaitFor(t, 1, 10*time.Second)[0]
+	if !webhook.Verify(req.Body, req.Header.Get(webhook.SignatureHeader), sub.SecretKey) {
+		t.Errorf("signature %q does not verify", req.Header.Get(webhook.SignatureHeader))
+	}
+	var payload map[string]any
+	if err := json.Unmarshal(req.Body, &payload); err != nil || payload["order_id"] != float64(42) {
+		t.Errorf("body = %s, want the published payload", req.Body)
+	}
+
+	attempts := env.WaitForAttempts(t, event.EventID, 1, 10*time.Second)
+	if attempts[0].Status != "success" || attempts[0].SubscriberID != sub.ID {
+		t.Errorf("attempt = %+v, want a success for %s", attempts[0], sub.ID)
+	}
+}
+
+func TestPipeline_RetriesUntilEndpointRecovers(t *testing.T) {
+	requireEnv(t)
+	capture := e2e.NewCapture(t)
+	capture.RespondWith(http.StatusServiceUnavailable)
+	env.CreateSubscriber(t, domain.CreateSubscriberRequest{
+		Name:        "e2e-recovers",
+		EndpointURL: capture.URL,
+		EventTypes:  []string{"e2e.recovers"},
+	})
+
+	event := env.PublishEvent(t, "e2e.recovers", map[string]any{"n": 1})
+	capture.WaitFor(t, 1, 10*time.Second)
+	capture.RespondWith(http.StatusOK)
+
+	reqs := capture.WaitFor(t, 2, 10*time.Second)
+	if reqs[0].Header.Get("X-Webhook-ID") != reqs[1].Header.Get("X-Webhook-ID") {
+		t.Error("the retry was sent as a different event")
+	}
+	attempts := env.WaitForAttempts(t, event.EventID, 2, 10*time.Second)
+	if attempts[0].Status != "success" {
+		t.Errorf("last attempt = %s, want success", attempts[0].Status)
+	}
+}
+
+func TestPipeline_DeadLettersAfterLastAttempt(t *testing.T) {
+	requireEnv(t)
+	capture := e2e.NewCapture(t)
+	capture.RespondWith(http.StatusInternalServerError)
+	sub := env.CreateSubscriber(t, domain.CreateSubscriberRequest{
+		Name:        "e2e-dead",
+		EndpointURL: capture.URL,
+		EventTypes:  []string{"e2e.dead"},
+	})
+
+	event := env.PublishEvent(t, "e2e.dead", map[string]any{"n": 1})
+
+	// RETRY_MAX_ATTEMPTS is 3 in e2e.DefaultEnv
+	capture.WaitFor(t, 3, 15*time.Second)
+	var letters []domain.DeadLetter
+	e2e.Eventually(t, 10*time.Second, func() bool {
+		letters = env.DeadLetters(t, sub.ID)
+		return len(letters) > 0
+	}, "a dead letter")
+	if letters[0].EventID != event.EventID {
+		t.Errorf("dead letter = %+v, want one for event %s", letters[0], event.EventID)
+	}
+
+	time.Sleep(500 * time.Millisecond)
+	if n := len(capture.Requests()); n != 3 {
+		t.Errorf("endpoint got %d requests, want 3", n)
+	}
+}
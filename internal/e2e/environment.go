@@ -0,0 +1,270 @@
+// Package e2e runs the whole delivery pipeline for end-to-end tests: it
+// starts Postgres and Redis in Docker containers, builds and boots
+// cmd/server against them, and drives it through its HTTP API while an
+// in-process Capture endpoint receives the deliveries.
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/ory/dockertest/v3"
+	"github.com/ory/dockertest/v3/docker"
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrNoDocker is returned by Start when Docker can't be reached, so tests
+// can skip rather than fail on machines without it.
+var ErrNoDocker = errors.New("e2e: docker is not available")
+
+// containerTTL bounds how long containers outlive a test run that crashes
+// before Close.
+const containerTTL = 10 * time.Minute
+
+// DefaultEnv is the server configuration Start applies before the caller's
+// settings. Retries are quick so that tests of failing endpoints finish in
+// seconds.
+var DefaultEnv = []string{
+	"RETRY_MAX_ATTEMPTS=3",
+	"RETRY_BASE_DELAY=100ms",
+	"RETRY_MAX_DELAY=500ms",
+	"RETRY_JITTER=0s",
+}
+
+// Environment is a running server with its own Postgres and Redis.
+type Environment struct {
+	// URL is the server's base URL, e.g. http://127.0.0.1:41234.
+	URL string
+	// DatabaseURL and RedisURL reach the containers from the host.
+	DatabaseURL string
+	RedisURL    string
+
+	client    *http.Client
+	pool      *dockertest.Pool
+	resources []*dockertest.Resource
+	dir       string
+	server    *exec.Cmd
+	exited    chan struct{}
+}
+
+// Start starts the containers, then builds the server and boots it with
+// DefaultEnv and env, which holds extra KEY=value settings. It returns once
+// the server reports ready. The caller must Close the environment.
+func Start(ctx context.Context, env ...string) (*Environment, error) {
+	pool, err := dockertest.NewPool("")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNoDocker, err)
+	}
+	if err := pool.Client.Ping(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNoDocker, err)
+	}
+	pool.MaxWait = 2 * time.Minute
+
+	e := &Environment{client: &http.Client{Timeout: 10 * time.Second}, pool: pool}
+	if err := e.start(ctx, env); err != nil {
+		e.Close()
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *Environment) start(ctx context.Context, env []string) error {
+	var err error
+	if e.DatabaseURL, err = e.startPostgres(ctx); err != nil {
+		return err
+	}
+	if e.RedisURL, err = e.startRedis(ctx); err != nil {
+		return err
+	}
+
+	if e.dir, err = os.MkdirTemp("", "webhook-e2e-"); err != nil {
+		return fmt.Errorf("creating work directory: %w", err)
+	}
+	bin, err := buildServer(ctx, e.dir)
+	if err != nil {
+		return err
+	}
+	return e.startServer(ctx, bin, env)
+}
+
+func (e *Environment) run(opts *dockertest.RunOptions) (*dockertest.Resource, error) {
+	resource, err := e.pool.RunWithOptions(opts, func(hc *docker.HostConfig) {
+		hc.AutoRemove = true
+		hc.RestartPolicy = docker.RestartPolicy{Name: "no"}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("starting %s container: %w", opts.Repository, err)
+	}
+	e.resources = append(e.resources, resource)
+	resource.Expire(uint(containerTTL.Seconds()))
+	return resource, nil
+}
+
+func (e *Environment) startPostgres(ctx context.Context) (string, error) {
+	resource, err := e.run(&dockertest.RunOptions{
+		Repository: "postgres",
+		Tag:        "16-alpine",
+		Env:        []string{"POSTGRES_USER=webhook", "POSTGRES_PASSWORD=webhook", "POSTGRES_DB=webhooks"},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	url := fmt.Sprintf("postgres://webhook:webhook@%s/webhooks?sslmode=disable", resource.GetHostPort("5432/tcp"))
+	err = e.pool.Retry(func() error {
+		conn, err := pgx.Connect(ctx, url)
+		if err != nil {
+			return err
+		}
+		defer conn.Close(ctx)
+		return conn.Ping(ctx)
+	})
+	if err != nil {
+		return "", fmt.Errorf("waiting for postgres: %w", err)
+	}
+	return url, nil
+}
+
+func (e *Environment) startRedis(ctx context.Context) (string, error) {
+	resource, err := e.run(&dockertest.RunOptions{Repository: "redis", Tag: "7-alpine"})
+	if err != nil {
+		return "", err
+	}
+
+	url := "redis://" + resource.GetHostPort("6379/tcp")
+	err = e.pool.Retry(func() error {
+		opts, err := redis.ParseURL(url)
+		if err != nil {
+			return err
+		}
+		client := redis.NewClient(opts)
+		defer client.Close()
+		return client.Ping(ctx).Err()
+	})
+	if err != nil {
+		return "", fmt.Errorf("waiting for redis: %w", err)
+	}
+	return url, nil
+}
+
+// buildServer builds cmd/server into dir and returns the binary's path.
+func buildServer(ctx context.Context, dir string) (string, error) {
+	gomod, err := exec.CommandContext(ctx, "go", "env", "GOMOD").Output()
+	if err != nil {
+		return "", fmt.Errorf("locating the module: %w", err)
+	}
+	root := filepath.Dir(strings.TrimSpace(string(gomod)))
+
+	bin := filepath.Join(dir, "server")
+	cmd := exec.CommandContext(ctx, "go", "build", "-o", bin, "./cmd/server")
+	cmd.Dir = root
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("building the server: %w\n%s", err, out)
+	}
+	return bin, nil
+}
+
+func (e *Environment) startServer(ctx context.Context, bin string, env []string) error {
+	port, err := freePort()
+	if err != nil {
+		return err
+	}
+	e.URL = "http://127.0.0.1:" + port
+
+	logs, err := os.Create(filepath.Join(e.dir, "server.log"))
+	if err != nil {
+		return fmt.Errorf("creating server log: %w", err)
+	}
+	defer logs.Close()
+
+	cmd := exec.Command(bin)
+	cmd.Env = append(os.Environ(), "PORT="+port, "DATABASE_URL="+e.DatabaseURL, "REDIS_URL="+e.RedisURL, "CONFIG_FILE=")
+	cmd.Env = append(cmd.Env, DefaultEnv...)
+	cmd.Env = append(cmd.Env, env...)
+	cmd.Stdout = logs
+	cmd.Stderr = logs
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("starting the server: %w", err)
+	}
+	e.server = cmd
+	e.exited = make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(e.exited)
+	}()
+
+	deadline := time.Now().Add(time.Minute)
+	for time.Now().Before(deadline) {
+		select {
+		case <-e.exited:
+			return fmt.Errorf("server exited during startup:\n%s", e.ServerLogs())
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(100 * time.Millisecond):
+		}
+		resp, err := e.client.Get(e.URL + "/readyz")
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("server not ready after a minute:\n%s", e.ServerLogs())
+}
+
+func freePort() (string, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", fmt.Errorf("finding a free port: %w", err)
+	}
+	defer l.Close()
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+	return port, nil
+}
+
+// ServerLogs returns what the server has logged so far, for failure
+// messages.
+func (e *Environment) ServerLogs() string {
+	if e.dir == "" {
+		return ""
+	}
+	data, err := os.ReadFile(filepath.Join(e.dir, "server.log"))
+	if err != nil {
+		return err.Error()
+	}
+	return string(bytes.TrimSpace(data))
+}
+
+// Close stops the server and removes the containers. It is safe to call on
+// a nil or partly started Environment.
+func (e *Environment) Close() {
+	if e == nil {
+		return
+	}
+	if e.server != nil {
+		e.server.Process.Signal(os.Interrupt)
+		select {
+		case <-e.exited:
+		case <-time.After(15 * time.Second):
+			e.server.Process.Kill()
+			<-e.exited
+		}
+	}
+	for _, r := range e.resources {
+		e.pool.Purge(r)
+	}
+	if e.dir != "" {
+		os.RemoveAll(e.dir)
+	}
+}
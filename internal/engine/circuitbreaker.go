@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log/slog"
 	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -26,8 +27,11 @@ const (
 type CircuitBreaker struct {
 	redisClient      *redis.Client
 	logger           *slog.Logger
-	failureThreshold int
-	cooldownPeriod   time.Duration
+	failureThreshold atomic.Int64
+	cooldownPeriod   atomic.Int64 // time.Duration
+	clock            Clock
+	onOpen           func(ctx context.Context, subscriberID string, failures int64, openedAt time.Time)
+	onClose          func(ctx context.Context, subscriberID string, closedAt time.Time)
 }
 
 // CircuitBreakerState represents the current state of a subscriber's circuit.
@@ -35,15 +39,56 @@ type CircuitBreakerState struct {
 	State        string `json:"state"`
 	Failures     int    `json:"failures"`
 	LastFailedAt string `json:"last_failed_at,omitempty"`
+	// OpenedAt is when the circuit last opened from closed. Failed half-open
+	// tests do not move it, so it tells how long the subscriber has been
+	// failing. Empty while the circuit is closed.
+	OpenedAt string `json:"opened_at,omitempty"`
 }
 
 func NewCircuitBreaker(redisClient *redis.Client, logger *slog.Logger) *CircuitBreaker {
-	return &CircuitBreaker{
-		redisClient:      redisClient,
-		logger:           logger,
-		failureThreshold: 5,
-		cooldownPeriod:   30 * time.Second,
+	cb := &CircuitBreaker{
+		redisClient: redisClient,
+		logger:      logger,
+		clock:       SystemClock{},
 	}
+	cb.SetThresholds(5, 30*time.Second)
+	return cb
+}
+
+// SetThresholds changes how many consecutive failures open a circuit and
+// how long it stays open before a test delivery is let through. It is safe
+// to call while deliveries are running; circuits already open keep their
+// state and are judged against the new cooldown.
+func (cb *CircuitBreaker) SetThresholds(failureThreshold int, cooldown time.Duration) {
+	cb.failureThreshold.Store(int64(failureThreshold))
+	cb.cooldownPeriod.Store(int64(cooldown))
+}
+
+// SetClock sets the clock that failures and cooldowns are timed by. Every
+// instance sharing the circuits should use the same one. Call it before
+// deliveries start.
+func (cb *CircuitBreaker) SetClock(c Clock) {
+	cb.clock = c
+}
+
+// OnOpen registers fn to be called when a subscriber's circuit opens. It is
+// called once for each outage, by the instance whose failure opened the
+// circuit; failed half-open tests don't call it again. Register it before
+// deliveries start.
+func (cb *CircuitBreaker) OnOpen(fn func(ctx context.Context, subscriberID string, failures int64, openedAt time.Time)) {
+	cb.onOpen = fn
+}
+
+// OnClose registers fn to be called when a subscriber's circuit closes
+// again after being open. It is called by the instance whose successful
+// delivery closed it, and may be called by more than one instance when
+// deliveries succeed at the same time. Register it before deliveries start.
+func (cb *CircuitBreaker) OnClose(fn func(ctx context.Context, subscriberID string, closedAt time.Time)) {
+	cb.onClose = fn
+}
+
+func (cb *CircuitBreaker) cooldown() time.Duration {
+	return time.Duration(cb.cooldownPeriod.Load())
 }
 
 func cbKey(subscriberID string) string {
@@ -68,7 +113,7 @@ func (cb *CircuitBreaker) AllowRequest(ctx context.Context, subscriberID string)
 	switch state {
 	case StateOpen:
 		// Check if cooldown period has elapsed
-		if time.Now().Unix()-lastFailedAt >= int64(cb.cooldownPeriod.Seconds()) {
+		if cb.clock.Now().Unix()-lastFailedAt >= int64(cb.cooldown().Seconds()) {
 			// Transition to half-open: allow one test request
 			cb.redisClient.HSet(ctx, key, "state", StateHalfOpen)
 			cb.logger.Info("circuit breaker half-open",
@@ -88,6 +133,30 @@ func (cb *CircuitBreaker) AllowRequest(ctx context.Context, subscriberID string)
 	}
 }
 
+// scheduleProbeScript claims the probe of an open circuit (KEYS[1]) for its
+// current cooldown, identified by last_failed_at, and returns last_failed_at.
+// It returns 0 when the circuit isn't open or its probe is already claimed.
+var scheduleProbeScript = redis.NewScript(`
+local s = redis.call('HMGET', KEYS[1], 'state', 'last_failed_at', 'probe_for')
+if s[1] ~= 'open' or not s[2] or s[2] == s[3] then
+    return 0
+end
+redis.call('HSET', KEYS[1], 'probe_for', s[2])
+return tonumber(s[2])
+`)
+
+// ScheduleProbe reports whether the caller should queue the delivery that
+// tests the subscriber's open circuit, and when: the end of the cooldown.
+// Only the first caller of each cooldown gets ok, so one probe is queued
+// per cooldown however many deliveries are deferred.
+func (cb *CircuitBreaker) ScheduleProbe(ctx context.Context, subscriberID string) (at time.Time, ok bool) {
+	lastFailedAt, err := scheduleProbeScript.Run(ctx, cb.redisClient, []string{cbKey(subscriberID)}).Int64()
+	if err != nil || lastFailedAt == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(lastFailedAt, 0).Add(cb.cooldown()), true
+}
+
 // RecordSuccess records a successful delivery. Resets the circuit to closed.
 func (cb *CircuitBreaker) RecordSuccess(ctx context.Context, subscriberID string) {
 	key := cbKey(subscriberID)
@@ -98,12 +167,16 @@ func (cb *CircuitBreaker) RecordSuccess(ctx context.Context, subscriberID string
 		"state", StateClosed,
 		"failures", 0,
 	)
+	cb.redisClient.HDel(ctx, key, "opened_at")
 
 	if state == StateHalfOpen {
 		cb.logger.Info("circuit breaker closed (recovered)",
 			"subscriber_id", subscriberID,
 		)
 	}
+	if (state == StateHalfOpen || state == StateOpen) && cb.onClose != nil {
+		cb.onClose(ctx, subscriberID, cb.clock.Now())
+	}
 }
 
 // RecordFailure records a failed delivery. Opens the circuit if threshold is reached.
@@ -117,7 +190,7 @@ func (cb *CircuitBreaker) RecordFailure(ctx context.Context, subscriberID string
 		return
 	}
 
-	cb.redisClient.HSet(ctx, key, "last_failed_at", time.Now().Unix())
+	cb.redisClient.HSet(ctx, key, "last_failed_at", cb.clock.Now().Unix())
 
 	state, _ := cb.redisClient.HGet(ctx, key, "state").Result()
 
@@ -127,14 +200,20 @@ func (cb *CircuitBreaker) RecordFailure(ctx context.Context, subscriberID string
 		cb.logger.Warn("circuit breaker re-opened (half-open test failed)",
 			"subscriber_id", subscriberID,
 		)
-	} else if failures >= int64(cb.failureThreshold) {
-		// Threshold reached → open the circuit
+	} else if threshold := cb.failureThreshold.Load(); failures >= threshold {
+		// Threshold reached → open the circuit. A failure that was in
+		// flight when it opened keeps the original opening time.
+		now := cb.clock.Now()
 		cb.redisClient.HSet(ctx, key, "state", StateOpen)
+		opened, _ := cb.redisClient.HSetNX(ctx, key, "opened_at", now.Unix()).Result()
 		cb.logger.Warn("circuit breaker opened",
 			"subscriber_id", subscriberID,
 			"failures", failures,
-			"threshold", cb.failureThreshold,
+			"threshold", threshold,
 		)
+		if opened && cb.onOpen != nil {
+			cb.onOpen(ctx, subscriberID, failures, now)
+		}
 	} else {
 		// Ensure state is set to closed if not already set
 		if state == "" {
@@ -143,6 +222,11 @@ func (cb *CircuitBreaker) RecordFailure(ctx context.Context, subscriberID string
 	}
 }
 
+// Reset forgets a subscriber's circuit, leaving it closed with no failures.
+func (cb *CircuitBreaker) Reset(ctx context.Context, subscriberID string) error {
+	return cb.redisClient.Del(ctx, cbKey(subscriberID)).Err()
+}
+
 // GetState returns the current circuit breaker state for a subscriber.
 func (cb *CircuitBreaker) GetState(ctx context.Context, subscriberID string) CircuitBreakerState {
 	key := cbKey(subscriberID)
@@ -161,7 +245,7 @@ func (cb *CircuitBreaker) GetState(ctx context.Context, subscriberID string) Cir
 	// Check if open circuit should transition to half-open
 	if state == StateOpen {
 		lastFailedAt, _ := strconv.ParseInt(data["last_failed_at"], 10, 64)
-		if time.Now().Unix()-lastFailedAt >= int64(cb.cooldownPeriod.Seconds()) {
+		if cb.clock.Now().Unix()-lastFailedAt >= int64(cb.cooldown().Seconds()) {
 			state = StateHalfOpen
 		}
 	}
@@ -177,6 +261,11 @@ func (cb *CircuitBreaker) GetState(ctx context.Context, subscriberID string) Cir
 			result.LastFailedAt = time.Unix(lastFailed, 0).Format(time.RFC3339)
 		}
 	}
+	if state != StateClosed {
+		if openedAt, _ := strconv.ParseInt(data["opened_at"], 10, 64); openedAt > 0 {
+			result.OpenedAt = time.Unix(openedAt, 0).Format(time.RFC3339)
+		}
+	}
 
 	return result
 }
@@ -125,7 +125,9 @@ func TestCircuitBreaker_SuccessResets(t *testing.T) {
 }
 
 func TestCircuitBreaker_TransitionsToHalfOpen(t *testing.T) {
-	cb, mr := setupTestCB(t)
+	cb, _ := setupTestCB(t)
+	clock := NewManualClock(time.Now())
+	cb.SetClock(clock)
 	ctx := context.Background()
 
 	// Open the circuit
@@ -139,11 +141,14 @@ func TestCircuitBreaker_TransitionsToHalfOpen(t *testing.T) {
 		t.Fatal("circuit should be open and blocking")
 	}
 
-	// Set last_failed_at to 31 seconds ago (past the 30s cooldown)
-	pastTime := time.Now().Unix() - 31
-	mr.HSet(cbKey("sub-1"), "last_failed_at", fmt.Sprintf("%d", pastTime))
+	// Still open a second before the 30s cooldown ends
+	clock.Advance(29 * time.Second)
+	if state, allowed := cb.AllowRequest(ctx, "sub-1"); state != StateOpen || allowed {
+		t.Fatalf("state %q, allowed %v before the cooldown ended", state, allowed)
+	}
 
 	// Now it should transition to half-open and allow one request
+	clock.Advance(time.Second)
 	state, allowed = cb.AllowRequest(ctx, "sub-1")
 	if state != StateHalfOpen {
 		t.Errorf("expected state %q, got %q", StateHalfOpen, state)
@@ -190,6 +195,60 @@ func TestCircuitBreaker_HalfOpenFailure_ReopensCircuit(t *testing.T) {
 	}
 }
 
+func TestCircuitBreaker_ScheduleProbeOncePerCooldown(t *testing.T) {
+	cb, _ := setupTestCB(t)
+	start := time.Now().Truncate(time.Second)
+	clock := NewManualClock(start)
+	cb.SetClock(clock)
+	ctx := context.Background()
+
+	if _, ok := cb.ScheduleProbe(ctx, "sub-1"); ok {
+		t.Error("probe scheduled for a closed circuit")
+	}
+
+	for i := 0; i < 5; i++ {
+		cb.RecordFailure(ctx, "sub-1")
+	}
+	at, ok := cb.ScheduleProbe(ctx, "sub-1")
+	if !ok || !at.Equal(start.Add(30*time.Second)) {
+		t.Errorf("ScheduleProbe = %v, %v, want the end of the cooldown", at, ok)
+	}
+	if _, ok := cb.ScheduleProbe(ctx, "sub-1"); ok {
+		t.Error("second probe scheduled for the same cooldown")
+	}
+
+	// A failed probe re-opens the circuit for a new cooldown, which gets
+	// its own probe
+	clock.Advance(45 * time.Second)
+	cb.AllowRequest(ctx, "sub-1")
+	cb.RecordFailure(ctx, "sub-1")
+	if at, ok := cb.ScheduleProbe(ctx, "sub-1"); !ok || !at.Equal(start.Add(75*time.Second)) {
+		t.Errorf("ScheduleProbe after a failed probe = %v, %v", at, ok)
+	}
+}
+
+func TestCircuitBreaker_OpenedAtSurvivesHalfOpenFailures(t *testing.T) {
+	cb, mr := setupTestCB(t)
+	ctx := context.Background()
+
+	openCircuitAndExpireCooldown(t, cb, mr, "sub-1")
+	openedAt := time.Now().Add(-2 * time.Hour).Unix()
+	mr.HSet(cbKey("sub-1"), "opened_at", fmt.Sprintf("%d", openedAt))
+
+	cb.AllowRequest(ctx, "sub-1")
+	cb.RecordFailure(ctx, "sub-1")
+
+	want := time.Unix(openedAt, 0).Format(time.RFC3339)
+	if state := cb.GetState(ctx, "sub-1"); state.OpenedAt != want {
+		t.Errorf("opened_at = %q after half-open failure, want %q", state.OpenedAt, want)
+	}
+
+	cb.RecordSuccess(ctx, "sub-1")
+	if state := cb.GetState(ctx, "sub-1"); state.OpenedAt != "" {
+		t.Errorf("opened_at = %q after recovery, want empty", state.OpenedAt)
+	}
+}
+
 func TestCircuitBreaker_IsolationBetweenSubscribers(t *testing.T) {
 	cb, _ := setupTestCB(t)
 	ctx := context.Background()
@@ -208,3 +267,59 @@ func TestCircuitBreaker_IsolationBetweenSubscribers(t *testing.T) {
 		t.Error("sub-2 should be allowed — circuit breakers are per-subscriber")
 	}
 }
+
+func TestCircuitBreaker_OnOpenCalledOncePerOutage(t *testing.T) {
+	cb, mr := setupTestCB(t)
+	ctx := context.Background()
+
+	var opened []int64
+	cb.OnOpen(func(_ context.Context, subscriberID string, failures int64, _ time.Time) {
+		if subscriberID != "sub-1" {
+			t.Errorf("OnOpen called for %q", subscriberID)
+		}
+		opened = append(opened, failures)
+	})
+
+	openCircuitAndExpireCooldown(t, cb, mr, "sub-1")
+	cb.RecordFailure(ctx, "sub-1")
+	cb.AllowRequest(ctx, "sub-1")
+	cb.RecordFailure(ctx, "sub-1")
+	if len(opened) != 1 || opened[0] != 5 {
+		t.Fatalf("OnOpen calls = %v, want one at 5 failures", opened)
+	}
+
+	// After recovery a new outage is reported again
+	cb.RecordSuccess(ctx, "sub-1")
+	for i := 0; i < 5; i++ {
+		cb.RecordFailure(ctx, "sub-1")
+	}
+	if len(opened) != 2 {
+		t.Errorf("OnOpen calls = %v, want a second outage reported", opened)
+	}
+}
+
+func TestCircuitBreaker_OnCloseCalledAfterOutage(t *testing.T) {
+	cb, mr := setupTestCB(t)
+	ctx := context.Background()
+
+	var closed []string
+	cb.OnClose(func(_ context.Context, subscriberID string, _ time.Time) {
+		closed = append(closed, subscriberID)
+	})
+
+	// Successes while closed are not a recovery
+	cb.RecordSuccess(ctx, "sub-1")
+	cb.RecordFailure(ctx, "sub-1")
+	cb.RecordSuccess(ctx, "sub-1")
+	if len(closed) != 0 {
+		t.Fatalf("OnClose calls = %v while the circuit was closed", closed)
+	}
+
+	openCircuitAndExpireCooldown(t, cb, mr, "sub-1")
+	cb.AllowRequest(ctx, "sub-1")
+	cb.RecordSuccess(ctx, "sub-1")
+	cb.RecordSuccess(ctx, "sub-1")
+	if len(closed) != 1 || closed[0] != "sub-1" {
+		t.Errorf("OnClose calls = %v, want one for the recovery", closed)
+	}
+}
@@ -0,0 +1,81 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+)
+
+// CleanupStore resolves the dead letters of removed subscribers.
+type CleanupStore interface {
+	ResolveSubscriberDeadLetters(ctx context.Context, subscriberID string, resolvedBy string) (int64, error)
+}
+
+// SubscriberCleanup clears what a subscriber leaves behind in the delivery
+// engine once it is deleted or deactivated: its queued, retrying and parked
+// jobs, its circuit breaker and rate limiter state and, optionally, its
+// unresolved dead letters.
+type SubscriberCleanup struct {
+	store   CleanupStore
+	queue   Queue
+	breaker *CircuitBreaker
+	limiter *RateLimiter
+	logger  *slog.Logger
+}
+
+// CleanupResult reports what a cleanup removed.
+type CleanupResult struct {
+	JobsPurged          int64 `json:"jobs_purged"`
+	DeadLettersResolved int64 `json:"dead_letters_resolved"`
+}
+
+func NewSubscriberCleanup(s CleanupStore, queue Queue, breaker *CircuitBreaker, limiter *RateLimiter, logger *slog.Logger) *SubscriberCleanup {
+	return &SubscriberCleanup{
+		store:   s,
+		queue:   queue,
+		breaker: breaker,
+		limiter: limiter,
+		logger:  logger,
+	}
+}
+
+// Cleanup removes a subscriber's delivery state, and resolves its dead
+// letters with resolved_by domain.DeadLetterSubscriberRemoved when
+// resolveDeadLetters is set. Every step is attempted even if an earlier one
+// fails, and the errors are returned together. Jobs a worker is already
+// delivering are not affected.
+func (c *SubscriberCleanup) Cleanup(ctx context.Context, subscriberID string, resolveDeadLetters bool) (CleanupResult, error) {
+	var result CleanupResult
+	var errs []error
+
+	purged, err := c.queue.PurgePending(ctx, subscriberID)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("purging pending jobs: %w", err))
+	}
+	result.JobsPurged = purged
+
+	if err := c.breaker.Reset(ctx, subscriberID); err != nil {
+		errs = append(errs, fmt.Errorf("resetting circuit breaker: %w", err))
+	}
+	if err := c.limiter.Reset(ctx, subscriberID); err != nil {
+		errs = append(errs, fmt.Errorf("resetting rate limiter: %w", err))
+	}
+
+	if resolveDeadLetters {
+		resolved, err := c.store.ResolveSubscriberDeadLetters(ctx, subscriberID, domain.DeadLetterSubscriberRemoved)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		result.DeadLettersResolved = resolved
+	}
+
+	c.logger.Info("subscriber cleaned up",
+		"subscriber_id", subscriberID,
+		"jobs_purged", result.JobsPurged,
+		"dead_letters_resolved", result.DeadLettersResolved,
+	)
+	return result, errors.Join(errs...)
+}
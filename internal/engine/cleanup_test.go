@@ -0,0 +1,70 @@
+package engine
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+type resolvingStore struct {
+	resolvedBy map[string]string
+}
+
+func (s *resolvingStore) ResolveSubscriberDeadLetters(_ context.Context, subscriberID string, resolvedBy string) (int64, error) {
+	s.resolvedBy[subscriberID] = resolvedBy
+	return 2, nil
+}
+
+func TestSubscriberCleanup_ClearsDeliveryState(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { client.Close() })
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	queue := NewMemoryQueue()
+	breaker := NewCircuitBreaker(client, logger)
+	limiter := NewRateLimiter(client, logger)
+	s := &resolvingStore{resolvedBy: make(map[string]string)}
+	c := NewSubscriberCleanup(s, queue, breaker, limiter, logger)
+
+	for _, sub := range []string{"sub-1", "sub-2"} {
+		queue.Enqueue(ctx, DeliveryJob{EventID: "evt-1", SubscriberID: sub, Attempt: 1}, time.Now().Add(time.Hour))
+		for i := 0; i < 5; i++ {
+			breaker.RecordFailure(ctx, sub)
+		}
+		limiter.Allow(ctx, sub, 1)
+	}
+
+	result, err := c.Cleanup(ctx, "sub-1", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.JobsPurged != 1 || result.DeadLettersResolved != 0 || len(s.resolvedBy) != 0 {
+		t.Errorf("result = %+v, resolved = %v", result, s.resolvedBy)
+	}
+	if state := breaker.GetState(ctx, "sub-1"); state.State != StateClosed || state.Failures != 0 {
+		t.Errorf("sub-1 circuit = %+v, want it reset", state)
+	}
+	if !limiter.Allow(ctx, "sub-1", 1) {
+		t.Error("sub-1 still rate limited after cleanup")
+	}
+	if state := breaker.GetState(ctx, "sub-2"); state.State != StateOpen {
+		t.Errorf("sub-2 circuit = %+v, want it left open", state)
+	}
+	if pending, _ := queue.PendingJobs(ctx, "sub-2"); len(pending) != 1 {
+		t.Errorf("sub-2 pending jobs = %+v, want them kept", pending)
+	}
+
+	result, err = c.Cleanup(ctx, "sub-2", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.DeadLettersResolved != 2 || s.resolvedBy["sub-2"] != "subscriber_removed" {
+		t.Errorf("result = %+v, resolved = %v", result, s.resolvedBy)
+	}
+}
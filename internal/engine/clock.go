@@ -0,0 +1,121 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Clock tells the time that queued jobs are scheduled and claimed by.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is the local clock. It is enough while a single process
+// schedules and claims every job, as with MemoryQueue.
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time { return time.Now() }
+
+// ManualClock is a Clock that only moves when it is told to, for testing
+// scheduling without waiting on real time.
+type ManualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewManualClock returns a clock stopped at now.
+func NewManualClock(now time.Time) *ManualClock {
+	return &ManualClock{now: now}
+}
+
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// clockSkewWarning is how far the local clock may be from Redis before Sync
+// logs it.
+const clockSkewWarning = time.Second
+
+// RedisClock follows the Redis server's clock, so instances whose own clocks
+// disagree still agree on when a scheduled retry is due: one scheduling with
+// a fast clock would otherwise fire its retries early on the others, and one
+// polling with a slow clock would claim them late.
+//
+// Now costs no round trip. Sync measures how far Redis TIME is from the local
+// clock and Now adds that offset to it, so the clock starts out local until
+// the first Sync.
+type RedisClock struct {
+	client *redis.Client
+	logger *slog.Logger
+
+	offset atomic.Int64 // nanoseconds the Redis clock is ahead of the local one
+}
+
+var _ Clock = (*RedisClock)(nil)
+
+func NewRedisClock(client *redis.Client, logger *slog.Logger) *RedisClock {
+	return &RedisClock{client: client, logger: logger}
+}
+
+func (c *RedisClock) Now() time.Time {
+	return time.Now().Add(c.Offset())
+}
+
+// Offset returns how far the Redis clock was ahead of the local clock at the
+// last Sync. It is negative when the local clock is ahead.
+func (c *RedisClock) Offset() time.Duration {
+	return time.Duration(c.offset.Load())
+}
+
+// Sync reads Redis TIME and updates the offset. Redis is taken to have read
+// its clock halfway through the round trip.
+func (c *RedisClock) Sync(ctx context.Context) error {
+	sent := time.Now()
+	redisNow, err := c.client.Time(ctx).Result()
+	if err != nil {
+		return fmt.Errorf("reading redis time: %w", err)
+	}
+	received := time.Now()
+
+	offset := redisNow.Sub(sent.Add(received.Sub(sent) / 2))
+	if old := c.Offset(); (offset-old).Abs() > clockSkewWarning && offset.Abs() > clockSkewWarning {
+		c.logger.Warn("local clock is off from Redis, scheduling by the Redis clock",
+			"offset_ms", offset.Milliseconds(),
+		)
+	}
+	c.offset.Store(int64(offset))
+	return nil
+}
+
+// Start syncs on every interval until the context is cancelled, so the
+// offset follows the local clock as it drifts or is stepped.
+func (c *RedisClock) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.Sync(ctx); err != nil && ctx.Err() == nil {
+				c.logger.Warn("failed to sync clock with Redis, keeping the last offset", "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package engine
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestRedisClock_FollowsRedisTime(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	ctx := context.Background()
+
+	clock := NewRedisClock(client, slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError})))
+	if clock.Offset() != 0 {
+		t.Fatalf("offset before the first sync = %v, want 0", clock.Offset())
+	}
+
+	// Redis is ten minutes ahead of this instance
+	mr.SetTime(time.Now().Add(10 * time.Minute))
+	if err := clock.Sync(ctx); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if off := clock.Offset(); (off - 10*time.Minute).Abs() > time.Second {
+		t.Errorf("offset = %v, want about 10m", off)
+	}
+	if ahead := time.Until(clock.Now()); (ahead - 10*time.Minute).Abs() > time.Second {
+		t.Errorf("clock is %v ahead of the local clock, want about 10m", ahead)
+	}
+}
+
+func TestRedisQueue_ScoresByItsClock(t *testing.T) {
+	client := setupTestQueue(t)
+	ctx := context.Background()
+
+	// The publishing instance's local clock is an hour behind
+	at := time.Now().Add(time.Hour).Truncate(time.Microsecond)
+	q := NewRedisQueue(client, nil)
+	q.SetClock(NewManualClock(at))
+	if err := q.Publish(ctx, "evt-1", []byte(`{}`), []DeliveryJob{{EventID: "evt-1", SubscriberID: "sub-1"}}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	next, ok, err := q.NextJobAt(ctx)
+	if err != nil || !ok || !next.Equal(at) {
+		t.Errorf("next job at %v (%v, %v), want %v", next, ok, err, at)
+	}
+
+	q.Pause(ctx, "sub-1", at.Add(time.Minute))
+	if status, _ := q.PauseStatus(ctx, "sub-1"); !status.Paused {
+		t.Error("pause ending a minute from the clock's now is over")
+	}
+}
@@ -0,0 +1,247 @@
+package engine
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ClusterInstancesKey is a Redis set of every instance ID that has registered
+// a heartbeat. The reaper walks it to find instances that have gone away.
+const ClusterInstancesKey = "cluster:instances"
+
+// HeartbeatKey returns the key an instance refreshes to prove it is alive.
+func HeartbeatKey(instanceID string) string {
+	return fmt.Sprintf("cluster:heartbeat:%s", instanceID)
+}
+
+// ClaimsKey returns the sorted set holding jobs an instance has claimed from
+// the delivery queue but not yet finished. Scores are the jobs' original
+// ready times so they can be put back in the same position.
+func ClaimsKey(instanceID string) string {
+	return fmt.Sprintf("delivery_claims:%s", instanceID)
+}
+
+// reapScript returns every job claimed by an instance to the delivery queue
+// and forgets the instance, unless its heartbeat is still alive. Probe jobs
+// are indexed in the probe queue again, since claiming them dropped their
+// entry.
+//
+// KEYS: heartbeat, claims, delivery queue, instances set, notify list,
+// probe queue
+// ARGV: instance ID
+// Returns the number of jobs requeued, or -1 if the instance is alive.
+var reapScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+    return -1
+end
+local jobs = redis.call('ZRANGE', KEYS[2], 0, -1, 'WITHSCORES')
+for i = 1, #jobs, 2 do
+    redis.call('ZADD', KEYS[3], jobs[i+1], jobs[i])
+    local ok, job = pcall(cjson.decode, jobs[i])
+    if ok and type(job) == 'table' and job.probe == true then
+        redis.call('ZADD', KEYS[6], jobs[i+1], jobs[i])
+    end
+end
+redis.call('DEL', KEYS[2])
+redis.call('SREM', KEYS[4], ARGV[1])
+if #jobs > 0 then
+    redis.call('LPUSH', KEYS[5], 1)
+    redis.call('LTRIM', KEYS[5], 0, 0)
+end
+return #jobs / 2
+`)
+
+// returnScript moves one claimed job back to the delivery queue at its
+// original score, and a probe job back into the probe queue.
+// KEYS: claims, delivery queue, notify list, probe queue. ARGV: member.
+// Returns 1 if the job was returned, 0 if it was no longer claimed.
+var returnScript = redis.NewScript(`
+local score = redis.call('ZSCORE', KEYS[1], ARGV[1])
+if not score then
+    return 0
+end
+redis.call('ZADD', KEYS[2], score, ARGV[1])
+local ok, job = pcall(cjson.decode, ARGV[1])
+if ok and type(job) == 'table' and job.probe == true then
+    redis.call('ZADD', KEYS[4], score, ARGV[1])
+end
+redis.call('ZREM', KEYS[1], ARGV[1])
+redis.call('LPUSH', KEYS[3], 1)
+redis.call('LTRIM', KEYS[3], 0, 0)
+return 1
+`)
+
+// Cluster lets several server instances share one delivery queue safely.
+//
+// Each instance claims jobs into its own claims set (see ClaimReadyJobs) and
+// acknowledges them once delivery finishes. While running it refreshes a
+// heartbeat key with a short TTL. Every instance also acts as a reaper: when
+// another instance's heartbeat expires, its unacknowledged claims are moved
+// back to the delivery queue so another replica can deliver them.
+//
+// Deliveries are at-least-once: an instance that stalls for longer than the
+// heartbeat TTL may have its claims reassigned while it is still delivering.
+type Cluster struct {
+	client       *redis.Client
+	instanceID   string
+	heartbeatTTL time.Duration
+	logger       *slog.Logger
+}
+
+// NewCluster creates a cluster member. An empty instanceID generates one from
+// the hostname and a random suffix.
+func NewCluster(client *redis.Client, instanceID string, heartbeatTTL time.Duration, logger *slog.Logger) *Cluster {
+	if instanceID == "" {
+		instanceID = generateInstanceID()
+	}
+	return &Cluster{
+		client:       client,
+		instanceID:   instanceID,
+		heartbeatTTL: heartbeatTTL,
+		logger:       logger,
+	}
+}
+
+// InstanceID returns this instance's identifier.
+func (c *Cluster) InstanceID() string {
+	return c.instanceID
+}
+
+// ClaimsKey returns this instance's claims set.
+func (c *Cluster) ClaimsKey() string {
+	return ClaimsKey(c.instanceID)
+}
+
+// Start refreshes the heartbeat and reaps dead instances until the context is
+// cancelled. Callers should Heartbeat once before dispatching so the instance
+// is registered before it holds any claims.
+func (c *Cluster) Start(ctx context.Context) {
+	c.logger.Info("cluster member started",
+		"instance_id", c.instanceID,
+		"heartbeat_ttl", c.heartbeatTTL.String(),
+	)
+
+	ticker := time.NewTicker(c.heartbeatTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		if err := c.Heartbeat(ctx); err != nil && ctx.Err() == nil {
+			c.logger.Error("failed to refresh heartbeat", "error", err)
+		}
+		if err := c.ReapDeadInstances(ctx); err != nil && ctx.Err() == nil {
+			c.logger.Error("failed to reap dead instances", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Heartbeat registers the instance and refreshes its liveness key.
+func (c *Cluster) Heartbeat(ctx context.Context) error {
+	pipe := c.client.Pipeline()
+	pipe.Set(ctx, HeartbeatKey(c.instanceID), time.Now().UTC().Format(time.RFC3339), c.heartbeatTTL)
+	pipe.SAdd(ctx, ClusterInstancesKey, c.instanceID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("refreshing heartbeat: %w", err)
+	}
+	return nil
+}
+
+// ReapDeadInstances requeues the claims of every registered instance whose
+// heartbeat has expired.
+func (c *Cluster) ReapDeadInstances(ctx context.Context) error {
+	ids, err := c.client.SMembers(ctx, ClusterInstancesKey).Result()
+	if err != nil {
+		return fmt.Errorf("listing instances: %w", err)
+	}
+
+	for _, id := range ids {
+		if id == c.instanceID {
+			continue
+		}
+
+		requeued, err := c.reap(ctx, id)
+		if err != nil {
+			return err
+		}
+		if requeued >= 0 {
+			c.logger.Warn("reassigned jobs from dead instance",
+				"dead_instance_id", id,
+				"jobs_requeued", requeued,
+			)
+		}
+	}
+	return nil
+}
+
+// Release returns this instance's outstanding claims to the delivery queue
+// and deregisters it. Call it during shutdown once workers have stopped.
+func (c *Cluster) Release(ctx context.Context) (int64, error) {
+	if err := c.client.Del(ctx, HeartbeatKey(c.instanceID)).Err(); err != nil {
+		return 0, fmt.Errorf("removing heartbeat: %w", err)
+	}
+	return c.reap(ctx, c.instanceID)
+}
+
+func (c *Cluster) reap(ctx context.Context, instanceID string) (int64, error) {
+	keys := []string{
+		HeartbeatKey(instanceID),
+		ClaimsKey(instanceID),
+		DeliveryQueueKey,
+		ClusterInstancesKey,
+		DeliveryQueueNotifyKey,
+		ProbeQueueKey,
+	}
+	n, err := reapScript.Run(ctx, c.client, keys, instanceID).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("reaping instance %s: %w", instanceID, err)
+	}
+	return n, nil
+}
+
+// Ack marks a claimed job as finished. It is a no-op on a nil Cluster or an
+// empty member, so unclustered callers and tests need no special casing.
+func (c *Cluster) Ack(ctx context.Context, member string) error {
+	if c == nil || member == "" {
+		return nil
+	}
+	if err := c.client.ZRem(ctx, c.ClaimsKey(), member).Err(); err != nil {
+		return fmt.Errorf("acknowledging job: %w", err)
+	}
+	return nil
+}
+
+// Return puts a claimed but undelivered job back on the delivery queue at its
+// original ready time. Like Ack it is a no-op on a nil Cluster or an empty
+// member.
+func (c *Cluster) Return(ctx context.Context, member string) error {
+	if c == nil || member == "" {
+		return nil
+	}
+	keys := []string{c.ClaimsKey(), DeliveryQueueKey, DeliveryQueueNotifyKey, ProbeQueueKey}
+	if err := returnScript.Run(ctx, c.client, keys, member).Err(); err != nil {
+		return fmt.Errorf("returning job: %w", err)
+	}
+	return nil
+}
+
+func generateInstanceID() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "instance"
+	}
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return host + "-" + hex.EncodeToString(suffix)
+}
@@ -0,0 +1,135 @@
+package engine
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestCluster_ReassignsJobsFromDeadInstance(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	ctx := context.Background()
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	dead := NewCluster(client, "dead", 5*time.Second, logger)
+	alive := NewCluster(client, "alive", 5*time.Second, logger)
+	dead.Heartbeat(ctx)
+	alive.Heartbeat(ctx)
+
+	readyAt := time.Now().Add(-time.Second)
+	EnqueueJob(ctx, client, DeliveryJob{EventID: "a"}, readyAt)
+	EnqueueJob(ctx, client, DeliveryJob{EventID: "b"}, readyAt)
+
+	claimed, err := ClaimReadyJobs(ctx, client, dead.ClaimsKey(), time.Now(), 10, 0)
+	if err != nil || len(claimed) != 2 {
+		t.Fatalf("claim: got %d jobs, err=%v", len(claimed), err)
+	}
+
+	// One job finishes before the instance dies
+	dead.Ack(ctx, claimed[0].Member)
+
+	// Heartbeat still alive: nothing is reassigned
+	alive.ReapDeadInstances(ctx)
+	if depth := client.ZCard(ctx, DeliveryQueueKey).Val(); depth != 0 {
+		t.Fatalf("jobs reassigned from a live instance, queue depth %d", depth)
+	}
+
+	mr.FastForward(6 * time.Second)
+	alive.Heartbeat(ctx)
+
+	if err := alive.ReapDeadInstances(ctx); err != nil {
+		t.Fatalf("ReapDeadInstances failed: %v", err)
+	}
+
+	queued, _ := client.ZRangeWithScores(ctx, DeliveryQueueKey, 0, -1).Result()
+	if len(queued) != 1 || queued[0].Member != claimed[1].Member {
+		t.Fatalf("expected only the unacknowledged job to be requeued, got %v", queued)
+	}
+	if got := time.UnixMicro(int64(queued[0].Score)); !got.Equal(claimed[1].ReadyAt) {
+		t.Errorf("requeued with score %v, want original ready time %v", got, claimed[1].ReadyAt)
+	}
+
+	if client.Exists(ctx, dead.ClaimsKey()).Val() != 0 {
+		t.Error("dead instance's claims set should be removed")
+	}
+	if client.SIsMember(ctx, ClusterInstancesKey, "dead").Val() {
+		t.Error("dead instance should be deregistered")
+	}
+}
+
+func TestCluster_ReleaseOnShutdown(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	ctx := context.Background()
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	c := NewCluster(client, "", 5*time.Second, logger)
+	if c.InstanceID() == "" {
+		t.Fatal("expected a generated instance ID")
+	}
+	c.Heartbeat(ctx)
+
+	EnqueueJob(ctx, client, DeliveryJob{EventID: "a"}, time.Now())
+	ClaimReadyJobs(ctx, client, c.ClaimsKey(), time.Now(), 10, 0)
+
+	n, err := c.Release(ctx)
+	if err != nil || n != 1 {
+		t.Fatalf("Release: n=%d err=%v, want 1 job released", n, err)
+	}
+	if depth := client.ZCard(ctx, DeliveryQueueKey).Val(); depth != 1 {
+		t.Errorf("queue depth = %d, want 1", depth)
+	}
+}
+
+func TestCluster_ReturnedProbeKeepsItsPlace(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	ctx := context.Background()
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	dead := NewCluster(client, "dead", 5*time.Second, logger)
+	alive := NewCluster(client, "alive", 5*time.Second, logger)
+	dead.Heartbeat(ctx)
+	alive.Heartbeat(ctx)
+
+	now := time.Now()
+	EnqueueJob(ctx, client, DeliveryJob{EventID: "probe", SubscriberID: "down", Probe: true}, now.Add(-time.Second))
+	claimed, err := ClaimReadyJobs(ctx, client, alive.ClaimsKey(), now, 1, 0)
+	if err != nil || len(claimed) != 1 {
+		t.Fatalf("claim: got %d jobs, err=%v", len(claimed), err)
+	}
+	EnqueueJob(ctx, client, DeliveryJob{EventID: "backlog", SubscriberID: "busy"}, now.Add(-time.Minute))
+
+	// Handed back, e.g. by a draining pool
+	if err := alive.Return(ctx, claimed[0].Member); err != nil {
+		t.Fatalf("Return failed: %v", err)
+	}
+	claimed, _ = ClaimReadyJobs(ctx, client, dead.ClaimsKey(), now, 1, 0)
+	if got := claimedSubscribers(t, claimed); !reflect.DeepEqual(got, []string{"down"}) {
+		t.Fatalf("claimed %v after Return, want the probe ahead of the older job", got)
+	}
+
+	// Requeued by the reaper after its instance died
+	mr.FastForward(6 * time.Second)
+	alive.Heartbeat(ctx)
+	if err := alive.ReapDeadInstances(ctx); err != nil {
+		t.Fatalf("ReapDeadInstances failed: %v", err)
+	}
+	claimed, _ = ClaimReadyJobs(ctx, client, alive.ClaimsKey(), now, 1, 0)
+	if got := claimedSubscribers(t, claimed); !reflect.DeepEqual(got, []string{"down"}) {
+		t.Errorf("claimed %v after the reap, want the probe ahead of the older job", got)
+	}
+}
@@ -3,101 +3,598 @@ package engine
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/Priya8975/webhook-delivery-system/internal/domain"
 	"github.com/Priya8975/webhook-delivery-system/internal/store"
-	"github.com/redis/go-redis/v9"
 )
 
 const DeliveryQueueKey = "delivery_queue"
 
-// DeliveryJob represents a single webhook delivery task queued in Redis.
+// PayloadTTL is how long the queue keeps its copy of an event payload.
+// It only needs to outlive the retry schedule; after it expires the
+// deliverer falls back to loading the payload from Postgres.
+const PayloadTTL = 24 * time.Hour
+
+// PayloadKey returns the Redis key holding the payload for an event.
+func PayloadKey(eventID string) string {
+	return fmt.Sprintf("payload:%s", eventID)
+}
+
+// DeliveryJob represents a single webhook delivery task on the delivery queue.
+// The payload is stored once per event under PayloadKey rather than copied
+// into every job; Payload is only set on jobs queued by older versions.
 type DeliveryJob struct {
-	EventID            string          `json:"event_id"`
-	SubscriberID       string          `json:"subscriber_id"`
-	EndpointURL        string          `json:"endpoint_url"`
-	Payload            json.RawMessage `json:"payload"`
-	SecretKey          string          `json:"secret_key"`
-	EventType          string          `json:"event_type"`
-	Attempt            int             `json:"attempt"`
-	MaxRetries         int             `json:"max_retries"`
-	RateLimitPerSecond int             `json:"rate_limit_per_second"`
-}
-
-// FanOutEngine distributes events to matching subscribers via Redis queue.
+	EventID      string          `json:"event_id"`
+	SubscriberID string          `json:"subscriber_id"`
+	EndpointURL  string          `json:"endpoint_url"`
+	Payload      json.RawMessage `json:"payload,omitempty"`
+	// PayloadRef is set instead when the payload is kept in object storage.
+	PayloadRef         *domain.PayloadRef `json:"payload_ref,omitempty"`
+	SecretKey          string             `json:"secret_key"`
+	EventType          string             `json:"event_type"`
+	Attempt            int                `json:"attempt"`
+	MaxRetries         int                `json:"max_retries"`
+	RateLimitPerSecond int                `json:"rate_limit_per_second"`
+	CompressPayload    bool               `json:"compress_payload,omitempty"`
+	DiscardResponse    bool               `json:"discard_response,omitempty"`
+	// BatchMaxEvents and BatchWindowMs are set for subscribers that take
+	// batched deliveries.
+	BatchMaxEvents int `json:"batch_max_events,omitempty"`
+	BatchWindowMs  int `json:"batch_window_ms,omitempty"`
+	// ProxyURL overrides the deliverer's proxy for this subscriber.
+	ProxyURL string `json:"proxy_url,omitempty"`
+	// SignatureFormat is how the job's requests are signed. Empty means
+	// domain.SignatureStandard.
+	SignatureFormat string `json:"signature_format,omitempty"`
+	// RetryJitter is the jitter strategy of the job's retries. Empty means
+	// the deliverer's default.
+	RetryJitter string `json:"retry_jitter,omitempty"`
+	// RetryDelayMs is the delay before the current attempt, 0 on the first,
+	// from which decorrelated jitter draws the next one.
+	RetryDelayMs int64 `json:"retry_delay_ms,omitempty"`
+	// MaxRetryDurationMs, if set, dead-letters the job once this long has
+	// passed since FailingSince, even with attempts left. RetryForDuration
+	// keeps retrying it until then past MaxRetries.
+	MaxRetryDurationMs int64 `json:"max_retry_duration_ms,omitempty"`
+	RetryForDuration   bool  `json:"retry_for_duration,omitempty"`
+	// FailingSince is when the job's first attempt failed, in Unix
+	// milliseconds; 0 until one has.
+	FailingSince int64 `json:"failing_since,omitempty"`
+	// SuccessBodyContains and SuccessJSONPath are the subscriber's success
+	// condition, which a 2xx response must also meet.
+	SuccessBodyContains string `json:"success_body_contains,omitempty"`
+	SuccessJSONPath     string `json:"success_json_path,omitempty"`
+	// RedirectPolicy is which redirects the job's requests follow. Empty
+	// means the deliverer's default.
+	RedirectPolicy string `json:"redirect_policy,omitempty"`
+	// EndpointType is what EndpointURL points at, with the credentials
+	// deliveries to it use. Empty means domain.EndpointHTTP.
+	EndpointType        string                      `json:"endpoint_type,omitempty"`
+	EndpointCredentials *domain.EndpointCredentials `json:"endpoint_credentials,omitempty"`
+	// EventVersion is the payload version the event was published in.
+	// PinnedVersion, if set, is the version the subscriber takes it in.
+	EventVersion  int `json:"event_version,omitempty"`
+	PinnedVersion int `json:"pinned_version,omitempty"`
+	// Sandbox records the job's deliveries as simulated instead of
+	// sending them.
+	Sandbox bool `json:"sandbox,omitempty"`
+	// DailyQuota and MonthlyQuota are the subscriber's delivery quotas,
+	// enforced by DeliveryQuota.
+	DailyQuota   int `json:"daily_quota,omitempty"`
+	MonthlyQuota int `json:"monthly_quota,omitempty"`
+	// DebugLogging logs the job's deliveries in full, bypassing sampling.
+	DebugLogging bool `json:"debug_logging,omitempty"`
+	// Panics counts the times handling the job has panicked. Jobs that keep
+	// panicking are dead-lettered rather than retried forever.
+	Panics int `json:"panics,omitempty"`
+	// Probe marks the job that tests a subscriber's endpoint once its open
+	// circuit breaker cools down. Queues claim due probes ahead of every
+	// other job, so recovery isn't detected late behind a backlog.
+	Probe bool `json:"probe,omitempty"`
+
+	// Claim is the raw queue member this job was claimed as, used to
+	// acknowledge it once delivery finishes. Never serialized.
+	Claim string `json:"-"`
+	// DeliveryID identifies the attempt being made. Each attempt gets a new
+	// one, so it is never serialized.
+	DeliveryID string `json:"-"`
+	// PayloadBytes is the size of the payload the attempt sends, once it is
+	// resolved, for usage metering.
+	PayloadBytes int `json:"-"`
+	// FinalURL is where the attempt's response came from, once it has
+	// followed redirects away from EndpointURL.
+	FinalURL string `json:"-"`
+}
+
+// SuccessCondition returns what the job's 2xx responses must hold.
+func (j DeliveryJob) SuccessCondition() domain.SuccessCondition {
+	return domain.SuccessCondition{BodyContains: j.SuccessBodyContains, JSONPath: j.SuccessJSONPath}
+}
+
+// RetryDeadline returns when the job stops being retried, or false if only
+// MaxRetries limits its retries.
+func (j DeliveryJob) RetryDeadline() (time.Time, bool) {
+	if j.MaxRetryDurationMs <= 0 || j.FailingSince == 0 {
+		return time.Time{}, false
+	}
+	return time.UnixMilli(j.FailingSince + j.MaxRetryDurationMs), true
+}
+
+// FanOutStore is what the fan-out engine needs from the database: events to
+// persist, subscribers to match them against, the outbox to clear, and where
+// to record which deliveries were queued.
+type FanOutStore interface {
+	store.EventStore
+	store.SubscriberStore
+	store.OutboxStore
+	store.FanOutStatusStore
+}
+
+// FanOutEngine distributes events to matching subscribers via the delivery
+// queue.
 type FanOutEngine struct {
-	pgStore    *store.PostgresStore
+	store      FanOutStore
+	queue      Queue
 	redisStore *store.RedisStore
 	logger     *slog.Logger
+
+	maxAttempts int
+	receipts    *DeliveryReceipts
+	clock       Clock
+
+	maxPayloadBytes  atomic.Int64
+	objects          ObjectStore
+	offloadPrefix    string
+	offloadThreshold int
 }
 
-func NewFanOutEngine(pg *store.PostgresStore, rs *store.RedisStore, logger *slog.Logger) *FanOutEngine {
+func NewFanOutEngine(s FanOutStore, queue Queue, rs *store.RedisStore, logger *slog.Logger) *FanOutEngine {
 	return &FanOutEngine{
-		pgStore:    pg,
+		store:      s,
+		queue:      queue,
 		redisStore: rs,
 		logger:     logger,
+
+		maxAttempts: 5,
+		clock:       SystemClock{},
 	}
 }
 
-// FanOut finds all matching subscribers for an event and queues delivery jobs.
-// Returns the number of deliveries queued.
-func (f *FanOutEngine) FanOut(ctx context.Context, event *domain.Event) (int, error) {
-	subscribers, err := f.pgStore.FindMatchingSubscribers(ctx, event.EventType)
+// SetMaxAttempts sets how many times new deliveries are attempted before
+// they are dead-lettered. Call it before events are published.
+func (f *FanOutEngine) SetMaxAttempts(n int) {
+	f.maxAttempts = n
+}
+
+// SetReceipts sets the delivery receipts that Redeliver clears, so replays
+// are sent even to subscribers that already received the event.
+func (f *FanOutEngine) SetReceipts(r *DeliveryReceipts) {
+	f.receipts = r
+}
+
+// SetClock sets the clock that decides which queued jobs InspectQueue and
+// PurgeJobs count as ready, normally the one the dispatcher claims by.
+func (f *FanOutEngine) SetClock(c Clock) {
+	f.clock = c
+}
+
+// PublishResult describes an event accepted by Publish.
+type PublishResult struct {
+	Event            *domain.Event
+	DeliveriesQueued int
+	// FanOutPending is set when inline fan-out failed and was left to the
+	// outbox relay.
+	FanOutPending bool
+	// FailedSubscribers lists the subscribers whose deliveries could not be
+	// queued when fan-out only partly failed. The repair job retries them.
+	FailedSubscribers []string
+}
+
+// UnmatchedSubscribersError is returned by Publish when an event is targeted
+// at subscribers that are not active subscribers of its type.
+type UnmatchedSubscribersError struct {
+	EventType     string
+	SubscriberIDs []string
+}
+
+func (e *UnmatchedSubscribersError) Error() string {
+	return fmt.Sprintf("subscribers not subscribed to %s: %s", e.EventType, strings.Join(e.SubscriberIDs, ", "))
+}
+
+// Publish stores an event and fans it out. Once it returns without error the
+// event is durable: if the inline fan-out fails, the outbox entry written
+// with the event makes the relay retry it. Every ingestion path goes through
+// here so they share the same delivery guarantees.
+//
+// A non-empty subscriberIDs delivers the event to only those subscribers,
+// for targeted resends and phased rollouts of new event types. Each of them
+// must subscribe to the event type, otherwise nothing is stored and an
+// *UnmatchedSubscribersError is returned.
+//
+// version is the version of the event type's payload; versions below 1 are
+// taken as 1, the version of events published before versioning.
+//
+// A payload over the limit set with SetMaxPayloadBytes is refused with a
+// *PayloadTooLargeError. One over the offload threshold is uploaded to
+// object storage before the event is stored.
+func (f *FanOutEngine) Publish(ctx context.Context, eventType string, version int, payload []byte, source string, subscriberIDs []string) (*PublishResult, error) {
+	if err := f.CheckPayloadSize(payload); err != nil {
+		return nil, err
+	}
+	if version < 1 {
+		version = 1
+	}
+	subscriberIDs = uniqueIDs(subscriberIDs)
+	if len(subscriberIDs) > 0 {
+		if err := f.checkTargets(ctx, eventType, subscriberIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	payload, ref, err := f.offloadPayload(ctx, payload)
 	if err != nil {
-		return 0, fmt.Errorf("finding matching subscribers: %w", err)
+		return nil, err
+	}
+	event, err := f.store.CreateEvent(ctx, eventType, version, payload, ref, source, subscriberIDs)
+	if err != nil {
+		return nil, err
 	}
 
-	if len(subscribers) == 0 {
-		f.logger.Info("no matching subscribers", "event_id", event.ID, "event_type", event.EventType)
-		return 0, nil
+	result, err := f.FanOut(ctx, event)
+	if err != nil {
+		f.logger.Warn("inline fan-out failed, leaving it to the outbox relay",
+			"event_id", event.ID,
+			"deliveries_queued", result.Queued,
+			"error", err,
+		)
+		return &PublishResult{
+			Event:             event,
+			DeliveriesQueued:  result.Queued,
+			FanOutPending:     true,
+			FailedSubscribers: result.Failed,
+		}, nil
+	}
+
+	// Clear the outbox entry so the relay skips it. If this fails the relay
+	// fans out again (at-least-once).
+	if err := f.store.CompleteOutboxEntry(ctx, event.ID); err != nil {
+		f.logger.Error("failed to complete outbox entry, the relay will fan out again", "error", err, "event_id", event.ID)
 	}
 
-	// Use Redis pipeline to batch-insert all delivery jobs
-	pipe := f.redisStore.Client().Pipeline()
+	return &PublishResult{Event: event, DeliveriesQueued: result.Queued, FailedSubscribers: result.Failed}, nil
+}
 
+// checkTargets returns an *UnmatchedSubscribersError listing the subscribers
+// in ids that would not receive an event of eventType.
+func (f *FanOutEngine) checkTargets(ctx context.Context, eventType string, ids []string) error {
+	subscribers, err := f.store.FindMatchingSubscribers(ctx, eventType)
+	if err != nil {
+		return fmt.Errorf("finding matching subscribers: %w", err)
+	}
+	matched := make(map[string]bool, len(subscribers))
 	for _, sub := range subscribers {
-		job := DeliveryJob{
-			EventID:            event.ID,
-			SubscriberID:       sub.ID,
-			EndpointURL:        sub.EndpointURL,
-			Payload:            event.Payload,
-			SecretKey:          sub.SecretKey,
-			EventType:          event.EventType,
-			Attempt:            1,
-			MaxRetries:         5,
-			RateLimitPerSecond: sub.RateLimitPerSecond,
+		matched[sub.ID] = true
+	}
+
+	var unmatched []string
+	for _, id := range ids {
+		if !matched[id] {
+			unmatched = append(unmatched, id)
+		}
+	}
+	if len(unmatched) > 0 {
+		return &UnmatchedSubscribersError{EventType: eventType, SubscriberIDs: unmatched}
+	}
+	return nil
+}
+
+// uniqueIDs drops empty and repeated IDs, keeping the first occurrence of
+// each. It returns nil when no IDs remain.
+func uniqueIDs(ids []string) []string {
+	var unique []string
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
 		}
+	}
+	return unique
+}
 
-		jobBytes, err := json.Marshal(job)
-		if err != nil {
-			f.logger.Error("failed to marshal job", "error", err, "subscriber_id", sub.ID)
-			continue
+// onlySubscribers returns the subscribers whose ID is in ids.
+func onlySubscribers(subscribers []domain.Subscriber, ids []string) []domain.Subscriber {
+	want := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		want[id] = true
+	}
+	var kept []domain.Subscriber
+	for _, sub := range subscribers {
+		if want[sub.ID] {
+			kept = append(kept, sub)
 		}
+	}
+	return kept
+}
 
-		pipe.ZAdd(ctx, DeliveryQueueKey, redis.Z{
-			Score:  float64(time.Now().UnixMicro()),
-			Member: string(jobBytes),
-		})
+// systemSubscribers returns the subscribers flagged as system subscribers.
+func systemSubscribers(subscribers []domain.Subscriber) []domain.Subscriber {
+	var kept []domain.Subscriber
+	for _, sub := range subscribers {
+		if sub.IsSystem {
+			kept = append(kept, sub)
+		}
 	}
+	return kept
+}
 
-	_, err = pipe.Exec(ctx)
+// PublishSystemEvent publishes an event about the delivery system itself,
+// of one of the domain system event types, with data as its payload. The
+// event is only stored when a system subscriber subscribes to its type.
+// System events are best effort: failures are logged, never returned, so
+// they can't fail the operation they describe.
+func (f *FanOutEngine) PublishSystemEvent(ctx context.Context, eventType string, data any) {
+	// The operation being described may be on a request that is about to
+	// finish; the event should still go out
+	ctx = context.WithoutCancel(ctx)
+
+	subscribers, err := f.store.FindMatchingSubscribers(ctx, eventType)
 	if err != nil {
-		return 0, fmt.Errorf("queuing deliveries to redis: %w", err)
+		f.logger.Error("failed to find system subscribers", "event_type", eventType, "error", err)
+		return
+	}
+	if len(systemSubscribers(subscribers)) == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		f.logger.Error("failed to encode system event", "event_type", eventType, "error", err)
+		return
+	}
+	if _, err := f.Publish(ctx, eventType, 1, payload, domain.SystemEventSource, nil); err != nil {
+		f.logger.Error("failed to publish system event", "event_type", eventType, "error", err)
+	}
+}
+
+// FanOutResult describes the deliveries queued by FanOut.
+type FanOutResult struct {
+	Queued int
+	// Failed lists the subscribers whose deliveries could not be queued.
+	Failed []string
+}
+
+// FanOut finds all matching subscribers for an event, narrowed to the
+// event's SubscriberIDs when it has any, and queues delivery jobs,
+// recording for each subscriber whether its job was queued. Jobs that
+// could not be queued are left to the FanOutRepairer, so FanOut only fails
+// when nothing was queued or when the failures could not be recorded; the
+// result then still reports what was queued.
+func (f *FanOutEngine) FanOut(ctx context.Context, event *domain.Event) (FanOutResult, error) {
+	subscribers, err := f.store.FindMatchingSubscribers(ctx, event.EventType)
+	if err != nil {
+		return FanOutResult{}, fmt.Errorf("finding matching subscribers: %w", err)
+	}
+	if len(event.SubscriberIDs) > 0 {
+		subscribers = onlySubscribers(subscribers, event.SubscriberIDs)
+	}
+	if domain.IsSystemEventType(event.EventType) {
+		subscribers = systemSubscribers(subscribers)
+	}
+
+	if len(subscribers) == 0 {
+		f.logger.Info("no matching subscribers", "event_id", event.ID, "event_type", event.EventType)
+		return FanOutResult{}, nil
+	}
+
+	// Store the payload once and queue all delivery jobs in one batch
+	jobs := make([]DeliveryJob, len(subscribers))
+	for i := range subscribers {
+		jobs[i] = newDeliveryJob(event, &subscribers[i], f.maxAttempts)
+	}
+	err = f.queue.Publish(ctx, event.ID, event.Payload, jobs)
+	var perr *PublishError
+	if err != nil && !errors.As(err, &perr) {
+		return FanOutResult{}, err
+	}
+
+	result := FanOutResult{Queued: len(jobs)}
+	if perr != nil {
+		result.Queued -= len(perr.Failed)
+		result.Failed = perr.Failed
+	}
+	statuses := fanOutStatuses(event.ID, jobs, perr, nil, time.Now())
+	if serr := f.store.SaveFanOutStatuses(ctx, statuses); serr != nil {
+		if perr != nil {
+			// Without a record of who was missed, the outbox relay has to
+			// fan out to everyone again
+			return result, fmt.Errorf("%w; recording fan-out status: %v", err, serr)
+		}
+		f.logger.Warn("failed to record fan-out status", "event_id", event.ID, "error", serr)
+	}
+
+	if perr != nil {
+		f.logger.Warn("fan-out partly failed, leaving the rest to the repair job",
+			"event_id", event.ID,
+			"event_type", event.EventType,
+			"deliveries_queued", result.Queued,
+			"deliveries_failed", len(result.Failed),
+			"error", perr.Err,
+		)
+		return result, nil
 	}
 
 	f.logger.Info("fan-out complete",
 		"event_id", event.ID,
 		"event_type", event.EventType,
-		"deliveries_queued", len(subscribers),
+		"deliveries_queued", result.Queued,
+	)
+
+	return result, nil
+}
+
+// fanOutStatuses records the outcome of queuing jobs. prevAttempts holds how
+// many times each subscriber's job was tried before; nil means never. Failed
+// jobs are due for repair after fanOutRepairDelay.
+func fanOutStatuses(eventID string, jobs []DeliveryJob, perr *PublishError, prevAttempts map[string]int, now time.Time) []domain.FanOutStatus {
+	failed := make(map[string]bool)
+	var errMsg string
+	if perr != nil {
+		errMsg = perr.Err.Error()
+		for _, id := range perr.Failed {
+			failed[id] = true
+		}
+	}
+
+	statuses := make([]domain.FanOutStatus, len(jobs))
+	for i, job := range jobs {
+		attempt := prevAttempts[job.SubscriberID] + 1
+		statuses[i] = domain.FanOutStatus{
+			EventID:      eventID,
+			SubscriberID: job.SubscriberID,
+			Status:       domain.FanOutQueued,
+			Attempts:     attempt,
+			UpdatedAt:    now,
+		}
+		if failed[job.SubscriberID] {
+			repairAt := now.Add(fanOutRepairDelay(attempt))
+			statuses[i].Status = domain.FanOutFailed
+			statuses[i].Error = &errMsg
+			statuses[i].NextRepairAt = &repairAt
+		}
+	}
+	return statuses
+}
+
+// Redeliver queues a fresh delivery of an event to a single subscriber,
+// starting again from the first attempt. It is used to replay dead letters.
+func (f *FanOutEngine) Redeliver(ctx context.Context, event *domain.Event, sub *domain.Subscriber) error {
+	if err := f.receipts.Clear(ctx, event.ID, sub.ID); err != nil {
+		return err
+	}
+	if err := f.queue.Publish(ctx, event.ID, event.Payload, []DeliveryJob{newDeliveryJob(event, sub, f.maxAttempts)}); err != nil {
+		return fmt.Errorf("queuing redelivery: %w", err)
+	}
+
+	f.logger.Info("redelivery queued",
+		"event_id", event.ID,
+		"subscriber_id", sub.ID,
 	)
+	return nil
+}
+
+// Ping stores a test event of domain.PingEventType and queues its delivery
+// to sub alone, so subscribers can check their endpoint receives and
+// verifies webhooks.
+func (f *FanOutEngine) Ping(ctx context.Context, sub *domain.Subscriber, source string) (*domain.Event, error) {
+	payload, err := json.Marshal(map[string]string{
+		"subscriber_id": sub.ID,
+		"message":       "Test event sent from the webhook portal",
+	})
+	if err != nil {
+		return nil, err
+	}
+	event, err := f.store.CreateEvent(ctx, domain.PingEventType, 1, payload, nil, source, []string{sub.ID})
+	if err != nil {
+		return nil, err
+	}
+	if err := f.Redeliver(ctx, event, sub); err != nil {
+		return nil, err
+	}
 
-	return len(subscribers), nil
+	// Fanning the event out would match nothing unless sub happens to
+	// subscribe to pings, so the outbox relay has nothing left to do
+	if err := f.store.CompleteOutboxEntry(ctx, event.ID); err != nil {
+		f.logger.Error("failed to complete outbox entry", "error", err, "event_id", event.ID)
+	}
+	return event, nil
+}
+
+// newDeliveryJob builds the first delivery attempt of an event to a subscriber.
+func newDeliveryJob(event *domain.Event, sub *domain.Subscriber, maxAttempts int) DeliveryJob {
+	return DeliveryJob{
+		EventID:             event.ID,
+		SubscriberID:        sub.ID,
+		EndpointURL:         sub.EndpointURL,
+		PayloadRef:          event.PayloadRef,
+		SecretKey:           sub.SecretKey,
+		EventType:           event.EventType,
+		EventVersion:        event.Version,
+		PinnedVersion:       sub.EventVersions[event.EventType],
+		Sandbox:             sub.Sandbox,
+		DailyQuota:          sub.DailyQuota,
+		MonthlyQuota:        sub.MonthlyQuota,
+		Attempt:             1,
+		MaxRetries:          maxAttempts,
+		RateLimitPerSecond:  sub.RateLimitPerSecond,
+		CompressPayload:     sub.CompressPayloads,
+		DiscardResponse:     sub.DiscardResponseBodies,
+		BatchMaxEvents:      sub.BatchMaxEvents,
+		BatchWindowMs:       sub.BatchWindowSeconds * 1000,
+		ProxyURL:            sub.ProxyURL,
+		SignatureFormat:     sub.SignatureFormat,
+		RetryJitter:         sub.RetryJitter,
+		MaxRetryDurationMs:  int64(sub.MaxRetryDurationSeconds) * 1000,
+		RetryForDuration:    sub.RetryForDuration,
+		SuccessBodyContains: sub.SuccessBodyContains,
+		SuccessJSONPath:     sub.SuccessJSONPath,
+		RedirectPolicy:      sub.RedirectPolicy,
+		EndpointType:        sub.EndpointType,
+		EndpointCredentials: sub.EndpointCredentials,
+		DebugLogging:        sub.DebugLogging,
+	}
 }
 
 // QueueDepth returns the current number of jobs waiting in the delivery queue.
 func (f *FanOutEngine) QueueDepth(ctx context.Context) (int64, error) {
-	return f.redisStore.Client().ZCard(ctx, DeliveryQueueKey).Result()
+	return f.queue.Depth(ctx)
+}
+
+// PauseSubscriber holds deliveries to a subscriber until ResumeSubscriber is
+// called, or until the given time if it is non-zero. New fan-outs keep
+// queueing jobs for it; workers park them instead of sending them.
+func (f *FanOutEngine) PauseSubscriber(ctx context.Context, subscriberID string, until time.Time) error {
+	return f.queue.Pause(ctx, subscriberID, until)
+}
+
+// ResumeSubscriber lifts a pause and queues the jobs parked during it.
+func (f *FanOutEngine) ResumeSubscriber(ctx context.Context, subscriberID string) (int64, error) {
+	return f.queue.Resume(ctx, subscriberID)
+}
+
+// SubscriberPause reports whether a subscriber is paused and how many of its
+// jobs are parked.
+func (f *FanOutEngine) SubscriberPause(ctx context.Context, subscriberID string) (PauseStatus, error) {
+	return f.queue.PauseStatus(ctx, subscriberID)
+}
+
+// PendingJobs returns the jobs waiting to be delivered to a subscriber.
+func (f *FanOutEngine) PendingJobs(ctx context.Context, subscriberID string) ([]PendingJob, error) {
+	return f.queue.PendingJobs(ctx, subscriberID)
+}
+
+// PurgePending drops every job waiting to be delivered to a subscriber.
+func (f *FanOutEngine) PurgePending(ctx context.Context, subscriberID string) (int64, error) {
+	return f.queue.PurgePending(ctx, subscriberID)
+}
+
+// InspectQueue counts the jobs waiting in the delivery queue.
+func (f *FanOutEngine) InspectQueue(ctx context.Context) (QueueStats, error) {
+	return f.queue.Inspect(ctx, f.clock.Now())
+}
+
+// PurgeJobs drops the queued jobs matching filter.
+func (f *FanOutEngine) PurgeJobs(ctx context.Context, filter JobFilter) (int64, error) {
+	return f.queue.PurgeJobs(ctx, filter, f.clock.Now())
+}
+
+// QueueDepthSamples returns recorded queue depth samples taken since the given time.
+func (f *FanOutEngine) QueueDepthSamples(ctx context.Context, since time.Time) ([]QueueDepthSample, error) {
+	return QueueDepthSamples(ctx, f.redisStore.Client(), since)
 }
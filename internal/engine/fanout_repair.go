@@ -0,0 +1,145 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+)
+
+// maxFanOutRepairDelay caps the backoff between repairs of a delivery.
+const maxFanOutRepairDelay = 5 * time.Minute
+
+// fanOutRepairDelay returns how long to wait before repairing a delivery
+// that has failed to queue attempts times.
+func fanOutRepairDelay(attempts int) time.Duration {
+	delay := time.Duration(math.Pow(2, float64(attempts))) * time.Second
+	if delay > maxFanOutRepairDelay || delay <= 0 {
+		return maxFanOutRepairDelay
+	}
+	return delay
+}
+
+// FanOutRepairer queues the deliveries a fan-out failed to queue. FanOut
+// records them as failed in the fan-out status; the repairer retries them
+// with backoff until they are queued, or skips them once their subscriber has
+// been deactivated. Like the outbox relay it is at-least-once: replicas
+// repairing the same delivery at once can queue it twice.
+type FanOutRepairer struct {
+	fanout       *FanOutEngine
+	logger       *slog.Logger
+	pollInterval time.Duration
+	batchSize    int
+}
+
+func NewFanOutRepairer(fanout *FanOutEngine, logger *slog.Logger) *FanOutRepairer {
+	return &FanOutRepairer{
+		fanout:       fanout,
+		logger:       logger,
+		pollInterval: 5 * time.Second,
+		batchSize:    500,
+	}
+}
+
+// Start runs the repair loop until the context is cancelled.
+func (r *FanOutRepairer) Start(ctx context.Context) {
+	r.logger.Info("fan-out repairer started")
+
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			r.logger.Info("fan-out repairer stopping")
+			return
+		case <-ticker.C:
+			r.repair(ctx)
+		}
+	}
+}
+
+// repair retries one batch of failed deliveries whose repair is due, one
+// queue publish per event.
+func (r *FanOutRepairer) repair(ctx context.Context) {
+	due, err := r.fanout.store.ListFanOutRepairs(ctx, r.batchSize)
+	if err != nil {
+		r.logger.Error("failed to read fan-out repairs", "error", err)
+		return
+	}
+
+	var order []string
+	byEvent := make(map[string][]domain.FanOutStatus)
+	for _, st := range due {
+		if _, ok := byEvent[st.EventID]; !ok {
+			order = append(order, st.EventID)
+		}
+		byEvent[st.EventID] = append(byEvent[st.EventID], st)
+	}
+	for _, eventID := range order {
+		if err := r.repairEvent(ctx, eventID, byEvent[eventID]); err != nil {
+			r.logger.Error("failed to repair fan-out", "event_id", eventID, "error", err)
+		}
+	}
+}
+
+func (r *FanOutRepairer) repairEvent(ctx context.Context, eventID string, failed []domain.FanOutStatus) error {
+	f := r.fanout
+	event, err := f.store.GetEvent(ctx, eventID)
+	if err != nil || event == nil {
+		return err // a pruned event takes its statuses with it
+	}
+
+	now := time.Now()
+	prevAttempts := make(map[string]int, len(failed))
+	var jobs []DeliveryJob
+	var skipped []domain.FanOutStatus
+	for _, st := range failed {
+		prevAttempts[st.SubscriberID] = st.Attempts
+		sub, err := f.store.GetSubscriber(ctx, st.SubscriberID)
+		if err != nil {
+			return err
+		}
+		if sub == nil || !sub.IsActive || sub.Deleted() {
+			reason := "subscriber is inactive"
+			st.Status = domain.FanOutSkipped
+			st.Error = &reason
+			st.NextRepairAt = nil
+			st.UpdatedAt = now
+			skipped = append(skipped, st)
+			continue
+		}
+		jobs = append(jobs, newDeliveryJob(event, sub, f.maxAttempts))
+	}
+
+	var perr *PublishError
+	if len(jobs) > 0 {
+		err := f.queue.Publish(ctx, event.ID, event.Payload, jobs)
+		if err != nil && !errors.As(err, &perr) {
+			perr = &PublishError{Total: len(jobs), Err: err}
+			for _, job := range jobs {
+				perr.Failed = append(perr.Failed, job.SubscriberID)
+			}
+		}
+	}
+
+	statuses := append(fanOutStatuses(event.ID, jobs, perr, prevAttempts, now), skipped...)
+	if err := f.store.SaveFanOutStatuses(ctx, statuses); err != nil {
+		return err
+	}
+
+	queued := len(jobs)
+	if perr != nil {
+		queued -= len(perr.Failed)
+	}
+	r.logger.Info("fan-out repaired",
+		"event_id", event.ID,
+		"deliveries_queued", queued,
+		"deliveries_skipped", len(skipped),
+		"deliveries_failed", len(jobs)-queued,
+	)
+	return nil
+}
@@ -3,8 +3,17 @@ package engine
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
 	"github.com/alicebob/miniredis/v2"
 	"github.com/redis/go-redis/v9"
 )
@@ -132,3 +141,354 @@ func TestDeliveryQueueKey_Constant(t *testing.T) {
 		t.Errorf("expected DeliveryQueueKey = %q, got %q", "delivery_queue", DeliveryQueueKey)
 	}
 }
+
+func TestRedeliver_QueuesFirstAttemptForOneSubscriber(t *testing.T) {
+	mr := miniredis.RunT(t)
+	ctx := context.Background()
+	rs, err := store.NewRedis(ctx, "redis://"+mr.Addr())
+	if err != nil {
+		t.Fatalf("connecting to redis: %v", err)
+	}
+	t.Cleanup(func() { rs.Close() })
+
+	f := NewFanOutEngine(nil, NewRedisQueue(rs.Client(), nil), rs, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	event := &domain.Event{ID: "evt-1", EventType: "order.created", Payload: json.RawMessage(`{"id":1}`)}
+	sub := &domain.Subscriber{ID: "sub-1", EndpointURL: "http://example.com/hook", SecretKey: "s", RateLimitPerSecond: 7}
+
+	if err := f.Redeliver(ctx, event, sub); err != nil {
+		t.Fatalf("Redeliver failed: %v", err)
+	}
+
+	members, err := rs.Client().ZRange(ctx, DeliveryQueueKey, 0, -1).Result()
+	if err != nil || len(members) != 1 {
+		t.Fatalf("expected one queued job, got %v (err %v)", members, err)
+	}
+	var job DeliveryJob
+	if err := json.Unmarshal([]byte(members[0]), &job); err != nil {
+		t.Fatalf("queued job is not valid JSON: %v", err)
+	}
+	if job.SubscriberID != "sub-1" || job.Attempt != 1 || job.RateLimitPerSecond != 7 {
+		t.Errorf("unexpected job: %+v", job)
+	}
+
+	payload, err := rs.Client().Get(ctx, PayloadKey("evt-1")).Result()
+	if err != nil || payload != `{"id":1}` {
+		t.Errorf("payload = %q (err %v), want the event payload", payload, err)
+	}
+}
+
+// outboxMemoryStore adds a no-op outbox to the memory store.
+type outboxMemoryStore struct {
+	*store.MemoryStore
+	completed []string
+}
+
+func (s *outboxMemoryStore) ClaimPendingOutbox(ctx context.Context, limit int, lease time.Duration) ([]store.OutboxEntry, error) {
+	return nil, nil
+}
+
+func (s *outboxMemoryStore) CompleteOutboxEntry(ctx context.Context, eventID string) error {
+	s.completed = append(s.completed, eventID)
+	return nil
+}
+
+func (s *outboxMemoryStore) FailOutboxEntry(ctx context.Context, eventID string, errMsg string, retryAt time.Time) error {
+	return nil
+}
+
+func (s *outboxMemoryStore) OutboxEntryPending(ctx context.Context, eventID string) (bool, error) {
+	return false, nil
+}
+
+func TestPublish_ReportsPartialFanOut(t *testing.T) {
+	ctx := context.Background()
+	s := &outboxMemoryStore{MemoryStore: store.NewMemoryStore()}
+	var subs []*domain.Subscriber
+	for i := 0; i < 5; i++ {
+		sub, err := s.CreateSubscriber(ctx, domain.CreateSubscriberRequest{
+			Name: fmt.Sprintf("sub-%d", i), EndpointURL: "http://example.com/hook", EventTypes: []string{"order.created"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		subs = append(subs, sub)
+	}
+
+	client := setupTestQueue(t)
+	hook := &failChunkHook{subscriberID: subs[0].ID}
+	client.AddHook(hook)
+	queue := NewRedisQueue(client, nil)
+	queue.SetPublishChunking(2, 2)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	f := NewFanOutEngine(s, queue, nil, logger)
+
+	result, err := f.Publish(ctx, "order.created", 1, []byte(`{}`), "", nil)
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if result.FanOutPending || len(result.FailedSubscribers) != 2 || !slices.Contains(result.FailedSubscribers, subs[0].ID) {
+		t.Errorf("expected the chunk with %s to be reported, got %+v", subs[0].ID, result)
+	}
+	if result.DeliveriesQueued != 3 {
+		t.Errorf("expected 3 deliveries queued, got %d", result.DeliveriesQueued)
+	}
+	if len(s.completed) != 1 {
+		t.Error("expected the outbox entry to be completed once the failures were recorded")
+	}
+
+	statuses, _ := s.ListFanOutStatuses(ctx, result.Event.ID)
+	if len(statuses) != 5 || statuses[0].Status != domain.FanOutFailed || statuses[1].Status != domain.FanOutFailed || statuses[2].Status != domain.FanOutQueued {
+		t.Fatalf("unexpected statuses %+v", statuses)
+	}
+	if statuses[0].NextRepairAt == nil || statuses[0].Error == nil || statuses[0].Attempts != 1 {
+		t.Errorf("expected a failed status to be scheduled for repair, got %+v", statuses[0])
+	}
+
+	// Once Redis recovers, the repairer queues the missed deliveries and
+	// skips those of subscribers deactivated in the meantime
+	hook.healed.Store(true)
+	inactive := false
+	other := statuses[0].SubscriberID
+	if other == subs[0].ID {
+		other = statuses[1].SubscriberID
+	}
+	s.UpdateSubscriber(ctx, other, domain.UpdateSubscriberRequest{IsActive: &inactive})
+	past := time.Now().Add(-time.Second)
+	for i := range statuses[:2] {
+		statuses[i].NextRepairAt = &past
+	}
+	s.SaveFanOutStatuses(ctx, statuses[:2])
+
+	NewFanOutRepairer(f, logger).repair(ctx)
+
+	statuses, _ = s.ListFanOutStatuses(ctx, result.Event.ID)
+	byID := make(map[string]domain.FanOutStatus)
+	for _, st := range statuses {
+		byID[st.SubscriberID] = st
+	}
+	if st := byID[subs[0].ID]; st.Status != domain.FanOutQueued || st.Attempts != 2 || st.NextRepairAt != nil {
+		t.Errorf("expected the repair to queue %s, got %+v", subs[0].ID, st)
+	}
+	if st := byID[other]; st.Status != domain.FanOutSkipped {
+		t.Errorf("expected the inactive subscriber to be skipped, got %+v", st)
+	}
+	if depth := client.ZCard(ctx, DeliveryQueueKey).Val(); depth != 4 {
+		t.Errorf("expected 4 queued jobs, got %d", depth)
+	}
+	if due, _ := s.ListFanOutRepairs(ctx, 10); len(due) != 0 {
+		t.Errorf("expected no repairs left, got %+v", due)
+	}
+}
+
+func TestFanOutRepairDelay(t *testing.T) {
+	if d := fanOutRepairDelay(1); d != 2*time.Second {
+		t.Errorf("first repair delay = %s, want 2s", d)
+	}
+	if d := fanOutRepairDelay(100); d != maxFanOutRepairDelay {
+		t.Errorf("delay after many attempts = %s, want the cap", d)
+	}
+}
+
+func TestPublish_TargetsSubscribers(t *testing.T) {
+	ctx := context.Background()
+	s := &outboxMemoryStore{MemoryStore: store.NewMemoryStore()}
+	var subs []*domain.Subscriber
+	for i, eventType := range []string{"order.created", "order.created", "order.shipped"} {
+		sub, err := s.CreateSubscriber(ctx, domain.CreateSubscriberRequest{
+			Name: fmt.Sprintf("sub-%d", i), EndpointURL: "http://example.com/hook", EventTypes: []string{eventType},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		subs = append(subs, sub)
+	}
+
+	client := setupTestQueue(t)
+	f := NewFanOutEngine(s, NewRedisQueue(client, nil), nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	// Subscribers must subscribe to the event type
+	_, err := f.Publish(ctx, "order.created", 1, []byte(`{}`), "", []string{subs[0].ID, subs[2].ID})
+	var unmatched *UnmatchedSubscribersError
+	if !errors.As(err, &unmatched) || !slices.Equal(unmatched.SubscriberIDs, []string{subs[2].ID}) {
+		t.Fatalf("expected %s to be rejected, got %v", subs[2].ID, err)
+	}
+	if events, _ := s.ListEvents(ctx, "", 0); len(events) != 0 {
+		t.Errorf("expected a rejected event not to be stored, got %d", len(events))
+	}
+
+	result, err := f.Publish(ctx, "order.created", 1, []byte(`{}`), "", []string{subs[1].ID, subs[1].ID})
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if result.DeliveriesQueued != 1 || !slices.Equal(result.Event.SubscriberIDs, []string{subs[1].ID}) {
+		t.Errorf("expected one delivery to %s, got %+v", subs[1].ID, result)
+	}
+
+	// The outbox relay honours the targets stored with the event
+	event, _ := s.GetEvent(ctx, result.Event.ID)
+	if fanned, err := f.FanOut(ctx, event); err != nil || fanned.Queued != 1 {
+		t.Errorf("expected the relay to queue one delivery, got %+v (err %v)", fanned, err)
+	}
+	for _, member := range client.ZRange(ctx, DeliveryQueueKey, 0, -1).Val() {
+		var job DeliveryJob
+		json.Unmarshal([]byte(member), &job)
+		if job.SubscriberID != subs[1].ID {
+			t.Errorf("expected only %s to be queued, got %s", subs[1].ID, job.SubscriberID)
+		}
+	}
+}
+
+func TestPublishSystemEvent_OnlyReachesSystemSubscribers(t *testing.T) {
+	ctx := context.Background()
+	s := &outboxMemoryStore{MemoryStore: store.NewMemoryStore()}
+	client := setupTestQueue(t)
+	f := NewFanOutEngine(s, NewRedisQueue(client, nil), nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	// Without a system subscriber nothing is stored, even for a regular
+	// subscriber to the type
+	if _, err := s.CreateSubscriber(ctx, domain.CreateSubscriberRequest{
+		Name: "regular", EndpointURL: "http://example.com/hook", EventTypes: []string{domain.EventDeliveryDeadLettered},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	f.PublishSystemEvent(ctx, domain.EventDeliveryDeadLettered, domain.DeadLetteredEvent{EventID: "evt-1"})
+	if events, _ := s.ListEvents(ctx, "", 0); len(events) != 0 {
+		t.Fatalf("expected no event without a system subscriber, got %d", len(events))
+	}
+
+	system, err := s.CreateSubscriber(ctx, domain.CreateSubscriberRequest{
+		Name: "ops", EndpointURL: "http://example.com/ops", EventTypes: []string{domain.EventDeliveryDeadLettered}, IsSystem: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.PublishSystemEvent(ctx, domain.EventDeliveryDeadLettered, domain.DeadLetteredEvent{EventID: "evt-1"})
+
+	events, _ := s.ListEvents(ctx, "", 0)
+	if len(events) != 1 || events[0].Source != domain.SystemEventSource || !strings.Contains(string(events[0].Payload), `"event_id":"evt-1"`) {
+		t.Fatalf("expected one system event, got %+v", events)
+	}
+	members := client.ZRange(ctx, DeliveryQueueKey, 0, -1).Val()
+	if len(members) != 1 {
+		t.Fatalf("expected one queued delivery, got %d", len(members))
+	}
+	var job DeliveryJob
+	json.Unmarshal([]byte(members[0]), &job)
+	if job.SubscriberID != system.ID {
+		t.Errorf("expected the delivery to go to the system subscriber, got %s", job.SubscriberID)
+	}
+}
+
+// memoryObjects is an ObjectStore in memory.
+type memoryObjects map[string][]byte
+
+func (m memoryObjects) PutObject(_ context.Context, key string, body []byte, _ string) error {
+	m[key] = body
+	return nil
+}
+
+func (m memoryObjects) GetObject(_ context.Context, key string) (io.ReadCloser, error) {
+	body, ok := m[key]
+	if !ok {
+		return nil, fmt.Errorf("no object %s", key)
+	}
+	return io.NopCloser(strings.NewReader(string(body))), nil
+}
+
+func TestPublish_PayloadSizes(t *testing.T) {
+	ctx := context.Background()
+	s := &outboxMemoryStore{MemoryStore: store.NewMemoryStore()}
+	if _, err := s.CreateSubscriber(ctx, domain.CreateSubscriberRequest{
+		Name: "sub", EndpointURL: "http://example.com/hook", EventTypes: []string{"file.uploaded"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	client := setupTestQueue(t)
+	f := NewFanOutEngine(s, NewRedisQueue(client, nil), nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	objects := memoryObjects{}
+	f.SetMaxPayloadBytes(64)
+	f.SetPayloadOffload(objects, "archive", 16)
+
+	_, err := f.Publish(ctx, "file.uploaded", 1, []byte(`{"data":"`+strings.Repeat("x", 64)+`"}`), "", nil)
+	var tooLarge *PayloadTooLargeError
+	if !errors.As(err, &tooLarge) || tooLarge.Size != 75 || tooLarge.Limit != 64 {
+		t.Fatalf("expected a 75-byte payload to be refused, got %v", err)
+	}
+	if events, _ := s.ListEvents(ctx, "", 0); len(events) != 0 {
+		t.Errorf("expected a refused event not to be stored, got %d", len(events))
+	}
+
+	// Small payloads stay with the event
+	small, err := f.Publish(ctx, "file.uploaded", 1, []byte(`{"n":1}`), "", nil)
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if small.Event.PayloadRef != nil || string(small.Event.Payload) != `{"n":1}` {
+		t.Errorf("expected the small payload to be stored with the event, got %+v", small.Event)
+	}
+
+	large := []byte(`{"data":"` + strings.Repeat("x", 32) + `"}`)
+	result, err := f.Publish(ctx, "file.uploaded", 1, large, "", nil)
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	ref := result.Event.PayloadRef
+	if ref == nil || ref.Size != int64(len(large)) || !strings.HasPrefix(ref.Key, "archive/payloads/") {
+		t.Fatalf("expected the large payload to be offloaded, got %+v", ref)
+	}
+	if string(objects[ref.Key]) != string(large) || string(result.Event.Payload) != "null" {
+		t.Errorf("expected the payload in object storage only, got %q stored and %q uploaded", result.Event.Payload, objects[ref.Key])
+	}
+
+	// Its deliveries carry the reference
+	var offloaded int
+	for _, member := range client.ZRange(ctx, DeliveryQueueKey, 0, -1).Val() {
+		var job DeliveryJob
+		json.Unmarshal([]byte(member), &job)
+		if job.EventID == result.Event.ID {
+			offloaded++
+			if job.PayloadRef == nil || *job.PayloadRef != *ref {
+				t.Errorf("expected the job to reference %s, got %+v", ref.Key, job.PayloadRef)
+			}
+		}
+	}
+	if offloaded != 1 {
+		t.Errorf("expected one delivery of the offloaded event, got %d", offloaded)
+	}
+}
+
+func BenchmarkFanOut(b *testing.B) {
+	for _, n := range []int{1, 100, 10000} {
+		b.Run(fmt.Sprintf("subscribers=%d", n), func(b *testing.B) {
+			ctx := context.Background()
+			s := &outboxMemoryStore{MemoryStore: store.NewMemoryStore()}
+			for i := 0; i < n; i++ {
+				_, err := s.CreateSubscriber(ctx, domain.CreateSubscriberRequest{
+					Name: fmt.Sprintf("sub-%d", i), EndpointURL: "http://example.com/hook", EventTypes: []string{"order.created"},
+				})
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+			event, err := s.CreateEvent(ctx, "order.created", 1, []byte(`{"order_id":42,"amount":19.99}`), nil, "", nil)
+			if err != nil {
+				b.Fatal(err)
+			}
+			client := setupTestQueue(b)
+			f := NewFanOutEngine(s, NewRedisQueue(client, nil), nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := f.FanOut(ctx, event); err != nil {
+					b.Fatal(err)
+				}
+				// Keep the queue from growing across iterations
+				b.StopTimer()
+				client.FlushAll(ctx)
+				b.StartTimer()
+			}
+			b.ReportMetric(float64(n*b.N)/b.Elapsed().Seconds(), "jobs/s")
+		})
+	}
+}
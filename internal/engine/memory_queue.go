@@ -0,0 +1,497 @@
+package engine
+
+import (
+	"container/heap"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryQueue is a Queue held entirely in process memory: a min-heap of jobs
+// ordered by ready time, plus a map of payloads. It needs no Redis and suits
+// development, tests and single-instance deployments.
+//
+// Nothing survives a restart. Jobs that are queued, scheduled for retry or
+// claimed but not yet delivered are lost when the process exits, and the
+// queue cannot be shared between instances.
+type MemoryQueue struct {
+	mu       sync.Mutex
+	jobs     jobHeap
+	claimed  map[string]time.Time // member -> original ready time
+	probes   map[string]time.Time // probe job member -> ready time
+	payloads map[string]memoryPayload
+	swept    time.Time // last time expired payloads were removed
+	notify   chan struct{}
+
+	paused map[string]time.Time // subscriber -> end of pause, zero if none
+	parked map[string][]string  // subscriber -> parked jobs
+
+	fairnessWindow int64
+}
+
+type memoryPayload struct {
+	data      []byte
+	expiresAt time.Time
+}
+
+var _ Queue = (*MemoryQueue)(nil)
+
+func NewMemoryQueue() *MemoryQueue {
+	return &MemoryQueue{
+		claimed:  make(map[string]time.Time),
+		probes:   make(map[string]time.Time),
+		payloads: make(map[string]memoryPayload),
+		notify:   make(chan struct{}, 1),
+		paused:   make(map[string]time.Time),
+		parked:   make(map[string][]string),
+
+		fairnessWindow: DefaultFairnessWindow,
+	}
+}
+
+// SetFairnessWindow sets how many of the oldest ready jobs Claim considers
+// when sharing a batch out across subscribers, like
+// RedisQueue.SetFairnessWindow.
+func (q *MemoryQueue) SetFairnessWindow(n int64) {
+	q.mu.Lock()
+	q.fairnessWindow = n
+	q.mu.Unlock()
+}
+
+func (q *MemoryQueue) Publish(ctx context.Context, eventID string, payload []byte, jobs []DeliveryJob) error {
+	members := make([]string, len(jobs))
+	for i, job := range jobs {
+		jobBytes, err := json.Marshal(job)
+		if err != nil {
+			return fmt.Errorf("marshaling job: %w", err)
+		}
+		members[i] = string(jobBytes)
+	}
+
+	now := time.Now()
+	q.mu.Lock()
+	q.sweepPayloads(now)
+	q.payloads[eventID] = memoryPayload{data: payload, expiresAt: now.Add(PayloadTTL)}
+	for _, m := range members {
+		heap.Push(&q.jobs, queuedJob{member: m, readyAt: now})
+	}
+	q.mu.Unlock()
+
+	q.wake()
+	return nil
+}
+
+func (q *MemoryQueue) Enqueue(ctx context.Context, job DeliveryJob, at time.Time) error {
+	jobBytes, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("marshaling job: %w", err)
+	}
+
+	q.mu.Lock()
+	heap.Push(&q.jobs, queuedJob{member: string(jobBytes), readyAt: at})
+	if job.Probe {
+		q.probes[string(jobBytes)] = at
+	}
+	q.mu.Unlock()
+
+	q.wake()
+	return nil
+}
+
+func (q *MemoryQueue) Claim(ctx context.Context, now time.Time, limit int64) ([]ClaimedJob, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	claimed := q.claimProbes(now, limit)
+	limit -= int64(len(claimed))
+	if limit <= 0 {
+		return claimed, nil
+	}
+
+	window := max(limit, q.fairnessWindow)
+	var ready []queuedJob
+	for int64(len(ready)) < window && len(q.jobs) > 0 && !q.jobs[0].readyAt.After(now) {
+		ready = append(ready, heap.Pop(&q.jobs).(queuedJob))
+	}
+
+	picked := make([]bool, len(ready))
+	for _, i := range fairOrder(ready, int(limit)) {
+		job := ready[i]
+		picked[i] = true
+		q.claimed[job.member] = job.readyAt
+		claimed = append(claimed, ClaimedJob{Member: job.member, ReadyAt: job.readyAt})
+	}
+	for i, job := range ready {
+		if !picked[i] {
+			heap.Push(&q.jobs, job)
+		}
+	}
+	return claimed, nil
+}
+
+// claimProbes claims up to limit due probe jobs, soonest first, the way
+// claimReadyScript takes them ahead of the rest. Probes no longer in the
+// queue, e.g. because they were purged, are forgotten.
+func (q *MemoryQueue) claimProbes(now time.Time, limit int64) []ClaimedJob {
+	var due []queuedJob
+	for member, readyAt := range q.probes {
+		if !readyAt.After(now) {
+			due = append(due, queuedJob{member: member, readyAt: readyAt})
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].readyAt.Before(due[j].readyAt) })
+
+	var claimed []ClaimedJob
+	for _, probe := range due {
+		if int64(len(claimed)) >= limit {
+			break
+		}
+		delete(q.probes, probe.member)
+		for i, queued := range q.jobs {
+			if queued.member == probe.member {
+				heap.Remove(&q.jobs, i)
+				q.claimed[queued.member] = queued.readyAt
+				claimed = append(claimed, ClaimedJob{Member: queued.member, ReadyAt: queued.readyAt})
+				break
+			}
+		}
+	}
+	return claimed
+}
+
+func (q *MemoryQueue) Ack(ctx context.Context, member string) error {
+	q.mu.Lock()
+	delete(q.claimed, member)
+	q.mu.Unlock()
+	return nil
+}
+
+func (q *MemoryQueue) Return(ctx context.Context, member string) error {
+	q.mu.Lock()
+	readyAt, ok := q.claimed[member]
+	if ok {
+		delete(q.claimed, member)
+		heap.Push(&q.jobs, queuedJob{member: member, readyAt: readyAt})
+		// Claiming a probe dropped it from the index; put it back so it
+		// still goes ahead of the backlog
+		var job DeliveryJob
+		if json.Unmarshal([]byte(member), &job) == nil && job.Probe {
+			q.probes[member] = readyAt
+		}
+	}
+	q.mu.Unlock()
+
+	if ok {
+		q.wake()
+	}
+	return nil
+}
+
+func (q *MemoryQueue) NextJobAt(ctx context.Context) (time.Time, bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.jobs) == 0 {
+		return time.Time{}, false, nil
+	}
+	return q.jobs[0].readyAt, true, nil
+}
+
+func (q *MemoryQueue) Wait(ctx context.Context, timeout time.Duration) error {
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+
+	select {
+	case <-q.notify:
+	case <-t.C:
+	case <-ctx.Done():
+	}
+	return nil
+}
+
+func (q *MemoryQueue) Depth(ctx context.Context) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return int64(len(q.jobs)), nil
+}
+
+func (q *MemoryQueue) ReadyCount(ctx context.Context, now time.Time) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var n int64
+	for _, job := range q.jobs {
+		if !job.readyAt.After(now) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (q *MemoryQueue) Payload(ctx context.Context, eventID string) ([]byte, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	p, ok := q.payloads[eventID]
+	if !ok || time.Now().After(p.expiresAt) {
+		return nil, nil
+	}
+	return p.data, nil
+}
+
+func (q *MemoryQueue) Pause(ctx context.Context, subscriberID string, until time.Time) error {
+	q.mu.Lock()
+	q.paused[subscriberID] = until
+	q.mu.Unlock()
+	return nil
+}
+
+func (q *MemoryQueue) Resume(ctx context.Context, subscriberID string) (int64, error) {
+	now := time.Now()
+	q.mu.Lock()
+	delete(q.paused, subscriberID)
+	jobs := q.parked[subscriberID]
+	delete(q.parked, subscriberID)
+	for _, m := range jobs {
+		heap.Push(&q.jobs, queuedJob{member: m, readyAt: now})
+	}
+	q.mu.Unlock()
+
+	if len(jobs) > 0 {
+		q.wake()
+	}
+	return int64(len(jobs)), nil
+}
+
+func (q *MemoryQueue) ParkIfPaused(ctx context.Context, job DeliveryJob) (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	until, ok := q.paused[job.SubscriberID]
+	if !ok || (!until.IsZero() && !time.Now().Before(until)) {
+		return false, nil
+	}
+	jobBytes, err := json.Marshal(job)
+	if err != nil {
+		return false, fmt.Errorf("marshaling job: %w", err)
+	}
+	q.parked[job.SubscriberID] = append(q.parked[job.SubscriberID], string(jobBytes))
+	return true, nil
+}
+
+func (q *MemoryQueue) PauseStatus(ctx context.Context, subscriberID string) (PauseStatus, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	status := PauseStatus{SubscriberID: subscriberID, ParkedJobs: int64(len(q.parked[subscriberID]))}
+	until, ok := q.paused[subscriberID]
+	switch {
+	case !ok:
+	case until.IsZero():
+		status.Paused = true
+	case time.Now().Before(until):
+		status.Paused = true
+		status.PausedUntil = &until
+	}
+	return status, nil
+}
+
+func (q *MemoryQueue) ExpiredPauses(ctx context.Context, now time.Time) ([]string, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var expired []string
+	for id, until := range q.paused {
+		if !until.IsZero() && !until.After(now) {
+			expired = append(expired, id)
+		}
+	}
+	return expired, nil
+}
+
+func (q *MemoryQueue) PendingJobs(ctx context.Context, subscriberID string) ([]PendingJob, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var pending []PendingJob
+	for _, queued := range q.jobs {
+		if job, ok := decodeSubscriberJob(queued.member, subscriberID); ok {
+			pending = append(pending, newPendingJob(job, queued.readyAt, false))
+		}
+	}
+	for _, member := range q.parked[subscriberID] {
+		if job, ok := decodeSubscriberJob(member, subscriberID); ok {
+			pending = append(pending, newPendingJob(job, time.Time{}, true))
+		}
+	}
+	sortPendingJobs(pending)
+	return pending, nil
+}
+
+func (q *MemoryQueue) PurgePending(ctx context.Context, subscriberID string) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	removed := int64(len(q.parked[subscriberID]))
+	delete(q.parked, subscriberID)
+
+	kept := q.jobs[:0]
+	for _, queued := range q.jobs {
+		if _, ok := decodeSubscriberJob(queued.member, subscriberID); ok {
+			removed++
+			continue
+		}
+		kept = append(kept, queued)
+	}
+	q.jobs = kept
+	heap.Init(&q.jobs)
+	return removed, nil
+}
+
+func (q *MemoryQueue) Inspect(ctx context.Context, now time.Time) (QueueStats, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	b := newQueueStatsBuilder(now)
+	for _, queued := range q.jobs {
+		var job DeliveryJob
+		if err := json.Unmarshal([]byte(queued.member), &job); err != nil {
+			continue
+		}
+		b.add(job, queued.readyAt)
+	}
+	for id, parked := range q.parked {
+		b.addParked(id, int64(len(parked)))
+	}
+	return b.build(), nil
+}
+
+func (q *MemoryQueue) PurgeJobs(ctx context.Context, filter JobFilter, now time.Time) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var removed int64
+	kept := q.jobs[:0]
+	for _, queued := range q.jobs {
+		var job DeliveryJob
+		if json.Unmarshal([]byte(queued.member), &job) == nil && filter.matches(job, queued.readyAt, now) {
+			removed++
+			continue
+		}
+		kept = append(kept, queued)
+	}
+	q.jobs = kept
+	heap.Init(&q.jobs)
+	return removed, nil
+}
+
+// decodeSubscriberJob decodes a raw job, reporting whether it belongs to the
+// subscriber.
+func decodeSubscriberJob(member, subscriberID string) (DeliveryJob, bool) {
+	var job DeliveryJob
+	if err := json.Unmarshal([]byte(member), &job); err != nil {
+		return job, false
+	}
+	return job, job.SubscriberID == subscriberID
+}
+
+// fairOrder returns the indexes of up to limit of the ready jobs, which are
+// oldest first, taken round-robin across subscribers in the order of each
+// subscriber's oldest job, the way claimReadyScript picks them.
+func fairOrder(ready []queuedJob, limit int) []int {
+	if len(ready) <= limit {
+		order := make([]int, len(ready))
+		for i := range order {
+			order[i] = i
+		}
+		return order
+	}
+
+	var groups [][]int
+	index := make(map[string]int)
+	for i, job := range ready {
+		sub := memberSubscriber(job.member)
+		g, ok := index[sub]
+		if !ok {
+			g = len(groups)
+			index[sub] = g
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], i)
+	}
+
+	order := make([]int, 0, limit)
+	for round := 0; len(order) < limit; round++ {
+		for _, group := range groups {
+			if round < len(group) && len(order) < limit {
+				order = append(order, group[round])
+			}
+		}
+	}
+	return order
+}
+
+// memberSubscriber returns the subscriber ID of a raw job without decoding
+// all of it, matching the pattern claimReadyScript uses.
+func memberSubscriber(member string) string {
+	const field = `"subscriber_id":"`
+	i := strings.Index(member, field)
+	if i < 0 {
+		return ""
+	}
+	rest := member[i+len(field):]
+	j := strings.IndexByte(rest, '"')
+	if j < 0 {
+		return ""
+	}
+	return rest[:j]
+}
+
+// wake signals a waiting dispatcher. The channel holds one token, so
+// signals sent while nobody waits collapse into one, like the Redis notify
+// list.
+func (q *MemoryQueue) wake() {
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+// sweepPayloads drops expired payloads, at most once a minute. Callers must
+// hold q.mu.
+func (q *MemoryQueue) sweepPayloads(now time.Time) {
+	if now.Sub(q.swept) < time.Minute {
+		return
+	}
+	q.swept = now
+	for id, p := range q.payloads {
+		if now.After(p.expiresAt) {
+			delete(q.payloads, id)
+		}
+	}
+}
+
+// queuedJob is a raw job waiting in a MemoryQueue.
+type queuedJob struct {
+	member  string
+	readyAt time.Time
+}
+
+// jobHeap orders queued jobs by ready time, earliest first.
+type jobHeap []queuedJob
+
+func (h jobHeap) Len() int            { return len(h) }
+func (h jobHeap) Less(i, j int) bool  { return h[i].readyAt.Before(h[j].readyAt) }
+func (h jobHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *jobHeap) Push(x interface{}) { *h = append(*h, x.(queuedJob)) }
+
+func (h *jobHeap) Pop() interface{} {
+	old := *h
+	job := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return job
+}
@@ -0,0 +1,244 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMemoryQueue_ClaimsDueJobsInOrder(t *testing.T) {
+	q := NewMemoryQueue()
+	ctx := context.Background()
+
+	now := time.Now()
+	q.Enqueue(ctx, DeliveryJob{EventID: "later"}, now.Add(-time.Second))
+	q.Enqueue(ctx, DeliveryJob{EventID: "future"}, now.Add(time.Hour))
+	q.Enqueue(ctx, DeliveryJob{EventID: "earlier"}, now.Add(-2*time.Second))
+
+	if n, _ := q.ReadyCount(ctx, now); n != 2 {
+		t.Errorf("ReadyCount = %d, want 2", n)
+	}
+
+	claimed, err := q.Claim(ctx, now, 10)
+	if err != nil {
+		t.Fatalf("Claim failed: %v", err)
+	}
+	if len(claimed) != 2 {
+		t.Fatalf("claimed %d jobs, want 2", len(claimed))
+	}
+	if !claimed[0].ReadyAt.Before(claimed[1].ReadyAt) {
+		t.Errorf("jobs claimed out of order: %v then %v", claimed[0].ReadyAt, claimed[1].ReadyAt)
+	}
+
+	if depth, _ := q.Depth(ctx); depth != 1 {
+		t.Errorf("Depth = %d, want 1 (the future job)", depth)
+	}
+	if next, ok, _ := q.NextJobAt(ctx); !ok || !next.Equal(now.Add(time.Hour)) {
+		t.Errorf("NextJobAt = %v, %v, want the future job", next, ok)
+	}
+
+	// A returned job goes back at its original ready time; an acked one is gone
+	q.Return(ctx, claimed[0].Member)
+	q.Ack(ctx, claimed[1].Member)
+	again, _ := q.Claim(ctx, now, 10)
+	if len(again) != 1 || again[0].Member != claimed[0].Member || !again[0].ReadyAt.Equal(claimed[0].ReadyAt) {
+		t.Errorf("reclaimed %+v, want the returned job", again)
+	}
+}
+
+func TestMemoryQueue_ClaimSharesBatchAcrossSubscribers(t *testing.T) {
+	q := NewMemoryQueue()
+	ctx := context.Background()
+
+	now := time.Now()
+	for i := 0; i < 20; i++ {
+		q.Enqueue(ctx, DeliveryJob{EventID: fmt.Sprintf("hot-%d", i), SubscriberID: "hot"}, now.Add(-time.Minute+time.Duration(i)*time.Millisecond))
+	}
+	q.Enqueue(ctx, DeliveryJob{EventID: "quiet", SubscriberID: "quiet"}, now.Add(-time.Second))
+
+	claimed, _ := q.Claim(ctx, now, 5)
+	if got := claimedSubscribers(t, claimed); !reflect.DeepEqual(got, []string{"hot", "quiet", "hot", "hot", "hot"}) {
+		t.Errorf("claimed %v, want the quiet subscriber's job in the batch", got)
+	}
+	if !claimed[0].ReadyAt.Equal(now.Add(-time.Minute)) {
+		t.Errorf("first claimed job ready at %v, want the oldest", claimed[0].ReadyAt)
+	}
+	if depth, _ := q.Depth(ctx); depth != 16 {
+		t.Errorf("Depth = %d, want the 16 jobs not claimed", depth)
+	}
+
+	// Without a window, the hot subscriber's backlog fills the batch
+	q.SetFairnessWindow(0)
+	q.Enqueue(ctx, DeliveryJob{EventID: "quiet-2", SubscriberID: "quiet"}, now.Add(-time.Second))
+	claimed, _ = q.Claim(ctx, now, 5)
+	if got := claimedSubscribers(t, claimed); !reflect.DeepEqual(got, []string{"hot", "hot", "hot", "hot", "hot"}) {
+		t.Errorf("window 0 claimed %v, want only the oldest jobs", got)
+	}
+}
+
+func TestMemoryQueue_ClaimsProbesFirst(t *testing.T) {
+	q := NewMemoryQueue()
+	q.SetFairnessWindow(0)
+	ctx := context.Background()
+
+	now := time.Now()
+	for i := 0; i < 10; i++ {
+		q.Enqueue(ctx, DeliveryJob{EventID: fmt.Sprintf("backlog-%d", i), SubscriberID: "busy"}, now.Add(-time.Minute))
+	}
+	q.Enqueue(ctx, DeliveryJob{EventID: "probe", SubscriberID: "down", Probe: true}, now.Add(-time.Second))
+	q.Enqueue(ctx, DeliveryJob{EventID: "early-probe", SubscriberID: "other", Probe: true}, now.Add(time.Minute))
+	q.Enqueue(ctx, DeliveryJob{EventID: "purged-probe", SubscriberID: "gone", Probe: true}, now.Add(-time.Second))
+	q.PurgePending(ctx, "gone")
+
+	claimed, _ := q.Claim(ctx, now, 3)
+	if got := claimedSubscribers(t, claimed); !reflect.DeepEqual(got, []string{"down", "busy", "busy"}) {
+		t.Errorf("claimed %v, want the due probe ahead of the older backlog", got)
+	}
+	if len(q.probes) != 1 {
+		t.Errorf("%d probes indexed, want only the one not due yet", len(q.probes))
+	}
+}
+
+func TestMemoryQueue_ReturnedProbeKeepsItsPlace(t *testing.T) {
+	q := NewMemoryQueue()
+	q.SetFairnessWindow(0)
+	ctx := context.Background()
+
+	now := time.Now()
+	q.Enqueue(ctx, DeliveryJob{EventID: "probe", SubscriberID: "down", Probe: true}, now.Add(-time.Second))
+	claimed, _ := q.Claim(ctx, now, 1)
+	if got := claimedSubscribers(t, claimed); !reflect.DeepEqual(got, []string{"down"}) {
+		t.Fatalf("claimed %v, want the probe", got)
+	}
+
+	q.Enqueue(ctx, DeliveryJob{EventID: "backlog", SubscriberID: "busy"}, now.Add(-time.Minute))
+	q.Return(ctx, claimed[0].Member)
+
+	claimed, _ = q.Claim(ctx, now, 1)
+	if got := claimedSubscribers(t, claimed); !reflect.DeepEqual(got, []string{"down"}) {
+		t.Errorf("claimed %v after the probe was returned, want it ahead of the older job", got)
+	}
+}
+
+func TestMemoryQueue_PublishStoresPayloadAndWakesWaiter(t *testing.T) {
+	q := NewMemoryQueue()
+	ctx := context.Background()
+
+	woke := make(chan struct{})
+	go func() {
+		q.Wait(ctx, 2*time.Second)
+		close(woke)
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	jobs := []DeliveryJob{{EventID: "evt-1", SubscriberID: "a"}, {EventID: "evt-1", SubscriberID: "b"}}
+	if err := q.Publish(ctx, "evt-1", []byte(`{"id":1}`), jobs); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	select {
+	case <-woke:
+	case <-time.After(time.Second):
+		t.Fatal("Publish did not wake the waiter")
+	}
+
+	if depth, _ := q.Depth(ctx); depth != 2 {
+		t.Errorf("Depth = %d, want 2", depth)
+	}
+	if payload, _ := q.Payload(ctx, "evt-1"); string(payload) != `{"id":1}` {
+		t.Errorf("Payload = %q", payload)
+	}
+	if payload, err := q.Payload(ctx, "missing"); payload != nil || err != nil {
+		t.Errorf("Payload(missing) = %q, %v, want nil, nil", payload, err)
+	}
+}
+
+func TestMemoryQueue_PauseParksJobsUntilResumed(t *testing.T) {
+	q := NewMemoryQueue()
+	ctx := context.Background()
+
+	q.Pause(ctx, "sub-1", time.Time{})
+	if parked, _ := q.ParkIfPaused(ctx, DeliveryJob{EventID: "evt-1", SubscriberID: "sub-1"}); !parked {
+		t.Error("job for a paused subscriber was not parked")
+	}
+	if parked, _ := q.ParkIfPaused(ctx, DeliveryJob{EventID: "evt-1", SubscriberID: "sub-2"}); parked {
+		t.Error("job for another subscriber was parked")
+	}
+
+	status, _ := q.PauseStatus(ctx, "sub-1")
+	if !status.Paused || status.PausedUntil != nil || status.ParkedJobs != 1 {
+		t.Errorf("PauseStatus = %+v, want indefinitely paused with 1 parked job", status)
+	}
+	if depth, _ := q.Depth(ctx); depth != 0 {
+		t.Errorf("Depth = %d, want parked jobs excluded", depth)
+	}
+
+	resumed, err := q.Resume(ctx, "sub-1")
+	if err != nil || resumed != 1 {
+		t.Fatalf("Resume = %d, %v, want 1 job", resumed, err)
+	}
+	claimed, _ := q.Claim(ctx, time.Now(), 10)
+	if len(claimed) != 1 {
+		t.Fatalf("claimed %d jobs after resume, want 1", len(claimed))
+	}
+	if status, _ := q.PauseStatus(ctx, "sub-1"); status.Paused || status.ParkedJobs != 0 {
+		t.Errorf("PauseStatus after resume = %+v", status)
+	}
+}
+
+func TestMemoryQueue_TimedPauseExpires(t *testing.T) {
+	q := NewMemoryQueue()
+	ctx := context.Background()
+
+	now := time.Now()
+	q.Pause(ctx, "short", now.Add(-time.Second))
+	q.Pause(ctx, "long", now.Add(time.Hour))
+	q.Pause(ctx, "forever", time.Time{})
+
+	if parked, _ := q.ParkIfPaused(ctx, DeliveryJob{SubscriberID: "short"}); parked {
+		t.Error("job parked after its pause ended")
+	}
+	expired, _ := q.ExpiredPauses(ctx, now)
+	if len(expired) != 1 || expired[0] != "short" {
+		t.Errorf("ExpiredPauses = %v, want [short]", expired)
+	}
+}
+
+func TestMemoryQueue_PendingJobsAndPurge(t *testing.T) {
+	q := NewMemoryQueue()
+	ctx := context.Background()
+
+	now := time.Now()
+	q.Enqueue(ctx, DeliveryJob{EventID: "retry", SubscriberID: "sub-1", Attempt: 2}, now.Add(time.Minute))
+	q.Enqueue(ctx, DeliveryJob{EventID: "due", SubscriberID: "sub-1", Attempt: 1}, now)
+	q.Enqueue(ctx, DeliveryJob{EventID: "other", SubscriberID: "sub-2"}, now)
+	q.Pause(ctx, "sub-1", time.Time{})
+	q.ParkIfPaused(ctx, DeliveryJob{EventID: "parked", SubscriberID: "sub-1"})
+
+	pending, err := q.PendingJobs(ctx, "sub-1")
+	if err != nil {
+		t.Fatalf("PendingJobs failed: %v", err)
+	}
+	var order []string
+	for _, p := range pending {
+		order = append(order, p.EventID)
+	}
+	if len(order) != 3 || order[0] != "due" || order[1] != "retry" || order[2] != "parked" {
+		t.Fatalf("pending = %v, want [due retry parked]", order)
+	}
+	if !pending[2].Parked || pending[2].NextAttemptAt != nil || !pending[1].NextAttemptAt.Equal(now.Add(time.Minute)) {
+		t.Errorf("pending = %+v", pending)
+	}
+
+	if n, _ := q.PurgePending(ctx, "sub-1"); n != 3 {
+		t.Errorf("PurgePending = %d, want 3", n)
+	}
+	if pending, _ := q.PendingJobs(ctx, "sub-1"); len(pending) != 0 {
+		t.Errorf("pending after purge = %+v", pending)
+	}
+	if claimed, _ := q.Claim(ctx, now, 10); len(claimed) != 1 {
+		t.Errorf("claimed %d jobs after purge, want the other subscriber's job", len(claimed))
+	}
+}
@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+)
+
+// MetricsRollup keeps the delivery_metrics_hourly table up to date so the
+// dashboard never has to aggregate the full delivery_attempts table.
+//
+// Every interval it recomputes all hours from the latest rolled-up hour (or
+// the previous hour, whichever is earlier) up to now. The current hour is
+// therefore refreshed continuously and finalised on the first pass after it
+// ends. On first start with an empty rollup it backfills history a day at a
+// time.
+type MetricsRollup struct {
+	pgStore  *store.PostgresStore
+	logger   *slog.Logger
+	interval time.Duration
+	chunk    time.Duration
+}
+
+func NewMetricsRollup(pg *store.PostgresStore, logger *slog.Logger) *MetricsRollup {
+	return &MetricsRollup{
+		pgStore:  pg,
+		logger:   logger,
+		interval: 1 * time.Minute,
+		chunk:    24 * time.Hour,
+	}
+}
+
+// Start runs a rollup pass immediately and then on every interval until the
+// context is cancelled.
+func (m *MetricsRollup) Start(ctx context.Context) {
+	m.logger.Info("metrics rollup started")
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := m.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			m.logger.Error("metrics rollup failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			m.logger.Info("metrics rollup stopping")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce brings the rollup up to date with delivery_attempts.
+func (m *MetricsRollup) RunOnce(ctx context.Context) error {
+	now := time.Now().UTC()
+	from := now.Truncate(time.Hour).Add(-time.Hour)
+
+	latest, ok, err := m.pgStore.LatestRollupHour(ctx)
+	if err != nil {
+		return err
+	}
+	if ok && latest.Before(from) {
+		from = latest
+	}
+	if !ok {
+		earliest, found, err := m.pgStore.EarliestDeliveryAttempt(ctx)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return nil
+		}
+		from = earliest.UTC().Truncate(time.Hour)
+		m.logger.Info("backfilling metrics rollup", "from", from.Format(time.RFC3339))
+	}
+
+	end := now.Truncate(time.Hour).Add(time.Hour)
+	for start := from; start.Before(end); start = start.Add(m.chunk) {
+		stop := start.Add(m.chunk)
+		if stop.After(end) {
+			stop = end
+		}
+		if _, err := m.pgStore.RollupDeliveryMetrics(ctx, start, stop); err != nil {
+			return err
+		}
+	}
+	return nil
+}
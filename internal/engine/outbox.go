@@ -0,0 +1,113 @@
+package engine
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+)
+
+// OutboxRelay drains the fanout_outbox table into the Redis delivery queue.
+//
+// Every event is written together with an outbox entry in one Postgres
+// transaction. The API fans out inline and clears the entry on success; if
+// that fails (e.g. Redis is down) the entry stays behind and the relay keeps
+// retrying it with backoff until the fan-out succeeds. This gives at-least-once
+// fan-out: a crash between queueing jobs and clearing the entry can produce
+// duplicate deliveries, but never a lost one.
+//
+// Relays on every instance poll the same table. Each batch is claimed for
+// lease, so an entry is fanned out by one relay at a time; one claimed by an
+// instance that died is picked up again once its lease runs out.
+type OutboxRelay struct {
+	store        store.OutboxStore
+	fanout       *FanOutEngine
+	logger       *slog.Logger
+	pollInterval time.Duration
+	batchSize    int
+	lease        time.Duration
+	maxBackoff   time.Duration
+}
+
+func NewOutboxRelay(s store.OutboxStore, fanout *FanOutEngine, logger *slog.Logger) *OutboxRelay {
+	return &OutboxRelay{
+		store:        s,
+		fanout:       fanout,
+		logger:       logger,
+		pollInterval: 1 * time.Second,
+		batchSize:    100,
+		lease:        5 * time.Minute,
+		maxBackoff:   1 * time.Minute,
+	}
+}
+
+// Start runs the relay loop until the context is cancelled.
+func (r *OutboxRelay) Start(ctx context.Context) {
+	r.logger.Info("outbox relay started")
+
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			r.logger.Info("outbox relay stopping")
+			return
+		case <-ticker.C:
+			r.relay(ctx)
+		}
+	}
+}
+
+// relay fans out one batch of due outbox entries.
+func (r *OutboxRelay) relay(ctx context.Context) {
+	entries, err := r.store.ClaimPendingOutbox(ctx, r.batchSize, r.lease)
+	if err != nil {
+		r.logger.Error("failed to read fanout outbox", "error", err)
+		return
+	}
+
+	for _, entry := range entries {
+		event := entry.Event
+
+		result, err := r.fanout.FanOut(ctx, &event)
+		if err != nil {
+			retryAt := time.Now().Add(r.backoff(entry.Attempts))
+			if ferr := r.store.FailOutboxEntry(ctx, event.ID, err.Error(), retryAt); ferr != nil {
+				r.logger.Error("failed to update outbox entry", "error", ferr, "event_id", event.ID)
+			}
+			r.logger.Warn("outbox fan-out failed, will retry",
+				"event_id", event.ID,
+				"deliveries_queued", result.Queued,
+				"attempts", entry.Attempts+1,
+				"retry_at", retryAt.Format(time.RFC3339),
+				"error", err,
+			)
+			continue
+		}
+
+		if err := r.store.CompleteOutboxEntry(ctx, event.ID); err != nil {
+			r.logger.Error("failed to complete outbox entry", "error", err, "event_id", event.ID)
+			continue
+		}
+
+		r.logger.Info("outbox fan-out relayed",
+			"event_id", event.ID,
+			"deliveries_queued", result.Queued,
+			"deliveries_failed", len(result.Failed),
+			"attempts", entry.Attempts+1,
+		)
+	}
+}
+
+// backoff returns the delay before retrying an entry that has already failed
+// the given number of times.
+func (r *OutboxRelay) backoff(attempts int) time.Duration {
+	delay := time.Duration(math.Pow(2, float64(attempts))) * time.Second
+	if delay > r.maxBackoff || delay <= 0 {
+		return r.maxBackoff
+	}
+	return delay
+}
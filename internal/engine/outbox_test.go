@@ -0,0 +1,82 @@
+package engine
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+	"github.com/Priya8975/webhook-delivery-system/migrations"
+)
+
+func TestOutboxRelay_Backoff(t *testing.T) {
+	r := &OutboxRelay{maxBackoff: time.Minute}
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, 1 * time.Second},
+		{1, 2 * time.Second},
+		{3, 8 * time.Second},
+		{5, 32 * time.Second},
+		{6, time.Minute},   // 64s capped
+		{100, time.Minute}, // overflow capped
+	}
+
+	for _, tt := range tests {
+		if got := r.backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestOutboxRelay_SkipsEntriesClaimedByAnotherRelay(t *testing.T) {
+	ctx := context.Background()
+	s, err := store.NewSQLite(ctx, "sqlite::memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if _, err := s.RunMigrations(ctx, migrations.FS); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CreateSubscriber(ctx, domain.CreateSubscriberRequest{
+		Name: "orders", EndpointURL: "http://example.com/hook", EventTypes: []string{"order.created"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// Events whose inline fan-out failed, now due for the relays
+	for i := 0; i < 20; i++ {
+		event, err := s.CreateEvent(ctx, "order.created", 1, []byte(`{}`), nil, "", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.FailOutboxEntry(ctx, event.ID, "queue unavailable", time.Now().Add(-time.Second))
+	}
+
+	queue := NewMemoryQueue()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	fanout := NewFanOutEngine(s, queue, nil, logger)
+	first, second := NewOutboxRelay(s, fanout, logger), NewOutboxRelay(s, fanout, logger)
+
+	// The first relay is in the middle of fanning out its batch
+	first.batchSize = 5
+	held, err := s.ClaimPendingOutbox(ctx, first.batchSize, first.lease)
+	if err != nil || len(held) != 5 {
+		t.Fatalf("first relay claimed %d entries (%v), want 5", len(held), err)
+	}
+
+	second.relay(ctx)
+	if depth, _ := queue.Depth(ctx); depth != 15 {
+		t.Errorf("second relay queued %d deliveries, want the 15 entries not claimed by the first", depth)
+	}
+	for _, entry := range held {
+		if pending, _ := s.OutboxEntryPending(ctx, entry.Event.ID); !pending {
+			t.Errorf("second relay fanned out %s, claimed by the first", entry.Event.ID)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package engine
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+)
+
+// partitionsAhead is how many months of partitions are kept ready beyond
+// the current one, so inserts never hit a month without a partition even if
+// the server is down for a while.
+const partitionsAhead = 3
+
+// PartitionMaintainer creates the monthly partitions of events and
+// delivery_attempts ahead of time. Expired partitions are dropped by the
+// archiver.
+type PartitionMaintainer struct {
+	pgStore  *store.PostgresStore
+	logger   *slog.Logger
+	interval time.Duration
+}
+
+func NewPartitionMaintainer(pg *store.PostgresStore, logger *slog.Logger) *PartitionMaintainer {
+	return &PartitionMaintainer{
+		pgStore:  pg,
+		logger:   logger,
+		interval: 1 * time.Hour,
+	}
+}
+
+// Start creates missing partitions immediately and then on every interval
+// until the context is cancelled.
+func (m *PartitionMaintainer) Start(ctx context.Context) {
+	m.logger.Info("partition maintainer started", "months_ahead", partitionsAhead)
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := m.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			m.logger.Error("creating partitions failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			m.logger.Info("partition maintainer stopping")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce creates the partitions of each partitioned table up to
+// partitionsAhead months from now.
+func (m *PartitionMaintainer) RunOnce(ctx context.Context) error {
+	through := time.Now().UTC().AddDate(0, partitionsAhead, 0)
+	for _, table := range store.PartitionedTables {
+		created, err := m.pgStore.CreateMonthlyPartitions(ctx, table, through)
+		if len(created) > 0 {
+			m.logger.Info("created partitions", "table", table, "partitions", created)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
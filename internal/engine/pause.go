@@ -0,0 +1,67 @@
+package engine
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// PauseExpirer resumes subscribers whose timed pause has ended, queueing the
+// jobs parked while they were paused. Resuming is atomic, so several
+// instances can run one without resuming a subscriber twice.
+type PauseExpirer struct {
+	queue    Queue
+	clock    Clock
+	interval time.Duration
+	logger   *slog.Logger
+}
+
+func NewPauseExpirer(queue Queue, logger *slog.Logger) *PauseExpirer {
+	return &PauseExpirer{
+		queue:    queue,
+		clock:    SystemClock{},
+		interval: time.Second,
+		logger:   logger,
+	}
+}
+
+// SetClock sets the clock that pauses are timed by, which must be the one
+// the queue times them by. Call it before Start.
+func (e *PauseExpirer) SetClock(c Clock) {
+	e.clock = c
+}
+
+// Start checks for ended pauses on every interval until the context is
+// cancelled.
+func (e *PauseExpirer) Start(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.resumeExpired(ctx, e.clock.Now())
+		}
+	}
+}
+
+func (e *PauseExpirer) resumeExpired(ctx context.Context, now time.Time) {
+	expired, err := e.queue.ExpiredPauses(ctx, now)
+	if err != nil {
+		if ctx.Err() == nil {
+			e.logger.Error("failed to list ended pauses", "error", err)
+		}
+		return
+	}
+
+	for _, id := range expired {
+		n, err := e.queue.Resume(ctx, id)
+		if err != nil {
+			e.logger.Error("failed to resume subscriber", "error", err, "subscriber_id", id)
+			continue
+		}
+		e.logger.Info("subscriber pause ended", "subscriber_id", id, "jobs_resumed", n)
+	}
+}
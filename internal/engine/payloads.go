@@ -0,0 +1,73 @@
+package engine
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+)
+
+// ObjectStore keeps payloads too large for the database and the queue. The
+// archive package's S3Client implements it.
+type ObjectStore interface {
+	PutObject(ctx context.Context, key string, body []byte, contentType string) error
+	GetObject(ctx context.Context, key string) (io.ReadCloser, error)
+}
+
+// PayloadTooLargeError is returned by Publish for a payload over the limit
+// set with SetMaxPayloadBytes.
+type PayloadTooLargeError struct {
+	Size  int
+	Limit int
+}
+
+func (e *PayloadTooLargeError) Error() string {
+	return fmt.Sprintf("payload is %d bytes, over the limit of %d bytes", e.Size, e.Limit)
+}
+
+// SetMaxPayloadBytes sets the largest payload Publish accepts, whatever the
+// ingestion path; 0 accepts any. It is safe to call while events are being
+// published.
+func (f *FanOutEngine) SetMaxPayloadBytes(n int) {
+	f.maxPayloadBytes.Store(int64(n))
+}
+
+// CheckPayloadSize returns a *PayloadTooLargeError if payload is over the
+// limit. Publish checks it too; ingestion paths that would otherwise retry
+// a failed Publish check first, since a payload never shrinks.
+func (f *FanOutEngine) CheckPayloadSize(payload []byte) error {
+	if limit := int(f.maxPayloadBytes.Load()); limit > 0 && len(payload) > limit {
+		return &PayloadTooLargeError{Size: len(payload), Limit: limit}
+	}
+	return nil
+}
+
+// SetPayloadOffload makes Publish keep payloads larger than threshold bytes
+// in objects, under prefix, instead of the database and the queue, so that
+// deliveries can stream them rather than hold them in memory. Call it
+// before events are published.
+func (f *FanOutEngine) SetPayloadOffload(objects ObjectStore, prefix string, threshold int) {
+	f.objects = objects
+	f.offloadPrefix = prefix
+	f.offloadThreshold = threshold
+}
+
+// offloadPayload uploads payload if it is over the offload threshold. It
+// returns the payload to store with the event, null once uploaded, and the
+// reference to the upload. Objects are named by their content, so
+// publishing the same payload again reuses its object.
+func (f *FanOutEngine) offloadPayload(ctx context.Context, payload []byte) ([]byte, *domain.PayloadRef, error) {
+	if f.objects == nil || len(payload) <= f.offloadThreshold {
+		return payload, nil, nil
+	}
+	sum := sha256.Sum256(payload)
+	key := path.Join(f.offloadPrefix, "payloads", hex.EncodeToString(sum[:])+".json")
+	if err := f.objects.PutObject(ctx, key, payload, "application/json"); err != nil {
+		return nil, nil, fmt.Errorf("offloading payload: %w", err)
+	}
+	return []byte("null"), &domain.PayloadRef{Key: key, Size: int64(len(payload))}, nil
+}
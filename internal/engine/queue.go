@@ -0,0 +1,771 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/errgroup"
+)
+
+// Queue is the delivery queue shared by the fan-out engine, the dispatcher
+// and the workers: a delay queue of raw jobs, each ready at a given time,
+// plus the event payloads those jobs reference. Claimed jobs are held until
+// they are acknowledged or returned.
+//
+// RedisQueue is the durable, multi-instance implementation. MemoryQueue keeps
+// everything in-process for development, tests and single-node setups, and
+// loses queued jobs on restart.
+type Queue interface {
+	// Publish stores an event's payload and queues jobs that are ready now.
+	Publish(ctx context.Context, eventID string, payload []byte, jobs []DeliveryJob) error
+	// Enqueue queues a job to become ready at the given time.
+	Enqueue(ctx context.Context, job DeliveryJob, at time.Time) error
+	// Claim removes up to limit jobs that are due at or before now. Due
+	// probe jobs come first. When more jobs are due, the oldest ones are
+	// shared out round-robin across subscribers so one subscriber's backlog
+	// can't starve the rest; after the probes, the first job returned is
+	// always the oldest. Each must be passed to Ack or Return once it is
+	// handled.
+	Claim(ctx context.Context, now time.Time, limit int64) ([]ClaimedJob, error)
+	// Ack marks a claimed job as finished.
+	Ack(ctx context.Context, member string) error
+	// Return puts a claimed job back at its original ready time.
+	Return(ctx context.Context, member string) error
+	// NextJobAt returns when the earliest queued job becomes ready. ok is
+	// false when the queue is empty.
+	NextJobAt(ctx context.Context) (at time.Time, ok bool, err error)
+	// Wait blocks until a job is queued or timeout elapses.
+	Wait(ctx context.Context, timeout time.Duration) error
+	// Depth returns the number of queued jobs, including scheduled retries.
+	Depth(ctx context.Context) (int64, error)
+	// ReadyCount returns the number of queued jobs due at or before now.
+	ReadyCount(ctx context.Context, now time.Time) (int64, error)
+	// Payload returns the stored payload of an event, or nil once it has
+	// expired.
+	Payload(ctx context.Context, eventID string) ([]byte, error)
+
+	// Pause holds deliveries to a subscriber until Resume is called, or
+	// until the given time if it is non-zero.
+	Pause(ctx context.Context, subscriberID string, until time.Time) error
+	// Resume lifts a subscriber's pause and queues its parked jobs, returning
+	// how many were queued.
+	Resume(ctx context.Context, subscriberID string) (int64, error)
+	// ParkIfPaused sets job aside if its subscriber is paused, reporting
+	// whether it did. Parked jobs are queued again by Resume.
+	ParkIfPaused(ctx context.Context, job DeliveryJob) (bool, error)
+	// PauseStatus reports whether a subscriber is paused.
+	PauseStatus(ctx context.Context, subscriberID string) (PauseStatus, error)
+	// ExpiredPauses returns the subscribers whose timed pause ended at or
+	// before now and still need to be resumed.
+	ExpiredPauses(ctx context.Context, now time.Time) ([]string, error)
+
+	// PendingJobs returns a subscriber's queued, retrying and parked jobs,
+	// soonest first with parked jobs last. Jobs a worker has already claimed
+	// are not included.
+	PendingJobs(ctx context.Context, subscriberID string) ([]PendingJob, error)
+	// PurgePending removes every queued, retrying and parked job of a
+	// subscriber, returning how many were removed.
+	PurgePending(ctx context.Context, subscriberID string) (int64, error)
+
+	// Inspect counts the queued and parked jobs, in total and per
+	// subscriber, with due jobs counted as ready as of now.
+	Inspect(ctx context.Context, now time.Time) (QueueStats, error)
+	// PurgeJobs removes the queued jobs matching filter, returning how many
+	// were removed. Parked and claimed jobs are left alone.
+	PurgeJobs(ctx context.Context, filter JobFilter, now time.Time) (int64, error)
+}
+
+// PauseStatus describes a subscriber's delivery pause.
+type PauseStatus struct {
+	SubscriberID string     `json:"subscriber_id"`
+	Paused       bool       `json:"paused"`
+	PausedUntil  *time.Time `json:"paused_until,omitempty"`
+	ParkedJobs   int64      `json:"parked_jobs"`
+}
+
+// PendingJob is a job waiting in the queue for its subscriber. It leaves out
+// the signing secret carried by the job itself.
+type PendingJob struct {
+	EventID       string     `json:"event_id"`
+	EventType     string     `json:"event_type"`
+	Attempt       int        `json:"attempt"`
+	MaxRetries    int        `json:"max_retries"`
+	NextAttemptAt *time.Time `json:"next_attempt_at"` // nil while parked
+	Parked        bool       `json:"parked"`
+}
+
+func newPendingJob(job DeliveryJob, readyAt time.Time, parked bool) PendingJob {
+	p := PendingJob{
+		EventID:    job.EventID,
+		EventType:  job.EventType,
+		Attempt:    job.Attempt,
+		MaxRetries: job.MaxRetries,
+		Parked:     parked,
+	}
+	if !parked {
+		p.NextAttemptAt = &readyAt
+	}
+	return p
+}
+
+// sortPendingJobs orders jobs by next attempt, with parked jobs last.
+func sortPendingJobs(jobs []PendingJob) {
+	sort.SliceStable(jobs, func(i, j int) bool {
+		a, b := jobs[i].NextAttemptAt, jobs[j].NextAttemptAt
+		if a == nil || b == nil {
+			return b == nil && a != nil
+		}
+		return a.Before(*b)
+	})
+}
+
+// DefaultFairnessWindow is how many of the oldest ready jobs a claim shares
+// out across subscribers unless the queue is told otherwise.
+const DefaultFairnessWindow = 1000
+
+// RedisQueue is a Queue in a Redis sorted set, scored by ready time. Jobs are
+// claimed into the cluster member's claims set so a crashed instance's jobs
+// can be recovered.
+type RedisQueue struct {
+	client  *redis.Client
+	cluster *Cluster
+
+	// Publish queues jobs in pipelines of up to chunkSize commands, running
+	// at most chunkParallelism of them at once.
+	chunkSize        int
+	chunkParallelism int
+
+	// fairnessWindow is how many of the oldest ready jobs Claim shares out
+	// across subscribers.
+	fairnessWindow int64
+
+	// clock scores the jobs queued now, and times pauses.
+	clock Clock
+}
+
+var _ Queue = (*RedisQueue)(nil)
+
+// NewRedisQueue creates a queue on the given client. cluster may be nil when
+// nothing claims from the queue, e.g. in tests that only enqueue.
+func NewRedisQueue(client *redis.Client, cluster *Cluster) *RedisQueue {
+	return &RedisQueue{client: client, cluster: cluster, chunkSize: 500, chunkParallelism: 4, fairnessWindow: DefaultFairnessWindow, clock: SystemClock{}}
+}
+
+// SetPublishChunking sets how many jobs Publish sends to Redis per pipeline
+// and how many pipelines it runs concurrently. Call it before events are
+// published.
+func (q *RedisQueue) SetPublishChunking(size, parallelism int) {
+	q.chunkSize = size
+	q.chunkParallelism = parallelism
+}
+
+// SetFairnessWindow sets how many of the oldest ready jobs Claim considers
+// when sharing a batch out across subscribers. A window no larger than the
+// batch size claims strictly oldest first. Call it before dispatching starts.
+func (q *RedisQueue) SetFairnessWindow(n int64) {
+	q.fairnessWindow = n
+}
+
+// SetClock sets the clock that jobs queued to be ready now are scored by. It
+// must be the clock the dispatcher claims by. Call it before events are
+// published.
+func (q *RedisQueue) SetClock(c Clock) {
+	q.clock = c
+}
+
+// PublishError reports the jobs of a Publish that could not be queued. The
+// other jobs were queued.
+type PublishError struct {
+	Failed []string // subscriber IDs, in the order the jobs were given
+	Total  int
+	Err    error // the first failure
+}
+
+func (e *PublishError) Error() string {
+	return fmt.Sprintf("%d of %d deliveries could not be queued: %v", len(e.Failed), e.Total, e.Err)
+}
+
+func (e *PublishError) Unwrap() error {
+	return e.Err
+}
+
+// Publish stores the payload, then queues the jobs in chunks so a fan-out to
+// thousands of subscribers is not one huge pipeline. A chunk that fails does
+// not stop the others; the jobs that were not queued are reported in a
+// *PublishError.
+func (q *RedisQueue) Publish(ctx context.Context, eventID string, payload []byte, jobs []DeliveryJob) error {
+	members := make([]string, len(jobs))
+	for i, job := range jobs {
+		jobBytes, err := json.Marshal(job)
+		if err != nil {
+			return fmt.Errorf("marshaling job: %w", err)
+		}
+		members[i] = string(jobBytes)
+	}
+
+	if err := q.client.Set(ctx, PayloadKey(eventID), payload, PayloadTTL).Err(); err != nil {
+		return fmt.Errorf("storing payload in redis: %w", err)
+	}
+
+	score := float64(q.clock.Now().UnixMicro())
+	failed := make([]bool, len(jobs))
+	var (
+		mu       sync.Mutex
+		firstErr error
+	)
+	g := new(errgroup.Group)
+	g.SetLimit(max(q.chunkParallelism, 1))
+	size := max(q.chunkSize, 1)
+	for start := 0; start < len(members); start += size {
+		end := min(start+size, len(members))
+		g.Go(func() error {
+			pipe := q.client.Pipeline()
+			cmds := make([]*redis.IntCmd, 0, end-start)
+			for _, member := range members[start:end] {
+				cmds = append(cmds, pipe.ZAdd(ctx, DeliveryQueueKey, redis.Z{Score: score, Member: member}))
+			}
+			NotifyDispatchers(ctx, pipe)
+
+			// A failed pipeline may still have applied some of its
+			// commands, so check each job's own result
+			if _, err := pipe.Exec(ctx); err != nil {
+				mu.Lock()
+				defer mu.Unlock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				for i, cmd := range cmds {
+					failed[start+i] = cmd.Err() != nil
+				}
+			}
+			return nil
+		})
+	}
+	g.Wait()
+
+	if firstErr == nil {
+		return nil
+	}
+	perr := &PublishError{Total: len(jobs), Err: fmt.Errorf("queuing deliveries to redis: %w", firstErr)}
+	for i, f := range failed {
+		if f {
+			perr.Failed = append(perr.Failed, jobs[i].SubscriberID)
+		}
+	}
+	if len(perr.Failed) == 0 {
+		return nil // only a wake-up failed; idle dispatchers check within a second anyway
+	}
+	return perr
+}
+
+func (q *RedisQueue) Enqueue(ctx context.Context, job DeliveryJob, at time.Time) error {
+	return EnqueueJob(ctx, q.client, job, at)
+}
+
+func (q *RedisQueue) Claim(ctx context.Context, now time.Time, limit int64) ([]ClaimedJob, error) {
+	return ClaimReadyJobs(ctx, q.client, q.cluster.ClaimsKey(), now, limit, q.fairnessWindow)
+}
+
+func (q *RedisQueue) Ack(ctx context.Context, member string) error {
+	return q.cluster.Ack(ctx, member)
+}
+
+func (q *RedisQueue) Return(ctx context.Context, member string) error {
+	return q.cluster.Return(ctx, member)
+}
+
+func (q *RedisQueue) NextJobAt(ctx context.Context) (time.Time, bool, error) {
+	return NextJobAt(ctx, q.client)
+}
+
+// Wait blocks on the notify list that producers push to.
+func (q *RedisQueue) Wait(ctx context.Context, timeout time.Duration) error {
+	// BLPOP accepts fractional seconds (Redis >= 6); go-redis's helper rounds
+	// to whole seconds, so issue the command directly for ms precision.
+	err := q.client.Do(ctx, "BLPOP", DeliveryQueueNotifyKey, formatSeconds(timeout)).Err()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+	return nil
+}
+
+func (q *RedisQueue) Depth(ctx context.Context) (int64, error) {
+	return q.client.ZCard(ctx, DeliveryQueueKey).Result()
+}
+
+func (q *RedisQueue) ReadyCount(ctx context.Context, now time.Time) (int64, error) {
+	return ReadyJobCount(ctx, q.client, now)
+}
+
+func (q *RedisQueue) Payload(ctx context.Context, eventID string) ([]byte, error) {
+	data, err := q.client.Get(ctx, PayloadKey(eventID)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fetching payload from redis: %w", err)
+	}
+	return data, nil
+}
+
+// Pause records the subscriber in the paused set, scored by when the pause
+// ends; 0 means it lasts until Resume.
+func (q *RedisQueue) Pause(ctx context.Context, subscriberID string, until time.Time) error {
+	var score float64
+	if !until.IsZero() {
+		score = float64(until.UnixMicro())
+	}
+	return q.client.ZAdd(ctx, PausedSubscribersKey, redis.Z{Score: score, Member: subscriberID}).Err()
+}
+
+func (q *RedisQueue) Resume(ctx context.Context, subscriberID string) (int64, error) {
+	keys := []string{PausedSubscribersKey, ParkedJobsKey(subscriberID), DeliveryQueueKey, DeliveryQueueNotifyKey}
+	n, err := resumeScript.Run(ctx, q.client, keys, subscriberID, q.clock.Now().UnixMicro()).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("resuming subscriber: %w", err)
+	}
+	return n, nil
+}
+
+func (q *RedisQueue) ParkIfPaused(ctx context.Context, job DeliveryJob) (bool, error) {
+	jobBytes, err := json.Marshal(job)
+	if err != nil {
+		return false, fmt.Errorf("marshaling job: %w", err)
+	}
+	keys := []string{PausedSubscribersKey, ParkedJobsKey(job.SubscriberID)}
+	parked, err := parkScript.Run(ctx, q.client, keys, job.SubscriberID, q.clock.Now().UnixMicro(), jobBytes).Int()
+	if err != nil {
+		return false, fmt.Errorf("parking job: %w", err)
+	}
+	return parked == 1, nil
+}
+
+func (q *RedisQueue) PauseStatus(ctx context.Context, subscriberID string) (PauseStatus, error) {
+	status := PauseStatus{SubscriberID: subscriberID}
+
+	pipe := q.client.Pipeline()
+	scoreCmd := pipe.ZScore(ctx, PausedSubscribersKey, subscriberID)
+	parkedCmd := pipe.LLen(ctx, ParkedJobsKey(subscriberID))
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return status, fmt.Errorf("reading pause status: %w", err)
+	}
+
+	status.ParkedJobs = parkedCmd.Val()
+	score, err := scoreCmd.Result()
+	if errors.Is(err, redis.Nil) {
+		return status, nil
+	}
+	if score == 0 {
+		status.Paused = true
+		return status, nil
+	}
+	until := time.UnixMicro(int64(score))
+	status.Paused = q.clock.Now().Before(until)
+	if status.Paused {
+		status.PausedUntil = &until
+	}
+	return status, nil
+}
+
+func (q *RedisQueue) ExpiredPauses(ctx context.Context, now time.Time) ([]string, error) {
+	return q.client.ZRangeByScore(ctx, PausedSubscribersKey, &redis.ZRangeBy{
+		Min: "(0",
+		Max: strconv.FormatInt(now.UnixMicro(), 10),
+	}).Result()
+}
+
+func (q *RedisQueue) PendingJobs(ctx context.Context, subscriberID string) ([]PendingJob, error) {
+	queued, err := q.scanJobs(ctx, subscriberMatch(subscriberID), func(job DeliveryJob, _ time.Time) bool {
+		return job.SubscriberID == subscriberID
+	})
+	if err != nil {
+		return nil, err
+	}
+	parked, err := q.client.LRange(ctx, ParkedJobsKey(subscriberID), 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("reading parked jobs: %w", err)
+	}
+
+	pending := make([]PendingJob, 0, len(queued)+len(parked))
+	for _, j := range queued {
+		pending = append(pending, newPendingJob(j.job, j.readyAt, false))
+	}
+	for _, member := range parked {
+		var job DeliveryJob
+		if err := json.Unmarshal([]byte(member), &job); err != nil {
+			continue
+		}
+		pending = append(pending, newPendingJob(job, time.Time{}, true))
+	}
+	sortPendingJobs(pending)
+	return pending, nil
+}
+
+func (q *RedisQueue) PurgePending(ctx context.Context, subscriberID string) (int64, error) {
+	queued, err := q.scanJobs(ctx, subscriberMatch(subscriberID), func(job DeliveryJob, _ time.Time) bool {
+		return job.SubscriberID == subscriberID
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// A job claimed since the scan is no longer in the queue and ZREM skips it
+	var removed, parked *redis.IntCmd
+	_, err = q.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if len(queued) > 0 {
+			removed = pipe.ZRem(ctx, DeliveryQueueKey, scannedMembers(queued)...)
+		}
+		parked = pipe.LLen(ctx, ParkedJobsKey(subscriberID))
+		pipe.Del(ctx, ParkedJobsKey(subscriberID))
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("purging pending jobs: %w", err)
+	}
+
+	n := parked.Val()
+	if removed != nil {
+		n += removed.Val()
+	}
+	return n, nil
+}
+
+func (q *RedisQueue) Inspect(ctx context.Context, now time.Time) (QueueStats, error) {
+	b := newQueueStatsBuilder(now)
+	_, err := q.scanJobs(ctx, "", func(job DeliveryJob, readyAt time.Time) bool {
+		b.add(job, readyAt)
+		return false
+	})
+	if err != nil {
+		return QueueStats{}, err
+	}
+
+	paused, err := q.client.ZRange(ctx, PausedSubscribersKey, 0, -1).Result()
+	if err != nil {
+		return QueueStats{}, fmt.Errorf("reading paused subscribers: %w", err)
+	}
+	if len(paused) > 0 {
+		pipe := q.client.Pipeline()
+		lens := make([]*redis.IntCmd, len(paused))
+		for i, id := range paused {
+			lens[i] = pipe.LLen(ctx, ParkedJobsKey(id))
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			return QueueStats{}, fmt.Errorf("counting parked jobs: %w", err)
+		}
+		for i, id := range paused {
+			b.addParked(id, lens[i].Val())
+		}
+	}
+	return b.build(), nil
+}
+
+func (q *RedisQueue) PurgeJobs(ctx context.Context, filter JobFilter, now time.Time) (int64, error) {
+	match := ""
+	if filter.SubscriberID != "" {
+		match = subscriberMatch(filter.SubscriberID)
+	}
+	queued, err := q.scanJobs(ctx, match, func(job DeliveryJob, readyAt time.Time) bool {
+		return filter.matches(job, readyAt, now)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var removed int64
+	for start := 0; start < len(queued); start += purgeBatchSize {
+		end := min(start+purgeBatchSize, len(queued))
+		n, err := q.client.ZRem(ctx, DeliveryQueueKey, scannedMembers(queued[start:end])...).Result()
+		if err != nil {
+			return removed, fmt.Errorf("purging jobs: %w", err)
+		}
+		removed += n
+	}
+	return removed, nil
+}
+
+// purgeBatchSize caps how many members one ZREM removes, so a large purge
+// doesn't block Redis.
+const purgeBatchSize = 1000
+
+// scannedJob is a delivery queue member found by scanJobs.
+type scannedJob struct {
+	member  string
+	job     DeliveryJob
+	readyAt time.Time
+}
+
+// scanJobs walks the delivery queue with ZSCAN and returns the jobs keep
+// accepts. A non-empty match narrows the scan to members matching that Redis
+// glob pattern before they are decoded. Members ZSCAN returns more than once
+// are only considered once.
+func (q *RedisQueue) scanJobs(ctx context.Context, match string, keep func(job DeliveryJob, readyAt time.Time) bool) ([]scannedJob, error) {
+	seen := make(map[string]bool)
+	var jobs []scannedJob
+	var cursor uint64
+	for {
+		vals, next, err := q.client.ZScan(ctx, DeliveryQueueKey, cursor, match, 1000).Result()
+		if err != nil {
+			return nil, fmt.Errorf("scanning delivery queue: %w", err)
+		}
+		for i := 0; i+1 < len(vals); i += 2 {
+			member := vals[i]
+			if seen[member] {
+				continue
+			}
+			seen[member] = true
+
+			var job DeliveryJob
+			score, err := strconv.ParseFloat(vals[i+1], 64)
+			if err != nil || json.Unmarshal([]byte(member), &job) != nil {
+				continue
+			}
+			readyAt := time.UnixMicro(int64(score))
+			if keep(job, readyAt) {
+				jobs = append(jobs, scannedJob{member: member, job: job, readyAt: readyAt})
+			}
+		}
+		if next == 0 {
+			return jobs, nil
+		}
+		cursor = next
+	}
+}
+
+func scannedMembers(jobs []scannedJob) []interface{} {
+	members := make([]interface{}, len(jobs))
+	for i, j := range jobs {
+		members[i] = j.member
+	}
+	return members
+}
+
+// subscriberMatch returns a ZSCAN pattern for the queue members that mention
+// a subscriber's ID. It can also match jobs whose payload contains the ID, so
+// callers still check each decoded job's SubscriberID.
+func subscriberMatch(subscriberID string) string {
+	idJSON, _ := json.Marshal(subscriberID)
+	return "*" + globEscaper.Replace(`"subscriber_id":`+string(idJSON)) + "*"
+}
+
+// globEscaper escapes the characters Redis glob patterns treat specially.
+var globEscaper = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`)
+
+// formatSeconds renders a duration as seconds with millisecond precision,
+// never below 1ms (0 would block forever).
+func formatSeconds(dur time.Duration) string {
+	if dur < time.Millisecond {
+		dur = time.Millisecond
+	}
+	return fmt.Sprintf("%.3f", dur.Seconds())
+}
+
+// DeliveryQueueNotifyKey is a single-element Redis list used to wake idle
+// dispatchers when new work is queued. Dispatchers block on it with BLPOP
+// instead of polling the sorted set on a fixed interval.
+const DeliveryQueueNotifyKey = "delivery_queue:notify"
+
+// ProbeQueueKey is a sorted set indexing the probe jobs in the delivery
+// queue by ready time, so claims can take them first. The jobs themselves
+// stay in the delivery queue, where purges and inspection see them; index
+// entries whose job is gone are dropped when they come due.
+const ProbeQueueKey = "delivery_queue:probes"
+
+// EnqueueJob adds a job to the delivery queue to become ready at the given
+// time and wakes any blocked dispatchers. Probe jobs are also indexed in
+// ProbeQueueKey.
+func EnqueueJob(ctx context.Context, client redis.Cmdable, job DeliveryJob, at time.Time) error {
+	jobBytes, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("marshaling job: %w", err)
+	}
+
+	z := redis.Z{Score: float64(at.UnixMicro()), Member: string(jobBytes)}
+	pipe := client.TxPipeline()
+	pipe.ZAdd(ctx, DeliveryQueueKey, z)
+	if job.Probe {
+		pipe.ZAdd(ctx, ProbeQueueKey, z)
+	}
+	NotifyDispatchers(ctx, pipe)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("queuing job: %w", err)
+	}
+	return nil
+}
+
+// PausedSubscribersKey is a sorted set of paused subscriber IDs, scored by
+// when each pause ends in Unix microseconds, or 0 for pauses without an end.
+const PausedSubscribersKey = "paused_subscribers"
+
+// ParkedJobsKey returns the Redis list holding a paused subscriber's jobs.
+func ParkedJobsKey(subscriberID string) string {
+	return "parked:" + subscriberID
+}
+
+// parkScript appends ARGV[3] to the parked list (KEYS[2]) if subscriber
+// ARGV[1] is in the paused set (KEYS[1]) and its pause has not ended by
+// ARGV[2]. Checking and parking in one step means a job can't be parked
+// after a concurrent Resume has already flushed the list. Returns 1 if the
+// job was parked.
+var parkScript = redis.NewScript(`
+local score = redis.call('ZSCORE', KEYS[1], ARGV[1])
+if not score then
+    return 0
+end
+local untilTime = tonumber(score)
+if untilTime > 0 and untilTime <= tonumber(ARGV[2]) then
+    return 0
+end
+redis.call('RPUSH', KEYS[2], ARGV[3])
+return 1
+`)
+
+// resumeScript removes subscriber ARGV[1] from the paused set (KEYS[1]) and
+// moves its parked jobs (KEYS[2]) onto the delivery queue (KEYS[3]), ready at
+// ARGV[2], waking dispatchers through KEYS[4]. Returns the number of jobs
+// moved.
+var resumeScript = redis.NewScript(`
+redis.call('ZREM', KEYS[1], ARGV[1])
+local jobs = redis.call('LRANGE', KEYS[2], 0, -1)
+for _, job in ipairs(jobs) do
+    redis.call('ZADD', KEYS[3], ARGV[2], job)
+end
+redis.call('DEL', KEYS[2])
+if #jobs > 0 then
+    redis.call('LPUSH', KEYS[4], 1)
+    redis.call('LTRIM', KEYS[4], 0, 0)
+end
+return #jobs
+`)
+
+// NotifyDispatchers queues a wake-up signal on the pipeline. The notify list
+// is trimmed to one element so it never grows while dispatchers are busy.
+func NotifyDispatchers(ctx context.Context, pipe redis.Pipeliner) {
+	pipe.LPush(ctx, DeliveryQueueNotifyKey, 1)
+	pipe.LTrim(ctx, DeliveryQueueNotifyKey, 0, 0)
+}
+
+// claimReadyScript atomically moves up to ARGV[2] jobs whose score is
+// <= ARGV[1] from the queue (KEYS[1]) into the caller's claims set (KEYS[2]),
+// so concurrent dispatchers never claim the same job and a crashed instance's
+// jobs can be recovered. Returns a flat list of member, score pairs.
+//
+// Due probes indexed in KEYS[3] are taken first, whatever their place in the
+// queue. When more than the rest of ARGV[2] of the oldest ARGV[3] ready jobs
+// are due, they are taken round-robin across subscribers, in the order of
+// each subscriber's oldest job, so one subscriber's backlog can't fill every
+// batch. The first job after the probes is always the oldest.
+var claimReadyScript = redis.NewScript(`
+local claimed = {}
+local probes = redis.call('ZRANGEBYSCORE', KEYS[3], '-inf', ARGV[1], 'LIMIT', 0, ARGV[2])
+for _, member in ipairs(probes) do
+    redis.call('ZREM', KEYS[3], member)
+    local score = redis.call('ZSCORE', KEYS[1], member)
+    if score then
+        redis.call('ZREM', KEYS[1], member)
+        claimed[#claimed+1] = member
+        claimed[#claimed+1] = score
+    end
+end
+local limit = 2 * tonumber(ARGV[2])
+local want = limit - #claimed
+local jobs = {}
+if want > 0 then
+    jobs = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'WITHSCORES', 'LIMIT', 0, ARGV[3])
+end
+if #jobs <= want then
+    for _, v in ipairs(jobs) do
+        claimed[#claimed+1] = v
+    end
+else
+    local order, groups = {}, {}
+    for i = 1, #jobs, 2 do
+        local sub = string.match(jobs[i], '"subscriber_id":"(.-)"') or ''
+        local group = groups[sub]
+        if not group then
+            group = {}
+            groups[sub] = group
+            order[#order+1] = group
+        end
+        group[#group+1] = i
+    end
+    local round = 1
+    while #claimed < limit do
+        for _, group in ipairs(order) do
+            local i = group[round]
+            if i and #claimed < limit then
+                claimed[#claimed+1] = jobs[i]
+                claimed[#claimed+1] = jobs[i+1]
+            end
+        end
+        round = round + 1
+    end
+end
+for i = 1, #claimed, 2 do
+    redis.call('ZREM', KEYS[1], claimed[i])
+    redis.call('ZADD', KEYS[2], claimed[i+1], claimed[i])
+end
+return claimed
+`)
+
+// ClaimedJob is a raw job removed from the queue along with the time it was
+// scheduled to run.
+type ClaimedJob struct {
+	Member  string
+	ReadyAt time.Time
+}
+
+// ClaimReadyJobs atomically moves up to limit raw jobs that are due at or
+// before now into claimsKey and returns them. Due probe jobs come first; the
+// rest are picked round-robin across subscribers from the oldest window
+// ready jobs, and a window no larger than limit claims strictly oldest
+// first. Each job must be acknowledged with Cluster.Ack once delivery has
+// finished.
+func ClaimReadyJobs(ctx context.Context, client redis.Scripter, claimsKey string, now time.Time, limit, window int64) ([]ClaimedJob, error) {
+	keys := []string{DeliveryQueueKey, claimsKey, ProbeQueueKey}
+	flat, err := claimReadyScript.Run(ctx, client, keys, now.UnixMicro(), limit, max(limit, window)).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]ClaimedJob, 0, len(flat)/2)
+	for i := 0; i+1 < len(flat); i += 2 {
+		score, err := strconv.ParseFloat(flat[i+1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing job score: %w", err)
+		}
+		jobs = append(jobs, ClaimedJob{
+			Member:  flat[i],
+			ReadyAt: time.UnixMicro(int64(score)),
+		})
+	}
+	return jobs, nil
+}
+
+// NextJobAt returns when the earliest queued job becomes ready.
+// ok is false when the queue is empty.
+func NextJobAt(ctx context.Context, client redis.Cmdable) (at time.Time, ok bool, err error) {
+	results, err := client.ZRangeWithScores(ctx, DeliveryQueueKey, 0, 0).Result()
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	if len(results) == 0 {
+		return time.Time{}, false, nil
+	}
+	return time.UnixMicro(int64(results[0].Score)), true, nil
+}
+
+// ReadyJobCount returns how many queued jobs are due at or before now,
+// excluding retries scheduled for later.
+func ReadyJobCount(ctx context.Context, client redis.Cmdable, now time.Time) (int64, error) {
+	return client.ZCount(ctx, DeliveryQueueKey, "-inf", strconv.FormatInt(now.UnixMicro(), 10)).Result()
+}
@@ -0,0 +1,117 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// QueueDepthSamplesKey is a sorted set of periodic delivery queue depth
+// samples, scored by sample time in Unix milliseconds. Members are
+// "<unix_ms>:<depth>" so each sample is unique.
+const QueueDepthSamplesKey = "metrics:queue_depth"
+
+// QueueDepthSample is the delivery queue depth at a point in time.
+type QueueDepthSample struct {
+	At    time.Time `json:"at"`
+	Depth int64     `json:"depth"`
+}
+
+// QueueDepthSampler records the delivery queue depth to Redis on a fixed
+// interval so the dashboard can chart it. Samples are aligned to the interval
+// and guarded by a per-slot lock, so running several instances still records
+// one sample per slot.
+type QueueDepthSampler struct {
+	client    *redis.Client
+	queue     Queue
+	interval  time.Duration
+	retention time.Duration
+	logger    *slog.Logger
+}
+
+func NewQueueDepthSampler(client *redis.Client, queue Queue, logger *slog.Logger) *QueueDepthSampler {
+	return &QueueDepthSampler{
+		client:    client,
+		queue:     queue,
+		interval:  15 * time.Second,
+		retention: 7 * 24 * time.Hour,
+		logger:    logger,
+	}
+}
+
+// Start samples the queue depth on every interval until the context is
+// cancelled.
+func (s *QueueDepthSampler) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := s.sample(ctx, now); err != nil && ctx.Err() == nil {
+				s.logger.Error("failed to sample queue depth", "error", err)
+			}
+		}
+	}
+}
+
+func (s *QueueDepthSampler) sample(ctx context.Context, now time.Time) error {
+	slot := now.Truncate(s.interval)
+	lockKey := fmt.Sprintf("%s:lock:%d", QueueDepthSamplesKey, slot.UnixMilli())
+
+	acquired, err := s.client.SetNX(ctx, lockKey, 1, s.interval).Result()
+	if err != nil || !acquired {
+		return err
+	}
+
+	depth, err := s.queue.Depth(ctx)
+	if err != nil {
+		return fmt.Errorf("reading queue depth: %w", err)
+	}
+
+	ms := slot.UnixMilli()
+	pipe := s.client.Pipeline()
+	pipe.ZAdd(ctx, QueueDepthSamplesKey, redis.Z{
+		Score:  float64(ms),
+		Member: fmt.Sprintf("%d:%d", ms, depth),
+	})
+	pipe.ZRemRangeByScore(ctx, QueueDepthSamplesKey, "-inf", strconv.FormatInt(slot.Add(-s.retention).UnixMilli(), 10))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("recording queue depth sample: %w", err)
+	}
+	return nil
+}
+
+// QueueDepthSamples returns recorded queue depth samples taken at or after
+// since, oldest first.
+func QueueDepthSamples(ctx context.Context, client redis.Cmdable, since time.Time) ([]QueueDepthSample, error) {
+	members, err := client.ZRangeByScore(ctx, QueueDepthSamplesKey, &redis.ZRangeBy{
+		Min: strconv.FormatInt(since.UnixMilli(), 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("reading queue depth samples: %w", err)
+	}
+
+	samples := make([]QueueDepthSample, 0, len(members))
+	for _, m := range members {
+		ts, depth, ok := strings.Cut(m, ":")
+		if !ok {
+			continue
+		}
+		ms, err1 := strconv.ParseInt(ts, 10, 64)
+		d, err2 := strconv.ParseInt(depth, 10, 64)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		samples = append(samples, QueueDepthSample{At: time.UnixMilli(ms).UTC(), Depth: d})
+	}
+	return samples, nil
+}
@@ -0,0 +1,45 @@
+package engine
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestQueueDepthSampler_OneSamplePerSlot(t *testing.T) {
+	client := setupTestQueue(t)
+	ctx := context.Background()
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	for i := 0; i < 3; i++ {
+		EnqueueJob(ctx, client, DeliveryJob{EventID: "evt", Attempt: i}, time.Now())
+	}
+
+	// Two instances sampling the same slot record it once
+	a := NewQueueDepthSampler(client, NewRedisQueue(client, nil), logger)
+	b := NewQueueDepthSampler(client, NewRedisQueue(client, nil), logger)
+	now := time.Now()
+	if err := a.sample(ctx, now); err != nil {
+		t.Fatalf("sample failed: %v", err)
+	}
+	if err := b.sample(ctx, now); err != nil {
+		t.Fatalf("sample failed: %v", err)
+	}
+	a.sample(ctx, now.Add(a.interval))
+
+	samples, err := QueueDepthSamples(ctx, client, now.Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("QueueDepthSamples failed: %v", err)
+	}
+	if len(samples) != 2 {
+		t.Fatalf("expected 2 samples, got %d", len(samples))
+	}
+	if samples[0].Depth != 3 {
+		t.Errorf("depth = %d, want 3", samples[0].Depth)
+	}
+	if !samples[0].At.Equal(now.Truncate(a.interval)) {
+		t.Errorf("sample at %v, want slot start %v", samples[0].At, now.Truncate(a.interval))
+	}
+}
@@ -0,0 +1,112 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+)
+
+// FallbackQueue is a Queue that saves the jobs it fails to enqueue, e.g.
+// while Redis is unreachable, to the database's pending_jobs table, and
+// queues them again once it can. Retries and deferrals are enqueued after
+// their claim is released, so without it a failed write loses the job.
+//
+// Publish needs no fallback: a fan-out that can't be queued stays in the
+// outbox until the outbox relay gets it through.
+type FallbackQueue struct {
+	Queue
+	store     store.PendingJobStore
+	logger    *slog.Logger
+	interval  time.Duration
+	batchSize int
+}
+
+func NewFallbackQueue(q Queue, s store.PendingJobStore, logger *slog.Logger) *FallbackQueue {
+	return &FallbackQueue{
+		Queue:     q,
+		store:     s,
+		logger:    logger,
+		interval:  5 * time.Second,
+		batchSize: 500,
+	}
+}
+
+// Enqueue queues a job, or saves it to be queued later if the queue fails.
+// It only returns an error if the job could be neither queued nor saved.
+func (q *FallbackQueue) Enqueue(ctx context.Context, job DeliveryJob, at time.Time) error {
+	err := q.Queue.Enqueue(ctx, job, at)
+	if err == nil {
+		return nil
+	}
+
+	data, merr := json.Marshal(job)
+	if merr != nil {
+		return errors.Join(err, fmt.Errorf("marshaling job: %w", merr))
+	}
+	// Saved even if ctx has ended, since the job is lost otherwise
+	serr := q.store.SavePendingJob(context.WithoutCancel(ctx), store.PendingQueueJob{
+		EventID:      job.EventID,
+		SubscriberID: job.SubscriberID,
+		Job:          data,
+		ReadyAt:      at,
+		LastError:    err.Error(),
+	})
+	if serr != nil {
+		return errors.Join(err, serr)
+	}
+
+	q.logger.Warn("queue unavailable, saved job to the database to queue later",
+		"event_id", job.EventID,
+		"subscriber_id", job.SubscriberID,
+		"error", err,
+	)
+	return nil
+}
+
+// Start queues saved jobs every interval until the context is cancelled.
+func (q *FallbackQueue) Start(ctx context.Context) {
+	ticker := time.NewTicker(q.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		n, err := q.RunOnce(ctx)
+		if n > 0 {
+			q.logger.Info("queued saved jobs", "jobs", n)
+		}
+		if err != nil && ctx.Err() == nil {
+			q.logger.Warn("queueing saved jobs failed, will retry", "error", err)
+		}
+	}
+}
+
+// RunOnce queues saved jobs in batches at their original ready time until
+// none are left or the queue fails. It returns how many were queued.
+func (q *FallbackQueue) RunOnce(ctx context.Context) (int, error) {
+	var total int
+	for {
+		n, err := q.store.DrainPendingJobs(ctx, q.batchSize, func(saved store.PendingQueueJob) error {
+			var job DeliveryJob
+			if err := json.Unmarshal(saved.Job, &job); err != nil {
+				// A job that can't be decoded never will be; drop it
+				q.logger.Error("discarding undecodable saved job", "id", saved.ID, "error", err)
+				return nil
+			}
+			return q.Queue.Enqueue(ctx, job, saved.ReadyAt)
+		})
+		total += n
+		if err != nil || n < q.batchSize {
+			return total, err
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+)
+
+// flakyQueue is a MemoryQueue whose Enqueue fails while down is set.
+type flakyQueue struct {
+	*MemoryQueue
+	down bool
+}
+
+func (q *flakyQueue) Enqueue(ctx context.Context, job DeliveryJob, at time.Time) error {
+	if q.down {
+		return errors.New("connection refused")
+	}
+	return q.MemoryQueue.Enqueue(ctx, job, at)
+}
+
+// pendingJobs is an in-memory store.PendingJobStore.
+type pendingJobs struct {
+	jobs   []store.PendingQueueJob
+	nextID int64
+}
+
+func (s *pendingJobs) SavePendingJob(ctx context.Context, job store.PendingQueueJob) error {
+	s.nextID++
+	job.ID = s.nextID
+	s.jobs = append(s.jobs, job)
+	return nil
+}
+
+func (s *pendingJobs) DrainPendingJobs(ctx context.Context, limit int, enqueue func(store.PendingQueueJob) error) (int, error) {
+	var n int
+	for len(s.jobs) > 0 && n < limit {
+		if err := enqueue(s.jobs[0]); err != nil {
+			return n, err
+		}
+		s.jobs = s.jobs[1:]
+		n++
+	}
+	return n, nil
+}
+
+func (s *pendingJobs) CountPendingJobs(ctx context.Context) (int64, error) {
+	return int64(len(s.jobs)), nil
+}
+
+func TestFallbackQueue_SavesAndReplaysFailedEnqueues(t *testing.T) {
+	ctx := context.Background()
+	inner := &flakyQueue{MemoryQueue: NewMemoryQueue(), down: true}
+	saved := &pendingJobs{}
+	q := NewFallbackQueue(inner, saved, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	q.batchSize = 2
+
+	retryAt := time.Now().Add(time.Minute)
+	for _, id := range []string{"evt-1", "evt-2", "evt-3"} {
+		if err := q.Enqueue(ctx, DeliveryJob{EventID: id, SubscriberID: "sub-1", Attempt: 2}, retryAt); err != nil {
+			t.Fatalf("Enqueue while down: %v", err)
+		}
+	}
+	if len(saved.jobs) != 3 || saved.jobs[0].LastError != "connection refused" {
+		t.Fatalf("saved jobs = %+v, want all 3 with the queue error", saved.jobs)
+	}
+
+	// Still down: nothing is replayed or lost
+	if n, err := q.RunOnce(ctx); n != 0 || err == nil {
+		t.Errorf("RunOnce while down = %d, %v, want 0 and an error", n, err)
+	}
+	if len(saved.jobs) != 3 {
+		t.Errorf("%d jobs left saved, want 3", len(saved.jobs))
+	}
+
+	inner.down = false
+	if n, err := q.RunOnce(ctx); n != 3 || err != nil {
+		t.Fatalf("RunOnce = %d, %v, want all 3 queued across batches", n, err)
+	}
+	if len(saved.jobs) != 0 {
+		t.Errorf("%d jobs left saved, want none", len(saved.jobs))
+	}
+
+	// Replayed jobs keep their ready time and attempt number
+	if next, ok, _ := inner.NextJobAt(ctx); !ok || !next.Equal(retryAt) {
+		t.Errorf("NextJobAt = %v, %v, want the original retry time %v", next, ok, retryAt)
+	}
+	claimed, _ := inner.Claim(ctx, retryAt, 10)
+	if len(claimed) != 3 {
+		t.Fatalf("claimed %d jobs, want 3", len(claimed))
+	}
+	var job DeliveryJob
+	if err := json.Unmarshal([]byte(claimed[0].Member), &job); err != nil || job.Attempt != 2 {
+		t.Errorf("replayed job = %+v, %v, want attempt 2", job, err)
+	}
+}
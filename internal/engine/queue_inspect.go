@@ -0,0 +1,163 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+)
+
+// Job states used by QueueStats and JobFilter. A ready job is due and waits
+// only for a dispatcher; a scheduled job, typically a retry, is not due yet.
+const (
+	JobStateReady     = "ready"
+	JobStateScheduled = "scheduled"
+)
+
+// QueueStats is a snapshot of the delivery queue. Jobs a worker has already
+// claimed are not counted.
+type QueueStats struct {
+	Depth     int64 `json:"depth"`
+	Ready     int64 `json:"ready"`
+	Scheduled int64 `json:"scheduled"`
+	Parked    int64 `json:"parked"`
+	// OldestReadyAt is when the longest-waiting due job became ready, and
+	// OldestAgeSeconds how long ago that was. Both are empty when no job is
+	// due.
+	OldestReadyAt    *time.Time             `json:"oldest_ready_at"`
+	OldestAgeSeconds float64                `json:"oldest_age_seconds"`
+	Subscribers      []SubscriberQueueStats `json:"subscribers"`
+}
+
+// SubscriberQueueStats breaks the queue down for one subscriber.
+type SubscriberQueueStats struct {
+	SubscriberID string `json:"subscriber_id"`
+	Ready        int64  `json:"ready"`
+	Scheduled    int64  `json:"scheduled"`
+	Parked       int64  `json:"parked"`
+}
+
+// Total is the number of jobs waiting for the subscriber.
+func (s SubscriberQueueStats) Total() int64 {
+	return s.Ready + s.Scheduled + s.Parked
+}
+
+// queueStatsBuilder accumulates QueueStats one job at a time, so both queue
+// implementations count the same way.
+type queueStatsBuilder struct {
+	now   time.Time
+	stats QueueStats
+	bySub map[string]*SubscriberQueueStats
+}
+
+func newQueueStatsBuilder(now time.Time) *queueStatsBuilder {
+	return &queueStatsBuilder{now: now, bySub: make(map[string]*SubscriberQueueStats)}
+}
+
+func (b *queueStatsBuilder) subscriber(id string) *SubscriberQueueStats {
+	s, ok := b.bySub[id]
+	if !ok {
+		s = &SubscriberQueueStats{SubscriberID: id}
+		b.bySub[id] = s
+	}
+	return s
+}
+
+func (b *queueStatsBuilder) add(job DeliveryJob, readyAt time.Time) {
+	sub := b.subscriber(job.SubscriberID)
+	b.stats.Depth++
+	if readyAt.After(b.now) {
+		b.stats.Scheduled++
+		sub.Scheduled++
+		return
+	}
+
+	b.stats.Ready++
+	sub.Ready++
+	if b.stats.OldestReadyAt == nil || readyAt.Before(*b.stats.OldestReadyAt) {
+		oldest := readyAt
+		b.stats.OldestReadyAt = &oldest
+	}
+}
+
+func (b *queueStatsBuilder) addParked(subscriberID string, n int64) {
+	if n == 0 {
+		return
+	}
+	b.stats.Parked += n
+	b.subscriber(subscriberID).Parked += n
+}
+
+// build returns the stats with subscribers ordered by how many jobs wait for
+// them, most first.
+func (b *queueStatsBuilder) build() QueueStats {
+	stats := b.stats
+	if stats.OldestReadyAt != nil {
+		stats.OldestAgeSeconds = b.now.Sub(*stats.OldestReadyAt).Seconds()
+	}
+
+	stats.Subscribers = make([]SubscriberQueueStats, 0, len(b.bySub))
+	for _, s := range b.bySub {
+		stats.Subscribers = append(stats.Subscribers, *s)
+	}
+	sort.Slice(stats.Subscribers, func(i, j int) bool {
+		a, b := stats.Subscribers[i], stats.Subscribers[j]
+		if a.Total() != b.Total() {
+			return a.Total() > b.Total()
+		}
+		return a.SubscriberID < b.SubscriberID
+	})
+	return stats
+}
+
+// JobFilter selects queued jobs for PurgeJobs. Empty fields match every job.
+type JobFilter struct {
+	SubscriberID string
+	// EventType is an exact event type or a pattern, as in subscriptions.
+	EventType string
+	// State is JobStateReady or JobStateScheduled.
+	State string
+	// MinAttempt matches jobs on this attempt or later, e.g. 2 for retries.
+	MinAttempt int
+}
+
+// Validate checks the filter's event type pattern and state.
+func (f JobFilter) Validate() error {
+	if f.EventType != "" {
+		if err := domain.ValidateEventPattern(f.EventType); err != nil {
+			return err
+		}
+	}
+	if f.State != "" && f.State != JobStateReady && f.State != JobStateScheduled {
+		return fmt.Errorf("state must be %q or %q", JobStateReady, JobStateScheduled)
+	}
+	if f.MinAttempt < 0 {
+		return fmt.Errorf("min_attempt must not be negative")
+	}
+	return nil
+}
+
+// IsEmpty reports whether the filter matches every job.
+func (f JobFilter) IsEmpty() bool {
+	return f == JobFilter{}
+}
+
+func (f JobFilter) matches(job DeliveryJob, readyAt, now time.Time) bool {
+	if f.SubscriberID != "" && job.SubscriberID != f.SubscriberID {
+		return false
+	}
+	if f.EventType != "" && !domain.MatchEventType(f.EventType, job.EventType) {
+		return false
+	}
+	if job.Attempt < f.MinAttempt {
+		return false
+	}
+	switch f.State {
+	case JobStateReady:
+		return !readyAt.After(now)
+	case JobStateScheduled:
+		return readyAt.After(now)
+	}
+	return true
+}
@@ -0,0 +1,109 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryQueue_Inspect(t *testing.T) {
+	q := NewMemoryQueue()
+	ctx := context.Background()
+
+	now := time.Now()
+	q.Enqueue(ctx, DeliveryJob{SubscriberID: "a"}, now.Add(-time.Minute))
+	q.Enqueue(ctx, DeliveryJob{SubscriberID: "a"}, now.Add(-time.Second))
+	q.Enqueue(ctx, DeliveryJob{SubscriberID: "b", Attempt: 2}, now.Add(time.Minute))
+	q.Pause(ctx, "c", time.Time{})
+	q.ParkIfPaused(ctx, DeliveryJob{SubscriberID: "c"})
+
+	stats, err := q.Inspect(ctx, now)
+	if err != nil {
+		t.Fatalf("Inspect failed: %v", err)
+	}
+	if stats.Depth != 3 || stats.Ready != 2 || stats.Scheduled != 1 || stats.Parked != 1 {
+		t.Errorf("stats = %+v, want depth 3 (2 ready, 1 scheduled), 1 parked", stats)
+	}
+	if stats.OldestAgeSeconds != 60 || !stats.OldestReadyAt.Equal(now.Add(-time.Minute)) {
+		t.Errorf("oldest = %v (%vs), want the job due a minute ago", stats.OldestReadyAt, stats.OldestAgeSeconds)
+	}
+
+	want := []SubscriberQueueStats{
+		{SubscriberID: "a", Ready: 2},
+		{SubscriberID: "b", Scheduled: 1},
+		{SubscriberID: "c", Parked: 1},
+	}
+	if len(stats.Subscribers) != len(want) {
+		t.Fatalf("subscribers = %+v, want %+v", stats.Subscribers, want)
+	}
+	for i := range want {
+		if stats.Subscribers[i] != want[i] {
+			t.Errorf("subscribers[%d] = %+v, want %+v", i, stats.Subscribers[i], want[i])
+		}
+	}
+}
+
+func TestJobFilter(t *testing.T) {
+	now := time.Now()
+	job := DeliveryJob{SubscriberID: "a", EventType: "order.created", Attempt: 2}
+
+	tests := []struct {
+		filter  JobFilter
+		readyAt time.Time
+		want    bool
+	}{
+		{JobFilter{}, now, true},
+		{JobFilter{SubscriberID: "b"}, now, false},
+		{JobFilter{EventType: "order.*"}, now, true},
+		{JobFilter{EventType: "user.**"}, now, false},
+		{JobFilter{State: JobStateReady}, now, true},
+		{JobFilter{State: JobStateReady}, now.Add(time.Minute), false},
+		{JobFilter{State: JobStateScheduled}, now.Add(time.Minute), true},
+		{JobFilter{MinAttempt: 2}, now, true},
+		{JobFilter{MinAttempt: 3}, now, false},
+	}
+	for _, tt := range tests {
+		if got := tt.filter.matches(job, tt.readyAt, now); got != tt.want {
+			t.Errorf("%+v matches = %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+
+	if err := (JobFilter{State: "running"}).Validate(); err == nil {
+		t.Error("Validate accepted an unknown state")
+	}
+	if err := (JobFilter{EventType: "order.*x"}).Validate(); err == nil {
+		t.Error("Validate accepted an invalid pattern")
+	}
+}
+
+func TestRedisQueue_InspectAndPurgeJobs(t *testing.T) {
+	client := setupTestQueue(t)
+	q := NewRedisQueue(client, nil)
+	ctx := context.Background()
+
+	now := time.Now()
+	q.Enqueue(ctx, DeliveryJob{EventID: "1", SubscriberID: "a", EventType: "order.created"}, now.Add(-time.Minute))
+	q.Enqueue(ctx, DeliveryJob{EventID: "2", SubscriberID: "a", EventType: "user.created"}, now)
+	q.Enqueue(ctx, DeliveryJob{EventID: "3", SubscriberID: "b", EventType: "order.created", Attempt: 2}, now.Add(time.Hour))
+	q.Pause(ctx, "b", time.Time{})
+	q.ParkIfPaused(ctx, DeliveryJob{EventID: "4", SubscriberID: "b"})
+
+	stats, err := q.Inspect(ctx, now)
+	if err != nil {
+		t.Fatalf("Inspect failed: %v", err)
+	}
+	if stats.Depth != 3 || stats.Ready != 2 || stats.Scheduled != 1 || stats.Parked != 1 || len(stats.Subscribers) != 2 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	n, err := q.PurgeJobs(ctx, JobFilter{EventType: "order.*"}, now)
+	if err != nil || n != 2 {
+		t.Fatalf("PurgeJobs = %d, %v, want 2", n, err)
+	}
+	if depth := client.ZCard(ctx, DeliveryQueueKey).Val(); depth != 1 {
+		t.Errorf("queue depth after purge = %d, want 1", depth)
+	}
+	if parked := client.LLen(ctx, ParkedJobsKey("b")).Val(); parked != 1 {
+		t.Errorf("parked jobs after purge = %d, want them left alone", parked)
+	}
+}
@@ -0,0 +1,309 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func setupTestQueue(t testing.TB) *redis.Client {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestClaimReadyJobs_OnlyClaimsDueJobs(t *testing.T) {
+	client := setupTestQueue(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	EnqueueJob(ctx, client, DeliveryJob{EventID: "due"}, now.Add(-time.Second))
+	EnqueueJob(ctx, client, DeliveryJob{EventID: "future"}, now.Add(time.Hour))
+
+	claimed, err := ClaimReadyJobs(ctx, client, ClaimsKey("test"), now, 10, 0)
+	if err != nil {
+		t.Fatalf("ClaimReadyJobs failed: %v", err)
+	}
+	if len(claimed) != 1 {
+		t.Fatalf("expected 1 claimed job, got %d", len(claimed))
+	}
+	if want := now.Add(-time.Second).Truncate(time.Microsecond); !claimed[0].ReadyAt.Equal(want) {
+		t.Errorf("ReadyAt = %v, want %v", claimed[0].ReadyAt, want)
+	}
+
+	// Claimed jobs are removed; the future job stays queued
+	if depth := client.ZCard(ctx, DeliveryQueueKey).Val(); depth != 1 {
+		t.Errorf("expected 1 job left in queue, got %d", depth)
+	}
+	if n := client.ZCard(ctx, ClaimsKey("test")).Val(); n != 1 {
+		t.Errorf("expected 1 job in claims set, got %d", n)
+	}
+}
+
+func TestClaimReadyJobs_SharesBatchAcrossSubscribers(t *testing.T) {
+	client := setupTestQueue(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	for i := 0; i < 20; i++ {
+		EnqueueJob(ctx, client, DeliveryJob{EventID: fmt.Sprintf("hot-%d", i), SubscriberID: "hot"}, now.Add(-time.Minute+time.Duration(i)*time.Millisecond))
+	}
+	EnqueueJob(ctx, client, DeliveryJob{EventID: "quiet", SubscriberID: "quiet"}, now.Add(-time.Second))
+
+	// Oldest first, the hot subscriber's backlog fills the batch
+	strict, err := ClaimReadyJobs(ctx, client, ClaimsKey("strict"), now, 5, 0)
+	if err != nil {
+		t.Fatalf("ClaimReadyJobs failed: %v", err)
+	}
+	if got := claimedSubscribers(t, strict); !reflect.DeepEqual(got, []string{"hot", "hot", "hot", "hot", "hot"}) {
+		t.Errorf("window 0 claimed %v, want only the oldest jobs", got)
+	}
+
+	fair, err := ClaimReadyJobs(ctx, client, ClaimsKey("fair"), now, 5, 100)
+	if err != nil {
+		t.Fatalf("ClaimReadyJobs failed: %v", err)
+	}
+	if got := claimedSubscribers(t, fair); !reflect.DeepEqual(got, []string{"hot", "quiet", "hot", "hot", "hot"}) {
+		t.Errorf("window 100 claimed %v, want the quiet subscriber's job in the batch", got)
+	}
+	if !fair[0].ReadyAt.Equal(now.Add(-time.Minute + 5*time.Millisecond).Truncate(time.Microsecond)) {
+		t.Errorf("first claimed job ready at %v, want the oldest one left", fair[0].ReadyAt)
+	}
+	if depth := client.ZCard(ctx, DeliveryQueueKey).Val(); depth != 11 {
+		t.Errorf("expected 11 jobs left in queue, got %d", depth)
+	}
+}
+
+func TestClaimReadyJobs_ClaimsProbesFirst(t *testing.T) {
+	client := setupTestQueue(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	for i := 0; i < 10; i++ {
+		EnqueueJob(ctx, client, DeliveryJob{EventID: fmt.Sprintf("backlog-%d", i), SubscriberID: "busy"}, now.Add(-time.Minute))
+	}
+	EnqueueJob(ctx, client, DeliveryJob{EventID: "probe", SubscriberID: "down", Probe: true}, now.Add(-time.Second))
+	EnqueueJob(ctx, client, DeliveryJob{EventID: "early-probe", SubscriberID: "other", Probe: true}, now.Add(time.Minute))
+	purged := DeliveryJob{EventID: "purged-probe", SubscriberID: "gone", Probe: true}
+	EnqueueJob(ctx, client, purged, now.Add(-time.Second))
+	member, _ := json.Marshal(purged)
+	client.ZRem(ctx, DeliveryQueueKey, string(member))
+
+	claimed, err := ClaimReadyJobs(ctx, client, ClaimsKey("test"), now, 3, 0)
+	if err != nil {
+		t.Fatalf("ClaimReadyJobs failed: %v", err)
+	}
+	if got := claimedSubscribers(t, claimed); !reflect.DeepEqual(got, []string{"down", "busy", "busy"}) {
+		t.Errorf("claimed %v, want the due probe ahead of the older backlog", got)
+	}
+	if !claimed[0].ReadyAt.Equal(now.Add(-time.Second).Truncate(time.Microsecond)) {
+		t.Errorf("probe ReadyAt = %v", claimed[0].ReadyAt)
+	}
+
+	// The probe not due yet stays indexed; the purged one's entry is dropped
+	if probes := client.ZRange(ctx, ProbeQueueKey, 0, -1).Val(); len(probes) != 1 || !strings.Contains(probes[0], "early-probe") {
+		t.Errorf("probe index = %v, want only the probe not due yet", probes)
+	}
+}
+
+// claimedSubscribers returns the subscriber of each claimed job, in order.
+func claimedSubscribers(t *testing.T, claimed []ClaimedJob) []string {
+	t.Helper()
+	subs := make([]string, len(claimed))
+	for i, c := range claimed {
+		var job DeliveryJob
+		if err := json.Unmarshal([]byte(c.Member), &job); err != nil {
+			t.Fatalf("decoding claimed job: %v", err)
+		}
+		subs[i] = job.SubscriberID
+	}
+	return subs
+}
+
+func TestEnqueueJob_NotifiesDispatchers(t *testing.T) {
+	client := setupTestQueue(t)
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		EnqueueJob(ctx, client, DeliveryJob{EventID: "evt"}, time.Now())
+	}
+
+	// Notify list is capped at a single wake-up token
+	if n := client.LLen(ctx, DeliveryQueueNotifyKey).Val(); n != 1 {
+		t.Errorf("expected 1 notify token, got %d", n)
+	}
+}
+
+func TestNextJobAt(t *testing.T) {
+	client := setupTestQueue(t)
+	ctx := context.Background()
+
+	if _, ok, err := NextJobAt(ctx, client); err != nil || ok {
+		t.Fatalf("empty queue: ok=%v err=%v", ok, err)
+	}
+
+	at := time.Now().Add(5 * time.Second).Truncate(time.Microsecond)
+	EnqueueJob(ctx, client, DeliveryJob{EventID: "later"}, at.Add(time.Minute))
+	EnqueueJob(ctx, client, DeliveryJob{EventID: "sooner"}, at)
+
+	next, ok, err := NextJobAt(ctx, client)
+	if err != nil || !ok {
+		t.Fatalf("NextJobAt: ok=%v err=%v", ok, err)
+	}
+	if !next.Equal(at) {
+		t.Errorf("NextJobAt = %v, want %v", next, at)
+	}
+}
+
+func TestRedisQueue_PauseParksAndResumeFlushes(t *testing.T) {
+	client := setupTestQueue(t)
+	q := NewRedisQueue(client, nil)
+	ctx := context.Background()
+
+	until := time.Now().Add(10 * time.Minute)
+	if err := q.Pause(ctx, "sub-1", until); err != nil {
+		t.Fatalf("Pause failed: %v", err)
+	}
+	for _, id := range []string{"evt-1", "evt-2"} {
+		if parked, err := q.ParkIfPaused(ctx, DeliveryJob{EventID: id, SubscriberID: "sub-1"}); err != nil || !parked {
+			t.Fatalf("ParkIfPaused = %v, %v, want parked", parked, err)
+		}
+	}
+	if parked, _ := q.ParkIfPaused(ctx, DeliveryJob{EventID: "evt-1", SubscriberID: "sub-2"}); parked {
+		t.Error("job for another subscriber was parked")
+	}
+
+	status, err := q.PauseStatus(ctx, "sub-1")
+	if err != nil || !status.Paused || status.ParkedJobs != 2 || status.PausedUntil == nil {
+		t.Fatalf("PauseStatus = %+v, %v", status, err)
+	}
+	if expired, _ := q.ExpiredPauses(ctx, until.Add(time.Second)); len(expired) != 1 {
+		t.Errorf("ExpiredPauses after the pause ends = %v, want [sub-1]", expired)
+	}
+
+	resumed, err := q.Resume(ctx, "sub-1")
+	if err != nil || resumed != 2 {
+		t.Fatalf("Resume = %d, %v, want 2", resumed, err)
+	}
+	if depth := client.ZCard(ctx, DeliveryQueueKey).Val(); depth != 2 {
+		t.Errorf("queue depth after resume = %d, want 2", depth)
+	}
+	if status, _ := q.PauseStatus(ctx, "sub-1"); status.Paused || status.ParkedJobs != 0 {
+		t.Errorf("PauseStatus after resume = %+v", status)
+	}
+}
+
+func TestRedisQueue_PendingJobsAndPurge(t *testing.T) {
+	client := setupTestQueue(t)
+	q := NewRedisQueue(client, nil)
+	ctx := context.Background()
+
+	at := time.Now().Add(time.Minute).Truncate(time.Microsecond)
+	q.Enqueue(ctx, DeliveryJob{EventID: "mine", SubscriberID: "sub-1", Attempt: 3}, at)
+	// Mentions sub-1 only inside its payload
+	q.Enqueue(ctx, DeliveryJob{EventID: "other", SubscriberID: "sub-2", Payload: []byte(`{"subscriber_id":"sub-1"}`)}, at)
+
+	pending, err := q.PendingJobs(ctx, "sub-1")
+	if err != nil {
+		t.Fatalf("PendingJobs failed: %v", err)
+	}
+	if len(pending) != 1 || pending[0].EventID != "mine" || pending[0].Attempt != 3 || !pending[0].NextAttemptAt.Equal(at) {
+		t.Fatalf("pending = %+v, want only the sub-1 job", pending)
+	}
+
+	q.Pause(ctx, "sub-1", time.Time{})
+	q.ParkIfPaused(ctx, DeliveryJob{EventID: "parked", SubscriberID: "sub-1"})
+
+	if n, err := q.PurgePending(ctx, "sub-1"); err != nil || n != 2 {
+		t.Fatalf("PurgePending = %d, %v, want 2", n, err)
+	}
+	if depth := client.ZCard(ctx, DeliveryQueueKey).Val(); depth != 1 {
+		t.Errorf("queue depth after purge = %d, want the other subscriber's job", depth)
+	}
+}
+
+func TestFormatSeconds(t *testing.T) {
+	tests := map[time.Duration]string{
+		0:                      "0.001",
+		250 * time.Millisecond: "0.250",
+		2 * time.Second:        "2.000",
+	}
+	for dur, want := range tests {
+		if got := formatSeconds(dur); got != want {
+			t.Errorf("formatSeconds(%v) = %q, want %q", dur, got, want)
+		}
+	}
+}
+
+// failChunkHook fails every pipeline that queues a job for subscriberID,
+// until it is healed.
+type failChunkHook struct {
+	subscriberID string
+	healed       atomic.Bool
+}
+
+func (h *failChunkHook) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (h *failChunkHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook { return next }
+
+func (h *failChunkHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		for _, cmd := range cmds {
+			if h.healed.Load() {
+				break
+			}
+			if strings.Contains(fmt.Sprint(cmd.Args()...), `"subscriber_id":"`+h.subscriberID+`"`) {
+				err := errors.New("connection reset")
+				for _, c := range cmds {
+					c.SetErr(err)
+				}
+				return err
+			}
+		}
+		return next(ctx, cmds)
+	}
+}
+
+func TestRedisQueue_PublishInChunks(t *testing.T) {
+	client := setupTestQueue(t)
+	ctx := context.Background()
+	q := NewRedisQueue(client, nil)
+	q.SetPublishChunking(3, 2)
+
+	jobs := make([]DeliveryJob, 10)
+	for i := range jobs {
+		jobs[i] = DeliveryJob{EventID: "evt-1", SubscriberID: fmt.Sprintf("sub-%d", i)}
+	}
+	if err := q.Publish(ctx, "evt-1", []byte(`{}`), jobs); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if depth := client.ZCard(ctx, DeliveryQueueKey).Val(); depth != 10 {
+		t.Errorf("expected 10 queued jobs, got %d", depth)
+	}
+
+	// The chunk holding sub-4 (sub-3 to sub-5) fails; the others are queued
+	client.Del(ctx, DeliveryQueueKey)
+	client.AddHook(&failChunkHook{subscriberID: "sub-4"})
+	err := q.Publish(ctx, "evt-1", []byte(`{}`), jobs)
+	var perr *PublishError
+	if !errors.As(err, &perr) {
+		t.Fatalf("expected a PublishError, got %v", err)
+	}
+	if !reflect.DeepEqual(perr.Failed, []string{"sub-3", "sub-4", "sub-5"}) || perr.Total != 10 {
+		t.Errorf("failed = %v of %d", perr.Failed, perr.Total)
+	}
+	if depth := client.ZCard(ctx, DeliveryQueueKey).Val(); depth != 7 {
+		t.Errorf("expected 7 queued jobs, got %d", depth)
+	}
+}
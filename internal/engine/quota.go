@@ -0,0 +1,116 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+// DeliveryQuota counts each subscriber's deliveries per UTC day and calendar
+// month in Redis, for subscribers with a daily or monthly quota. Counters
+// are shared by every instance and expire a day after their window ends. A
+// nil *DeliveryQuota enforces no quotas.
+type DeliveryQuota struct {
+	redisClient *redis.Client
+	logger      *slog.Logger
+	now         func() time.Time
+}
+
+// takeQuotaScript adds ARGV[5] deliveries to the day and month counters in
+// KEYS[1] and KEYS[2], unless one of them has already reached its limit in
+// ARGV[1] or ARGV[2], where 0 is no limit. It returns the index of the
+// exhausted window, or 0 once the deliveries are counted. ARGV[3] and
+// ARGV[4] are the counters' TTLs in seconds.
+var takeQuotaScript = redis.NewScript(`
+for i = 1, 2 do
+    local limit = tonumber(ARGV[i])
+    if limit > 0 and tonumber(redis.call('GET', KEYS[i]) or '0') >= limit then
+        return i
+    end
+end
+for i = 1, 2 do
+    redis.call('INCRBY', KEYS[i], ARGV[5])
+    redis.call('EXPIRE', KEYS[i], ARGV[i + 2])
+end
+return 0
+`)
+
+// quotaGraceSeconds keeps a counter for a day after its window ends, so
+// instances whose clocks are a little behind still find it.
+const quotaGraceSeconds = 24 * 60 * 60
+
+func NewDeliveryQuota(redisClient *redis.Client, logger *slog.Logger) *DeliveryQuota {
+	return &DeliveryQuota{redisClient: redisClient, logger: logger, now: time.Now}
+}
+
+func quotaKeys(subscriberID string, now time.Time) (day, month string) {
+	now = now.UTC()
+	return fmt.Sprintf("quota:%s:day:%s", subscriberID, now.Format("2006-01-02")),
+		fmt.Sprintf("quota:%s:month:%s", subscriberID, now.Format("2006-01"))
+}
+
+// Take counts n deliveries to the subscriber against its quotas. If a quota
+// is used up it counts nothing and returns false with the time its window
+// resets. Deliveries are allowed while the count is under the quota, so a
+// batch can take it past the quota by up to its size. Like the rate
+// limiter, it allows deliveries when Redis fails.
+func (q *DeliveryQuota) Take(ctx context.Context, subscriberID string, daily, monthly, n int) (bool, time.Time) {
+	if q == nil || (daily <= 0 && monthly <= 0) {
+		return true, time.Time{}
+	}
+
+	now := q.now()
+	dayKey, monthKey := quotaKeys(subscriberID, now)
+	dayEnd, monthEnd := domain.DayEnd(now), domain.MonthEnd(now)
+	exhausted, err := takeQuotaScript.Run(ctx, q.redisClient, []string{dayKey, monthKey},
+		max(daily, 0), max(monthly, 0),
+		int(dayEnd.Sub(now).Seconds())+quotaGraceSeconds, int(monthEnd.Sub(now).Seconds())+quotaGraceSeconds, n,
+	).Int()
+	if err != nil {
+		q.logger.Error("delivery quota script failed", "error", err, "subscriber_id", subscriberID)
+		return true, time.Time{}
+	}
+
+	switch exhausted {
+	case 1:
+		return false, dayEnd
+	case 2:
+		return false, monthEnd
+	}
+	return true, time.Time{}
+}
+
+// Usage returns the subscriber's deliveries in the current windows of the
+// quotas it has, or nil if it has none.
+func (q *DeliveryQuota) Usage(ctx context.Context, subscriberID string, daily, monthly int) (*domain.QuotaUsage, error) {
+	if q == nil || (daily <= 0 && monthly <= 0) {
+		return nil, nil
+	}
+
+	now := q.now()
+	dayKey, monthKey := quotaKeys(subscriberID, now)
+	counts, err := q.redisClient.MGet(ctx, dayKey, monthKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("reading delivery quota usage: %w", err)
+	}
+	used := func(v interface{}) int {
+		var n int
+		if s, ok := v.(string); ok {
+			fmt.Sscan(s, &n)
+		}
+		return n
+	}
+
+	usage := &domain.QuotaUsage{}
+	if daily > 0 {
+		usage.Daily = &domain.QuotaWindow{Limit: daily, Used: used(counts[0]), ResetsAt: domain.DayEnd(now)}
+	}
+	if monthly > 0 {
+		usage.Monthly = &domain.QuotaWindow{Limit: monthly, Used: used(counts[1]), ResetsAt: domain.MonthEnd(now)}
+	}
+	return usage, nil
+}
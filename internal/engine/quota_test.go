@@ -0,0 +1,74 @@
+package engine
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func setupTestQuota(t *testing.T, now time.Time) (*DeliveryQuota, *time.Time) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	q := NewDeliveryQuota(client, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	q.now = func() time.Time { return now }
+	return q, &now
+}
+
+func TestDeliveryQuota_DefersUntilDayResets(t *testing.T) {
+	q, now := setupTestQuota(t, time.Date(2026, 3, 31, 22, 0, 0, 0, time.UTC))
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := q.Take(ctx, "sub-1", 3, 0, 1); !ok {
+			t.Fatalf("delivery %d refused under a daily quota of 3", i+1)
+		}
+	}
+	ok, resetAt := q.Take(ctx, "sub-1", 3, 0, 1)
+	if ok || !resetAt.Equal(time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("Take over quota = %v, %v, want refused until midnight UTC", ok, resetAt)
+	}
+	if ok, _ := q.Take(ctx, "sub-2", 3, 0, 1); !ok {
+		t.Error("another subscriber's quota was used up too")
+	}
+
+	*now = now.Add(3 * time.Hour)
+	if ok, _ := q.Take(ctx, "sub-1", 3, 0, 1); !ok {
+		t.Error("delivery refused after the day reset")
+	}
+}
+
+func TestDeliveryQuota_MonthlyQuotaAndUsage(t *testing.T) {
+	q, _ := setupTestQuota(t, time.Date(2026, 12, 15, 12, 0, 0, 0, time.UTC))
+	ctx := context.Background()
+
+	if ok, _ := q.Take(ctx, "sub-1", 100, 10, 8); !ok {
+		t.Fatal("batch refused under quota")
+	}
+	// Still under the quota, so the batch is allowed past it
+	if ok, _ := q.Take(ctx, "sub-1", 100, 10, 5); !ok {
+		t.Fatal("batch refused while under quota")
+	}
+	ok, resetAt := q.Take(ctx, "sub-1", 100, 10, 1)
+	if ok || !resetAt.Equal(time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("Take over monthly quota = %v, %v", ok, resetAt)
+	}
+
+	usage, err := q.Usage(ctx, "sub-1", 100, 10)
+	if err != nil {
+		t.Fatalf("Usage: %v", err)
+	}
+	if usage.Daily.Used != 13 || usage.Monthly.Used != 13 || !usage.Monthly.Exhausted() || usage.Daily.Exhausted() {
+		t.Errorf("usage = %+v / %+v", usage.Daily, usage.Monthly)
+	}
+	if usage, _ := q.Usage(ctx, "sub-1", 0, 0); usage != nil {
+		t.Errorf("usage without quotas = %+v, want nil", usage)
+	}
+}
@@ -0,0 +1,40 @@
+package engine
+
+import (
+	"math/rand/v2"
+	"sync"
+)
+
+// Rand is a source of randomness, such as retry jitter, that is safe for
+// concurrent use. A seeded Rand repeats the same sequence, so tests can
+// assert exact jittered delays. A nil *Rand draws from the process-wide
+// source.
+type Rand struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+// NewRand returns a Rand seeded with seed.
+func NewRand(seed uint64) *Rand {
+	return &Rand{r: rand.New(rand.NewPCG(seed, seed))}
+}
+
+// Int64N returns a number in [0, n). It panics if n <= 0.
+func (r *Rand) Int64N(n int64) int64 {
+	if r == nil {
+		return rand.Int64N(n)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.r.Int64N(n)
+}
+
+// Float64 returns a number in [0.0, 1.0).
+func (r *Rand) Float64() float64 {
+	if r == nil {
+		return rand.Float64()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.r.Float64()
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"sync/atomic"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -13,9 +14,10 @@ import (
 // Uses a sorted set where each member is a unique request ID with a timestamp score.
 // A Lua script atomically cleans expired entries, checks the count, and adds new entries.
 type RateLimiter struct {
-	redisClient *redis.Client
-	logger      *slog.Logger
-	script      *redis.Script
+	redisClient  *redis.Client
+	logger       *slog.Logger
+	script       *redis.Script
+	defaultLimit atomic.Int64
 }
 
 // Lua script for atomic sliding window rate limiting.
@@ -56,13 +58,28 @@ func NewRateLimiter(redisClient *redis.Client, logger *slog.Logger) *RateLimiter
 	}
 }
 
+// SetDefaultLimit sets the per-second limit applied to subscribers without
+// one of their own. 0, the default, leaves them unlimited. It is safe to
+// call while deliveries are running.
+func (rl *RateLimiter) SetDefaultLimit(perSecond int) {
+	rl.defaultLimit.Store(int64(perSecond))
+}
+
 func rlKey(subscriberID string) string {
 	return fmt.Sprintf("rl:%s", subscriberID)
 }
 
+// Reset clears a subscriber's sliding window.
+func (rl *RateLimiter) Reset(ctx context.Context, subscriberID string) error {
+	return rl.redisClient.Del(ctx, rlKey(subscriberID)).Err()
+}
+
 // Allow checks if a delivery to this subscriber is within the rate limit.
 // Returns true if allowed, false if rate limited.
 func (rl *RateLimiter) Allow(ctx context.Context, subscriberID string, limit int) bool {
+	if limit <= 0 {
+		limit = int(rl.defaultLimit.Load())
+	}
 	if limit <= 0 {
 		return true // No rate limit configured
 	}
@@ -60,6 +60,25 @@ func TestRateLimiter_ZeroLimit_AllowsAll(t *testing.T) {
 	}
 }
 
+func TestRateLimiter_DefaultLimitAppliesToUnlimited(t *testing.T) {
+	rl, _ := setupTestRL(t)
+	rl.SetDefaultLimit(2)
+	ctx := context.Background()
+
+	rl.Allow(ctx, "sub-1", 0)
+	rl.Allow(ctx, "sub-1", 0)
+	if rl.Allow(ctx, "sub-1", 0) {
+		t.Error("subscriber without a limit should get the default")
+	}
+
+	// A subscriber's own limit takes precedence
+	for i := 0; i < 3; i++ {
+		if !rl.Allow(ctx, "sub-2", 5) {
+			t.Errorf("request %d should be allowed (limit=5)", i+1)
+		}
+	}
+}
+
 func TestRateLimiter_IsolationBetweenSubscribers(t *testing.T) {
 	rl, _ := setupTestRL(t)
 	ctx := context.Background()
@@ -0,0 +1,70 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ReceiptTTL is how long a delivery receipt is kept. Like PayloadTTL, it
+// only needs to outlive the jobs of the event left in the queue.
+const ReceiptTTL = PayloadTTL
+
+// DeliveryReceipts remembers which events each subscriber has acknowledged
+// with a 2xx, so a job that is delivered again, such as one left in the
+// queue by a worker that crashed after the delivery succeeded, is skipped
+// instead of sent twice. A nil *DeliveryReceipts records nothing and
+// reports nothing as delivered.
+type DeliveryReceipts struct {
+	redisClient *redis.Client
+}
+
+func NewDeliveryReceipts(redisClient *redis.Client) *DeliveryReceipts {
+	return &DeliveryReceipts{redisClient: redisClient}
+}
+
+func receiptKey(eventID, subscriberID string) string {
+	return fmt.Sprintf("receipt:%s:%s", eventID, subscriberID)
+}
+
+// Record marks the event as delivered to the subscriber.
+func (r *DeliveryReceipts) Record(ctx context.Context, eventID, subscriberID string) error {
+	if r == nil {
+		return nil
+	}
+	if err := r.redisClient.Set(ctx, receiptKey(eventID, subscriberID), time.Now().Unix(), ReceiptTTL).Err(); err != nil {
+		return fmt.Errorf("recording delivery receipt: %w", err)
+	}
+	return nil
+}
+
+// Delivered reports whether the event has already been delivered to the
+// subscriber.
+func (r *DeliveryReceipts) Delivered(ctx context.Context, eventID, subscriberID string) (bool, error) {
+	if r == nil {
+		return false, nil
+	}
+	err := r.redisClient.Get(ctx, receiptKey(eventID, subscriberID)).Err()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("checking delivery receipt: %w", err)
+	}
+	return true, nil
+}
+
+// Clear forgets the event's delivery to the subscriber, so that an explicit
+// redelivery is sent again.
+func (r *DeliveryReceipts) Clear(ctx context.Context, eventID, subscriberID string) error {
+	if r == nil {
+		return nil
+	}
+	if err := r.redisClient.Del(ctx, receiptKey(eventID, subscriberID)).Err(); err != nil {
+		return fmt.Errorf("clearing delivery receipt: %w", err)
+	}
+	return nil
+}
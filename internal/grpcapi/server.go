@@ -0,0 +1,309 @@
+// Package grpcapi serves the WebhookService gRPC API defined in
+// proto/webhook/v1. It is backed by the same stores and publish path as the
+// HTTP API.
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+	webhookv1 "github.com/Priya8975/webhook-delivery-system/proto/webhook/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// grpcActor is the audit log actor for changes made through the gRPC API.
+const grpcActor = "grpc"
+
+// maxBatchSize caps the number of events in one PublishEventBatch call.
+const maxBatchSize = 500
+
+// Server implements webhookv1.WebhookServiceServer.
+type Server struct {
+	webhookv1.UnimplementedWebhookServiceServer
+
+	store   store.Store
+	fanout  *engine.FanOutEngine
+	cleanup *engine.SubscriberCleanup
+}
+
+func NewServer(s store.Store, f *engine.FanOutEngine, c *engine.SubscriberCleanup) *Server {
+	return &Server{store: s, fanout: f, cleanup: c}
+}
+
+func (s *Server) PublishEvent(ctx context.Context, req *webhookv1.PublishEventRequest) (*webhookv1.PublishEventResponse, error) {
+	if err := validateEvent(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	resp, err := s.publish(ctx, req)
+	if err != nil {
+		var unmatched *engine.UnmatchedSubscribersError
+		if errors.As(err, &unmatched) {
+			return nil, status.Error(codes.InvalidArgument, unmatched.Error())
+		}
+		var tooLarge *engine.PayloadTooLargeError
+		if errors.As(err, &tooLarge) {
+			return nil, status.Error(codes.InvalidArgument, tooLarge.Error())
+		}
+		return nil, status.Error(codes.Internal, "failed to create event")
+	}
+	return resp, nil
+}
+
+// PublishEventBatch rejects the whole batch if any event is invalid, so
+// callers don't have to work out which events were stored. Storage failures
+// are reported per event.
+func (s *Server) PublishEventBatch(ctx context.Context, req *webhookv1.PublishEventBatchRequest) (*webhookv1.PublishEventBatchResponse, error) {
+	if len(req.GetEvents()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "at least one event is required")
+	}
+	if len(req.GetEvents()) > maxBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d events per batch", maxBatchSize)
+	}
+	for i, event := range req.GetEvents() {
+		if err := validateEvent(event); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "events[%d]: %v", i, err)
+		}
+	}
+
+	results := make([]*webhookv1.PublishEventResult, len(req.GetEvents()))
+	for i, event := range req.GetEvents() {
+		resp, err := s.publish(ctx, event)
+		if err != nil {
+			var unmatched *engine.UnmatchedSubscribersError
+			var tooLarge *engine.PayloadTooLargeError
+			if errors.As(err, &unmatched) {
+				results[i] = &webhookv1.PublishEventResult{Error: unmatched.Error()}
+			} else if errors.As(err, &tooLarge) {
+				results[i] = &webhookv1.PublishEventResult{Error: tooLarge.Error()}
+			} else {
+				results[i] = &webhookv1.PublishEventResult{Error: "failed to create event"}
+			}
+			continue
+		}
+		results[i] = &webhookv1.PublishEventResult{Event: resp}
+	}
+	return &webhookv1.PublishEventBatchResponse{Results: results}, nil
+}
+
+func (s *Server) publish(ctx context.Context, req *webhookv1.PublishEventRequest) (*webhookv1.PublishEventResponse, error) {
+	result, err := s.fanout.Publish(ctx, req.GetEventType(), int(req.GetVersion()), req.GetPayload(), req.GetSource(), req.GetSubscriberIds())
+	if err != nil {
+		return nil, err
+	}
+	return &webhookv1.PublishEventResponse{
+		EventId:          result.Event.ID,
+		EventType:        result.Event.EventType,
+		DeliveriesQueued: int32(result.DeliveriesQueued),
+		FanoutPending:    result.FanOutPending,
+	}, nil
+}
+
+func validateEvent(req *webhookv1.PublishEventRequest) error {
+	if req.GetEventType() == "" {
+		return fmt.Errorf("event_type is required")
+	}
+	if err := domain.ValidatePublishedEventType(req.GetEventType()); err != nil {
+		return err
+	}
+	if len(req.GetPayload()) == 0 {
+		return fmt.Errorf("payload is required")
+	}
+	if !json.Valid(req.GetPayload()) {
+		return fmt.Errorf("payload must be valid JSON")
+	}
+	if req.GetVersion() < 0 {
+		return fmt.Errorf("version must be at least 1")
+	}
+	return nil
+}
+
+func (s *Server) CreateSubscriber(ctx context.Context, req *webhookv1.CreateSubscriberRequest) (*webhookv1.CreateSubscriberResponse, error) {
+	if req.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+	if req.GetEndpointUrl() == "" {
+		return nil, status.Error(codes.InvalidArgument, "endpoint_url is required")
+	}
+	if len(req.GetEventTypes()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "at least one event_type is required")
+	}
+	if err := domain.ValidateEventPatterns(req.GetEventTypes()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	create := domain.CreateSubscriberRequest{
+		Name:                  req.GetName(),
+		EndpointURL:           req.GetEndpointUrl(),
+		EventTypes:            req.GetEventTypes(),
+		CompressPayloads:      req.GetCompressPayloads(),
+		DiscardResponseBodies: req.GetDiscardResponseBodies(),
+	}
+	sub, err := s.store.CreateSubscriber(ctx, create)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to create subscriber")
+	}
+
+	s.recordAudit(ctx, domain.AuditSubscriberCreate, domain.AuditEntitySubscriber, sub.ID, create)
+	s.fanout.PublishSystemEvent(ctx, domain.EventSubscriberCreated, domain.SubscriberCreatedEvent{
+		SubscriberID: sub.ID,
+		Name:         sub.Name,
+		EndpointURL:  sub.EndpointURL,
+		EventTypes:   create.EventTypes,
+	})
+
+	return &webhookv1.CreateSubscriberResponse{
+		Id:        sub.ID,
+		Name:      sub.Name,
+		SecretKey: sub.SecretKey,
+	}, nil
+}
+
+func (s *Server) GetSubscriber(ctx context.Context, req *webhookv1.GetSubscriberRequest) (*webhookv1.GetSubscriberResponse, error) {
+	sub, err := s.store.GetSubscriber(ctx, req.GetId())
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to get subscriber")
+	}
+	if sub == nil {
+		return nil, status.Error(codes.NotFound, "subscriber not found")
+	}
+
+	subscriptions, err := s.store.GetSubscriberSubscriptions(ctx, sub.ID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to get subscriptions")
+	}
+
+	return &webhookv1.GetSubscriberResponse{Subscriber: subscriberToProto(sub, subscriptions)}, nil
+}
+
+func (s *Server) ListSubscribers(ctx context.Context, req *webhookv1.ListSubscribersRequest) (*webhookv1.ListSubscribersResponse, error) {
+	subscribers, err := s.store.ListSubscribers(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to list subscribers")
+	}
+
+	resp := &webhookv1.ListSubscribersResponse{Subscribers: make([]*webhookv1.Subscriber, len(subscribers))}
+	for i := range subscribers {
+		resp.Subscribers[i] = subscriberToProto(&subscribers[i], nil)
+	}
+	return resp, nil
+}
+
+func (s *Server) UpdateSubscriber(ctx context.Context, req *webhookv1.UpdateSubscriberRequest) (*webhookv1.UpdateSubscriberResponse, error) {
+	update := domain.UpdateSubscriberRequest{
+		Name:                  req.Name,
+		EndpointURL:           req.EndpointUrl,
+		IsActive:              req.IsActive,
+		CompressPayloads:      req.CompressPayloads,
+		DiscardResponseBodies: req.DiscardResponseBodies,
+	}
+	if req.RateLimitPerSecond != nil {
+		limit := int(req.GetRateLimitPerSecond())
+		update.RateLimitPerSecond = &limit
+	}
+	if req.Version != nil {
+		version := int(req.GetVersion())
+		update.Version = &version
+	}
+
+	before, err := s.store.GetSubscriber(ctx, req.GetId())
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to get subscriber")
+	}
+	if before == nil {
+		return nil, status.Error(codes.NotFound, "subscriber not found")
+	}
+
+	sub, err := s.store.UpdateSubscriber(ctx, req.GetId(), update)
+	if errors.Is(err, store.ErrVersionConflict) {
+		return nil, status.Errorf(codes.Aborted, "subscriber was changed since version %d", req.GetVersion())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to update subscriber")
+	}
+	if sub == nil {
+		return nil, status.Error(codes.NotFound, "subscriber not found")
+	}
+
+	change := update
+	change.Version = nil
+	s.recordAudit(ctx, domain.AuditSubscriberUpdate, domain.AuditEntitySubscriber, sub.ID, domain.SubscriberChange{
+		Before: update.Previous(before),
+		After:  change,
+	})
+
+	if before.IsActive && !sub.IsActive && s.cleanup != nil {
+		result, err := s.cleanup.Cleanup(ctx, sub.ID, false)
+		s.recordAudit(ctx, domain.AuditSubscriberCleanup, domain.AuditEntitySubscriber, sub.ID, result)
+		if err != nil {
+			return nil, status.Error(codes.Internal, "subscriber deactivated, but cleanup failed")
+		}
+	}
+
+	return &webhookv1.UpdateSubscriberResponse{Subscriber: subscriberToProto(sub, nil)}, nil
+}
+
+// recordAudit writes an audit entry for a mutation. The gRPC API has no
+// authentication, so changes are attributed to "grpc" and the peer address.
+// Like the HTTP API, a failed write is logged rather than failing the call.
+func (s *Server) recordAudit(ctx context.Context, action, entityType, entityID string, details interface{}) {
+	entry := &domain.AuditEntry{
+		Actor:      grpcActor,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		entry.RemoteAddr = p.Addr.String()
+	}
+	if data, err := json.Marshal(details); err == nil {
+		entry.Details = data
+	}
+
+	if err := s.store.InsertAuditEntry(ctx, entry); err != nil {
+		slog.Error("failed to write audit entry", "error", err, "action", action, "entity_id", entityID)
+	}
+}
+
+// subscriberToProto converts a subscriber, leaving out its secret key.
+// Event types are only filled in when subscriptions are given.
+func subscriberToProto(sub *domain.Subscriber, subscriptions []domain.Subscription) *webhookv1.Subscriber {
+	pb := &webhookv1.Subscriber{
+		Id:                    sub.ID,
+		Name:                  sub.Name,
+		EndpointUrl:           sub.EndpointURL,
+		IsActive:              sub.IsActive,
+		RateLimitPerSecond:    int32(sub.RateLimitPerSecond),
+		CompressPayloads:      sub.CompressPayloads,
+		DiscardResponseBodies: sub.DiscardResponseBodies,
+		Version:               int32(sub.Version),
+		CreatedAt:             timestamppb.New(sub.CreatedAt),
+		UpdatedAt:             timestamppb.New(sub.UpdatedAt),
+	}
+	for _, subscription := range subscriptions {
+		if subscription.IsActive {
+			pb.EventTypes = append(pb.EventTypes, subscription.EventType)
+		}
+	}
+	return pb
+}
+
+// NewGRPCServer returns a gRPC server with WebhookService and server
+// reflection registered, so tools like grpcurl work without the proto files.
+func NewGRPCServer(s store.Store, f *engine.FanOutEngine, c *engine.SubscriberCleanup, opts ...grpc.ServerOption) *grpc.Server {
+	gs := grpc.NewServer(opts...)
+	webhookv1.RegisterWebhookServiceServer(gs, NewServer(s, f, c))
+	reflection.Register(gs)
+	return gs
+}
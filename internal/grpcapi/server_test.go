@@ -0,0 +1,130 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	webhookv1 "github.com/Priya8975/webhook-delivery-system/proto/webhook/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestClient serves a Server without stores over an in-memory listener.
+// Only requests rejected before reaching a store can be exercised with it.
+func newTestClient(t *testing.T) webhookv1.WebhookServiceClient {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	gs := NewGRPCServer(nil, nil, nil)
+	go gs.Serve(lis)
+	t.Cleanup(gs.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to dial bufconn: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return webhookv1.NewWebhookServiceClient(conn)
+}
+
+func TestServer_RejectsInvalidRequests(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	tooMany := make([]*webhookv1.PublishEventRequest, maxBatchSize+1)
+	for i := range tooMany {
+		tooMany[i] = &webhookv1.PublishEventRequest{EventType: "a", Payload: []byte(`{}`)}
+	}
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"missing event type", func() error {
+			_, err := client.PublishEvent(ctx, &webhookv1.PublishEventRequest{Payload: []byte(`{}`)})
+			return err
+		}},
+		{"reserved event type", func() error {
+			_, err := client.PublishEvent(ctx, &webhookv1.PublishEventRequest{EventType: "subscriber.circuit_opened", Payload: []byte(`{}`)})
+			return err
+		}},
+		{"missing payload", func() error {
+			_, err := client.PublishEvent(ctx, &webhookv1.PublishEventRequest{EventType: "order.created"})
+			return err
+		}},
+		{"invalid payload", func() error {
+			_, err := client.PublishEvent(ctx, &webhookv1.PublishEventRequest{EventType: "order.created", Payload: []byte(`{`)})
+			return err
+		}},
+		{"empty batch", func() error {
+			_, err := client.PublishEventBatch(ctx, &webhookv1.PublishEventBatchRequest{})
+			return err
+		}},
+		{"batch too large", func() error {
+			_, err := client.PublishEventBatch(ctx, &webhookv1.PublishEventBatchRequest{Events: tooMany})
+			return err
+		}},
+		{"invalid event in batch", func() error {
+			_, err := client.PublishEventBatch(ctx, &webhookv1.PublishEventBatchRequest{Events: []*webhookv1.PublishEventRequest{
+				{EventType: "order.created", Payload: []byte(`{}`)},
+				{EventType: "order.created"},
+			}})
+			return err
+		}},
+		{"subscriber without event types", func() error {
+			_, err := client.CreateSubscriber(ctx, &webhookv1.CreateSubscriberRequest{Name: "a", EndpointUrl: "http://example.com"})
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			if status.Code(err) != codes.InvalidArgument {
+				t.Errorf("expected InvalidArgument, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSubscriberToProto(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	sub := &domain.Subscriber{
+		ID:                 "sub-1",
+		Name:               "orders",
+		EndpointURL:        "http://example.com/hook",
+		SecretKey:          "whdlv_secret",
+		IsActive:           true,
+		RateLimitPerSecond: 10,
+		CreatedAt:          created,
+		UpdatedAt:          created,
+	}
+	subscriptions := []domain.Subscription{
+		{EventType: "order.created", IsActive: true},
+		{EventType: "order.deleted", IsActive: false},
+	}
+
+	pb := subscriberToProto(sub, subscriptions)
+
+	if pb.GetId() != "sub-1" || pb.GetRateLimitPerSecond() != 10 || !pb.GetIsActive() {
+		t.Errorf("unexpected subscriber fields: %+v", pb)
+	}
+	if got := pb.GetEventTypes(); len(got) != 1 || got[0] != "order.created" {
+		t.Errorf("event types = %v, want only active subscriptions", got)
+	}
+	if !pb.GetCreatedAt().AsTime().Equal(created) {
+		t.Errorf("created_at = %v, want %v", pb.GetCreatedAt().AsTime(), created)
+	}
+}
@@ -0,0 +1,76 @@
+// Package ingest consumes events from message brokers and publishes them
+// through the same path as POST /api/v1/events.
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+)
+
+// maxPublishBackoff caps the delay between attempts to store a consumed
+// event while Postgres is unavailable.
+const maxPublishBackoff = 30 * time.Second
+
+// envelope is a consumed message in the same shape as the HTTP create-event
+// request body.
+type envelope struct {
+	EventType string          `json:"event_type"`
+	Payload   json.RawMessage `json:"payload"`
+	Source    string          `json:"source,omitempty"`
+	Version   int             `json:"version,omitempty"`
+}
+
+// decodeEnvelope parses and validates a message body.
+func decodeEnvelope(body []byte) (envelope, error) {
+	var env envelope
+	if err := json.Unmarshal(body, &env); err != nil {
+		return env, errors.New("message is not a valid JSON event envelope")
+	}
+	if env.EventType == "" {
+		return env, errors.New("event_type is required")
+	}
+	if err := domain.ValidatePublishedEventType(env.EventType); err != nil {
+		return env, err
+	}
+	if len(env.Payload) == 0 {
+		return env, errors.New("payload is required")
+	}
+	if env.Version < 0 {
+		return env, errors.New("version must be at least 1")
+	}
+	return env, nil
+}
+
+// publish stores and fans out a consumed event, retrying with backoff until
+// it succeeds or the context is cancelled. Brokers must only acknowledge the
+// message once this returns nil; after that the outbox guarantees fan-out.
+func publish(ctx context.Context, fanout *engine.FanOutEngine, logger *slog.Logger, env envelope) (*engine.PublishResult, error) {
+	backoff := time.Second
+	for {
+		result, err := fanout.Publish(ctx, env.EventType, env.Version, env.Payload, env.Source, nil)
+		if err == nil {
+			return result, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		logger.Error("failed to store consumed event, retrying",
+			"event_type", env.EventType,
+			"retry_in", backoff.String(),
+			"error", err,
+		)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxPublishBackoff)
+	}
+}
@@ -0,0 +1,157 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+	"github.com/segmentio/kafka-go"
+)
+
+// KafkaEventTypeHeader lets producers publish a bare domain event. When a
+// message carries this header, its value is the event type and the whole
+// message value is the payload. Otherwise the value must be an envelope like
+// the POST /api/v1/events body.
+const KafkaEventTypeHeader = "event_type"
+
+// KafkaConfig configures the Kafka consumer.
+type KafkaConfig struct {
+	Brokers []string
+	Topic   string
+	GroupID string
+}
+
+// KafkaConsumer reads events from a Kafka topic as part of a consumer group
+// and publishes them. Offsets are committed only after an event is stored,
+// so a crash before that redelivers the message: ingestion is at-least-once.
+// Messages that can never be stored (malformed JSON, missing event type) are
+// logged and skipped so they don't block the partition.
+type KafkaConsumer struct {
+	reader *kafka.Reader
+	topic  string
+	fanout *engine.FanOutEngine
+	logger *slog.Logger
+}
+
+// NewKafkaConsumer creates a consumer for cfg.Topic in group cfg.GroupID.
+func NewKafkaConsumer(cfg KafkaConfig, fanout *engine.FanOutEngine, logger *slog.Logger) *KafkaConsumer {
+	return &KafkaConsumer{
+		reader: kafka.NewReader(kafka.ReaderConfig{
+			Brokers:  cfg.Brokers,
+			GroupID:  cfg.GroupID,
+			Topic:    cfg.Topic,
+			MaxBytes: 10e6,
+		}),
+		topic:  cfg.Topic,
+		fanout: fanout,
+		logger: logger,
+	}
+}
+
+// Start consumes messages until the context is cancelled, then leaves the
+// consumer group.
+func (c *KafkaConsumer) Start(ctx context.Context) {
+	c.logger.Info("kafka consumer started", "topic", c.topic)
+	defer func() {
+		if err := c.reader.Close(); err != nil {
+			c.logger.Warn("failed to close kafka reader", "error", err)
+		}
+		c.logger.Info("kafka consumer stopping")
+	}()
+
+	for {
+		msg, err := c.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.logger.Error("failed to fetch kafka message", "error", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+
+		if err := c.handle(ctx, msg); err != nil {
+			// Only happens on shutdown; the message is redelivered later
+			return
+		}
+		if err := c.reader.CommitMessages(ctx, msg); err != nil && ctx.Err() == nil {
+			c.logger.Error("failed to commit kafka offset",
+				"partition", msg.Partition,
+				"offset", msg.Offset,
+				"error", err,
+			)
+		}
+	}
+}
+
+// handle publishes one message. It returns an error only if the context was
+// cancelled before the event was stored.
+func (c *KafkaConsumer) handle(ctx context.Context, msg kafka.Message) error {
+	env, err := decodeKafkaMessage(msg)
+	if err == nil {
+		err = c.fanout.CheckPayloadSize(env.Payload)
+	}
+	if err != nil {
+		c.logger.Warn("skipping invalid kafka message",
+			"partition", msg.Partition,
+			"offset", msg.Offset,
+			"error", err,
+		)
+		return nil
+	}
+
+	result, err := publish(ctx, c.fanout, c.logger, env)
+	if err != nil {
+		return err
+	}
+
+	c.logger.Info("event ingested from kafka",
+		"event_id", result.Event.ID,
+		"event_type", result.Event.EventType,
+		"partition", msg.Partition,
+		"offset", msg.Offset,
+		"deliveries_queued", result.DeliveriesQueued,
+	)
+	return nil
+}
+
+// decodeKafkaMessage turns a message into an event envelope, using the
+// event_type header when present. Source defaults to "kafka:<topic>".
+func decodeKafkaMessage(msg kafka.Message) (envelope, error) {
+	var env envelope
+	var err error
+
+	if eventType := kafkaHeader(msg, KafkaEventTypeHeader); eventType != "" {
+		if err := domain.ValidatePublishedEventType(eventType); err != nil {
+			return env, err
+		}
+		if !json.Valid(msg.Value) {
+			return env, errors.New("payload must be valid JSON")
+		}
+		env = envelope{EventType: eventType, Payload: msg.Value}
+	} else if env, err = decodeEnvelope(msg.Value); err != nil {
+		return env, err
+	}
+
+	if env.Source == "" {
+		env.Source = "kafka:" + msg.Topic
+	}
+	return env, nil
+}
+
+func kafkaHeader(msg kafka.Message, key string) string {
+	for _, h := range msg.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,83 @@
+package ingest
+
+import (
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+)
+
+func TestDecodeKafkaMessage(t *testing.T) {
+	tests := []struct {
+		name       string
+		msg        kafka.Message
+		wantErr    bool
+		wantType   string
+		wantSource string
+		wantBody   string
+	}{
+		{
+			name:       "envelope",
+			msg:        kafka.Message{Topic: "orders", Value: []byte(`{"event_type":"order.created","payload":{"id":1}}`)},
+			wantType:   "order.created",
+			wantSource: "kafka:orders",
+			wantBody:   `{"id":1}`,
+		},
+		{
+			name:       "envelope keeps its source",
+			msg:        kafka.Message{Topic: "orders", Value: []byte(`{"event_type":"order.created","payload":{},"source":"billing"}`)},
+			wantType:   "order.created",
+			wantSource: "billing",
+			wantBody:   `{}`,
+		},
+		{
+			name: "event type header",
+			msg: kafka.Message{
+				Topic:   "orders",
+				Headers: []kafka.Header{{Key: KafkaEventTypeHeader, Value: []byte("order.shipped")}},
+				Value:   []byte(`{"id":2}`),
+			},
+			wantType:   "order.shipped",
+			wantSource: "kafka:orders",
+			wantBody:   `{"id":2}`,
+		},
+		{
+			name: "header with invalid payload",
+			msg: kafka.Message{
+				Headers: []kafka.Header{{Key: KafkaEventTypeHeader, Value: []byte("order.shipped")}},
+				Value:   []byte(`not json`),
+			},
+			wantErr: true,
+		},
+		{
+			name: "reserved event type in header",
+			msg: kafka.Message{
+				Headers: []kafka.Header{{Key: KafkaEventTypeHeader, Value: []byte("delivery.dead_lettered")}},
+				Value:   []byte(`{}`),
+			},
+			wantErr: true,
+		},
+		{name: "reserved event type", msg: kafka.Message{Value: []byte(`{"event_type":"subscriber.created","payload":{}}`)}, wantErr: true},
+		{name: "missing event type", msg: kafka.Message{Value: []byte(`{"payload":{}}`)}, wantErr: true},
+		{name: "missing payload", msg: kafka.Message{Value: []byte(`{"event_type":"a"}`)}, wantErr: true},
+		{name: "not json", msg: kafka.Message{Value: []byte(`{`)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, err := decodeKafkaMessage(tt.msg)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", env)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if env.EventType != tt.wantType || env.Source != tt.wantSource || string(env.Payload) != tt.wantBody {
+				t.Errorf("got %+v (payload %s), want type %q source %q payload %s",
+					env, env.Payload, tt.wantType, tt.wantSource, tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,283 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// natsAckWait is how long JetStream waits for an ack before redelivering.
+// The consumer reports progress well within it while an event is stored.
+const natsAckWait = 30 * time.Second
+
+// SubjectMapping maps NATS subjects to an event type. Subject may use the
+// NATS wildcards "*" and ">". An empty EventType uses the message's subject.
+type SubjectMapping struct {
+	Subject   string
+	EventType string
+}
+
+// ParseSubjectMappings parses entries of the form "subject=event.type" or
+// just "subject".
+func ParseSubjectMappings(entries []string) ([]SubjectMapping, error) {
+	mappings := make([]SubjectMapping, 0, len(entries))
+	for _, entry := range entries {
+		subject, eventType, _ := strings.Cut(entry, "=")
+		subject, eventType = strings.TrimSpace(subject), strings.TrimSpace(eventType)
+		if subject == "" {
+			return nil, fmt.Errorf("invalid subject mapping %q", entry)
+		}
+		mappings = append(mappings, SubjectMapping{Subject: subject, EventType: eventType})
+	}
+	return mappings, nil
+}
+
+// NATSConfig configures the JetStream consumer.
+type NATSConfig struct {
+	URL      string
+	Stream   string
+	Durable  string
+	Subjects []SubjectMapping
+}
+
+// NATSConsumer consumes events from a JetStream stream through a durable
+// pull consumer. Each message body is the event payload and its subject
+// determines the event type. A message is acked only after its event is
+// stored and fan-out has been queued, so unacked messages are redelivered
+// after a crash. Messages that can never be stored are terminated.
+type NATSConsumer struct {
+	cfg          NATSConfig
+	fanout       *engine.FanOutEngine
+	store        store.OutboxStore
+	logger       *slog.Logger
+	pollInterval time.Duration
+}
+
+// NewNATSConsumer creates a JetStream consumer.
+func NewNATSConsumer(cfg NATSConfig, fanout *engine.FanOutEngine, s store.OutboxStore, logger *slog.Logger) *NATSConsumer {
+	return &NATSConsumer{
+		cfg:          cfg,
+		fanout:       fanout,
+		store:        s,
+		logger:       logger,
+		pollInterval: 2 * time.Second,
+	}
+}
+
+// Start consumes messages until the context is cancelled, reconnecting with
+// backoff if NATS is unreachable or the consumer cannot be created.
+func (c *NATSConsumer) Start(ctx context.Context) {
+	c.logger.Info("nats consumer started",
+		"stream", c.cfg.Stream,
+		"durable", c.cfg.Durable,
+		"subjects", len(c.cfg.Subjects),
+	)
+
+	backoff := time.Second
+	for {
+		err := c.run(ctx)
+		if ctx.Err() != nil {
+			c.logger.Info("nats consumer stopping")
+			return
+		}
+		c.logger.Error("nats consumer failed, reconnecting",
+			"retry_in", backoff.String(),
+			"error", err,
+		)
+		select {
+		case <-ctx.Done():
+			c.logger.Info("nats consumer stopping")
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxPublishBackoff)
+	}
+}
+
+func (c *NATSConsumer) run(ctx context.Context) error {
+	nc, err := nats.Connect(c.cfg.URL, nats.Name("webhook-delivery"), nats.MaxReconnects(-1))
+	if err != nil {
+		return fmt.Errorf("connecting to nats: %w", err)
+	}
+	defer nc.Close()
+
+	js, err := jetstream.New(nc)
+	if err != nil {
+		return fmt.Errorf("creating jetstream context: %w", err)
+	}
+
+	filters := make([]string, len(c.cfg.Subjects))
+	for i, m := range c.cfg.Subjects {
+		filters[i] = m.Subject
+	}
+	consumer, err := js.CreateOrUpdateConsumer(ctx, c.cfg.Stream, jetstream.ConsumerConfig{
+		Durable:        c.cfg.Durable,
+		FilterSubjects: filters,
+		AckPolicy:      jetstream.AckExplicitPolicy,
+		AckWait:        natsAckWait,
+	})
+	if err != nil {
+		return fmt.Errorf("creating consumer %s on stream %s: %w", c.cfg.Durable, c.cfg.Stream, err)
+	}
+
+	iter, err := consumer.Messages()
+	if err != nil {
+		return fmt.Errorf("subscribing to consumer: %w", err)
+	}
+	defer iter.Stop()
+
+	for {
+		msg, err := iter.Next(jetstream.NextContext(ctx))
+		if err != nil {
+			return fmt.Errorf("reading message: %w", err)
+		}
+		if err := c.handle(ctx, msg); err != nil {
+			return err
+		}
+	}
+}
+
+// handle stores one message and acks it. It returns an error only if the
+// context was cancelled or the ack failed; the message is then redelivered.
+func (c *NATSConsumer) handle(ctx context.Context, msg jetstream.Msg) error {
+	env, err := c.decode(msg)
+	if err == nil {
+		err = c.fanout.CheckPayloadSize(env.Payload)
+	}
+	if err != nil {
+		c.logger.Warn("terminating invalid nats message",
+			"subject", msg.Subject(),
+			"error", err,
+		)
+		msg.Term()
+		return nil
+	}
+
+	// Keep JetStream from redelivering while Postgres or Redis is slow
+	stop := keepInProgress(msg, natsAckWait/3)
+	defer stop()
+
+	result, err := publish(ctx, c.fanout, c.logger, env)
+	if err != nil {
+		return err
+	}
+	if result.FanOutPending {
+		if err := c.waitForFanOut(ctx, result.Event.ID); err != nil {
+			return err
+		}
+	}
+
+	if err := msg.DoubleAck(ctx); err != nil {
+		return fmt.Errorf("acking message: %w", err)
+	}
+
+	c.logger.Info("event ingested from nats",
+		"event_id", result.Event.ID,
+		"event_type", result.Event.EventType,
+		"subject", msg.Subject(),
+		"deliveries_queued", result.DeliveriesQueued,
+	)
+	return nil
+}
+
+// waitForFanOut blocks until the outbox relay has fanned out an event whose
+// inline fan-out failed. Retrying here as well would race the relay and
+// queue duplicate deliveries.
+func (c *NATSConsumer) waitForFanOut(ctx context.Context, eventID string) error {
+	ticker := time.NewTicker(c.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		pending, err := c.store.OutboxEntryPending(ctx, eventID)
+		if err == nil && !pending {
+			return nil
+		}
+		if err != nil && ctx.Err() == nil {
+			c.logger.Warn("failed to check fan-out status", "event_id", eventID, "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// decode builds an event envelope from a message. Source is "nats:<subject>".
+func (c *NATSConsumer) decode(msg jetstream.Msg) (envelope, error) {
+	eventType, ok := eventTypeForSubject(c.cfg.Subjects, msg.Subject())
+	if !ok {
+		return envelope{}, fmt.Errorf("no event type mapped for subject %s", msg.Subject())
+	}
+	if err := domain.ValidatePublishedEventType(eventType); err != nil {
+		return envelope{}, err
+	}
+	if !json.Valid(msg.Data()) {
+		return envelope{}, fmt.Errorf("payload must be valid JSON")
+	}
+	return envelope{
+		EventType: eventType,
+		Payload:   msg.Data(),
+		Source:    "nats:" + msg.Subject(),
+	}, nil
+}
+
+// keepInProgress tells JetStream the message is still being worked on every
+// interval until the returned stop function is called.
+func keepInProgress(msg jetstream.Msg, interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				msg.InProgress()
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// eventTypeForSubject returns the event type of the first mapping whose
+// subject pattern matches.
+func eventTypeForSubject(mappings []SubjectMapping, subject string) (string, bool) {
+	for _, m := range mappings {
+		if !subjectMatches(m.Subject, subject) {
+			continue
+		}
+		if m.EventType == "" {
+			return subject, true
+		}
+		return m.EventType, true
+	}
+	return "", false
+}
+
+// subjectMatches reports whether subject matches a NATS subject pattern,
+// where "*" matches one token and a trailing ">" matches one or more.
+func subjectMatches(pattern, subject string) bool {
+	p := strings.Split(pattern, ".")
+	s := strings.Split(subject, ".")
+	for i, tok := range p {
+		if tok == ">" {
+			return len(s) > i
+		}
+		if i >= len(s) || (tok != "*" && tok != s[i]) {
+			return false
+		}
+	}
+	return len(p) == len(s)
+}
@@ -0,0 +1,56 @@
+package ingest
+
+import "testing"
+
+func TestSubjectMatches(t *testing.T) {
+	tests := []struct {
+		pattern, subject string
+		want             bool
+	}{
+		{"orders.created", "orders.created", true},
+		{"orders.created", "orders.updated", false},
+		{"orders.*", "orders.created", true},
+		{"orders.*", "orders.created.eu", false},
+		{"orders.>", "orders.created.eu", true},
+		{"orders.>", "orders", false},
+		{"*.created", "payments.created", true},
+		{"orders", "orders.created", false},
+	}
+
+	for _, tt := range tests {
+		if got := subjectMatches(tt.pattern, tt.subject); got != tt.want {
+			t.Errorf("subjectMatches(%q, %q) = %v, want %v", tt.pattern, tt.subject, got, tt.want)
+		}
+	}
+}
+
+func TestEventTypeForSubject(t *testing.T) {
+	mappings, err := ParseSubjectMappings([]string{"orders.created=order.created", "orders.>=order.other", "billing.>"})
+	if err != nil {
+		t.Fatalf("ParseSubjectMappings failed: %v", err)
+	}
+
+	tests := []struct {
+		subject string
+		want    string
+		ok      bool
+	}{
+		{"orders.created", "order.created", true},
+		{"orders.shipped", "order.other", true},
+		{"billing.invoice.paid", "billing.invoice.paid", true},
+		{"users.created", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := eventTypeForSubject(mappings, tt.subject)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("eventTypeForSubject(%q) = %q, %v; want %q, %v", tt.subject, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseSubjectMappings_RejectsEmptySubject(t *testing.T) {
+	if _, err := ParseSubjectMappings([]string{"=order.created"}); err == nil {
+		t.Error("expected error for mapping without a subject")
+	}
+}
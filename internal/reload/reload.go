@@ -0,0 +1,121 @@
+// Package reload re-reads the configuration of a running server and applies
+// the operational settings that can change without a restart.
+package reload
+
+import (
+	"log/slog"
+	"sync"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/api"
+	"github.com/Priya8975/webhook-delivery-system/internal/config"
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+	"github.com/Priya8975/webhook-delivery-system/internal/worker"
+)
+
+// reloadable lists the settings Reload applies. Changes to any other
+// setting take effect on the next restart.
+var reloadable = map[string]bool{
+	"NUM_WORKERS":                       true, // through the pool bounds
+	"WORKER_POOL_MIN":                   true,
+	"WORKER_POOL_MAX":                   true,
+	"DISPATCHER_BATCH_SIZE":             true,
+	"RATE_LIMIT_DEFAULT_PER_SECOND":     true,
+	"CIRCUIT_BREAKER_FAILURE_THRESHOLD": true,
+	"CIRCUIT_BREAKER_COOLDOWN":          true,
+	"INGEST_MAX_PAYLOAD_BYTES":          true,
+	"INGEST_RATE_LIMIT_PER_SECOND":      true,
+	"DELIVERY_LOG_SUCCESS_SAMPLE_RATE":  true,
+	"DELIVERY_LOG_FAILURE_SAMPLE_RATE":  true,
+}
+
+// Targets are the components whose settings Reload changes.
+type Targets struct {
+	Autoscaler     *worker.Autoscaler
+	Dispatcher     *worker.Dispatcher
+	RateLimiter    *engine.RateLimiter
+	CircuitBreaker *engine.CircuitBreaker
+	Ingest         *api.IngestLimiter
+	FanOut         *engine.FanOutEngine
+	Deliverer      *worker.Deliverer
+}
+
+// Reloader re-reads the configuration file and environment. Environment
+// variables cannot change in a running process, so in practice a reload
+// picks up edits to the file.
+type Reloader struct {
+	path       string
+	instanceID string
+	targets    Targets
+	logger     *slog.Logger
+
+	mu      sync.Mutex
+	started *config.Config // what the server started with
+	applied *config.Config // the last configuration applied
+}
+
+// New creates a reloader for the instance instanceID, started with cfg
+// loaded from path.
+func New(path, instanceID string, cfg *config.Config, targets Targets, logger *slog.Logger) *Reloader {
+	return &Reloader{
+		path:       path,
+		instanceID: instanceID,
+		targets:    targets,
+		logger:     logger,
+		started:    cfg,
+		applied:    cfg,
+	}
+}
+
+// Reload loads the configuration again and applies the runtime settings.
+// Nothing is applied if the new configuration is invalid. Workers are added
+// or retired between jobs, so in-flight deliveries are not interrupted.
+func (r *Reloader) Reload() (*domain.ConfigReload, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	next, err := config.Load(r.path)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.ConfigReload{
+		InstanceID:      r.instanceID,
+		Applied:         []domain.ConfigChange{},
+		RestartRequired: []string{},
+	}
+	for _, name := range next.Changed(r.applied) {
+		if reloadable[name] {
+			result.Applied = append(result.Applied, domain.ConfigChange{
+				Setting: name,
+				From:    r.applied.Setting(name),
+				To:      next.Setting(name),
+			})
+		}
+	}
+	for _, name := range next.Changed(r.started) {
+		if !reloadable[name] {
+			result.RestartRequired = append(result.RestartRequired, name)
+		}
+	}
+
+	r.targets.Autoscaler.SetBounds(next.WorkerPoolMin, next.WorkerPoolMax)
+	r.targets.Dispatcher.SetBatchSize(next.DispatcherBatchSize)
+	r.targets.RateLimiter.SetDefaultLimit(next.RateLimitDefaultPerSecond)
+	r.targets.CircuitBreaker.SetThresholds(next.CircuitBreakerFailureThreshold, next.CircuitBreakerCooldown)
+	r.targets.Ingest.SetLimits(next.IngestMaxPayloadBytes, next.IngestRateLimitPerSecond)
+	r.targets.FanOut.SetMaxPayloadBytes(next.IngestMaxPayloadBytes)
+	r.targets.Deliverer.SetLogSampling(worker.LogSampling{
+		Success: next.DeliveryLogSuccessSampleRate,
+		Failure: next.DeliveryLogFailureSampleRate,
+	})
+	r.applied = next
+
+	for _, c := range result.Applied {
+		r.logger.Info("configuration changed", "setting", c.Setting, "from", c.From, "to", c.To)
+	}
+	if len(result.RestartRequired) > 0 {
+		r.logger.Warn("configuration changes need a restart to take effect", "settings", result.RestartRequired)
+	}
+	return result, nil
+}
@@ -0,0 +1,148 @@
+package reload
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/api"
+	"github.com/Priya8975/webhook-delivery-system/internal/config"
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+	"github.com/Priya8975/webhook-delivery-system/internal/worker"
+)
+
+const baseConfig = `
+database_url: sqlite:test.db
+queue_mode: memory
+num_workers: 4
+`
+
+func TestReloader_AppliesRuntimeSettings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	write := func(content string) {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(baseConfig)
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	queue := engine.NewMemoryQueue()
+	pool := worker.NewPool(cfg.WorkerPoolMin, nil, queue, logger)
+	pool.Start(ctx)
+	t.Cleanup(pool.Stop)
+
+	autoscaler := worker.NewAutoscaler(pool, queue, worker.AutoscalerConfig{
+		MinWorkers: cfg.WorkerPoolMin,
+		MaxWorkers: cfg.WorkerPoolMax,
+	}, logger)
+	rl := engine.NewRateLimiter(client, logger)
+	cb := engine.NewCircuitBreaker(client, logger)
+	r := New(path, "instance-1", cfg, Targets{
+		Autoscaler:     autoscaler,
+		Dispatcher:     worker.NewDispatcher(queue, pool, logger),
+		RateLimiter:    rl,
+		CircuitBreaker: cb,
+		Ingest:         api.NewIngestLimiter(rl, cfg.IngestMaxPayloadBytes, cfg.IngestRateLimitPerSecond),
+		FanOut:         engine.NewFanOutEngine(nil, queue, nil, logger),
+		Deliverer:      worker.NewDeliverer(nil, queue, cb, rl, nil, worker.DelivererConfig{}, logger),
+	}, logger)
+
+	write(baseConfig + `
+worker_pool:
+  min: 6
+  max: 20
+rate_limit:
+  default_per_second: 1
+circuit_breaker:
+  failure_threshold: 1
+ingest:
+  rate_limit_per_second: 100
+delivery:
+  timeout: 3s
+  log_success_sample_rate: 0.01
+`)
+	result, err := r.Reload()
+	if err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+
+	want := []domain.ConfigChange{
+		{Setting: "CIRCUIT_BREAKER_FAILURE_THRESHOLD", From: "5", To: "1"},
+		{Setting: "DELIVERY_LOG_SUCCESS_SAMPLE_RATE", From: "1", To: "0.01"},
+		{Setting: "INGEST_RATE_LIMIT_PER_SECOND", From: "0", To: "100"},
+		{Setting: "RATE_LIMIT_DEFAULT_PER_SECOND", From: "0", To: "1"},
+		{Setting: "WORKER_POOL_MAX", From: "4", To: "20"},
+		{Setting: "WORKER_POOL_MIN", From: "4", To: "6"},
+	}
+	if !reflect.DeepEqual(result.Applied, want) {
+		t.Errorf("applied = %+v, want %+v", result.Applied, want)
+	}
+	if !reflect.DeepEqual(result.RestartRequired, []string{"DELIVERY_TIMEOUT"}) {
+		t.Errorf("restart required = %v", result.RestartRequired)
+	}
+
+	if lo, hi := autoscaler.Bounds(); lo != 6 || hi != 20 || pool.Size() != 6 {
+		t.Errorf("pool bounds = %d-%d, size %d; want 6-20, size 6", lo, hi, pool.Size())
+	}
+	if rl.Allow(ctx, "sub-1", 0); rl.Allow(ctx, "sub-1", 0) {
+		t.Error("expected the new default rate limit to apply")
+	}
+	if cb.RecordFailure(ctx, "sub-1"); cb.GetState(ctx, "sub-1").State != engine.StateOpen {
+		t.Error("expected one failure to open the circuit")
+	}
+
+	// Reloading again reports nothing new applied, but still flags the
+	// setting that needs a restart
+	result, err = r.Reload()
+	if err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if len(result.Applied) != 0 || len(result.RestartRequired) != 1 {
+		t.Errorf("second reload = %+v", result)
+	}
+}
+
+func TestReloader_KeepsConfigOnError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(baseConfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	queue := engine.NewMemoryQueue()
+	pool := worker.NewPool(cfg.WorkerPoolMin, nil, queue, logger)
+	autoscaler := worker.NewAutoscaler(pool, queue, worker.AutoscalerConfig{MinWorkers: 4, MaxWorkers: 4}, logger)
+	r := New(path, "instance-1", cfg, Targets{Autoscaler: autoscaler}, logger)
+
+	if err := os.WriteFile(path, []byte(baseConfig+"worker_pool:\n  min: 0\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Reload(); err == nil {
+		t.Fatal("expected an invalid configuration to be rejected")
+	}
+	if lo, hi := autoscaler.Bounds(); lo != 4 || hi != 4 {
+		t.Errorf("pool bounds changed to %d-%d", lo, hi)
+	}
+}
@@ -0,0 +1,36 @@
+package sigv4
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The get-vanilla case of the AWS Signature Version 4 test suite.
+func TestSign_MatchesAWSTestSuite(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	creds := Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	Sign(req, nil, "service", "us-east-1", creds, time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+		"SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization = %q\nwant %q", got, want)
+	}
+	if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+		t.Errorf("X-Amz-Date = %q", got)
+	}
+}
+
+func TestSign_SessionToken(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodPost, "https://sqs.us-east-1.amazonaws.com/", nil)
+	Sign(req, nil, "sqs", "us-east-1", Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret", SessionToken: "token"}, time.Now())
+
+	if req.Header.Get("X-Amz-Security-Token") != "token" {
+		t.Errorf("X-Amz-Security-Token = %q", req.Header.Get("X-Amz-Security-Token"))
+	}
+	if auth := req.Header.Get("Authorization"); !strings.Contains(auth, "x-amz-security-token") {
+		t.Errorf("the session token isn't signed: %s", auth)
+	}
+}
@@ -0,0 +1,91 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+)
+
+// ElasticsearchSink indexes attempts into an Elasticsearch or OpenSearch
+// index through the bulk API, using the attempt ID as document ID so an
+// attempt written twice is stored once.
+type ElasticsearchSink struct {
+	url        string // base URL; credentials in it are sent as basic auth
+	index      string
+	apiKey     string
+	httpClient *http.Client
+}
+
+var _ Sink = (*ElasticsearchSink)(nil)
+
+func NewElasticsearchSink(url, index, apiKey string) *ElasticsearchSink {
+	return &ElasticsearchSink{
+		url:        strings.TrimRight(url, "/"),
+		index:      index,
+		apiKey:     apiKey,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (s *ElasticsearchSink) Name() string { return "elasticsearch" }
+
+func (s *ElasticsearchSink) Write(ctx context.Context, attempts []domain.DeliveryAttempt) error {
+	body, err := encodeNDJSON(attempts, func(a domain.DeliveryAttempt) any {
+		return map[string]any{"index": map[string]string{"_index": s.index, "_id": a.ID}}
+	})
+	if err != nil {
+		return fmt.Errorf("encoding attempts: %w", err)
+	}
+
+	header := http.Header{}
+	if s.apiKey != "" {
+		header.Set("Authorization", "ApiKey "+s.apiKey)
+	}
+	data, err := post(ctx, s.httpClient, s.url+"/_bulk", "application/x-ndjson", body, header)
+	if err != nil {
+		return fmt.Errorf("bulk indexing into %s: %w", s.index, err)
+	}
+	return bulkError(data)
+}
+
+func (s *ElasticsearchSink) Close() error {
+	s.httpClient.CloseIdleConnections()
+	return nil
+}
+
+// bulkError reports the first failed item of a bulk response. The bulk API
+// answers 200 even when some or all of the items failed.
+func bulkError(data []byte) error {
+	var resp struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			Status int             `json:"status"`
+			Error  json.RawMessage `json:"error"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return fmt.Errorf("decoding bulk response: %w", err)
+	}
+	if !resp.Errors {
+		return nil
+	}
+
+	failed := 0
+	var first string
+	for _, item := range resp.Items {
+		for _, result := range item {
+			if len(result.Error) > 0 {
+				failed++
+				if first == "" {
+					first = fmt.Sprintf("status %d: %s", result.Status, result.Error)
+				}
+			}
+		}
+	}
+	return fmt.Errorf("%d of %d attempts not indexed, first: %s", failed, len(resp.Items), first)
+}
@@ -0,0 +1,56 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/segmentio/kafka-go"
+)
+
+// KafkaSink produces each attempt as a JSON message to a Kafka topic, keyed
+// by subscriber ID so one subscriber's attempts stay in order on a single
+// partition.
+type KafkaSink struct {
+	writer *kafka.Writer
+}
+
+var _ Sink = (*KafkaSink)(nil)
+
+func NewKafkaSink(brokers []string, topic string) *KafkaSink {
+	return &KafkaSink{writer: &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Topic:        topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireOne,
+		BatchTimeout: 10 * time.Millisecond,
+	}}
+}
+
+func (s *KafkaSink) Name() string { return "kafka" }
+
+func (s *KafkaSink) Write(ctx context.Context, attempts []domain.DeliveryAttempt) error {
+	msgs, err := kafkaMessages(attempts)
+	if err != nil {
+		return err
+	}
+	return s.writer.WriteMessages(ctx, msgs...)
+}
+
+func (s *KafkaSink) Close() error {
+	return s.writer.Close()
+}
+
+func kafkaMessages(attempts []domain.DeliveryAttempt) ([]kafka.Message, error) {
+	msgs := make([]kafka.Message, len(attempts))
+	for i, a := range attempts {
+		value, err := json.Marshal(a)
+		if err != nil {
+			return nil, fmt.Errorf("encoding attempt %s: %w", a.ID, err)
+		}
+		msgs[i] = kafka.Message{Key: []byte(a.SubscriberID), Value: value, Time: a.CreatedAt}
+	}
+	return msgs, nil
+}
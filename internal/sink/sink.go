@@ -0,0 +1,229 @@
+// Package sink mirrors delivery attempts to external systems (a Kafka
+// topic, an Elasticsearch index or a ClickHouse table) so analytics and
+// long-term search can run outside the primary database.
+//
+// Mirroring is best effort. The database stays the record of every
+// attempt: attempts are handed to each sink in the background, and dropped
+// rather than slowing deliveries when a sink falls behind.
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+)
+
+// Sink is an external system delivery attempts are written to.
+type Sink interface {
+	// Name identifies the sink in logs, e.g. "kafka".
+	Name() string
+	// Write stores a batch of attempts.
+	Write(ctx context.Context, attempts []domain.DeliveryAttempt) error
+	// Close releases the sink's connections.
+	Close() error
+}
+
+// Config tunes how attempts are handed to the sinks.
+type Config struct {
+	// BufferSize bounds how many attempts can wait for each sink. Attempts
+	// arriving while a sink's buffer is full are dropped for that sink.
+	BufferSize int
+	// BatchSize is the most attempts written to a sink at once.
+	BatchSize int
+	// FlushInterval is the longest an attempt waits for its batch to fill.
+	FlushInterval time.Duration
+}
+
+// Mirror copies the delivery attempts it is given to every sink, each
+// through a buffer and writer goroutine of its own so a slow sink holds up
+// neither the others nor the caller.
+type Mirror struct {
+	writers       []*writer
+	batchSize     int
+	flushInterval time.Duration
+	logger        *slog.Logger
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+type writer struct {
+	sink    Sink
+	queue   chan domain.DeliveryAttempt
+	dropped atomic.Int64
+	done    chan struct{}
+}
+
+func NewMirror(sinks []Sink, cfg Config, logger *slog.Logger) *Mirror {
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = 10000
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 500
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = time.Second
+	}
+
+	m := &Mirror{batchSize: cfg.BatchSize, flushInterval: cfg.FlushInterval, logger: logger}
+	for _, s := range sinks {
+		m.writers = append(m.writers, &writer{
+			sink:  s,
+			queue: make(chan domain.DeliveryAttempt, cfg.BufferSize),
+			done:  make(chan struct{}),
+		})
+	}
+	return m
+}
+
+// Start launches a writer goroutine per sink.
+func (m *Mirror) Start() {
+	m.startOnce.Do(func() {
+		for _, w := range m.writers {
+			go m.run(w)
+			m.logger.Info("attempt sink started", "sink", w.sink.Name())
+		}
+	})
+}
+
+// Mirror queues attempts for every sink without blocking. It must not be
+// called after Stop.
+func (m *Mirror) Mirror(recs []store.DeliveryAttemptRecord) {
+	now := time.Now()
+	for _, rec := range recs {
+		a := rec.Attempt(now)
+		for _, w := range m.writers {
+			select {
+			case w.queue <- a:
+			default:
+				w.dropped.Add(1)
+			}
+		}
+	}
+}
+
+// Stop writes the attempts still queued, waits for the writers to exit and
+// closes the sinks. Call it once nothing mirrors attempts any more.
+func (m *Mirror) Stop() {
+	m.stopOnce.Do(func() {
+		m.Start()
+		for _, w := range m.writers {
+			close(w.queue)
+		}
+		for _, w := range m.writers {
+			<-w.done
+			if err := w.sink.Close(); err != nil {
+				m.logger.Warn("failed to close attempt sink", "sink", w.sink.Name(), "error", err)
+			}
+		}
+	})
+}
+
+func (m *Mirror) run(w *writer) {
+	defer close(w.done)
+
+	ticker := time.NewTicker(m.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]domain.DeliveryAttempt, 0, m.batchSize)
+	for {
+		select {
+		case a, ok := <-w.queue:
+			if !ok {
+				m.flush(w, batch)
+				return
+			}
+			batch = append(batch, a)
+			if len(batch) >= m.batchSize {
+				m.flush(w, batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			m.flush(w, batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+// flush writes one batch and reports attempts dropped since the last flush.
+// Writes run without the server's context so that attempts queued during
+// shutdown are still written.
+func (m *Mirror) flush(w *writer, batch []domain.DeliveryAttempt) {
+	if n := w.dropped.Swap(0); n > 0 {
+		m.logger.Warn("attempt sink buffer full, attempts dropped", "sink", w.sink.Name(), "dropped", n)
+	}
+	if len(batch) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := w.sink.Write(ctx, batch); err != nil {
+		m.logger.Error("failed to write delivery attempts to sink",
+			"sink", w.sink.Name(),
+			"error", err,
+			"count", len(batch),
+		)
+	}
+}
+
+// encodeNDJSON writes each document on a line of its own, preceded by the
+// line header returns for it, if any.
+func encodeNDJSON(attempts []domain.DeliveryAttempt, header func(domain.DeliveryAttempt) any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, a := range attempts {
+		if header != nil {
+			if err := enc.Encode(header(a)); err != nil {
+				return nil, err
+			}
+		}
+		if err := enc.Encode(a); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// post sends body to url and returns the response body of a 2xx answer.
+func post(ctx context.Context, client *http.Client, url, contentType string, body []byte, header http.Header) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg := strings.TrimSpace(string(data))
+		if len(msg) > 512 {
+			msg = msg[:512]
+		}
+		return nil, fmt.Errorf("status %d: %s", resp.StatusCode, msg)
+	}
+	return data, nil
+}
@@ -0,0 +1,212 @@
+package sink
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+)
+
+var testLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// memorySink keeps the batches written to it.
+type memorySink struct {
+	mu      sync.Mutex
+	batches [][]domain.DeliveryAttempt
+	block   chan struct{} // when set, Write waits for it to close
+	closed  bool
+}
+
+func (s *memorySink) Name() string { return "memory" }
+
+func (s *memorySink) Write(ctx context.Context, attempts []domain.DeliveryAttempt) error {
+	if s.block != nil {
+		<-s.block
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, append([]domain.DeliveryAttempt(nil), attempts...))
+	return nil
+}
+
+func (s *memorySink) Close() error {
+	s.closed = true
+	return nil
+}
+
+func (s *memorySink) written() []domain.DeliveryAttempt {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var all []domain.DeliveryAttempt
+	for _, b := range s.batches {
+		all = append(all, b...)
+	}
+	return all
+}
+
+func records(n int) []store.DeliveryAttemptRecord {
+	recs := make([]store.DeliveryAttemptRecord, n)
+	for i := range recs {
+		recs[i] = store.DeliveryAttemptRecord{
+			ID:            "att-" + string(rune('a'+i)),
+			EventID:       "evt-1",
+			SubscriberID:  "sub-1",
+			AttemptNumber: 1,
+			Status:        "failed",
+			ErrorMessage:  "connection refused",
+			FailureReason: domain.FailureConnection,
+		}
+	}
+	return recs
+}
+
+func TestMirror_WritesToEverySinkInBatches(t *testing.T) {
+	a, b := &memorySink{}, &memorySink{}
+	m := NewMirror([]Sink{a, b}, Config{BatchSize: 2, FlushInterval: time.Hour}, testLogger)
+	m.Start()
+
+	m.Mirror(records(5))
+	m.Stop()
+
+	for _, s := range []*memorySink{a, b} {
+		got := s.written()
+		if len(got) != 5 || len(s.batches) != 3 {
+			t.Fatalf("sink got %d attempts in %d batches, want 5 in 3", len(got), len(s.batches))
+		}
+		if got[0].ID != "att-a" || got[0].ErrorMessage == nil || *got[0].FailureReason != "connection_error" || got[0].CreatedAt.IsZero() {
+			t.Errorf("first attempt = %+v", got[0])
+		}
+		if !s.closed {
+			t.Error("sink not closed by Stop")
+		}
+	}
+}
+
+func TestMirror_DropsWhenSinkFallsBehind(t *testing.T) {
+	slow := &memorySink{block: make(chan struct{})}
+	m := NewMirror([]Sink{slow}, Config{BufferSize: 2, BatchSize: 1, FlushInterval: time.Hour}, testLogger)
+	m.Start()
+
+	done := make(chan struct{})
+	go func() {
+		// The writer takes one attempt and blocks on it, two fill the
+		// buffer and the rest are dropped, without blocking the caller
+		m.Mirror(records(10))
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Mirror blocked on a slow sink")
+	}
+
+	close(slow.block)
+	m.Stop()
+	if n := len(slow.written()); n < 2 || n > 3 {
+		t.Errorf("slow sink got %d attempts, want the 2 or 3 that fit", n)
+	}
+}
+
+func TestElasticsearchSink_BulkIndexes(t *testing.T) {
+	var auth string
+	var lines []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/_bulk" || r.Header.Get("Content-Type") != "application/x-ndjson" {
+			t.Errorf("request %s %s, content type %q", r.Method, r.URL.Path, r.Header.Get("Content-Type"))
+		}
+		auth = r.Header.Get("Authorization")
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			var line map[string]any
+			json.Unmarshal(scanner.Bytes(), &line)
+			lines = append(lines, line)
+		}
+		w.Write([]byte(`{"errors":false,"items":[]}`))
+	}))
+	defer server.Close()
+
+	s := NewElasticsearchSink(server.URL+"/", "attempts", "secret-key")
+	attempts := []domain.DeliveryAttempt{{ID: "att-1", EventID: "evt-1"}, {ID: "att-2", EventID: "evt-2"}}
+	if err := s.Write(context.Background(), attempts); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	if auth != "ApiKey secret-key" {
+		t.Errorf("Authorization = %q", auth)
+	}
+	if len(lines) != 4 {
+		t.Fatalf("bulk body has %d lines, want an action and a document per attempt", len(lines))
+	}
+	action, _ := lines[2]["index"].(map[string]any)
+	if action["_index"] != "attempts" || action["_id"] != "att-2" || lines[3]["event_id"] != "evt-2" {
+		t.Errorf("second action %v, document %v", lines[2], lines[3])
+	}
+}
+
+func TestElasticsearchSink_ReportsItemErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"errors":true,"items":[
+			{"index":{"status":201}},
+			{"index":{"status":400,"error":{"type":"mapper_parsing_exception"}}}]}`))
+	}))
+	defer server.Close()
+
+	err := NewElasticsearchSink(server.URL, "attempts", "").Write(context.Background(), []domain.DeliveryAttempt{{ID: "a"}, {ID: "b"}})
+	if err == nil || !strings.Contains(err.Error(), "1 of 2") || !strings.Contains(err.Error(), "mapper_parsing_exception") {
+		t.Errorf("err = %v, want the failed item reported", err)
+	}
+}
+
+func TestClickHouseSink_InsertsJSONEachRow(t *testing.T) {
+	var query, user string
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query().Get("query")
+		user, _, _ = r.BasicAuth()
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	s, err := NewClickHouseSink(strings.Replace(server.URL, "http://", "http://analytics:pw@", 1), "metrics.delivery_attempts")
+	if err != nil {
+		t.Fatalf("NewClickHouseSink: %v", err)
+	}
+	if err := s.Write(context.Background(), []domain.DeliveryAttempt{{ID: "att-1"}, {ID: "att-2"}}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	if query != "INSERT INTO metrics.delivery_attempts FORMAT JSONEachRow" || user != "analytics" {
+		t.Errorf("query %q as %q", query, user)
+	}
+	if rows := bytes.Split(bytes.TrimSpace(body), []byte("\n")); len(rows) != 2 || !bytes.Contains(rows[1], []byte(`"id":"att-2"`)) {
+		t.Errorf("body = %s, want a row per attempt", body)
+	}
+
+	for _, table := range []string{"", "attempts; DROP TABLE x", "a.b.c"} {
+		if _, err := NewClickHouseSink(server.URL, table); err == nil {
+			t.Errorf("table %q accepted", table)
+		}
+	}
+}
+
+func TestKafkaMessages_KeyedBySubscriber(t *testing.T) {
+	msgs, err := kafkaMessages([]domain.DeliveryAttempt{{ID: "att-1", SubscriberID: "sub-1"}})
+	if err != nil {
+		t.Fatalf("kafkaMessages: %v", err)
+	}
+	var a domain.DeliveryAttempt
+	if string(msgs[0].Key) != "sub-1" || json.Unmarshal(msgs[0].Value, &a) != nil || a.ID != "att-1" {
+		t.Errorf("message = %+v", msgs[0])
+	}
+}
@@ -0,0 +1,163 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+// apiKeyColumns is the column list scanned by scanAPIKey.
+const apiKeyColumns = `id, name, key_prefix, role, subscriber_id, created_at, last_used_at, revoked_at`
+
+// apiKeyPrefixLen is how much of a key is kept in plaintext so it can be
+// recognised in listings.
+const apiKeyPrefixLen = 12
+
+// scanAPIKey scans a row selected with apiKeyColumns.
+func scanAPIKey(row pgx.Row, k *domain.APIKey) error {
+	return row.Scan(&k.ID, &k.Name, &k.KeyPrefix, &k.Role, &k.SubscriberID, &k.CreatedAt, &k.LastUsedAt, &k.RevokedAt)
+}
+
+// HashAPIKey returns the stored form of an API key.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateAPIKey generates and stores a new API key with the given role,
+// returning it along with the plaintext key. The plaintext is not
+// recoverable afterwards. A non-nil subscriberID scopes the key to that
+// subscriber.
+func (s *PostgresStore) CreateAPIKey(ctx context.Context, name string, role domain.Role, subscriberID *string) (*domain.APIKey, string, error) {
+	key, err := generateAPIKey()
+	if err != nil {
+		return nil, "", fmt.Errorf("generating api key: %w", err)
+	}
+
+	var k domain.APIKey
+	err = scanAPIKey(s.pool.QueryRow(ctx, `
+		INSERT INTO api_keys (name, key_prefix, key_hash, role, subscriber_id)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING `+apiKeyColumns,
+		name, key[:apiKeyPrefixLen], HashAPIKey(key), role, subscriberID,
+	), &k)
+	if err != nil {
+		return nil, "", fmt.Errorf("inserting api key: %w", err)
+	}
+	return &k, key, nil
+}
+
+// GetAPIKeyByHash returns the unrevoked key with the given hash, or nil if
+// there is none.
+func (s *PostgresStore) GetAPIKeyByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	var k domain.APIKey
+	err := scanAPIKey(s.pool.QueryRow(ctx, `
+		SELECT `+apiKeyColumns+`
+		FROM api_keys WHERE key_hash = $1 AND revoked_at IS NULL
+	`, hash), &k)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("querying api key: %w", err)
+	}
+	return &k, nil
+}
+
+// GetAPIKey returns a key by ID, including revoked keys, or nil if there is
+// none.
+func (s *PostgresStore) GetAPIKey(ctx context.Context, id string) (*domain.APIKey, error) {
+	var k domain.APIKey
+	err := scanAPIKey(s.pool.QueryRow(ctx, `
+		SELECT `+apiKeyColumns+`
+		FROM api_keys WHERE id = $1
+	`, id), &k)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("querying api key: %w", err)
+	}
+	return &k, nil
+}
+
+// ListAPIKeys returns all API keys, including revoked ones, newest first.
+func (s *PostgresStore) ListAPIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT `+apiKeyColumns+`
+		FROM api_keys ORDER BY created_at DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying api keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []domain.APIKey{}
+	for rows.Next() {
+		var k domain.APIKey
+		if err := scanAPIKey(rows, &k); err != nil {
+			return nil, fmt.Errorf("scanning api key: %w", err)
+		}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey revokes a key. It returns false if no unrevoked key has the
+// given ID.
+func (s *PostgresStore) RevokeAPIKey(ctx context.Context, id string) (bool, error) {
+	result, err := s.pool.Exec(ctx, `
+		UPDATE api_keys SET revoked_at = NOW()
+		WHERE id = $1 AND revoked_at IS NULL
+	`, id)
+	if err != nil {
+		return false, fmt.Errorf("revoking api key: %w", err)
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// SetAPIKeyRole changes the role of an unrevoked key and returns the updated
+// key, or nil if no unrevoked key has the given ID.
+func (s *PostgresStore) SetAPIKeyRole(ctx context.Context, id string, role domain.Role) (*domain.APIKey, error) {
+	var k domain.APIKey
+	err := scanAPIKey(s.pool.QueryRow(ctx, `
+		UPDATE api_keys SET role = $2
+		WHERE id = $1 AND revoked_at IS NULL
+		RETURNING `+apiKeyColumns,
+		id, role,
+	), &k)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("updating api key role: %w", err)
+	}
+	return &k, nil
+}
+
+// TouchAPIKey records that a key was used. Updates are throttled to one a
+// minute so polling clients don't write on every request.
+func (s *PostgresStore) TouchAPIKey(ctx context.Context, id string) error {
+	_, err := s.pool.Exec(ctx, `
+		UPDATE api_keys SET last_used_at = NOW()
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')
+	`, id)
+	if err != nil {
+		return fmt.Errorf("touching api key: %w", err)
+	}
+	return nil
+}
+
+// generateAPIKey returns a key of the form whk_<64 hex chars>.
+func generateAPIKey() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return "whk_" + hex.EncodeToString(bytes), nil
+}
@@ -0,0 +1,169 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+// ListDeliveryAttemptsBefore returns the oldest delivery attempts created before cutoff.
+func (s *PostgresStore) ListDeliveryAttemptsBefore(ctx context.Context, cutoff time.Time, limit int) ([]domain.DeliveryAttempt, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT id, event_id, subscriber_id, attempt_number, status, http_status_code, response_body, response_headers, response_time_ms, timings, error_message, failure_reason, next_retry_at, final_url, created_at
+		FROM delivery_attempts
+		WHERE created_at < $1
+		ORDER BY created_at, id
+		LIMIT $2
+	`, cutoff, limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying aged delivery attempts: %w", err)
+	}
+	defer rows.Close()
+
+	var attempts []domain.DeliveryAttempt
+	for rows.Next() {
+		var a domain.DeliveryAttempt
+		err := rows.Scan(
+			&a.ID, &a.EventID, &a.SubscriberID, &a.AttemptNumber,
+			&a.Status, &a.HTTPStatusCode, &a.ResponseBody, &a.ResponseHeaders,
+			&a.ResponseTimeMs, &a.Timings, &a.ErrorMessage, &a.FailureReason, &a.NextRetryAt, &a.FinalURL, &a.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning delivery attempt: %w", err)
+		}
+		attempts = append(attempts, a)
+	}
+
+	return attempts, nil
+}
+
+// DeleteDeliveryAttempts removes the given delivery attempts.
+func (s *PostgresStore) DeleteDeliveryAttempts(ctx context.Context, ids []string) (int64, error) {
+	result, err := s.pool.Exec(ctx, `DELETE FROM delivery_attempts WHERE id = ANY($1::uuid[])`, ids)
+	if err != nil {
+		return 0, fmt.Errorf("deleting delivery attempts: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
+
+// ListPrunableEvents returns the oldest events created before cutoff that are no
+// longer referenced by any delivery attempt or pending fan-out. Dead letters
+// keep their own copy of the event, so they don't hold events back.
+func (s *PostgresStore) ListPrunableEvents(ctx context.Context, cutoff time.Time, limit int) ([]domain.Event, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT e.id, e.event_type, e.version, e.payload, e.payload_ref, e.payload_size, e.source, e.created_at
+		FROM events e
+		WHERE e.created_at < $1
+		  AND NOT EXISTS (SELECT 1 FROM delivery_attempts da WHERE da.event_id = e.id)
+		  AND NOT EXISTS (SELECT 1 FROM fanout_outbox o WHERE o.event_id = e.id)
+		ORDER BY e.created_at, e.id
+		LIMIT $2
+	`, cutoff, limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying prunable events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []domain.Event
+	for rows.Next() {
+		var e domain.Event
+		var refKey string
+		var refSize int64
+		if err := rows.Scan(&e.ID, &e.EventType, &e.Version, &e.Payload, &refKey, &refSize, &e.Source, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning event: %w", err)
+		}
+		e.PayloadRef = payloadRef(refKey, refSize)
+		events = append(events, e)
+	}
+
+	return events, nil
+}
+
+// DeleteEvents removes the given events.
+func (s *PostgresStore) DeleteEvents(ctx context.Context, ids []string) (int64, error) {
+	result, err := s.pool.Exec(ctx, `DELETE FROM events WHERE id = ANY($1::uuid[])`, ids)
+	if err != nil {
+		return 0, fmt.Errorf("deleting events: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
+
+// InsertArchiveManifest records an uploaded archive object.
+func (s *PostgresStore) InsertArchiveManifest(ctx context.Context, m *domain.ArchiveManifest) error {
+	err := s.pool.QueryRow(ctx, `
+		INSERT INTO archive_manifests (table_name, bucket, object_key, row_count, byte_size, sha256, oldest_at, newest_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`, m.TableName, m.Bucket, m.ObjectKey, m.RowCount, m.ByteSize, m.SHA256, m.OldestAt, m.NewestAt).Scan(&m.ID, &m.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("inserting archive manifest: %w", err)
+	}
+	return nil
+}
+
+// ListArchiveManifests returns archive manifests, newest first, optionally filtered by table.
+func (s *PostgresStore) ListArchiveManifests(ctx context.Context, tableName string, limit int) ([]domain.ArchiveManifest, error) {
+	query := `SELECT id, table_name, bucket, object_key, row_count, byte_size, sha256, oldest_at, newest_at, created_at FROM archive_manifests`
+	args := []interface{}{}
+	argIdx := 1
+
+	if tableName != "" {
+		query += fmt.Sprintf(" WHERE table_name = $%d", argIdx)
+		args = append(args, tableName)
+		argIdx++
+	}
+
+	query += " ORDER BY newest_at DESC"
+
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argIdx)
+		args = append(args, limit)
+	}
+
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying archive manifests: %w", err)
+	}
+	defer rows.Close()
+
+	var manifests []domain.ArchiveManifest
+	for rows.Next() {
+		var m domain.ArchiveManifest
+		err := rows.Scan(
+			&m.ID, &m.TableName, &m.Bucket, &m.ObjectKey, &m.RowCount,
+			&m.ByteSize, &m.SHA256, &m.OldestAt, &m.NewestAt, &m.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning archive manifest: %w", err)
+		}
+		manifests = append(manifests, m)
+	}
+
+	if manifests == nil {
+		manifests = []domain.ArchiveManifest{}
+	}
+
+	return manifests, nil
+}
+
+// GetArchiveManifest returns a single archive manifest by ID.
+func (s *PostgresStore) GetArchiveManifest(ctx context.Context, id string) (*domain.ArchiveManifest, error) {
+	var m domain.ArchiveManifest
+	err := s.pool.QueryRow(ctx, `
+		SELECT id, table_name, bucket, object_key, row_count, byte_size, sha256, oldest_at, newest_at, created_at
+		FROM archive_manifests WHERE id = $1
+	`, id).Scan(
+		&m.ID, &m.TableName, &m.Bucket, &m.ObjectKey, &m.RowCount,
+		&m.ByteSize, &m.SHA256, &m.OldestAt, &m.NewestAt, &m.CreatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("querying archive manifest: %w", err)
+	}
+	return &m, nil
+}
@@ -0,0 +1,95 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+)
+
+// InsertAuditEntry records a management mutation.
+func (s *PostgresStore) InsertAuditEntry(ctx context.Context, e *domain.AuditEntry) error {
+	var details interface{}
+	if len(e.Details) > 0 {
+		details = []byte(e.Details)
+	}
+
+	err := s.pool.QueryRow(ctx, `
+		INSERT INTO audit_log (actor, actor_key_id, action, entity_type, entity_id, details, remote_addr, request_id)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), NULLIF($8, ''))
+		RETURNING id, created_at
+	`, e.Actor, e.ActorKeyID, e.Action, e.EntityType, e.EntityID, details, e.RemoteAddr, e.RequestID).Scan(&e.ID, &e.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("inserting audit entry: %w", err)
+	}
+	return nil
+}
+
+// ListAuditEntries returns audit entries matching the filter, newest first.
+func (s *PostgresStore) ListAuditEntries(ctx context.Context, f domain.AuditFilter) ([]domain.AuditEntry, error) {
+	query := `SELECT id, actor, actor_key_id, action, entity_type, entity_id, details, COALESCE(remote_addr, ''), COALESCE(request_id, ''), created_at FROM audit_log`
+	args := []interface{}{}
+	conditions := []string{}
+
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if f.Actor != "" {
+		add("actor = $%d", f.Actor)
+	}
+	if f.Action != "" {
+		add("action = $%d", f.Action)
+	}
+	if f.EntityType != "" {
+		add("entity_type = $%d", f.EntityType)
+	}
+	if f.EntityID != "" {
+		add("entity_id = $%d", f.EntityID)
+	}
+	if !f.Since.IsZero() {
+		add("created_at >= $%d", f.Since)
+	}
+	if !f.Until.IsZero() {
+		add("created_at < $%d", f.Until)
+	}
+
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query += " ORDER BY created_at DESC"
+
+	if f.Limit > 0 {
+		args = append(args, f.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying audit log: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []domain.AuditEntry
+	for rows.Next() {
+		var e domain.AuditEntry
+		var details []byte
+		err := rows.Scan(
+			&e.ID, &e.Actor, &e.ActorKeyID, &e.Action, &e.EntityType, &e.EntityID,
+			&details, &e.RemoteAddr, &e.RequestID, &e.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning audit entry: %w", err)
+		}
+		e.Details = details
+		entries = append(entries, e)
+	}
+
+	if entries == nil {
+		entries = []domain.AuditEntry{}
+	}
+
+	return entries, nil
+}
@@ -2,7 +2,9 @@ package store
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/Priya8975/webhook-delivery-system/internal/domain"
@@ -11,18 +13,78 @@ import (
 
 // DeliveryAttemptRecord holds data for inserting a delivery attempt.
 type DeliveryAttemptRecord struct {
-	EventID        string
-	SubscriberID   string
-	AttemptNumber  int
-	Status         string
-	HTTPStatusCode *int
-	ResponseBody   string
-	ResponseTimeMs int
-	ErrorMessage   string
-	NextRetryAt    *time.Time
+	// ID is the delivery ID sent to the subscriber with the attempt. The
+	// store generates one when it is empty.
+	ID              string
+	EventID         string
+	SubscriberID    string
+	AttemptNumber   int
+	Status          string
+	HTTPStatusCode  *int
+	ResponseBody    string
+	ResponseHeaders map[string]string
+	ResponseTimeMs  int
+	Timings         *domain.DeliveryTimings
+	ErrorMessage    string
+	FailureReason   domain.FailureReason
+	NextRetryAt     *time.Time
+	// FinalURL is where the response came from, when the attempt followed
+	// redirects away from the endpoint.
+	FinalURL string
+	// PayloadBytes is the size of the payload sent, metered as usage but
+	// not stored with the attempt.
+	PayloadBytes int
+}
+
+func (rec DeliveryAttemptRecord) id() string {
+	if rec.ID != "" {
+		return rec.ID
+	}
+	return newUUID()
+}
+
+// Attempt returns the attempt as the API reports it, recorded at createdAt.
+func (rec DeliveryAttemptRecord) Attempt(createdAt time.Time) domain.DeliveryAttempt {
+	responseTime := rec.ResponseTimeMs
+	a := domain.DeliveryAttempt{
+		ID:             rec.id(),
+		EventID:        rec.EventID,
+		SubscriberID:   rec.SubscriberID,
+		AttemptNumber:  rec.AttemptNumber,
+		Status:         rec.Status,
+		HTTPStatusCode: rec.HTTPStatusCode,
+		ResponseTimeMs: &responseTime,
+		Timings:        rec.Timings,
+		NextRetryAt:    rec.NextRetryAt,
+		CreatedAt:      createdAt,
+	}
+	if rec.ResponseBody != "" {
+		a.ResponseBody = &rec.ResponseBody
+	}
+	if len(rec.ResponseHeaders) > 0 {
+		a.ResponseHeaders = rec.ResponseHeaders
+	}
+	if rec.ErrorMessage != "" {
+		a.ErrorMessage = &rec.ErrorMessage
+	}
+	if rec.FailureReason != "" {
+		reason := string(rec.FailureReason)
+		a.FailureReason = &reason
+	}
+	if rec.FinalURL != "" {
+		a.FinalURL = &rec.FinalURL
+	}
+	return a
+}
+
+// NewDeliveryID returns an ID for a delivery attempt that is about to be
+// made, so the same ID can be sent to the subscriber and recorded.
+func NewDeliveryID() string {
+	return newUUID()
 }
 
-// RecordDeliveryAttempt inserts a delivery attempt into the database.
+// RecordDeliveryAttempt inserts a delivery attempt into the database and
+// meters it.
 func (s *PostgresStore) RecordDeliveryAttempt(ctx context.Context, rec DeliveryAttemptRecord) error {
 	var statusCode *int
 	if rec.HTTPStatusCode != nil {
@@ -34,19 +96,113 @@ func (s *PostgresStore) RecordDeliveryAttempt(ctx context.Context, rec DeliveryA
 		respBody = &rec.ResponseBody
 	}
 
+	var respHeaders interface{}
+	if len(rec.ResponseHeaders) > 0 {
+		respHeaders = rec.ResponseHeaders
+	}
+
 	var errMsg *string
 	if rec.ErrorMessage != "" {
 		errMsg = &rec.ErrorMessage
 	}
 
-	_, err := s.pool.Exec(ctx, `
-		INSERT INTO delivery_attempts (event_id, subscriber_id, attempt_number, status, http_status_code, response_body, response_time_ms, error_message, next_retry_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-	`, rec.EventID, rec.SubscriberID, rec.AttemptNumber, rec.Status, statusCode, respBody, rec.ResponseTimeMs, errMsg, rec.NextRetryAt)
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO delivery_attempts (id, event_id, subscriber_id, attempt_number, status, http_status_code, response_body, response_headers, response_time_ms, timings, error_message, failure_reason, next_retry_at, final_url)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`, rec.id(), rec.EventID, rec.SubscriberID, rec.AttemptNumber, rec.Status, statusCode, respBody, respHeaders, rec.ResponseTimeMs, rec.Timings, errMsg, nullString(string(rec.FailureReason)), rec.NextRetryAt, nullString(rec.FinalURL))
 	if err != nil {
 		return fmt.Errorf("inserting delivery attempt: %w", err)
 	}
-	return nil
+	if err := meterUsage(ctx, tx, []DeliveryAttemptRecord{rec}); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// InsertDeliveryAttempts inserts a batch of delivery attempts in a single
+// statement by unnesting one array per column, and meters them in the same
+// transaction.
+func (s *PostgresStore) InsertDeliveryAttempts(ctx context.Context, recs []DeliveryAttemptRecord) error {
+	if len(recs) == 0 {
+		return nil
+	}
+
+	n := len(recs)
+	ids := make([]string, n)
+	eventIDs := make([]string, n)
+	subscriberIDs := make([]string, n)
+	attemptNumbers := make([]int, n)
+	statuses := make([]string, n)
+	statusCodes := make([]*int, n)
+	respBodies := make([]*string, n)
+	respHeaders := make([]*string, n)
+	respTimes := make([]int, n)
+	timings := make([]*string, n)
+	errMsgs := make([]*string, n)
+	reasons := make([]*string, n)
+	nextRetries := make([]*time.Time, n)
+	finalURLs := make([]*string, n)
+
+	for i, rec := range recs {
+		ids[i] = rec.id()
+		eventIDs[i] = rec.EventID
+		subscriberIDs[i] = rec.SubscriberID
+		attemptNumbers[i] = rec.AttemptNumber
+		statuses[i] = rec.Status
+		statusCodes[i] = rec.HTTPStatusCode
+		if rec.ResponseBody != "" {
+			respBodies[i] = &recs[i].ResponseBody
+		}
+		if len(rec.ResponseHeaders) > 0 {
+			data, err := json.Marshal(rec.ResponseHeaders)
+			if err != nil {
+				return fmt.Errorf("encoding response headers: %w", err)
+			}
+			headers := string(data)
+			respHeaders[i] = &headers
+		}
+		respTimes[i] = rec.ResponseTimeMs
+		if rec.Timings != nil {
+			data, err := json.Marshal(rec.Timings)
+			if err != nil {
+				return fmt.Errorf("encoding timings: %w", err)
+			}
+			t := string(data)
+			timings[i] = &t
+		}
+		if rec.ErrorMessage != "" {
+			errMsgs[i] = &recs[i].ErrorMessage
+		}
+		reasons[i] = nullString(string(rec.FailureReason))
+		nextRetries[i] = rec.NextRetryAt
+		finalURLs[i] = nullString(rec.FinalURL)
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO delivery_attempts (id, event_id, subscriber_id, attempt_number, status, http_status_code, response_body, response_headers, response_time_ms, timings, error_message, failure_reason, next_retry_at, final_url)
+		SELECT id, event_id, subscriber_id, attempt_number, status, http_status_code, response_body, response_headers::jsonb, response_time_ms, timings::jsonb, error_message, failure_reason, next_retry_at, final_url
+		FROM unnest($1::uuid[], $2::uuid[], $3::uuid[], $4::int[], $5::text[], $6::int[], $7::text[], $8::text[], $9::int[], $10::text[], $11::text[], $12::text[], $13::timestamptz[], $14::text[])
+			AS t(id, event_id, subscriber_id, attempt_number, status, http_status_code, response_body, response_headers, response_time_ms, timings, error_message, failure_reason, next_retry_at, final_url)
+	`, ids, eventIDs, subscriberIDs, attemptNumbers, statuses, statusCodes, respBodies, respHeaders, respTimes, timings, errMsgs, reasons, nextRetries, finalURLs)
+	if err != nil {
+		return fmt.Errorf("inserting %d delivery attempts: %w", n, err)
+	}
+	if err := meterUsage(ctx, tx, recs); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
 // DeadLetterRecord holds data for inserting a dead letter entry.
@@ -56,9 +212,11 @@ type DeadLetterRecord struct {
 	TotalAttempts  int
 	LastHTTPStatus *int
 	LastError      string
+	FailureReason  domain.FailureReason
 }
 
-// InsertDeadLetter adds a permanently failed delivery to the dead letter queue.
+// InsertDeadLetter adds a permanently failed delivery to the dead letter queue,
+// snapshotting the event so the entry outlives the event row.
 func (s *PostgresStore) InsertDeadLetter(ctx context.Context, rec DeadLetterRecord) error {
 	var lastErr *string
 	if rec.LastError != "" {
@@ -66,32 +224,60 @@ func (s *PostgresStore) InsertDeadLetter(ctx context.Context, rec DeadLetterReco
 	}
 
 	_, err := s.pool.Exec(ctx, `
-		INSERT INTO dead_letter_queue (event_id, subscriber_id, total_attempts, last_http_status, last_error)
-		VALUES ($1, $2, $3, $4, $5)
-	`, rec.EventID, rec.SubscriberID, rec.TotalAttempts, rec.LastHTTPStatus, lastErr)
+		INSERT INTO dead_letter_queue (event_id, subscriber_id, total_attempts, last_http_status, last_error, failure_reason, event_type, payload, event_source)
+		SELECT $1, $2, $3, $4, $5, $6, e.event_type, e.payload, e.source
+		FROM (SELECT 1) AS one LEFT JOIN events e ON e.id = $1
+	`, rec.EventID, rec.SubscriberID, rec.TotalAttempts, rec.LastHTTPStatus, lastErr, nullString(string(rec.FailureReason)))
 	if err != nil {
 		return fmt.Errorf("inserting dead letter: %w", err)
 	}
 	return nil
 }
 
-// ListDeadLetters returns dead letter entries with optional filtering.
-func (s *PostgresStore) ListDeadLetters(ctx context.Context, subscriberID string, resolved bool, limit int) ([]domain.DeadLetter, error) {
-	query := `SELECT id, event_id, subscriber_id, total_attempts, last_error, last_http_status, created_at, resolved_at, resolved_by FROM dead_letter_queue`
+// DeadLetterFilter narrows ListDeadLetters. Empty fields match everything;
+// a nil Resolved matches resolved and unresolved entries alike. OldestFirst
+// reverses the listing, and After then continues it past the cursor in that
+// order.
+type DeadLetterFilter struct {
+	SubscriberID string
+	Resolved     *bool
+	Since        time.Time
+	Until        time.Time
+	After        *PageCursor
+	OldestFirst  bool
+	Limit        int
+}
+
+// ListDeadLetters returns dead letter entries with optional filtering,
+// newest first unless the filter asks for the oldest.
+func (s *PostgresStore) ListDeadLetters(ctx context.Context, f DeadLetterFilter) ([]domain.DeadLetter, error) {
+	query := `SELECT id, event_id, subscriber_id, event_type, event_source, total_attempts, last_error, last_http_status, failure_reason, created_at, resolved_at, resolved_by FROM dead_letter_queue`
 	args := []interface{}{}
 	argIdx := 1
 	conditions := []string{}
 
-	if subscriberID != "" {
+	if f.SubscriberID != "" {
 		conditions = append(conditions, fmt.Sprintf("subscriber_id = $%d", argIdx))
-		args = append(args, subscriberID)
+		args = append(args, f.SubscriberID)
 		argIdx++
 	}
 
-	if resolved {
-		conditions = append(conditions, "resolved_at IS NOT NULL")
+	if f.Resolved != nil {
+		if *f.Resolved {
+			conditions = append(conditions, "resolved_at IS NOT NULL")
+		} else {
+			conditions = append(conditions, "resolved_at IS NULL")
+		}
+	}
+	if f.OldestFirst {
+		conditions, args, argIdx = pageConditions(conditions, args, argIdx, f.Since, f.Until, nil)
+		if f.After != nil {
+			conditions = append(conditions, fmt.Sprintf("(created_at, id) > ($%d, $%d)", argIdx, argIdx+1))
+			args = append(args, f.After.CreatedAt, f.After.ID)
+			argIdx += 2
+		}
 	} else {
-		conditions = append(conditions, "resolved_at IS NULL")
+		conditions, args, argIdx = pageConditions(conditions, args, argIdx, f.Since, f.Until, f.After)
 	}
 
 	if len(conditions) > 0 {
@@ -104,14 +290,18 @@ func (s *PostgresStore) ListDeadLetters(ctx context.Context, subscriberID string
 		}
 	}
 
-	query += " ORDER BY created_at DESC"
+	if f.OldestFirst {
+		query += " ORDER BY created_at, id"
+	} else {
+		query += " ORDER BY created_at DESC, id DESC"
+	}
 
-	if limit > 0 {
+	if f.Limit > 0 {
 		query += fmt.Sprintf(" LIMIT $%d", argIdx)
-		args = append(args, limit)
+		args = append(args, f.Limit)
 	}
 
-	rows, err := s.pool.Query(ctx, query, args...)
+	rows, err := s.reads().Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("querying dead letters: %w", err)
 	}
@@ -121,8 +311,8 @@ func (s *PostgresStore) ListDeadLetters(ctx context.Context, subscriberID string
 	for rows.Next() {
 		var dl domain.DeadLetter
 		err := rows.Scan(
-			&dl.ID, &dl.EventID, &dl.SubscriberID, &dl.TotalAttempts,
-			&dl.LastError, &dl.LastHTTPStatus, &dl.CreatedAt,
+			&dl.ID, &dl.EventID, &dl.SubscriberID, &dl.EventType, &dl.EventSource, &dl.TotalAttempts,
+			&dl.LastError, &dl.LastHTTPStatus, &dl.FailureReason, &dl.CreatedAt,
 			&dl.ResolvedAt, &dl.ResolvedBy,
 		)
 		if err != nil {
@@ -138,15 +328,16 @@ func (s *PostgresStore) ListDeadLetters(ctx context.Context, subscriberID string
 	return letters, nil
 }
 
-// GetDeadLetter returns a single dead letter by ID.
+// GetDeadLetter returns a single dead letter by ID, including its payload.
 func (s *PostgresStore) GetDeadLetter(ctx context.Context, id string) (*domain.DeadLetter, error) {
 	var dl domain.DeadLetter
+	var payload []byte
 	err := s.pool.QueryRow(ctx, `
-		SELECT id, event_id, subscriber_id, total_attempts, last_error, last_http_status, created_at, resolved_at, resolved_by
+		SELECT id, event_id, subscriber_id, event_type, event_source, payload, total_attempts, last_error, last_http_status, failure_reason, created_at, resolved_at, resolved_by
 		FROM dead_letter_queue WHERE id = $1
 	`, id).Scan(
-		&dl.ID, &dl.EventID, &dl.SubscriberID, &dl.TotalAttempts,
-		&dl.LastError, &dl.LastHTTPStatus, &dl.CreatedAt,
+		&dl.ID, &dl.EventID, &dl.SubscriberID, &dl.EventType, &dl.EventSource, &payload, &dl.TotalAttempts,
+		&dl.LastError, &dl.LastHTTPStatus, &dl.FailureReason, &dl.CreatedAt,
 		&dl.ResolvedAt, &dl.ResolvedBy,
 	)
 	if err != nil {
@@ -155,6 +346,7 @@ func (s *PostgresStore) GetDeadLetter(ctx context.Context, id string) (*domain.D
 		}
 		return nil, fmt.Errorf("querying dead letter: %w", err)
 	}
+	dl.Payload = payload
 	return &dl, nil
 }
 
@@ -173,28 +365,269 @@ func (s *PostgresStore) ResolveDeadLetter(ctx context.Context, id string, resolv
 	return nil
 }
 
+func (s *PostgresStore) ResolveSubscriberDeadLetters(ctx context.Context, subscriberID string, resolvedBy string) (int64, error) {
+	result, err := s.pool.Exec(ctx, `
+		UPDATE dead_letter_queue SET resolved_at = NOW(), resolved_by = $2
+		WHERE subscriber_id = $1 AND resolved_at IS NULL
+	`, subscriberID, resolvedBy)
+	if err != nil {
+		return 0, fmt.Errorf("resolving dead letters: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
+
+func (s *PostgresStore) SummarizeDeadLetters(ctx context.Context, subscriberID string) ([]domain.DeadLetterGroup, error) {
+	query := `
+		SELECT d.subscriber_id, COALESCE(s.name, ''), d.failure_reason, d.last_http_status, COUNT(*), MIN(d.created_at), MAX(d.created_at)
+		FROM dead_letter_queue d
+		LEFT JOIN subscribers s ON s.id = d.subscriber_id
+		WHERE d.resolved_at IS NULL`
+	args := []interface{}{}
+	if subscriberID != "" {
+		query += " AND d.subscriber_id = $1"
+		args = append(args, subscriberID)
+	}
+	query += `
+		GROUP BY d.subscriber_id, s.name, d.failure_reason, d.last_http_status
+		ORDER BY COUNT(*) DESC, s.name, d.subscriber_id, d.failure_reason, d.last_http_status`
+
+	rows, err := s.reads().Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("summarizing dead letters: %w", err)
+	}
+	defer rows.Close()
+
+	groups := []domain.DeadLetterGroup{}
+	for rows.Next() {
+		var g domain.DeadLetterGroup
+		if err := rows.Scan(&g.SubscriberID, &g.SubscriberName, &g.FailureReason, &g.LastHTTPStatus, &g.Count, &g.FirstAt, &g.LastAt); err != nil {
+			return nil, fmt.Errorf("scanning dead letter group: %w", err)
+		}
+		groups = append(groups, g)
+	}
+	return groups, rows.Err()
+}
+
+// groupDeadLetters groups dead letters as SummarizeDeadLetters does, for
+// the stores that don't group them in SQL. names maps subscriber IDs to
+// their names.
+func groupDeadLetters(letters []domain.DeadLetter, names map[string]string) []domain.DeadLetterGroup {
+	type groupKey struct {
+		subscriberID string
+		reason       string
+		status       int
+	}
+	groups := []domain.DeadLetterGroup{}
+	index := map[groupKey]int{}
+	for _, dl := range letters {
+		key := groupKey{subscriberID: dl.SubscriberID}
+		if dl.FailureReason != nil {
+			key.reason = *dl.FailureReason
+		}
+		if dl.LastHTTPStatus != nil {
+			key.status = *dl.LastHTTPStatus
+		}
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, domain.DeadLetterGroup{
+				SubscriberID:   dl.SubscriberID,
+				SubscriberName: names[dl.SubscriberID],
+				FailureReason:  dl.FailureReason,
+				LastHTTPStatus: dl.LastHTTPStatus,
+				FirstAt:        dl.CreatedAt,
+				LastAt:         dl.CreatedAt,
+			})
+		}
+		g := &groups[i]
+		g.Count++
+		if dl.CreatedAt.Before(g.FirstAt) {
+			g.FirstAt = dl.CreatedAt
+		}
+		if dl.CreatedAt.After(g.LastAt) {
+			g.LastAt = dl.CreatedAt
+		}
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		a, b := groups[i], groups[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.SubscriberName != b.SubscriberName {
+			return a.SubscriberName < b.SubscriberName
+		}
+		return a.SubscriberID < b.SubscriberID
+	})
+	return groups
+}
+
+// ExpireDeadLetters auto-resolves up to limit unresolved dead letters created
+// before cutoff with resolved_by "expired", recording an audit entry for each
+// in the same statement. It returns the number of entries expired.
+func (s *PostgresStore) ExpireDeadLetters(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	result, err := s.pool.Exec(ctx, `
+		WITH expired AS (
+			UPDATE dead_letter_queue SET resolved_at = NOW(), resolved_by = $3
+			WHERE id IN (
+				SELECT id FROM dead_letter_queue
+				WHERE resolved_at IS NULL AND created_at < $1
+				ORDER BY created_at
+				LIMIT $2
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING id, subscriber_id, created_at
+		)
+		INSERT INTO audit_log (actor, action, entity_type, entity_id, details)
+		SELECT $4, $5, $6, id::text, jsonb_build_object('subscriber_id', subscriber_id, 'created_at', created_at)
+		FROM expired
+	`, cutoff, limit, domain.DeadLetterExpired, domain.SystemActor, domain.AuditDeadLetterExpire, domain.AuditEntityDeadLetter)
+	if err != nil {
+		return 0, fmt.Errorf("expiring dead letters: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
+
+// SubscriberDeadLetterCount is the number of dead letters a subscriber
+// accrued within a window.
+type SubscriberDeadLetterCount struct {
+	SubscriberID string
+	Name         string
+	EndpointURL  string
+	Count        int
+}
+
+// CountRecentDeadLetters returns subscribers with more than threshold dead
+// letters created since the given time, busiest first.
+func (s *PostgresStore) CountRecentDeadLetters(ctx context.Context, since time.Time, threshold int) ([]SubscriberDeadLetterCount, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT d.subscriber_id, s.name, s.endpoint_url, COUNT(*)
+		FROM dead_letter_queue d
+		JOIN subscribers s ON s.id = d.subscriber_id
+		WHERE d.created_at >= $1
+		GROUP BY d.subscriber_id, s.name, s.endpoint_url
+		HAVING COUNT(*) > $2
+		ORDER BY COUNT(*) DESC
+	`, since, threshold)
+	if err != nil {
+		return nil, fmt.Errorf("counting recent dead letters: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []SubscriberDeadLetterCount
+	for rows.Next() {
+		var c SubscriberDeadLetterCount
+		if err := rows.Scan(&c.SubscriberID, &c.Name, &c.EndpointURL, &c.Count); err != nil {
+			return nil, fmt.Errorf("scanning dead letter count: %w", err)
+		}
+		counts = append(counts, c)
+	}
+	return counts, rows.Err()
+}
+
+// DeliveryAttemptFilter narrows ListDeliveryAttempts. Empty fields match
+// everything. ResponseHeaders matches attempts whose captured headers include
+// every given name and value.
+type DeliveryAttemptFilter struct {
+	EventID         string
+	SubscriberID    string
+	Status          string
+	FailureReason   string
+	ResponseHeaders map[string]string
+	Since           time.Time
+	Until           time.Time
+	After           *PageCursor
+	Limit           int
+}
+
+// PageCursor marks the last row of a page of a newest-first listing. The
+// next page starts with the rows older than it, or as old with a smaller ID.
+type PageCursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// Follows reports whether a row created at createdAt with the given ID comes
+// after the cursor in a newest-first listing. A nil cursor is followed by
+// every row.
+func (c *PageCursor) Follows(createdAt time.Time, id string) bool {
+	if c == nil {
+		return true
+	}
+	return createdAt.Before(c.CreatedAt) || (createdAt.Equal(c.CreatedAt) && id < c.ID)
+}
+
+// Precedes reports whether a row created at createdAt with the given ID
+// comes after the cursor in an oldest-first listing. A nil cursor is
+// followed by every row.
+func (c *PageCursor) Precedes(createdAt time.Time, id string) bool {
+	if c == nil {
+		return true
+	}
+	return createdAt.After(c.CreatedAt) || (createdAt.Equal(c.CreatedAt) && id > c.ID)
+}
+
+// inRange reports whether t is within [since, until), where zero bounds are
+// open.
+func inRange(t, since, until time.Time) bool {
+	return (since.IsZero() || !t.Before(since)) && (until.IsZero() || t.Before(until))
+}
+
+// pageConditions appends the created_at range and cursor conditions of a
+// newest-first listing to a Postgres WHERE clause.
+func pageConditions(conditions []string, args []interface{}, argIdx int, since, until time.Time, after *PageCursor) ([]string, []interface{}, int) {
+	if !since.IsZero() {
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", argIdx))
+		args = append(args, since)
+		argIdx++
+	}
+	if !until.IsZero() {
+		conditions = append(conditions, fmt.Sprintf("created_at < $%d", argIdx))
+		args = append(args, until)
+		argIdx++
+	}
+	if after != nil {
+		conditions = append(conditions, fmt.Sprintf("(created_at, id) < ($%d, $%d)", argIdx, argIdx+1))
+		args = append(args, after.CreatedAt, after.ID)
+		argIdx += 2
+	}
+	return conditions, args, argIdx
+}
+
 // ListDeliveryAttempts returns delivery attempts with optional filtering.
-func (s *PostgresStore) ListDeliveryAttempts(ctx context.Context, eventID, subscriberID, status string, limit int) ([]domain.DeliveryAttempt, error) {
-	query := `SELECT id, event_id, subscriber_id, attempt_number, status, http_status_code, response_body, response_time_ms, error_message, next_retry_at, created_at FROM delivery_attempts`
+func (s *PostgresStore) ListDeliveryAttempts(ctx context.Context, f DeliveryAttemptFilter) ([]domain.DeliveryAttempt, error) {
+	query := `SELECT id, event_id, subscriber_id, attempt_number, status, http_status_code, response_body, response_headers, response_time_ms, timings, error_message, failure_reason, next_retry_at, final_url, created_at FROM delivery_attempts`
 	args := []interface{}{}
 	argIdx := 1
 	conditions := []string{}
 
-	if eventID != "" {
+	if f.EventID != "" {
 		conditions = append(conditions, fmt.Sprintf("event_id = $%d", argIdx))
-		args = append(args, eventID)
+		args = append(args, f.EventID)
 		argIdx++
 	}
-	if subscriberID != "" {
+	if f.SubscriberID != "" {
 		conditions = append(conditions, fmt.Sprintf("subscriber_id = $%d", argIdx))
-		args = append(args, subscriberID)
+		args = append(args, f.SubscriberID)
 		argIdx++
 	}
-	if status != "" {
+	if f.Status != "" {
 		conditions = append(conditions, fmt.Sprintf("status = $%d", argIdx))
-		args = append(args, status)
+		args = append(args, f.Status)
+		argIdx++
+	}
+	if f.FailureReason != "" {
+		conditions = append(conditions, fmt.Sprintf("failure_reason = $%d", argIdx))
+		args = append(args, f.FailureReason)
+		argIdx++
+	}
+	if len(f.ResponseHeaders) > 0 {
+		conditions = append(conditions, fmt.Sprintf("response_headers @> $%d", argIdx))
+		args = append(args, f.ResponseHeaders)
 		argIdx++
 	}
+	conditions, args, argIdx = pageConditions(conditions, args, argIdx, f.Since, f.Until, f.After)
 
 	if len(conditions) > 0 {
 		query += " WHERE "
@@ -206,14 +639,14 @@ func (s *PostgresStore) ListDeliveryAttempts(ctx context.Context, eventID, subsc
 		}
 	}
 
-	query += " ORDER BY created_at DESC"
+	query += " ORDER BY created_at DESC, id DESC"
 
-	if limit > 0 {
+	if f.Limit > 0 {
 		query += fmt.Sprintf(" LIMIT $%d", argIdx)
-		args = append(args, limit)
+		args = append(args, f.Limit)
 	}
 
-	rows, err := s.pool.Query(ctx, query, args...)
+	rows, err := s.reads().Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("querying delivery attempts: %w", err)
 	}
@@ -224,8 +657,8 @@ func (s *PostgresStore) ListDeliveryAttempts(ctx context.Context, eventID, subsc
 		var a domain.DeliveryAttempt
 		err := rows.Scan(
 			&a.ID, &a.EventID, &a.SubscriberID, &a.AttemptNumber,
-			&a.Status, &a.HTTPStatusCode, &a.ResponseBody,
-			&a.ResponseTimeMs, &a.ErrorMessage, &a.NextRetryAt, &a.CreatedAt,
+			&a.Status, &a.HTTPStatusCode, &a.ResponseBody, &a.ResponseHeaders,
+			&a.ResponseTimeMs, &a.Timings, &a.ErrorMessage, &a.FailureReason, &a.NextRetryAt, &a.FinalURL, &a.CreatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scanning delivery attempt: %w", err)
@@ -244,12 +677,12 @@ func (s *PostgresStore) ListDeliveryAttempts(ctx context.Context, eventID, subsc
 func (s *PostgresStore) GetDeliveryAttempt(ctx context.Context, id string) (*domain.DeliveryAttempt, error) {
 	var a domain.DeliveryAttempt
 	err := s.pool.QueryRow(ctx, `
-		SELECT id, event_id, subscriber_id, attempt_number, status, http_status_code, response_body, response_time_ms, error_message, next_retry_at, created_at
+		SELECT id, event_id, subscriber_id, attempt_number, status, http_status_code, response_body, response_headers, response_time_ms, timings, error_message, failure_reason, next_retry_at, final_url, created_at
 		FROM delivery_attempts WHERE id = $1
 	`, id).Scan(
 		&a.ID, &a.EventID, &a.SubscriberID, &a.AttemptNumber,
-		&a.Status, &a.HTTPStatusCode, &a.ResponseBody,
-		&a.ResponseTimeMs, &a.ErrorMessage, &a.NextRetryAt, &a.CreatedAt,
+		&a.Status, &a.HTTPStatusCode, &a.ResponseBody, &a.ResponseHeaders,
+		&a.ResponseTimeMs, &a.Timings, &a.ErrorMessage, &a.FailureReason, &a.NextRetryAt, &a.FinalURL, &a.CreatedAt,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
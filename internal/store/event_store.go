@@ -3,33 +3,63 @@ package store
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/Priya8975/webhook-delivery-system/internal/domain"
 	"github.com/jackc/pgx/v5"
 )
 
-func (s *PostgresStore) CreateEvent(ctx context.Context, eventType string, payload []byte, source string) (*domain.Event, error) {
-	var event domain.Event
-	err := s.pool.QueryRow(ctx, `
-		INSERT INTO events (event_type, payload, source)
-		VALUES ($1, $2, $3)
-		RETURNING id, event_type, payload, source, created_at
-	`, eventType, payload, source).Scan(
-		&event.ID, &event.EventType, &event.Payload, &event.Source, &event.CreatedAt,
+// outboxGracePeriod is how long the relay waits before picking up a new outbox
+// entry, giving the request path a chance to fan out inline first.
+const outboxGracePeriod = 10 * time.Second
+
+// CreateEvent inserts the event and its fan-out outbox entry in a single
+// transaction, so an event can never be persisted without a pending fan-out.
+func (s *PostgresStore) CreateEvent(ctx context.Context, eventType string, version int, payload []byte, ref *domain.PayloadRef, source string, subscriberIDs []string) (*domain.Event, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	refKey, refSize := splitPayloadRef(ref)
+	event := domain.Event{PayloadRef: ref}
+	err = tx.QueryRow(ctx, `
+		INSERT INTO events (event_type, version, payload, payload_ref, payload_size, source, subscriber_ids)
+		VALUES ($1, $2, $3, $4, $5, $6, $7::uuid[])
+		RETURNING id, event_type, version, payload, source, subscriber_ids::text[], created_at
+	`, eventType, version, payload, refKey, refSize, source, subscriberIDs).Scan(
+		&event.ID, &event.EventType, &event.Version, &event.Payload, &event.Source, &event.SubscriberIDs, &event.CreatedAt,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("inserting event: %w", err)
 	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO fanout_outbox (event_id, available_at)
+		VALUES ($1, $2)
+	`, event.ID, time.Now().Add(outboxGracePeriod))
+	if err != nil {
+		return nil, fmt.Errorf("inserting outbox entry: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("committing transaction: %w", err)
+	}
+
 	return &event, nil
 }
 
 func (s *PostgresStore) GetEvent(ctx context.Context, id string) (*domain.Event, error) {
 	var event domain.Event
+	var refKey string
+	var refSize int64
 	err := s.pool.QueryRow(ctx, `
-		SELECT id, event_type, payload, source, created_at
+		SELECT id, event_type, version, payload, payload_ref, payload_size, source, subscriber_ids::text[], created_at
 		FROM events WHERE id = $1
 	`, id).Scan(
-		&event.ID, &event.EventType, &event.Payload, &event.Source, &event.CreatedAt,
+		&event.ID, &event.EventType, &event.Version, &event.Payload, &refKey, &refSize, &event.Source, &event.SubscriberIDs, &event.CreatedAt,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -37,11 +67,12 @@ func (s *PostgresStore) GetEvent(ctx context.Context, id string) (*domain.Event,
 		}
 		return nil, fmt.Errorf("querying event: %w", err)
 	}
+	event.PayloadRef = payloadRef(refKey, refSize)
 	return &event, nil
 }
 
 func (s *PostgresStore) ListEvents(ctx context.Context, eventType string, limit int) ([]domain.Event, error) {
-	query := `SELECT id, event_type, payload, source, created_at FROM events`
+	query := `SELECT id, event_type, version, payload, payload_ref, payload_size, source, subscriber_ids::text[], created_at FROM events`
 	args := []interface{}{}
 	argIdx := 1
 
@@ -67,10 +98,13 @@ func (s *PostgresStore) ListEvents(ctx context.Context, eventType string, limit
 	var events []domain.Event
 	for rows.Next() {
 		var e domain.Event
-		err := rows.Scan(&e.ID, &e.EventType, &e.Payload, &e.Source, &e.CreatedAt)
+		var refKey string
+		var refSize int64
+		err := rows.Scan(&e.ID, &e.EventType, &e.Version, &e.Payload, &refKey, &refSize, &e.Source, &e.SubscriberIDs, &e.CreatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("scanning event: %w", err)
 		}
+		e.PayloadRef = payloadRef(refKey, refSize)
 		events = append(events, e)
 	}
 
@@ -81,38 +115,115 @@ func (s *PostgresStore) ListEvents(ctx context.Context, eventType string, limit
 	return events, nil
 }
 
+// EventSearchFilter selects events by the content of their payload, and
+// optionally by type.
+type EventSearchFilter struct {
+	Terms     []domain.PayloadTerm
+	EventType string
+	Limit     int
+}
+
+// SearchEvents finds events by JSONB containment, which the GIN index on
+// payload serves. Each term matches if the payload contains any of its
+// candidate values at the term's path.
+func (s *PostgresStore) SearchEvents(ctx context.Context, f EventSearchFilter) ([]domain.Event, error) {
+	query := `SELECT id, event_type, version, payload, payload_ref, payload_size, source, subscriber_ids::text[], created_at FROM events`
+	args := []interface{}{}
+	conditions := []string{}
+
+	for _, term := range f.Terms {
+		var alternatives []string
+		for _, c := range term.Candidates() {
+			args = append(args, string(term.Document(c)))
+			alternatives = append(alternatives, fmt.Sprintf("payload @> $%d::jsonb", len(args)))
+		}
+		conditions = append(conditions, "("+strings.Join(alternatives, " OR ")+")")
+	}
+	if f.EventType != "" {
+		args = append(args, f.EventType)
+		conditions = append(conditions, fmt.Sprintf("event_type = $%d", len(args)))
+	}
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query += " ORDER BY created_at DESC"
+	if f.Limit > 0 {
+		args = append(args, f.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := s.reads().Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("searching events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []domain.Event{}
+	for rows.Next() {
+		var e domain.Event
+		var refKey string
+		var refSize int64
+		err := rows.Scan(&e.ID, &e.EventType, &e.Version, &e.Payload, &refKey, &refSize, &e.Source, &e.SubscriberIDs, &e.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("scanning event: %w", err)
+		}
+		e.PayloadRef = payloadRef(refKey, refSize)
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
 // FindMatchingSubscribers finds all active subscribers whose event type
-// patterns match the given event type.
+// patterns match the given event type. Exact subscriptions are found through
+// the event_type index; wildcard patterns are fetched and matched with
+// domain.MatchEventType.
 func (s *PostgresStore) FindMatchingSubscribers(ctx context.Context, eventType string) ([]domain.Subscriber, error) {
 	rows, err := s.pool.Query(ctx, `
-		SELECT DISTINCT s.id, s.name, s.endpoint_url, s.secret_key, s.is_active,
-			   s.rate_limit_per_second, s.created_at, s.updated_at
-		FROM subscribers s
-		JOIN subscriptions sub ON s.id = sub.subscriber_id
-		WHERE s.is_active = true
-		  AND sub.is_active = true
-		  AND (
-			sub.event_type = $1
-			OR sub.event_type = '*'
-			OR (
-				sub.event_type LIKE '%.*'
-				AND $1 LIKE REPLACE(sub.event_type, '.*', '.%')
-			)
-		  )
+		SELECT DISTINCT subscriber_id, event_type
+		FROM subscriptions
+		WHERE is_active = true
+		  AND (event_type = $1 OR event_type LIKE '%*%')
 	`, eventType)
 	if err != nil {
 		return nil, fmt.Errorf("finding matching subscribers: %w", err)
 	}
+
+	var ids []string
+	seen := map[string]bool{}
+	for rows.Next() {
+		var id, pattern string
+		if err := rows.Scan(&id, &pattern); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scanning subscription: %w", err)
+		}
+		if !seen[id] && domain.MatchEventType(pattern, eventType) {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("finding matching subscribers: %w", err)
+	}
+
+	if len(ids) == 0 {
+		return []domain.Subscriber{}, nil
+	}
+	rows, err = s.pool.Query(ctx, `
+		SELECT `+subscriberColumns+`
+		FROM subscribers
+		WHERE is_active = true AND deleted_at IS NULL AND id = ANY($1::uuid[])
+	`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("finding matching subscribers: %w", err)
+	}
 	defer rows.Close()
 
 	var subscribers []domain.Subscriber
 	for rows.Next() {
 		var sub domain.Subscriber
-		err := rows.Scan(
-			&sub.ID, &sub.Name, &sub.EndpointURL, &sub.SecretKey,
-			&sub.IsActive, &sub.RateLimitPerSecond, &sub.CreatedAt, &sub.UpdatedAt,
-		)
-		if err != nil {
+		if err := scanSubscriber(rows, &sub); err != nil {
 			return nil, fmt.Errorf("scanning subscriber: %w", err)
 		}
 		subscribers = append(subscribers, sub)
@@ -124,3 +235,20 @@ func (s *PostgresStore) FindMatchingSubscribers(ctx context.Context, eventType s
 
 	return subscribers, nil
 }
+
+// splitPayloadRef returns the payload_ref and payload_size columns of an
+// event whose payload is at ref, which is nil for inline payloads.
+func splitPayloadRef(ref *domain.PayloadRef) (string, int64) {
+	if ref == nil {
+		return "", 0
+	}
+	return ref.Key, ref.Size
+}
+
+// payloadRef is the reverse of splitPayloadRef.
+func payloadRef(key string, size int64) *domain.PayloadRef {
+	if key == "" {
+		return nil
+	}
+	return &domain.PayloadRef{Key: key, Size: size}
+}
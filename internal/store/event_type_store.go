@@ -0,0 +1,108 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+)
+
+// ListEventTypes returns the event type catalog: every documented type plus
+// every type that has been published, with how often and when it was last
+// seen.
+func (s *PostgresStore) ListEventTypes(ctx context.Context) ([]domain.EventType, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT name, description, example_payload, current_version
+		FROM event_types
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying event types: %w", err)
+	}
+	var documented []domain.EventType
+	for rows.Next() {
+		et := domain.EventType{Documented: true}
+		var example []byte
+		if err := rows.Scan(&et.Name, &et.Description, &example, &et.CurrentVersion); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scanning event type: %w", err)
+		}
+		if example != nil {
+			et.ExamplePayload = json.RawMessage(example)
+		}
+		documented = append(documented, et)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("querying event types: %w", err)
+	}
+
+	rows, err = s.pool.Query(ctx, `
+		SELECT t.event_type, t.event_count, t.last_seen_at, t.version, latest.payload
+		FROM (
+			SELECT event_type, COUNT(*) AS event_count, MAX(created_at) AS last_seen_at,
+			       MAX(version) AS version
+			FROM events
+			GROUP BY event_type
+		) t
+		CROSS JOIN LATERAL (
+			SELECT payload FROM events e
+			WHERE e.event_type = t.event_type
+			ORDER BY e.created_at DESC
+			LIMIT 1
+		) latest
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying published event types: %w", err)
+	}
+	defer rows.Close()
+
+	var published []domain.EventType
+	for rows.Next() {
+		var et domain.EventType
+		var lastSeen time.Time
+		var payload []byte
+		if err := rows.Scan(&et.Name, &et.EventCount, &lastSeen, &et.CurrentVersion, &payload); err != nil {
+			return nil, fmt.Errorf("scanning published event type: %w", err)
+		}
+		et.LastSeenAt = &lastSeen
+		et.ExamplePayload = json.RawMessage(payload)
+		published = append(published, et)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("querying published event types: %w", err)
+	}
+
+	return mergeEventTypes(documented, published), nil
+}
+
+// DescribeEventType creates or replaces the catalog entry for an event type.
+func (s *PostgresStore) DescribeEventType(ctx context.Context, name string, req domain.DescribeEventTypeRequest) (*domain.EventType, error) {
+	var example []byte
+	if len(req.ExamplePayload) > 0 {
+		example = req.ExamplePayload
+	}
+	version := max(req.CurrentVersion, 1)
+
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO event_types (name, description, example_payload, current_version)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (name) DO UPDATE
+		SET description = EXCLUDED.description,
+		    example_payload = EXCLUDED.example_payload,
+		    current_version = EXCLUDED.current_version,
+		    updated_at = NOW()
+	`, name, req.Description, example, version)
+	if err != nil {
+		return nil, fmt.Errorf("describing event type: %w", err)
+	}
+
+	return &domain.EventType{
+		Name:           name,
+		Description:    req.Description,
+		ExamplePayload: req.ExamplePayload,
+		Documented:     true,
+		CurrentVersion: version,
+	}, nil
+}
@@ -0,0 +1,98 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+)
+
+// fanOutStatusColumns is the column list scanned by scanFanOutStatus.
+const fanOutStatusColumns = `event_id, subscriber_id, status, error, attempts, next_repair_at, updated_at`
+
+func scanFanOutStatus(row interface{ Scan(...interface{}) error }) (domain.FanOutStatus, error) {
+	var st domain.FanOutStatus
+	err := row.Scan(&st.EventID, &st.SubscriberID, &st.Status, &st.Error, &st.Attempts, &st.NextRepairAt, &st.UpdatedAt)
+	return st, err
+}
+
+// SaveFanOutStatuses upserts all statuses in one statement, as a fan-out can
+// cover thousands of subscribers.
+func (s *PostgresStore) SaveFanOutStatuses(ctx context.Context, statuses []domain.FanOutStatus) error {
+	if len(statuses) == 0 {
+		return nil
+	}
+	n := len(statuses)
+	eventIDs, subscriberIDs, states := make([]string, n), make([]string, n), make([]string, n)
+	errs, attempts, repairAts := make([]*string, n), make([]int32, n), make([]*time.Time, n)
+	for i, st := range statuses {
+		eventIDs[i], subscriberIDs[i], states[i] = st.EventID, st.SubscriberID, st.Status
+		errs[i], attempts[i], repairAts[i] = st.Error, int32(st.Attempts), st.NextRepairAt
+	}
+
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO fanout_status (event_id, subscriber_id, status, error, attempts, next_repair_at, updated_at)
+		SELECT u.event_id, u.subscriber_id, u.status, u.error, u.attempts, u.next_repair_at, NOW()
+		FROM unnest($1::uuid[], $2::uuid[], $3::text[], $4::text[], $5::int[], $6::timestamptz[])
+			AS u(event_id, subscriber_id, status, error, attempts, next_repair_at)
+		ON CONFLICT (event_id, subscriber_id) DO UPDATE SET
+			status = EXCLUDED.status,
+			error = EXCLUDED.error,
+			attempts = EXCLUDED.attempts,
+			next_repair_at = EXCLUDED.next_repair_at,
+			updated_at = EXCLUDED.updated_at
+	`, eventIDs, subscriberIDs, states, errs, attempts, repairAts)
+	if err != nil {
+		return fmt.Errorf("saving fan-out status: %w", err)
+	}
+	return nil
+}
+
+// ListFanOutStatuses returns an event's statuses, failed ones first.
+func (s *PostgresStore) ListFanOutStatuses(ctx context.Context, eventID string) ([]domain.FanOutStatus, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT `+fanOutStatusColumns+`
+		FROM fanout_status
+		WHERE event_id = $1
+		ORDER BY status = 'queued', subscriber_id
+	`, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("querying fan-out status: %w", err)
+	}
+	defer rows.Close()
+
+	statuses := []domain.FanOutStatus{}
+	for rows.Next() {
+		st, err := scanFanOutStatus(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning fan-out status: %w", err)
+		}
+		statuses = append(statuses, st)
+	}
+	return statuses, rows.Err()
+}
+
+func (s *PostgresStore) ListFanOutRepairs(ctx context.Context, limit int) ([]domain.FanOutStatus, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT `+fanOutStatusColumns+`
+		FROM fanout_status
+		WHERE status = 'failed' AND next_repair_at <= NOW()
+		ORDER BY next_repair_at
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying fan-out repairs: %w", err)
+	}
+	defer rows.Close()
+
+	var statuses []domain.FanOutStatus
+	for rows.Next() {
+		st, err := scanFanOutStatus(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning fan-out status: %w", err)
+		}
+		statuses = append(statuses, st)
+	}
+	return statuses, rows.Err()
+}
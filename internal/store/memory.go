@@ -0,0 +1,901 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+)
+
+// MemoryStore is an in-process Store for unit tests. It mirrors the behaviour
+// of PostgresStore that callers rely on (nil results for missing rows, newest
+// first listings, secrets hidden from listings) but keeps everything in
+// slices, so lookups are linear and nothing survives a restart.
+type MemoryStore struct {
+	mu            sync.Mutex
+	subscribers   []*domain.Subscriber
+	subscriptions []domain.Subscription
+	events        []domain.Event
+	attempts      []domain.DeliveryAttempt
+	deadLetters   []domain.DeadLetter
+	auditEntries  []domain.AuditEntry
+	apiKeys       []memoryAPIKey
+	eventTypes    map[string]domain.EventType
+	fanOut        []domain.FanOutStatus
+	usage         []domain.UsageDay
+}
+
+type memoryAPIKey struct {
+	key  domain.APIKey
+	hash string
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+func (s *MemoryStore) CreateSubscriber(ctx context.Context, req domain.CreateSubscriberRequest) (*domain.Subscriber, error) {
+	secretKey, err := subscriberSecretKey(req)
+	if err != nil {
+		return nil, fmt.Errorf("generating secret key: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	sub := &domain.Subscriber{
+		ID:                      newUUID(),
+		Name:                    req.Name,
+		EndpointURL:             req.EndpointURL,
+		SecretKey:               secretKey,
+		IsActive:                true,
+		RateLimitPerSecond:      10,
+		CompressPayloads:        req.CompressPayloads,
+		DiscardResponseBodies:   req.DiscardResponseBodies,
+		BatchMaxEvents:          req.BatchMaxEvents,
+		BatchWindowSeconds:      req.BatchWindowSeconds,
+		ProxyURL:                req.ProxyURL,
+		SignatureFormat:         domain.SignatureFormatOrDefault(req.SignatureFormat),
+		EventVersions:           eventVersionsOrEmpty(req.EventVersions),
+		Sandbox:                 req.Sandbox,
+		DailyQuota:              req.DailyQuota,
+		MonthlyQuota:            req.MonthlyQuota,
+		AutoReplayDeadLetters:   req.AutoReplayDeadLetters,
+		RetryJitter:             req.RetryJitter,
+		MaxRetryDurationSeconds: req.MaxRetryDurationSeconds,
+		RetryForDuration:        req.RetryForDuration,
+		SuccessBodyContains:     req.SuccessBodyContains,
+		SuccessJSONPath:         req.SuccessJSONPath,
+		RedirectPolicy:          req.RedirectPolicy,
+		EndpointType:            domain.EndpointTypeOrDefault(req.EndpointType),
+		EndpointCredentials:     endpointCredentialsOrNil(req.EndpointCredentials),
+		IsSystem:                req.IsSystem,
+		Version:                 1,
+		CreatedAt:               now,
+		UpdatedAt:               now,
+	}
+	s.subscribers = append(s.subscribers, sub)
+
+	for _, eventType := range req.EventTypes {
+		s.subscriptions = append(s.subscriptions, domain.Subscription{
+			ID:           newUUID(),
+			SubscriberID: sub.ID,
+			EventType:    eventType,
+			IsActive:     true,
+			CreatedAt:    now,
+		})
+	}
+
+	created := *sub
+	return &created, nil
+}
+
+func (s *MemoryStore) GetSubscriber(ctx context.Context, id string) (*domain.Subscriber, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub := s.findSubscriber(id)
+	if sub == nil {
+		return nil, nil
+	}
+	found := *sub
+	return &found, nil
+}
+
+func (s *MemoryStore) ListSubscribers(ctx context.Context) ([]domain.Subscriber, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subscribers := []domain.Subscriber{}
+	for i := len(s.subscribers) - 1; i >= 0; i-- {
+		if s.subscribers[i].Deleted() {
+			continue
+		}
+		sub := *s.subscribers[i]
+		sub.SecretKey = ""
+		sub.EndpointCredentials = nil
+		subscribers = append(subscribers, sub)
+	}
+	return subscribers, nil
+}
+
+func (s *MemoryStore) UpdateSubscriber(ctx context.Context, id string, req domain.UpdateSubscriberRequest) (*domain.Subscriber, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub := s.findSubscriber(id)
+	if sub == nil {
+		return nil, nil
+	}
+	if req.Version != nil && sub.Version != *req.Version {
+		return nil, ErrVersionConflict
+	}
+
+	changed := false
+	if req.Name != nil {
+		sub.Name, changed = *req.Name, true
+	}
+	if req.EndpointURL != nil {
+		sub.EndpointURL, changed = *req.EndpointURL, true
+	}
+	if req.IsActive != nil {
+		sub.IsActive, changed = *req.IsActive, true
+	}
+	if req.RateLimitPerSecond != nil {
+		sub.RateLimitPerSecond, changed = *req.RateLimitPerSecond, true
+	}
+	if req.CompressPayloads != nil {
+		sub.CompressPayloads, changed = *req.CompressPayloads, true
+	}
+	if req.DiscardResponseBodies != nil {
+		sub.DiscardResponseBodies, changed = *req.DiscardResponseBodies, true
+	}
+	if req.BatchMaxEvents != nil {
+		sub.BatchMaxEvents, changed = *req.BatchMaxEvents, true
+	}
+	if req.BatchWindowSeconds != nil {
+		sub.BatchWindowSeconds, changed = *req.BatchWindowSeconds, true
+	}
+	if req.ProxyURL != nil {
+		sub.ProxyURL, changed = *req.ProxyURL, true
+	}
+	if req.DebugLogging != nil {
+		sub.DebugLogging, changed = *req.DebugLogging, true
+	}
+	if req.SignatureFormat != nil {
+		sub.SignatureFormat, changed = domain.SignatureFormatOrDefault(*req.SignatureFormat), true
+	}
+	if req.EventVersions != nil {
+		sub.EventVersions, changed = eventVersionsOrEmpty(req.EventVersions), true
+	}
+	if req.Sandbox != nil {
+		sub.Sandbox, changed = *req.Sandbox, true
+	}
+	if req.DailyQuota != nil {
+		sub.DailyQuota, changed = *req.DailyQuota, true
+	}
+	if req.MonthlyQuota != nil {
+		sub.MonthlyQuota, changed = *req.MonthlyQuota, true
+	}
+	if req.AutoReplayDeadLetters != nil {
+		sub.AutoReplayDeadLetters, changed = *req.AutoReplayDeadLetters, true
+	}
+	if req.RetryJitter != nil {
+		sub.RetryJitter, changed = *req.RetryJitter, true
+	}
+	if req.MaxRetryDurationSeconds != nil {
+		sub.MaxRetryDurationSeconds, changed = *req.MaxRetryDurationSeconds, true
+	}
+	if req.RetryForDuration != nil {
+		sub.RetryForDuration, changed = *req.RetryForDuration, true
+	}
+	if req.SuccessBodyContains != nil {
+		sub.SuccessBodyContains, changed = *req.SuccessBodyContains, true
+	}
+	if req.SuccessJSONPath != nil {
+		sub.SuccessJSONPath, changed = *req.SuccessJSONPath, true
+	}
+	if req.RedirectPolicy != nil {
+		sub.RedirectPolicy, changed = *req.RedirectPolicy, true
+	}
+	if req.EndpointType != nil {
+		sub.EndpointType, changed = domain.EndpointTypeOrDefault(*req.EndpointType), true
+	}
+	if req.EndpointCredentials != nil {
+		sub.EndpointCredentials, changed = endpointCredentialsOrNil(req.EndpointCredentials), true
+	}
+
+	updated := *sub
+	if changed {
+		sub.Version++
+		sub.UpdatedAt = time.Now()
+		updated = *sub
+		updated.SecretKey = ""
+		updated.EndpointCredentials = nil
+	}
+	return &updated, nil
+}
+
+// eventVersionsOrEmpty copies a subscriber's version pins, returning an
+// empty map rather than nil as the database stores do.
+func eventVersionsOrEmpty(versions map[string]int) map[string]int {
+	if versions == nil {
+		return map[string]int{}
+	}
+	return maps.Clone(versions)
+}
+
+func endpointCredentialsOrNil(creds *domain.EndpointCredentials) *domain.EndpointCredentials {
+	if creds.IsZero() {
+		return nil
+	}
+	clone := *creds
+	return &clone
+}
+
+func (s *MemoryStore) DeleteSubscriber(ctx context.Context, id string) (*domain.Subscriber, error) {
+	now := time.Now()
+	return s.setSubscriberDeletedAt(id, &now)
+}
+
+func (s *MemoryStore) RestoreSubscriber(ctx context.Context, id string) (*domain.Subscriber, error) {
+	return s.setSubscriberDeletedAt(id, nil)
+}
+
+func (s *MemoryStore) setSubscriberDeletedAt(id string, deletedAt *time.Time) (*domain.Subscriber, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub := s.findSubscriber(id)
+	if sub == nil {
+		return nil, nil
+	}
+	sub.DeletedAt = deletedAt
+	sub.Version++
+	sub.UpdatedAt = time.Now()
+	updated := *sub
+	updated.SecretKey = ""
+	updated.EndpointCredentials = nil
+	return &updated, nil
+}
+
+func (s *MemoryStore) GetSubscriberSubscriptions(ctx context.Context, subscriberID string) ([]domain.Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subs := []domain.Subscription{}
+	for _, sub := range s.subscriptions {
+		if sub.SubscriberID == subscriberID {
+			subs = append(subs, sub)
+		}
+	}
+	return subs, nil
+}
+
+func (s *MemoryStore) ListActiveSubscriptions(ctx context.Context) ([]domain.Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subs := []domain.Subscription{}
+	for _, sub := range s.subscriptions {
+		if sub.IsActive {
+			subs = append(subs, sub)
+		}
+	}
+	return subs, nil
+}
+
+func (s *MemoryStore) FindMatchingSubscribers(ctx context.Context, eventType string) ([]domain.Subscriber, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subscribers := []domain.Subscriber{}
+	for _, sub := range s.subscribers {
+		if !sub.IsActive || sub.Deleted() {
+			continue
+		}
+		for _, subscription := range s.subscriptions {
+			if subscription.SubscriberID == sub.ID && subscription.IsActive && domain.MatchEventType(subscription.EventType, eventType) {
+				subscribers = append(subscribers, *sub)
+				break
+			}
+		}
+	}
+	return subscribers, nil
+}
+
+func (s *MemoryStore) CreateEvent(ctx context.Context, eventType string, version int, payload []byte, ref *domain.PayloadRef, source string, subscriberIDs []string) (*domain.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	event := domain.Event{
+		ID:            newUUID(),
+		EventType:     eventType,
+		Version:       version,
+		Payload:       append([]byte(nil), payload...),
+		Source:        source,
+		SubscriberIDs: append([]string(nil), subscriberIDs...),
+		PayloadRef:    ref,
+		CreatedAt:     time.Now(),
+	}
+	s.events = append(s.events, event)
+	return &event, nil
+}
+
+func (s *MemoryStore) GetEvent(ctx context.Context, id string) (*domain.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	event := s.findEvent(id)
+	if event == nil {
+		return nil, nil
+	}
+	found := *event
+	return &found, nil
+}
+
+func (s *MemoryStore) ListEvents(ctx context.Context, eventType string, limit int) ([]domain.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	events := []domain.Event{}
+	for i := len(s.events) - 1; i >= 0 && (limit <= 0 || len(events) < limit); i-- {
+		if eventType == "" || s.events[i].EventType == eventType {
+			events = append(events, s.events[i])
+		}
+	}
+	return events, nil
+}
+
+func (s *MemoryStore) SearchEvents(ctx context.Context, f EventSearchFilter) ([]domain.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	events := []domain.Event{}
+	for i := len(s.events) - 1; i >= 0 && (f.Limit <= 0 || len(events) < f.Limit); i-- {
+		e := s.events[i]
+		if f.EventType != "" && e.EventType != f.EventType {
+			continue
+		}
+		matched := true
+		for _, term := range f.Terms {
+			if !term.Matches(e.Payload) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+func (s *MemoryStore) ListEventTypes(ctx context.Context) ([]domain.EventType, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var documented []domain.EventType
+	for _, et := range s.eventTypes {
+		documented = append(documented, et)
+	}
+
+	var published []domain.EventType
+	index := map[string]int{}
+	for _, e := range s.events {
+		i, ok := index[e.EventType]
+		if !ok {
+			i = len(published)
+			index[e.EventType] = i
+			published = append(published, domain.EventType{Name: e.EventType})
+		}
+		et := &published[i]
+		et.EventCount++
+		et.CurrentVersion = max(et.CurrentVersion, e.Version)
+		if et.LastSeenAt == nil || !e.CreatedAt.Before(*et.LastSeenAt) {
+			seen := e.CreatedAt
+			et.LastSeenAt = &seen
+			et.ExamplePayload = e.Payload
+		}
+	}
+
+	return mergeEventTypes(documented, published), nil
+}
+
+func (s *MemoryStore) DescribeEventType(ctx context.Context, name string, req domain.DescribeEventTypeRequest) (*domain.EventType, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	et := domain.EventType{
+		Name:           name,
+		Description:    req.Description,
+		ExamplePayload: req.ExamplePayload,
+		Documented:     true,
+		CurrentVersion: max(req.CurrentVersion, 1),
+	}
+	if s.eventTypes == nil {
+		s.eventTypes = make(map[string]domain.EventType)
+	}
+	s.eventTypes[name] = et
+	return &et, nil
+}
+
+func (s *MemoryStore) RecordDeliveryAttempt(ctx context.Context, rec DeliveryAttemptRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.insertAttempt(rec)
+	return nil
+}
+
+func (s *MemoryStore) InsertDeliveryAttempts(ctx context.Context, recs []DeliveryAttemptRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, rec := range recs {
+		s.insertAttempt(rec)
+	}
+	return nil
+}
+
+func (s *MemoryStore) insertAttempt(rec DeliveryAttemptRecord) {
+	now := time.Now()
+	s.attempts = append(s.attempts, rec.Attempt(now))
+
+	day := usageDay(now)
+	for _, delta := range usageDeltas([]DeliveryAttemptRecord{rec}) {
+		i := 0
+		for i < len(s.usage) && !(s.usage[i].Day.Equal(day) && s.usage[i].SubscriberID == delta.SubscriberID) {
+			i++
+		}
+		if i == len(s.usage) {
+			s.usage = append(s.usage, domain.UsageDay{Day: day, Usage: domain.Usage{SubscriberID: delta.SubscriberID}})
+		}
+		u := &s.usage[i]
+		u.Deliveries += delta.Deliveries
+		u.Successful += delta.Successful
+		u.Retries += delta.Retries
+		u.PayloadBytes += delta.PayloadBytes
+	}
+}
+
+func (s *MemoryStore) ListUsage(ctx context.Context, f UsageFilter) ([]domain.UsageDay, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	from, to := usageDay(f.From), usageDay(f.To)
+	days := []domain.UsageDay{}
+	for _, u := range s.usage {
+		if u.Day.Before(from) || u.Day.After(to) || (f.SubscriberID != "" && u.SubscriberID != f.SubscriberID) {
+			continue
+		}
+		if sub := s.findSubscriber(u.SubscriberID); sub != nil {
+			u.SubscriberName = sub.Name
+		}
+		days = append(days, u)
+	}
+	sort.SliceStable(days, func(i, j int) bool {
+		if !days[i].Day.Equal(days[j].Day) {
+			return days[i].Day.Before(days[j].Day)
+		}
+		return days[i].SubscriberName < days[j].SubscriberName
+	})
+	return days, nil
+}
+
+func (s *MemoryStore) ListDeliveryAttempts(ctx context.Context, f DeliveryAttemptFilter) ([]domain.DeliveryAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempts := []domain.DeliveryAttempt{}
+	for i := len(s.attempts) - 1; i >= 0 && (f.Limit <= 0 || len(attempts) < f.Limit); i-- {
+		a := s.attempts[i]
+		if f.EventID != "" && a.EventID != f.EventID {
+			continue
+		}
+		if f.SubscriberID != "" && a.SubscriberID != f.SubscriberID {
+			continue
+		}
+		if f.Status != "" && a.Status != f.Status {
+			continue
+		}
+		if f.FailureReason != "" && (a.FailureReason == nil || *a.FailureReason != f.FailureReason) {
+			continue
+		}
+		if !containsHeaders(a.ResponseHeaders, f.ResponseHeaders) {
+			continue
+		}
+		if !inRange(a.CreatedAt, f.Since, f.Until) || !f.After.Follows(a.CreatedAt, a.ID) {
+			continue
+		}
+		attempts = append(attempts, a)
+	}
+	return attempts, nil
+}
+
+// containsHeaders reports whether have includes every name and value in want.
+func containsHeaders(have, want map[string]string) bool {
+	for name, value := range want {
+		if v, ok := have[name]; !ok || v != value {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *MemoryStore) GetDeliveryAttempt(ctx context.Context, id string) (*domain.DeliveryAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, a := range s.attempts {
+		if a.ID == id {
+			return &a, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *MemoryStore) InsertDeadLetter(ctx context.Context, rec DeadLetterRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dl := domain.DeadLetter{
+		ID:             newUUID(),
+		EventID:        rec.EventID,
+		SubscriberID:   rec.SubscriberID,
+		TotalAttempts:  rec.TotalAttempts,
+		LastHTTPStatus: rec.LastHTTPStatus,
+		CreatedAt:      time.Now(),
+	}
+	if rec.LastError != "" {
+		dl.LastError = &rec.LastError
+	}
+	if rec.FailureReason != "" {
+		reason := string(rec.FailureReason)
+		dl.FailureReason = &reason
+	}
+	if event := s.findEvent(rec.EventID); event != nil {
+		eventType, source := event.EventType, event.Source
+		dl.EventType = &eventType
+		dl.EventSource = &source
+		dl.Payload = event.Payload
+	}
+	s.deadLetters = append(s.deadLetters, dl)
+	return nil
+}
+
+// ListDeadLetters leaves Payload empty, like the Postgres listing.
+func (s *MemoryStore) ListDeadLetters(ctx context.Context, f DeadLetterFilter) ([]domain.DeadLetter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	letters := []domain.DeadLetter{}
+	for n := 0; n < len(s.deadLetters) && (f.Limit <= 0 || len(letters) < f.Limit); n++ {
+		dl := s.deadLetters[len(s.deadLetters)-1-n]
+		if f.OldestFirst {
+			dl = s.deadLetters[n]
+		}
+		if f.SubscriberID != "" && dl.SubscriberID != f.SubscriberID {
+			continue
+		}
+		if f.Resolved != nil && (dl.ResolvedAt != nil) != *f.Resolved {
+			continue
+		}
+		if !inRange(dl.CreatedAt, f.Since, f.Until) {
+			continue
+		}
+		if (f.OldestFirst && !f.After.Precedes(dl.CreatedAt, dl.ID)) || (!f.OldestFirst && !f.After.Follows(dl.CreatedAt, dl.ID)) {
+			continue
+		}
+		dl.Payload = nil
+		letters = append(letters, dl)
+	}
+	return letters, nil
+}
+
+func (s *MemoryStore) GetDeadLetter(ctx context.Context, id string) (*domain.DeadLetter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, dl := range s.deadLetters {
+		if dl.ID == id {
+			return &dl, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *MemoryStore) ResolveDeadLetter(ctx context.Context, id string, resolvedBy string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.deadLetters {
+		dl := &s.deadLetters[i]
+		if dl.ID == id && dl.ResolvedAt == nil {
+			now := time.Now()
+			dl.ResolvedAt = &now
+			dl.ResolvedBy = &resolvedBy
+			return nil
+		}
+	}
+	return fmt.Errorf("dead letter not found or already resolved")
+}
+
+func (s *MemoryStore) ResolveSubscriberDeadLetters(ctx context.Context, subscriberID string, resolvedBy string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var resolved int64
+	now := time.Now()
+	for i := range s.deadLetters {
+		dl := &s.deadLetters[i]
+		if dl.SubscriberID == subscriberID && dl.ResolvedAt == nil {
+			dl.ResolvedAt = &now
+			dl.ResolvedBy = &resolvedBy
+			resolved++
+		}
+	}
+	return resolved, nil
+}
+
+func (s *MemoryStore) SummarizeDeadLetters(ctx context.Context, subscriberID string) ([]domain.DeadLetterGroup, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var letters []domain.DeadLetter
+	names := map[string]string{}
+	for _, dl := range s.deadLetters {
+		if dl.ResolvedAt != nil || (subscriberID != "" && dl.SubscriberID != subscriberID) {
+			continue
+		}
+		letters = append(letters, dl)
+		if sub := s.findSubscriber(dl.SubscriberID); sub != nil {
+			names[sub.ID] = sub.Name
+		}
+	}
+	return groupDeadLetters(letters, names), nil
+}
+
+func (s *MemoryStore) InsertAuditEntry(ctx context.Context, e *domain.AuditEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e.ID = newUUID()
+	e.CreatedAt = time.Now()
+	s.auditEntries = append(s.auditEntries, *e)
+	return nil
+}
+
+func (s *MemoryStore) ListAuditEntries(ctx context.Context, f domain.AuditFilter) ([]domain.AuditEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := []domain.AuditEntry{}
+	for i := len(s.auditEntries) - 1; i >= 0 && (f.Limit <= 0 || len(entries) < f.Limit); i-- {
+		e := s.auditEntries[i]
+		if (f.Actor != "" && e.Actor != f.Actor) ||
+			(f.Action != "" && e.Action != f.Action) ||
+			(f.EntityType != "" && e.EntityType != f.EntityType) ||
+			(f.EntityID != "" && e.EntityID != f.EntityID) ||
+			(!f.Since.IsZero() && e.CreatedAt.Before(f.Since)) ||
+			(!f.Until.IsZero() && !e.CreatedAt.Before(f.Until)) {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+func (s *MemoryStore) CreateAPIKey(ctx context.Context, name string, role domain.Role, subscriberID *string) (*domain.APIKey, string, error) {
+	key, err := generateAPIKey()
+	if err != nil {
+		return nil, "", fmt.Errorf("generating api key: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := domain.APIKey{
+		ID:           newUUID(),
+		Name:         name,
+		KeyPrefix:    key[:apiKeyPrefixLen],
+		Role:         role,
+		SubscriberID: subscriberID,
+		CreatedAt:    time.Now(),
+	}
+	s.apiKeys = append(s.apiKeys, memoryAPIKey{key: k, hash: HashAPIKey(key)})
+	return &k, key, nil
+}
+
+func (s *MemoryStore) GetAPIKeyByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, k := range s.apiKeys {
+		if k.hash == hash && k.key.RevokedAt == nil {
+			return &k.key, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *MemoryStore) GetAPIKey(ctx context.Context, id string) (*domain.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if k := s.findAPIKey(id); k != nil {
+		found := k.key
+		return &found, nil
+	}
+	return nil, nil
+}
+
+func (s *MemoryStore) ListAPIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := []domain.APIKey{}
+	for i := len(s.apiKeys) - 1; i >= 0; i-- {
+		keys = append(keys, s.apiKeys[i].key)
+	}
+	return keys, nil
+}
+
+func (s *MemoryStore) RevokeAPIKey(ctx context.Context, id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := s.findAPIKey(id)
+	if k == nil || k.key.RevokedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	k.key.RevokedAt = &now
+	return true, nil
+}
+
+func (s *MemoryStore) SetAPIKeyRole(ctx context.Context, id string, role domain.Role) (*domain.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := s.findAPIKey(id)
+	if k == nil || k.key.RevokedAt != nil {
+		return nil, nil
+	}
+	k.key.Role = role
+	updated := k.key
+	return &updated, nil
+}
+
+func (s *MemoryStore) TouchAPIKey(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if k := s.findAPIKey(id); k != nil {
+		now := time.Now()
+		if k.key.LastUsedAt == nil || k.key.LastUsedAt.Before(now.Add(-time.Minute)) {
+			k.key.LastUsedAt = &now
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) GetSubscriberStats(ctx context.Context, subscriberID string, since time.Time) (*SubscriberStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st := SubscriberStats{SubscriberID: subscriberID, Since: since}
+
+	var attempts []domain.DeliveryAttempt
+	for _, a := range s.attempts {
+		if a.SubscriberID == subscriberID && !a.CreatedAt.Before(since) {
+			attempts = append(attempts, a)
+		}
+	}
+	addAttemptStats(&st, attempts)
+
+	for _, dl := range s.deadLetters {
+		if dl.SubscriberID != subscriberID {
+			continue
+		}
+		if !dl.CreatedAt.Before(since) {
+			st.DeadLetters++
+		}
+		if dl.ResolvedAt == nil {
+			st.OpenDeadLetters++
+		}
+	}
+
+	return &st, nil
+}
+
+func (s *MemoryStore) SaveFanOutStatuses(ctx context.Context, statuses []domain.FanOutStatus) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for _, st := range statuses {
+		st.UpdatedAt = now
+		replaced := false
+		for i := range s.fanOut {
+			if s.fanOut[i].EventID == st.EventID && s.fanOut[i].SubscriberID == st.SubscriberID {
+				s.fanOut[i] = st
+				replaced = true
+			}
+		}
+		if !replaced {
+			s.fanOut = append(s.fanOut, st)
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) ListFanOutStatuses(ctx context.Context, eventID string) ([]domain.FanOutStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := []domain.FanOutStatus{}
+	for _, st := range s.fanOut {
+		if st.EventID == eventID {
+			statuses = append(statuses, st)
+		}
+	}
+	sort.SliceStable(statuses, func(i, j int) bool {
+		a, b := statuses[i], statuses[j]
+		if (a.Status == domain.FanOutQueued) != (b.Status == domain.FanOutQueued) {
+			return b.Status == domain.FanOutQueued
+		}
+		return a.SubscriberID < b.SubscriberID
+	})
+	return statuses, nil
+}
+
+func (s *MemoryStore) ListFanOutRepairs(ctx context.Context, limit int) ([]domain.FanOutStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var due []domain.FanOutStatus
+	for _, st := range s.fanOut {
+		if st.Status == domain.FanOutFailed && st.NextRepairAt != nil && !st.NextRepairAt.After(now) {
+			due = append(due, st)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool { return due[i].NextRepairAt.Before(*due[j].NextRepairAt) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+func (s *MemoryStore) findSubscriber(id string) *domain.Subscriber {
+	for _, sub := range s.subscribers {
+		if sub.ID == id {
+			return sub
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) findEvent(id string) *domain.Event {
+	for i := range s.events {
+		if s.events[i].ID == id {
+			return &s.events[i]
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) findAPIKey(id string) *memoryAPIKey {
+	for i := range s.apiKeys {
+		if s.apiKeys[i].key.ID == id {
+			return &s.apiKeys[i]
+		}
+	}
+	return nil
+}
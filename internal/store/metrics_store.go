@@ -3,6 +3,7 @@ package store
 import (
 	"context"
 	"fmt"
+	"time"
 )
 
 // DeliveryMetrics holds aggregated delivery statistics.
@@ -18,28 +19,64 @@ type DeliveryMetrics struct {
 }
 
 // GetDeliveryMetrics returns aggregated delivery statistics from the database.
+//
+// Delivery counts come from the delivery_metrics_hourly rollup for every hour
+// before the latest rolled-up hour; only attempts from that hour onward are
+// aggregated from delivery_attempts, so the query stays cheap as the table
+// grows. With an empty rollup it falls back to scanning all attempts.
 func (s *PostgresStore) GetDeliveryMetrics(ctx context.Context) (*DeliveryMetrics, error) {
 	var m DeliveryMetrics
 
+	watermark, _, err := s.LatestRollupHour(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	// Delivery counts and average response time
-	err := s.pool.QueryRow(ctx, `
+	var responseSum float64
+	var responseCount int
+	err = s.reads().QueryRow(ctx, `
+		WITH rolled AS (
+			SELECT
+				COALESCE(SUM(total_attempts), 0) AS total,
+				COALESCE(SUM(success_count), 0) AS success,
+				COALESCE(SUM(failed_count), 0) AS failed,
+				COALESCE(SUM(response_time_sum_ms), 0) AS rt_sum,
+				COALESCE(SUM(response_time_count), 0) AS rt_count
+			FROM delivery_metrics_hourly
+			WHERE hour < $1
+		), live AS (
+			SELECT
+				COUNT(*) AS total,
+				COUNT(*) FILTER (WHERE status = 'success') AS success,
+				COUNT(*) FILTER (WHERE status = 'failed') AS failed,
+				COALESCE(SUM(response_time_ms) FILTER (WHERE response_time_ms > 0), 0) AS rt_sum,
+				COUNT(*) FILTER (WHERE response_time_ms > 0) AS rt_count
+			FROM delivery_attempts
+			WHERE created_at >= $1 AND status <> 'simulated'
+		)
 		SELECT
-			COUNT(*) AS total,
-			COUNT(*) FILTER (WHERE status = 'success') AS success,
-			COUNT(*) FILTER (WHERE status = 'failed') AS failed,
-			COALESCE(AVG(response_time_ms) FILTER (WHERE response_time_ms > 0), 0) AS avg_response_ms
-		FROM delivery_attempts
-	`).Scan(&m.TotalDeliveries, &m.SuccessCount, &m.FailedCount, &m.AvgResponseMs)
+			rolled.total + live.total,
+			rolled.success + live.success,
+			rolled.failed + live.failed,
+			rolled.rt_sum + live.rt_sum,
+			rolled.rt_count + live.rt_count
+		FROM rolled, live
+	`, watermark).Scan(&m.TotalDeliveries, &m.SuccessCount, &m.FailedCount, &responseSum, &responseCount)
 	if err != nil {
 		return nil, fmt.Errorf("querying delivery metrics: %w", err)
 	}
 
+	if responseCount > 0 {
+		m.AvgResponseMs = responseSum / float64(responseCount)
+	}
+
 	if m.TotalDeliveries > 0 {
 		m.SuccessRate = float64(m.SuccessCount) / float64(m.TotalDeliveries) * 100
 	}
 
 	// Unresolved dead letters
-	err = s.pool.QueryRow(ctx, `
+	err = s.reads().QueryRow(ctx, `
 		SELECT COUNT(*) FROM dead_letter_queue WHERE resolved_at IS NULL
 	`).Scan(&m.DeadLetterCount)
 	if err != nil {
@@ -47,15 +84,15 @@ func (s *PostgresStore) GetDeliveryMetrics(ctx context.Context) (*DeliveryMetric
 	}
 
 	// Active subscribers
-	err = s.pool.QueryRow(ctx, `
-		SELECT COUNT(*) FROM subscribers WHERE is_active = true
+	err = s.reads().QueryRow(ctx, `
+		SELECT COUNT(*) FROM subscribers WHERE is_active = true AND deleted_at IS NULL
 	`).Scan(&m.ActiveSubscribers)
 	if err != nil {
 		return nil, fmt.Errorf("querying active subscribers: %w", err)
 	}
 
 	// Total events
-	err = s.pool.QueryRow(ctx, `
+	err = s.reads().QueryRow(ctx, `
 		SELECT COUNT(*) FROM events
 	`).Scan(&m.TotalEvents)
 	if err != nil {
@@ -64,3 +101,290 @@ func (s *PostgresStore) GetDeliveryMetrics(ctx context.Context) (*DeliveryMetric
 
 	return &m, nil
 }
+
+// SubscriberStats holds delivery statistics for one subscriber over a window.
+type SubscriberStats struct {
+	SubscriberID    string          `json:"subscriber_id"`
+	Since           time.Time       `json:"since"`
+	TotalAttempts   int             `json:"total_attempts"`
+	SuccessCount    int             `json:"success_count"`
+	FailedCount     int             `json:"failed_count"`
+	SuccessRate     float64         `json:"success_rate"`
+	LatencyP50Ms    float64         `json:"latency_p50_ms"`
+	LatencyP95Ms    float64         `json:"latency_p95_ms"`
+	LatencyP99Ms    float64         `json:"latency_p99_ms"`
+	Retries         int             `json:"retries"`
+	DeadLetters     int             `json:"dead_letters"`
+	OpenDeadLetters int             `json:"open_dead_letters"`
+	FailureReasons  map[string]int  `json:"failure_reasons"` // failed attempts by domain.FailureReason
+	AttemptsPerDay  []DailyAttempts `json:"attempts_per_day"`
+}
+
+// DailyAttempts is the attempt count for one UTC day.
+type DailyAttempts struct {
+	Day          time.Time `json:"day"`
+	Attempts     int       `json:"attempts"`
+	SuccessCount int       `json:"success_count"`
+}
+
+// GetSubscriberStats aggregates a subscriber's delivery attempts and dead
+// letters created at or after since.
+func (s *PostgresStore) GetSubscriberStats(ctx context.Context, subscriberID string, since time.Time) (*SubscriberStats, error) {
+	st := SubscriberStats{SubscriberID: subscriberID, Since: since}
+
+	// Counts and latency percentiles
+	err := s.reads().QueryRow(ctx, `
+		SELECT
+			COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE status = 'success') AS success,
+			COUNT(*) FILTER (WHERE status = 'failed') AS failed,
+			COUNT(*) FILTER (WHERE attempt_number > 1) AS retries,
+			COALESCE(percentile_cont(0.50) WITHIN GROUP (ORDER BY response_time_ms) FILTER (WHERE response_time_ms > 0), 0),
+			COALESCE(percentile_cont(0.95) WITHIN GROUP (ORDER BY response_time_ms) FILTER (WHERE response_time_ms > 0), 0),
+			COALESCE(percentile_cont(0.99) WITHIN GROUP (ORDER BY response_time_ms) FILTER (WHERE response_time_ms > 0), 0)
+		FROM delivery_attempts
+		WHERE subscriber_id = $1 AND created_at >= $2 AND status <> 'simulated'
+	`, subscriberID, since).Scan(
+		&st.TotalAttempts, &st.SuccessCount, &st.FailedCount, &st.Retries,
+		&st.LatencyP50Ms, &st.LatencyP95Ms, &st.LatencyP99Ms,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying subscriber delivery stats: %w", err)
+	}
+
+	if st.TotalAttempts > 0 {
+		st.SuccessRate = float64(st.SuccessCount) / float64(st.TotalAttempts) * 100
+	}
+
+	// Dead letters created in the window, and those still unresolved
+	err = s.reads().QueryRow(ctx, `
+		SELECT
+			COUNT(*) FILTER (WHERE created_at >= $2),
+			COUNT(*) FILTER (WHERE resolved_at IS NULL)
+		FROM dead_letter_queue
+		WHERE subscriber_id = $1
+	`, subscriberID, since).Scan(&st.DeadLetters, &st.OpenDeadLetters)
+	if err != nil {
+		return nil, fmt.Errorf("querying subscriber dead letters: %w", err)
+	}
+
+	// Failed attempts by reason
+	st.FailureReasons, err = s.countFailureReasons(ctx, `subscriber_id = $1 AND created_at >= $2`, subscriberID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	// Daily breakdown
+	rows, err := s.reads().Query(ctx, `
+		SELECT
+			date_trunc('day', created_at AT TIME ZONE 'UTC') AS day,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE status = 'success')
+		FROM delivery_attempts
+		WHERE subscriber_id = $1 AND created_at >= $2 AND status <> 'simulated'
+		GROUP BY day
+		ORDER BY day
+	`, subscriberID, since)
+	if err != nil {
+		return nil, fmt.Errorf("querying subscriber daily attempts: %w", err)
+	}
+	defer rows.Close()
+
+	st.AttemptsPerDay = []DailyAttempts{}
+	for rows.Next() {
+		var d DailyAttempts
+		if err := rows.Scan(&d.Day, &d.Attempts, &d.SuccessCount); err != nil {
+			return nil, fmt.Errorf("scanning daily attempts: %w", err)
+		}
+		d.Day = d.Day.UTC()
+		st.AttemptsPerDay = append(st.AttemptsPerDay, d)
+	}
+
+	return &st, nil
+}
+
+// countFailureReasons counts the delivery attempts matching condition by
+// failure reason. Attempts without one are left out.
+func (s *PostgresStore) countFailureReasons(ctx context.Context, condition string, args ...interface{}) (map[string]int, error) {
+	rows, err := s.reads().Query(ctx, `
+		SELECT failure_reason, COUNT(*)
+		FROM delivery_attempts
+		WHERE `+condition+` AND failure_reason IS NOT NULL
+		GROUP BY failure_reason
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying failure reasons: %w", err)
+	}
+	defer rows.Close()
+
+	counts := map[string]int{}
+	for rows.Next() {
+		var reason string
+		var n int
+		if err := rows.Scan(&reason, &n); err != nil {
+			return nil, fmt.Errorf("scanning failure reason: %w", err)
+		}
+		counts[reason] = n
+	}
+	return counts, rows.Err()
+}
+
+// RollupDeliveryMetrics recomputes delivery_metrics_hourly rows for every hour
+// in [from, to) from delivery_attempts. from and to should be hour-aligned;
+// recomputing an hour replaces its previous totals, so it is safe to repeat.
+func (s *PostgresStore) RollupDeliveryMetrics(ctx context.Context, from, to time.Time) (int64, error) {
+	result, err := s.pool.Exec(ctx, `
+		INSERT INTO delivery_metrics_hourly (
+			hour, subscriber_id, total_attempts, success_count, failed_count,
+			retry_count, response_time_sum_ms, response_time_count, updated_at
+		)
+		SELECT
+			date_trunc('hour', created_at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' AS hour,
+			subscriber_id,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE status = 'success'),
+			COUNT(*) FILTER (WHERE status = 'failed'),
+			COUNT(*) FILTER (WHERE attempt_number > 1),
+			COALESCE(SUM(response_time_ms) FILTER (WHERE response_time_ms > 0), 0),
+			COUNT(*) FILTER (WHERE response_time_ms > 0),
+			NOW()
+		FROM delivery_attempts
+		WHERE created_at >= $1 AND created_at < $2 AND status <> 'simulated'
+		GROUP BY 1, 2
+		ON CONFLICT (hour, subscriber_id) DO UPDATE SET
+			total_attempts = EXCLUDED.total_attempts,
+			success_count = EXCLUDED.success_count,
+			failed_count = EXCLUDED.failed_count,
+			retry_count = EXCLUDED.retry_count,
+			response_time_sum_ms = EXCLUDED.response_time_sum_ms,
+			response_time_count = EXCLUDED.response_time_count,
+			updated_at = EXCLUDED.updated_at
+	`, from, to)
+	if err != nil {
+		return 0, fmt.Errorf("rolling up delivery metrics: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
+
+// LatestRollupHour returns the most recent hour present in the rollup. ok is
+// false when the rollup is empty, in which case the zero time is returned.
+func (s *PostgresStore) LatestRollupHour(ctx context.Context) (hour time.Time, ok bool, err error) {
+	var latest *time.Time
+	if err := s.pool.QueryRow(ctx, `SELECT MAX(hour) FROM delivery_metrics_hourly`).Scan(&latest); err != nil {
+		return time.Time{}, false, fmt.Errorf("querying latest rollup hour: %w", err)
+	}
+	if latest == nil {
+		return time.Time{}, false, nil
+	}
+	return *latest, true, nil
+}
+
+// EarliestDeliveryAttempt returns when the oldest stored delivery attempt was
+// created. ok is false when there are none.
+func (s *PostgresStore) EarliestDeliveryAttempt(ctx context.Context) (at time.Time, ok bool, err error) {
+	var earliest *time.Time
+	if err := s.pool.QueryRow(ctx, `SELECT MIN(created_at) FROM delivery_attempts`).Scan(&earliest); err != nil {
+		return time.Time{}, false, fmt.Errorf("querying earliest delivery attempt: %w", err)
+	}
+	if earliest == nil {
+		return time.Time{}, false, nil
+	}
+	return *earliest, true, nil
+}
+
+// TimeseriesBucket holds delivery statistics for one time bucket.
+type TimeseriesBucket struct {
+	Start        time.Time `json:"start"`
+	Total        int       `json:"total"`
+	SuccessCount int       `json:"success_count"`
+	FailedCount  int       `json:"failed_count"`
+	SuccessRate  float64   `json:"success_rate"`
+	LatencyP50Ms float64   `json:"latency_p50_ms"`
+	LatencyP95Ms float64   `json:"latency_p95_ms"`
+	LatencyP99Ms float64   `json:"latency_p99_ms"`
+	// FailureReasons counts the bucket's failed attempts by reason. Nil
+	// when none failed.
+	FailureReasons map[string]int `json:"failure_reasons,omitempty"`
+}
+
+// GetDeliveryTimeseries buckets delivery attempts created at or after since
+// into fixed-width intervals aligned to the Unix epoch. Buckets with no
+// attempts are omitted.
+func (s *PostgresStore) GetDeliveryTimeseries(ctx context.Context, since time.Time, interval time.Duration) ([]TimeseriesBucket, error) {
+	rows, err := s.reads().Query(ctx, `
+		SELECT
+			to_timestamp(floor(extract(epoch FROM created_at) / $2::float8) * $2::float8) AS bucket,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE status = 'success'),
+			COUNT(*) FILTER (WHERE status = 'failed'),
+			COALESCE(percentile_cont(0.50) WITHIN GROUP (ORDER BY response_time_ms) FILTER (WHERE response_time_ms > 0), 0),
+			COALESCE(percentile_cont(0.95) WITHIN GROUP (ORDER BY response_time_ms) FILTER (WHERE response_time_ms > 0), 0),
+			COALESCE(percentile_cont(0.99) WITHIN GROUP (ORDER BY response_time_ms) FILTER (WHERE response_time_ms > 0), 0)
+		FROM delivery_attempts
+		WHERE created_at >= $1 AND status <> 'simulated'
+		GROUP BY bucket
+		ORDER BY bucket
+	`, since, interval.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("querying delivery timeseries: %w", err)
+	}
+	defer rows.Close()
+
+	var buckets []TimeseriesBucket
+	for rows.Next() {
+		var b TimeseriesBucket
+		err := rows.Scan(
+			&b.Start, &b.Total, &b.SuccessCount, &b.FailedCount,
+			&b.LatencyP50Ms, &b.LatencyP95Ms, &b.LatencyP99Ms,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning timeseries bucket: %w", err)
+		}
+		b.Start = b.Start.UTC()
+		if b.Total > 0 {
+			b.SuccessRate = float64(b.SuccessCount) / float64(b.Total) * 100
+		}
+		buckets = append(buckets, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading delivery timeseries: %w", err)
+	}
+
+	// Failure reasons per bucket, attached to the buckets above
+	rows, err = s.reads().Query(ctx, `
+		SELECT
+			to_timestamp(floor(extract(epoch FROM created_at) / $2::float8) * $2::float8) AS bucket,
+			failure_reason,
+			COUNT(*)
+		FROM delivery_attempts
+		WHERE created_at >= $1 AND failure_reason IS NOT NULL
+		GROUP BY bucket, failure_reason
+	`, since, interval.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("querying failure reason timeseries: %w", err)
+	}
+	defer rows.Close()
+
+	byStart := make(map[int64]*TimeseriesBucket, len(buckets))
+	for i := range buckets {
+		byStart[buckets[i].Start.Unix()] = &buckets[i]
+	}
+	for rows.Next() {
+		var start time.Time
+		var reason string
+		var n int
+		if err := rows.Scan(&start, &reason, &n); err != nil {
+			return nil, fmt.Errorf("scanning failure reason bucket: %w", err)
+		}
+		b := byStart[start.Unix()]
+		if b == nil {
+			continue // an attempt recorded between the two queries
+		}
+		if b.FailureReasons == nil {
+			b.FailureReasons = map[string]int{}
+		}
+		b.FailureReasons[reason] = n
+	}
+
+	return buckets, rows.Err()
+}
@@ -0,0 +1,83 @@
+package store
+
+import (
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MigrationState is a migration and when it was applied. AppliedAt is nil
+// for a pending migration.
+type MigrationState struct {
+	Version   string     `json:"version"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+// migrationVersions returns the names of the .up.sql files at the root of
+// fsys, sorted by version.
+func migrationVersions(fsys fs.FS) ([]string, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("reading migrations: %w", err)
+	}
+
+	var versions []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".up.sql") {
+			versions = append(versions, e.Name())
+		}
+	}
+	sort.Strings(versions)
+	return versions, nil
+}
+
+// readDownMigration returns the script that reverts version.
+func readDownMigration(fsys fs.FS, version string) ([]byte, error) {
+	name := strings.TrimSuffix(version, ".up.sql") + ".down.sql"
+	script, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, fmt.Errorf("reading down migration for %s: %w", version, err)
+	}
+	return script, nil
+}
+
+// migrationStates lists every migration in versions, then any applied
+// migration that is not among them, with when each was applied.
+func migrationStates(versions []string, applied map[string]time.Time) []MigrationState {
+	states := make([]MigrationState, 0, len(versions))
+	known := make(map[string]bool, len(versions))
+	for _, v := range versions {
+		known[v] = true
+		state := MigrationState{Version: v}
+		if at, ok := applied[v]; ok {
+			state.AppliedAt = &at
+		}
+		states = append(states, state)
+	}
+
+	var unknown []string
+	for v := range applied {
+		if !known[v] {
+			unknown = append(unknown, v)
+		}
+	}
+	sort.Strings(unknown)
+	for _, v := range unknown {
+		at := applied[v]
+		states = append(states, MigrationState{Version: v, AppliedAt: &at})
+	}
+	return states
+}
+
+// pendingMigrations returns the versions of states not yet applied.
+func pendingMigrations(states []MigrationState) []string {
+	var pending []string
+	for _, s := range states {
+		if s.AppliedAt == nil {
+			pending = append(pending, s.Version)
+		}
+	}
+	return pending
+}
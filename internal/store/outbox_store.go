@@ -0,0 +1,99 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+)
+
+// OutboxEntry is a pending fan-out recorded alongside its event.
+type OutboxEntry struct {
+	Event    domain.Event
+	Attempts int
+}
+
+// ClaimPendingOutbox claims up to limit outbox entries whose fan-out is due
+// and returns them with their events. Claimed entries are pushed back by
+// lease, so relays on other instances skip them until this one completes or
+// fails them, or the lease runs out after a crash.
+func (s *PostgresStore) ClaimPendingOutbox(ctx context.Context, limit int, lease time.Duration) ([]OutboxEntry, error) {
+	rows, err := s.pool.Query(ctx, `
+		WITH claimed AS (
+			UPDATE fanout_outbox
+			SET available_at = NOW() + make_interval(secs => $2)
+			WHERE event_id IN (
+				SELECT event_id FROM fanout_outbox
+				WHERE available_at <= NOW()
+				ORDER BY available_at
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING event_id, attempts
+		)
+		SELECT e.id, e.event_type, e.version, e.payload, e.payload_ref, e.payload_size, e.source,
+			e.subscriber_ids::text[], e.created_at, c.attempts
+		FROM claimed c
+		JOIN events e ON e.id = c.event_id
+		ORDER BY e.created_at
+	`, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("claiming fanout outbox: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []OutboxEntry
+	for rows.Next() {
+		var entry OutboxEntry
+		var refKey string
+		var refSize int64
+		err := rows.Scan(
+			&entry.Event.ID, &entry.Event.EventType, &entry.Event.Version, &entry.Event.Payload, &refKey, &refSize,
+			&entry.Event.Source, &entry.Event.SubscriberIDs, &entry.Event.CreatedAt, &entry.Attempts,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning outbox entry: %w", err)
+		}
+		entry.Event.PayloadRef = payloadRef(refKey, refSize)
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// CompleteOutboxEntry removes the outbox entry for an event once its fan-out
+// has been queued in Redis.
+func (s *PostgresStore) CompleteOutboxEntry(ctx context.Context, eventID string) error {
+	_, err := s.pool.Exec(ctx, `DELETE FROM fanout_outbox WHERE event_id = $1`, eventID)
+	if err != nil {
+		return fmt.Errorf("completing outbox entry: %w", err)
+	}
+	return nil
+}
+
+// FailOutboxEntry records a failed relay attempt and defers the entry until retryAt.
+func (s *PostgresStore) FailOutboxEntry(ctx context.Context, eventID string, errMsg string, retryAt time.Time) error {
+	_, err := s.pool.Exec(ctx, `
+		UPDATE fanout_outbox
+		SET attempts = attempts + 1, last_error = $2, available_at = $3
+		WHERE event_id = $1
+	`, eventID, errMsg, retryAt)
+	if err != nil {
+		return fmt.Errorf("updating outbox entry: %w", err)
+	}
+	return nil
+}
+
+// OutboxEntryPending reports whether an event's fan-out has not yet been
+// queued.
+func (s *PostgresStore) OutboxEntryPending(ctx context.Context, eventID string) (bool, error) {
+	var pending bool
+	err := s.pool.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM fanout_outbox WHERE event_id = $1)
+	`, eventID).Scan(&pending)
+	if err != nil {
+		return false, fmt.Errorf("checking outbox entry: %w", err)
+	}
+	return pending, nil
+}
@@ -0,0 +1,185 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+// PartitionedTables are the tables partitioned by month of created_at.
+// delivery_attempts is listed first, since its rows reference events.
+var PartitionedTables = []string{"delivery_attempts", "events"}
+
+// ErrPartitionInUse is returned when a partition of events can't be dropped
+// because its events still have a fan-out pending.
+var ErrPartitionInUse = errors.New("partition has events with a pending fan-out")
+
+// Partition is one partition of a partitioned table, holding the rows
+// created in [From, To). From is nil for the partition holding the rows
+// from before the table was partitioned.
+type Partition struct {
+	Table string
+	Name  string
+	From  *time.Time
+	To    *time.Time
+}
+
+// ListPartitions returns the partitions of table, oldest first.
+func (s *PostgresStore) ListPartitions(ctx context.Context, table string) ([]Partition, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT c.relname,
+			(regexp_match(pg_get_expr(c.relpartbound, c.oid), 'FROM \(''([^'']+)''\)'))[1]::timestamptz,
+			(regexp_match(pg_get_expr(c.relpartbound, c.oid), 'TO \(''([^'']+)''\)'))[1]::timestamptz
+		FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		WHERE i.inhparent = to_regclass($1)
+		ORDER BY 2 NULLS FIRST
+	`, table)
+	if err != nil {
+		return nil, fmt.Errorf("listing partitions of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var partitions []Partition
+	for rows.Next() {
+		p := Partition{Table: table}
+		if err := rows.Scan(&p.Name, &p.From, &p.To); err != nil {
+			return nil, fmt.Errorf("scanning partition: %w", err)
+		}
+		partitions = append(partitions, p)
+	}
+	return partitions, rows.Err()
+}
+
+// CreateMonthlyPartitions adds a partition of table for each month after
+// its last one, until the month containing through is covered. It returns
+// the names of the partitions created.
+func (s *PostgresStore) CreateMonthlyPartitions(ctx context.Context, table string, through time.Time) ([]string, error) {
+	partitions, err := s.ListPartitions(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+	if len(partitions) == 0 {
+		return nil, fmt.Errorf("%s is not partitioned", table)
+	}
+	last := partitions[len(partitions)-1]
+	if last.To == nil {
+		return nil, fmt.Errorf("partition %s has no upper bound", last.Name)
+	}
+
+	var created []string
+	for start := last.To.UTC(); !start.After(through); start = start.AddDate(0, 1, 0) {
+		name := fmt.Sprintf("%s_p%s", table, start.Format("2006_01"))
+		_, err := s.pool.Exec(ctx, fmt.Sprintf(
+			`CREATE TABLE IF NOT EXISTS %s PARTITION OF %s FOR VALUES FROM ('%s') TO ('%s')`,
+			pgx.Identifier{name}.Sanitize(), pgx.Identifier{table}.Sanitize(),
+			start.Format(time.RFC3339), start.AddDate(0, 1, 0).Format(time.RFC3339),
+		))
+		if err != nil {
+			return created, fmt.Errorf("creating partition %s: %w", name, err)
+		}
+		created = append(created, name)
+	}
+	return created, nil
+}
+
+// DropPartition removes a partition and its rows. For events, their fan-out
+// statuses go too, and ErrPartitionInUse is returned if any still has an
+// outbox entry.
+func (s *PostgresStore) DropPartition(ctx context.Context, p Partition) error {
+	name := pgx.Identifier{p.Name}.Sanitize()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if p.Table == "events" {
+		var pending bool
+		err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM fanout_outbox o JOIN `+name+` e ON e.id = o.event_id)`).Scan(&pending)
+		if err != nil {
+			return fmt.Errorf("checking pending fan-outs: %w", err)
+		}
+		if pending {
+			return ErrPartitionInUse
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM fanout_status WHERE event_id IN (SELECT id FROM `+name+`)`); err != nil {
+			return fmt.Errorf("deleting fan-out statuses: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `DROP TABLE `+name); err != nil {
+		return fmt.Errorf("dropping partition %s: %w", p.Name, err)
+	}
+	return tx.Commit(ctx)
+}
+
+// ListPartitionDeliveryAttempts returns a partition's delivery attempts
+// oldest first, starting after the attempt at (afterTime, afterID).
+func (s *PostgresStore) ListPartitionDeliveryAttempts(ctx context.Context, p Partition, afterTime time.Time, afterID string, limit int) ([]domain.DeliveryAttempt, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT id, event_id, subscriber_id, attempt_number, status, http_status_code, response_body, response_headers, response_time_ms, timings, error_message, failure_reason, next_retry_at, final_url, created_at
+		FROM `+pgx.Identifier{p.Name}.Sanitize()+`
+		WHERE (created_at, id) > ($1, $2::uuid)
+		ORDER BY created_at, id
+		LIMIT $3
+	`, afterTime, afterIDOrZero(afterID), limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying partition %s: %w", p.Name, err)
+	}
+	defer rows.Close()
+
+	var attempts []domain.DeliveryAttempt
+	for rows.Next() {
+		var a domain.DeliveryAttempt
+		err := rows.Scan(
+			&a.ID, &a.EventID, &a.SubscriberID, &a.AttemptNumber,
+			&a.Status, &a.HTTPStatusCode, &a.ResponseBody, &a.ResponseHeaders,
+			&a.ResponseTimeMs, &a.Timings, &a.ErrorMessage, &a.FailureReason, &a.NextRetryAt, &a.FinalURL, &a.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning delivery attempt: %w", err)
+		}
+		attempts = append(attempts, a)
+	}
+	return attempts, rows.Err()
+}
+
+// ListPartitionEvents returns a partition's events oldest first, starting
+// after the event at (afterTime, afterID).
+func (s *PostgresStore) ListPartitionEvents(ctx context.Context, p Partition, afterTime time.Time, afterID string, limit int) ([]domain.Event, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT id, event_type, version, payload, source, created_at
+		FROM `+pgx.Identifier{p.Name}.Sanitize()+`
+		WHERE (created_at, id) > ($1, $2::uuid)
+		ORDER BY created_at, id
+		LIMIT $3
+	`, afterTime, afterIDOrZero(afterID), limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying partition %s: %w", p.Name, err)
+	}
+	defer rows.Close()
+
+	var events []domain.Event
+	for rows.Next() {
+		var e domain.Event
+		if err := rows.Scan(&e.ID, &e.EventType, &e.Version, &e.Payload, &e.Source, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning event: %w", err)
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// afterIDOrZero returns id, or the lowest UUID to start from the beginning.
+func afterIDOrZero(id string) string {
+	if id == "" {
+		return "00000000-0000-0000-0000-000000000000"
+	}
+	return id
+}
@@ -0,0 +1,92 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// PendingQueueJob is a delivery job saved while the queue could not take it.
+type PendingQueueJob struct {
+	ID           int64
+	EventID      string
+	SubscriberID string
+	Job          []byte // the delivery job as JSON
+	ReadyAt      time.Time
+	LastError    string
+}
+
+// SavePendingJob saves a job to be queued again later.
+func (s *PostgresStore) SavePendingJob(ctx context.Context, job PendingQueueJob) error {
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO pending_jobs (event_id, subscriber_id, job, ready_at, last_error)
+		VALUES ($1, $2, $3, $4, $5)
+	`, job.EventID, job.SubscriberID, job.Job, job.ReadyAt, nullString(job.LastError))
+	if err != nil {
+		return fmt.Errorf("saving pending job: %w", err)
+	}
+	return nil
+}
+
+// DrainPendingJobs passes up to limit saved jobs to enqueue, oldest first,
+// and deletes those it accepts. The jobs stay locked meanwhile, so other
+// instances draining at the same time skip them.
+func (s *PostgresStore) DrainPendingJobs(ctx context.Context, limit int, enqueue func(PendingQueueJob) error) (int, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		SELECT id, event_id, subscriber_id, job, ready_at, COALESCE(last_error, '')
+		FROM pending_jobs
+		ORDER BY id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	`, limit)
+	if err != nil {
+		return 0, fmt.Errorf("querying pending jobs: %w", err)
+	}
+	var jobs []PendingQueueJob
+	for rows.Next() {
+		var j PendingQueueJob
+		if err := rows.Scan(&j.ID, &j.EventID, &j.SubscriberID, &j.Job, &j.ReadyAt, &j.LastError); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scanning pending job: %w", err)
+		}
+		jobs = append(jobs, j)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("querying pending jobs: %w", err)
+	}
+
+	var done []int64
+	var enqueueErr error
+	for _, j := range jobs {
+		if enqueueErr = enqueue(j); enqueueErr != nil {
+			break
+		}
+		done = append(done, j.ID)
+	}
+
+	if len(done) > 0 {
+		if _, err := tx.Exec(ctx, `DELETE FROM pending_jobs WHERE id = ANY($1)`, done); err != nil {
+			return 0, fmt.Errorf("deleting pending jobs: %w", err)
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return 0, fmt.Errorf("committing pending jobs: %w", err)
+		}
+	}
+	return len(done), enqueueErr
+}
+
+// CountPendingJobs returns the number of saved jobs.
+func (s *PostgresStore) CountPendingJobs(ctx context.Context) (int64, error) {
+	var n int64
+	if err := s.pool.QueryRow(ctx, `SELECT COUNT(*) FROM pending_jobs`).Scan(&n); err != nil {
+		return 0, fmt.Errorf("counting pending jobs: %w", err)
+	}
+	return n, nil
+}
@@ -4,105 +4,297 @@ import (
 	"context"
 	"fmt"
 	"io/fs"
-	"os"
-	"path/filepath"
-	"sort"
-	"strings"
+	"log/slog"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type PostgresStore struct {
-	pool *pgxpool.Pool
+	pool    *timeoutPool
+	replica *replicaPool // nil without a read replica
 }
 
-func NewPostgres(ctx context.Context, databaseURL string) (*PostgresStore, error) {
-	pool, err := pgxpool.New(ctx, databaseURL)
+func NewPostgres(ctx context.Context, databaseURL string, poolConfig PoolConfig) (*PostgresStore, error) {
+	cfg, err := poolConfig.pgxConfig(databaseURL)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := pgxpool.NewWithConfig(ctx, cfg)
 	if err != nil {
 		return nil, fmt.Errorf("connecting to postgres: %w", err)
 	}
 
 	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
 		return nil, fmt.Errorf("pinging postgres: %w", err)
 	}
 
-	return &PostgresStore{pool: pool}, nil
+	s := &PostgresStore{pool: &timeoutPool{Pool: pool, timeout: poolConfig.StatementTimeout}}
+	if poolConfig.ReplicaURL != "" {
+		if err := s.openReplica(ctx, poolConfig); err != nil {
+			pool.Close()
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// openReplica connects to the read replica. A replica that can't be reached
+// yet is not an error: reads go to the primary until it can.
+func (s *PostgresStore) openReplica(ctx context.Context, poolConfig PoolConfig) error {
+	cfg, err := poolConfig.pgxConfig(poolConfig.ReplicaURL)
+	if err != nil {
+		return fmt.Errorf("replica: %w", err)
+	}
+	pool, err := pgxpool.NewWithConfig(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("connecting to replica: %w", err)
+	}
+
+	s.replica = newReplicaPool(&timeoutPool{Pool: pool, timeout: poolConfig.StatementTimeout}, s.pool)
+	if err := pool.Ping(ctx); err != nil {
+		s.replica.failed(context.Background(), err)
+	}
+	return nil
+}
+
+// reads returns where heavy reads are sent: the replica when there is one,
+// or the primary.
+func (s *PostgresStore) reads() querier {
+	if s.replica != nil {
+		return s.replica
+	}
+	return s.pool
 }
 
 func (s *PostgresStore) Close() {
+	if s.replica != nil {
+		s.replica.Close()
+	}
 	s.pool.Close()
 }
 
 func (s *PostgresStore) Pool() *pgxpool.Pool {
-	return s.pool
+	return s.pool.Pool
 }
 
-// RunMigrations executes all .up.sql migration files in order.
-func (s *PostgresStore) RunMigrations(ctx context.Context, migrationsDir string) error {
-	// Create migrations tracking table
-	_, err := s.pool.Exec(ctx, `
+// Ping checks that a pooled connection to the database is usable.
+func (s *PostgresStore) Ping(ctx context.Context) error {
+	return s.pool.Ping(ctx)
+}
+
+// migrationLockID is the advisory lock held while migrating, so instances
+// starting together apply each migration once, one after another.
+const migrationLockID int64 = 0x77686b5f6d696772 // "whk_migr"
+
+// migrationConn acquires a connection for migrating and takes the migration
+// lock on it, waiting for any other instance that holds it. Migrations are
+// exempt from the statement timeout, since rewriting a large table can take
+// a while. release unlocks, restores the timeout and returns the connection.
+func (s *PostgresStore) migrationConn(ctx context.Context) (conn *pgxpool.Conn, release func(), err error) {
+	conn, err = s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("acquiring connection: %w", err)
+	}
+	if _, err := conn.Exec(ctx, "SET statement_timeout = 0"); err != nil {
+		conn.Release()
+		return nil, nil, fmt.Errorf("disabling statement timeout: %w", err)
+	}
+	release = func() {
+		// Back to the configured timeout before the connection returns to the pool
+		conn.Exec(context.WithoutCancel(ctx), "RESET statement_timeout")
+		conn.Release()
+	}
+
+	var locked bool
+	if err := conn.QueryRow(ctx, "SELECT pg_try_advisory_lock($1)", migrationLockID).Scan(&locked); err != nil {
+		release()
+		return nil, nil, fmt.Errorf("taking migration lock: %w", err)
+	}
+	if !locked {
+		slog.Info("another instance is migrating the database; waiting for it to finish")
+		if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+			// A cancelled wait can leave the connection unusable
+			conn.Conn().Close(context.WithoutCancel(ctx))
+			release()
+			return nil, nil, fmt.Errorf("waiting for migration lock: %w", err)
+		}
+	}
+	unlocked := release
+	release = func() {
+		conn.Exec(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", migrationLockID)
+		unlocked()
+	}
+
+	// Created under the lock, since concurrent CREATE TABLE IF NOT EXISTS can
+	// still collide
+	_, err = conn.Exec(ctx, `
 		CREATE TABLE IF NOT EXISTS schema_migrations (
 			version VARCHAR(255) PRIMARY KEY,
 			applied_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
 		)
 	`)
 	if err != nil {
-		return fmt.Errorf("creating migrations table: %w", err)
+		release()
+		return nil, nil, fmt.Errorf("creating migrations table: %w", err)
 	}
+	return conn, release, nil
+}
 
-	// Find all up migration files
-	var migrations []string
-	err = filepath.WalkDir(migrationsDir, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-		if !d.IsDir() && strings.HasSuffix(d.Name(), ".up.sql") {
-			migrations = append(migrations, path)
-		}
-		return nil
-	})
+// RunMigrations executes the .up.sql files at the root of fsys in order,
+// skipping those already applied, and returns the versions it applied. Each
+// migration runs in a transaction together with its record, so one that
+// fails or is interrupted leaves nothing behind and is simply run again.
+func (s *PostgresStore) RunMigrations(ctx context.Context, fsys fs.FS) ([]string, error) {
+	conn, release, err := s.migrationConn(ctx)
 	if err != nil {
-		return fmt.Errorf("reading migrations directory: %w", err)
+		return nil, err
 	}
+	defer release()
 
-	sort.Strings(migrations)
-
-	for _, path := range migrations {
-		version := filepath.Base(path)
+	migrations, err := migrationVersions(fsys)
+	if err != nil {
+		return nil, err
+	}
 
-		// Check if already applied
+	var applied []string
+	for _, version := range migrations {
+		// Check if already applied, possibly by another instance that held
+		// the lock first
 		var exists bool
-		err := s.pool.QueryRow(ctx,
+		err := conn.QueryRow(ctx,
 			"SELECT EXISTS(SELECT 1 FROM schema_migrations WHERE version = $1)",
 			version,
 		).Scan(&exists)
 		if err != nil {
-			return fmt.Errorf("checking migration %s: %w", version, err)
+			return applied, fmt.Errorf("checking migration %s: %w", version, err)
 		}
 		if exists {
 			continue
 		}
 
 		// Read and execute migration
-		sql, err := os.ReadFile(path)
+		sql, err := fs.ReadFile(fsys, version)
 		if err != nil {
-			return fmt.Errorf("reading migration %s: %w", version, err)
+			return applied, fmt.Errorf("reading migration %s: %w", version, err)
 		}
 
-		_, err = s.pool.Exec(ctx, string(sql))
+		tx, err := conn.Begin(ctx)
 		if err != nil {
-			return fmt.Errorf("executing migration %s: %w", version, err)
+			return applied, fmt.Errorf("beginning migration %s: %w", version, err)
+		}
+		if _, err := tx.Exec(ctx, string(sql)); err != nil {
+			tx.Rollback(ctx)
+			return applied, fmt.Errorf("executing migration %s: %w", version, err)
 		}
 
 		// Record migration
-		_, err = s.pool.Exec(ctx,
-			"INSERT INTO schema_migrations (version) VALUES ($1)",
+		_, err = tx.Exec(ctx,
+			"INSERT INTO schema_migrations (version) VALUES ($1) ON CONFLICT (version) DO NOTHING",
 			version,
 		)
 		if err != nil {
-			return fmt.Errorf("recording migration %s: %w", version, err)
+			tx.Rollback(ctx)
+			return applied, fmt.Errorf("recording migration %s: %w", version, err)
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return applied, fmt.Errorf("committing migration %s: %w", version, err)
 		}
+		applied = append(applied, version)
 	}
 
-	return nil
+	return applied, nil
+}
+
+// RollbackMigrations reverts the n most recently applied migrations, newest
+// first, with their .down.sql files in fsys, and returns their versions.
+func (s *PostgresStore) RollbackMigrations(ctx context.Context, fsys fs.FS, n int) ([]string, error) {
+	conn, release, err := s.migrationConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	rows, err := conn.Query(ctx, "SELECT version FROM schema_migrations ORDER BY version DESC LIMIT $1", n)
+	if err != nil {
+		return nil, fmt.Errorf("listing applied migrations: %w", err)
+	}
+	versions, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("listing applied migrations: %w", err)
+	}
+
+	var reverted []string
+	for _, version := range versions {
+		script, err := readDownMigration(fsys, version)
+		if err != nil {
+			return reverted, err
+		}
+
+		// Revert the migration and forget it together
+		tx, err := conn.Begin(ctx)
+		if err != nil {
+			return reverted, fmt.Errorf("beginning rollback of %s: %w", version, err)
+		}
+		if _, err := tx.Exec(ctx, string(script)); err != nil {
+			tx.Rollback(ctx)
+			return reverted, fmt.Errorf("reverting migration %s: %w", version, err)
+		}
+		if _, err := tx.Exec(ctx, "DELETE FROM schema_migrations WHERE version = $1", version); err != nil {
+			tx.Rollback(ctx)
+			return reverted, fmt.Errorf("unrecording migration %s: %w", version, err)
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return reverted, fmt.Errorf("committing rollback of %s: %w", version, err)
+		}
+		reverted = append(reverted, version)
+	}
+	return reverted, nil
+}
+
+// MigrationStatus lists the migrations in fsys, and any applied migration
+// missing from it, with when each was applied.
+func (s *PostgresStore) MigrationStatus(ctx context.Context, fsys fs.FS) ([]MigrationState, error) {
+	versions, err := migrationVersions(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[string]time.Time)
+	var exists bool
+	if err := s.pool.QueryRow(ctx, "SELECT to_regclass('schema_migrations') IS NOT NULL").Scan(&exists); err != nil {
+		return nil, fmt.Errorf("checking migrations table: %w", err)
+	}
+	if exists {
+		rows, err := s.pool.Query(ctx, "SELECT version, COALESCE(applied_at, 'epoch') FROM schema_migrations")
+		if err != nil {
+			return nil, fmt.Errorf("listing applied migrations: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var version string
+			var at time.Time
+			if err := rows.Scan(&version, &at); err != nil {
+				return nil, fmt.Errorf("scanning applied migration: %w", err)
+			}
+			applied[version] = at
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("listing applied migrations: %w", err)
+		}
+	}
+
+	return migrationStates(versions, applied), nil
+}
+
+// PendingMigrations returns the migrations in fsys that have not been
+// applied yet.
+func (s *PostgresStore) PendingMigrations(ctx context.Context, fsys fs.FS) ([]string, error) {
+	states, err := s.MigrationStatus(ctx, fsys)
+	if err != nil {
+		return nil, err
+	}
+	return pendingMigrations(states), nil
 }
@@ -0,0 +1,188 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PoolConfig tunes the PostgreSQL connection pool. Zero values keep pgx's
+// defaults, or the pool_* parameters of the database URL.
+type PoolConfig struct {
+	MaxConns          int
+	MinConns          int
+	MaxConnLifetime   time.Duration
+	HealthCheckPeriod time.Duration
+
+	// ReplicaURL is a read-only replica that list, export and analytics
+	// queries are sent to. It gets a pool of its own with the same
+	// settings.
+	ReplicaURL string
+
+	// StatementTimeout bounds every query, both as the server's
+	// statement_timeout and as a deadline on the query's context, which
+	// also covers waiting for a free connection. 0 disables it.
+	StatementTimeout time.Duration
+}
+
+// pgxConfig parses databaseURL and applies c to it.
+func (c PoolConfig) pgxConfig(databaseURL string) (*pgxpool.Config, error) {
+	cfg, err := pgxpool.ParseConfig(databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing database URL: %w", err)
+	}
+	if c.MaxConns > 0 {
+		cfg.MaxConns = int32(c.MaxConns)
+	}
+	if c.MinConns > 0 {
+		cfg.MinConns = int32(c.MinConns)
+	}
+	if c.MaxConnLifetime > 0 {
+		cfg.MaxConnLifetime = c.MaxConnLifetime
+	}
+	if c.HealthCheckPeriod > 0 {
+		cfg.HealthCheckPeriod = c.HealthCheckPeriod
+	}
+	if c.StatementTimeout > 0 {
+		cfg.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(c.StatementTimeout.Milliseconds(), 10)
+	}
+	return cfg, nil
+}
+
+// timeoutPool is a pool whose queries are cancelled after timeout, unless
+// their context ends sooner. Transactions are only bounded by the server's
+// statement_timeout, per statement.
+type timeoutPool struct {
+	*pgxpool.Pool
+	timeout time.Duration
+}
+
+func (p *timeoutPool) deadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	if p.timeout <= 0 {
+		return ctx, func() {}
+	}
+	if d, ok := ctx.Deadline(); ok && time.Until(d) <= p.timeout {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, p.timeout)
+}
+
+func (p *timeoutPool) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	ctx, cancel := p.deadline(ctx)
+	defer cancel()
+	return p.Pool.Exec(ctx, sql, args...)
+}
+
+// Query returns rows that release the deadline once read or closed.
+func (p *timeoutPool) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	ctx, cancel := p.deadline(ctx)
+	rows, err := p.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &timeoutRows{Rows: rows, cancel: cancel}, nil
+}
+
+// QueryRow returns a row that releases the deadline once scanned.
+func (p *timeoutPool) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	ctx, cancel := p.deadline(ctx)
+	return &timeoutRow{row: p.Pool.QueryRow(ctx, sql, args...), cancel: cancel}
+}
+
+type timeoutRows struct {
+	pgx.Rows
+	cancel context.CancelFunc
+}
+
+func (r *timeoutRows) Next() bool {
+	if r.Rows.Next() {
+		return true
+	}
+	r.cancel()
+	return false
+}
+
+func (r *timeoutRows) Close() {
+	r.Rows.Close()
+	r.cancel()
+}
+
+type timeoutRow struct {
+	row    pgx.Row
+	cancel context.CancelFunc
+}
+
+func (r *timeoutRow) Scan(dest ...any) error {
+	defer r.cancel()
+	return r.row.Scan(dest...)
+}
+
+// PoolStats is a snapshot of the connection pool, for diagnostics.
+type PoolStats struct {
+	MaxConns      int32 `json:"max_conns"`
+	TotalConns    int32 `json:"total_conns"`
+	IdleConns     int32 `json:"idle_conns"`
+	AcquiredConns int32 `json:"acquired_conns"`
+	// EmptyAcquires counts queries that had to wait for a free connection,
+	// and CanceledAcquires those whose context ended while waiting.
+	EmptyAcquires    int64   `json:"empty_acquires"`
+	CanceledAcquires int64   `json:"canceled_acquires"`
+	AvgAcquireMs     float64 `json:"avg_acquire_ms"`
+
+	// Replica is the read replica's pool, if there is one.
+	Replica *ReplicaPoolStats `json:"replica,omitempty"`
+}
+
+// ReplicaPoolStats is a snapshot of the read replica's pool. While it is
+// not Available, its reads go to the primary.
+type ReplicaPoolStats struct {
+	Available bool `json:"available"`
+	PoolStats
+}
+
+// PoolStats reports the connection pools' current use.
+func (s *PostgresStore) PoolStats() PoolStats {
+	stats := poolStats(s.pool.Pool)
+	if s.replica != nil {
+		stats.Replica = &ReplicaPoolStats{
+			Available: !s.replica.unavailable.Load(),
+			PoolStats: poolStats(s.replica.replica.Pool),
+		}
+	}
+	return stats
+}
+
+func poolStats(pool *pgxpool.Pool) PoolStats {
+	stat := pool.Stat()
+	stats := PoolStats{
+		MaxConns:         stat.MaxConns(),
+		TotalConns:       stat.TotalConns(),
+		IdleConns:        stat.IdleConns(),
+		AcquiredConns:    stat.AcquiredConns(),
+		EmptyAcquires:    stat.EmptyAcquireCount(),
+		CanceledAcquires: stat.CanceledAcquireCount(),
+	}
+	if n := stat.AcquireCount(); n > 0 {
+		stats.AvgAcquireMs = float64(stat.AcquireDuration()) / float64(n) / float64(time.Millisecond)
+	}
+	return stats
+}
+
+// DatabasePoolStats reports the connection pool of db, seen through a
+// SubscriberCache, or false if it does not use PostgreSQL.
+func DatabasePoolStats(db Database) (PoolStats, bool) {
+	if cache, ok := db.(*SubscriberCache); ok {
+		db = cache.Database
+	}
+	pg, ok := db.(*PostgresStore)
+	if !ok {
+		return PoolStats{}, false
+	}
+	return pg.PoolStats(), true
+}
@@ -0,0 +1,49 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPoolConfig_OverridesDatabaseURL(t *testing.T) {
+	cfg, err := PoolConfig{
+		MaxConns:          40,
+		MaxConnLifetime:   30 * time.Minute,
+		HealthCheckPeriod: 15 * time.Second,
+		StatementTimeout:  5 * time.Second,
+	}.pgxConfig("postgres://localhost/webhooks?pool_max_conns=4&pool_min_conns=2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.MaxConns != 40 || cfg.MinConns != 2 || cfg.MaxConnLifetime != 30*time.Minute || cfg.HealthCheckPeriod != 15*time.Second {
+		t.Errorf("pool config = max %d, min %d, lifetime %s, health check %s", cfg.MaxConns, cfg.MinConns, cfg.MaxConnLifetime, cfg.HealthCheckPeriod)
+	}
+	if got := cfg.ConnConfig.RuntimeParams["statement_timeout"]; got != "5000" {
+		t.Errorf("statement_timeout = %q, want 5000", got)
+	}
+}
+
+func TestTimeoutPool_KeepsEarlierDeadlines(t *testing.T) {
+	p := &timeoutPool{timeout: time.Minute}
+
+	ctx, cancel := p.deadline(context.Background())
+	defer cancel()
+	if d, ok := ctx.Deadline(); !ok || time.Until(d) > time.Minute {
+		t.Errorf("deadline = %v, %v, want within a minute", d, ok)
+	}
+
+	parent, cancelParent := context.WithTimeout(context.Background(), time.Second)
+	defer cancelParent()
+	ctx, cancel = p.deadline(parent)
+	defer cancel()
+	if ctx != parent {
+		t.Error("a sooner deadline of the caller was replaced")
+	}
+
+	ctx, cancel = (&timeoutPool{}).deadline(context.Background())
+	defer cancel()
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("deadline set with the timeout disabled")
+	}
+}
@@ -0,0 +1,146 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// querier runs queries on a pool.
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+var (
+	_ querier = (*timeoutPool)(nil)
+	_ querier = (*replicaPool)(nil)
+)
+
+// replicaCheckInterval is how often an unavailable replica is tried again.
+const replicaCheckInterval = 10 * time.Second
+
+// replicaPool sends queries to a read replica, falling back to the primary
+// while the replica cannot be reached. Reads from it may lag slightly
+// behind writes to the primary.
+type replicaPool struct {
+	replica     *timeoutPool
+	primary     *timeoutPool
+	unavailable atomic.Bool
+	stop        context.CancelFunc
+}
+
+func newReplicaPool(replica, primary *timeoutPool) *replicaPool {
+	ctx, stop := context.WithCancel(context.Background())
+	p := &replicaPool{replica: replica, primary: primary, stop: stop}
+	go p.watch(ctx)
+	return p
+}
+
+// watch pings the replica while it is marked unavailable, and puts it back
+// into use once it answers.
+func (p *replicaPool) watch(ctx context.Context) {
+	ticker := time.NewTicker(replicaCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if !p.unavailable.Load() {
+			continue
+		}
+		pingCtx, cancel := context.WithTimeout(ctx, replicaCheckInterval)
+		err := p.replica.Ping(pingCtx)
+		cancel()
+		if err == nil {
+			p.unavailable.Store(false)
+			slog.Info("database replica is available again; reads are routed to it")
+		}
+	}
+}
+
+func (p *replicaPool) Close() {
+	p.stop()
+	p.replica.Close()
+}
+
+// failed reports whether err means the replica could not be reached, and
+// if so marks it unavailable.
+func (p *replicaPool) failed(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil || !replicaUnreachable(err) {
+		return false
+	}
+	if !p.unavailable.Swap(true) {
+		slog.Warn("database replica is unavailable; reading from the primary", "error", err)
+	}
+	return true
+}
+
+func replicaUnreachable(err error) bool {
+	var connectErr *pgconn.ConnectError
+	if errors.As(err, &connectErr) || pgconn.SafeToRetry(err) {
+		return true
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "57P01", "57P02", "57P03": // admin_shutdown, crash_shutdown, cannot_connect_now
+			return true
+		}
+	}
+	return false
+}
+
+func (p *replicaPool) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	if p.unavailable.Load() {
+		return p.primary.Exec(ctx, sql, args...)
+	}
+	tag, err := p.replica.Exec(ctx, sql, args...)
+	if p.failed(ctx, err) {
+		return p.primary.Exec(ctx, sql, args...)
+	}
+	return tag, err
+}
+
+func (p *replicaPool) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	if p.unavailable.Load() {
+		return p.primary.Query(ctx, sql, args...)
+	}
+	rows, err := p.replica.Query(ctx, sql, args...)
+	if p.failed(ctx, err) {
+		return p.primary.Query(ctx, sql, args...)
+	}
+	return rows, err
+}
+
+func (p *replicaPool) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	if p.unavailable.Load() {
+		return p.primary.QueryRow(ctx, sql, args...)
+	}
+	return &fallbackRow{pool: p, ctx: ctx, sql: sql, args: args}
+}
+
+// fallbackRow runs its query on the replica when scanned, and again on the
+// primary if the replica cannot be reached.
+type fallbackRow struct {
+	pool *replicaPool
+	ctx  context.Context
+	sql  string
+	args []any
+}
+
+func (r *fallbackRow) Scan(dest ...any) error {
+	err := r.pool.replica.QueryRow(r.ctx, r.sql, r.args...).Scan(dest...)
+	if r.pool.failed(r.ctx, err) {
+		return r.pool.primary.QueryRow(r.ctx, r.sql, r.args...).Scan(dest...)
+	}
+	return err
+}
@@ -0,0 +1,57 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// unreachablePool returns a pool whose connections to port are refused.
+func unreachablePool(t *testing.T, port string) *timeoutPool {
+	t.Helper()
+	pool, err := pgxpool.New(context.Background(), "postgres://webhook@127.0.0.1:"+port+"/webhooks?connect_timeout=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+	return &timeoutPool{Pool: pool}
+}
+
+func TestReplicaPool_FallsBackWhenUnreachable(t *testing.T) {
+	p := &replicaPool{replica: unreachablePool(t, "1"), primary: unreachablePool(t, "2")}
+
+	var n int
+	err := p.QueryRow(context.Background(), "SELECT 1").Scan(&n)
+	var connectErr *pgconn.ConnectError
+	if !errors.As(err, &connectErr) || connectErr.Config.Port != 2 {
+		t.Fatalf("err = %v, want the primary's connect error", err)
+	}
+	if !p.unavailable.Load() {
+		t.Fatal("unreachable replica still marked available")
+	}
+
+	_, err = p.Query(context.Background(), "SELECT 1")
+	if !errors.As(err, &connectErr) || connectErr.Config.Port != 2 {
+		t.Errorf("err = %v, want reads to go straight to the primary", err)
+	}
+}
+
+func TestReplicaUnreachable(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{&pgconn.ConnectError{}, true},
+		{&pgconn.PgError{Code: "57P03"}, true},
+		{&pgconn.PgError{Code: "42P01"}, false}, // undefined_table
+		{context.DeadlineExceeded, false},
+	}
+	for _, c := range cases {
+		if got := replicaUnreachable(c.err); got != c.want {
+			t.Errorf("replicaUnreachable(%v) = %v, want %v", c.err, got, c.want)
+		}
+	}
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/redis/go-redis/v9"
 )
 
 type RedisStore struct {
-	client *redis.Client
+	client   *redis.Client
+	embedded *miniredis.Miniredis
 }
 
 func NewRedis(ctx context.Context, redisURL string) (*RedisStore, error) {
@@ -26,8 +28,37 @@ func NewRedis(ctx context.Context, redisURL string) (*RedisStore, error) {
 	return &RedisStore{client: client}, nil
 }
 
+// NewEmbeddedRedis starts an in-process Redis emulation on a loopback port.
+// It backs the circuit breaker, rate limiter and live feed when the server
+// runs without a Redis server (QUEUE_MODE=memory and no REDIS_URL). Its
+// contents are lost on restart and not shared with other instances.
+func NewEmbeddedRedis(ctx context.Context) (*RedisStore, error) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		return nil, fmt.Errorf("starting embedded redis: %w", err)
+	}
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		mr.Close()
+		return nil, fmt.Errorf("pinging embedded redis: %w", err)
+	}
+
+	return &RedisStore{client: client, embedded: mr}, nil
+}
+
 func (s *RedisStore) Close() error {
-	return s.client.Close()
+	err := s.client.Close()
+	if s.embedded != nil {
+		s.embedded.Close()
+	}
+	return err
+}
+
+// Ping checks that Redis is reachable.
+func (s *RedisStore) Ping(ctx context.Context) error {
+	return s.client.Ping(ctx).Err()
 }
 
 func (s *RedisStore) Client() *redis.Client {
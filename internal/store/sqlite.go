@@ -0,0 +1,266 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// sqliteMigrationsDir is the directory of the migrations that holds the
+// SQLite schema.
+const sqliteMigrationsDir = "sqlite"
+
+// sqliteParams configure every connection: UTC timestamps in a fixed text
+// format so they compare correctly as strings, enforced foreign keys, and
+// write transactions that take the lock up front instead of failing with
+// SQLITE_BUSY when they upgrade from a read.
+var sqliteParams = []string{
+	"_time_format=sqlite",
+	"_timezone=UTC",
+	"_txlock=immediate",
+	"_pragma=foreign_keys(1)",
+	"_pragma=busy_timeout(5000)",
+	"_pragma=journal_mode(WAL)",
+}
+
+// SQLiteStore is a Database backed by a single SQLite file, for development
+// and single-node deployments that don't want to run Postgres. It does not
+// maintain the hourly metrics rollup or support retention archiving, so the
+// dashboard aggregates raw delivery attempts.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLite opens the SQLite database named by a "sqlite:" URL, e.g.
+// sqlite:webhooks.db, sqlite:///var/lib/webhooks/webhooks.db or
+// sqlite::memory:. The file is created if it does not exist.
+func NewSQLite(ctx context.Context, databaseURL string) (*SQLiteStore, error) {
+	path := strings.TrimPrefix(strings.TrimPrefix(databaseURL, "sqlite:"), "//")
+	if path == "" {
+		return nil, fmt.Errorf("sqlite database url %q has no path", databaseURL)
+	}
+
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	db, err := sql.Open("sqlite", path+sep+strings.Join(sqliteParams, "&"))
+	if err != nil {
+		return nil, fmt.Errorf("opening sqlite: %w", err)
+	}
+
+	// SQLite allows one writer at a time, and an in-memory database exists
+	// only on the connection that created it, so share a single connection.
+	db.SetMaxOpenConns(1)
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("opening sqlite: %w", err)
+	}
+
+	return &SQLiteStore{db: db}, nil
+}
+
+func (s *SQLiteStore) Close() {
+	s.db.Close()
+}
+
+// Ping checks that the database file is still readable.
+func (s *SQLiteStore) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+// RunMigrations executes the .up.sql files in the sqlite directory of fsys
+// in order, skipping those already applied, and returns the versions it
+// applied.
+func (s *SQLiteStore) RunMigrations(ctx context.Context, fsys fs.FS) ([]string, error) {
+	if err := s.createMigrationsTable(ctx); err != nil {
+		return nil, err
+	}
+
+	dir, err := fs.Sub(fsys, sqliteMigrationsDir)
+	if err != nil {
+		return nil, fmt.Errorf("reading migrations: %w", err)
+	}
+	migrations, err := migrationVersions(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []string
+	for _, version := range migrations {
+		var exists bool
+		err := s.db.QueryRowContext(ctx,
+			"SELECT EXISTS(SELECT 1 FROM schema_migrations WHERE version = ?)",
+			version,
+		).Scan(&exists)
+		if err != nil {
+			return applied, fmt.Errorf("checking migration %s: %w", version, err)
+		}
+		if exists {
+			continue
+		}
+
+		script, err := fs.ReadFile(dir, version)
+		if err != nil {
+			return applied, fmt.Errorf("reading migration %s: %w", version, err)
+		}
+
+		// Apply the migration and record it together
+		tx, err := s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return applied, fmt.Errorf("beginning migration %s: %w", version, err)
+		}
+		if _, err := tx.ExecContext(ctx, string(script)); err != nil {
+			tx.Rollback()
+			return applied, fmt.Errorf("executing migration %s: %w", version, err)
+		}
+		if _, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (version) VALUES (?)", version); err != nil {
+			tx.Rollback()
+			return applied, fmt.Errorf("recording migration %s: %w", version, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return applied, fmt.Errorf("committing migration %s: %w", version, err)
+		}
+		applied = append(applied, version)
+	}
+
+	return applied, nil
+}
+
+// RollbackMigrations reverts the n most recently applied migrations, newest
+// first, with their .down.sql files in the sqlite directory of fsys, and
+// returns their versions.
+func (s *SQLiteStore) RollbackMigrations(ctx context.Context, fsys fs.FS, n int) ([]string, error) {
+	if err := s.createMigrationsTable(ctx); err != nil {
+		return nil, err
+	}
+	dir, err := fs.Sub(fsys, sqliteMigrationsDir)
+	if err != nil {
+		return nil, fmt.Errorf("reading migrations: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, "SELECT version FROM schema_migrations ORDER BY version DESC LIMIT ?", n)
+	if err != nil {
+		return nil, fmt.Errorf("listing applied migrations: %w", err)
+	}
+	var versions []string
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scanning applied migration: %w", err)
+		}
+		versions = append(versions, version)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("listing applied migrations: %w", err)
+	}
+
+	var reverted []string
+	for _, version := range versions {
+		script, err := readDownMigration(dir, version)
+		if err != nil {
+			return reverted, err
+		}
+
+		// Revert the migration and forget it together
+		tx, err := s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return reverted, fmt.Errorf("beginning rollback of %s: %w", version, err)
+		}
+		if _, err := tx.ExecContext(ctx, string(script)); err != nil {
+			tx.Rollback()
+			return reverted, fmt.Errorf("reverting migration %s: %w", version, err)
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = ?", version); err != nil {
+			tx.Rollback()
+			return reverted, fmt.Errorf("unrecording migration %s: %w", version, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return reverted, fmt.Errorf("committing rollback of %s: %w", version, err)
+		}
+		reverted = append(reverted, version)
+	}
+	return reverted, nil
+}
+
+// MigrationStatus lists the migrations in the sqlite directory of fsys, and
+// any applied migration missing from it, with when each was applied.
+func (s *SQLiteStore) MigrationStatus(ctx context.Context, fsys fs.FS) ([]MigrationState, error) {
+	dir, err := fs.Sub(fsys, sqliteMigrationsDir)
+	if err != nil {
+		return nil, fmt.Errorf("reading migrations: %w", err)
+	}
+	versions, err := migrationVersions(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[string]time.Time)
+	var exists bool
+	err = s.db.QueryRowContext(ctx,
+		"SELECT EXISTS(SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations')",
+	).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("checking migrations table: %w", err)
+	}
+	if exists {
+		rows, err := s.db.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+		if err != nil {
+			return nil, fmt.Errorf("listing applied migrations: %w", err)
+		}
+		for rows.Next() {
+			var version string
+			var at time.Time
+			if err := rows.Scan(&version, &at); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("scanning applied migration: %w", err)
+			}
+			applied[version] = at
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("listing applied migrations: %w", err)
+		}
+	}
+
+	return migrationStates(versions, applied), nil
+}
+
+// PendingMigrations returns the migrations in the sqlite directory of fsys
+// that have not been applied yet.
+func (s *SQLiteStore) PendingMigrations(ctx context.Context, fsys fs.FS) ([]string, error) {
+	states, err := s.MigrationStatus(ctx, fsys)
+	if err != nil {
+		return nil, err
+	}
+	return pendingMigrations(states), nil
+}
+
+func (s *SQLiteStore) createMigrationsTable(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version TEXT PRIMARY KEY,
+			applied_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("creating migrations table: %w", err)
+	}
+	return nil
+}
+
+// nullString maps "" to NULL, as the Postgres store does for optional text.
+func nullString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,1713 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+)
+
+// The queries below mirror the Postgres store method for method. Where
+// Postgres does the work in SQL (percentiles, time bucketing, pattern
+// matching), SQLite rows are fetched and aggregated in Go.
+
+func (s *SQLiteStore) CreateSubscriber(ctx context.Context, req domain.CreateSubscriberRequest) (*domain.Subscriber, error) {
+	secretKey, err := subscriberSecretKey(req)
+	if err != nil {
+		return nil, fmt.Errorf("generating secret key: %w", err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	var sub domain.Subscriber
+	err = scanSubscriber(tx.QueryRowContext(ctx, `
+		INSERT INTO subscribers (id, name, endpoint_url, secret_key, compress_payloads, discard_response_bodies, batch_max_events, batch_window_seconds, proxy_url, signature_format, event_versions, sandbox, daily_quota, monthly_quota, auto_replay_dead_letters, retry_jitter, max_retry_duration_seconds, retry_for_duration, success_body_contains, success_json_path, redirect_policy, endpoint_type, endpoint_credentials, is_system, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING `+subscriberColumns,
+		newUUID(), req.Name, req.EndpointURL, secretKey, req.CompressPayloads, req.DiscardResponseBodies, req.BatchMaxEvents, req.BatchWindowSeconds, req.ProxyURL, domain.SignatureFormatOrDefault(req.SignatureFormat), string(encodeEventVersions(req.EventVersions)), req.Sandbox, req.DailyQuota, req.MonthlyQuota, req.AutoReplayDeadLetters, req.RetryJitter, req.MaxRetryDurationSeconds, req.RetryForDuration, req.SuccessBodyContains, req.SuccessJSONPath, req.RedirectPolicy, domain.EndpointTypeOrDefault(req.EndpointType), encodeEndpointCredentials(req.EndpointCredentials), req.IsSystem, now, now,
+	), &sub)
+	if err != nil {
+		return nil, fmt.Errorf("inserting subscriber: %w", err)
+	}
+
+	for _, eventType := range req.EventTypes {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO subscriptions (id, subscriber_id, event_type, created_at)
+			VALUES (?, ?, ?, ?)
+		`, newUUID(), sub.ID, eventType, now)
+		if err != nil {
+			return nil, fmt.Errorf("inserting subscription for %s: %w", eventType, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("committing transaction: %w", err)
+	}
+
+	return &sub, nil
+}
+
+func (s *SQLiteStore) GetSubscriber(ctx context.Context, id string) (*domain.Subscriber, error) {
+	var sub domain.Subscriber
+	err := scanSubscriber(s.db.QueryRowContext(ctx, `
+		SELECT `+subscriberColumns+`
+		FROM subscribers WHERE id = ?
+	`, id), &sub)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("querying subscriber: %w", err)
+	}
+	return &sub, nil
+}
+
+func (s *SQLiteStore) ListSubscribers(ctx context.Context) ([]domain.Subscriber, error) {
+	subscribers, err := s.querySubscribers(ctx, `
+		SELECT `+subscriberColumns+`
+		FROM subscribers
+		WHERE deleted_at IS NULL
+		ORDER BY created_at DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying subscribers: %w", err)
+	}
+	for i := range subscribers {
+		subscribers[i].SecretKey = "" // never expose secrets in listings
+		subscribers[i].EndpointCredentials = nil
+	}
+	return subscribers, nil
+}
+
+func (s *SQLiteStore) UpdateSubscriber(ctx context.Context, id string, req domain.UpdateSubscriberRequest) (*domain.Subscriber, error) {
+	setClauses := []string{}
+	args := []interface{}{}
+
+	if req.Name != nil {
+		setClauses = append(setClauses, "name = ?")
+		args = append(args, *req.Name)
+	}
+	if req.EndpointURL != nil {
+		setClauses = append(setClauses, "endpoint_url = ?")
+		args = append(args, *req.EndpointURL)
+	}
+	if req.IsActive != nil {
+		setClauses = append(setClauses, "is_active = ?")
+		args = append(args, *req.IsActive)
+	}
+	if req.RateLimitPerSecond != nil {
+		setClauses = append(setClauses, "rate_limit_per_second = ?")
+		args = append(args, *req.RateLimitPerSecond)
+	}
+	if req.CompressPayloads != nil {
+		setClauses = append(setClauses, "compress_payloads = ?")
+		args = append(args, *req.CompressPayloads)
+	}
+	if req.DiscardResponseBodies != nil {
+		setClauses = append(setClauses, "discard_response_bodies = ?")
+		args = append(args, *req.DiscardResponseBodies)
+	}
+	if req.BatchMaxEvents != nil {
+		setClauses = append(setClauses, "batch_max_events = ?")
+		args = append(args, *req.BatchMaxEvents)
+	}
+	if req.BatchWindowSeconds != nil {
+		setClauses = append(setClauses, "batch_window_seconds = ?")
+		args = append(args, *req.BatchWindowSeconds)
+	}
+	if req.ProxyURL != nil {
+		setClauses = append(setClauses, "proxy_url = ?")
+		args = append(args, *req.ProxyURL)
+	}
+	if req.DebugLogging != nil {
+		setClauses = append(setClauses, "debug_logging = ?")
+		args = append(args, *req.DebugLogging)
+	}
+	if req.SignatureFormat != nil {
+		setClauses = append(setClauses, "signature_format = ?")
+		args = append(args, domain.SignatureFormatOrDefault(*req.SignatureFormat))
+	}
+	if req.EventVersions != nil {
+		setClauses = append(setClauses, "event_versions = ?")
+		args = append(args, string(encodeEventVersions(req.EventVersions)))
+	}
+	if req.Sandbox != nil {
+		setClauses = append(setClauses, "sandbox = ?")
+		args = append(args, *req.Sandbox)
+	}
+	if req.DailyQuota != nil {
+		setClauses = append(setClauses, "daily_quota = ?")
+		args = append(args, *req.DailyQuota)
+	}
+	if req.MonthlyQuota != nil {
+		setClauses = append(setClauses, "monthly_quota = ?")
+		args = append(args, *req.MonthlyQuota)
+	}
+	if req.AutoReplayDeadLetters != nil {
+		setClauses = append(setClauses, "auto_replay_dead_letters = ?")
+		args = append(args, *req.AutoReplayDeadLetters)
+	}
+	if req.RetryJitter != nil {
+		setClauses = append(setClauses, "retry_jitter = ?")
+		args = append(args, *req.RetryJitter)
+	}
+	if req.MaxRetryDurationSeconds != nil {
+		setClauses = append(setClauses, "max_retry_duration_seconds = ?")
+		args = append(args, *req.MaxRetryDurationSeconds)
+	}
+	if req.RetryForDuration != nil {
+		setClauses = append(setClauses, "retry_for_duration = ?")
+		args = append(args, *req.RetryForDuration)
+	}
+	if req.SuccessBodyContains != nil {
+		setClauses = append(setClauses, "success_body_contains = ?")
+		args = append(args, *req.SuccessBodyContains)
+	}
+	if req.SuccessJSONPath != nil {
+		setClauses = append(setClauses, "success_json_path = ?")
+		args = append(args, *req.SuccessJSONPath)
+	}
+	if req.RedirectPolicy != nil {
+		setClauses = append(setClauses, "redirect_policy = ?")
+		args = append(args, *req.RedirectPolicy)
+	}
+	if req.EndpointType != nil {
+		setClauses = append(setClauses, "endpoint_type = ?")
+		args = append(args, domain.EndpointTypeOrDefault(*req.EndpointType))
+	}
+	if req.EndpointCredentials != nil {
+		setClauses = append(setClauses, "endpoint_credentials = ?")
+		args = append(args, encodeEndpointCredentials(req.EndpointCredentials))
+	}
+
+	if len(setClauses) == 0 {
+		sub, err := s.GetSubscriber(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return checkVersion(sub, req.Version)
+	}
+
+	setClauses = append(setClauses, "version = version + 1", "updated_at = ?")
+	args = append(args, time.Now(), id)
+	where := "id = ?"
+	if req.Version != nil {
+		where += " AND version = ?"
+		args = append(args, *req.Version)
+	}
+
+	var sub domain.Subscriber
+	err := scanSubscriber(s.db.QueryRowContext(ctx, `
+		UPDATE subscribers SET `+strings.Join(setClauses, ", ")+`
+		WHERE `+where+`
+		RETURNING `+subscriberColumns,
+		args...,
+	), &sub)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			if req.Version == nil {
+				return nil, nil
+			}
+			// Either the subscriber is gone or its version moved on
+			current, err := s.GetSubscriber(ctx, id)
+			if err != nil || current == nil {
+				return nil, err
+			}
+			return nil, ErrVersionConflict
+		}
+		return nil, fmt.Errorf("updating subscriber: %w", err)
+	}
+	sub.SecretKey = ""
+	sub.EndpointCredentials = nil
+
+	return &sub, nil
+}
+
+func (s *SQLiteStore) DeleteSubscriber(ctx context.Context, id string) (*domain.Subscriber, error) {
+	now := time.Now()
+	return s.setSubscriberDeletedAt(ctx, id, &now)
+}
+
+func (s *SQLiteStore) RestoreSubscriber(ctx context.Context, id string) (*domain.Subscriber, error) {
+	return s.setSubscriberDeletedAt(ctx, id, nil)
+}
+
+func (s *SQLiteStore) setSubscriberDeletedAt(ctx context.Context, id string, deletedAt *time.Time) (*domain.Subscriber, error) {
+	var sub domain.Subscriber
+	err := scanSubscriber(s.db.QueryRowContext(ctx, `
+		UPDATE subscribers SET deleted_at = ?, version = version + 1, updated_at = ?
+		WHERE id = ?
+		RETURNING `+subscriberColumns,
+		deletedAt, time.Now(), id,
+	), &sub)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("updating subscriber: %w", err)
+	}
+	sub.SecretKey = ""
+	sub.EndpointCredentials = nil
+
+	return &sub, nil
+}
+
+func (s *SQLiteStore) GetSubscriberSubscriptions(ctx context.Context, subscriberID string) ([]domain.Subscription, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, subscriber_id, event_type, is_active, created_at
+		FROM subscriptions
+		WHERE subscriber_id = ?
+		ORDER BY created_at
+	`, subscriberID)
+	if err != nil {
+		return nil, fmt.Errorf("querying subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	subs := []domain.Subscription{}
+	for rows.Next() {
+		var sub domain.Subscription
+		err := rows.Scan(&sub.ID, &sub.SubscriberID, &sub.EventType, &sub.IsActive, &sub.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("scanning subscription: %w", err)
+		}
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
+
+// ListActiveSubscriptions returns every active subscription, whatever the
+// state of its subscriber.
+func (s *SQLiteStore) ListActiveSubscriptions(ctx context.Context) ([]domain.Subscription, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, subscriber_id, event_type, is_active, created_at
+		FROM subscriptions
+		WHERE is_active = 1
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	subs := []domain.Subscription{}
+	for rows.Next() {
+		var sub domain.Subscription
+		err := rows.Scan(&sub.ID, &sub.SubscriberID, &sub.EventType, &sub.IsActive, &sub.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("scanning subscription: %w", err)
+		}
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
+
+// FindMatchingSubscribers loads the active subscriptions that name the event
+// type exactly or contain a wildcard, and matches the patterns in Go.
+func (s *SQLiteStore) FindMatchingSubscribers(ctx context.Context, eventType string) ([]domain.Subscriber, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT DISTINCT subscriber_id, event_type
+		FROM subscriptions
+		WHERE is_active = 1 AND (event_type = ? OR event_type LIKE '%*%')
+	`, eventType)
+	if err != nil {
+		return nil, fmt.Errorf("finding matching subscribers: %w", err)
+	}
+
+	var ids []interface{}
+	seen := map[string]bool{}
+	for rows.Next() {
+		var id, pattern string
+		if err := rows.Scan(&id, &pattern); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scanning subscription: %w", err)
+		}
+		if !seen[id] && domain.MatchEventType(pattern, eventType) {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("finding matching subscribers: %w", err)
+	}
+
+	if len(ids) == 0 {
+		return []domain.Subscriber{}, nil
+	}
+	subscribers, err := s.querySubscribers(ctx, `
+		SELECT `+subscriberColumns+`
+		FROM subscribers
+		WHERE is_active = 1 AND deleted_at IS NULL AND id IN (`+placeholders(len(ids))+`)
+	`, ids...)
+	if err != nil {
+		return nil, fmt.Errorf("finding matching subscribers: %w", err)
+	}
+	return subscribers, nil
+}
+
+func (s *SQLiteStore) querySubscribers(ctx context.Context, query string, args ...interface{}) ([]domain.Subscriber, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subscribers := []domain.Subscriber{}
+	for rows.Next() {
+		var sub domain.Subscriber
+		if err := scanSubscriber(rows, &sub); err != nil {
+			return nil, fmt.Errorf("scanning subscriber: %w", err)
+		}
+		subscribers = append(subscribers, sub)
+	}
+	return subscribers, rows.Err()
+}
+
+// CreateEvent inserts the event and its fan-out outbox entry in a single
+// transaction.
+func (s *SQLiteStore) CreateEvent(ctx context.Context, eventType string, version int, payload []byte, ref *domain.PayloadRef, source string, subscriberIDs []string) (*domain.Event, error) {
+	var targets *string
+	if len(subscriberIDs) > 0 {
+		data, err := json.Marshal(subscriberIDs)
+		if err != nil {
+			return nil, fmt.Errorf("encoding subscriber ids: %w", err)
+		}
+		ids := string(data)
+		targets = &ids
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	event := domain.Event{
+		ID:            newUUID(),
+		EventType:     eventType,
+		Version:       version,
+		Payload:       payload,
+		Source:        source,
+		SubscriberIDs: subscriberIDs,
+		PayloadRef:    ref,
+		CreatedAt:     now,
+	}
+	refKey, refSize := splitPayloadRef(ref)
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO events (id, event_type, version, payload, payload_ref, payload_size, source, subscriber_ids, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, event.ID, eventType, version, string(payload), refKey, refSize, source, targets, now)
+	if err != nil {
+		return nil, fmt.Errorf("inserting event: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO fanout_outbox (event_id, available_at, created_at)
+		VALUES (?, ?, ?)
+	`, event.ID, now.Add(outboxGracePeriod), now)
+	if err != nil {
+		return nil, fmt.Errorf("inserting outbox entry: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("committing transaction: %w", err)
+	}
+
+	return &event, nil
+}
+
+func (s *SQLiteStore) GetEvent(ctx context.Context, id string) (*domain.Event, error) {
+	event, err := scanSQLiteEvent(s.db.QueryRowContext(ctx, `
+		SELECT id, event_type, version, payload, payload_ref, payload_size, COALESCE(source, ''), subscriber_ids, created_at
+		FROM events WHERE id = ?
+	`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("querying event: %w", err)
+	}
+	return event, nil
+}
+
+func (s *SQLiteStore) ListEvents(ctx context.Context, eventType string, limit int) ([]domain.Event, error) {
+	query := `SELECT id, event_type, version, payload, payload_ref, payload_size, COALESCE(source, ''), subscriber_ids, created_at FROM events`
+	args := []interface{}{}
+
+	if eventType != "" {
+		query += " WHERE event_type = ?"
+		args = append(args, eventType)
+	}
+
+	query += " ORDER BY created_at DESC"
+
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []domain.Event{}
+	for rows.Next() {
+		e, err := scanSQLiteEvent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning event: %w", err)
+		}
+		events = append(events, *e)
+	}
+	return events, rows.Err()
+}
+
+// SearchEvents compares the JSON found at each term's path with the term's
+// candidate values. SQLite has no index on payloads, so every event of the
+// type, or every event, is scanned.
+func (s *SQLiteStore) SearchEvents(ctx context.Context, f EventSearchFilter) ([]domain.Event, error) {
+	query := `SELECT id, event_type, version, payload, payload_ref, payload_size, COALESCE(source, ''), subscriber_ids, created_at FROM events`
+	args := []interface{}{}
+	conditions := []string{}
+
+	for _, term := range f.Terms {
+		candidates := term.Candidates()
+		args = append(args, sqliteJSONPath(term.Path))
+		for _, c := range candidates {
+			args = append(args, string(c))
+		}
+		conditions = append(conditions, "payload -> ? IN ("+placeholders(len(candidates))+")")
+	}
+	if f.EventType != "" {
+		conditions = append(conditions, "event_type = ?")
+		args = append(args, f.EventType)
+	}
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query += " ORDER BY created_at DESC"
+	if f.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, f.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("searching events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []domain.Event{}
+	for rows.Next() {
+		e, err := scanSQLiteEvent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning event: %w", err)
+		}
+		events = append(events, *e)
+	}
+	return events, rows.Err()
+}
+
+// sqliteJSONPath returns the JSON path of the object keys in path, quoted so
+// that keys with dots or spaces are taken literally.
+func sqliteJSONPath(path []string) string {
+	var b strings.Builder
+	b.WriteString("$")
+	for _, key := range path {
+		b.WriteString(`."`)
+		b.WriteString(strings.ReplaceAll(key, `"`, `\"`))
+		b.WriteString(`"`)
+	}
+	return b.String()
+}
+
+// ListEventTypes returns the event type catalog. Counts come from one
+// grouped query; the latest payload and time of each type are then read one
+// type at a time so they scan with their column types.
+func (s *SQLiteStore) ListEventTypes(ctx context.Context) ([]domain.EventType, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT name, description, example_payload, current_version
+		FROM event_types
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying event types: %w", err)
+	}
+	var documented []domain.EventType
+	for rows.Next() {
+		et := domain.EventType{Documented: true}
+		var example *string
+		if err := rows.Scan(&et.Name, &et.Description, &example, &et.CurrentVersion); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scanning event type: %w", err)
+		}
+		if example != nil {
+			et.ExamplePayload = json.RawMessage(*example)
+		}
+		documented = append(documented, et)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("querying event types: %w", err)
+	}
+
+	rows, err = s.db.QueryContext(ctx, `
+		SELECT event_type, COUNT(*), MAX(version)
+		FROM events
+		GROUP BY event_type
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying published event types: %w", err)
+	}
+	var published []domain.EventType
+	for rows.Next() {
+		var et domain.EventType
+		if err := rows.Scan(&et.Name, &et.EventCount, &et.CurrentVersion); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scanning published event type: %w", err)
+		}
+		published = append(published, et)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("querying published event types: %w", err)
+	}
+
+	for i := range published {
+		var payload string
+		var lastSeen time.Time
+		err := s.db.QueryRowContext(ctx, `
+			SELECT payload, created_at FROM events
+			WHERE event_type = ?
+			ORDER BY created_at DESC
+			LIMIT 1
+		`, published[i].Name).Scan(&payload, &lastSeen)
+		if err != nil {
+			return nil, fmt.Errorf("querying latest %s event: %w", published[i].Name, err)
+		}
+		published[i].ExamplePayload = json.RawMessage(payload)
+		published[i].LastSeenAt = &lastSeen
+	}
+
+	return mergeEventTypes(documented, published), nil
+}
+
+func (s *SQLiteStore) DescribeEventType(ctx context.Context, name string, req domain.DescribeEventTypeRequest) (*domain.EventType, error) {
+	var example *string
+	if len(req.ExamplePayload) > 0 {
+		e := string(req.ExamplePayload)
+		example = &e
+	}
+	version := max(req.CurrentVersion, 1)
+
+	now := time.Now()
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO event_types (name, description, example_payload, current_version, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (name) DO UPDATE
+		SET description = excluded.description,
+		    example_payload = excluded.example_payload,
+		    current_version = excluded.current_version,
+		    updated_at = excluded.updated_at
+	`, name, req.Description, example, version, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("describing event type: %w", err)
+	}
+
+	return &domain.EventType{
+		Name:           name,
+		Description:    req.Description,
+		ExamplePayload: req.ExamplePayload,
+		Documented:     true,
+		CurrentVersion: version,
+	}, nil
+}
+
+func scanSQLiteEvent(row interface{ Scan(...interface{}) error }) (*domain.Event, error) {
+	var e domain.Event
+	var payload, targets []byte
+	var refKey string
+	var refSize int64
+	if err := row.Scan(&e.ID, &e.EventType, &e.Version, &payload, &refKey, &refSize, &e.Source, &targets, &e.CreatedAt); err != nil {
+		return nil, err
+	}
+	e.Payload = payload
+	e.PayloadRef = payloadRef(refKey, refSize)
+	if len(targets) > 0 {
+		if err := json.Unmarshal(targets, &e.SubscriberIDs); err != nil {
+			return nil, fmt.Errorf("decoding subscriber ids: %w", err)
+		}
+	}
+	return &e, nil
+}
+
+func (s *SQLiteStore) RecordDeliveryAttempt(ctx context.Context, rec DeliveryAttemptRecord) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insertSQLiteAttempt(ctx, tx, rec); err != nil {
+		return fmt.Errorf("inserting delivery attempt: %w", err)
+	}
+	if err := meterSQLiteUsage(ctx, tx, []DeliveryAttemptRecord{rec}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// InsertDeliveryAttempts inserts a batch of delivery attempts in one
+// transaction, so the batch and its metering cost a single commit.
+func (s *SQLiteStore) InsertDeliveryAttempts(ctx context.Context, recs []DeliveryAttemptRecord) error {
+	if len(recs) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, rec := range recs {
+		if err := insertSQLiteAttempt(ctx, tx, rec); err != nil {
+			return fmt.Errorf("inserting %d delivery attempts: %w", len(recs), err)
+		}
+	}
+	if err := meterSQLiteUsage(ctx, tx, recs); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// meterSQLiteUsage adds recs to today's usage within tx.
+func meterSQLiteUsage(ctx context.Context, tx *sql.Tx, recs []DeliveryAttemptRecord) error {
+	day := usageDay(time.Now()).Format(time.DateOnly)
+	for _, u := range usageDeltas(recs) {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO usage_daily (day, subscriber_id, deliveries, successful, retries, payload_bytes)
+			VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT (day, subscriber_id) DO UPDATE SET
+				deliveries = deliveries + excluded.deliveries,
+				successful = successful + excluded.successful,
+				retries = retries + excluded.retries,
+				payload_bytes = payload_bytes + excluded.payload_bytes
+		`, day, u.SubscriberID, u.Deliveries, u.Successful, u.Retries, u.PayloadBytes)
+		if err != nil {
+			return fmt.Errorf("metering usage: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *SQLiteStore) ListUsage(ctx context.Context, f UsageFilter) ([]domain.UsageDay, error) {
+	query := `
+		SELECT u.day, u.subscriber_id, COALESCE(s.name, ''), u.deliveries, u.successful, u.retries, u.payload_bytes
+		FROM usage_daily u
+		LEFT JOIN subscribers s ON s.id = u.subscriber_id
+		WHERE u.day BETWEEN ? AND ?`
+	args := []interface{}{usageDay(f.From).Format(time.DateOnly), usageDay(f.To).Format(time.DateOnly)}
+	if f.SubscriberID != "" {
+		query += " AND u.subscriber_id = ?"
+		args = append(args, f.SubscriberID)
+	}
+	query += " ORDER BY u.day, s.name, u.subscriber_id"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing usage: %w", err)
+	}
+	defer rows.Close()
+
+	days := []domain.UsageDay{}
+	for rows.Next() {
+		var u domain.UsageDay
+		var day string
+		if err := rows.Scan(&day, &u.SubscriberID, &u.SubscriberName, &u.Deliveries, &u.Successful, &u.Retries, &u.PayloadBytes); err != nil {
+			return nil, fmt.Errorf("scanning usage: %w", err)
+		}
+		if u.Day, err = time.Parse(time.DateOnly, day); err != nil {
+			return nil, fmt.Errorf("parsing usage day %q: %w", day, err)
+		}
+		days = append(days, u)
+	}
+	return days, rows.Err()
+}
+
+func insertSQLiteAttempt(ctx context.Context, db interface {
+	ExecContext(context.Context, string, ...interface{}) (sql.Result, error)
+}, rec DeliveryAttemptRecord) error {
+	var respHeaders *string
+	if len(rec.ResponseHeaders) > 0 {
+		data, err := json.Marshal(rec.ResponseHeaders)
+		if err != nil {
+			return fmt.Errorf("encoding response headers: %w", err)
+		}
+		headers := string(data)
+		respHeaders = &headers
+	}
+	var timings *string
+	if rec.Timings != nil {
+		data, err := json.Marshal(rec.Timings)
+		if err != nil {
+			return fmt.Errorf("encoding timings: %w", err)
+		}
+		t := string(data)
+		timings = &t
+	}
+
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO delivery_attempts (id, event_id, subscriber_id, attempt_number, status, http_status_code, response_body, response_headers, response_time_ms, timings, error_message, failure_reason, next_retry_at, final_url, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, rec.id(), rec.EventID, rec.SubscriberID, rec.AttemptNumber, rec.Status, rec.HTTPStatusCode,
+		nullString(rec.ResponseBody), respHeaders, rec.ResponseTimeMs, timings, nullString(rec.ErrorMessage), nullString(string(rec.FailureReason)), rec.NextRetryAt, nullString(rec.FinalURL), time.Now())
+	return err
+}
+
+// deliveryAttemptColumns is the column list scanned by scanSQLiteAttempt.
+const deliveryAttemptColumns = `id, event_id, subscriber_id, attempt_number, status, http_status_code, response_body, response_headers, response_time_ms, timings, error_message, failure_reason, next_retry_at, final_url, created_at`
+
+func scanSQLiteAttempt(row interface{ Scan(...interface{}) error }) (*domain.DeliveryAttempt, error) {
+	var a domain.DeliveryAttempt
+	var headers, timings []byte
+	err := row.Scan(
+		&a.ID, &a.EventID, &a.SubscriberID, &a.AttemptNumber,
+		&a.Status, &a.HTTPStatusCode, &a.ResponseBody, &headers,
+		&a.ResponseTimeMs, &timings, &a.ErrorMessage, &a.FailureReason, &a.NextRetryAt, &a.FinalURL, &a.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(headers) > 0 {
+		if err := json.Unmarshal(headers, &a.ResponseHeaders); err != nil {
+			return nil, fmt.Errorf("decoding response headers: %w", err)
+		}
+	}
+	if len(timings) > 0 {
+		if err := json.Unmarshal(timings, &a.Timings); err != nil {
+			return nil, fmt.Errorf("decoding timings: %w", err)
+		}
+	}
+	return &a, nil
+}
+
+func (s *SQLiteStore) ListDeliveryAttempts(ctx context.Context, f DeliveryAttemptFilter) ([]domain.DeliveryAttempt, error) {
+	conditions := []string{}
+	args := []interface{}{}
+
+	if f.EventID != "" {
+		conditions = append(conditions, "event_id = ?")
+		args = append(args, f.EventID)
+	}
+	if f.SubscriberID != "" {
+		conditions = append(conditions, "subscriber_id = ?")
+		args = append(args, f.SubscriberID)
+	}
+	if f.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, f.Status)
+	}
+	if f.FailureReason != "" {
+		conditions = append(conditions, "failure_reason = ?")
+		args = append(args, f.FailureReason)
+	}
+	for name, value := range f.ResponseHeaders {
+		conditions = append(conditions, "json_extract(response_headers, ?) = ?")
+		args = append(args, `$."`+name+`"`, value)
+	}
+	conditions, args = sqlitePageConditions(conditions, args, f.Since, f.Until, f.After)
+
+	query := `SELECT ` + deliveryAttemptColumns + ` FROM delivery_attempts`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY created_at DESC, id DESC"
+	if f.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, f.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying delivery attempts: %w", err)
+	}
+	defer rows.Close()
+
+	attempts := []domain.DeliveryAttempt{}
+	for rows.Next() {
+		a, err := scanSQLiteAttempt(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning delivery attempt: %w", err)
+		}
+		attempts = append(attempts, *a)
+	}
+	return attempts, rows.Err()
+}
+
+// sqlitePageConditions appends the created_at range and cursor conditions of
+// a newest-first listing to a WHERE clause.
+func sqlitePageConditions(conditions []string, args []interface{}, since, until time.Time, after *PageCursor) ([]string, []interface{}) {
+	if !since.IsZero() {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, since)
+	}
+	if !until.IsZero() {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, until)
+	}
+	if after != nil {
+		conditions = append(conditions, "(created_at < ? OR (created_at = ? AND id < ?))")
+		args = append(args, after.CreatedAt, after.CreatedAt, after.ID)
+	}
+	return conditions, args
+}
+
+func (s *SQLiteStore) GetDeliveryAttempt(ctx context.Context, id string) (*domain.DeliveryAttempt, error) {
+	a, err := scanSQLiteAttempt(s.db.QueryRowContext(ctx, `
+		SELECT `+deliveryAttemptColumns+`
+		FROM delivery_attempts WHERE id = ?
+	`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("querying delivery attempt: %w", err)
+	}
+	return a, nil
+}
+
+// InsertDeadLetter adds a permanently failed delivery to the dead letter queue,
+// snapshotting the event so the entry outlives the event row.
+func (s *SQLiteStore) InsertDeadLetter(ctx context.Context, rec DeadLetterRecord) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO dead_letter_queue (id, event_id, subscriber_id, total_attempts, last_http_status, last_error, failure_reason, event_type, payload, event_source, created_at)
+		SELECT ?1, ?2, ?3, ?4, ?5, ?6, ?7, e.event_type, e.payload, e.source, ?8
+		FROM (SELECT 1) AS one LEFT JOIN events e ON e.id = ?2
+	`, newUUID(), rec.EventID, rec.SubscriberID, rec.TotalAttempts, rec.LastHTTPStatus, nullString(rec.LastError), nullString(string(rec.FailureReason)), time.Now())
+	if err != nil {
+		return fmt.Errorf("inserting dead letter: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) ListDeadLetters(ctx context.Context, f DeadLetterFilter) ([]domain.DeadLetter, error) {
+	conditions := []string{}
+	args := []interface{}{}
+
+	if f.Resolved != nil {
+		if *f.Resolved {
+			conditions = append(conditions, "resolved_at IS NOT NULL")
+		} else {
+			conditions = append(conditions, "resolved_at IS NULL")
+		}
+	}
+	if f.SubscriberID != "" {
+		conditions = append(conditions, "subscriber_id = ?")
+		args = append(args, f.SubscriberID)
+	}
+	if f.OldestFirst {
+		conditions, args = sqlitePageConditions(conditions, args, f.Since, f.Until, nil)
+		if f.After != nil {
+			conditions = append(conditions, "(created_at > ? OR (created_at = ? AND id > ?))")
+			args = append(args, f.After.CreatedAt, f.After.CreatedAt, f.After.ID)
+		}
+	} else {
+		conditions, args = sqlitePageConditions(conditions, args, f.Since, f.Until, f.After)
+	}
+
+	query := `SELECT id, event_id, subscriber_id, event_type, event_source, total_attempts, last_error, last_http_status, failure_reason, created_at, resolved_at, resolved_by FROM dead_letter_queue`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	if f.OldestFirst {
+		query += " ORDER BY created_at, id"
+	} else {
+		query += " ORDER BY created_at DESC, id DESC"
+	}
+
+	if f.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, f.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying dead letters: %w", err)
+	}
+	defer rows.Close()
+
+	letters := []domain.DeadLetter{}
+	for rows.Next() {
+		var dl domain.DeadLetter
+		err := rows.Scan(
+			&dl.ID, &dl.EventID, &dl.SubscriberID, &dl.EventType, &dl.EventSource, &dl.TotalAttempts,
+			&dl.LastError, &dl.LastHTTPStatus, &dl.FailureReason, &dl.CreatedAt,
+			&dl.ResolvedAt, &dl.ResolvedBy,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning dead letter: %w", err)
+		}
+		letters = append(letters, dl)
+	}
+	return letters, rows.Err()
+}
+
+// GetDeadLetter returns a single dead letter by ID, including its payload.
+func (s *SQLiteStore) GetDeadLetter(ctx context.Context, id string) (*domain.DeadLetter, error) {
+	var dl domain.DeadLetter
+	var payload []byte
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, event_id, subscriber_id, event_type, event_source, payload, total_attempts, last_error, last_http_status, failure_reason, created_at, resolved_at, resolved_by
+		FROM dead_letter_queue WHERE id = ?
+	`, id).Scan(
+		&dl.ID, &dl.EventID, &dl.SubscriberID, &dl.EventType, &dl.EventSource, &payload, &dl.TotalAttempts,
+		&dl.LastError, &dl.LastHTTPStatus, &dl.FailureReason, &dl.CreatedAt,
+		&dl.ResolvedAt, &dl.ResolvedBy,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("querying dead letter: %w", err)
+	}
+	dl.Payload = payload
+	return &dl, nil
+}
+
+func (s *SQLiteStore) ResolveDeadLetter(ctx context.Context, id string, resolvedBy string) error {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE dead_letter_queue SET resolved_at = ?, resolved_by = ?
+		WHERE id = ? AND resolved_at IS NULL
+	`, time.Now(), resolvedBy, id)
+	if err != nil {
+		return fmt.Errorf("resolving dead letter: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("dead letter not found or already resolved")
+	}
+	return nil
+}
+
+func (s *SQLiteStore) ResolveSubscriberDeadLetters(ctx context.Context, subscriberID string, resolvedBy string) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE dead_letter_queue SET resolved_at = ?, resolved_by = ?
+		WHERE subscriber_id = ? AND resolved_at IS NULL
+	`, time.Now(), resolvedBy, subscriberID)
+	if err != nil {
+		return 0, fmt.Errorf("resolving dead letters: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// SummarizeDeadLetters groups the dead letters in Go rather than SQL, since
+// SQLite would return their MIN and MAX creation times as untyped text.
+func (s *SQLiteStore) SummarizeDeadLetters(ctx context.Context, subscriberID string) ([]domain.DeadLetterGroup, error) {
+	query := `
+		SELECT d.subscriber_id, COALESCE(s.name, ''), d.failure_reason, d.last_http_status, d.created_at
+		FROM dead_letter_queue d
+		LEFT JOIN subscribers s ON s.id = d.subscriber_id
+		WHERE d.resolved_at IS NULL`
+	args := []interface{}{}
+	if subscriberID != "" {
+		query += " AND d.subscriber_id = ?"
+		args = append(args, subscriberID)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("summarizing dead letters: %w", err)
+	}
+	defer rows.Close()
+
+	var letters []domain.DeadLetter
+	names := map[string]string{}
+	for rows.Next() {
+		var dl domain.DeadLetter
+		var name string
+		if err := rows.Scan(&dl.SubscriberID, &name, &dl.FailureReason, &dl.LastHTTPStatus, &dl.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning dead letter: %w", err)
+		}
+		letters = append(letters, dl)
+		names[dl.SubscriberID] = name
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("summarizing dead letters: %w", err)
+	}
+	return groupDeadLetters(letters, names), nil
+}
+
+// ExpireDeadLetters auto-resolves up to limit unresolved dead letters created
+// before cutoff with resolved_by "expired", recording an audit entry for each
+// in the same transaction.
+func (s *SQLiteStore) ExpireDeadLetters(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		UPDATE dead_letter_queue SET resolved_at = ?, resolved_by = ?
+		WHERE id IN (
+			SELECT id FROM dead_letter_queue
+			WHERE resolved_at IS NULL AND created_at < ?
+			ORDER BY created_at
+			LIMIT ?
+		)
+		RETURNING id, subscriber_id, created_at
+	`, time.Now(), domain.DeadLetterExpired, cutoff, limit)
+	if err != nil {
+		return 0, fmt.Errorf("expiring dead letters: %w", err)
+	}
+
+	type expired struct {
+		ID           string
+		SubscriberID string
+		CreatedAt    time.Time
+	}
+	var letters []expired
+	for rows.Next() {
+		var e expired
+		if err := rows.Scan(&e.ID, &e.SubscriberID, &e.CreatedAt); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scanning expired dead letter: %w", err)
+		}
+		letters = append(letters, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("expiring dead letters: %w", err)
+	}
+
+	for _, e := range letters {
+		details, _ := json.Marshal(map[string]interface{}{"subscriber_id": e.SubscriberID, "created_at": e.CreatedAt})
+		entry := &domain.AuditEntry{
+			Actor:      domain.SystemActor,
+			Action:     domain.AuditDeadLetterExpire,
+			EntityType: domain.AuditEntityDeadLetter,
+			EntityID:   e.ID,
+			Details:    details,
+		}
+		if err := insertSQLiteAuditEntry(ctx, tx, entry); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("committing transaction: %w", err)
+	}
+	return int64(len(letters)), nil
+}
+
+// CountRecentDeadLetters returns subscribers with more than threshold dead
+// letters created since the given time, busiest first.
+func (s *SQLiteStore) CountRecentDeadLetters(ctx context.Context, since time.Time, threshold int) ([]SubscriberDeadLetterCount, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT d.subscriber_id, s.name, s.endpoint_url, COUNT(*)
+		FROM dead_letter_queue d
+		JOIN subscribers s ON s.id = d.subscriber_id
+		WHERE d.created_at >= ?
+		GROUP BY d.subscriber_id, s.name, s.endpoint_url
+		HAVING COUNT(*) > ?
+		ORDER BY COUNT(*) DESC
+	`, since, threshold)
+	if err != nil {
+		return nil, fmt.Errorf("counting recent dead letters: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []SubscriberDeadLetterCount
+	for rows.Next() {
+		var c SubscriberDeadLetterCount
+		if err := rows.Scan(&c.SubscriberID, &c.Name, &c.EndpointURL, &c.Count); err != nil {
+			return nil, fmt.Errorf("scanning dead letter count: %w", err)
+		}
+		counts = append(counts, c)
+	}
+	return counts, rows.Err()
+}
+
+func (s *SQLiteStore) InsertAuditEntry(ctx context.Context, e *domain.AuditEntry) error {
+	return insertSQLiteAuditEntry(ctx, s.db, e)
+}
+
+func insertSQLiteAuditEntry(ctx context.Context, db interface {
+	ExecContext(context.Context, string, ...interface{}) (sql.Result, error)
+}, e *domain.AuditEntry) error {
+	var details *string
+	if len(e.Details) > 0 {
+		d := string(e.Details)
+		details = &d
+	}
+
+	id, now := newUUID(), time.Now()
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO audit_log (id, actor, actor_key_id, action, entity_type, entity_id, details, remote_addr, request_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, id, e.Actor, e.ActorKeyID, e.Action, e.EntityType, e.EntityID, details, nullString(e.RemoteAddr), nullString(e.RequestID), now)
+	if err != nil {
+		return fmt.Errorf("inserting audit entry: %w", err)
+	}
+	e.ID, e.CreatedAt = id, now
+	return nil
+}
+
+func (s *SQLiteStore) ListAuditEntries(ctx context.Context, f domain.AuditFilter) ([]domain.AuditEntry, error) {
+	conditions := []string{}
+	args := []interface{}{}
+
+	add := func(condition string, arg interface{}) {
+		conditions = append(conditions, condition)
+		args = append(args, arg)
+	}
+	if f.Actor != "" {
+		add("actor = ?", f.Actor)
+	}
+	if f.Action != "" {
+		add("action = ?", f.Action)
+	}
+	if f.EntityType != "" {
+		add("entity_type = ?", f.EntityType)
+	}
+	if f.EntityID != "" {
+		add("entity_id = ?", f.EntityID)
+	}
+	if !f.Since.IsZero() {
+		add("created_at >= ?", f.Since)
+	}
+	if !f.Until.IsZero() {
+		add("created_at < ?", f.Until)
+	}
+
+	query := `SELECT id, actor, actor_key_id, action, entity_type, entity_id, details, COALESCE(remote_addr, ''), COALESCE(request_id, ''), created_at FROM audit_log`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY created_at DESC"
+	if f.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, f.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying audit log: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []domain.AuditEntry{}
+	for rows.Next() {
+		var e domain.AuditEntry
+		var details []byte
+		err := rows.Scan(
+			&e.ID, &e.Actor, &e.ActorKeyID, &e.Action, &e.EntityType, &e.EntityID,
+			&details, &e.RemoteAddr, &e.RequestID, &e.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning audit entry: %w", err)
+		}
+		e.Details = details
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+func (s *SQLiteStore) CreateAPIKey(ctx context.Context, name string, role domain.Role, subscriberID *string) (*domain.APIKey, string, error) {
+	key, err := generateAPIKey()
+	if err != nil {
+		return nil, "", fmt.Errorf("generating api key: %w", err)
+	}
+
+	var k domain.APIKey
+	err = scanAPIKey(s.db.QueryRowContext(ctx, `
+		INSERT INTO api_keys (id, name, key_prefix, key_hash, role, subscriber_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		RETURNING `+apiKeyColumns,
+		newUUID(), name, key[:apiKeyPrefixLen], HashAPIKey(key), role, subscriberID, time.Now(),
+	), &k)
+	if err != nil {
+		return nil, "", fmt.Errorf("inserting api key: %w", err)
+	}
+	return &k, key, nil
+}
+
+func (s *SQLiteStore) GetAPIKeyByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	return s.getAPIKey(ctx, `key_hash = ? AND revoked_at IS NULL`, hash)
+}
+
+func (s *SQLiteStore) GetAPIKey(ctx context.Context, id string) (*domain.APIKey, error) {
+	return s.getAPIKey(ctx, `id = ?`, id)
+}
+
+func (s *SQLiteStore) getAPIKey(ctx context.Context, condition string, arg string) (*domain.APIKey, error) {
+	var k domain.APIKey
+	err := scanAPIKey(s.db.QueryRowContext(ctx, `
+		SELECT `+apiKeyColumns+`
+		FROM api_keys WHERE `+condition, arg), &k)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("querying api key: %w", err)
+	}
+	return &k, nil
+}
+
+func (s *SQLiteStore) ListAPIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+apiKeyColumns+`
+		FROM api_keys ORDER BY created_at DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying api keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []domain.APIKey{}
+	for rows.Next() {
+		var k domain.APIKey
+		if err := scanAPIKey(rows, &k); err != nil {
+			return nil, fmt.Errorf("scanning api key: %w", err)
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+func (s *SQLiteStore) RevokeAPIKey(ctx context.Context, id string) (bool, error) {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE api_keys SET revoked_at = ?
+		WHERE id = ? AND revoked_at IS NULL
+	`, time.Now(), id)
+	if err != nil {
+		return false, fmt.Errorf("revoking api key: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
+
+func (s *SQLiteStore) SetAPIKeyRole(ctx context.Context, id string, role domain.Role) (*domain.APIKey, error) {
+	var k domain.APIKey
+	err := scanAPIKey(s.db.QueryRowContext(ctx, `
+		UPDATE api_keys SET role = ?
+		WHERE id = ? AND revoked_at IS NULL
+		RETURNING `+apiKeyColumns,
+		role, id,
+	), &k)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("updating api key role: %w", err)
+	}
+	return &k, nil
+}
+
+// TouchAPIKey records that a key was used, at most once a minute.
+func (s *SQLiteStore) TouchAPIKey(ctx context.Context, id string) error {
+	now := time.Now()
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE api_keys SET last_used_at = ?
+		WHERE id = ? AND (last_used_at IS NULL OR last_used_at < ?)
+	`, now, id, now.Add(-time.Minute))
+	if err != nil {
+		return fmt.Errorf("touching api key: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) GetSubscriberStats(ctx context.Context, subscriberID string, since time.Time) (*SubscriberStats, error) {
+	st := SubscriberStats{SubscriberID: subscriberID, Since: since}
+
+	attempts, err := s.attemptSamples(ctx, `subscriber_id = ? AND created_at >= ?`, subscriberID, since)
+	if err != nil {
+		return nil, fmt.Errorf("querying subscriber delivery stats: %w", err)
+	}
+	addAttemptStats(&st, attempts)
+
+	err = s.db.QueryRowContext(ctx, `
+		SELECT
+			COUNT(*) FILTER (WHERE created_at >= ?),
+			COUNT(*) FILTER (WHERE resolved_at IS NULL)
+		FROM dead_letter_queue
+		WHERE subscriber_id = ?
+	`, since, subscriberID).Scan(&st.DeadLetters, &st.OpenDeadLetters)
+	if err != nil {
+		return nil, fmt.Errorf("querying subscriber dead letters: %w", err)
+	}
+
+	return &st, nil
+}
+
+// attemptSamples loads the fields of matching delivery attempts that
+// statistics are computed from, leaving out simulated attempts.
+func (s *SQLiteStore) attemptSamples(ctx context.Context, condition string, args ...interface{}) ([]domain.DeliveryAttempt, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT attempt_number, status, response_time_ms, failure_reason, created_at
+		FROM delivery_attempts
+		WHERE status <> 'simulated' AND `+condition+`
+		ORDER BY created_at
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attempts []domain.DeliveryAttempt
+	for rows.Next() {
+		var a domain.DeliveryAttempt
+		if err := rows.Scan(&a.AttemptNumber, &a.Status, &a.ResponseTimeMs, &a.FailureReason, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		attempts = append(attempts, a)
+	}
+	return attempts, rows.Err()
+}
+
+// GetDeliveryMetrics aggregates delivery statistics over every attempt;
+// SQLite deployments are small enough not to need the hourly rollup.
+func (s *SQLiteStore) GetDeliveryMetrics(ctx context.Context) (*DeliveryMetrics, error) {
+	var m DeliveryMetrics
+	var responseSum float64
+	var responseCount int
+	err := s.db.QueryRowContext(ctx, `
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE status = 'success'),
+			COUNT(*) FILTER (WHERE status = 'failed'),
+			COALESCE(SUM(response_time_ms) FILTER (WHERE response_time_ms > 0), 0),
+			COUNT(*) FILTER (WHERE response_time_ms > 0),
+			(SELECT COUNT(*) FROM dead_letter_queue WHERE resolved_at IS NULL),
+			(SELECT COUNT(*) FROM subscribers WHERE is_active = 1 AND deleted_at IS NULL),
+			(SELECT COUNT(*) FROM events)
+		FROM delivery_attempts
+		WHERE status <> 'simulated'
+	`).Scan(
+		&m.TotalDeliveries, &m.SuccessCount, &m.FailedCount, &responseSum, &responseCount,
+		&m.DeadLetterCount, &m.ActiveSubscribers, &m.TotalEvents,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying delivery metrics: %w", err)
+	}
+
+	if responseCount > 0 {
+		m.AvgResponseMs = responseSum / float64(responseCount)
+	}
+	if m.TotalDeliveries > 0 {
+		m.SuccessRate = float64(m.SuccessCount) / float64(m.TotalDeliveries) * 100
+	}
+	return &m, nil
+}
+
+// GetDeliveryTimeseries buckets delivery attempts created at or after since
+// into fixed-width intervals aligned to the Unix epoch. Buckets with no
+// attempts are omitted.
+func (s *SQLiteStore) GetDeliveryTimeseries(ctx context.Context, since time.Time, interval time.Duration) ([]TimeseriesBucket, error) {
+	attempts, err := s.attemptSamples(ctx, `created_at >= ?`, since)
+	if err != nil {
+		return nil, fmt.Errorf("querying delivery timeseries: %w", err)
+	}
+
+	var buckets []TimeseriesBucket
+	var latencies []float64
+	flush := func() {
+		b := &buckets[len(buckets)-1]
+		if b.Total > 0 {
+			b.SuccessRate = float64(b.SuccessCount) / float64(b.Total) * 100
+		}
+		sort.Float64s(latencies)
+		b.LatencyP50Ms = percentile(latencies, 0.50)
+		b.LatencyP95Ms = percentile(latencies, 0.95)
+		b.LatencyP99Ms = percentile(latencies, 0.99)
+		latencies = latencies[:0]
+	}
+
+	for _, a := range attempts {
+		start := time.Unix(0, a.CreatedAt.UnixNano()/int64(interval)*int64(interval)).UTC()
+		if len(buckets) == 0 || !buckets[len(buckets)-1].Start.Equal(start) {
+			if len(buckets) > 0 {
+				flush()
+			}
+			buckets = append(buckets, TimeseriesBucket{Start: start})
+		}
+		b := &buckets[len(buckets)-1]
+		b.Total++
+		switch a.Status {
+		case "success":
+			b.SuccessCount++
+		case "failed":
+			b.FailedCount++
+		}
+		if a.FailureReason != nil {
+			if b.FailureReasons == nil {
+				b.FailureReasons = map[string]int{}
+			}
+			b.FailureReasons[*a.FailureReason]++
+		}
+		if a.ResponseTimeMs != nil && *a.ResponseTimeMs > 0 {
+			latencies = append(latencies, float64(*a.ResponseTimeMs))
+		}
+	}
+	if len(buckets) > 0 {
+		flush()
+	}
+
+	return buckets, nil
+}
+
+// ClaimPendingOutbox claims up to limit outbox entries whose fan-out is due
+// and returns them with their events. The claim is a single UPDATE, pushing
+// the entries back by lease so a second relay skips them.
+func (s *SQLiteStore) ClaimPendingOutbox(ctx context.Context, limit int, lease time.Duration) ([]OutboxEntry, error) {
+	now := time.Now()
+	rows, err := s.db.QueryContext(ctx, `
+		UPDATE fanout_outbox
+		SET available_at = ?
+		WHERE event_id IN (
+			SELECT event_id FROM fanout_outbox
+			WHERE available_at <= ?
+			ORDER BY available_at
+			LIMIT ?
+		)
+		RETURNING event_id, attempts
+	`, now.Add(lease), now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("claiming fanout outbox: %w", err)
+	}
+	var claimed []OutboxEntry
+	for rows.Next() {
+		var entry OutboxEntry
+		if err := rows.Scan(&entry.Event.ID, &entry.Attempts); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scanning outbox entry: %w", err)
+		}
+		claimed = append(claimed, entry)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("claiming fanout outbox: %w", err)
+	}
+
+	entries := make([]OutboxEntry, 0, len(claimed))
+	for _, entry := range claimed {
+		event, err := s.GetEvent(ctx, entry.Event.ID)
+		if err != nil {
+			return nil, err
+		}
+		if event == nil {
+			continue
+		}
+		entries = append(entries, OutboxEntry{Event: *event, Attempts: entry.Attempts})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Event.CreatedAt.Before(entries[j].Event.CreatedAt) })
+	return entries, nil
+}
+
+func (s *SQLiteStore) CompleteOutboxEntry(ctx context.Context, eventID string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM fanout_outbox WHERE event_id = ?`, eventID)
+	if err != nil {
+		return fmt.Errorf("completing outbox entry: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) FailOutboxEntry(ctx context.Context, eventID string, errMsg string, retryAt time.Time) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE fanout_outbox
+		SET attempts = attempts + 1, last_error = ?, available_at = ?
+		WHERE event_id = ?
+	`, errMsg, retryAt, eventID)
+	if err != nil {
+		return fmt.Errorf("updating outbox entry: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) OutboxEntryPending(ctx context.Context, eventID string) (bool, error) {
+	var pending bool
+	err := s.db.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM fanout_outbox WHERE event_id = ?)
+	`, eventID).Scan(&pending)
+	if err != nil {
+		return false, fmt.Errorf("checking outbox entry: %w", err)
+	}
+	return pending, nil
+}
+
+func (s *SQLiteStore) SaveFanOutStatuses(ctx context.Context, statuses []domain.FanOutStatus) error {
+	if len(statuses) == 0 {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO fanout_status (event_id, subscriber_id, status, error, attempts, next_repair_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (event_id, subscriber_id) DO UPDATE SET
+			status = excluded.status,
+			error = excluded.error,
+			attempts = excluded.attempts,
+			next_repair_at = excluded.next_repair_at,
+			updated_at = excluded.updated_at
+	`)
+	if err != nil {
+		return fmt.Errorf("preparing fan-out status insert: %w", err)
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	for _, st := range statuses {
+		_, err := stmt.ExecContext(ctx, st.EventID, st.SubscriberID, st.Status, st.Error, st.Attempts, st.NextRepairAt, now)
+		if err != nil {
+			return fmt.Errorf("saving fan-out status: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) ListFanOutStatuses(ctx context.Context, eventID string) ([]domain.FanOutStatus, error) {
+	statuses, err := s.queryFanOutStatuses(ctx, `
+		SELECT `+fanOutStatusColumns+`
+		FROM fanout_status
+		WHERE event_id = ?
+		ORDER BY status = 'queued', subscriber_id
+	`, eventID)
+	if statuses == nil && err == nil {
+		statuses = []domain.FanOutStatus{}
+	}
+	return statuses, err
+}
+
+func (s *SQLiteStore) ListFanOutRepairs(ctx context.Context, limit int) ([]domain.FanOutStatus, error) {
+	return s.queryFanOutStatuses(ctx, `
+		SELECT `+fanOutStatusColumns+`
+		FROM fanout_status
+		WHERE status = 'failed' AND next_repair_at <= ?
+		ORDER BY next_repair_at
+		LIMIT ?
+	`, time.Now(), limit)
+}
+
+func (s *SQLiteStore) queryFanOutStatuses(ctx context.Context, query string, args ...interface{}) ([]domain.FanOutStatus, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying fan-out status: %w", err)
+	}
+	defer rows.Close()
+
+	var statuses []domain.FanOutStatus
+	for rows.Next() {
+		st, err := scanFanOutStatus(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning fan-out status: %w", err)
+		}
+		statuses = append(statuses, st)
+	}
+	return statuses, rows.Err()
+}
+
+// ListArchiveManifests returns no manifests: retention archiving needs
+// Postgres, so a SQLite database never has any.
+func (s *SQLiteStore) ListArchiveManifests(ctx context.Context, tableName string, limit int) ([]domain.ArchiveManifest, error) {
+	return []domain.ArchiveManifest{}, nil
+}
+
+func (s *SQLiteStore) GetArchiveManifest(ctx context.Context, id string) (*domain.ArchiveManifest, error) {
+	return nil, nil
+}
+
+func (s *SQLiteStore) SavePendingJob(ctx context.Context, job PendingQueueJob) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO pending_jobs (event_id, subscriber_id, job, ready_at, last_error, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, job.EventID, job.SubscriberID, string(job.Job), job.ReadyAt, nullString(job.LastError), time.Now())
+	if err != nil {
+		return fmt.Errorf("saving pending job: %w", err)
+	}
+	return nil
+}
+
+// DrainPendingJobs passes up to limit saved jobs to enqueue, oldest first,
+// and deletes those it accepts, all in one write transaction.
+func (s *SQLiteStore) DrainPendingJobs(ctx context.Context, limit int, enqueue func(PendingQueueJob) error) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, event_id, subscriber_id, job, ready_at, COALESCE(last_error, '')
+		FROM pending_jobs
+		ORDER BY id
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return 0, fmt.Errorf("querying pending jobs: %w", err)
+	}
+	var jobs []PendingQueueJob
+	for rows.Next() {
+		var j PendingQueueJob
+		var job string
+		if err := rows.Scan(&j.ID, &j.EventID, &j.SubscriberID, &job, &j.ReadyAt, &j.LastError); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scanning pending job: %w", err)
+		}
+		j.Job = []byte(job)
+		jobs = append(jobs, j)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("querying pending jobs: %w", err)
+	}
+
+	var drained int
+	var enqueueErr error
+	for _, j := range jobs {
+		if enqueueErr = enqueue(j); enqueueErr != nil {
+			break
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM pending_jobs WHERE id = ?`, j.ID); err != nil {
+			return 0, fmt.Errorf("deleting pending job: %w", err)
+		}
+		drained++
+	}
+
+	if drained > 0 {
+		if err := tx.Commit(); err != nil {
+			return 0, fmt.Errorf("committing pending jobs: %w", err)
+		}
+	}
+	return drained, enqueueErr
+}
+
+func (s *SQLiteStore) CountPendingJobs(ctx context.Context) (int64, error) {
+	var n int64
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM pending_jobs`).Scan(&n); err != nil {
+		return 0, fmt.Errorf("counting pending jobs: %w", err)
+	}
+	return n, nil
+}
+
+// placeholders returns n comma-separated bind parameters.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
@@ -0,0 +1,624 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/migrations"
+)
+
+func newTestSQLite(t testing.TB) *SQLiteStore {
+	t.Helper()
+	ctx := context.Background()
+	s, err := NewSQLite(ctx, "sqlite::memory:")
+	if err != nil {
+		t.Fatalf("NewSQLite: %v", err)
+	}
+	t.Cleanup(s.Close)
+	if _, err := s.RunMigrations(ctx, migrations.FS); err != nil {
+		t.Fatalf("RunMigrations: %v", err)
+	}
+	if pending, err := s.PendingMigrations(ctx, migrations.FS); err != nil || len(pending) != 0 {
+		t.Fatalf("PendingMigrations = %v, %v, want none", pending, err)
+	}
+	// A second run must be a no-op.
+	if applied, err := s.RunMigrations(ctx, migrations.FS); err != nil || len(applied) != 0 {
+		t.Fatalf("RunMigrations again = %v, %v, want none applied", applied, err)
+	}
+	return s
+}
+
+func TestSQLite_RollbackMigrations(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSQLite(t)
+
+	states, err := s.MigrationStatus(ctx, migrations.FS)
+	if err != nil {
+		t.Fatalf("MigrationStatus: %v", err)
+	}
+	for _, state := range states {
+		if state.AppliedAt == nil {
+			t.Fatalf("migration %s not applied", state.Version)
+		}
+	}
+	latest := states[len(states)-1].Version
+
+	reverted, err := s.RollbackMigrations(ctx, migrations.FS, 1)
+	if err != nil || !slices.Equal(reverted, []string{latest}) {
+		t.Fatalf("RollbackMigrations(1) = %v, %v, want [%s]", reverted, err, latest)
+	}
+	if pending, _ := s.PendingMigrations(ctx, migrations.FS); !slices.Equal(pending, []string{latest}) {
+		t.Errorf("pending after rollback = %v, want [%s]", pending, latest)
+	}
+
+	// Every down migration must undo its up migration cleanly.
+	reverted, err = s.RollbackMigrations(ctx, migrations.FS, len(states))
+	if err != nil || len(reverted) != len(states)-1 {
+		t.Fatalf("RollbackMigrations(all) = %d reverted, %v, want %d", len(reverted), err, len(states)-1)
+	}
+	if applied, err := s.RunMigrations(ctx, migrations.FS); err != nil || len(applied) != len(states) {
+		t.Fatalf("RunMigrations after rollback = %d applied, %v, want %d", len(applied), err, len(states))
+	}
+}
+
+func TestSQLite_SubscribersAndMatching(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSQLite(t)
+
+	sub, err := s.CreateSubscriber(ctx, domain.CreateSubscriberRequest{
+		Name:        "orders",
+		EndpointURL: "https://example.com/hook",
+		EventTypes:  []string{"order.*", "*.created", "invoice.**"},
+	})
+	if err != nil {
+		t.Fatalf("CreateSubscriber: %v", err)
+	}
+	if sub.SecretKey == "" || !sub.IsActive || sub.RateLimitPerSecond != 10 || sub.IsSystem {
+		t.Fatalf("created subscriber = %+v", sub)
+	}
+
+	system, err := s.CreateSubscriber(ctx, domain.CreateSubscriberRequest{
+		Name: "ops", EndpointURL: "https://example.com/ops", EventTypes: []string{"delivery.dead_lettered"}, IsSystem: true,
+	})
+	if err != nil {
+		t.Fatalf("CreateSubscriber(system): %v", err)
+	}
+	if got, _ := s.GetSubscriber(ctx, system.ID); got == nil || !got.IsSystem {
+		t.Errorf("system subscriber = %+v, want is_system", got)
+	}
+
+	for eventType, want := range map[string]int{
+		"order.created": 1, "user.created": 1, "user.deleted": 0, "order.item.added": 0, "invoice.line.paid": 1,
+	} {
+		matches, err := s.FindMatchingSubscribers(ctx, eventType)
+		if err != nil {
+			t.Fatalf("FindMatchingSubscribers(%s): %v", eventType, err)
+		}
+		if len(matches) != want {
+			t.Errorf("FindMatchingSubscribers(%s) = %d subscribers, want %d", eventType, len(matches), want)
+		}
+	}
+
+	inactive, debug := false, true
+	batchMax, batchWindow := 50, 5
+	proxy := "http://egress.internal:3128"
+	format, jitter := domain.SignatureStripe, domain.JitterDecorrelated
+	retryDuration := 86400
+	successPath := `$.status == "ok"`
+	redirects := domain.RedirectFollowResign
+	updated, err := s.UpdateSubscriber(ctx, sub.ID, domain.UpdateSubscriberRequest{
+		IsActive: &inactive, BatchMaxEvents: &batchMax, BatchWindowSeconds: &batchWindow, ProxyURL: &proxy,
+		DebugLogging: &debug, SignatureFormat: &format, EventVersions: map[string]int{"order.created": 1}, Sandbox: &debug,
+		AutoReplayDeadLetters: &debug, RetryJitter: &jitter, MaxRetryDurationSeconds: &retryDuration, RetryForDuration: &debug,
+		SuccessJSONPath: &successPath, RedirectPolicy: &redirects,
+	})
+	if err != nil {
+		t.Fatalf("UpdateSubscriber: %v", err)
+	}
+	if updated.IsActive || updated.SecretKey != "" {
+		t.Errorf("updated subscriber = %+v, want inactive without secret", updated)
+	}
+	if updated.BatchMaxEvents != 50 || updated.BatchWindowSeconds != 5 {
+		t.Errorf("updated batching = %d/%ds, want 50/5s", updated.BatchMaxEvents, updated.BatchWindowSeconds)
+	}
+	if updated.ProxyURL != proxy || !updated.DebugLogging {
+		t.Errorf("updated proxy = %q, debug logging = %v", updated.ProxyURL, updated.DebugLogging)
+	}
+	if sub.SignatureFormat != domain.SignatureStandard || updated.SignatureFormat != format {
+		t.Errorf("signature format = %q, then %q; want standard, then %q", sub.SignatureFormat, updated.SignatureFormat, format)
+	}
+	if sub.Sandbox || !updated.Sandbox {
+		t.Errorf("sandbox = %v, then %v; want false, then true", sub.Sandbox, updated.Sandbox)
+	}
+	if sub.AutoReplayDeadLetters || !updated.AutoReplayDeadLetters {
+		t.Errorf("auto-replay = %v, then %v; want false, then true", sub.AutoReplayDeadLetters, updated.AutoReplayDeadLetters)
+	}
+	if sub.RetryJitter != "" || updated.RetryJitter != jitter {
+		t.Errorf("retry jitter = %q, then %q; want the default, then %q", sub.RetryJitter, updated.RetryJitter, jitter)
+	}
+	if updated.MaxRetryDurationSeconds != retryDuration || !updated.RetryForDuration {
+		t.Errorf("retry duration = %ds, for the duration = %v; want %ds, true", updated.MaxRetryDurationSeconds, updated.RetryForDuration, retryDuration)
+	}
+	if sub.SuccessJSONPath != "" || updated.SuccessJSONPath != successPath {
+		t.Errorf("success JSONPath = %q, then %q; want none, then %q", sub.SuccessJSONPath, updated.SuccessJSONPath, successPath)
+	}
+	if sub.RedirectPolicy != "" || updated.RedirectPolicy != redirects {
+		t.Errorf("redirect policy = %q, then %q; want the default, then %q", sub.RedirectPolicy, updated.RedirectPolicy, redirects)
+	}
+	if len(sub.EventVersions) != 0 || updated.EventVersions["order.created"] != 1 {
+		t.Errorf("event versions = %v, then %v; want none, then order.created pinned to 1", sub.EventVersions, updated.EventVersions)
+	}
+	if sub.Version != 1 || updated.Version != 2 {
+		t.Errorf("versions = %d then %d, want 1 then 2", sub.Version, updated.Version)
+	}
+	stale := sub.Version
+	if _, err := s.UpdateSubscriber(ctx, sub.ID, domain.UpdateSubscriberRequest{DebugLogging: &inactive, Version: &stale}); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("UpdateSubscriber at a stale version: err = %v, want ErrVersionConflict", err)
+	}
+	if got, err := s.UpdateSubscriber(ctx, sub.ID, domain.UpdateSubscriberRequest{DebugLogging: &inactive, Version: &updated.Version}); err != nil || got.Version != 3 || got.DebugLogging {
+		t.Errorf("UpdateSubscriber at the current version = %+v, %v", got, err)
+	}
+	if matches, _ := s.FindMatchingSubscribers(ctx, "order.created"); len(matches) != 0 {
+		t.Errorf("inactive subscriber matched %d times", len(matches))
+	}
+
+	deleted, err := s.DeleteSubscriber(ctx, system.ID)
+	if err != nil || deleted == nil || !deleted.Deleted() {
+		t.Fatalf("DeleteSubscriber = %+v, %v", deleted, err)
+	}
+	if matches, _ := s.FindMatchingSubscribers(ctx, "delivery.dead_lettered"); len(matches) != 0 {
+		t.Errorf("deleted subscriber matched %d times", len(matches))
+	}
+	if listed, _ := s.ListSubscribers(ctx); len(listed) != 1 || listed[0].ID != sub.ID {
+		t.Errorf("ListSubscribers = %+v, want the deleted subscriber left out", listed)
+	}
+	if restored, err := s.RestoreSubscriber(ctx, system.ID); err != nil || restored == nil || restored.Deleted() {
+		t.Errorf("RestoreSubscriber = %+v, %v", restored, err)
+	}
+
+	if got, err := s.GetSubscriber(ctx, "missing"); got != nil || err != nil {
+		t.Errorf("GetSubscriber(missing) = %v, %v, want nil, nil", got, err)
+	}
+	if got, err := s.UpdateSubscriber(ctx, "missing", domain.UpdateSubscriberRequest{IsActive: &inactive}); got != nil || err != nil {
+		t.Errorf("UpdateSubscriber(missing) = %v, %v, want nil, nil", got, err)
+	}
+	if got, err := s.UpdateSubscriber(ctx, "missing", domain.UpdateSubscriberRequest{IsActive: &inactive, Version: &stale}); got != nil || err != nil {
+		t.Errorf("UpdateSubscriber(missing) at a version = %v, %v, want nil, nil", got, err)
+	}
+}
+
+func TestSQLite_EventSubscriberIDs(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSQLite(t)
+
+	targeted, err := s.CreateEvent(ctx, "order.created", 2, []byte(`{}`), nil, "", []string{"sub-1", "sub-2"})
+	if err != nil {
+		t.Fatalf("CreateEvent: %v", err)
+	}
+	s.CreateEvent(ctx, "order.created", 1, []byte(`{}`), nil, "", nil)
+
+	got, err := s.GetEvent(ctx, targeted.ID)
+	if err != nil || got == nil || !slices.Equal(got.SubscriberIDs, []string{"sub-1", "sub-2"}) || got.Version != 2 {
+		t.Fatalf("GetEvent = %+v, %v", got, err)
+	}
+	events, _ := s.ListEvents(ctx, "", 0)
+	for _, e := range events {
+		if e.ID != targeted.ID && e.SubscriberIDs != nil {
+			t.Errorf("expected an untargeted event to have no subscriber ids, got %v", e.SubscriberIDs)
+		}
+	}
+}
+
+func TestSQLite_EventPayloadRef(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSQLite(t)
+
+	ref := &domain.PayloadRef{Key: "archive/payloads/abc.json", Size: 5 << 20}
+	offloaded, err := s.CreateEvent(ctx, "file.uploaded", 1, []byte(`null`), ref, "", nil)
+	if err != nil {
+		t.Fatalf("CreateEvent: %v", err)
+	}
+	inline, _ := s.CreateEvent(ctx, "file.uploaded", 1, []byte(`{}`), nil, "", nil)
+
+	got, err := s.GetEvent(ctx, offloaded.ID)
+	if err != nil || got == nil || got.PayloadRef == nil || *got.PayloadRef != *ref {
+		t.Fatalf("GetEvent = %+v, %v, want the payload ref", got, err)
+	}
+	events, _ := s.ListEvents(ctx, "", 0)
+	for _, e := range events {
+		if e.ID == inline.ID && e.PayloadRef != nil {
+			t.Errorf("expected an inline payload to have no ref, got %+v", e.PayloadRef)
+		}
+		if e.ID == offloaded.ID && (e.PayloadRef == nil || *e.PayloadRef != *ref) {
+			t.Errorf("ListEvents lost the payload ref, got %+v", e.PayloadRef)
+		}
+	}
+}
+
+func TestSQLite_SubscriberEndpointType(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSQLite(t)
+
+	creds := &domain.EndpointCredentials{Username: "publisher", Password: "hunter2"}
+	sub, err := s.CreateSubscriber(ctx, domain.CreateSubscriberRequest{
+		Name: "rabbit", EndpointURL: "amqps://mq.example.com/?exchange=webhooks", EventTypes: []string{"order.*"},
+		EndpointType: domain.EndpointAMQP, EndpointCredentials: creds,
+	})
+	if err != nil {
+		t.Fatalf("CreateSubscriber: %v", err)
+	}
+	got, _ := s.GetSubscriber(ctx, sub.ID)
+	if got.EndpointType != domain.EndpointAMQP || got.EndpointCredentials == nil || *got.EndpointCredentials != *creds {
+		t.Fatalf("GetSubscriber = %+v, want the amqp endpoint and its credentials", got)
+	}
+	listed, _ := s.ListSubscribers(ctx)
+	if len(listed) != 1 || listed[0].EndpointType != domain.EndpointAMQP || listed[0].EndpointCredentials != nil {
+		t.Errorf("ListSubscribers = %+v, want the credentials left out", listed)
+	}
+	matched, _ := s.FindMatchingSubscribers(ctx, "order.created")
+	if len(matched) != 1 || matched[0].EndpointCredentials == nil {
+		t.Errorf("FindMatchingSubscribers = %+v, want the credentials deliveries use", matched)
+	}
+
+	httpType := domain.EndpointHTTP
+	endpoint := "https://hooks.example.com"
+	if _, err := s.UpdateSubscriber(ctx, sub.ID, domain.UpdateSubscriberRequest{
+		EndpointType: &httpType, EndpointURL: &endpoint, EndpointCredentials: &domain.EndpointCredentials{},
+	}); err != nil {
+		t.Fatalf("UpdateSubscriber: %v", err)
+	}
+	got, _ = s.GetSubscriber(ctx, sub.ID)
+	if got.EndpointType != domain.EndpointHTTP || got.EndpointCredentials != nil {
+		t.Errorf("after update = %+v, want an http endpoint without credentials", got)
+	}
+
+	plain, _ := s.CreateSubscriber(ctx, domain.CreateSubscriberRequest{Name: "plain", EndpointURL: endpoint, EventTypes: []string{"order.*"}})
+	if plain.EndpointType != domain.EndpointHTTP {
+		t.Errorf("endpoint type defaults to %q, want http", plain.EndpointType)
+	}
+}
+
+func TestSQLite_SearchEvents(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSQLite(t)
+
+	paid, _ := s.CreateEvent(ctx, "order.paid", 1, []byte(`{"order_id":"abc-123","total":42,"customer":{"id":7}}`), nil, "", nil)
+	created, _ := s.CreateEvent(ctx, "order.created", 1, []byte(`{"order_id":"abc-123","total":"42"}`), nil, "", nil)
+	s.CreateEvent(ctx, "order.created", 1, []byte(`{"order_id":"abc-124","a.b":"dotted"}`), nil, "", nil)
+
+	search := func(query, eventType string) []string {
+		t.Helper()
+		terms, err := domain.ParsePayloadQuery(query)
+		if err != nil {
+			t.Fatalf("ParsePayloadQuery(%q): %v", query, err)
+		}
+		events, err := s.SearchEvents(ctx, EventSearchFilter{Terms: terms, EventType: eventType, Limit: 10})
+		if err != nil {
+			t.Fatalf("SearchEvents(%q): %v", query, err)
+		}
+		ids := []string{}
+		for _, e := range events {
+			ids = append(ids, e.ID)
+		}
+		return ids
+	}
+
+	if got := search("order_id:abc-123", ""); !slices.Equal(got, []string{created.ID, paid.ID}) {
+		t.Errorf("order_id:abc-123 found %v, want both orders newest first", got)
+	}
+	if got := search("order_id:abc-123", "order.paid"); !slices.Equal(got, []string{paid.ID}) {
+		t.Errorf("order_id:abc-123 of order.paid found %v", got)
+	}
+	if got := search("total:42 customer.id:7", ""); !slices.Equal(got, []string{paid.ID}) {
+		t.Errorf("total:42 customer.id:7 found %v", got)
+	}
+	if got := search("order_id:nope", ""); len(got) != 0 {
+		t.Errorf("order_id:nope found %v", got)
+	}
+}
+
+func TestSQLite_MetersUsage(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSQLite(t)
+
+	billing, _ := s.CreateSubscriber(ctx, domain.CreateSubscriberRequest{Name: "billing", EndpointURL: "https://billing.example.com/hook"})
+	search, _ := s.CreateSubscriber(ctx, domain.CreateSubscriberRequest{Name: "search", EndpointURL: "https://search.example.com/hook"})
+	event, _ := s.CreateEvent(ctx, "order.paid", 1, []byte(`{}`), nil, "", nil)
+
+	attempt := func(sub *domain.Subscriber, number int, status string, bytes int) DeliveryAttemptRecord {
+		return DeliveryAttemptRecord{EventID: event.ID, SubscriberID: sub.ID, AttemptNumber: number, Status: status, PayloadBytes: bytes}
+	}
+	if err := s.InsertDeliveryAttempts(ctx, []DeliveryAttemptRecord{
+		attempt(search, 1, "failed", 100),
+		attempt(search, 2, "success", 100),
+		attempt(billing, 1, "success", 40),
+		attempt(billing, 1, domain.AttemptSimulated, 40),
+	}); err != nil {
+		t.Fatalf("InsertDeliveryAttempts: %v", err)
+	}
+	if err := s.RecordDeliveryAttempt(ctx, attempt(billing, 1, "success", 60)); err != nil {
+		t.Fatalf("RecordDeliveryAttempt: %v", err)
+	}
+
+	today := time.Now().UTC()
+	days, err := s.ListUsage(ctx, UsageFilter{From: today, To: today})
+	if err != nil {
+		t.Fatalf("ListUsage: %v", err)
+	}
+	want := []domain.Usage{
+		{SubscriberID: billing.ID, SubscriberName: "billing", Deliveries: 2, Successful: 2, PayloadBytes: 100},
+		{SubscriberID: search.ID, SubscriberName: "search", Deliveries: 2, Successful: 1, Retries: 1, PayloadBytes: 200},
+	}
+	if len(days) != len(want) {
+		t.Fatalf("ListUsage = %+v, want a day per subscriber", days)
+	}
+	for i, d := range days {
+		if d.Usage != want[i] || !d.Day.Equal(today.Truncate(24*time.Hour)) {
+			t.Errorf("day %d = %+v, want %+v today", i, d, want[i])
+		}
+	}
+
+	days, _ = s.ListUsage(ctx, UsageFilter{SubscriberID: search.ID, From: today, To: today})
+	if len(days) != 1 || days[0].SubscriberID != search.ID {
+		t.Errorf("usage of search = %+v", days)
+	}
+	days, _ = s.ListUsage(ctx, UsageFilter{From: today.AddDate(0, 0, -7), To: today.AddDate(0, 0, -1)})
+	if len(days) != 0 {
+		t.Errorf("usage of last week = %+v, want none", days)
+	}
+}
+
+func TestSQLite_EventsAttemptsAndDeadLetters(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSQLite(t)
+
+	sub, _ := s.CreateSubscriber(ctx, domain.CreateSubscriberRequest{
+		Name: "orders", EndpointURL: "https://example.com/hook", EventTypes: []string{"*"},
+	})
+	event, err := s.CreateEvent(ctx, "order.created", 1, []byte(`{"id":1}`), nil, "test", nil)
+	if err != nil {
+		t.Fatalf("CreateEvent: %v", err)
+	}
+	if pending, _ := s.OutboxEntryPending(ctx, event.ID); !pending {
+		t.Error("new event has no outbox entry")
+	}
+	got, err := s.GetEvent(ctx, event.ID)
+	if err != nil || got == nil || string(got.Payload) != `{"id":1}` {
+		t.Fatalf("GetEvent = %+v, %v", got, err)
+	}
+
+	status, ms := 500, 40
+	err = s.InsertDeliveryAttempts(ctx, []DeliveryAttemptRecord{
+		{EventID: event.ID, SubscriberID: sub.ID, AttemptNumber: 1, Status: "failed", HTTPStatusCode: &status, ResponseTimeMs: ms,
+			ResponseHeaders: map[string]string{"Retry-After": "30"}, FailureReason: domain.FailureHTTP5xx,
+			Timings: &domain.DeliveryTimings{TTFBMs: &ms}, FinalURL: "https://new.example.com/hook"},
+		{EventID: event.ID, SubscriberID: sub.ID, AttemptNumber: 2, Status: "success", ResponseTimeMs: ms},
+	})
+	if err != nil {
+		t.Fatalf("InsertDeliveryAttempts: %v", err)
+	}
+	attempts, err := s.ListDeliveryAttempts(ctx, DeliveryAttemptFilter{ResponseHeaders: map[string]string{"Retry-After": "30"}})
+	if err != nil {
+		t.Fatalf("ListDeliveryAttempts: %v", err)
+	}
+	if len(attempts) != 1 || attempts[0].ResponseHeaders["Retry-After"] != "30" {
+		t.Fatalf("attempts filtered by header = %+v", attempts)
+	}
+	if timings := attempts[0].Timings; timings == nil || timings.TTFBMs == nil || *timings.TTFBMs != ms {
+		t.Errorf("timings = %+v, want ttfb_ms %d", timings, ms)
+	}
+	if attempts[0].FinalURL == nil || *attempts[0].FinalURL != "https://new.example.com/hook" {
+		t.Errorf("final URL = %v, want the redirect target", attempts[0].FinalURL)
+	}
+	attempts, _ = s.ListDeliveryAttempts(ctx, DeliveryAttemptFilter{FailureReason: string(domain.FailureHTTP5xx)})
+	if len(attempts) != 1 || attempts[0].FailureReason == nil || *attempts[0].FailureReason != "http_5xx" {
+		t.Fatalf("attempts filtered by failure reason = %+v", attempts)
+	}
+
+	if err := s.InsertDeadLetter(ctx, DeadLetterRecord{EventID: event.ID, SubscriberID: sub.ID, TotalAttempts: 2, LastError: "boom"}); err != nil {
+		t.Fatalf("InsertDeadLetter: %v", err)
+	}
+	first, _ := s.ListDeliveryAttempts(ctx, DeliveryAttemptFilter{SubscriberID: sub.ID, Limit: 1})
+	if len(first) != 1 {
+		t.Fatalf("first page = %+v", first)
+	}
+	after := &PageCursor{CreatedAt: first[0].CreatedAt, ID: first[0].ID}
+	second, _ := s.ListDeliveryAttempts(ctx, DeliveryAttemptFilter{SubscriberID: sub.ID, After: after})
+	if len(second) != 1 || second[0].ID == first[0].ID {
+		t.Fatalf("page after %s = %+v", first[0].ID, second)
+	}
+	if rest, _ := s.ListDeliveryAttempts(ctx, DeliveryAttemptFilter{Until: first[0].CreatedAt.Add(-time.Hour)}); len(rest) != 0 {
+		t.Fatalf("attempts before an hour earlier = %+v", rest)
+	}
+
+	resolved := false
+	letters, _ := s.ListDeadLetters(ctx, DeadLetterFilter{SubscriberID: sub.ID, Resolved: &resolved, Limit: 10})
+	if len(letters) != 1 {
+		t.Fatalf("ListDeadLetters = %d entries, want 1", len(letters))
+	}
+	dl, _ := s.GetDeadLetter(ctx, letters[0].ID)
+	if dl == nil || dl.EventType == nil || *dl.EventType != "order.created" || string(dl.Payload) != `{"id":1}` {
+		t.Fatalf("dead letter snapshot = %+v", dl)
+	}
+
+	stats, err := s.GetSubscriberStats(ctx, sub.ID, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("GetSubscriberStats: %v", err)
+	}
+	if stats.TotalAttempts != 2 || stats.Retries != 1 || stats.OpenDeadLetters != 1 || stats.LatencyP50Ms != 40 ||
+		stats.FailureReasons["http_5xx"] != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	metrics, err := s.GetDeliveryMetrics(ctx)
+	if err != nil {
+		t.Fatalf("GetDeliveryMetrics: %v", err)
+	}
+	if metrics.TotalDeliveries != 2 || metrics.SuccessRate != 50 || metrics.DeadLetterCount != 1 {
+		t.Errorf("metrics = %+v", metrics)
+	}
+	buckets, err := s.GetDeliveryTimeseries(ctx, time.Now().Add(-time.Hour), time.Minute)
+	if err != nil || len(buckets) != 1 || buckets[0].Total != 2 {
+		t.Errorf("timeseries = %+v, %v", buckets, err)
+	}
+
+	if _, err := s.DescribeEventType(ctx, "order.created", domain.DescribeEventTypeRequest{Description: "An order was placed", CurrentVersion: 2}); err != nil {
+		t.Fatalf("DescribeEventType: %v", err)
+	}
+	types, err := s.ListEventTypes(ctx)
+	if err != nil {
+		t.Fatalf("ListEventTypes: %v", err)
+	}
+	if len(types) != 1 || !types[0].Documented || types[0].EventCount != 1 || types[0].LastSeenAt == nil || string(types[0].ExamplePayload) != `{"id":1}` || types[0].CurrentVersion != 2 {
+		t.Errorf("event types = %+v", types)
+	}
+
+	n, err := s.ExpireDeadLetters(ctx, time.Now().Add(time.Minute), 100)
+	if err != nil || n != 1 {
+		t.Fatalf("ExpireDeadLetters = %d, %v, want 1", n, err)
+	}
+	entries, _ := s.ListAuditEntries(ctx, domain.AuditFilter{Action: domain.AuditDeadLetterExpire})
+	if len(entries) != 1 || entries[0].EntityID != dl.ID {
+		t.Errorf("expiry audit entries = %+v", entries)
+	}
+	if err := s.ResolveDeadLetter(ctx, dl.ID, "ops"); err == nil {
+		t.Error("resolving an expired dead letter succeeded")
+	}
+
+	for i := 0; i < 2; i++ {
+		s.InsertDeadLetter(ctx, DeadLetterRecord{EventID: dl.EventID, SubscriberID: sub.ID, TotalAttempts: 5})
+	}
+	oldest, _ := s.ListDeadLetters(ctx, DeadLetterFilter{SubscriberID: sub.ID, Resolved: &resolved, OldestFirst: true})
+	if len(oldest) != 2 || oldest[0].CreatedAt.After(oldest[1].CreatedAt) {
+		t.Fatalf("oldest first = %+v", oldest)
+	}
+	next, _ := s.ListDeadLetters(ctx, DeadLetterFilter{
+		SubscriberID: sub.ID, Resolved: &resolved, OldestFirst: true,
+		After: &PageCursor{CreatedAt: oldest[0].CreatedAt, ID: oldest[0].ID},
+	})
+	if len(next) != 1 || next[0].ID != oldest[1].ID {
+		t.Errorf("oldest first after %s = %+v, want %s", oldest[0].ID, next, oldest[1].ID)
+	}
+
+	groups, err := s.SummarizeDeadLetters(ctx, sub.ID)
+	if err != nil || len(groups) != 1 || groups[0].Count != 2 || groups[0].SubscriberName != "orders" ||
+		groups[0].FailureReason != nil || !groups[0].FirstAt.Equal(oldest[0].CreatedAt) || !groups[0].LastAt.Equal(oldest[1].CreatedAt) {
+		t.Errorf("dead letter summary = %+v, %v", groups, err)
+	}
+}
+
+func TestSQLite_FanOutStatus(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSQLite(t)
+
+	a, _ := s.CreateSubscriber(ctx, domain.CreateSubscriberRequest{Name: "a", EndpointURL: "https://a.example.com", EventTypes: []string{"*"}})
+	b, _ := s.CreateSubscriber(ctx, domain.CreateSubscriberRequest{Name: "b", EndpointURL: "https://b.example.com", EventTypes: []string{"*"}})
+	event, _ := s.CreateEvent(ctx, "order.created", 1, []byte(`{}`), nil, "", nil)
+
+	errMsg := "connection reset"
+	due, later := time.Now().Add(-time.Second), time.Now().Add(time.Hour)
+	err := s.SaveFanOutStatuses(ctx, []domain.FanOutStatus{
+		{EventID: event.ID, SubscriberID: a.ID, Status: domain.FanOutQueued, Attempts: 1},
+		{EventID: event.ID, SubscriberID: b.ID, Status: domain.FanOutFailed, Error: &errMsg, Attempts: 1, NextRepairAt: &due},
+	})
+	if err != nil {
+		t.Fatalf("SaveFanOutStatuses: %v", err)
+	}
+
+	statuses, err := s.ListFanOutStatuses(ctx, event.ID)
+	if err != nil || len(statuses) != 2 {
+		t.Fatalf("ListFanOutStatuses = %+v, %v", statuses, err)
+	}
+	if statuses[0].SubscriberID != b.ID || *statuses[0].Error != errMsg || statuses[1].Error != nil {
+		t.Errorf("expected the failed status first, got %+v", statuses)
+	}
+
+	repairs, err := s.ListFanOutRepairs(ctx, 10)
+	if err != nil || len(repairs) != 1 || repairs[0].SubscriberID != b.ID {
+		t.Fatalf("ListFanOutRepairs = %+v, %v", repairs, err)
+	}
+
+	// Saving again replaces the row
+	repairs[0].Attempts = 2
+	repairs[0].NextRepairAt = &later
+	if err := s.SaveFanOutStatuses(ctx, repairs); err != nil {
+		t.Fatalf("SaveFanOutStatuses: %v", err)
+	}
+	if repairs, _ := s.ListFanOutRepairs(ctx, 10); len(repairs) != 0 {
+		t.Errorf("expected the rescheduled repair not to be due, got %+v", repairs)
+	}
+}
+
+func BenchmarkSQLite_InsertDeliveryAttempts(b *testing.B) {
+	const batch = 100
+	ctx := context.Background()
+	s := newTestSQLite(b)
+	sub, err := s.CreateSubscriber(ctx, domain.CreateSubscriberRequest{
+		Name: "bench", EndpointURL: "http://example.com/hook", EventTypes: []string{"order.created"},
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	event, err := s.CreateEvent(ctx, "order.created", 1, []byte(`{"order_id":42}`), nil, "", nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	status := 200
+	recs := make([]DeliveryAttemptRecord, batch)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := range recs {
+			recs[j] = DeliveryAttemptRecord{
+				EventID:         event.ID,
+				SubscriberID:    sub.ID,
+				AttemptNumber:   1,
+				Status:          "success",
+				HTTPStatusCode:  &status,
+				ResponseHeaders: map[string]string{"Content-Type": "application/json"},
+				ResponseTimeMs:  12,
+			}
+		}
+		if err := s.InsertDeliveryAttempts(ctx, recs); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(batch*b.N)/b.Elapsed().Seconds(), "attempts/s")
+}
+
+func TestSQLite_ClaimPendingOutbox(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSQLite(t)
+
+	ref := &domain.PayloadRef{Key: "payloads/abc", Size: 4 << 20}
+	event, err := s.CreateEvent(ctx, "order.created", 2, nil, ref, "test", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := s.ClaimPendingOutbox(ctx, 10, time.Minute); len(entries) != 0 {
+		t.Fatalf("claimed %d entries during the grace period, want none", len(entries))
+	}
+
+	s.FailOutboxEntry(ctx, event.ID, "queue unavailable", time.Now().Add(-time.Second))
+	entries, err := s.ClaimPendingOutbox(ctx, 10, time.Minute)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("ClaimPendingOutbox = %+v, %v, want the due entry", entries, err)
+	}
+	got := entries[0]
+	if got.Event.ID != event.ID || got.Event.Version != 2 || got.Event.PayloadRef == nil || *got.Event.PayloadRef != *ref || got.Attempts != 1 {
+		t.Errorf("claimed %+v", got)
+	}
+
+	// Another relay skips it until the lease runs out
+	if entries, _ := s.ClaimPendingOutbox(ctx, 10, time.Minute); len(entries) != 0 {
+		t.Errorf("claimed entry was claimed again: %+v", entries)
+	}
+	if pending, _ := s.OutboxEntryPending(ctx, event.ID); !pending {
+		t.Error("claimed entry is no longer pending")
+	}
+}
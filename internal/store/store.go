@@ -0,0 +1,339 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+)
+
+// The interfaces below are what the API handlers, gRPC server and delivery
+// workers need from a backend. PostgresStore is the production implementation,
+// SQLiteStore serves single-node deployments, and MemoryStore is an
+// in-process one for tests. Jobs that rely on Postgres specifics (the
+// archiver and metrics rollup) still take *PostgresStore directly.
+
+// ErrVersionConflict is returned by UpdateSubscriber when the request's
+// version no longer matches the subscriber's.
+var ErrVersionConflict = errors.New("subscriber was changed since the given version")
+
+// checkVersion returns sub, or ErrVersionConflict if version is set and sub
+// is at another one.
+func checkVersion(sub *domain.Subscriber, version *int) (*domain.Subscriber, error) {
+	if sub != nil && version != nil && sub.Version != *version {
+		return nil, ErrVersionConflict
+	}
+	return sub, nil
+}
+
+// SubscriberStore manages subscribers and their event type subscriptions.
+// Lookups return nil, nil when the subscriber does not exist. Deleted
+// subscribers are still found by ID, but are left out of ListSubscribers and
+// FindMatchingSubscribers.
+type SubscriberStore interface {
+	CreateSubscriber(ctx context.Context, req domain.CreateSubscriberRequest) (*domain.Subscriber, error)
+	GetSubscriber(ctx context.Context, id string) (*domain.Subscriber, error)
+	ListSubscribers(ctx context.Context) ([]domain.Subscriber, error)
+	// UpdateSubscriber applies the changes in req and bumps the version.
+	// With req.Version set, it returns ErrVersionConflict instead if the
+	// subscriber is at another version.
+	UpdateSubscriber(ctx context.Context, id string, req domain.UpdateSubscriberRequest) (*domain.Subscriber, error)
+	// DeleteSubscriber soft-deletes a subscriber by setting its deleted_at,
+	// and RestoreSubscriber clears it again.
+	DeleteSubscriber(ctx context.Context, id string) (*domain.Subscriber, error)
+	RestoreSubscriber(ctx context.Context, id string) (*domain.Subscriber, error)
+	GetSubscriberSubscriptions(ctx context.Context, subscriberID string) ([]domain.Subscription, error)
+	ListActiveSubscriptions(ctx context.Context) ([]domain.Subscription, error)
+	FindMatchingSubscribers(ctx context.Context, eventType string) ([]domain.Subscriber, error)
+}
+
+// EventStore persists published events.
+type EventStore interface {
+	// CreateEvent stores an event. A non-empty ref records that its payload
+	// is kept in object storage, payload then being null. A non-empty
+	// subscriberIDs restricts its fan-out to those subscribers.
+	CreateEvent(ctx context.Context, eventType string, version int, payload []byte, ref *domain.PayloadRef, source string, subscriberIDs []string) (*domain.Event, error)
+	GetEvent(ctx context.Context, id string) (*domain.Event, error)
+	ListEvents(ctx context.Context, eventType string, limit int) ([]domain.Event, error)
+	// SearchEvents returns the newest events whose payload matches every
+	// term of the filter.
+	SearchEvents(ctx context.Context, f EventSearchFilter) ([]domain.Event, error)
+}
+
+// EventTypeStore manages the event type catalog.
+type EventTypeStore interface {
+	ListEventTypes(ctx context.Context) ([]domain.EventType, error)
+	DescribeEventType(ctx context.Context, name string, req domain.DescribeEventTypeRequest) (*domain.EventType, error)
+}
+
+// DeliveryStore records and queries delivery attempts.
+type DeliveryStore interface {
+	RecordDeliveryAttempt(ctx context.Context, rec DeliveryAttemptRecord) error
+	InsertDeliveryAttempts(ctx context.Context, recs []DeliveryAttemptRecord) error
+	ListDeliveryAttempts(ctx context.Context, f DeliveryAttemptFilter) ([]domain.DeliveryAttempt, error)
+	GetDeliveryAttempt(ctx context.Context, id string) (*domain.DeliveryAttempt, error)
+}
+
+// DLQStore manages the dead letter queue.
+type DLQStore interface {
+	InsertDeadLetter(ctx context.Context, rec DeadLetterRecord) error
+	ListDeadLetters(ctx context.Context, f DeadLetterFilter) ([]domain.DeadLetter, error)
+	GetDeadLetter(ctx context.Context, id string) (*domain.DeadLetter, error)
+	ResolveDeadLetter(ctx context.Context, id string, resolvedBy string) error
+	// ResolveSubscriberDeadLetters resolves every unresolved dead letter of
+	// a subscriber, returning how many were resolved.
+	ResolveSubscriberDeadLetters(ctx context.Context, subscriberID string, resolvedBy string) (int64, error)
+	// SummarizeDeadLetters groups the unresolved dead letters of every
+	// subscriber, or only of subscriberID if set, by how they failed,
+	// largest group first.
+	SummarizeDeadLetters(ctx context.Context, subscriberID string) ([]domain.DeadLetterGroup, error)
+}
+
+// AuditStore records and queries the audit log.
+type AuditStore interface {
+	InsertAuditEntry(ctx context.Context, e *domain.AuditEntry) error
+	ListAuditEntries(ctx context.Context, f domain.AuditFilter) ([]domain.AuditEntry, error)
+}
+
+// APIKeyStore manages API keys.
+type APIKeyStore interface {
+	CreateAPIKey(ctx context.Context, name string, role domain.Role, subscriberID *string) (*domain.APIKey, string, error)
+	GetAPIKeyByHash(ctx context.Context, hash string) (*domain.APIKey, error)
+	GetAPIKey(ctx context.Context, id string) (*domain.APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]domain.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id string) (bool, error)
+	SetAPIKeyRole(ctx context.Context, id string, role domain.Role) (*domain.APIKey, error)
+	TouchAPIKey(ctx context.Context, id string) error
+}
+
+// StatsStore serves per-subscriber delivery statistics.
+type StatsStore interface {
+	GetSubscriberStats(ctx context.Context, subscriberID string, since time.Time) (*SubscriberStats, error)
+}
+
+// OutboxStore tracks events whose fan-out has not been queued yet.
+type OutboxStore interface {
+	ClaimPendingOutbox(ctx context.Context, limit int, lease time.Duration) ([]OutboxEntry, error)
+	CompleteOutboxEntry(ctx context.Context, eventID string) error
+	FailOutboxEntry(ctx context.Context, eventID string, errMsg string, retryAt time.Time) error
+	OutboxEntryPending(ctx context.Context, eventID string) (bool, error)
+}
+
+// FanOutStatusStore records whether each event's delivery to each subscriber
+// was queued, so the repair job can retry the ones that were not.
+type FanOutStatusStore interface {
+	// SaveFanOutStatuses inserts or replaces the given statuses.
+	SaveFanOutStatuses(ctx context.Context, statuses []domain.FanOutStatus) error
+	ListFanOutStatuses(ctx context.Context, eventID string) ([]domain.FanOutStatus, error)
+	// ListFanOutRepairs returns failed statuses whose repair is due, oldest
+	// first.
+	ListFanOutRepairs(ctx context.Context, limit int) ([]domain.FanOutStatus, error)
+}
+
+// PendingJobStore keeps delivery jobs the queue could not take, e.g. while
+// Redis is unreachable, until they can be queued again.
+type PendingJobStore interface {
+	SavePendingJob(ctx context.Context, job PendingQueueJob) error
+	// DrainPendingJobs passes up to limit saved jobs, oldest first, to
+	// enqueue and deletes each one it accepts. It stops at the first error,
+	// which it returns, keeping that job and the rest. It returns how many
+	// jobs were drained.
+	DrainPendingJobs(ctx context.Context, limit int, enqueue func(PendingQueueJob) error) (int, error)
+	CountPendingJobs(ctx context.Context) (int64, error)
+}
+
+// MetricsStore serves the dashboard's aggregate delivery statistics.
+type MetricsStore interface {
+	GetDeliveryMetrics(ctx context.Context) (*DeliveryMetrics, error)
+	GetDeliveryTimeseries(ctx context.Context, since time.Time, interval time.Duration) ([]TimeseriesBucket, error)
+}
+
+// ArchiveStore lists the manifests of archived batches.
+type ArchiveStore interface {
+	ListArchiveManifests(ctx context.Context, tableName string, limit int) ([]domain.ArchiveManifest, error)
+	GetArchiveManifest(ctx context.Context, id string) (*domain.ArchiveManifest, error)
+}
+
+// Store is a complete backend.
+type Store interface {
+	SubscriberStore
+	EventStore
+	EventTypeStore
+	DeliveryStore
+	DLQStore
+	AuditStore
+	APIKeyStore
+	StatsStore
+}
+
+// Database is a backend the server can run on: a Store plus the outbox,
+// dashboard aggregates, archive listings, usage metering and dead letter
+// maintenance.
+type Database interface {
+	Store
+	OutboxStore
+	FanOutStatusStore
+	PendingJobStore
+	MetricsStore
+	ArchiveStore
+	UsageStore
+	ExpireDeadLetters(ctx context.Context, cutoff time.Time, limit int) (int64, error)
+	CountRecentDeadLetters(ctx context.Context, since time.Time, threshold int) ([]SubscriberDeadLetterCount, error)
+	// RunMigrations applies the backend's migrations in fsys that are not
+	// applied yet and returns their versions. fsys holds the PostgreSQL
+	// migrations at its root and the SQLite ones in sqlite/, as
+	// migrations.FS does.
+	RunMigrations(ctx context.Context, fsys fs.FS) ([]string, error)
+	// RollbackMigrations reverts the n most recently applied migrations,
+	// newest first, and returns their versions.
+	RollbackMigrations(ctx context.Context, fsys fs.FS, n int) ([]string, error)
+	// MigrationStatus lists the migrations in fsys and when each was applied.
+	MigrationStatus(ctx context.Context, fsys fs.FS) ([]MigrationState, error)
+	// PendingMigrations lists the migrations in fsys not yet applied.
+	PendingMigrations(ctx context.Context, fsys fs.FS) ([]string, error)
+	Ping(ctx context.Context) error
+	Close()
+}
+
+var (
+	_ Database = (*PostgresStore)(nil)
+	_ Database = (*SQLiteStore)(nil)
+	_ Store    = (*MemoryStore)(nil)
+
+	_ FanOutStatusStore = (*MemoryStore)(nil)
+	_ UsageStore        = (*MemoryStore)(nil)
+)
+
+// Open connects to the database named by databaseURL. URLs starting with
+// "sqlite:" open a SQLite file; anything else is handed to Postgres, with a
+// connection pool tuned by poolConfig.
+func Open(ctx context.Context, databaseURL string, poolConfig PoolConfig) (Database, error) {
+	if IsSQLiteURL(databaseURL) {
+		return NewSQLite(ctx, databaseURL)
+	}
+	return NewPostgres(ctx, databaseURL, poolConfig)
+}
+
+// IsSQLiteURL reports whether databaseURL selects the SQLite backend.
+func IsSQLiteURL(databaseURL string) bool {
+	return strings.HasPrefix(databaseURL, "sqlite:")
+}
+
+// newUUID returns a random version 4 UUID, the same shape as the IDs
+// Postgres generates.
+func newUUID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// mergeEventTypes combines documented catalog entries with the published
+// event type statistics into one list sorted by name. A documented example
+// takes precedence over the latest published payload, and a documented
+// current version over the latest published one.
+func mergeEventTypes(documented, published []domain.EventType) []domain.EventType {
+	merged := append([]domain.EventType(nil), documented...)
+	index := make(map[string]int, len(merged))
+	for i, et := range merged {
+		index[et.Name] = i
+	}
+
+	for _, p := range published {
+		i, ok := index[p.Name]
+		if !ok {
+			merged = append(merged, p)
+			continue
+		}
+		merged[i].EventCount = p.EventCount
+		merged[i].LastSeenAt = p.LastSeenAt
+		if len(merged[i].ExamplePayload) == 0 {
+			merged[i].ExamplePayload = p.ExamplePayload
+		}
+	}
+	if merged == nil {
+		merged = []domain.EventType{}
+	}
+
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Name < merged[j].Name })
+	return merged
+}
+
+// percentile interpolates between the closest ranks of sorted values, as
+// Postgres' percentile_cont does.
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	pos := p * float64(len(sorted)-1)
+	lower := int(pos)
+	if lower+1 >= len(sorted) {
+		return sorted[lower]
+	}
+	return sorted[lower] + (pos-float64(lower))*(sorted[lower+1]-sorted[lower])
+}
+
+// addAttemptStats fills in the attempt counts, latency percentiles and daily
+// breakdown of st from a subscriber's attempts in the window, leaving out
+// simulated attempts. Backends without SQL percentiles compute statistics
+// with it.
+func addAttemptStats(st *SubscriberStats, attempts []domain.DeliveryAttempt) {
+	var latencies []float64
+	days := map[time.Time]*DailyAttempts{}
+	st.FailureReasons = map[string]int{}
+	for _, a := range attempts {
+		if a.Status == domain.AttemptSimulated {
+			continue
+		}
+		st.TotalAttempts++
+		switch a.Status {
+		case "success":
+			st.SuccessCount++
+		case "failed":
+			st.FailedCount++
+		}
+		if a.FailureReason != nil {
+			st.FailureReasons[*a.FailureReason]++
+		}
+		if a.AttemptNumber > 1 {
+			st.Retries++
+		}
+		if a.ResponseTimeMs != nil && *a.ResponseTimeMs > 0 {
+			latencies = append(latencies, float64(*a.ResponseTimeMs))
+		}
+
+		day := a.CreatedAt.UTC().Truncate(24 * time.Hour)
+		d, ok := days[day]
+		if !ok {
+			d = &DailyAttempts{Day: day}
+			days[day] = d
+		}
+		d.Attempts++
+		if a.Status == "success" {
+			d.SuccessCount++
+		}
+	}
+
+	if st.TotalAttempts > 0 {
+		st.SuccessRate = float64(st.SuccessCount) / float64(st.TotalAttempts) * 100
+	}
+	sort.Float64s(latencies)
+	st.LatencyP50Ms = percentile(latencies, 0.50)
+	st.LatencyP95Ms = percentile(latencies, 0.95)
+	st.LatencyP99Ms = percentile(latencies, 0.99)
+
+	st.AttemptsPerDay = []DailyAttempts{}
+	for _, d := range days {
+		st.AttemptsPerDay = append(st.AttemptsPerDay, *d)
+	}
+	sort.Slice(st.AttemptsPerDay, func(i, j int) bool {
+		return st.AttemptsPerDay[i].Day.Before(st.AttemptsPerDay[j].Day)
+	})
+}
@@ -0,0 +1,241 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+// SubscriberChangedChannel is the Redis pub/sub channel instances announce
+// subscriber changes on, so every instance drops its cached subscriptions.
+const SubscriberChangedChannel = "subscribers:changed"
+
+// SubscriberCache wraps a Database and answers FindMatchingSubscribers from
+// an in-memory index of active subscriptions instead of querying on every
+// event. Creating, updating, deleting or restoring a subscriber through the
+// cache invalidates it here and, over SubscriberChangedChannel, on every
+// other instance. The index is also rebuilt once it is older than the TTL,
+// which bounds how stale it can get if a change notification is missed or
+// the database is edited directly.
+type SubscriberCache struct {
+	Database
+	client *redis.Client
+	ttl    time.Duration
+	logger *slog.Logger
+
+	mu         sync.RWMutex
+	index      *subscriptionIndex
+	loadedAt   time.Time
+	loadedGen  uint64
+	generation uint64 // bumped on every invalidation
+	loadMu     sync.Mutex
+}
+
+// NewSubscriberCache wraps db with a subscription cache that is rebuilt at
+// least every ttl. Call Start to receive invalidations from other instances.
+func NewSubscriberCache(db Database, client *redis.Client, ttl time.Duration, logger *slog.Logger) *SubscriberCache {
+	return &SubscriberCache{
+		Database: db,
+		client:   client,
+		ttl:      ttl,
+		logger:   logger,
+	}
+}
+
+// Start drops the cached index whenever any instance announces a subscriber
+// change, until the context is cancelled.
+func (c *SubscriberCache) Start(ctx context.Context) {
+	sub := c.client.Subscribe(ctx, SubscriberChangedChannel)
+	defer sub.Close()
+
+	c.logger.Info("subscriber cache started", "ttl", c.ttl)
+
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-ch:
+			if !ok {
+				return
+			}
+			c.Invalidate()
+		}
+	}
+}
+
+// Invalidate drops the cached index so the next lookup rebuilds it.
+func (c *SubscriberCache) Invalidate() {
+	c.mu.Lock()
+	c.generation++
+	c.mu.Unlock()
+}
+
+func (c *SubscriberCache) CreateSubscriber(ctx context.Context, req domain.CreateSubscriberRequest) (*domain.Subscriber, error) {
+	sub, err := c.Database.CreateSubscriber(ctx, req)
+	if err == nil {
+		c.changed(ctx)
+	}
+	return sub, err
+}
+
+func (c *SubscriberCache) UpdateSubscriber(ctx context.Context, id string, req domain.UpdateSubscriberRequest) (*domain.Subscriber, error) {
+	sub, err := c.Database.UpdateSubscriber(ctx, id, req)
+	if err == nil && sub != nil {
+		c.changed(ctx)
+	}
+	return sub, err
+}
+
+func (c *SubscriberCache) DeleteSubscriber(ctx context.Context, id string) (*domain.Subscriber, error) {
+	sub, err := c.Database.DeleteSubscriber(ctx, id)
+	if err == nil && sub != nil {
+		c.changed(ctx)
+	}
+	return sub, err
+}
+
+func (c *SubscriberCache) RestoreSubscriber(ctx context.Context, id string) (*domain.Subscriber, error) {
+	sub, err := c.Database.RestoreSubscriber(ctx, id)
+	if err == nil && sub != nil {
+		c.changed(ctx)
+	}
+	return sub, err
+}
+
+// FindMatchingSubscribers matches eventType against the cached index. If the
+// index can't be rebuilt it falls back to querying the database.
+func (c *SubscriberCache) FindMatchingSubscribers(ctx context.Context, eventType string) ([]domain.Subscriber, error) {
+	idx, err := c.current(ctx)
+	if err != nil {
+		c.logger.Warn("subscriber cache unavailable, querying database", "error", err)
+		return c.Database.FindMatchingSubscribers(ctx, eventType)
+	}
+	return idx.match(eventType), nil
+}
+
+// changed invalidates the local index and tells the other instances to do
+// the same. A failed publish only delays them until their TTL expires.
+func (c *SubscriberCache) changed(ctx context.Context) {
+	c.Invalidate()
+	if err := c.client.Publish(context.WithoutCancel(ctx), SubscriberChangedChannel, "").Err(); err != nil {
+		c.logger.Warn("failed to announce subscriber change", "error", err)
+	}
+}
+
+// current returns a fresh index, rebuilding it if it was invalidated or has
+// outlived the TTL. Only one caller rebuilds at a time; the others wait and
+// then use its result.
+func (c *SubscriberCache) current(ctx context.Context) (*subscriptionIndex, error) {
+	if idx := c.fresh(); idx != nil {
+		return idx, nil
+	}
+
+	c.loadMu.Lock()
+	defer c.loadMu.Unlock()
+	if idx := c.fresh(); idx != nil {
+		return idx, nil
+	}
+
+	c.mu.RLock()
+	gen := c.generation
+	c.mu.RUnlock()
+
+	idx, err := c.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// An invalidation that arrived during the load leaves loadedGen behind
+	// generation, so the next lookup loads again
+	c.mu.Lock()
+	c.index, c.loadedAt, c.loadedGen = idx, time.Now(), gen
+	c.mu.Unlock()
+	return idx, nil
+}
+
+func (c *SubscriberCache) fresh() *subscriptionIndex {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.index == nil || c.loadedGen != c.generation || time.Since(c.loadedAt) >= c.ttl {
+		return nil
+	}
+	return c.index
+}
+
+func (c *SubscriberCache) load(ctx context.Context) (*subscriptionIndex, error) {
+	subscribers, err := c.Database.ListSubscribers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("loading subscribers: %w", err)
+	}
+	subscriptions, err := c.Database.ListActiveSubscriptions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("loading subscriptions: %w", err)
+	}
+	return newSubscriptionIndex(subscribers, subscriptions), nil
+}
+
+// subscriptionIndex maps event types to the active subscribers that want
+// them. Exact subscriptions are looked up directly; wildcard patterns are
+// matched one by one.
+type subscriptionIndex struct {
+	subscribers []domain.Subscriber
+	exact       map[string][]int // event type -> indexes into subscribers
+	patterns    []indexedPattern
+}
+
+type indexedPattern struct {
+	pattern    string
+	subscriber int
+}
+
+func newSubscriptionIndex(subscribers []domain.Subscriber, subscriptions []domain.Subscription) *subscriptionIndex {
+	idx := &subscriptionIndex{exact: make(map[string][]int)}
+	position := make(map[string]int)
+	for _, sub := range subscribers {
+		if sub.IsActive {
+			position[sub.ID] = len(idx.subscribers)
+			idx.subscribers = append(idx.subscribers, sub)
+		}
+	}
+
+	for _, s := range subscriptions {
+		i, ok := position[s.SubscriberID]
+		if !ok || !s.IsActive {
+			continue
+		}
+		if domain.IsEventPattern(s.EventType) {
+			idx.patterns = append(idx.patterns, indexedPattern{pattern: s.EventType, subscriber: i})
+		} else {
+			idx.exact[s.EventType] = append(idx.exact[s.EventType], i)
+		}
+	}
+	return idx
+}
+
+// match returns each subscriber with a subscription matching eventType once.
+func (idx *subscriptionIndex) match(eventType string) []domain.Subscriber {
+	matched := []domain.Subscriber{}
+	seen := make(map[int]bool)
+	add := func(i int) {
+		if !seen[i] {
+			seen[i] = true
+			matched = append(matched, idx.subscribers[i])
+		}
+	}
+
+	for _, i := range idx.exact[eventType] {
+		add(i)
+	}
+	for _, p := range idx.patterns {
+		if domain.MatchEventType(p.pattern, eventType) {
+			add(p.subscriber)
+		}
+	}
+	return matched
+}
@@ -0,0 +1,85 @@
+package store
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestSubscriberCache_InvalidatesAcrossInstances(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	db := newTestSQLite(t)
+	local := NewSubscriberCache(db, client, time.Hour, logger)
+	remote := NewSubscriberCache(db, client, time.Hour, logger)
+	go remote.Start(ctx)
+	for deadline := time.Now().Add(time.Second); mr.PubSubNumSub(SubscriberChangedChannel)[SubscriberChangedChannel] == 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("remote cache never subscribed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	matches := func(c *SubscriberCache, eventType string) int {
+		t.Helper()
+		subs, err := c.FindMatchingSubscribers(ctx, eventType)
+		if err != nil {
+			t.Fatalf("FindMatchingSubscribers: %v", err)
+		}
+		return len(subs)
+	}
+
+	sub, err := local.CreateSubscriber(ctx, domain.CreateSubscriberRequest{
+		Name: "orders", EndpointURL: "https://example.com/hook", EventTypes: []string{"order.created", "order.**"},
+	})
+	if err != nil {
+		t.Fatalf("CreateSubscriber: %v", err)
+	}
+	if n := matches(local, "order.created"); n != 1 {
+		t.Errorf("order.created matched %d subscribers, want 1 (no duplicates)", n)
+	}
+	if n := matches(local, "order.item.added"); n != 1 {
+		t.Errorf("order.item.added matched %d subscribers, want 1", n)
+	}
+	if n := matches(remote, "order.created"); n != 1 {
+		t.Fatalf("remote cache matched %d subscribers, want 1", n)
+	}
+
+	// Deactivating through one instance reaches the other over pub/sub
+	inactive := false
+	if _, err := local.UpdateSubscriber(ctx, sub.ID, domain.UpdateSubscriberRequest{IsActive: &inactive}); err != nil {
+		t.Fatalf("UpdateSubscriber: %v", err)
+	}
+	if n := matches(local, "order.created"); n != 0 {
+		t.Errorf("local cache still matches %d subscribers after deactivation", n)
+	}
+	for deadline := time.Now().Add(time.Second); matches(remote, "order.created") != 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("remote cache was not invalidated")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// Changes that bypass the cache show up once it is invalidated
+	active := true
+	db.UpdateSubscriber(ctx, sub.ID, domain.UpdateSubscriberRequest{IsActive: &active})
+	if n := matches(local, "order.created"); n != 0 {
+		t.Errorf("cache reloaded without an invalidation: %d matches", n)
+	}
+	local.Invalidate()
+	if n := matches(local, "order.created"); n != 1 {
+		t.Errorf("after Invalidate matched %d subscribers, want 1", n)
+	}
+}
@@ -4,14 +4,62 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 
 	"github.com/Priya8975/webhook-delivery-system/internal/domain"
 	"github.com/jackc/pgx/v5"
 )
 
+// subscriberColumns is the column list scanned by scanSubscriber.
+const subscriberColumns = `id, name, endpoint_url, secret_key, is_active, rate_limit_per_second, compress_payloads, discard_response_bodies, batch_max_events, batch_window_seconds, proxy_url, debug_logging, signature_format, event_versions, sandbox, daily_quota, monthly_quota, auto_replay_dead_letters, retry_jitter, max_retry_duration_seconds, retry_for_duration, success_body_contains, success_json_path, redirect_policy, endpoint_type, endpoint_credentials, is_system, version, created_at, updated_at, deleted_at`
+
+// scanSubscriber scans a row selected with subscriberColumns.
+func scanSubscriber(row pgx.Row, sub *domain.Subscriber) error {
+	var eventVersions []byte
+	var credentials string
+	err := row.Scan(
+		&sub.ID, &sub.Name, &sub.EndpointURL, &sub.SecretKey,
+		&sub.IsActive, &sub.RateLimitPerSecond, &sub.CompressPayloads, &sub.DiscardResponseBodies,
+		&sub.BatchMaxEvents, &sub.BatchWindowSeconds, &sub.ProxyURL, &sub.DebugLogging, &sub.SignatureFormat, &eventVersions, &sub.Sandbox, &sub.DailyQuota, &sub.MonthlyQuota, &sub.AutoReplayDeadLetters, &sub.RetryJitter, &sub.MaxRetryDurationSeconds, &sub.RetryForDuration, &sub.SuccessBodyContains, &sub.SuccessJSONPath, &sub.RedirectPolicy, &sub.EndpointType, &credentials, &sub.IsSystem, &sub.Version, &sub.CreatedAt, &sub.UpdatedAt, &sub.DeletedAt,
+	)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(eventVersions, &sub.EventVersions); err != nil {
+		return fmt.Errorf("decoding event versions: %w", err)
+	}
+	if credentials != "" {
+		sub.EndpointCredentials = &domain.EndpointCredentials{}
+		if err := json.Unmarshal([]byte(credentials), sub.EndpointCredentials); err != nil {
+			return fmt.Errorf("decoding endpoint credentials: %w", err)
+		}
+	}
+	return nil
+}
+
+// encodeEventVersions encodes a subscriber's version pins for the
+// event_versions column, which is never null.
+func encodeEventVersions(versions map[string]int) []byte {
+	if len(versions) == 0 {
+		return []byte("{}")
+	}
+	data, _ := json.Marshal(versions)
+	return data
+}
+
+// encodeEndpointCredentials encodes a subscriber's endpoint credentials for
+// the endpoint_credentials column, empty when there are none.
+func encodeEndpointCredentials(creds *domain.EndpointCredentials) string {
+	if creds.IsZero() {
+		return ""
+	}
+	data, _ := json.Marshal(creds)
+	return string(data)
+}
+
 func (s *PostgresStore) CreateSubscriber(ctx context.Context, req domain.CreateSubscriberRequest) (*domain.Subscriber, error) {
-	secretKey, err := generateSecretKey()
+	secretKey, err := subscriberSecretKey(req)
 	if err != nil {
 		return nil, fmt.Errorf("generating secret key: %w", err)
 	}
@@ -24,14 +72,12 @@ func (s *PostgresStore) CreateSubscriber(ctx context.Context, req domain.CreateS
 
 	// Insert subscriber
 	var sub domain.Subscriber
-	err = tx.QueryRow(ctx, `
-		INSERT INTO subscribers (name, endpoint_url, secret_key)
-		VALUES ($1, $2, $3)
-		RETURNING id, name, endpoint_url, secret_key, is_active, rate_limit_per_second, created_at, updated_at
-	`, req.Name, req.EndpointURL, secretKey).Scan(
-		&sub.ID, &sub.Name, &sub.EndpointURL, &sub.SecretKey,
-		&sub.IsActive, &sub.RateLimitPerSecond, &sub.CreatedAt, &sub.UpdatedAt,
-	)
+	err = scanSubscriber(tx.QueryRow(ctx, `
+		INSERT INTO subscribers (name, endpoint_url, secret_key, compress_payloads, discard_response_bodies, batch_max_events, batch_window_seconds, proxy_url, signature_format, event_versions, sandbox, daily_quota, monthly_quota, auto_replay_dead_letters, retry_jitter, max_retry_duration_seconds, retry_for_duration, success_body_contains, success_json_path, redirect_policy, endpoint_type, endpoint_credentials, is_system)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23)
+		RETURNING `+subscriberColumns,
+		req.Name, req.EndpointURL, secretKey, req.CompressPayloads, req.DiscardResponseBodies, req.BatchMaxEvents, req.BatchWindowSeconds, req.ProxyURL, domain.SignatureFormatOrDefault(req.SignatureFormat), encodeEventVersions(req.EventVersions), req.Sandbox, req.DailyQuota, req.MonthlyQuota, req.AutoReplayDeadLetters, req.RetryJitter, req.MaxRetryDurationSeconds, req.RetryForDuration, req.SuccessBodyContains, req.SuccessJSONPath, req.RedirectPolicy, domain.EndpointTypeOrDefault(req.EndpointType), encodeEndpointCredentials(req.EndpointCredentials), req.IsSystem,
+	), &sub)
 	if err != nil {
 		return nil, fmt.Errorf("inserting subscriber: %w", err)
 	}
@@ -56,13 +102,10 @@ func (s *PostgresStore) CreateSubscriber(ctx context.Context, req domain.CreateS
 
 func (s *PostgresStore) GetSubscriber(ctx context.Context, id string) (*domain.Subscriber, error) {
 	var sub domain.Subscriber
-	err := s.pool.QueryRow(ctx, `
-		SELECT id, name, endpoint_url, secret_key, is_active, rate_limit_per_second, created_at, updated_at
+	err := scanSubscriber(s.pool.QueryRow(ctx, `
+		SELECT `+subscriberColumns+`
 		FROM subscribers WHERE id = $1
-	`, id).Scan(
-		&sub.ID, &sub.Name, &sub.EndpointURL, &sub.SecretKey,
-		&sub.IsActive, &sub.RateLimitPerSecond, &sub.CreatedAt, &sub.UpdatedAt,
-	)
+	`, id), &sub)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, nil
@@ -74,8 +117,9 @@ func (s *PostgresStore) GetSubscriber(ctx context.Context, id string) (*domain.S
 
 func (s *PostgresStore) ListSubscribers(ctx context.Context) ([]domain.Subscriber, error) {
 	rows, err := s.pool.Query(ctx, `
-		SELECT id, name, endpoint_url, is_active, rate_limit_per_second, created_at, updated_at
+		SELECT `+subscriberColumns+`
 		FROM subscribers
+		WHERE deleted_at IS NULL
 		ORDER BY created_at DESC
 	`)
 	if err != nil {
@@ -86,13 +130,11 @@ func (s *PostgresStore) ListSubscribers(ctx context.Context) ([]domain.Subscribe
 	var subscribers []domain.Subscriber
 	for rows.Next() {
 		var sub domain.Subscriber
-		err := rows.Scan(
-			&sub.ID, &sub.Name, &sub.EndpointURL,
-			&sub.IsActive, &sub.RateLimitPerSecond, &sub.CreatedAt, &sub.UpdatedAt,
-		)
-		if err != nil {
+		if err := scanSubscriber(rows, &sub); err != nil {
 			return nil, fmt.Errorf("scanning subscriber: %w", err)
 		}
+		sub.SecretKey = "" // never expose secrets in listings
+		sub.EndpointCredentials = nil
 		subscribers = append(subscribers, sub)
 	}
 
@@ -129,31 +171,176 @@ func (s *PostgresStore) UpdateSubscriber(ctx context.Context, id string, req dom
 		args = append(args, *req.RateLimitPerSecond)
 		argIdx++
 	}
+	if req.CompressPayloads != nil {
+		setClauses = append(setClauses, fmt.Sprintf("compress_payloads = $%d", argIdx))
+		args = append(args, *req.CompressPayloads)
+		argIdx++
+	}
+	if req.DiscardResponseBodies != nil {
+		setClauses = append(setClauses, fmt.Sprintf("discard_response_bodies = $%d", argIdx))
+		args = append(args, *req.DiscardResponseBodies)
+		argIdx++
+	}
+	if req.BatchMaxEvents != nil {
+		setClauses = append(setClauses, fmt.Sprintf("batch_max_events = $%d", argIdx))
+		args = append(args, *req.BatchMaxEvents)
+		argIdx++
+	}
+	if req.BatchWindowSeconds != nil {
+		setClauses = append(setClauses, fmt.Sprintf("batch_window_seconds = $%d", argIdx))
+		args = append(args, *req.BatchWindowSeconds)
+		argIdx++
+	}
+	if req.ProxyURL != nil {
+		setClauses = append(setClauses, fmt.Sprintf("proxy_url = $%d", argIdx))
+		args = append(args, *req.ProxyURL)
+		argIdx++
+	}
+	if req.DebugLogging != nil {
+		setClauses = append(setClauses, fmt.Sprintf("debug_logging = $%d", argIdx))
+		args = append(args, *req.DebugLogging)
+		argIdx++
+	}
+	if req.SignatureFormat != nil {
+		setClauses = append(setClauses, fmt.Sprintf("signature_format = $%d", argIdx))
+		args = append(args, domain.SignatureFormatOrDefault(*req.SignatureFormat))
+		argIdx++
+	}
+	if req.EventVersions != nil {
+		setClauses = append(setClauses, fmt.Sprintf("event_versions = $%d", argIdx))
+		args = append(args, encodeEventVersions(req.EventVersions))
+		argIdx++
+	}
+	if req.Sandbox != nil {
+		setClauses = append(setClauses, fmt.Sprintf("sandbox = $%d", argIdx))
+		args = append(args, *req.Sandbox)
+		argIdx++
+	}
+	if req.DailyQuota != nil {
+		setClauses = append(setClauses, fmt.Sprintf("daily_quota = $%d", argIdx))
+		args = append(args, *req.DailyQuota)
+		argIdx++
+	}
+	if req.MonthlyQuota != nil {
+		setClauses = append(setClauses, fmt.Sprintf("monthly_quota = $%d", argIdx))
+		args = append(args, *req.MonthlyQuota)
+		argIdx++
+	}
+	if req.AutoReplayDeadLetters != nil {
+		setClauses = append(setClauses, fmt.Sprintf("auto_replay_dead_letters = $%d", argIdx))
+		args = append(args, *req.AutoReplayDeadLetters)
+		argIdx++
+	}
+	if req.RetryJitter != nil {
+		setClauses = append(setClauses, fmt.Sprintf("retry_jitter = $%d", argIdx))
+		args = append(args, *req.RetryJitter)
+		argIdx++
+	}
+	if req.MaxRetryDurationSeconds != nil {
+		setClauses = append(setClauses, fmt.Sprintf("max_retry_duration_seconds = $%d", argIdx))
+		args = append(args, *req.MaxRetryDurationSeconds)
+		argIdx++
+	}
+	if req.RetryForDuration != nil {
+		setClauses = append(setClauses, fmt.Sprintf("retry_for_duration = $%d", argIdx))
+		args = append(args, *req.RetryForDuration)
+		argIdx++
+	}
+	if req.SuccessBodyContains != nil {
+		setClauses = append(setClauses, fmt.Sprintf("success_body_contains = $%d", argIdx))
+		args = append(args, *req.SuccessBodyContains)
+		argIdx++
+	}
+	if req.SuccessJSONPath != nil {
+		setClauses = append(setClauses, fmt.Sprintf("success_json_path = $%d", argIdx))
+		args = append(args, *req.SuccessJSONPath)
+		argIdx++
+	}
+	if req.RedirectPolicy != nil {
+		setClauses = append(setClauses, fmt.Sprintf("redirect_policy = $%d", argIdx))
+		args = append(args, *req.RedirectPolicy)
+		argIdx++
+	}
+	if req.EndpointType != nil {
+		setClauses = append(setClauses, fmt.Sprintf("endpoint_type = $%d", argIdx))
+		args = append(args, domain.EndpointTypeOrDefault(*req.EndpointType))
+		argIdx++
+	}
+	if req.EndpointCredentials != nil {
+		setClauses = append(setClauses, fmt.Sprintf("endpoint_credentials = $%d", argIdx))
+		args = append(args, encodeEndpointCredentials(req.EndpointCredentials))
+		argIdx++
+	}
 
 	if len(setClauses) == 0 {
-		return s.GetSubscriber(ctx, id)
+		sub, err := s.GetSubscriber(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return checkVersion(sub, req.Version)
 	}
 
-	setClauses = append(setClauses, "updated_at = NOW()")
+	setClauses = append(setClauses, "version = version + 1", "updated_at = NOW()")
+
+	where := fmt.Sprintf("id = $%d", argIdx)
+	args = append(args, id)
+	if req.Version != nil {
+		where += fmt.Sprintf(" AND version = $%d", argIdx+1)
+		args = append(args, *req.Version)
+	}
 
 	query := fmt.Sprintf(`
 		UPDATE subscribers SET %s
-		WHERE id = $%d
-		RETURNING id, name, endpoint_url, is_active, rate_limit_per_second, created_at, updated_at
-	`, joinStrings(setClauses, ", "), argIdx)
-	args = append(args, id)
+		WHERE %s
+		RETURNING %s
+	`, joinStrings(setClauses, ", "), where, subscriberColumns)
 
 	var sub domain.Subscriber
-	err := s.pool.QueryRow(ctx, query, args...).Scan(
-		&sub.ID, &sub.Name, &sub.EndpointURL,
-		&sub.IsActive, &sub.RateLimitPerSecond, &sub.CreatedAt, &sub.UpdatedAt,
-	)
+	err := scanSubscriber(s.pool.QueryRow(ctx, query, args...), &sub)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			if req.Version == nil {
+				return nil, nil
+			}
+			// Either the subscriber is gone or its version moved on
+			current, err := s.GetSubscriber(ctx, id)
+			if err != nil || current == nil {
+				return nil, err
+			}
+			return nil, ErrVersionConflict
+		}
+		return nil, fmt.Errorf("updating subscriber: %w", err)
+	}
+	sub.SecretKey = ""
+	sub.EndpointCredentials = nil
+
+	return &sub, nil
+}
+
+func (s *PostgresStore) DeleteSubscriber(ctx context.Context, id string) (*domain.Subscriber, error) {
+	return s.setSubscriberDeletedAt(ctx, id, "NOW()")
+}
+
+func (s *PostgresStore) RestoreSubscriber(ctx context.Context, id string) (*domain.Subscriber, error) {
+	return s.setSubscriberDeletedAt(ctx, id, "NULL")
+}
+
+func (s *PostgresStore) setSubscriberDeletedAt(ctx context.Context, id, value string) (*domain.Subscriber, error) {
+	var sub domain.Subscriber
+	err := scanSubscriber(s.pool.QueryRow(ctx, `
+		UPDATE subscribers SET deleted_at = `+value+`, version = version + 1, updated_at = NOW()
+		WHERE id = $1
+		RETURNING `+subscriberColumns,
+		id,
+	), &sub)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, nil
 		}
 		return nil, fmt.Errorf("updating subscriber: %w", err)
 	}
+	sub.SecretKey = ""
+	sub.EndpointCredentials = nil
 
 	return &sub, nil
 }
@@ -187,6 +374,40 @@ func (s *PostgresStore) GetSubscriberSubscriptions(ctx context.Context, subscrib
 	return subs, nil
 }
 
+// ListActiveSubscriptions returns every active subscription, whatever the
+// state of its subscriber.
+func (s *PostgresStore) ListActiveSubscriptions(ctx context.Context) ([]domain.Subscription, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT id, subscriber_id, event_type, is_active, created_at
+		FROM subscriptions
+		WHERE is_active = true
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	subs := []domain.Subscription{}
+	for rows.Next() {
+		var sub domain.Subscription
+		err := rows.Scan(&sub.ID, &sub.SubscriberID, &sub.EventType, &sub.IsActive, &sub.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("scanning subscription: %w", err)
+		}
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
+
+// subscriberSecretKey returns the secret a new subscriber signs with: the
+// one given in req, or a generated one.
+func subscriberSecretKey(req domain.CreateSubscriberRequest) (string, error) {
+	if req.SecretKey != "" {
+		return req.SecretKey, nil
+	}
+	return generateSecretKey()
+}
+
 func generateSecretKey() (string, error) {
 	bytes := make([]byte, 32)
 	if _, err := rand.Read(bytes); err != nil {
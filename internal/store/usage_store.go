@@ -0,0 +1,128 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+// UsageFilter selects the days of a usage report. From and To are UTC days,
+// both included; SubscriberID optionally narrows it to one subscriber.
+type UsageFilter struct {
+	SubscriberID string
+	From         time.Time
+	To           time.Time
+}
+
+// UsageStore serves the usage metered as delivery attempts are recorded.
+type UsageStore interface {
+	// ListUsage returns the usage of each subscriber on each day of the
+	// filter it had any, ordered by day and subscriber name.
+	ListUsage(ctx context.Context, f UsageFilter) ([]domain.UsageDay, error)
+}
+
+// usageDay returns the UTC day that attempts recorded at t are metered on.
+func usageDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
+
+// usageDeltas sums recs per subscriber into what they add to a day's usage.
+// Simulated attempts are not metered.
+func usageDeltas(recs []DeliveryAttemptRecord) []domain.Usage {
+	var deltas []domain.Usage
+	index := map[string]int{}
+	for _, rec := range recs {
+		if rec.Status == domain.AttemptSimulated {
+			continue
+		}
+		i, ok := index[rec.SubscriberID]
+		if !ok {
+			i = len(deltas)
+			index[rec.SubscriberID] = i
+			deltas = append(deltas, domain.Usage{SubscriberID: rec.SubscriberID})
+		}
+		u := &deltas[i]
+		u.Deliveries++
+		if rec.Status == "success" {
+			u.Successful++
+		}
+		if rec.AttemptNumber > 1 {
+			u.Retries++
+		}
+		u.PayloadBytes += int64(rec.PayloadBytes)
+	}
+	return deltas
+}
+
+// meterUsage adds recs to today's usage in the same transaction that
+// inserts them, so attempts are metered exactly once.
+func meterUsage(ctx context.Context, tx pgx.Tx, recs []DeliveryAttemptRecord) error {
+	deltas := usageDeltas(recs)
+	if len(deltas) == 0 {
+		return nil
+	}
+
+	n := len(deltas)
+	subscriberIDs := make([]string, n)
+	deliveries := make([]int64, n)
+	successful := make([]int64, n)
+	retries := make([]int64, n)
+	payloadBytes := make([]int64, n)
+	for i, u := range deltas {
+		subscriberIDs[i] = u.SubscriberID
+		deliveries[i] = u.Deliveries
+		successful[i] = u.Successful
+		retries[i] = u.Retries
+		payloadBytes[i] = u.PayloadBytes
+	}
+
+	_, err := tx.Exec(ctx, `
+		INSERT INTO usage_daily (day, subscriber_id, deliveries, successful, retries, payload_bytes)
+		SELECT $1::date, subscriber_id, deliveries, successful, retries, payload_bytes
+		FROM unnest($2::uuid[], $3::bigint[], $4::bigint[], $5::bigint[], $6::bigint[])
+			AS t(subscriber_id, deliveries, successful, retries, payload_bytes)
+		ON CONFLICT (day, subscriber_id) DO UPDATE SET
+			deliveries = usage_daily.deliveries + EXCLUDED.deliveries,
+			successful = usage_daily.successful + EXCLUDED.successful,
+			retries = usage_daily.retries + EXCLUDED.retries,
+			payload_bytes = usage_daily.payload_bytes + EXCLUDED.payload_bytes
+	`, usageDay(time.Now()), subscriberIDs, deliveries, successful, retries, payloadBytes)
+	if err != nil {
+		return fmt.Errorf("metering usage: %w", err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) ListUsage(ctx context.Context, f UsageFilter) ([]domain.UsageDay, error) {
+	query := `
+		SELECT u.day, u.subscriber_id, COALESCE(s.name, ''), u.deliveries, u.successful, u.retries, u.payload_bytes
+		FROM usage_daily u
+		LEFT JOIN subscribers s ON s.id = u.subscriber_id
+		WHERE u.day BETWEEN $1 AND $2`
+	args := []interface{}{usageDay(f.From), usageDay(f.To)}
+	if f.SubscriberID != "" {
+		query += " AND u.subscriber_id = $3"
+		args = append(args, f.SubscriberID)
+	}
+	query += " ORDER BY u.day, s.name, u.subscriber_id"
+
+	rows, err := s.reads().Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing usage: %w", err)
+	}
+	defer rows.Close()
+
+	days := []domain.UsageDay{}
+	for rows.Next() {
+		var u domain.UsageDay
+		if err := rows.Scan(&u.Day, &u.SubscriberID, &u.SubscriberName, &u.Deliveries, &u.Successful, &u.Retries, &u.PayloadBytes); err != nil {
+			return nil, fmt.Errorf("scanning usage: %w", err)
+		}
+		u.Day = u.Day.UTC()
+		days = append(days, u)
+	}
+	return days, rows.Err()
+}
@@ -1,26 +1,22 @@
 package websocket
 
 import (
+	"context"
 	"encoding/json"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
 )
 
-var upgrader = websocket.Upgrader{
-	ReadBufferSize:  1024,
-	WriteBufferSize: 1024,
-	CheckOrigin: func(r *http.Request) bool {
-		return true // Allow all origins for development
-	},
-}
-
 // DeliveryEvent represents a real-time delivery update sent to dashboard clients.
 type DeliveryEvent struct {
-	Type         string    `json:"type"` // "delivery_success", "delivery_failed", "delivery_retrying", "delivery_dlq"
+	Type         string    `json:"type"` // "delivery_success", "delivery_failed", "delivery_retrying", "delivery_dlq", "delivery_simulated"
+	DeliveryID   string    `json:"delivery_id,omitempty"`
 	EventID      string    `json:"event_id"`
 	SubscriberID string    `json:"subscriber_id"`
 	EndpointURL  string    `json:"endpoint_url"`
@@ -32,31 +28,181 @@ type DeliveryEvent struct {
 	Timestamp    time.Time `json:"timestamp"`
 }
 
-// Hub manages WebSocket connections and broadcasts events to all connected clients.
+// Filter narrows the events a client receives. Clients set it by sending a
+// subscribe message such as {"subscriber_id": "...", "types": ["delivery_dlq"]}
+// after connecting. Empty fields match everything, so sending {} restores the
+// full stream.
+type Filter struct {
+	SubscriberID string   `json:"subscriber_id,omitempty"`
+	Types        []string `json:"types,omitempty"`
+}
+
+// Matches reports whether the event passes the filter.
+func (f *Filter) Matches(event DeliveryEvent) bool {
+	if f == nil {
+		return true
+	}
+	if f.SubscriberID != "" && f.SubscriberID != event.SubscriberID {
+		return false
+	}
+	if len(f.Types) == 0 {
+		return true
+	}
+	for _, t := range f.Types {
+		if t == event.Type {
+			return true
+		}
+	}
+	return false
+}
+
+// controlMessage is sent to a client in reply to a subscribe message.
+type controlMessage struct {
+	Type   string  `json:"type"` // "subscribed" or "error"
+	Filter *Filter `json:"filter,omitempty"`
+	Error  string  `json:"error,omitempty"`
+}
+
+// recentEventsSize is how many recent events are replayed to a newly
+// connected WebSocket client so the dashboard isn't blank until new traffic.
+const recentEventsSize = 50
+
+// Snapshot is the first message a WebSocket client receives, describing
+// current system state.
+type Snapshot struct {
+	Type            string         `json:"type"` // always "snapshot"
+	QueueDepth      int64          `json:"queue_depth"`
+	CircuitBreakers []BreakerState `json:"circuit_breakers"`
+	Timestamp       time.Time      `json:"timestamp"`
+}
+
+// BreakerState is one subscriber's circuit breaker state within a Snapshot.
+type BreakerState struct {
+	SubscriberID string `json:"subscriber_id"`
+	Name         string `json:"name"`
+	State        string `json:"state"`
+	Failures     int    `json:"failures"`
+}
+
+// SnapshotFunc builds a Snapshot for a newly connected client.
+type SnapshotFunc func(ctx context.Context) (*Snapshot, error)
+
+// Hub manages WebSocket connections and broadcasts events to all connected
+// clients and SSE listeners.
 type Hub struct {
 	clients    map[*client]struct{}
+	listeners  map[*listener]struct{}
 	mu         sync.RWMutex
-	broadcast  chan []byte
+	broadcast  chan outbound
 	register   chan *client
 	unregister chan *client
+	stream     *EventStream
+	relay      *Relay
+	snapshot   SnapshotFunc
+	upgrader   websocket.Upgrader
 	logger     *slog.Logger
+
+	// allowedOrigins is set before serving and read-only afterwards.
+	allowedOrigins []string
+
+	// recent is a ring buffer of the last recentEventsSize broadcasts. It is
+	// only touched by the Run goroutine.
+	recent     []outbound
+	recentNext int
+}
+
+// outbound is a broadcast event along with its encoded form, so each event is
+// marshaled once no matter how many clients receive it. id is the event's
+// stream ID, empty when no event stream is configured.
+type outbound struct {
+	id    string
+	event DeliveryEvent
+	data  []byte
+}
+
+// listener receives broadcasts over a channel rather than a WebSocket. The
+// hub closes ch if the listener falls behind.
+type listener struct {
+	ch     chan outbound
+	filter *Filter
 }
 
 type client struct {
 	hub  *Hub
 	conn *websocket.Conn
 	send chan []byte
+
+	filterMu sync.RWMutex
+	filter   *Filter
 }
 
-// NewHub creates a new WebSocket hub.
-func NewHub(logger *slog.Logger) *Hub {
-	return &Hub{
+func (c *client) wants(event DeliveryEvent) bool {
+	c.filterMu.RLock()
+	defer c.filterMu.RUnlock()
+	return c.filter.Matches(event)
+}
+
+func (c *client) setFilter(f *Filter) {
+	c.filterMu.Lock()
+	defer c.filterMu.Unlock()
+	c.filter = f
+}
+
+// NewHub creates a new WebSocket hub. If stream is non-nil, every broadcast
+// is also recorded there so SSE clients can resume after reconnecting. If
+// snapshot is non-nil, new WebSocket clients receive its result first.
+func NewHub(stream *EventStream, snapshot SnapshotFunc, logger *slog.Logger) *Hub {
+	h := &Hub{
 		clients:    make(map[*client]struct{}),
-		broadcast:  make(chan []byte, 256),
+		listeners:  make(map[*listener]struct{}),
+		stream:     stream,
+		snapshot:   snapshot,
+		recent:     make([]outbound, 0, recentEventsSize),
+		broadcast:  make(chan outbound, 256),
 		register:   make(chan *client),
 		unregister: make(chan *client),
 		logger:     logger,
 	}
+	h.upgrader = websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin:     h.checkOrigin,
+	}
+	return h
+}
+
+// AllowOrigins sets the origins allowed to open WebSocket connections, such
+// as "https://dashboard.example.com". With no origins configured only
+// same-origin connections are accepted; "*" accepts any origin. Call it
+// before serving requests.
+func (h *Hub) AllowOrigins(origins []string) {
+	h.allowedOrigins = origins
+}
+
+// UseRelay shares this hub's broadcasts with other instances through relay.
+// Run relay.Start alongside the hub to receive theirs. Call it before
+// broadcasting.
+func (h *Hub) UseRelay(relay *Relay) {
+	h.relay = relay
+}
+
+// checkOrigin is the upgrader's origin policy. Requests without an Origin
+// header come from non-browser clients and are left to authentication.
+func (h *Hub) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if len(h.allowedOrigins) == 0 {
+		u, err := url.Parse(origin)
+		return err == nil && strings.EqualFold(u.Host, r.Host)
+	}
+	for _, allowed := range h.allowedOrigins {
+		if allowed == "*" || strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+			return true
+		}
+	}
+	return false
 }
 
 // Run starts the hub's event loop. Should be called as a goroutine.
@@ -64,6 +210,9 @@ func (h *Hub) Run() {
 	for {
 		select {
 		case c := <-h.register:
+			// Replay recent events before the client joins the live stream.
+			// Both happen on this goroutine, so nothing is missed or repeated.
+			h.replayRecent(c)
 			h.mu.Lock()
 			h.clients[c] = struct{}{}
 			h.mu.Unlock()
@@ -79,10 +228,14 @@ func (h *Hub) Run() {
 			h.logger.Debug("websocket client disconnected", "total_clients", len(h.clients))
 
 		case message := <-h.broadcast:
+			h.remember(message)
 			h.mu.RLock()
 			for c := range h.clients {
+				if !c.wants(message.event) {
+					continue
+				}
 				select {
-				case c.send <- message:
+				case c.send <- message.data:
 				default:
 					// Client buffer full — drop it
 					h.mu.RUnlock()
@@ -93,11 +246,67 @@ func (h *Hub) Run() {
 					h.mu.RLock()
 				}
 			}
+			for l := range h.listeners {
+				if !l.filter.Matches(message.event) {
+					continue
+				}
+				select {
+				case l.ch <- message:
+				default:
+					// Listener fell behind — it can resume from its last event ID
+					h.mu.RUnlock()
+					h.removeListener(l)
+					h.mu.RLock()
+				}
+			}
 			h.mu.RUnlock()
 		}
 	}
 }
 
+// remember adds a broadcast to the recent-events ring buffer.
+func (h *Hub) remember(message outbound) {
+	if len(h.recent) < recentEventsSize {
+		h.recent = append(h.recent, message)
+		return
+	}
+	h.recent[h.recentNext] = message
+	h.recentNext = (h.recentNext + 1) % recentEventsSize
+}
+
+// replayRecent queues the buffered recent events, oldest first, on a client
+// that is not yet registered.
+func (h *Hub) replayRecent(c *client) {
+	for i := 0; i < len(h.recent); i++ {
+		message := h.recent[(h.recentNext+i)%len(h.recent)]
+		select {
+		case c.send <- message.data:
+		default:
+			return
+		}
+	}
+}
+
+// subscribe registers a listener for broadcasts matching filter.
+func (h *Hub) subscribe(filter *Filter) *listener {
+	l := &listener{ch: make(chan outbound, 256), filter: filter}
+	h.mu.Lock()
+	h.listeners[l] = struct{}{}
+	h.mu.Unlock()
+	return l
+}
+
+// removeListener unregisters a listener and closes its channel. It is safe
+// to call more than once.
+func (h *Hub) removeListener(l *listener) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.listeners[l]; ok {
+		delete(h.listeners, l)
+		close(l.ch)
+	}
+}
+
 // Broadcast sends a delivery event to all connected WebSocket clients.
 func (h *Hub) Broadcast(event DeliveryEvent) {
 	data, err := json.Marshal(event)
@@ -106,8 +315,42 @@ func (h *Hub) Broadcast(event DeliveryEvent) {
 		return
 	}
 
+	var id string
+	if h.stream != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		id, err = h.stream.Append(ctx, data)
+		cancel()
+		if err != nil {
+			h.logger.Warn("failed to record event in stream", "error", err)
+		}
+	}
+
+	if h.relay != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		err = h.relay.Publish(ctx, id, data)
+		cancel()
+		if err != nil {
+			h.logger.Warn("failed to relay event to other instances", "error", err)
+		}
+	}
+
+	h.enqueue(outbound{id: id, event: event, data: data})
+}
+
+// deliverRemote sends an event broadcast by another instance to this hub's
+// clients. It has already been recorded in the event stream.
+func (h *Hub) deliverRemote(id string, data []byte) {
+	var event DeliveryEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		h.logger.Warn("failed to decode relayed event", "error", err)
+		return
+	}
+	h.enqueue(outbound{id: id, event: event, data: data})
+}
+
+func (h *Hub) enqueue(message outbound) {
 	select {
-	case h.broadcast <- data:
+	case h.broadcast <- message:
 	default:
 		h.logger.Warn("websocket broadcast channel full, dropping event")
 	}
@@ -115,7 +358,7 @@ func (h *Hub) Broadcast(event DeliveryEvent) {
 
 // HandleWebSocket upgrades HTTP connections to WebSocket and registers the client.
 func (h *Hub) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
-	conn, err := upgrader.Upgrade(w, r, nil)
+	conn, err := h.upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		h.logger.Error("websocket upgrade failed", "error", err)
 		return
@@ -127,20 +370,43 @@ func (h *Hub) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 		send: make(chan []byte, 256),
 	}
 
+	if h.snapshot != nil {
+		h.sendSnapshot(r.Context(), c)
+	}
+
 	h.register <- c
 
 	go c.writePump()
 	go c.readPump()
 }
 
-// readPump reads messages from the WebSocket connection (handles pings/disconnects).
+// sendSnapshot queues the current system snapshot on a client that is not
+// yet registered.
+func (h *Hub) sendSnapshot(ctx context.Context, c *client) {
+	snap, err := h.snapshot(ctx)
+	if err != nil {
+		h.logger.Warn("failed to build websocket snapshot", "error", err)
+		return
+	}
+	snap.Type = "snapshot"
+
+	data, err := json.Marshal(snap)
+	if err != nil {
+		h.logger.Error("failed to marshal websocket snapshot", "error", err)
+		return
+	}
+	c.send <- data
+}
+
+// readPump reads messages from the WebSocket connection (handles pings,
+// disconnects, and subscribe messages).
 func (c *client) readPump() {
 	defer func() {
 		c.hub.unregister <- c
 		c.conn.Close()
 	}()
 
-	c.conn.SetReadLimit(512)
+	c.conn.SetReadLimit(4096)
 	c.conn.SetReadDeadline(time.Now().Add(60 * time.Second))
 	c.conn.SetPongHandler(func(string) error {
 		c.conn.SetReadDeadline(time.Now().Add(60 * time.Second))
@@ -148,10 +414,39 @@ func (c *client) readPump() {
 	})
 
 	for {
-		_, _, err := c.conn.ReadMessage()
+		_, message, err := c.conn.ReadMessage()
 		if err != nil {
 			break
 		}
+		c.handleMessage(message)
+	}
+}
+
+// handleMessage applies a subscribe message and acknowledges it.
+func (c *client) handleMessage(message []byte) {
+	var f Filter
+	reply := controlMessage{Type: "subscribed", Filter: &f}
+	if err := json.Unmarshal(message, &f); err != nil {
+		reply = controlMessage{Type: "error", Error: "invalid subscribe message"}
+	} else {
+		c.setFilter(&f)
+	}
+
+	data, err := json.Marshal(reply)
+	if err != nil {
+		return
+	}
+
+	// The hub closes c.send under its write lock when it drops a client, so
+	// only send while holding the read lock and still registered
+	c.hub.mu.RLock()
+	defer c.hub.mu.RUnlock()
+	if _, ok := c.hub.clients[c]; !ok {
+		return
+	}
+	select {
+	case c.send <- data:
+	default:
 	}
 }
 
@@ -1,6 +1,8 @@
 package websocket
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
@@ -15,7 +17,7 @@ import (
 func setupTestHub(t *testing.T) *Hub {
 	t.Helper()
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
-	hub := NewHub(logger)
+	hub := NewHub(nil, nil, logger)
 	go hub.Run()
 	return hub
 }
@@ -136,3 +138,168 @@ func TestHub_ClientCountStartsAtZero(t *testing.T) {
 		t.Errorf("expected 0 clients initially, got %d", count)
 	}
 }
+
+func TestHub_SubscribeFiltersEvents(t *testing.T) {
+	hub := setupTestHub(t)
+
+	conn, cleanup := connectWS(t, hub)
+	defer cleanup()
+
+	time.Sleep(50 * time.Millisecond)
+
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(`{"subscriber_id": "sub-1", "types": ["delivery_dlq"]}`)); err != nil {
+		t.Fatalf("failed to send subscribe message: %v", err)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, ack, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("failed to read subscribe ack: %v", err)
+	}
+	if !strings.Contains(string(ack), `"subscribed"`) {
+		t.Fatalf("expected subscribed ack, got: %s", ack)
+	}
+
+	hub.Broadcast(DeliveryEvent{Type: "delivery_success", SubscriberID: "sub-1", EventID: "wrong-type"})
+	hub.Broadcast(DeliveryEvent{Type: "delivery_dlq", SubscriberID: "sub-2", EventID: "wrong-subscriber"})
+	hub.Broadcast(DeliveryEvent{Type: "delivery_dlq", SubscriberID: "sub-1", EventID: "evt-match"})
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, message, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("failed to read message: %v", err)
+	}
+	if !strings.Contains(string(message), "evt-match") {
+		t.Errorf("expected only the matching event, got: %s", message)
+	}
+}
+
+func TestFilter_Matches(t *testing.T) {
+	event := DeliveryEvent{Type: "delivery_failed", SubscriberID: "sub-1"}
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   bool
+	}{
+		{"nil filter", nil, true},
+		{"empty filter", &Filter{}, true},
+		{"subscriber match", &Filter{SubscriberID: "sub-1"}, true},
+		{"subscriber mismatch", &Filter{SubscriberID: "sub-2"}, false},
+		{"type match", &Filter{Types: []string{"delivery_dlq", "delivery_failed"}}, true},
+		{"type mismatch", &Filter{Types: []string{"delivery_success"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Matches(event); got != tt.want {
+				t.Errorf("Matches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHub_NewClientGetsSnapshotThenRecentEvents(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	hub := NewHub(nil, func(ctx context.Context) (*Snapshot, error) {
+		return &Snapshot{
+			QueueDepth:      7,
+			CircuitBreakers: []BreakerState{{SubscriberID: "sub-1", State: "open"}},
+		}, nil
+	}, logger)
+	go hub.Run()
+
+	// Overflow the ring buffer so only the newest events remain
+	for i := 0; i < recentEventsSize+5; i++ {
+		hub.Broadcast(DeliveryEvent{Type: "delivery_success", EventID: fmt.Sprintf("evt-%d", i)})
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	conn, cleanup := connectWS(t, hub)
+	defer cleanup()
+
+	read := func() string {
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("failed to read message: %v", err)
+		}
+		return string(message)
+	}
+
+	snap := read()
+	if !strings.Contains(snap, `"type":"snapshot"`) || !strings.Contains(snap, `"queue_depth":7`) {
+		t.Fatalf("expected snapshot first, got: %s", snap)
+	}
+
+	if first := read(); !strings.Contains(first, `"evt-5"`) {
+		t.Errorf("expected replay to start with evt-5 (oldest retained), got: %s", first)
+	}
+	for i := 1; i < recentEventsSize-1; i++ {
+		read()
+	}
+	if last := read(); !strings.Contains(last, fmt.Sprintf(`"evt-%d"`, recentEventsSize+4)) {
+		t.Errorf("expected replay to end with newest event, got: %s", last)
+	}
+}
+
+func TestHub_CheckOrigin(t *testing.T) {
+	hub := setupTestHub(t)
+
+	request := func(host, origin string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "http://"+host+"/ws", nil)
+		if origin != "" {
+			r.Header.Set("Origin", origin)
+		}
+		return r
+	}
+
+	tests := []struct {
+		name    string
+		allowed []string
+		host    string
+		origin  string
+		want    bool
+	}{
+		{"no origin header", nil, "api.example.com", "", true},
+		{"same origin by default", nil, "api.example.com", "https://api.example.com", true},
+		{"cross origin rejected by default", nil, "api.example.com", "https://evil.example.com", false},
+		{"allowlisted origin", []string{"https://dash.example.com/"}, "api.example.com", "https://dash.example.com", true},
+		{"origin not in allowlist", []string{"https://dash.example.com"}, "api.example.com", "https://api.example.com", false},
+		{"wildcard", []string{"*"}, "api.example.com", "https://evil.example.com", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hub.AllowOrigins(tt.allowed)
+			if got := hub.checkOrigin(request(tt.host, tt.origin)); got != tt.want {
+				t.Errorf("checkOrigin() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHub_RejectsDisallowedOrigin(t *testing.T) {
+	hub := setupTestHub(t)
+	hub.AllowOrigins([]string{"https://dash.example.com"})
+
+	server := httptest.NewServer(http.HandlerFunc(hub.HandleWebSocket))
+	defer server.Close()
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	header := http.Header{"Origin": []string{"https://evil.example.com"}}
+	_, resp, err := websocket.DefaultDialer.Dial(wsURL, header)
+	if err == nil {
+		t.Fatal("expected upgrade from disallowed origin to fail")
+	}
+	if resp == nil || resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expected 403 response, got %v", resp)
+	}
+
+	header.Set("Origin", "https://dash.example.com")
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, header)
+	if err != nil {
+		t.Fatalf("expected allowlisted origin to connect: %v", err)
+	}
+	conn.Close()
+}
@@ -0,0 +1,81 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// EventRelayChannel is the Redis pub/sub channel every instance's hub
+// publishes its broadcasts to and relays from.
+const EventRelayChannel = "delivery_events:live"
+
+// relayMessage is the envelope published on EventRelayChannel. Origin lets
+// an instance skip its own messages, which it has already delivered locally.
+type relayMessage struct {
+	Origin string          `json:"origin"`
+	ID     string          `json:"id,omitempty"`
+	Event  json.RawMessage `json:"event"`
+}
+
+// Relay shares broadcasts between server instances over Redis pub/sub, so a
+// dashboard connected to any replica sees deliveries made by all of them.
+// Pub/sub is fire-and-forget: events published while an instance is
+// disconnected from Redis are not relayed to it, though SSE clients can
+// still recover them from the event stream.
+type Relay struct {
+	client *redis.Client
+	origin string
+	logger *slog.Logger
+}
+
+// NewRelay creates a relay. origin must be unique per running instance.
+func NewRelay(client *redis.Client, origin string, logger *slog.Logger) *Relay {
+	return &Relay{client: client, origin: origin, logger: logger}
+}
+
+// Publish sends an encoded event and its stream ID to the other instances.
+func (r *Relay) Publish(ctx context.Context, id string, data []byte) error {
+	msg, err := json.Marshal(relayMessage{Origin: r.origin, ID: id, Event: data})
+	if err != nil {
+		return fmt.Errorf("encoding relay message: %w", err)
+	}
+	if err := r.client.Publish(ctx, EventRelayChannel, msg).Err(); err != nil {
+		return fmt.Errorf("publishing relay message: %w", err)
+	}
+	return nil
+}
+
+// Start delivers events published by other instances to the hub's clients
+// until the context is cancelled. The Redis client resubscribes on its own
+// after a dropped connection.
+func (r *Relay) Start(ctx context.Context, hub *Hub) {
+	sub := r.client.Subscribe(ctx, EventRelayChannel)
+	defer sub.Close()
+
+	r.logger.Info("websocket relay started", "origin", r.origin)
+
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case m, ok := <-ch:
+			if !ok {
+				return
+			}
+			var msg relayMessage
+			if err := json.Unmarshal([]byte(m.Payload), &msg); err != nil {
+				r.logger.Warn("failed to decode relay message", "error", err)
+				continue
+			}
+			if msg.Origin == r.origin {
+				continue
+			}
+			hub.deliverRemote(msg.ID, msg.Event)
+		}
+	}
+}
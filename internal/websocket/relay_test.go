@@ -0,0 +1,73 @@
+package websocket
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestRelay_BroadcastReachesOtherInstances(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	newInstance := func(origin string) *Hub {
+		hub := NewHub(NewEventStream(rdb, 100), nil, logger)
+		relay := NewRelay(rdb, origin, logger)
+		hub.UseRelay(relay)
+		go hub.Run()
+		go relay.Start(ctx, hub)
+		return hub
+	}
+	hubA := newInstance("instance-a")
+	hubB := newInstance("instance-b")
+
+	connA, cleanupA := connectWS(t, hubA)
+	defer cleanupA()
+	connB, cleanupB := connectWS(t, hubB)
+	defer cleanupB()
+
+	// Wait for both relays to subscribe and both clients to register
+	deadline := time.Now().Add(2 * time.Second)
+	for mr.PubSubNumSub(EventRelayChannel)[EventRelayChannel] < 2 || hubA.ClientCount() < 1 || hubB.ClientCount() < 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("relays or clients did not start")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	hubA.Broadcast(DeliveryEvent{Type: "delivery_success", EventID: "evt-1"})
+	hubA.Broadcast(DeliveryEvent{Type: "delivery_failed", EventID: "evt-2"})
+
+	for name, conn := range map[string]interface {
+		SetReadDeadline(time.Time) error
+		ReadMessage() (int, []byte, error)
+	}{"local": connA, "remote": connB} {
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		for _, want := range []string{"evt-1", "evt-2"} {
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				t.Fatalf("%s client: failed to read message: %v", name, err)
+			}
+			if !strings.Contains(string(message), want) {
+				t.Errorf("%s client: expected %s, got %s", name, want, message)
+			}
+		}
+
+		// The publishing instance must not deliver its own events twice
+		conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+		if _, message, err := conn.ReadMessage(); err == nil {
+			t.Errorf("%s client: unexpected extra message %s", name, message)
+		}
+	}
+}
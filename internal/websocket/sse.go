@@ -0,0 +1,117 @@
+package websocket
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// sseHeartbeatInterval keeps idle SSE connections open through proxies.
+const sseHeartbeatInterval = 15 * time.Second
+
+// HandleSSE streams delivery events as Server-Sent Events. It accepts the
+// same filter as the WebSocket subscribe message via ?subscriber_id= and
+// ?types=a,b. Clients that reconnect with a Last-Event-ID header (or
+// ?last_event_id=) first receive any buffered events they missed.
+func (h *Hub) HandleSSE(w http.ResponseWriter, r *http.Request) {
+	rc := http.NewResponseController(w)
+
+	filter := &Filter{SubscriberID: r.URL.Query().Get("subscriber_id")}
+	if types := r.URL.Query().Get("types"); types != "" {
+		filter.Types = strings.Split(types, ",")
+	}
+
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = r.URL.Query().Get("last_event_id")
+	}
+
+	// Register before replaying so nothing broadcast in between is missed;
+	// duplicates are skipped by comparing stream IDs below
+	l := h.subscribe(filter)
+	defer h.removeListener(l)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	// The server's WriteTimeout would otherwise cut the stream off
+	rc.SetWriteDeadline(time.Time{})
+
+	if h.stream != nil && isStreamID(lastID) {
+		missed, err := h.stream.Since(r.Context(), lastID)
+		if err != nil {
+			h.logger.Warn("failed to replay event stream", "error", err)
+		}
+		for _, e := range missed {
+			if !filter.Matches(e.Event) {
+				continue
+			}
+			if err := writeSSE(w, e.ID, e.Data); err != nil {
+				return
+			}
+			lastID = e.ID
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+
+		case msg, ok := <-l.ch:
+			if !ok {
+				// Dropped for falling behind; the client will reconnect and resume
+				return
+			}
+			// Only skip what the replay already sent. Live events from other
+			// instances can arrive slightly out of stream order
+			if msg.id != "" && lastID != "" && !streamIDAfter(msg.id, lastID) {
+				continue
+			}
+			if err := writeSSE(w, msg.id, msg.data); err != nil {
+				return
+			}
+			rc.Flush()
+
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			rc.Flush()
+		}
+	}
+}
+
+func writeSSE(w http.ResponseWriter, id string, data []byte) error {
+	if id != "" {
+		if _, err := fmt.Fprintf(w, "id: %s\n", id); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "data: %s\n\n", data)
+	return err
+}
+
+// isStreamID reports whether s looks like a Redis stream ID ("<ms>-<seq>").
+func isStreamID(s string) bool {
+	ms, seq, ok := strings.Cut(s, "-")
+	if !ok || ms == "" || seq == "" {
+		return false
+	}
+	for _, c := range ms + seq {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
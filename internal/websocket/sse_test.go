@@ -0,0 +1,81 @@
+package websocket
+
+import (
+	"bufio"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestHandleSSE_ResumesFromLastEventID(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	hub := NewHub(NewEventStream(rdb, 100), nil, logger)
+	go hub.Run()
+
+	hub.Broadcast(DeliveryEvent{Type: "delivery_success", EventID: "evt-seen"})
+	hub.Broadcast(DeliveryEvent{Type: "delivery_failed", EventID: "evt-missed"})
+
+	entries, err := hub.stream.Since(context.Background(), "0-0")
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("expected 2 buffered events, got %d (err=%v)", len(entries), err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(hub.HandleSSE))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("Last-Event-ID", entries[0].ID)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("SSE request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q, want text/event-stream", ct)
+	}
+
+	lines := make(chan string, 16)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if line := scanner.Text(); strings.HasPrefix(line, "data: ") {
+				lines <- line
+			}
+		}
+	}()
+
+	next := func() string {
+		select {
+		case line := <-lines:
+			return line
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for SSE event")
+			return ""
+		}
+	}
+
+	// Replayed event the client missed, not the one it had already seen
+	if line := next(); !strings.Contains(line, "evt-missed") {
+		t.Fatalf("expected replay of evt-missed, got %s", line)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	hub.Broadcast(DeliveryEvent{Type: "delivery_success", EventID: "evt-live"})
+
+	if line := next(); !strings.Contains(line, "evt-live") {
+		t.Fatalf("expected live evt-live, got %s", line)
+	}
+}
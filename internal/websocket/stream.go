@@ -0,0 +1,90 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// EventStreamKey is a capped Redis stream holding the most recent delivery
+// events. Its entry IDs are used as SSE event IDs, so clients can resume
+// with Last-Event-ID after a reconnect.
+const EventStreamKey = "delivery_events"
+
+// EventStream is a short ring buffer of delivery events in Redis.
+type EventStream struct {
+	client *redis.Client
+	maxLen int64
+}
+
+// NewEventStream creates a ring buffer that keeps roughly maxLen events.
+func NewEventStream(client *redis.Client, maxLen int64) *EventStream {
+	return &EventStream{client: client, maxLen: maxLen}
+}
+
+// Append records an encoded event and returns its stream ID.
+func (s *EventStream) Append(ctx context.Context, data []byte) (string, error) {
+	id, err := s.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: EventStreamKey,
+		MaxLen: s.maxLen,
+		Approx: true,
+		Values: map[string]interface{}{"data": data},
+	}).Result()
+	if err != nil {
+		return "", fmt.Errorf("appending to event stream: %w", err)
+	}
+	return id, nil
+}
+
+// streamEntry is a buffered event and its stream ID.
+type streamEntry struct {
+	ID    string
+	Event DeliveryEvent
+	Data  []byte
+}
+
+// Since returns buffered events recorded after the given ID, oldest first.
+// If the ID has already been trimmed from the buffer, everything still
+// buffered is returned.
+func (s *EventStream) Since(ctx context.Context, lastID string) ([]streamEntry, error) {
+	msgs, err := s.client.XRange(ctx, EventStreamKey, lastID, "+").Result()
+	if err != nil {
+		return nil, fmt.Errorf("reading event stream: %w", err)
+	}
+
+	entries := make([]streamEntry, 0, len(msgs))
+	for _, m := range msgs {
+		if m.ID == lastID {
+			continue
+		}
+		raw, _ := m.Values["data"].(string)
+		var event DeliveryEvent
+		if err := json.Unmarshal([]byte(raw), &event); err != nil {
+			continue
+		}
+		entries = append(entries, streamEntry{ID: m.ID, Event: event, Data: []byte(raw)})
+	}
+	return entries, nil
+}
+
+// streamIDAfter reports whether stream ID a sorts after b. IDs have the form
+// "<ms>-<seq>"; malformed IDs sort first.
+func streamIDAfter(a, b string) bool {
+	am, as := parseStreamID(a)
+	bm, bs := parseStreamID(b)
+	if am != bm {
+		return am > bm
+	}
+	return as > bs
+}
+
+func parseStreamID(id string) (ms, seq uint64) {
+	msPart, seqPart, _ := strings.Cut(id, "-")
+	ms, _ = strconv.ParseUint(msPart, 10, 64)
+	seq, _ = strconv.ParseUint(seqPart, 10, 64)
+	return ms, seq
+}
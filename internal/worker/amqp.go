@@ -0,0 +1,181 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// amqpBroker is the connection kept to one broker, virtual host and user,
+// shared by every delivery to it. Each delivery opens its own channel.
+type amqpBroker struct {
+	mu   sync.Mutex
+	conn *amqp.Connection
+}
+
+// pushAMQP publishes the payload to job's exchange and waits for the
+// broker to confirm it. The routing key defaults to the event type.
+// Messages are published mandatory and persistent: one the exchange can't
+// route anywhere is returned and fails the attempt rather than being
+// dropped. The broker's answer is recorded as the closest HTTP status:
+// 200 once confirmed, 404 for an unroutable message or a missing exchange,
+// 403 when access is refused, and 503 for a nack or any other refusal.
+func (d *Deliverer) pushAMQP(job *engine.DeliveryJob, req *http.Request, body []byte) (*http.Response, error) {
+	endpoint, err := domain.ParseAMQPEndpoint(job.EndpointURL)
+	if err != nil {
+		return nil, err
+	}
+	creds := job.EndpointCredentials
+	if creds == nil {
+		creds = &domain.EndpointCredentials{}
+	}
+	routingKey := endpoint.RoutingKey
+	if routingKey == "" {
+		routingKey = job.EventType
+	}
+	ctx := req.Context()
+	if d.httpClient.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.httpClient.Timeout)
+		defer cancel()
+	}
+
+	conn, err := d.queues.amqpConnection(ctx, endpoint.URL, creds, d.httpClient.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	ch, err := conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("opening channel: %w", err)
+	}
+	defer ch.Close()
+	if err := ch.Confirm(false); err != nil {
+		return nil, fmt.Errorf("enabling publisher confirms: %w", err)
+	}
+	// Buffered so the broker's reply is kept until it is read here
+	returned := ch.NotifyReturn(make(chan amqp.Return, 1))
+	closed := ch.NotifyClose(make(chan *amqp.Error, 1))
+
+	headers := amqp.Table{}
+	for name, value := range messageAttributes(req.Header) {
+		headers[name] = value
+	}
+	confirm, err := ch.PublishWithDeferredConfirmWithContext(ctx, endpoint.Exchange, routingKey, true, false, amqp.Publishing{
+		Headers:      headers,
+		ContentType:  req.Header.Get("Content-Type"),
+		DeliveryMode: amqp.Persistent,
+		MessageId:    job.EventID,
+		Timestamp:    time.Now(),
+		Type:         job.EventType,
+		Body:         body,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("publishing: %w", err)
+	}
+	acked, err := confirm.WaitContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// A return arrives ahead of the confirm of the same message
+	select {
+	case r := <-returned:
+		return syntheticResponse(req, http.StatusNotFound, fmt.Sprintf("message returned by the broker: %d %s", r.ReplyCode, r.ReplyText)), nil
+	default:
+	}
+	if acked {
+		return syntheticResponse(req, http.StatusOK, ""), nil
+	}
+	select {
+	case amqpErr := <-closed:
+		if amqpErr != nil {
+			return syntheticResponse(req, amqpStatus(amqpErr.Code), fmt.Sprintf("refused by the broker: %d %s", amqpErr.Code, amqpErr.Reason)), nil
+		}
+	default:
+	}
+	return syntheticResponse(req, http.StatusServiceUnavailable, "message nacked by the broker"), nil
+}
+
+// amqpStatus maps an AMQP reply code to the closest HTTP status.
+func amqpStatus(code int) int {
+	switch code {
+	case amqp.AccessRefused:
+		return http.StatusForbidden
+	case amqp.NotFound, amqp.NoRoute:
+		return http.StatusNotFound
+	default:
+		return http.StatusServiceUnavailable
+	}
+}
+
+// amqpConnection returns the open connection to the broker at brokerURL
+// as creds' user, dialling it if there is none yet or the last one was
+// closed. timeout bounds the TCP, TLS and AMQP handshakes.
+func (p *queuePushers) amqpConnection(ctx context.Context, brokerURL string, creds *domain.EndpointCredentials, timeout time.Duration) (*amqp.Connection, error) {
+	id := brokerURL + "\x00" + creds.Username + "\x00" + creds.Password
+	p.mu.Lock()
+	broker := p.brokers[id]
+	if broker == nil {
+		broker = &amqpBroker{}
+		if p.brokers == nil {
+			p.brokers = map[string]*amqpBroker{}
+		}
+		p.brokers[id] = broker
+	}
+	p.mu.Unlock()
+
+	// Deliveries to other brokers don't wait on this one's dial
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+	if broker.conn != nil && !broker.conn.IsClosed() {
+		return broker.conn, nil
+	}
+
+	dial := p.dial
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+	config := amqp.Config{
+		Dial: func(network, addr string) (net.Conn, error) {
+			conn, err := dial(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+			// Cleared by the library once the AMQP handshake is done
+			if timeout > 0 {
+				conn.SetDeadline(time.Now().Add(timeout))
+			}
+			return conn, nil
+		},
+	}
+	if creds.Username != "" || creds.Password != "" {
+		config.SASL = []amqp.Authentication{&amqp.PlainAuth{Username: creds.Username, Password: creds.Password}}
+	}
+	conn, err := amqp.DialConfig(brokerURL, config)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to broker: %w", err)
+	}
+	broker.conn = conn
+	return conn, nil
+}
+
+// close closes the connections kept to AMQP brokers.
+func (p *queuePushers) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for id, broker := range p.brokers {
+		broker.mu.Lock()
+		if broker.conn != nil {
+			broker.conn.Close()
+		}
+		broker.mu.Unlock()
+		delete(p.brokers, id)
+	}
+}
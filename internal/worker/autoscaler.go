@@ -0,0 +1,156 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+)
+
+// defaultLatency is assumed before any delivery has completed.
+const defaultLatency = 100 * time.Millisecond
+
+// AutoscalerConfig bounds and tunes worker pool autoscaling.
+type AutoscalerConfig struct {
+	MinWorkers int
+	MaxWorkers int
+	Interval   time.Duration // how often to re-evaluate the pool size
+	DrainTime  time.Duration // how quickly the ready backlog should be cleared
+	Clock      engine.Clock  // decides which queued jobs are ready; nil means the local clock
+}
+
+// Autoscaler resizes a Pool between MinWorkers and MaxWorkers.
+//
+// Each interval it estimates how many workers are needed to clear the ready
+// backlog within DrainTime given the pool's average delivery latency:
+//
+//	needed = busy + ceil(backlog × avg_latency / DrainTime)
+//
+// Scale-ups apply immediately so bursts are absorbed quickly. Scale-downs
+// remove at most a quarter of the pool per interval so a brief lull between
+// batches doesn't tear down workers that are about to be needed again.
+//
+// With equal bounds the pool is held at that size, so a pool can be switched
+// between fixed and autoscaled at runtime with SetBounds.
+type Autoscaler struct {
+	pool   *Pool
+	queue  engine.Queue
+	logger *slog.Logger
+
+	mu  sync.Mutex
+	cfg AutoscalerConfig
+}
+
+func NewAutoscaler(pool *Pool, queue engine.Queue, cfg AutoscalerConfig, logger *slog.Logger) *Autoscaler {
+	if cfg.Clock == nil {
+		cfg.Clock = engine.SystemClock{}
+	}
+	return &Autoscaler{
+		pool:   pool,
+		queue:  queue,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// SetBounds changes the pool size limits and moves the pool inside them
+// immediately rather than at the next interval.
+func (a *Autoscaler) SetBounds(minWorkers, maxWorkers int) {
+	a.mu.Lock()
+	a.cfg.MinWorkers = minWorkers
+	a.cfg.MaxWorkers = maxWorkers
+	a.mu.Unlock()
+
+	if size := a.pool.Size(); size < minWorkers || size > maxWorkers {
+		a.pool.Resize(max(minWorkers, min(maxWorkers, size)))
+	}
+}
+
+// Bounds returns the current pool size limits.
+func (a *Autoscaler) Bounds() (minWorkers, maxWorkers int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.cfg.MinWorkers, a.cfg.MaxWorkers
+}
+
+// Start evaluates the pool size on every interval until the context is
+// cancelled.
+func (a *Autoscaler) Start(ctx context.Context) {
+	minWorkers, maxWorkers := a.Bounds()
+	a.logger.Info("worker autoscaler started",
+		"min_workers", minWorkers,
+		"max_workers", maxWorkers,
+	)
+
+	ticker := time.NewTicker(a.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			a.logger.Info("worker autoscaler stopping")
+			return
+		case <-ticker.C:
+			a.evaluate(ctx)
+		}
+	}
+}
+
+func (a *Autoscaler) evaluate(ctx context.Context) {
+	// A fixed-size pool needs no queue reads
+	if minWorkers, maxWorkers := a.Bounds(); minWorkers == maxWorkers {
+		return
+	}
+
+	backlog, err := a.queue.ReadyCount(ctx, a.cfg.Clock.Now())
+	if err != nil {
+		if ctx.Err() == nil {
+			a.logger.Error("failed to read queue depth for autoscaling", "error", err)
+		}
+		return
+	}
+
+	latency := a.pool.AvgLatency()
+	if latency == 0 {
+		latency = defaultLatency
+	}
+
+	current := a.pool.Size()
+	desired := a.desiredSize(current, a.pool.Busy(), backlog, latency)
+	if desired == current {
+		return
+	}
+
+	direction := "up"
+	if desired < current {
+		direction = "down"
+	}
+	a.logger.Info("scaling worker pool",
+		"direction", direction,
+		"from", current,
+		"to", desired,
+		"ready_backlog", backlog,
+		"avg_latency_ms", latency.Milliseconds(),
+	)
+	a.pool.Resize(desired)
+}
+
+// desiredSize returns the pool size to move to from current.
+func (a *Autoscaler) desiredSize(current, busy int, backlog int64, latency time.Duration) int {
+	a.mu.Lock()
+	cfg := a.cfg
+	a.mu.Unlock()
+
+	extra := math.Ceil(float64(backlog) * latency.Seconds() / cfg.DrainTime.Seconds())
+	needed := busy + int(extra)
+	needed = max(cfg.MinWorkers, min(cfg.MaxWorkers, needed))
+
+	if needed < current {
+		step := max(1, current/4)
+		needed = max(needed, current-step)
+	}
+	return needed
+}
@@ -0,0 +1,67 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestAutoscaler_DesiredSize(t *testing.T) {
+	a := &Autoscaler{cfg: AutoscalerConfig{MinWorkers: 5, MaxWorkers: 100, DrainTime: 10 * time.Second}}
+
+	tests := []struct {
+		name    string
+		current int
+		busy    int
+		backlog int64
+		latency time.Duration
+		want    int
+	}{
+		{"idle stays at min", 5, 0, 0, 100 * time.Millisecond, 5},
+		// 1000 jobs × 200ms / 10s = 20 extra workers
+		{"backlog scales up", 5, 5, 1000, 200 * time.Millisecond, 25},
+		{"capped at max", 5, 5, 100000, time.Second, 100},
+		{"shrinks by at most a quarter", 80, 0, 0, 100 * time.Millisecond, 60},
+		{"small pools shrink by one", 6, 0, 0, 100 * time.Millisecond, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := a.desiredSize(tt.current, tt.busy, tt.backlog, tt.latency); got != tt.want {
+				t.Errorf("desiredSize = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPool_Resize(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	pool := NewPool(2, nil, nil, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	pool.Start(ctx)
+
+	pool.Resize(6)
+	pool.Resize(3)
+	pool.Resize(4)
+
+	stats := pool.Stats()
+	if stats.Workers != 4 || stats.ScaleUps != 2 || stats.ScaleDowns != 1 {
+		t.Errorf("stats = %+v, want 4 workers after 2 scale-ups and 1 scale-down", stats)
+	}
+
+	// Every remaining worker must exit on cancellation for Drain to return
+	cancel()
+	done := make(chan struct{})
+	go func() {
+		pool.Drain()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("workers did not exit after cancellation")
+	}
+}
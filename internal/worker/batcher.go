@@ -0,0 +1,385 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+)
+
+// Batch delivery headers. A batch has no single event, so X-Webhook-Event
+// and X-Webhook-ID are left out; each element of the body carries its own.
+const (
+	batchIDHeader   = "X-Webhook-Batch-ID"
+	batchSizeHeader = "X-Webhook-Batch-Size"
+)
+
+// batchItem is one event in the JSON array body of a batched delivery.
+type batchItem struct {
+	ID         string          `json:"id"`
+	DeliveryID string          `json:"delivery_id"`
+	EventType  string          `json:"event_type"`
+	Version    int             `json:"version"`
+	Attempt    int             `json:"attempt"`
+	Payload    json.RawMessage `json:"payload"`
+}
+
+// Batcher collects the deliveries of subscribers that take batches and
+// sends each subscriber's jobs in one request once BatchMaxEvents of them
+// have gathered or the first has waited BatchWindowMs. Buffered jobs keep
+// their queue claims until their batch has been sent, so the jobs buffered
+// by an instance that dies are recovered like any other claimed job.
+type Batcher struct {
+	deliverer *Deliverer
+	queue     engine.Queue
+	logger    *slog.Logger
+
+	mu      sync.Mutex
+	pending map[string]*pendingBatch // by batchKey
+	stopped bool
+	sending sync.WaitGroup
+}
+
+type pendingBatch struct {
+	jobs  []engine.DeliveryJob
+	timer *time.Timer
+}
+
+// batchKey identifies the batch a job joins. Jobs queued before the
+// subscriber's endpoint changed are batched apart from newer ones, so they
+// are still sent to the endpoint they were queued for.
+func batchKey(job engine.DeliveryJob) string {
+	return job.SubscriberID + " " + job.EndpointURL
+}
+
+// NewBatcher creates a batcher that sends through d and acknowledges sent
+// jobs on queue. A nil queue (in tests) skips acknowledgement.
+func NewBatcher(d *Deliverer, queue engine.Queue, logger *slog.Logger) *Batcher {
+	return &Batcher{
+		deliverer: d,
+		queue:     queue,
+		logger:    logger,
+		pending:   make(map[string]*pendingBatch),
+	}
+}
+
+// Add buffers a job for its subscriber's next batch. The batcher takes over
+// the job's claim. If the job fills the batch, the batch is sent before Add
+// returns, on the caller's goroutine; otherwise it is sent from a timer
+// when its window closes.
+func (b *Batcher) Add(ctx context.Context, job engine.DeliveryJob) {
+	b.mu.Lock()
+	if b.stopped {
+		b.mu.Unlock()
+		b.returnJobs([]engine.DeliveryJob{job})
+		return
+	}
+
+	key := batchKey(job)
+	batch := b.pending[key]
+	if batch == nil {
+		batch = &pendingBatch{}
+		b.pending[key] = batch
+		window := time.Duration(job.BatchWindowMs) * time.Millisecond
+		batch.timer = time.AfterFunc(window, func() { b.flush(key, batch) })
+	}
+	batch.jobs = append(batch.jobs, job)
+	if len(batch.jobs) < job.BatchMaxEvents {
+		b.mu.Unlock()
+		return
+	}
+
+	batch.timer.Stop()
+	delete(b.pending, key)
+	b.sending.Add(1)
+	b.mu.Unlock()
+
+	defer b.sending.Done()
+	b.send(ctx, batch.jobs)
+}
+
+// flush sends a batch whose window has closed, unless it was already sent
+// because it filled up or handed back by Stop.
+func (b *Batcher) flush(key string, batch *pendingBatch) {
+	b.mu.Lock()
+	if b.pending[key] != batch {
+		b.mu.Unlock()
+		return
+	}
+	delete(b.pending, key)
+	b.sending.Add(1)
+	b.mu.Unlock()
+
+	defer b.sending.Done()
+	b.send(context.Background(), batch.jobs)
+}
+
+// send delivers a batch and releases the claims of its jobs. Any retries
+// have been queued by the time DeliverBatch returns.
+func (b *Batcher) send(ctx context.Context, jobs []engine.DeliveryJob) {
+	b.deliverer.DeliverBatch(ctx, jobs)
+	if b.queue == nil {
+		return
+	}
+	for _, job := range jobs {
+		if job.Claim == "" {
+			continue
+		}
+		if err := b.queue.Ack(ctx, job.Claim); err != nil {
+			b.logger.Error("failed to acknowledge job", "error", err, "event_id", job.EventID)
+		}
+	}
+}
+
+// Buffered returns the number of jobs waiting for their batch to be sent.
+func (b *Batcher) Buffered() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	n := 0
+	for _, batch := range b.pending {
+		n += len(batch.jobs)
+	}
+	return n
+}
+
+// Stop waits for batches being sent and hands every buffered job back to
+// the delivery queue at its original ready time, so another instance (or
+// this one after a restart) batches it again. Jobs added afterwards are
+// handed back straight away. Returns the number of jobs handed back.
+func (b *Batcher) Stop() int {
+	b.mu.Lock()
+	b.stopped = true
+	pending := b.pending
+	b.pending = make(map[string]*pendingBatch)
+	b.mu.Unlock()
+
+	returned := 0
+	for _, batch := range pending {
+		batch.timer.Stop()
+		b.returnJobs(batch.jobs)
+		returned += len(batch.jobs)
+	}
+	b.sending.Wait()
+	return returned
+}
+
+func (b *Batcher) returnJobs(jobs []engine.DeliveryJob) {
+	if b.queue == nil {
+		return
+	}
+	for _, job := range jobs {
+		if job.Claim == "" {
+			continue
+		}
+		if err := b.queue.Return(context.Background(), job.Claim); err != nil {
+			b.logger.Error("failed to return job to queue", "error", err, "event_id", job.EventID)
+		}
+	}
+}
+
+// DeliverBatch sends jobs for one subscriber in a single request whose body
+// is a JSON array of the events, signed as a whole. The batch counts as one
+// request against the circuit breaker and rate limiter, and its outcome is
+// recorded as an attempt of every job in it: on failure each job is retried
+// or dead-lettered on its own, and retries due together are batched again.
+func (d *Deliverer) DeliverBatch(ctx context.Context, jobs []engine.DeliveryJob) {
+	// Set aside the jobs of a paused subscriber and drop those already
+	// delivered, as Deliver does
+	var ready []engine.DeliveryJob
+	var deliveryIDs []string
+	for _, job := range jobs {
+		job.DeliveryID = store.NewDeliveryID()
+		job.Probe = false
+		parked, err := d.queue.ParkIfPaused(ctx, job)
+		if err != nil {
+			d.jobLogger(job).Error("failed to check subscriber pause", "error", err)
+		}
+		if parked {
+			d.logDebug(ctx, job, "subscriber paused, parking job")
+			continue
+		}
+		if d.alreadyDelivered(ctx, job) {
+			continue
+		}
+		ready = append(ready, job)
+		deliveryIDs = append(deliveryIDs, job.DeliveryID)
+	}
+	if len(ready) == 0 {
+		return
+	}
+
+	// The latest job carries the subscriber's current settings
+	last := ready[len(ready)-1]
+
+	if state, allowed := d.circuitBreaker.AllowRequest(ctx, last.SubscriberID); !allowed {
+		d.logger.Warn("circuit breaker open, re-queuing batch",
+			"subscriber_id", last.SubscriberID,
+			"delivery_ids", deliveryIDs,
+			"state", state,
+		)
+		// The oldest job becomes the probe, as Deliver does for single jobs
+		at, probe := d.circuitBreaker.ScheduleProbe(ctx, last.SubscriberID)
+		for i, job := range ready {
+			if i == 0 && probe {
+				job.Probe = true
+				d.requeueWithDelay(ctx, job, at.Sub(d.now()))
+				continue
+			}
+			d.requeueWithDelay(ctx, job, 5*time.Second)
+		}
+		return
+	}
+	if !d.rateLimiter.Allow(ctx, last.SubscriberID, last.RateLimitPerSecond) {
+		d.logger.Debug("rate limited, re-queuing batch",
+			"subscriber_id", last.SubscriberID,
+			"delivery_ids", deliveryIDs,
+		)
+		for _, job := range ready {
+			d.requeueWithDelay(ctx, job, 1*time.Second)
+		}
+		return
+	}
+	if ok, resetAt := d.quota.Take(ctx, last.SubscriberID, last.DailyQuota, last.MonthlyQuota, len(ready)); !ok {
+		d.logger.Debug("delivery quota used up, re-queuing batch until it resets",
+			"subscriber_id", last.SubscriberID,
+			"delivery_ids", deliveryIDs,
+			"resets_at", resetAt,
+		)
+		for _, job := range ready {
+			d.requeueWithDelay(ctx, job, resetAt.Sub(d.now()))
+		}
+		return
+	}
+
+	start := time.Now()
+	batch := make([]engine.DeliveryJob, 0, len(ready))
+	items := make([]batchItem, 0, len(ready))
+	for _, job := range ready {
+		payload, err := d.loadPayload(ctx, job)
+		if err != nil {
+			d.handleFailure(ctx, job, start, nil, "", nil, domain.FailureInternal, fmt.Sprintf("failed to resolve payload: %v", err))
+			continue
+		}
+		payload, version, err := d.versionedPayload(job, payload)
+		if err != nil {
+			d.handleFailure(ctx, job, start, nil, "", nil, domain.FailureInternal, fmt.Sprintf("failed to convert payload: %v", err))
+			continue
+		}
+		job.PayloadBytes = len(payload)
+		batch = append(batch, job)
+		items = append(items, batchItem{
+			ID:         job.EventID,
+			DeliveryID: job.DeliveryID,
+			EventType:  job.EventType,
+			Version:    version,
+			Attempt:    job.Attempt,
+			Payload:    payload,
+		})
+	}
+	if len(batch) == 0 {
+		return
+	}
+
+	fail := func(statusCode *int, responseBody string, responseHeaders map[string]string, reason domain.FailureReason, errMsg string) {
+		d.circuitBreaker.RecordFailure(ctx, last.SubscriberID)
+		for _, job := range batch {
+			d.handleFailure(ctx, job, start, statusCode, responseBody, responseHeaders, reason, errMsg)
+		}
+	}
+
+	body, err := json.Marshal(items)
+	if err != nil {
+		fail(nil, "", nil, domain.FailureInternal, fmt.Sprintf("failed to encode batch: %v", err))
+		return
+	}
+	reqBody, compressed := d.compress(body, last)
+
+	// Every job of the batch is recorded with the batch request's timings
+	trace := &attemptTrace{}
+	ctx = withAttemptTrace(ctx, trace)
+
+	req, err := newDeliveryRequest(trace.requestContext(ctx), last, reqBody)
+	if err != nil {
+		fail(nil, "", nil, domain.FailureInternal, fmt.Sprintf("failed to create request: %v", err))
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if compressed {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	batchID := store.NewDeliveryID()
+	resign := func(h http.Header) error {
+		return sign(h, last.SignatureFormat, signedMessage{
+			ID:        batchID,
+			Payload:   body,
+			Secret:    last.SecretKey,
+			Timestamp: time.Now(),
+		})
+	}
+	if err := resign(req.Header); err != nil {
+		for _, job := range batch {
+			d.handleFailure(ctx, job, start, nil, "", nil, domain.FailureInternal, fmt.Sprintf("failed to sign batch: %v", err))
+		}
+		return
+	}
+	req.Header.Set(batchIDHeader, batchID)
+	req.Header.Set(batchSizeHeader, strconv.Itoa(len(batch)))
+
+	if last.Sandbox {
+		for _, job := range batch {
+			d.handleSimulated(ctx, job, start)
+		}
+		return
+	}
+
+	if last.DebugLogging {
+		d.logger.Info("sending delivery batch",
+			"subscriber_id", last.SubscriberID,
+			"delivery_ids", deliveryIDs,
+			"endpoint_url", last.EndpointURL,
+			"body_bytes", len(body),
+			"compressed", compressed,
+			"debug", true,
+		)
+	}
+
+	resp, err := d.send(&last, req, resign)
+	for i := range batch {
+		batch[i].FinalURL = last.FinalURL
+	}
+	if err != nil {
+		fail(nil, "", nil, classifyError(err), fmt.Sprintf("request failed: %v", err))
+		return
+	}
+	defer resp.Body.Close()
+
+	unmet, respBody := checkResponse(resp, last)
+	responseBody := d.readResponseBody(respBody, last)
+	trace.readBody()
+	responseHeaders := captureHeaders(resp.Header, d.captureHeaders)
+	for name, value := range responseHeaders {
+		responseHeaders[name] = d.redactor.Redact(value)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		fail(&resp.StatusCode, responseBody, responseHeaders, domain.StatusFailureReason(resp.StatusCode), "")
+		return
+	}
+	if unmet != "" {
+		fail(&resp.StatusCode, responseBody, responseHeaders, domain.FailureUnexpectedBody, unmet)
+		return
+	}
+	d.circuitBreaker.RecordSuccess(ctx, last.SubscriberID)
+	for _, job := range batch {
+		d.handleSuccess(ctx, job, start, resp.StatusCode, responseBody, responseHeaders)
+	}
+}
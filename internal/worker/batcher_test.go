@@ -0,0 +1,195 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+	"github.com/Priya8975/webhook-delivery-system/pkg/webhook"
+)
+
+// batchServer records the events of each batch it receives, after checking
+// its signature.
+type batchServer struct {
+	*httptest.Server
+	status int
+
+	mu      sync.Mutex
+	batches [][]webhook.BatchEvent
+	errs    []error
+}
+
+func newBatchServer(t *testing.T, status int) *batchServer {
+	t.Helper()
+	s := &batchServer{status: status}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		payload, err := webhook.VerifyRequest(r, "secret")
+		if err == nil && !webhook.IsBatch(r) {
+			err = fmt.Errorf("request without %s", webhook.BatchSizeHeader)
+		}
+		var events []webhook.BatchEvent
+		if err == nil {
+			events, err = webhook.ParseBatch(payload)
+		}
+		if err != nil {
+			s.errs = append(s.errs, err)
+		}
+		s.batches = append(s.batches, events)
+		w.WriteHeader(s.status)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *batchServer) received(t *testing.T) [][]webhook.BatchEvent {
+	t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, err := range s.errs {
+		t.Errorf("invalid batch: %v", err)
+	}
+	s.errs = nil
+	return append([][]webhook.BatchEvent(nil), s.batches...)
+}
+
+func newTestBatcher(t *testing.T, queue engine.Queue) *Batcher {
+	t.Helper()
+	_, cb, rl, hub, logger := setupDeliveryTest(t)
+	d := &Deliverer{
+		httpClient:     &http.Client{Timeout: 5 * time.Second},
+		queue:          queue,
+		circuitBreaker: cb,
+		rateLimiter:    rl,
+		hub:            hub,
+		logger:         logger,
+	}
+	return NewBatcher(d, nil, logger)
+}
+
+func batchJob(url string, i, maxEvents int, window time.Duration) engine.DeliveryJob {
+	return engine.DeliveryJob{
+		EventID:        fmt.Sprintf("evt-%d", i),
+		SubscriberID:   "sub-batch",
+		EndpointURL:    url,
+		Payload:        json.RawMessage(fmt.Sprintf(`{"n":%d}`, i)),
+		SecretKey:      "secret",
+		EventType:      "metric.recorded",
+		Attempt:        1,
+		MaxRetries:     5,
+		BatchMaxEvents: maxEvents,
+		BatchWindowMs:  int(window.Milliseconds()),
+	}
+}
+
+func TestBatcher_SendsFullBatch(t *testing.T) {
+	server := newBatchServer(t, http.StatusOK)
+	b := newTestBatcher(t, engine.NewMemoryQueue())
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		b.Add(ctx, batchJob(server.URL, i, 3, time.Minute))
+	}
+
+	batches := server.received(t)
+	if len(batches) != 1 || len(batches[0]) != 3 {
+		t.Fatalf("expected one batch of 3 events, got %+v", batches)
+	}
+	for i, event := range batches[0] {
+		if event.ID != fmt.Sprintf("evt-%d", i) || string(event.Payload) != fmt.Sprintf(`{"n":%d}`, i) || event.DeliveryID == "" {
+			t.Errorf("event %d = %+v", i, event)
+		}
+	}
+	if b.Buffered() != 0 {
+		t.Errorf("expected nothing buffered after the batch was sent, got %d", b.Buffered())
+	}
+}
+
+func TestBatcher_SendsWhenWindowCloses(t *testing.T) {
+	server := newBatchServer(t, http.StatusOK)
+	b := newTestBatcher(t, engine.NewMemoryQueue())
+	ctx := context.Background()
+
+	b.Add(ctx, batchJob(server.URL, 0, 10, 50*time.Millisecond))
+	b.Add(ctx, batchJob(server.URL, 1, 10, 50*time.Millisecond))
+	if len(server.received(t)) != 0 {
+		t.Fatal("expected the batch to wait for its window")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(server.received(t)) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if batches := server.received(t); len(batches) != 1 || len(batches[0]) != 2 {
+		t.Fatalf("expected one batch of 2 events once the window closed, got %+v", batches)
+	}
+}
+
+func TestBatcher_KeepsJobsForTheOldEndpointApart(t *testing.T) {
+	oldServer := newBatchServer(t, http.StatusOK)
+	newServer := newBatchServer(t, http.StatusOK)
+	b := newTestBatcher(t, engine.NewMemoryQueue())
+	ctx := context.Background()
+
+	// A retry queued before the endpoint changed, then new deliveries
+	b.Add(ctx, batchJob(oldServer.URL, 0, 2, time.Minute))
+	b.Add(ctx, batchJob(newServer.URL, 1, 2, time.Minute))
+	b.Add(ctx, batchJob(newServer.URL, 2, 2, time.Minute))
+
+	if batches := newServer.received(t); len(batches) != 1 || len(batches[0]) != 2 || batches[0][0].ID != "evt-1" {
+		t.Fatalf("new endpoint got %+v, want one batch of evt-1 and evt-2", batches)
+	}
+	if b.Buffered() != 1 {
+		t.Errorf("buffered = %d, want the old endpoint's job still waiting", b.Buffered())
+	}
+	if batches := oldServer.received(t); len(batches) != 0 {
+		t.Errorf("old endpoint got %+v before its window closed", batches)
+	}
+}
+
+func TestBatcher_RetriesEachJobOfAFailedBatch(t *testing.T) {
+	server := newBatchServer(t, http.StatusServiceUnavailable)
+	queue := engine.NewMemoryQueue()
+	b := newTestBatcher(t, queue)
+	ctx := context.Background()
+
+	b.Add(ctx, batchJob(server.URL, 0, 2, time.Minute))
+	b.Add(ctx, batchJob(server.URL, 1, 2, time.Minute))
+
+	if depth, _ := queue.Depth(ctx); depth != 2 {
+		t.Fatalf("expected both jobs to be queued for retry, got %d", depth)
+	}
+	claimed, _ := queue.Claim(ctx, time.Now().Add(time.Hour), 10)
+	for _, c := range claimed {
+		var job engine.DeliveryJob
+		json.Unmarshal([]byte(c.Member), &job)
+		if job.Attempt != 2 || job.BatchMaxEvents != 2 {
+			t.Errorf("unexpected retry %+v", job)
+		}
+	}
+}
+
+func TestBatcher_StopHandsBackBufferedJobs(t *testing.T) {
+	server := newBatchServer(t, http.StatusOK)
+	b := newTestBatcher(t, engine.NewMemoryQueue())
+	ctx := context.Background()
+
+	b.Add(ctx, batchJob(server.URL, 0, 10, time.Minute))
+	b.Add(ctx, batchJob(server.URL, 1, 10, time.Minute))
+
+	if n := b.Stop(); n != 2 {
+		t.Errorf("Stop returned %d jobs, want 2", n)
+	}
+	b.Add(ctx, batchJob(server.URL, 2, 1, time.Minute))
+	if len(server.received(t)) != 0 || b.Buffered() != 0 {
+		t.Error("expected nothing to be sent or buffered after Stop")
+	}
+}
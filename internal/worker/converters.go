@@ -0,0 +1,80 @@
+package worker
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+)
+
+// PayloadConverter converts a payload of one version of an event type to
+// the version before it.
+type PayloadConverter func(payload json.RawMessage) (json.RawMessage, error)
+
+type converterKey struct {
+	eventType string
+	from      int
+}
+
+// PayloadConverters holds the converters that down-convert event payloads
+// for subscribers pinned to an older version than the event was published
+// in. Converters must all be registered before deliveries start. A nil
+// *PayloadConverters has none.
+type PayloadConverters struct {
+	converters map[converterKey]PayloadConverter
+}
+
+func NewPayloadConverters() *PayloadConverters {
+	return &PayloadConverters{converters: make(map[converterKey]PayloadConverter)}
+}
+
+// Register sets the converter from version from of eventType to version
+// from-1, replacing any registered before.
+func (c *PayloadConverters) Register(eventType string, from int, fn PayloadConverter) {
+	c.converters[converterKey{eventType, from}] = fn
+}
+
+// Convert converts payload, of version from of eventType, to version to one
+// version at a time. A payload already at or before version to is returned
+// as it is.
+func (c *PayloadConverters) Convert(eventType string, payload json.RawMessage, from, to int) (json.RawMessage, error) {
+	for v := from; v > to; v-- {
+		var fn PayloadConverter
+		if c != nil {
+			fn = c.converters[converterKey{eventType, v}]
+		}
+		if fn == nil {
+			return nil, fmt.Errorf("no converter from %s v%d to v%d", eventType, v, v-1)
+		}
+		converted, err := fn(payload)
+		if err != nil {
+			return nil, fmt.Errorf("converting %s v%d to v%d: %w", eventType, v, v-1, err)
+		}
+		if !json.Valid(converted) {
+			return nil, fmt.Errorf("converting %s v%d to v%d: result is not valid JSON", eventType, v, v-1)
+		}
+		payload = converted
+	}
+	return payload, nil
+}
+
+// needsConversion reports whether the job's subscriber pins an older
+// version of its event than the one published.
+func needsConversion(job engine.DeliveryJob) bool {
+	return job.PinnedVersion != 0 && job.PinnedVersion < max(job.EventVersion, 1)
+}
+
+// versionedPayload returns the job's payload in the version its subscriber
+// receives, and that version. Jobs queued before events were versioned are
+// of version 1.
+func (d *Deliverer) versionedPayload(job engine.DeliveryJob, payload json.RawMessage) (json.RawMessage, int, error) {
+	version := max(job.EventVersion, 1)
+	if !needsConversion(job) {
+		return payload, version, nil
+	}
+	converted, err := d.converters.Convert(job.EventType, payload, version, job.PinnedVersion)
+	if err != nil {
+		return nil, 0, err
+	}
+	return converted, job.PinnedVersion, nil
+}
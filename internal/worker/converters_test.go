@@ -0,0 +1,118 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+	"github.com/Priya8975/webhook-delivery-system/pkg/webhook"
+)
+
+func TestPayloadConverters_Convert(t *testing.T) {
+	c := NewPayloadConverters()
+	c.Register("order.created", 3, func(p json.RawMessage) (json.RawMessage, error) {
+		return json.RawMessage(strings.Replace(string(p), `"v":3`, `"v":2`, 1)), nil
+	})
+	c.Register("order.created", 2, func(p json.RawMessage) (json.RawMessage, error) {
+		return json.RawMessage(strings.Replace(string(p), `"v":2`, `"v":1`, 1)), nil
+	})
+	c.Register("order.paid", 2, func(json.RawMessage) (json.RawMessage, error) {
+		return nil, errors.New("amount missing")
+	})
+
+	got, err := c.Convert("order.created", json.RawMessage(`{"v":3}`), 3, 1)
+	if err != nil || string(got) != `{"v":1}` {
+		t.Errorf("v3 to v1 = %s, %v, want {\"v\":1}", got, err)
+	}
+	if got, err := c.Convert("order.created", json.RawMessage(`{"v":2}`), 2, 3); err != nil || string(got) != `{"v":2}` {
+		t.Errorf("v2 pinned to v3 = %s, %v, want it unchanged", got, err)
+	}
+	if _, err := c.Convert("order.created", json.RawMessage(`{"v":4}`), 4, 1); err == nil || !strings.Contains(err.Error(), "v4 to v3") {
+		t.Errorf("missing converter: err = %v", err)
+	}
+	if _, err := c.Convert("order.paid", json.RawMessage(`{}`), 2, 1); err == nil || !strings.Contains(err.Error(), "amount missing") {
+		t.Errorf("failing converter: err = %v", err)
+	}
+	if _, err := (*PayloadConverters)(nil).Convert("order.created", json.RawMessage(`{}`), 2, 1); err == nil {
+		t.Error("nil converters converted a payload")
+	}
+}
+
+func TestDeliver_PinnedVersion(t *testing.T) {
+	var body []byte
+	var version string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		version = r.Header.Get(webhook.EventVersionHeader)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	converters := NewPayloadConverters()
+	converters.Register("order.created", 2, func(json.RawMessage) (json.RawMessage, error) {
+		return json.RawMessage(`{"amount":10}`), nil
+	})
+	_, cb, rl, hub, logger := setupDeliveryTest(t)
+	s := store.NewMemoryStore()
+	deliverer := &Deliverer{
+		httpClient:     server.Client(),
+		store:          s,
+		queue:          engine.NewMemoryQueue(),
+		circuitBreaker: cb,
+		rateLimiter:    rl,
+		hub:            hub,
+		converters:     converters,
+		logger:         logger,
+	}
+
+	deliver := func(eventID string, eventVersion, pinned int) {
+		t.Helper()
+		body, version = nil, ""
+		deliverer.Deliver(context.Background(), engine.DeliveryJob{
+			EventID:       eventID,
+			SubscriberID:  "sub-pinned",
+			EndpointURL:   server.URL,
+			Payload:       json.RawMessage(`{"amount":{"value":10}}`),
+			SecretKey:     "secret",
+			EventType:     "order.created",
+			EventVersion:  eventVersion,
+			PinnedVersion: pinned,
+			Attempt:       1,
+			MaxRetries:    1,
+		})
+	}
+
+	deliver("evt-pinned", 2, 1)
+	if string(body) != `{"amount":10}` || version != "1" {
+		t.Errorf("pinned to v1: body = %s, version = %q, want the v1 payload", body, version)
+	}
+
+	deliver("evt-unpinned", 2, 0)
+	if string(body) != `{"amount":{"value":10}}` || version != "2" {
+		t.Errorf("unpinned: body = %s, version = %q, want the v2 payload", body, version)
+	}
+
+	deliver("evt-unconvertible", 3, 1)
+	if body != nil {
+		t.Errorf("sent %s without a converter from v3", body)
+	}
+	dead, _ := s.ListDeadLetters(context.Background(), store.DeadLetterFilter{})
+	if len(dead) != 1 || dead[0].EventID != "evt-unconvertible" {
+		t.Errorf("dead letters = %+v, want the unconvertible delivery", dead)
+	}
+
+	// Usage is metered on the payload as sent, after conversion
+	now := time.Now()
+	usage, _ := s.ListUsage(context.Background(), store.UsageFilter{From: now, To: now})
+	if want := int64(len(`{"amount":10}`) + len(`{"amount":{"value":10}}`)); len(usage) != 1 || usage[0].PayloadBytes != want {
+		t.Errorf("usage = %+v, want %d payload bytes", usage, want)
+	}
+}
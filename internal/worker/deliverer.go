@@ -2,64 +2,241 @@ package worker
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
-	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
 	"math"
-	"math/rand/v2"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/Priya8975/webhook-delivery-system/internal/chaos"
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
 	"github.com/Priya8975/webhook-delivery-system/internal/engine"
 	"github.com/Priya8975/webhook-delivery-system/internal/store"
 	ws "github.com/Priya8975/webhook-delivery-system/internal/websocket"
-	"github.com/redis/go-redis/v9"
 )
 
+// DelivererConfig holds tunables for outbound deliveries.
+type DelivererConfig struct {
+	Transport TransportConfig
+	// Timeout bounds each request, including reading the response. 0 uses
+	// 10 seconds.
+	Timeout time.Duration
+	Retry   RetryConfig
+	// GzipThresholdBytes is the payload size at or above which bodies are
+	// gzip-compressed for subscribers that opted in. 0 disables compression.
+	GzipThresholdBytes int
+	// PayloadCacheSize is the number of event payloads kept in memory.
+	PayloadCacheSize int
+	// CaptureHeaders lists the response headers recorded with each attempt.
+	// Nil uses DefaultCaptureHeaders.
+	CaptureHeaders []string
+	// ResponseBodyLimit is the number of response body bytes stored with
+	// each attempt. 0 stores no bodies.
+	ResponseBodyLimit int
+	// Redactor masks sensitive data in stored bodies and headers. Nil
+	// stores them as received.
+	Redactor *Redactor
+	// Recorder batches attempt inserts off the delivery path. Nil inserts
+	// each attempt synchronously.
+	Recorder *AttemptRecorder
+	// LogSampling thins out the logs of delivery outcomes. Nil logs every
+	// delivery.
+	LogSampling *LogSampling
+	// SystemEvents publishes delivery.dead_lettered events. Nil publishes
+	// none.
+	SystemEvents SystemEventPublisher
+	// Receipts remembers successful deliveries, so that jobs queued again
+	// after one are skipped. Nil sends every job.
+	Receipts *engine.DeliveryReceipts
+	// Converters down-convert payloads for subscribers pinned to an older
+	// event version. Without one for a version, its deliveries fail.
+	Converters *PayloadConverters
+	// Chaos, when set, makes delivery requests time out at the rate it is
+	// configured with.
+	Chaos *chaos.Injector
+	// Quota, when set, holds deliveries to subscribers over their daily or
+	// monthly quota until the quota resets.
+	Quota *engine.DeliveryQuota
+	// Clock schedules retries and deferred jobs. It must be the clock the
+	// dispatcher claims by; nil means the local clock.
+	Clock engine.Clock
+	// Rand draws retry jitter and log samples. Seed it to make them
+	// repeatable; nil draws from the process-wide source.
+	Rand *engine.Rand
+	// Objects holds the payloads that were too large to keep with their
+	// events. Without it, their deliveries fail.
+	Objects engine.ObjectStore
+	// RedirectPolicy is which redirects are followed for jobs without a
+	// policy of their own, one of domain.RedirectPolicies. Empty means
+	// domain.RedirectSameHost.
+	RedirectPolicy string
+}
+
+// SystemEventPublisher publishes events about the delivery system itself to
+// its system subscribers.
+type SystemEventPublisher interface {
+	PublishSystemEvent(ctx context.Context, eventType string, data any)
+}
+
+// RetryConfig controls the backoff between attempts. Attempt n is retried
+// after BaseDelay * 2^n, capped at MaxDelay when it is set, plus a random
+// jitter of up to Jitter.
+type RetryConfig struct {
+	BaseDelay time.Duration // 0 uses 1 second
+	MaxDelay  time.Duration
+	Jitter    time.Duration // added to the backoff by domain.JitterAdditive
+	// Strategy jitters the retries of jobs that don't pick a strategy of
+	// their own. Empty means domain.JitterAdditive.
+	Strategy string
+	// DurationMaxDelay caps the backoff of jobs that retry for their whole
+	// maximum retry duration, so they keep trying at a steady interval
+	// instead of backing off for hours. 0 leaves them capped by MaxDelay.
+	DurationMaxDelay time.Duration
+}
+
+// DefaultCaptureHeaders are the response headers recorded when none are
+// configured: retry hints, the body's type, and common request ID headers
+// consumers quote when disputing a delivery.
+var DefaultCaptureHeaders = []string{"Retry-After", "Content-Type", "X-Request-Id", "X-Correlation-Id", "Request-Id"}
+
+// maxCapturedHeaderLen caps each stored header value.
+const maxCapturedHeaderLen = 256
+
+// redactLookahead is how far past the body limit is read when redacting, so
+// a match that straddles the limit is still recognised.
+const redactLookahead = 256
+
+// drainLimit is how much of a response that isn't stored is still read, so
+// small bodies don't prevent the connection from being reused.
+const drainLimit = 4096
+
 // Deliverer handles the HTTP delivery of webhook payloads to subscriber endpoints.
 type Deliverer struct {
 	httpClient     *http.Client
-	pgStore        *store.PostgresStore
-	redisClient    *redis.Client
+	retry          RetryConfig
+	gzipThreshold  int
+	captureHeaders []string
+	bodyLimit      int
+	redactor       *Redactor
+	recorder       *AttemptRecorder
+	payloads       *payloadResolver
+	store          DelivererStore
+	queue          engine.Queue
 	circuitBreaker *engine.CircuitBreaker
 	rateLimiter    *engine.RateLimiter
+	quota          *engine.DeliveryQuota
+	clock          engine.Clock
+	rng            *engine.Rand
 	hub            *ws.Hub
+	systemEvents   SystemEventPublisher
+	receipts       *engine.DeliveryReceipts
+	converters     *PayloadConverters
+	redirectPolicy string
+	objects        engine.ObjectStore
+	queues         queuePushers
 	logger         *slog.Logger
+	logSampling    atomic.Pointer[LogSampling]
+}
+
+// DelivererStore is what a Deliverer needs from the store: events to load
+// payloads from, and the tables delivery results are written to.
+type DelivererStore interface {
+	store.EventStore
+	store.DeliveryStore
+	store.DLQStore
 }
 
 // NewDeliverer creates a deliverer with a configured HTTP client.
-func NewDeliverer(pgStore *store.PostgresStore, redisClient *redis.Client, cb *engine.CircuitBreaker, rl *engine.RateLimiter, hub *ws.Hub, logger *slog.Logger) *Deliverer {
-	return &Deliverer{
+// All workers share one tuned transport so keep-alive connections are reused.
+func NewDeliverer(s DelivererStore, queue engine.Queue, cb *engine.CircuitBreaker, rl *engine.RateLimiter, hub *ws.Hub, cfg DelivererConfig, logger *slog.Logger) *Deliverer {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.Retry.BaseDelay <= 0 {
+		cfg.Retry.BaseDelay = time.Second
+	}
+	transport := newHTTPTransport(cfg.Transport)
+	d := &Deliverer{
 		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
+			Timeout:   cfg.Timeout,
+			Transport: cfg.Chaos.Transport(transport),
 		},
-		pgStore:        pgStore,
-		redisClient:    redisClient,
+		retry:          cfg.Retry,
+		gzipThreshold:  cfg.GzipThresholdBytes,
+		captureHeaders: cfg.CaptureHeaders,
+		bodyLimit:      cfg.ResponseBodyLimit,
+		redactor:       cfg.Redactor,
+		recorder:       cfg.Recorder,
+		payloads:       newPayloadResolver(queue, s, cfg.PayloadCacheSize),
+		store:          s,
+		queue:          queue,
 		circuitBreaker: cb,
 		rateLimiter:    rl,
+		quota:          cfg.Quota,
+		clock:          cfg.Clock,
+		rng:            cfg.Rand,
 		hub:            hub,
+		systemEvents:   cfg.SystemEvents,
+		receipts:       cfg.Receipts,
+		converters:     cfg.Converters,
+		redirectPolicy: cfg.RedirectPolicy,
+		objects:        cfg.Objects,
 		logger:         logger,
 	}
+	d.queues.dial = transport.DialContext
+	d.logSampling.Store(cfg.LogSampling)
+	return d
 }
 
 // Deliver sends the webhook payload to the subscriber endpoint via HTTP POST.
-// Checks circuit breaker and rate limiter before attempting delivery.
+// Parks jobs of paused subscribers, and checks the circuit breaker, rate
+// limiter and delivery quota before attempting delivery.
 // On failure, it either re-queues with exponential backoff or moves to the dead letter queue.
 func (d *Deliverer) Deliver(ctx context.Context, job engine.DeliveryJob) {
+	// Assigned up front so that every log line about the job carries it,
+	// including those of jobs deferred without an attempt
+	job.DeliveryID = store.NewDeliveryID()
+	// A probe only jumps the queue once; if it is deferred or retried it
+	// waits its turn like any other job
+	job.Probe = false
+
+	// Set the job aside while its subscriber is paused. If the check fails,
+	// deliver rather than risk losing the job
+	parked, err := d.queue.ParkIfPaused(ctx, job)
+	if err != nil {
+		d.jobLogger(job).Error("failed to check subscriber pause", "error", err)
+	}
+	if parked {
+		d.logDebug(ctx, job, "subscriber paused, parking job")
+		return
+	}
+
+	if d.alreadyDelivered(ctx, job) {
+		return
+	}
+
 	// Check circuit breaker
 	state, allowed := d.circuitBreaker.AllowRequest(ctx, job.SubscriberID)
 	if !allowed {
-		// Circuit is open — re-queue with a short delay instead of delivering
-		d.logger.Warn("circuit breaker open, re-queuing",
-			"subscriber_id", job.SubscriberID,
-			"event_id", job.EventID,
-			"state", state,
-		)
+		// Circuit is open — re-queue with a short delay instead of delivering.
+		// The first job deferred in a cooldown becomes the probe, claimed
+		// ahead of the backlog as soon as the circuit goes half-open
+		if at, ok := d.circuitBreaker.ScheduleProbe(ctx, job.SubscriberID); ok {
+			job.Probe = true
+			d.jobLogger(job).Warn("circuit breaker open, scheduling probe", "state", state, "probe_at", at)
+			d.requeueWithDelay(ctx, job, at.Sub(d.now()))
+			return
+		}
+		d.jobLogger(job).Warn("circuit breaker open, re-queuing", "state", state)
 		d.requeueWithDelay(ctx, job, 5*time.Second)
 		return
 	}
@@ -67,110 +244,276 @@ func (d *Deliverer) Deliver(ctx context.Context, job engine.DeliveryJob) {
 	// Check rate limiter
 	if !d.rateLimiter.Allow(ctx, job.SubscriberID, job.RateLimitPerSecond) {
 		// Rate limited — re-queue with a short delay
-		d.logger.Debug("rate limited, re-queuing",
-			"subscriber_id", job.SubscriberID,
-			"event_id", job.EventID,
-			"rate_limit", job.RateLimitPerSecond,
-		)
+		d.logDebug(ctx, job, "rate limited, re-queuing", "rate_limit", job.RateLimitPerSecond)
 		d.requeueWithDelay(ctx, job, 1*time.Second)
 		return
 	}
 
+	// Check delivery quota; retries count against it like first attempts
+	if ok, resetAt := d.quota.Take(ctx, job.SubscriberID, job.DailyQuota, job.MonthlyQuota, 1); !ok {
+		d.logDebug(ctx, job, "delivery quota used up, re-queuing until it resets", "resets_at", resetAt)
+		d.requeueWithDelay(ctx, job, resetAt.Sub(d.now()))
+		return
+	}
+
 	start := time.Now()
 
-	// Compute HMAC-SHA256 signature
-	signature := computeHMAC(job.Payload, job.SecretKey)
+	// A payload kept in object storage is streamed, into the signature and
+	// then the request, rather than loaded
+	stream := d.streamsPayload(job)
+	var payload []byte
+	version := max(job.EventVersion, 1)
+	if stream {
+		job.PayloadBytes = int(job.PayloadRef.Size)
+	} else {
+		// Resolve the payload referenced by the job
+		payload, err = d.loadPayload(ctx, job)
+		if err != nil {
+			d.handleFailure(ctx, job, start, nil, "", nil, domain.FailureInternal, fmt.Sprintf("failed to resolve payload: %v", err))
+			return
+		}
+		// Retried like other failures, so a converter deployed in the
+		// meantime still gets the event through
+		payload, version, err = d.versionedPayload(job, payload)
+		if err != nil {
+			d.handleFailure(ctx, job, start, nil, "", nil, domain.FailureInternal, fmt.Sprintf("failed to convert payload: %v", err))
+			return
+		}
+		job.PayloadBytes = len(payload)
+	}
+
+	reqBody, compressed := d.compress(payload, job)
+	if stream {
+		compressed = d.compressesStream(job)
+	}
+
+	// Time the phases of the request, for recordAttempt
+	trace := &attemptTrace{}
+	ctx = withAttemptTrace(ctx, trace)
 
 	// Build HTTP request
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, job.EndpointURL, bytes.NewReader(job.Payload))
+	req, err := newDeliveryRequest(trace.requestContext(ctx), job, reqBody)
 	if err != nil {
 		d.circuitBreaker.RecordFailure(ctx, job.SubscriberID)
-		d.handleFailure(ctx, job, start, nil, "", fmt.Sprintf("failed to create request: %v", err))
+		d.handleFailure(ctx, job, start, nil, "", nil, domain.FailureInternal, fmt.Sprintf("failed to create request: %v", err))
 		return
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-Webhook-Signature", signature)
+	if compressed {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	// Signed over the uncompressed payload, and again for each redirect
+	// target under domain.RedirectFollowResign
+	resign := func(h http.Header) error {
+		m := signedMessage{
+			ID:        job.EventID,
+			Payload:   payload,
+			Secret:    job.SecretKey,
+			Timestamp: time.Now(),
+		}
+		if stream {
+			m.Open = d.openPayload(ctx, job.PayloadRef)
+		}
+		return sign(h, job.SignatureFormat, m)
+	}
+	if err := resign(req.Header); err != nil {
+		d.handleFailure(ctx, job, start, nil, "", nil, domain.FailureInternal, fmt.Sprintf("failed to sign payload: %v", err))
+		return
+	}
 	req.Header.Set("X-Webhook-Event", job.EventType)
+	req.Header.Set("X-Webhook-Event-Version", fmt.Sprintf("%d", version))
 	req.Header.Set("X-Webhook-ID", job.EventID)
+	req.Header.Set("X-Webhook-Delivery-ID", job.DeliveryID)
 	req.Header.Set("X-Webhook-Attempt", fmt.Sprintf("%d", job.Attempt))
 
+	// Sandbox deliveries stop here, fully prepared but never sent
+	if job.Sandbox {
+		d.handleSimulated(ctx, job, start)
+		return
+	}
+
+	if job.DebugLogging {
+		d.logDebug(ctx, job, "sending delivery",
+			"endpoint_url", job.EndpointURL,
+			"attempt", job.Attempt,
+			"payload_bytes", job.PayloadBytes,
+			"compressed", compressed,
+		)
+	}
+
 	// Execute the request
-	resp, err := d.httpClient.Do(req)
+	if stream {
+		if err := d.streamBody(ctx, req, job, compressed); err != nil {
+			d.handleFailure(ctx, job, start, nil, "", nil, domain.FailureInternal, fmt.Sprintf("failed to fetch payload: %v", err))
+			return
+		}
+	}
+	resp, err := d.send(&job, req, resign)
 	if err != nil {
 		d.circuitBreaker.RecordFailure(ctx, job.SubscriberID)
-		d.handleFailure(ctx, job, start, nil, "", fmt.Sprintf("request failed: %v", err))
+		d.handleFailure(ctx, job, start, nil, "", nil, classifyError(err), fmt.Sprintf("request failed: %v", err))
 		return
 	}
 	defer resp.Body.Close()
 
-	// Read response body (limit to 1KB to prevent memory issues)
-	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
-	responseBody := string(body)
-	elapsed := time.Since(start).Milliseconds()
+	unmet, body := checkResponse(resp, job)
+	responseBody := d.readResponseBody(body, job)
+	trace.readBody()
+	responseHeaders := captureHeaders(resp.Header, d.captureHeaders)
+	for name, value := range responseHeaders {
+		responseHeaders[name] = d.redactor.Redact(value)
+	}
 
-	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+	switch {
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		d.circuitBreaker.RecordFailure(ctx, job.SubscriberID)
+		d.handleFailure(ctx, job, start, &resp.StatusCode, responseBody, responseHeaders, domain.StatusFailureReason(resp.StatusCode), "")
+	case unmet != "":
+		d.circuitBreaker.RecordFailure(ctx, job.SubscriberID)
+		d.handleFailure(ctx, job, start, &resp.StatusCode, responseBody, responseHeaders, domain.FailureUnexpectedBody, unmet)
+	default:
 		d.circuitBreaker.RecordSuccess(ctx, job.SubscriberID)
-		d.recordAttempt(ctx, job, start, &resp.StatusCode, responseBody, "", nil)
+		d.handleSuccess(ctx, job, start, resp.StatusCode, responseBody, responseHeaders)
+	}
+}
 
-		// Broadcast success to dashboard
-		d.hub.Broadcast(ws.DeliveryEvent{
-			Type:         "delivery_success",
-			EventID:      job.EventID,
-			SubscriberID: job.SubscriberID,
-			EndpointURL:  job.EndpointURL,
-			EventType:    job.EventType,
-			Attempt:      job.Attempt,
-			StatusCode:   &resp.StatusCode,
-			ResponseMs:   elapsed,
-			Timestamp:    time.Now(),
-		})
+// handleSuccess records a delivered job and announces it.
+func (d *Deliverer) handleSuccess(ctx context.Context, job engine.DeliveryJob, start time.Time, statusCode int, responseBody string, responseHeaders map[string]string) {
+	elapsed := time.Since(start).Milliseconds()
 
-		d.logger.Info("delivery successful",
-			"event_id", job.EventID,
-			"subscriber_id", job.SubscriberID,
+	// Written first, as the job is most likely to be delivered again if the
+	// worker stops before it is acknowledged
+	if err := d.receipts.Record(ctx, job.EventID, job.SubscriberID); err != nil {
+		d.jobLogger(job).Error("failed to record delivery receipt", "error", err)
+	}
+	d.recordAttempt(ctx, job, start, &statusCode, responseBody, responseHeaders, "", "", nil)
+
+	// Broadcast success to dashboard
+	d.hub.Broadcast(ws.DeliveryEvent{
+		Type:         "delivery_success",
+		DeliveryID:   job.DeliveryID,
+		EventID:      job.EventID,
+		SubscriberID: job.SubscriberID,
+		EndpointURL:  job.EndpointURL,
+		EventType:    job.EventType,
+		Attempt:      job.Attempt,
+		StatusCode:   &statusCode,
+		ResponseMs:   elapsed,
+		Timestamp:    time.Now(),
+	})
+
+	if d.shouldLog(job, true) {
+		d.jobLogger(job).Info("delivery successful", append([]any{
 			"attempt", job.Attempt,
-			"status_code", resp.StatusCode,
+			"status_code", statusCode,
 			"response_time_ms", elapsed,
-		)
-	} else {
-		d.circuitBreaker.RecordFailure(ctx, job.SubscriberID)
-		d.handleFailure(ctx, job, start, &resp.StatusCode, responseBody, "")
+		}, responseDetails(job, responseBody, responseHeaders)...)...)
 	}
 }
 
-// requeueWithDelay puts the job back in the Redis queue with a short delay.
-// Used for circuit breaker and rate limiter deferrals (does NOT increment attempt count).
-func (d *Deliverer) requeueWithDelay(ctx context.Context, job engine.DeliveryJob, delay time.Duration) {
-	nextTime := time.Now().Add(delay)
+// handleSimulated records a delivery to a sandbox subscriber as simulated.
+// No receipt is recorded, as nothing was received.
+func (d *Deliverer) handleSimulated(ctx context.Context, job engine.DeliveryJob, start time.Time) {
+	d.recordAttempt(ctx, job, start, nil, "", nil, "", "", nil)
+
+	d.hub.Broadcast(ws.DeliveryEvent{
+		Type:         "delivery_simulated",
+		DeliveryID:   job.DeliveryID,
+		EventID:      job.EventID,
+		SubscriberID: job.SubscriberID,
+		EndpointURL:  job.EndpointURL,
+		EventType:    job.EventType,
+		Attempt:      job.Attempt,
+		Timestamp:    time.Now(),
+	})
 
-	jobBytes, err := json.Marshal(job)
+	if d.shouldLog(job, true) {
+		d.jobLogger(job).Info("delivery simulated for sandbox subscriber", "attempt", job.Attempt)
+	}
+}
+
+// alreadyDelivered reports whether the job's event was already delivered to
+// its subscriber, in which case the job is dropped. If the check fails, the
+// job is delivered rather than risk losing it.
+func (d *Deliverer) alreadyDelivered(ctx context.Context, job engine.DeliveryJob) bool {
+	delivered, err := d.receipts.Delivered(ctx, job.EventID, job.SubscriberID)
 	if err != nil {
-		d.logger.Error("failed to marshal requeue job", "error", err)
-		return
+		d.jobLogger(job).Error("failed to check delivery receipt", "error", err)
+		return false
+	}
+	if delivered {
+		d.jobLogger(job).Info("event already delivered, skipping job", "attempt", job.Attempt)
 	}
+	return delivered
+}
 
-	err = d.redisClient.ZAdd(ctx, engine.DeliveryQueueKey, redis.Z{
-		Score:  float64(nextTime.UnixMicro()),
-		Member: string(jobBytes),
-	}).Err()
+// compress gzips large bodies for subscribers that opted in, reporting
+// whether it did. Messages pushed to queues are never compressed.
+func (d *Deliverer) compress(body []byte, job engine.DeliveryJob) ([]byte, bool) {
+	if !job.CompressPayload || pushesToQueue(job) || d.gzipThreshold <= 0 || len(body) < d.gzipThreshold {
+		return body, false
+	}
+	gz, err := gzipBytes(body)
 	if err != nil {
-		d.logger.Error("failed to requeue job", "error", err)
+		d.jobLogger(job).Warn("failed to gzip payload, sending uncompressed", "error", err)
+		return body, false
+	}
+	return gz, true
+}
+
+// newDeliveryRequest builds the POST of body to job's endpoint, routed
+// through the subscriber's proxy if it has one.
+func newDeliveryRequest(ctx context.Context, job engine.DeliveryJob, body []byte) (*http.Request, error) {
+	if job.ProxyURL != "" {
+		proxy, err := url.Parse(job.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		ctx = withProxy(ctx, proxy)
+	}
+	return http.NewRequestWithContext(ctx, http.MethodPost, job.EndpointURL, bytes.NewReader(body))
+}
+
+// now returns the time on the clock retries are scheduled by.
+func (d *Deliverer) now() time.Time {
+	if d.clock == nil {
+		return time.Now()
+	}
+	return d.clock.Now()
+}
+
+// requeueWithDelay puts the job back in the queue with a short delay.
+// Used for circuit breaker and rate limiter deferrals (does NOT increment attempt count).
+func (d *Deliverer) requeueWithDelay(ctx context.Context, job engine.DeliveryJob, delay time.Duration) {
+	if err := d.queue.Enqueue(ctx, job, d.now().Add(delay)); err != nil {
+		d.jobLogger(job).Error("failed to requeue job", "error", err)
 	}
 }
 
 // handleFailure processes a failed delivery — either retries or sends to DLQ.
-func (d *Deliverer) handleFailure(ctx context.Context, job engine.DeliveryJob, start time.Time, statusCode *int, responseBody string, errMsg string) {
+func (d *Deliverer) handleFailure(ctx context.Context, job engine.DeliveryJob, start time.Time, statusCode *int, responseBody string, responseHeaders map[string]string, reason domain.FailureReason, errMsg string) {
 	elapsed := time.Since(start).Milliseconds()
 
-	if job.Attempt < job.MaxRetries {
+	// A maximum retry duration is counted from the first failure. Past it
+	// the job is dead-lettered whatever attempts it has left; until then,
+	// jobs retrying for the duration ignore the attempt count.
+	now := d.now()
+	if job.FailingSince == 0 {
+		job.FailingSince = now.UnixMilli()
+	}
+	deadline, limited := job.RetryDeadline()
+	expired := limited && !now.Before(deadline)
+
+	if !expired && (job.Attempt < job.MaxRetries || (limited && job.RetryForDuration)) {
 		// Schedule retry with exponential backoff + jitter
 		nextRetry := d.scheduleRetry(ctx, job)
-		d.recordAttempt(ctx, job, start, statusCode, responseBody, errMsg, nextRetry)
+		d.recordAttempt(ctx, job, start, statusCode, responseBody, responseHeaders, reason, errMsg, nextRetry)
 
 		// Broadcast retry to dashboard
 		d.hub.Broadcast(ws.DeliveryEvent{
 			Type:         "delivery_retrying",
+			DeliveryID:   job.DeliveryID,
 			EventID:      job.EventID,
 			SubscriberID: job.SubscriberID,
 			EndpointURL:  job.EndpointURL,
@@ -182,23 +525,25 @@ func (d *Deliverer) handleFailure(ctx context.Context, job engine.DeliveryJob, s
 			Timestamp:    time.Now(),
 		})
 
-		d.logger.Warn("delivery failed, scheduling retry",
-			"event_id", job.EventID,
-			"subscriber_id", job.SubscriberID,
-			"attempt", job.Attempt,
-			"next_attempt", job.Attempt+1,
-			"next_retry_at", nextRetry.Format(time.RFC3339),
-			"error", errMsg,
-			"status_code", statusCode,
-		)
+		if d.shouldLog(job, false) {
+			d.jobLogger(job).Warn("delivery failed, scheduling retry", append([]any{
+				"attempt", job.Attempt,
+				"next_attempt", job.Attempt + 1,
+				"next_retry_at", nextRetry.Format(time.RFC3339),
+				"failure_reason", reason,
+				"error", errMsg,
+				"status_code", statusCode,
+			}, responseDetails(job, responseBody, responseHeaders)...)...)
+		}
 	} else {
 		// Max retries exhausted — move to dead letter queue
-		d.recordAttempt(ctx, job, start, statusCode, responseBody, errMsg, nil)
-		d.moveToDLQ(ctx, job, statusCode, errMsg)
+		d.recordAttempt(ctx, job, start, statusCode, responseBody, responseHeaders, reason, errMsg, nil)
+		d.moveToDLQ(ctx, job, statusCode, reason, errMsg)
 
 		// Broadcast DLQ entry to dashboard
 		d.hub.Broadcast(ws.DeliveryEvent{
 			Type:         "delivery_dlq",
+			DeliveryID:   job.DeliveryID,
 			EventID:      job.EventID,
 			SubscriberID: job.SubscriberID,
 			EndpointURL:  job.EndpointURL,
@@ -210,106 +555,227 @@ func (d *Deliverer) handleFailure(ctx context.Context, job engine.DeliveryJob, s
 			Timestamp:    time.Now(),
 		})
 
-		d.logger.Error("delivery permanently failed, moved to dead letter queue",
-			"event_id", job.EventID,
-			"subscriber_id", job.SubscriberID,
+		d.jobLogger(job).Error("delivery permanently failed, moved to dead letter queue", append([]any{
 			"total_attempts", job.Attempt,
+			"retry_duration_expired", expired,
+			"failure_reason", reason,
 			"error", errMsg,
 			"status_code", statusCode,
-		)
+		}, responseDetails(job, responseBody, responseHeaders)...)...)
 	}
 }
 
-// scheduleRetry re-queues the job to Redis with a future timestamp.
+// scheduleRetry re-queues the job with a future timestamp.
 func (d *Deliverer) scheduleRetry(ctx context.Context, job engine.DeliveryJob) *time.Time {
-	baseDelay := time.Duration(math.Pow(2, float64(job.Attempt))) * time.Second
-	jitter := time.Duration(rand.IntN(1000)) * time.Millisecond
-	delay := baseDelay + jitter
+	delay := retryDelay(d.retry, job, d.rng)
+
+	now := d.now()
+	nextRetry := now.Add(delay)
+	// The last retry within a maximum retry duration is made as it ends
+	if deadline, ok := job.RetryDeadline(); ok && nextRetry.After(deadline) {
+		nextRetry, delay = deadline, deadline.Sub(now)
+	}
 
-	nextRetry := time.Now().Add(delay)
+	retryJob := job
+	retryJob.Attempt = job.Attempt + 1
+	retryJob.RetryDelayMs = delay.Milliseconds()
 
-	retryJob := engine.DeliveryJob{
-		EventID:            job.EventID,
-		SubscriberID:       job.SubscriberID,
-		EndpointURL:        job.EndpointURL,
-		Payload:            job.Payload,
-		SecretKey:          job.SecretKey,
-		EventType:          job.EventType,
-		Attempt:            job.Attempt + 1,
-		MaxRetries:         job.MaxRetries,
-		RateLimitPerSecond: job.RateLimitPerSecond,
+	if err := d.queue.Enqueue(ctx, retryJob, nextRetry); err != nil {
+		d.jobLogger(job).Error("failed to queue retry", "error", err)
 	}
 
-	jobBytes, err := json.Marshal(retryJob)
-	if err != nil {
-		d.logger.Error("failed to marshal retry job", "error", err)
-		return &nextRetry
+	return &nextRetry
+}
+
+// retryDelay returns how long to wait before retrying job's attempt,
+// jittered by the job's strategy or else cfg's, drawing from rng.
+func retryDelay(cfg RetryConfig, job engine.DeliveryJob, rng *engine.Rand) time.Duration {
+	ceiling := float64(math.MaxInt64 / 4)
+	if cfg.MaxDelay > 0 {
+		ceiling = float64(cfg.MaxDelay)
 	}
+	if job.RetryForDuration && cfg.DurationMaxDelay > 0 {
+		ceiling = min(ceiling, float64(cfg.DurationMaxDelay))
+	}
+	backoff := time.Duration(min(float64(cfg.BaseDelay)*math.Pow(2, float64(job.Attempt)), ceiling))
 
-	err = d.redisClient.ZAdd(ctx, engine.DeliveryQueueKey, redis.Z{
-		Score:  float64(nextRetry.UnixMicro()),
-		Member: string(jobBytes),
-	}).Err()
-	if err != nil {
-		d.logger.Error("failed to queue retry", "error", err)
+	strategy := job.RetryJitter
+	if strategy == "" {
+		strategy = cfg.Strategy
+	}
+	switch strategy {
+	case domain.JitterFull:
+		return time.Duration(rng.Int64N(int64(backoff) + 1))
+	case domain.JitterEqual:
+		half := backoff / 2
+		return half + time.Duration(rng.Int64N(int64(backoff-half)+1))
+	case domain.JitterDecorrelated:
+		// Independent of the attempt: each delay is drawn from the base
+		// delay up to three times the one before it
+		prev := max(time.Duration(job.RetryDelayMs)*time.Millisecond, cfg.BaseDelay)
+		upper := time.Duration(min(float64(prev)*3, ceiling))
+		if upper <= cfg.BaseDelay {
+			return upper
+		}
+		return cfg.BaseDelay + time.Duration(rng.Int64N(int64(upper-cfg.BaseDelay)+1))
 	}
 
-	return &nextRetry
+	delay := backoff
+	if cfg.Jitter > 0 {
+		delay += time.Duration(rng.Int64N(int64(cfg.Jitter)))
+	}
+	return delay
 }
 
-// moveToDLQ inserts the failed delivery into the dead letter queue.
-func (d *Deliverer) moveToDLQ(ctx context.Context, job engine.DeliveryJob, statusCode *int, errMsg string) {
-	if d.pgStore == nil {
+// moveToDLQ inserts the failed delivery into the dead letter queue and
+// publishes a delivery.dead_lettered system event. Dead-lettered system
+// events don't publish one of their own, so a failing system subscriber
+// can't feed itself events.
+func (d *Deliverer) moveToDLQ(ctx context.Context, job engine.DeliveryJob, statusCode *int, reason domain.FailureReason, errMsg string) {
+	if d.store == nil {
 		return
 	}
 
-	err := d.pgStore.InsertDeadLetter(ctx, store.DeadLetterRecord{
+	err := d.store.InsertDeadLetter(ctx, store.DeadLetterRecord{
 		EventID:        job.EventID,
 		SubscriberID:   job.SubscriberID,
 		TotalAttempts:  job.Attempt,
 		LastHTTPStatus: statusCode,
 		LastError:      errMsg,
+		FailureReason:  reason,
 	})
 	if err != nil {
-		d.logger.Error("failed to insert into dead letter queue",
-			"error", err,
-			"event_id", job.EventID,
-			"subscriber_id", job.SubscriberID,
-		)
+		d.jobLogger(job).Error("failed to insert into dead letter queue", "error", err)
+		return
+	}
+
+	if d.systemEvents != nil && !domain.IsSystemEventType(job.EventType) {
+		d.systemEvents.PublishSystemEvent(ctx, domain.EventDeliveryDeadLettered, domain.DeadLetteredEvent{
+			EventID:        job.EventID,
+			EventType:      job.EventType,
+			SubscriberID:   job.SubscriberID,
+			TotalAttempts:  job.Attempt,
+			LastHTTPStatus: statusCode,
+			FailureReason:  reason,
+			LastError:      errMsg,
+		})
 	}
 }
 
-// recordAttempt logs the delivery result to PostgreSQL.
-func (d *Deliverer) recordAttempt(ctx context.Context, job engine.DeliveryJob, start time.Time, statusCode *int, responseBody string, errMsg string, nextRetryAt *time.Time) {
-	if d.pgStore == nil {
+// recordAttempt logs the delivery result to the store, through the batching
+// recorder when one is configured.
+func (d *Deliverer) recordAttempt(ctx context.Context, job engine.DeliveryJob, start time.Time, statusCode *int, responseBody string, responseHeaders map[string]string, reason domain.FailureReason, errMsg string, nextRetryAt *time.Time) {
+	if d.recorder == nil && d.store == nil {
 		return
 	}
 
 	elapsed := time.Since(start).Milliseconds()
 
 	status := "success"
-	if errMsg != "" || (statusCode != nil && *statusCode >= 400) {
+	if errMsg != "" || (statusCode != nil && (*statusCode < 200 || *statusCode >= 300)) {
 		status = "failed"
+	} else if job.Sandbox {
+		status = domain.AttemptSimulated
 	}
 
-	err := d.pgStore.RecordDeliveryAttempt(ctx, store.DeliveryAttemptRecord{
-		EventID:        job.EventID,
-		SubscriberID:   job.SubscriberID,
-		AttemptNumber:  job.Attempt,
-		Status:         status,
-		HTTPStatusCode: statusCode,
-		ResponseBody:   responseBody,
-		ResponseTimeMs: int(elapsed),
-		ErrorMessage:   errMsg,
-		NextRetryAt:    nextRetryAt,
-	})
-	if err != nil {
-		d.logger.Error("failed to record delivery attempt",
-			"error", err,
-			"event_id", job.EventID,
-			"subscriber_id", job.SubscriberID,
-		)
+	rec := store.DeliveryAttemptRecord{
+		ID:              job.DeliveryID,
+		EventID:         job.EventID,
+		SubscriberID:    job.SubscriberID,
+		AttemptNumber:   job.Attempt,
+		Status:          status,
+		HTTPStatusCode:  statusCode,
+		ResponseBody:    responseBody,
+		ResponseHeaders: responseHeaders,
+		ResponseTimeMs:  int(elapsed),
+		Timings:         attemptTraceFrom(ctx).timings(),
+		ErrorMessage:    errMsg,
+		FailureReason:   reason,
+		NextRetryAt:     nextRetryAt,
+		FinalURL:        job.FinalURL,
+		PayloadBytes:    job.PayloadBytes,
+	}
+	if d.recorder != nil {
+		d.recorder.Record(rec)
+		return
+	}
+
+	if err := d.store.RecordDeliveryAttempt(ctx, rec); err != nil {
+		d.jobLogger(job).Error("failed to record delivery attempt", "error", err)
+	}
+}
+
+// readResponseBody returns the part of the response body to store with the
+// attempt: at most bodyLimit bytes, redacted, or nothing for subscribers that
+// opted out of body storage.
+func (d *Deliverer) readResponseBody(body io.Reader, job engine.DeliveryJob) string {
+	if d.bodyLimit <= 0 || job.DiscardResponse {
+		io.CopyN(io.Discard, body, drainLimit)
+		return ""
+	}
+
+	readLimit := int64(d.bodyLimit)
+	if d.redactor != nil {
+		readLimit += redactLookahead
+	}
+	data, _ := io.ReadAll(io.LimitReader(body, readLimit))
+
+	text := d.redactor.Redact(string(data))
+	if len(text) > d.bodyLimit {
+		text = text[:d.bodyLimit]
+	}
+	return text
+}
+
+// checkResponse checks a 2xx response against job's success condition,
+// returning why it fails, or "" if it holds or there is none. The condition
+// sees the body before any redaction; the reader returned yields the whole
+// body again for readResponseBody.
+func checkResponse(resp *http.Response, job engine.DeliveryJob) (string, io.Reader) {
+	condition := job.SuccessCondition()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 || condition.IsZero() {
+		return "", resp.Body
+	}
+	head, _ := io.ReadAll(io.LimitReader(resp.Body, domain.MaxSuccessBodyBytes))
+	return condition.Check(head), io.MultiReader(bytes.NewReader(head), resp.Body)
+}
+
+// captureHeaders returns the named headers present in h, keyed by canonical
+// name. Repeated headers are joined with ", " and long values are truncated.
+func captureHeaders(h http.Header, names []string) map[string]string {
+	if names == nil {
+		names = DefaultCaptureHeaders
+	}
+
+	var captured map[string]string
+	for _, name := range names {
+		values := h.Values(name)
+		if len(values) == 0 {
+			continue
+		}
+		value := strings.Join(values, ", ")
+		if len(value) > maxCapturedHeaderLen {
+			value = value[:maxCapturedHeaderLen]
+		}
+		if captured == nil {
+			captured = make(map[string]string)
+		}
+		captured[http.CanonicalHeaderKey(name)] = value
+	}
+	return captured
+}
+
+// gzipBytes compresses data with gzip at the default level.
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(data); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
 	}
+	return buf.Bytes(), nil
 }
 
 // computeHMAC generates an HMAC-SHA256 signature for the payload.
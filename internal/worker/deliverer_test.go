@@ -1,10 +1,19 @@
 package worker
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
 )
 
 func TestComputeHMAC(t *testing.T) {
@@ -95,3 +104,243 @@ func TestComputeHMAC_DifferentPayloads(t *testing.T) {
 		t.Error("different payloads should produce different signatures")
 	}
 }
+
+func TestCaptureHeaders(t *testing.T) {
+	h := http.Header{}
+	h.Set("Content-Type", "application/json")
+	h.Set("x-request-id", "req-123")
+	h.Add("Retry-After", "30")
+	h.Add("Retry-After", "60")
+	h.Set("Set-Cookie", "session=secret")
+	h.Set("X-Correlation-Id", strings.Repeat("a", 300))
+
+	got := captureHeaders(h, nil)
+
+	if got["X-Request-Id"] != "req-123" || got["Content-Type"] != "application/json" {
+		t.Errorf("missing default headers: %v", got)
+	}
+	if got["Retry-After"] != "30, 60" {
+		t.Errorf("Retry-After = %q, want repeated values joined", got["Retry-After"])
+	}
+	if _, ok := got["Set-Cookie"]; ok {
+		t.Error("captured a header that was not requested")
+	}
+	if len(got["X-Correlation-Id"]) != maxCapturedHeaderLen {
+		t.Errorf("long value not truncated: %d bytes", len(got["X-Correlation-Id"]))
+	}
+
+	if got := captureHeaders(h, []string{"set-cookie"}); len(got) != 1 || got["Set-Cookie"] == "" {
+		t.Errorf("configured headers = %v, want only Set-Cookie", got)
+	}
+	if got := captureHeaders(http.Header{}, nil); got != nil {
+		t.Errorf("no matching headers = %v, want nil", got)
+	}
+}
+
+func TestReadResponseBody(t *testing.T) {
+	body := "status: jane@example.com is suspended"
+
+	d := &Deliverer{bodyLimit: 12}
+	if got := d.readResponseBody(strings.NewReader(body), engine.DeliveryJob{}); got != "status: jane" {
+		t.Errorf("limited body = %q", got)
+	}
+
+	// The address straddles the limit; reading past it lets the redactor
+	// recognise the whole match before the body is cut
+	d.redactor, _ = NewRedactor([]string{"email"}, "")
+	if got := d.readResponseBody(strings.NewReader(body), engine.DeliveryJob{}); got != "status: [RED" {
+		t.Errorf("redacted body = %q", got)
+	}
+	d.bodyLimit = 100
+	if got := d.readResponseBody(strings.NewReader(body), engine.DeliveryJob{}); got != "status: [REDACTED] is suspended" {
+		t.Errorf("redacted body = %q", got)
+	}
+
+	if got := d.readResponseBody(strings.NewReader(body), engine.DeliveryJob{DiscardResponse: true}); got != "" {
+		t.Errorf("subscriber opted out, got %q", got)
+	}
+	d.bodyLimit = 0
+	if got := d.readResponseBody(strings.NewReader(body), engine.DeliveryJob{}); got != "" {
+		t.Errorf("body storage disabled, got %q", got)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	cfg := RetryConfig{BaseDelay: time.Second}
+	if got := retryDelay(cfg, engine.DeliveryJob{Attempt: 1}, nil); got != 2*time.Second {
+		t.Errorf("attempt 1 delay = %s", got)
+	}
+	if got := retryDelay(cfg, engine.DeliveryJob{Attempt: 4}, nil); got != 16*time.Second {
+		t.Errorf("attempt 4 delay = %s", got)
+	}
+
+	cfg.MaxDelay = 10 * time.Second
+	if got := retryDelay(cfg, engine.DeliveryJob{Attempt: 4}, nil); got != 10*time.Second {
+		t.Errorf("capped delay = %s", got)
+	}
+
+	cfg.Jitter = 500 * time.Millisecond
+	for i := 0; i < 100; i++ {
+		if got := retryDelay(cfg, engine.DeliveryJob{Attempt: 1}, nil); got < 2*time.Second || got >= 2500*time.Millisecond {
+			t.Fatalf("jittered delay = %s", got)
+		}
+	}
+
+	// The same seed jitters the same way
+	a, b := engine.NewRand(42), engine.NewRand(42)
+	for i := 0; i < 10; i++ {
+		if got, want := retryDelay(cfg, engine.DeliveryJob{Attempt: 1}, a), retryDelay(cfg, engine.DeliveryJob{Attempt: 1}, b); got != want {
+			t.Fatalf("delay %d = %s with one rand and %s with the other", i, got, want)
+		}
+	}
+}
+
+func TestRetryDelay_JitterStrategies(t *testing.T) {
+	cfg := RetryConfig{BaseDelay: time.Second, MaxDelay: 20 * time.Second, Jitter: time.Second, Strategy: domain.JitterFull}
+	rng := engine.NewRand(1)
+
+	tests := []struct {
+		name     string
+		job      engine.DeliveryJob
+		min, max time.Duration
+	}{
+		{"default strategy", engine.DeliveryJob{Attempt: 3}, 0, 8 * time.Second},
+		{"full", engine.DeliveryJob{Attempt: 3, RetryJitter: domain.JitterFull}, 0, 8 * time.Second},
+		{"full capped", engine.DeliveryJob{Attempt: 10, RetryJitter: domain.JitterFull}, 0, 20 * time.Second},
+		{"equal", engine.DeliveryJob{Attempt: 3, RetryJitter: domain.JitterEqual}, 4 * time.Second, 8 * time.Second},
+		{"additive", engine.DeliveryJob{Attempt: 3, RetryJitter: domain.JitterAdditive}, 8 * time.Second, 9 * time.Second},
+		{"decorrelated first retry", engine.DeliveryJob{Attempt: 1, RetryJitter: domain.JitterDecorrelated}, time.Second, 3 * time.Second},
+		{"decorrelated", engine.DeliveryJob{Attempt: 3, RetryJitter: domain.JitterDecorrelated, RetryDelayMs: 4000}, time.Second, 12 * time.Second},
+		{"decorrelated capped", engine.DeliveryJob{Attempt: 3, RetryJitter: domain.JitterDecorrelated, RetryDelayMs: 15000}, time.Second, 20 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lowest, highest := time.Duration(1<<62), time.Duration(0)
+			for i := 0; i < 1000; i++ {
+				got := retryDelay(cfg, tt.job, rng)
+				if got < tt.min || got > tt.max {
+					t.Fatalf("delay = %s, want between %s and %s", got, tt.min, tt.max)
+				}
+				lowest, highest = min(lowest, got), max(highest, got)
+			}
+			// Spread across the range rather than bunched at one end
+			if spread := tt.max - tt.min; highest-lowest < spread*8/10 {
+				t.Errorf("delays ranged from %s to %s, want most of %s to %s", lowest, highest, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestDeliverer_ScheduleRetryByClock(t *testing.T) {
+	clock := engine.NewManualClock(time.Now())
+	queue := engine.NewMemoryQueue()
+	retry := RetryConfig{BaseDelay: time.Second, Jitter: time.Second}
+	d := &Deliverer{
+		retry: retry,
+		queue: queue,
+		clock: clock,
+		rng:   engine.NewRand(7),
+	}
+
+	next := d.scheduleRetry(context.Background(), engine.DeliveryJob{EventID: "evt-1", Attempt: 1})
+	if want := clock.Now().Add(retryDelay(retry, engine.DeliveryJob{Attempt: 1}, engine.NewRand(7))); !next.Equal(want) {
+		t.Errorf("retry scheduled at %v, want %v", next, want)
+	}
+	if at, ok, _ := queue.NextJobAt(context.Background()); !ok || !at.Equal(*next) {
+		t.Errorf("queued retry at %v, want %v", at, next)
+	}
+}
+
+func TestDeliverer_MaxRetryDuration(t *testing.T) {
+	_, _, _, hub, logger := setupDeliveryTest(t)
+	ctx := context.Background()
+	start := time.Now().Truncate(time.Millisecond)
+	clock := engine.NewManualClock(start)
+	s := store.NewMemoryStore()
+	d := &Deliverer{
+		retry:  RetryConfig{BaseDelay: time.Second, MaxDelay: time.Hour, DurationMaxDelay: 5 * time.Minute},
+		queue:  engine.NewMemoryQueue(),
+		store:  s,
+		hub:    hub,
+		logger: logger,
+		clock:  clock,
+	}
+	// fail fails job and returns the retry it queued, if any
+	fail := func(job engine.DeliveryJob) (engine.DeliveryJob, time.Time, bool) {
+		t.Helper()
+		d.handleFailure(ctx, job, time.Now(), nil, "", nil, domain.FailureHTTP5xx, "")
+		claimed, err := d.queue.Claim(ctx, start.Add(100*24*time.Hour), 10)
+		if err != nil || len(claimed) > 1 {
+			t.Fatalf("claimed %v (%v), want at most one retry", claimed, err)
+		}
+		if len(claimed) == 0 {
+			return engine.DeliveryJob{}, time.Time{}, false
+		}
+		var retry engine.DeliveryJob
+		if err := json.Unmarshal([]byte(claimed[0].Member), &retry); err != nil {
+			t.Fatalf("decoding retry: %v", err)
+		}
+		d.queue.Ack(ctx, claimed[0].Member)
+		return retry, claimed[0].ReadyAt, true
+	}
+	day := (24 * time.Hour).Milliseconds()
+
+	// Retrying for the duration keeps going past the attempt count, at the
+	// capped interval, counting from the first failure
+	job := engine.DeliveryJob{EventID: "evt-1", SubscriberID: "sub-1", Attempt: 10, MaxRetries: 10, MaxRetryDurationMs: day, RetryForDuration: true}
+	retry, at, ok := fail(job)
+	if !ok || retry.Attempt != 11 || retry.FailingSince != start.UnixMilli() || !at.Equal(start.Add(5*time.Minute)) {
+		t.Fatalf("retry = %+v at %v (%v), want attempt 11 five minutes on", retry, at, ok)
+	}
+
+	// The last retry is made as the duration ends, not after it
+	clock.Advance(24*time.Hour - time.Minute)
+	if _, at, ok := fail(retry); !ok || !at.Equal(start.Add(24*time.Hour)) {
+		t.Errorf("last retry at %v (%v), want at the end of the duration", at, ok)
+	}
+
+	// Once it has passed, the job is dead-lettered
+	clock.Advance(time.Minute)
+	if _, _, ok := fail(retry); ok {
+		t.Error("retried a job past its maximum retry duration")
+	}
+
+	// Without retrying for the duration, it dead-letters jobs with attempts left
+	if _, _, ok := fail(engine.DeliveryJob{EventID: "evt-2", SubscriberID: "sub-1", Attempt: 2, MaxRetries: 5, MaxRetryDurationMs: day, FailingSince: start.UnixMilli()}); ok {
+		t.Error("retried a job past its maximum retry duration with attempts left")
+	}
+	letters, _ := s.ListDeadLetters(ctx, store.DeadLetterFilter{SubscriberID: "sub-1"})
+	if len(letters) != 2 {
+		t.Errorf("%d dead letters, want 2", len(letters))
+	}
+}
+
+type recordingSystemEvents struct {
+	events []any
+}
+
+func (p *recordingSystemEvents) PublishSystemEvent(_ context.Context, eventType string, data any) {
+	p.events = append(p.events, data)
+}
+
+func TestMoveToDLQ_PublishesDeadLetteredEvent(t *testing.T) {
+	published := &recordingSystemEvents{}
+	d := &Deliverer{store: store.NewMemoryStore(), systemEvents: published}
+	ctx := context.Background()
+	status := http.StatusBadGateway
+
+	d.moveToDLQ(ctx, engine.DeliveryJob{EventID: "evt-1", EventType: "order.created", SubscriberID: "sub-1", Attempt: 5}, &status, domain.FailureHTTP5xx, "bad gateway")
+	if len(published.events) != 1 {
+		t.Fatalf("published %d events, want 1", len(published.events))
+	}
+	event, ok := published.events[0].(domain.DeadLetteredEvent)
+	if !ok || event.EventID != "evt-1" || event.TotalAttempts != 5 || *event.LastHTTPStatus != status {
+		t.Errorf("event = %+v", published.events[0])
+	}
+
+	// A dead-lettered system event must not publish another one
+	d.moveToDLQ(ctx, engine.DeliveryJob{EventID: "evt-2", EventType: domain.EventDeliveryDeadLettered, SubscriberID: "sub-2", Attempt: 5}, nil, domain.FailureReadTimeout, "timeout")
+	if len(published.events) != 1 {
+		t.Errorf("published %d events after a system event was dead-lettered, want 1", len(published.events))
+	}
+}
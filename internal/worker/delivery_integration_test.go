@@ -1,24 +1,31 @@
 package worker
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
 	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
 	ws "github.com/Priya8975/webhook-delivery-system/internal/websocket"
+	"github.com/Priya8975/webhook-delivery-system/pkg/webhook"
 	"github.com/alicebob/miniredis/v2"
 	"github.com/redis/go-redis/v9"
 )
 
 // setupDeliveryTest creates a deliverer with miniredis (no Postgres — just tests HTTP delivery logic).
-// Returns a deliverer without pgStore since we're testing HTTP mechanics, not DB recording.
+// Returns a deliverer without a store since we're testing HTTP mechanics, not DB recording.
 func setupDeliveryTest(t *testing.T) (*redis.Client, *engine.CircuitBreaker, *engine.RateLimiter, *ws.Hub, *slog.Logger) {
 	t.Helper()
 
@@ -29,7 +36,7 @@ func setupDeliveryTest(t *testing.T) (*redis.Client, *engine.CircuitBreaker, *en
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
 	cb := engine.NewCircuitBreaker(client, logger)
 	rl := engine.NewRateLimiter(client, logger)
-	hub := ws.NewHub(logger)
+	hub := ws.NewHub(nil, nil, logger)
 	go hub.Run()
 
 	return client, cb, rl, hub, logger
@@ -52,7 +59,7 @@ func TestDelivery_SuccessfulEndpoint(t *testing.T) {
 
 	deliverer := &Deliverer{
 		httpClient:     &http.Client{Timeout: 5 * time.Second},
-		redisClient:    redis.NewClient(&redis.Options{Addr: "localhost:0"}), // not used for this test
+		queue:          engine.NewMemoryQueue(),
 		circuitBreaker: cb,
 		rateLimiter:    rl,
 		hub:            hub,
@@ -96,6 +103,116 @@ func TestDelivery_SuccessfulEndpoint(t *testing.T) {
 	}
 }
 
+func TestDelivery_SkipsDeliveredJobs(t *testing.T) {
+	var receivedCount atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedCount.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, cb, rl, hub, logger := setupDeliveryTest(t)
+	receipts := engine.NewDeliveryReceipts(client)
+
+	deliverer := &Deliverer{
+		httpClient:     &http.Client{Timeout: 5 * time.Second},
+		queue:          engine.NewMemoryQueue(),
+		circuitBreaker: cb,
+		rateLimiter:    rl,
+		hub:            hub,
+		receipts:       receipts,
+		logger:         logger,
+	}
+
+	job := engine.DeliveryJob{
+		EventID:      "evt-receipt",
+		SubscriberID: "sub-receipt",
+		EndpointURL:  server.URL,
+		Payload:      json.RawMessage(`{"test":true}`),
+		SecretKey:    "test-secret",
+		EventType:    "test.event",
+		Attempt:      1,
+		MaxRetries:   5,
+	}
+	ctx := context.Background()
+
+	deliverer.Deliver(ctx, job)
+	if delivered, err := receipts.Delivered(ctx, job.EventID, job.SubscriberID); err != nil || !delivered {
+		t.Fatalf("Delivered = %v, %v; want a receipt after a 2xx", delivered, err)
+	}
+
+	// The same job picked up again, e.g. after a crash before it was acked
+	deliverer.Deliver(ctx, job)
+	deliverer.DeliverBatch(ctx, []engine.DeliveryJob{job})
+	if got := receivedCount.Load(); got != 1 {
+		t.Fatalf("endpoint received %d requests, want 1", got)
+	}
+
+	// An explicit redelivery clears the receipt first
+	if err := receipts.Clear(ctx, job.EventID, job.SubscriberID); err != nil {
+		t.Fatalf("Clear: %v", err)
+	}
+	deliverer.Deliver(ctx, job)
+	if got := receivedCount.Load(); got != 2 {
+		t.Errorf("endpoint received %d requests after clearing the receipt, want 2", got)
+	}
+}
+
+func TestDelivery_SandboxIsSimulated(t *testing.T) {
+	var receivedCount atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedCount.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	_, cb, rl, hub, logger := setupDeliveryTest(t)
+	s := store.NewMemoryStore()
+	deliverer := &Deliverer{
+		httpClient:     &http.Client{Timeout: 5 * time.Second},
+		store:          s,
+		queue:          engine.NewMemoryQueue(),
+		circuitBreaker: cb,
+		rateLimiter:    rl,
+		hub:            hub,
+		logger:         logger,
+	}
+
+	job := engine.DeliveryJob{
+		EventID:      "evt-sandbox",
+		SubscriberID: "sub-sandbox",
+		EndpointURL:  server.URL,
+		Payload:      json.RawMessage(`{"test":true}`),
+		SecretKey:    "test-secret",
+		EventType:    "test.event",
+		Attempt:      1,
+		MaxRetries:   5,
+		Sandbox:      true,
+	}
+	ctx := context.Background()
+	deliverer.Deliver(ctx, job)
+	batched := job
+	batched.EventID = "evt-sandbox-batch"
+	deliverer.DeliverBatch(ctx, []engine.DeliveryJob{batched})
+
+	if got := receivedCount.Load(); got != 0 {
+		t.Errorf("endpoint received %d requests, want none", got)
+	}
+	attempts, _ := s.ListDeliveryAttempts(ctx, store.DeliveryAttemptFilter{SubscriberID: job.SubscriberID})
+	if len(attempts) != 2 {
+		t.Fatalf("attempts = %+v, want one per job", attempts)
+	}
+	for _, a := range attempts {
+		if a.Status != domain.AttemptSimulated || a.HTTPStatusCode != nil {
+			t.Errorf("attempt = %+v, want simulated without a status code", a)
+		}
+	}
+	stats, _ := s.GetSubscriberStats(ctx, job.SubscriberID, time.Time{})
+	if stats.TotalAttempts != 0 {
+		t.Errorf("stats count %d attempts, want simulated ones left out", stats.TotalAttempts)
+	}
+}
+
 func TestDelivery_SignatureIsValid(t *testing.T) {
 	var receivedSig string
 	var receivedBody []byte
@@ -116,7 +233,7 @@ func TestDelivery_SignatureIsValid(t *testing.T) {
 
 	deliverer := &Deliverer{
 		httpClient:     &http.Client{Timeout: 5 * time.Second},
-		redisClient:    redis.NewClient(&redis.Options{Addr: "localhost:0"}),
+		queue:          engine.NewMemoryQueue(),
 		circuitBreaker: cb,
 		rateLimiter:    rl,
 		hub:            hub,
@@ -160,7 +277,7 @@ func TestDelivery_CircuitBreakerBlocks(t *testing.T) {
 
 	deliverer := &Deliverer{
 		httpClient:     &http.Client{Timeout: 5 * time.Second},
-		redisClient:    client,
+		queue:          engine.NewRedisQueue(client, nil),
 		circuitBreaker: cb,
 		rateLimiter:    rl,
 		hub:            hub,
@@ -184,6 +301,97 @@ func TestDelivery_CircuitBreakerBlocks(t *testing.T) {
 	}
 }
 
+func TestDelivery_CircuitBreakerSchedulesProbe(t *testing.T) {
+	client, cb, rl, hub, logger := setupDeliveryTest(t)
+	clock := engine.NewManualClock(time.Now().Truncate(time.Second))
+	cb.SetClock(clock)
+	queue := engine.NewRedisQueue(client, nil)
+	deliverer := &Deliverer{
+		httpClient:     &http.Client{Timeout: 5 * time.Second},
+		queue:          queue,
+		circuitBreaker: cb,
+		rateLimiter:    rl,
+		hub:            hub,
+		clock:          clock,
+		logger:         logger,
+	}
+
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		cb.RecordFailure(ctx, "sub-down")
+	}
+	for _, eventID := range []string{"evt-first", "evt-second"} {
+		deliverer.Deliver(ctx, engine.DeliveryJob{
+			EventID:      eventID,
+			SubscriberID: "sub-down",
+			EndpointURL:  "http://127.0.0.1:1",
+			Payload:      json.RawMessage(`{}`),
+			Attempt:      1,
+			MaxRetries:   5,
+		})
+	}
+
+	// Only the first job deferred becomes the probe, due when the cooldown ends
+	probes, _ := client.ZRangeWithScores(ctx, engine.ProbeQueueKey, 0, -1).Result()
+	if len(probes) != 1 || !strings.Contains(probes[0].Member.(string), "evt-first") {
+		t.Fatalf("probe index = %v, want only the first deferred job", probes)
+	}
+	if due := time.UnixMicro(int64(probes[0].Score)); !due.Equal(clock.Now().Add(30 * time.Second)) {
+		t.Errorf("probe due at %v, want at the end of the 30s cooldown", due)
+	}
+	if depth, _ := queue.Depth(ctx); depth != 2 {
+		t.Errorf("queue depth = %d, want both jobs deferred", depth)
+	}
+}
+
+func TestDelivery_QuotaDefersUntilReset(t *testing.T) {
+	var requestCount atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, cb, rl, hub, logger := setupDeliveryTest(t)
+	queue := engine.NewRedisQueue(client, nil)
+	quota := engine.NewDeliveryQuota(client, logger)
+	deliverer := &Deliverer{
+		httpClient:     &http.Client{Timeout: 5 * time.Second},
+		queue:          queue,
+		circuitBreaker: cb,
+		rateLimiter:    rl,
+		quota:          quota,
+		hub:            hub,
+		logger:         logger,
+	}
+
+	// The day's only delivery has been made
+	ctx := context.Background()
+	quota.Take(ctx, "sub-quota", 1, 0, 1)
+	deliverer.Deliver(ctx, engine.DeliveryJob{
+		EventID:      "evt-quota",
+		SubscriberID: "sub-quota",
+		EndpointURL:  server.URL,
+		Payload:      json.RawMessage(`{}`),
+		SecretKey:    "secret",
+		EventType:    "test.event",
+		Attempt:      1,
+		MaxRetries:   5,
+		DailyQuota:   1,
+	})
+
+	if requestCount.Load() != 0 {
+		t.Fatalf("a delivery over quota reached the endpoint")
+	}
+	pending, err := queue.PendingJobs(ctx, "sub-quota")
+	if err != nil || len(pending) != 1 {
+		t.Fatalf("PendingJobs = %+v, %v, want the deferred job", pending, err)
+	}
+	if at := pending[0].NextAttemptAt; at == nil || at.Sub(domain.DayEnd(time.Now())).Abs() > time.Second || pending[0].Attempt != 1 {
+		t.Errorf("deferred job = %+v, want attempt 1 at midnight UTC", pending[0])
+	}
+}
+
 func TestWorkerPool_ProcessesJobs(t *testing.T) {
 	var processed atomic.Int32
 
@@ -198,7 +406,7 @@ func TestWorkerPool_ProcessesJobs(t *testing.T) {
 
 	deliverer := &Deliverer{
 		httpClient:     &http.Client{Timeout: 5 * time.Second},
-		redisClient:    redis.NewClient(&redis.Options{Addr: "localhost:0"}),
+		queue:          engine.NewMemoryQueue(),
 		circuitBreaker: cb,
 		rateLimiter:    rl,
 		hub:            hub,
@@ -206,7 +414,7 @@ func TestWorkerPool_ProcessesJobs(t *testing.T) {
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
-	pool := NewPool(3, deliverer, logger)
+	pool := NewPool(3, deliverer, nil, logger)
 	pool.Start(ctx)
 
 	// Submit 5 jobs
@@ -233,3 +441,423 @@ func TestWorkerPool_ProcessesJobs(t *testing.T) {
 		t.Errorf("expected 5 jobs processed, got %d", processed.Load())
 	}
 }
+
+func TestDelivery_GzipLargePayload(t *testing.T) {
+	var contentEncoding string
+	var verifiedPayload []byte
+	var verifyErr error
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentEncoding = r.Header.Get("Content-Encoding")
+		verifiedPayload, verifyErr = webhook.VerifyRequest(r, "gzip-secret")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	_, cb, rl, hub, logger := setupDeliveryTest(t)
+
+	deliverer := &Deliverer{
+		httpClient:     &http.Client{Timeout: 5 * time.Second},
+		gzipThreshold:  64,
+		queue:          engine.NewMemoryQueue(),
+		circuitBreaker: cb,
+		rateLimiter:    rl,
+		hub:            hub,
+		logger:         logger,
+	}
+
+	payload := json.RawMessage(`{"data":"` + strings.Repeat("x", 200) + `"}`)
+
+	deliverer.Deliver(context.Background(), engine.DeliveryJob{
+		EventID:         "evt-gzip",
+		SubscriberID:    "sub-gzip",
+		EndpointURL:     server.URL,
+		Payload:         payload,
+		SecretKey:       "gzip-secret",
+		EventType:       "test.event",
+		Attempt:         1,
+		MaxRetries:      5,
+		CompressPayload: true,
+	})
+
+	if contentEncoding != "gzip" {
+		t.Errorf("Content-Encoding = %q, want gzip", contentEncoding)
+	}
+	if verifyErr != nil {
+		t.Fatalf("signature over uncompressed payload should verify: %v", verifyErr)
+	}
+	if string(verifiedPayload) != string(payload) {
+		t.Errorf("decompressed payload mismatch")
+	}
+}
+
+// memoryObjects is an engine.ObjectStore in memory that counts reads.
+type memoryObjects struct {
+	objects map[string][]byte
+	gets    atomic.Int32
+}
+
+func (m *memoryObjects) PutObject(_ context.Context, key string, body []byte, _ string) error {
+	m.objects[key] = body
+	return nil
+}
+
+func (m *memoryObjects) GetObject(_ context.Context, key string) (io.ReadCloser, error) {
+	m.gets.Add(1)
+	body, ok := m.objects[key]
+	if !ok {
+		return nil, fmt.Errorf("no object %s", key)
+	}
+	return io.NopCloser(bytes.NewReader(body)), nil
+}
+
+func TestDelivery_StreamsOffloadedPayload(t *testing.T) {
+	payload := []byte(`{"data":"` + strings.Repeat("x", 200) + `"}`)
+	ref := &domain.PayloadRef{Key: "archive/payloads/abc.json", Size: int64(len(payload))}
+
+	for _, compress := range []bool{false, true} {
+		t.Run(fmt.Sprintf("compress=%v", compress), func(t *testing.T) {
+			var contentEncoding string
+			var contentLength int64
+			var verifiedPayload []byte
+			var verifyErr error
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				contentEncoding = r.Header.Get("Content-Encoding")
+				contentLength = r.ContentLength
+				verifiedPayload, verifyErr = webhook.VerifyRequest(r, "stream-secret")
+			}))
+			defer server.Close()
+
+			_, cb, rl, hub, logger := setupDeliveryTest(t)
+			objects := &memoryObjects{objects: map[string][]byte{ref.Key: payload}}
+			s := store.NewMemoryStore()
+			deliverer := &Deliverer{
+				httpClient:     &http.Client{Timeout: 5 * time.Second},
+				gzipThreshold:  64,
+				store:          s,
+				objects:        objects,
+				queue:          engine.NewMemoryQueue(),
+				circuitBreaker: cb,
+				rateLimiter:    rl,
+				hub:            hub,
+				logger:         logger,
+			}
+			deliverer.Deliver(context.Background(), engine.DeliveryJob{
+				EventID:         "evt-stream",
+				SubscriberID:    "sub-stream",
+				EndpointURL:     server.URL,
+				PayloadRef:      ref,
+				SecretKey:       "stream-secret",
+				EventType:       "file.uploaded",
+				Attempt:         1,
+				MaxRetries:      3,
+				CompressPayload: compress,
+			})
+
+			if verifyErr != nil {
+				t.Fatalf("signature over the streamed payload should verify: %v", verifyErr)
+			}
+			if string(verifiedPayload) != string(payload) {
+				t.Errorf("endpoint got %q, want the offloaded payload", verifiedPayload)
+			}
+			// Read once for the signature and once for the body
+			if n := objects.gets.Load(); n != 2 {
+				t.Errorf("payload fetched %d times, want 2", n)
+			}
+			if compress {
+				if contentEncoding != "gzip" || contentLength != -1 {
+					t.Errorf("got Content-Encoding %q and length %d, want gzip sent chunked", contentEncoding, contentLength)
+				}
+			} else if contentEncoding != "" || contentLength != ref.Size {
+				t.Errorf("got Content-Encoding %q and length %d, want the payload as is", contentEncoding, contentLength)
+			}
+			attempts, _ := s.ListDeliveryAttempts(context.Background(), store.DeliveryAttemptFilter{EventID: "evt-stream"})
+			if len(attempts) != 1 || attempts[0].Status != "success" {
+				t.Errorf("attempts = %+v, want one success", attempts)
+			}
+		})
+	}
+
+	// Without object storage the attempt fails instead of sending nothing
+	_, cb, rl, hub, logger := setupDeliveryTest(t)
+	s := store.NewMemoryStore()
+	deliverer := &Deliverer{
+		httpClient:     &http.Client{Timeout: 5 * time.Second},
+		store:          s,
+		queue:          engine.NewMemoryQueue(),
+		circuitBreaker: cb,
+		rateLimiter:    rl,
+		hub:            hub,
+		logger:         logger,
+	}
+	deliverer.Deliver(context.Background(), engine.DeliveryJob{
+		EventID: "evt-unreachable", SubscriberID: "sub-stream", EndpointURL: "http://127.0.0.1:1",
+		PayloadRef: ref, SecretKey: "stream-secret", EventType: "file.uploaded", Attempt: 1, MaxRetries: 3,
+	})
+	attempts, _ := s.ListDeliveryAttempts(context.Background(), store.DeliveryAttemptFilter{EventID: "evt-unreachable"})
+	if len(attempts) != 1 || attempts[0].Status == "success" || attempts[0].ErrorMessage == nil {
+		t.Errorf("attempts = %+v, want one failure", attempts)
+	}
+}
+
+func TestDelivery_GzipSkippedBelowThreshold(t *testing.T) {
+	var contentEncoding string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentEncoding = r.Header.Get("Content-Encoding")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	_, cb, rl, hub, logger := setupDeliveryTest(t)
+
+	deliverer := &Deliverer{
+		httpClient:     &http.Client{Timeout: 5 * time.Second},
+		gzipThreshold:  1024,
+		queue:          engine.NewMemoryQueue(),
+		circuitBreaker: cb,
+		rateLimiter:    rl,
+		hub:            hub,
+		logger:         logger,
+	}
+
+	deliverer.Deliver(context.Background(), engine.DeliveryJob{
+		EventID:         "evt-small",
+		SubscriberID:    "sub-small",
+		EndpointURL:     server.URL,
+		Payload:         json.RawMessage(`{"small":true}`),
+		SecretKey:       "secret",
+		EventType:       "test.event",
+		Attempt:         1,
+		MaxRetries:      5,
+		CompressPayload: true,
+	})
+
+	if contentEncoding != "" {
+		t.Errorf("small payload should not be compressed, got Content-Encoding %q", contentEncoding)
+	}
+}
+
+func TestDelivery_RecordsAttemptAndDeadLetterInStore(t *testing.T) {
+	var deliveryID string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deliveryID = r.Header.Get("X-Webhook-Delivery-ID")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("down for maintenance"))
+	}))
+	defer server.Close()
+
+	client, cb, rl, hub, logger := setupDeliveryTest(t)
+	s := store.NewMemoryStore()
+	ctx := context.Background()
+
+	event, err := s.CreateEvent(ctx, "order.created", 1, []byte(`{"order_id":"abc-123"}`), nil, "", nil)
+	if err != nil {
+		t.Fatalf("CreateEvent failed: %v", err)
+	}
+
+	deliverer := &Deliverer{
+		httpClient:     &http.Client{Timeout: 5 * time.Second},
+		bodyLimit:      1024,
+		payloads:       newPayloadResolver(engine.NewRedisQueue(client, nil), s, 10),
+		store:          s,
+		queue:          engine.NewRedisQueue(client, nil),
+		circuitBreaker: cb,
+		rateLimiter:    rl,
+		hub:            hub,
+		logger:         logger,
+	}
+
+	// Final attempt, with the payload left for the resolver to load
+	deliverer.Deliver(ctx, engine.DeliveryJob{
+		EventID:      event.ID,
+		SubscriberID: "sub-store",
+		EndpointURL:  server.URL,
+		SecretKey:    "secret",
+		EventType:    event.EventType,
+		Attempt:      3,
+		MaxRetries:   3,
+	})
+
+	attempts, _ := s.ListDeliveryAttempts(ctx, store.DeliveryAttemptFilter{EventID: event.ID})
+	if len(attempts) != 1 {
+		t.Fatalf("recorded %d attempts, want 1", len(attempts))
+	}
+	a := attempts[0]
+	if a.Status != "failed" || a.HTTPStatusCode == nil || *a.HTTPStatusCode != http.StatusServiceUnavailable {
+		t.Errorf("attempt = %+v, want a failed 503", a)
+	}
+	if a.ResponseBody == nil || *a.ResponseBody != "down for maintenance" {
+		t.Errorf("response body = %v", a.ResponseBody)
+	}
+	if deliveryID == "" || a.ID != deliveryID {
+		t.Errorf("attempt ID = %q, want the X-Webhook-Delivery-ID sent (%q)", a.ID, deliveryID)
+	}
+	if a.FailureReason == nil || *a.FailureReason != string(domain.FailureHTTP5xx) {
+		t.Errorf("attempt failure reason = %v, want http_5xx", a.FailureReason)
+	}
+
+	letters, _ := s.ListDeadLetters(ctx, store.DeadLetterFilter{SubscriberID: "sub-store"})
+	if len(letters) != 1 || letters[0].TotalAttempts != 3 {
+		t.Fatalf("dead letters = %+v, want one after 3 attempts", letters)
+	}
+	if r := letters[0].FailureReason; r == nil || *r != string(domain.FailureHTTP5xx) {
+		t.Errorf("dead letter failure reason = %v, want http_5xx", r)
+	}
+	dl, _ := s.GetDeadLetter(ctx, letters[0].ID)
+	if snapshot := dl.SnapshotEvent(); snapshot == nil || string(snapshot.Payload) != `{"order_id":"abc-123"}` {
+		t.Errorf("dead letter snapshot = %+v", snapshot)
+	}
+}
+
+func TestDelivery_SuccessConditionRetriesErrorBodies(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The endpoint answers 200 either way, with the outcome in the body
+		if requests.Add(1) == 1 {
+			w.Write([]byte(`{"status":"error","message":"ledger locked"}`))
+			return
+		}
+		w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer server.Close()
+
+	_, cb, rl, hub, logger := setupDeliveryTest(t)
+	s := store.NewMemoryStore()
+	queue := engine.NewMemoryQueue()
+	ctx := context.Background()
+
+	deliverer := &Deliverer{
+		httpClient:     &http.Client{Timeout: 5 * time.Second},
+		bodyLimit:      1024,
+		store:          s,
+		queue:          queue,
+		circuitBreaker: cb,
+		rateLimiter:    rl,
+		hub:            hub,
+		logger:         logger,
+	}
+
+	job := engine.DeliveryJob{
+		EventID:         "evt-ledger",
+		SubscriberID:    "sub-ledger",
+		EndpointURL:     server.URL,
+		Payload:         json.RawMessage(`{"amount":42}`),
+		SecretKey:       "secret",
+		EventType:       "payment.settled",
+		Attempt:         1,
+		MaxRetries:      3,
+		SuccessJSONPath: `$.status == "ok"`,
+	}
+	deliverer.Deliver(ctx, job)
+
+	attempts, _ := s.ListDeliveryAttempts(ctx, store.DeliveryAttemptFilter{EventID: job.EventID})
+	if len(attempts) != 1 {
+		t.Fatalf("recorded %d attempts, want 1", len(attempts))
+	}
+	a := attempts[0]
+	if a.Status != "failed" || a.FailureReason == nil || *a.FailureReason != string(domain.FailureUnexpectedBody) {
+		t.Errorf("attempt = %+v, want failed with unexpected_body", a)
+	}
+	if a.ResponseBody == nil || !strings.Contains(*a.ResponseBody, "ledger locked") {
+		t.Errorf("response body = %v, want the error body stored", a.ResponseBody)
+	}
+	if _, ok, _ := queue.NextJobAt(ctx); !ok {
+		t.Fatal("no retry queued for a 200 failing the success condition")
+	}
+
+	job.Attempt = 2
+	deliverer.Deliver(ctx, job)
+	attempts, _ = s.ListDeliveryAttempts(ctx, store.DeliveryAttemptFilter{EventID: job.EventID})
+	if len(attempts) != 2 || attempts[0].Status != "success" {
+		t.Errorf("attempts = %+v, want the retry delivered", attempts)
+	}
+}
+
+func TestDelivery_RedirectPolicy(t *testing.T) {
+	var received atomic.Pointer[http.Request]
+	var receivedBody atomic.Value
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := make([]byte, 1024)
+		n, _ := r.Body.Read(body)
+		receivedBody.Store(string(body[:n]))
+		received.Store(r)
+	}))
+	defer target.Close()
+	// The same server under another hostname
+	elsewhere := strings.Replace(target.URL, "127.0.0.1", "localhost", 1)
+
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A 302, which the client alone would follow as a GET without the payload
+		http.Redirect(w, r, r.URL.Query().Get("to")+"/moved", http.StatusFound)
+	}))
+	defer origin.Close()
+
+	_, cb, rl, hub, logger := setupDeliveryTest(t)
+	tests := []struct {
+		name, policy, to string
+		followed         bool
+	}{
+		{"never", domain.RedirectNever, target.URL, false},
+		{"same host", domain.RedirectSameHost, target.URL, true},
+		{"other host", domain.RedirectSameHost, elsewhere, false},
+		{"default is same host", "", elsewhere, false},
+		{"follow and re-sign", domain.RedirectFollowResign, elsewhere, true},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received.Store(nil)
+			s := store.NewMemoryStore()
+			deliverer := &Deliverer{
+				httpClient:     &http.Client{Timeout: 5 * time.Second},
+				store:          s,
+				queue:          engine.NewMemoryQueue(),
+				circuitBreaker: cb,
+				rateLimiter:    rl,
+				hub:            hub,
+				logger:         logger,
+			}
+			job := engine.DeliveryJob{
+				EventID:        "evt-redirect",
+				SubscriberID:   fmt.Sprintf("sub-redirect-%d", i),
+				EndpointURL:    origin.URL + "/hook?to=" + tt.to,
+				Payload:        json.RawMessage(`{"order_id":"abc-123"}`),
+				SecretKey:      "secret",
+				EventType:      "order.created",
+				Attempt:        1,
+				MaxRetries:     3,
+				RedirectPolicy: tt.policy,
+			}
+			deliverer.Deliver(context.Background(), job)
+
+			attempts, _ := s.ListDeliveryAttempts(context.Background(), store.DeliveryAttemptFilter{EventID: job.EventID})
+			if len(attempts) != 1 {
+				t.Fatalf("recorded %d attempts, want 1", len(attempts))
+			}
+			a := attempts[0]
+			req := received.Load()
+			if !tt.followed {
+				if req != nil {
+					t.Error("the redirect was followed")
+				}
+				if a.Status != "failed" || a.HTTPStatusCode == nil || *a.HTTPStatusCode != http.StatusFound || a.FinalURL != nil {
+					t.Errorf("attempt = %+v, want failed with the 302 and no final URL", a)
+				}
+				return
+			}
+
+			if req == nil {
+				t.Fatal("the redirect wasn't followed")
+			}
+			if req.Method != http.MethodPost || receivedBody.Load() != string(job.Payload) {
+				t.Errorf("target got %s %q, want the POST of the payload", req.Method, receivedBody.Load())
+			}
+			if sig := req.Header.Get("X-Webhook-Signature"); sig != computeHMAC(job.Payload, job.SecretKey) {
+				t.Errorf("target got signature %q, want the payload's", sig)
+			}
+			if a.Status != "success" || a.FinalURL == nil || *a.FinalURL != tt.to+"/moved" {
+				t.Errorf("attempt = %+v, want success with final URL %s/moved", a, tt.to)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package worker
+
+import (
+	"context"
+	"net"
+	"slices"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+)
+
+// splitAddrs splits an endpoint's addresses into those dialed first and
+// those raced against them after the fallback delay, by IP family. With
+// domain.IPPreferenceAuto the family of the resolver's first address goes
+// first; the *_only preferences leave no fallbacks. The resolver's order
+// is kept within each family.
+func splitAddrs(ips []net.IP, preference string) (primaries, fallbacks []net.IP) {
+	var v4, v6 []net.IP
+	for _, ip := range ips {
+		if ip.To4() != nil {
+			v4 = append(v4, ip)
+		} else {
+			v6 = append(v6, ip)
+		}
+	}
+	switch preference {
+	case domain.IPPreferenceIPv4Only:
+		return v4, nil
+	case domain.IPPreferenceIPv6Only:
+		return v6, nil
+	case domain.IPPreferenceIPv4:
+		primaries, fallbacks = v4, v6
+	case domain.IPPreferenceIPv6:
+		primaries, fallbacks = v6, v4
+	default:
+		primaries, fallbacks = v4, v6
+		if len(ips) > 0 && ips[0].To4() == nil {
+			primaries, fallbacks = v6, v4
+		}
+	}
+	if len(primaries) == 0 {
+		return fallbacks, nil
+	}
+	return primaries, fallbacks
+}
+
+// noAddressError is returned for an endpoint with no address left to dial
+// once the IP preference has dropped the other family.
+func noAddressError(host, preference string) error {
+	family := "IPv4"
+	if preference == domain.IPPreferenceIPv6Only {
+		family = "IPv6"
+	}
+	return &net.DNSError{Err: "no " + family + " address", Name: host, IsNotFound: true}
+}
+
+// dialAddrs connects to one of an endpoint's addresses. The primaries are
+// tried one after another; once they have had fallbackDelay to connect,
+// or have all failed, the fallbacks are tried alongside them (happy
+// eyeballs, RFC 8305). An endpoint whose IPv6 addresses accept no
+// connections is then reached over IPv4 without waiting for each IPv6
+// connect to time out. The first connection made wins.
+func (d *deliveryDialer) dialAddrs(ctx context.Context, network, addr, host, port string, primaries, fallbacks []net.IP) (net.Conn, error) {
+	if len(fallbacks) == 0 || d.fallbackDelay <= 0 {
+		return d.dialSerial(ctx, network, addr, host, port, slices.Concat(primaries, fallbacks))
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type dialResult struct {
+		conn    net.Conn
+		err     error
+		primary bool
+	}
+	results := make(chan dialResult, 2)
+	race := func(ips []net.IP, primary bool) {
+		go func() {
+			conn, err := d.dialSerial(ctx, network, addr, host, port, ips)
+			results <- dialResult{conn, err, primary}
+		}()
+	}
+	race(primaries, true)
+	pending, fallbackStarted := 1, false
+	startFallback := func() {
+		if !fallbackStarted {
+			fallbackStarted = true
+			pending++
+			race(fallbacks, false)
+		}
+	}
+	timer := time.NewTimer(d.fallbackDelay)
+	defer timer.Stop()
+
+	var primaryErr error
+	for {
+		select {
+		case <-timer.C:
+			startFallback()
+		case r := <-results:
+			pending--
+			if r.err == nil {
+				if pending > 0 {
+					// The loser is cancelled, but may have connected already
+					go func() {
+						if lost := <-results; lost.conn != nil {
+							lost.conn.Close()
+						}
+					}()
+				}
+				return r.conn, nil
+			}
+			if r.primary {
+				primaryErr = r.err
+				startFallback()
+			}
+			// Both have failed; the primaries' error is the more telling
+			if pending == 0 {
+				return nil, primaryErr
+			}
+		}
+	}
+}
+
+// dialSerial dials ips one after another, each for up to the dialer's
+// timeout, refusing private addresses with blockPrivate.
+func (d *deliveryDialer) dialSerial(ctx context.Context, network, addr, host, port string, ips []net.IP) (net.Conn, error) {
+	var lastErr error
+	for _, ip := range ips {
+		if d.blockPrivate && isPrivateIP(ip) {
+			lastErr = &blockedAddressError{addr: addr, host: host, ip: ip}
+			continue
+		}
+		conn, err := d.dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	if lastErr == nil {
+		lastErr = &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	return nil, lastErr
+}
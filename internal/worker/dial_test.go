@@ -0,0 +1,135 @@
+package worker
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+)
+
+func TestSplitAddrs(t *testing.T) {
+	v4a, v4b, v6 := net.ParseIP("203.0.113.7"), net.ParseIP("203.0.113.8"), net.ParseIP("2001:db8::1")
+	tests := []struct {
+		preference string
+		ips        []net.IP
+		primaries  []net.IP
+		fallbacks  []net.IP
+	}{
+		{domain.IPPreferenceAuto, []net.IP{v6, v4a, v4b}, []net.IP{v6}, []net.IP{v4a, v4b}},
+		{domain.IPPreferenceAuto, []net.IP{v4b, v6, v4a}, []net.IP{v4b, v4a}, []net.IP{v6}},
+		{"", []net.IP{v6, v4a}, []net.IP{v6}, []net.IP{v4a}},
+		{domain.IPPreferenceIPv4, []net.IP{v6, v4a}, []net.IP{v4a}, []net.IP{v6}},
+		{domain.IPPreferenceIPv4, []net.IP{v6}, []net.IP{v6}, nil},
+		{domain.IPPreferenceIPv6, []net.IP{v4a, v6}, []net.IP{v6}, []net.IP{v4a}},
+		{domain.IPPreferenceIPv4Only, []net.IP{v6, v4a}, []net.IP{v4a}, nil},
+		{domain.IPPreferenceIPv6Only, []net.IP{v4a}, nil, nil},
+	}
+	equal := func(a, b []net.IP) bool { return slices.EqualFunc(a, b, net.IP.Equal) }
+	for _, tt := range tests {
+		primaries, fallbacks := splitAddrs(tt.ips, tt.preference)
+		if !equal(primaries, tt.primaries) || !equal(fallbacks, tt.fallbacks) {
+			t.Errorf("splitAddrs(%v, %q) = %v, %v, want %v, %v", tt.ips, tt.preference, primaries, fallbacks, tt.primaries, tt.fallbacks)
+		}
+	}
+}
+
+// hangingIPv6Dialer returns a dialer for an endpoint resolving to an IPv6
+// address that never answers, ahead of the loopback address server listens
+// on, and the addresses it dials in order.
+func hangingIPv6Dialer(preference string, fallbackDelay time.Duration) (*deliveryDialer, func() []string) {
+	var mu sync.Mutex
+	var dialed []string
+	d := &deliveryDialer{
+		dialer: &net.Dialer{
+			Timeout: 2 * time.Second,
+			ControlContext: func(ctx context.Context, network, address string, _ syscall.RawConn) error {
+				mu.Lock()
+				dialed = append(dialed, address)
+				mu.Unlock()
+				if strings.HasPrefix(address, "[") {
+					<-ctx.Done()
+					return ctx.Err()
+				}
+				return nil
+			},
+		},
+		dns:           newDNSCache(time.Minute),
+		ipPreference:  preference,
+		fallbackDelay: fallbackDelay,
+	}
+	d.dns.lookup = func(ctx context.Context, host string) ([]net.IPAddr, error) {
+		return []net.IPAddr{{IP: net.ParseIP("2001:db8::1")}, {IP: net.ParseIP("127.0.0.1")}}, nil
+	}
+	return d, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(dialed)
+	}
+}
+
+func TestDeliveryDialer_FallsBackFromHangingIPv6(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	addr := net.JoinHostPort("dual.example.com", port)
+
+	// The IPv6 address gets a head start, then IPv4 connects
+	d, dialed := hangingIPv6Dialer(domain.IPPreferenceAuto, 50*time.Millisecond)
+	start := time.Now()
+	conn, err := d.DialContext(context.Background(), "tcp", addr)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	conn.Close()
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("dial took %s, want IPv4 raced in after the fallback delay", elapsed)
+	}
+	if got := dialed(); len(got) != 2 || !strings.HasPrefix(got[0], "[2001:db8::1]") {
+		t.Errorf("dialed %v, want IPv6 first", got)
+	}
+
+	// Preferring IPv4 never waits on IPv6
+	d, dialed = hangingIPv6Dialer(domain.IPPreferenceIPv4, 50*time.Millisecond)
+	conn, err = d.DialContext(context.Background(), "tcp", addr)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	conn.Close()
+	if got := dialed(); len(got) != 1 || !strings.HasPrefix(got[0], "127.0.0.1") {
+		t.Errorf("dialed %v, want only IPv4", got)
+	}
+
+	// Without a fallback delay the IPv6 address is given its full timeout
+	d, _ = hangingIPv6Dialer(domain.IPPreferenceAuto, 0)
+	d.dialer.Timeout = 100 * time.Millisecond
+	start = time.Now()
+	conn, err = d.DialContext(context.Background(), "tcp", addr)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	conn.Close()
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("dial took %s, want it to wait out the IPv6 timeout", elapsed)
+	}
+
+	// An endpoint without an address of the only family allowed fails
+	d, _ = hangingIPv6Dialer(domain.IPPreferenceIPv4Only, 0)
+	d.dns.lookup = func(ctx context.Context, host string) ([]net.IPAddr, error) {
+		return []net.IPAddr{{IP: net.ParseIP("2001:db8::1")}}, nil
+	}
+	_, err = d.DialContext(context.Background(), "tcp", addr)
+	if err == nil || !strings.Contains(err.Error(), "no IPv4 address") {
+		t.Errorf("expected no IPv4 address, got %v", err)
+	}
+	if classifyError(err) != domain.FailureDNS {
+		t.Errorf("classified as %s, want %s", classifyError(err), domain.FailureDNS)
+	}
+}
@@ -4,96 +4,263 @@ import (
 	"context"
 	"encoding/json"
 	"log/slog"
-	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/Priya8975/webhook-delivery-system/internal/chaos"
 	"github.com/Priya8975/webhook-delivery-system/internal/engine"
-	"github.com/redis/go-redis/v9"
 )
 
-// Dispatcher continuously polls the Redis delivery queue and sends jobs
-// to the worker pool via channels.
+// Dispatcher moves ready jobs from the delivery queue into the worker pool.
+//
+// Rather than polling on a fixed interval, it claims every ready job and then
+// waits until either a producer signals new work or the earliest scheduled
+// job becomes due. Idle systems issue roughly one Redis call per maxWait,
+// while new jobs are picked up within milliseconds.
+//
+// Batches are sized to the pool's free slots so jobs stay in the queue
+// (visible to other instances and to queue depth metrics) rather than piling
+// up in-process behind busy workers.
 type Dispatcher struct {
-	redisClient  *redis.Client
-	pool         *Pool
-	logger       *slog.Logger
-	pollInterval time.Duration
-	batchSize    int64
+	queue         engine.Queue
+	pool          *Pool
+	logger        *slog.Logger
+	maxWait       time.Duration
+	maxBatchSize  atomic.Int64
+	submitTimeout time.Duration
+	chaos         *chaos.Injector
+	clock         engine.Clock
+
+	lagMs   atomic.Int64 // how late the oldest job in the last batch was picked up
+	running atomic.Bool
+
+	statsMu sync.Mutex
+	stats   DispatcherStats
+}
+
+// DispatcherStats describes the work and timing of the dispatch loop since
+// it started, for diagnosing delivery latency. A poll is one claim from the
+// queue plus the handoff of the claimed jobs to the pool; averages are
+// moving averages weighted towards recent polls.
+type DispatcherStats struct {
+	Running       bool    `json:"running"`
+	LagMs         int64   `json:"lag_ms"`
+	Polls         int64   `json:"polls"`
+	JobsClaimed   int64   `json:"jobs_claimed"`
+	PoolFullWaits int64   `json:"pool_full_waits"` // times the loop found no free worker slot
+	LastPollMs    float64 `json:"last_poll_ms"`
+	AvgPollMs     float64 `json:"avg_poll_ms"`
+	MaxPollMs     float64 `json:"max_poll_ms"`
+	AvgClaimMs    float64 `json:"avg_claim_ms"`  // time spent claiming from the queue
+	AvgSubmitMs   float64 `json:"avg_submit_ms"` // time spent waiting for the pool to take jobs
 }
 
-// NewDispatcher creates a dispatcher that pulls from the Redis sorted set.
-func NewDispatcher(redisClient *redis.Client, pool *Pool, logger *slog.Logger) *Dispatcher {
-	return &Dispatcher{
-		redisClient:  redisClient,
-		pool:         pool,
-		logger:       logger,
-		pollInterval: 100 * time.Millisecond,
-		batchSize:    10,
+// NewDispatcher creates a dispatcher that pulls from the delivery queue.
+func NewDispatcher(queue engine.Queue, pool *Pool, logger *slog.Logger) *Dispatcher {
+	d := &Dispatcher{
+		queue:         queue,
+		pool:          pool,
+		logger:        logger,
+		maxWait:       1 * time.Second,
+		submitTimeout: 50 * time.Millisecond,
+		clock:         engine.SystemClock{},
 	}
+	d.SetBatchSize(100)
+	return d
+}
+
+// SetBatchSize caps the jobs claimed from the queue at once. It is safe to
+// call while the dispatcher is running and applies from its next batch.
+func (d *Dispatcher) SetBatchSize(n int) {
+	d.maxBatchSize.Store(int64(n))
+}
+
+// SetChaos makes the dispatcher hold each batch of claimed jobs for the
+// dispatch delay c injects. Call it before Start.
+func (d *Dispatcher) SetChaos(c *chaos.Injector) {
+	d.chaos = c
 }
 
-// Start begins the polling loop. It runs until the context is cancelled.
+// SetClock sets the clock that decides which queued jobs are due, which must
+// be the one jobs are scheduled by. Call it before Start.
+func (d *Dispatcher) SetClock(c engine.Clock) {
+	d.clock = c
+}
+
+// Start begins the dispatch loop. It runs until the context is cancelled.
 func (d *Dispatcher) Start(ctx context.Context) {
+	d.running.Store(true)
+	defer d.running.Store(false)
 	d.logger.Info("dispatcher started")
 
-	ticker := time.NewTicker(d.pollInterval)
-	defer ticker.Stop()
-
 	for {
-		select {
-		case <-ctx.Done():
+		if ctx.Err() != nil {
 			d.logger.Info("dispatcher stopping")
 			return
-		case <-ticker.C:
-			d.poll(ctx)
 		}
+
+		free := d.pool.FreeSlots()
+		if free == 0 {
+			// All workers busy and buffer full — leave jobs queued
+			d.statsMu.Lock()
+			d.stats.PoolFullWaits++
+			d.statsMu.Unlock()
+			d.pool.WaitForSlot(ctx, d.maxWait)
+			continue
+		}
+
+		batch := min(free, int(d.maxBatchSize.Load()))
+
+		// Keep claiming while full batches are coming back
+		n, err := d.poll(ctx, batch)
+		if err != nil {
+			// Back off: the next job may already be due, and wait would
+			// return at once
+			sleepCtx(ctx, d.maxWait)
+			continue
+		}
+		if n == batch {
+			continue
+		}
+
+		d.wait(ctx)
 	}
 }
 
-// poll fetches a batch of ready jobs from Redis and sends them to workers.
-func (d *Dispatcher) poll(ctx context.Context) {
-	now := float64(time.Now().UnixMicro())
-
-	// Fetch jobs with score <= now (ready for delivery)
-	results, err := d.redisClient.ZRangeByScoreWithScores(ctx, engine.DeliveryQueueKey, &redis.ZRangeBy{
-		Min:   "-inf",
-		Max:   formatFloat(now),
-		Count: d.batchSize,
-	}).Result()
+// poll claims up to batch ready jobs from the queue and sends them to workers.
+// Returns the number of jobs claimed, or the error the claim failed with.
+func (d *Dispatcher) poll(ctx context.Context, batch int) (int, error) {
+	start := time.Now()
+	now := d.clock.Now()
+	claimed, err := d.queue.Claim(ctx, now, int64(batch))
+	claimedAt := time.Now()
+	defer func() { d.recordPoll(len(claimed), claimedAt.Sub(start), time.Since(claimedAt)) }()
 	if err != nil {
-		d.logger.Error("failed to poll delivery queue", "error", err)
-		return
+		if ctx.Err() == nil {
+			d.logger.Error("failed to poll delivery queue", "error", err)
+		}
+		return 0, err
 	}
 
-	if len(results) == 0 {
-		return
+	if len(claimed) == 0 {
+		d.lagMs.Store(0)
+		return 0, nil
 	}
+	d.lagMs.Store(now.Sub(claimed[0].ReadyAt).Milliseconds())
 
-	// Remove fetched jobs atomically and dispatch them
-	for _, z := range results {
-		member := z.Member.(string)
+	if delay := d.chaos.DispatchDelay(); delay > 0 {
+		sleepCtx(ctx, delay)
+	}
 
-		// Remove from queue — if another worker already took it, ZRem returns 0
-		removed, err := d.redisClient.ZRem(ctx, engine.DeliveryQueueKey, member).Result()
-		if err != nil {
-			d.logger.Error("failed to remove job from queue", "error", err)
+	for _, c := range claimed {
+		var job engine.DeliveryJob
+		if err := json.Unmarshal([]byte(c.Member), &job); err != nil {
+			d.logger.Error("failed to unmarshal job", "error", err)
+			d.queue.Ack(ctx, c.Member)
 			continue
 		}
-		if removed == 0 {
-			// Another dispatcher instance already claimed this job
-			continue
+		job.Claim = c.Member
+
+		if !d.pool.TrySubmit(job, d.submitTimeout) {
+			// Pool filled up since we sized the batch — hand the job back.
+			// This runs even during shutdown so the claim doesn't sit until
+			// the instance is reaped.
+			bg := context.WithoutCancel(ctx)
+			if err := d.queue.Enqueue(bg, job, c.ReadyAt); err != nil {
+				d.logger.Error("failed to requeue job after full pool", "error", err, "event_id", job.EventID)
+				continue
+			}
+			if err := d.queue.Ack(bg, c.Member); err != nil {
+				d.logger.Error("failed to release claim", "error", err, "event_id", job.EventID)
+			}
 		}
+	}
 
-		var job engine.DeliveryJob
-		if err := json.Unmarshal([]byte(member), &job); err != nil {
-			d.logger.Error("failed to unmarshal job", "error", err)
-			continue
+	return len(claimed), nil
+}
+
+// recordPoll adds a poll that claimed jobs to the loop statistics.
+func (d *Dispatcher) recordPoll(jobs int, claim, submit time.Duration) {
+	d.statsMu.Lock()
+	defer d.statsMu.Unlock()
+
+	total := durationMs(claim + submit)
+	st := &d.stats
+	st.Polls++
+	st.JobsClaimed += int64(jobs)
+	st.LastPollMs = total
+	st.MaxPollMs = max(st.MaxPollMs, total)
+	if st.Polls == 1 {
+		st.AvgPollMs, st.AvgClaimMs, st.AvgSubmitMs = total, durationMs(claim), durationMs(submit)
+		return
+	}
+	st.AvgPollMs += latencyEWMAWeight * (total - st.AvgPollMs)
+	st.AvgClaimMs += latencyEWMAWeight * (durationMs(claim) - st.AvgClaimMs)
+	st.AvgSubmitMs += latencyEWMAWeight * (durationMs(submit) - st.AvgSubmitMs)
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// Stats returns a snapshot of the dispatch loop's activity and timing.
+func (d *Dispatcher) Stats() DispatcherStats {
+	d.statsMu.Lock()
+	st := d.stats
+	d.statsMu.Unlock()
+
+	st.Running = d.Running()
+	st.LagMs = d.LagMs()
+	return st
+}
+
+// Running reports whether the dispatch loop is active.
+func (d *Dispatcher) Running() bool {
+	return d.running.Load()
+}
+
+// LagMs returns how far behind schedule the oldest job in the most recent
+// batch was when it was claimed. 0 means the dispatcher is keeping up.
+func (d *Dispatcher) LagMs() int64 {
+	return d.lagMs.Load()
+}
+
+// wait blocks until new work is signalled, the next scheduled job is due,
+// or maxWait elapses — whichever comes first.
+func (d *Dispatcher) wait(ctx context.Context) {
+	timeout := d.maxWait
+
+	next, ok, err := d.queue.NextJobAt(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			d.logger.Error("failed to peek delivery queue", "error", err)
+			sleepCtx(ctx, d.maxWait)
 		}
+		return
+	}
+	if ok {
+		untilNext := next.Sub(d.clock.Now())
+		if untilNext <= 0 {
+			return
+		}
+		if untilNext < timeout {
+			timeout = untilNext
+		}
+	}
 
-		d.pool.Submit(job)
+	if err := d.queue.Wait(ctx, timeout); err != nil && ctx.Err() == nil {
+		d.logger.Error("failed to wait on delivery queue", "error", err)
+		sleepCtx(ctx, d.maxWait)
 	}
 }
 
-func formatFloat(f float64) string {
-	return strconv.FormatFloat(f, 'f', -1, 64)
+// sleepCtx sleeps for dur or until the context is cancelled.
+func sleepCtx(ctx context.Context, dur time.Duration) {
+	t := time.NewTimer(dur)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+	case <-t.C:
+	}
 }
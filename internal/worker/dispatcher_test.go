@@ -0,0 +1,246 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/chaos"
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func setupDispatcherTest(t testing.TB) (*redis.Client, *Pool, *Dispatcher) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	// Pool is not started — the test reads submitted jobs straight off its channel
+	cluster := engine.NewCluster(client, "test", 15*time.Second, logger)
+	queue := engine.NewRedisQueue(client, cluster)
+	pool := NewPool(1, nil, queue, logger)
+	d := NewDispatcher(queue, pool, logger)
+	return client, pool, d
+}
+
+func TestDispatcher_PicksUpNewJobPromptly(t *testing.T) {
+	client, pool, d := setupDispatcherTest(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Start(ctx)
+
+	// Let the dispatcher go idle and block on the notify list
+	time.Sleep(50 * time.Millisecond)
+
+	start := time.Now()
+	if err := engine.EnqueueJob(ctx, client, engine.DeliveryJob{EventID: "evt-now"}, time.Now()); err != nil {
+		t.Fatalf("EnqueueJob failed: %v", err)
+	}
+
+	select {
+	case job := <-pool.jobs:
+		if job.EventID != "evt-now" {
+			t.Errorf("got job %q, want evt-now", job.EventID)
+		}
+		if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+			t.Errorf("job took %v to dispatch, expected well under maxWait", elapsed)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("job was not dispatched")
+	}
+}
+
+func TestDispatcher_WaitsForScheduledJob(t *testing.T) {
+	client, pool, d := setupDispatcherTest(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runAt := time.Now().Add(300 * time.Millisecond)
+	engine.EnqueueJob(ctx, client, engine.DeliveryJob{EventID: "evt-later"}, runAt)
+
+	go d.Start(ctx)
+
+	select {
+	case <-pool.jobs:
+		if time.Now().Before(runAt) {
+			t.Error("job dispatched before its scheduled time")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("scheduled job was not dispatched")
+	}
+}
+
+func TestDispatcher_ClaimsScheduledJobOnceDue(t *testing.T) {
+	client, pool, d := setupDispatcherTest(t)
+	clock := engine.NewManualClock(time.Now())
+	d.SetClock(clock)
+	ctx := context.Background()
+
+	engine.EnqueueJob(ctx, client, engine.DeliveryJob{EventID: "evt-later"}, clock.Now().Add(time.Minute))
+	if n, _ := d.poll(ctx, 10); n != 0 {
+		t.Fatalf("claimed %d jobs a minute before they were due", n)
+	}
+
+	clock.Advance(time.Minute)
+	if n, _ := d.poll(ctx, 10); n != 1 {
+		t.Fatalf("claimed %d jobs once due, want 1", n)
+	}
+	if job := <-pool.jobs; job.EventID != "evt-later" {
+		t.Errorf("got job %q, want evt-later", job.EventID)
+	}
+}
+
+// failingClaimQueue fails every claim while its oldest job is already due.
+type failingClaimQueue struct {
+	engine.Queue
+	claims atomic.Int64
+}
+
+func (q *failingClaimQueue) Claim(context.Context, time.Time, int64) ([]engine.ClaimedJob, error) {
+	q.claims.Add(1)
+	return nil, errors.New("OOM command not allowed when used memory > 'maxmemory'")
+}
+
+func (q *failingClaimQueue) NextJobAt(context.Context) (time.Time, bool, error) {
+	return time.Now().Add(-time.Minute), true, nil
+}
+
+func (q *failingClaimQueue) Wait(context.Context, time.Duration) error {
+	return nil
+}
+
+func TestDispatcher_BacksOffWhenClaimFails(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	queue := &failingClaimQueue{}
+	d := NewDispatcher(queue, NewPool(1, nil, queue, logger), logger)
+	d.maxWait = 50 * time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	d.Start(ctx)
+
+	if n := queue.claims.Load(); n > 6 {
+		t.Errorf("claimed %d times in 200ms, want a back-off of maxWait between failed claims", n)
+	}
+}
+
+func TestDispatcher_LeavesJobsQueuedWhenPoolFull(t *testing.T) {
+	client, pool, d := setupDispatcherTest(t)
+	clock := engine.NewManualClock(time.Now())
+	d.SetClock(clock)
+	ctx := context.Background()
+
+	// 5 jobs, but the unstarted pool only buffers 2
+	past := clock.Now().Add(-2 * time.Second)
+	for i := 0; i < 5; i++ {
+		engine.EnqueueJob(ctx, client, engine.DeliveryJob{EventID: "evt", Attempt: i}, past)
+	}
+
+	if n, _ := d.poll(ctx, 5); n != 5 {
+		t.Fatalf("claimed %d jobs, want 5", n)
+	}
+	if lag := d.LagMs(); lag != 2000 {
+		t.Errorf("LagMs = %d, want 2000 for jobs 2s overdue", lag)
+	}
+
+	// Jobs that found the pool full waited submitTimeout for it
+	if st := d.Stats(); st.Running || st.Polls == 0 || st.JobsClaimed < 3 || st.MaxPollMs < 50 || st.AvgSubmitMs == 0 {
+		t.Errorf("Stats = %+v", st)
+	}
+
+	if got := len(pool.jobs); got != 2 {
+		t.Errorf("pool buffered %d jobs, want 2", got)
+	}
+
+	remaining, _ := client.ZCard(context.Background(), engine.DeliveryQueueKey).Result()
+	if remaining+int64(len(pool.jobs)) != 5 {
+		t.Errorf("lost jobs: %d in Redis + %d in pool, want 5 total", remaining, len(pool.jobs))
+	}
+
+	// Only jobs actually handed to the pool remain claimed
+	claimed, _ := client.ZCard(context.Background(), engine.ClaimsKey("test")).Result()
+	if claimed != int64(len(pool.jobs)) {
+		t.Errorf("%d jobs claimed, want %d", claimed, len(pool.jobs))
+	}
+}
+
+func TestDispatcher_ChaosDelay(t *testing.T) {
+	client, pool, d := setupDispatcherTest(t)
+	injector := chaos.New()
+	injector.Set(domain.ChaosSettings{DispatchDelayMs: 200})
+	d.SetChaos(injector)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	start := time.Now()
+	if err := engine.EnqueueJob(ctx, client, engine.DeliveryJob{EventID: "evt-delayed"}, start); err != nil {
+		t.Fatalf("EnqueueJob failed: %v", err)
+	}
+	go d.Start(ctx)
+
+	select {
+	case <-pool.jobs:
+		if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+			t.Errorf("job dispatched after %v, want the 200ms injected delay", elapsed)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("job was not dispatched")
+	}
+}
+
+func BenchmarkDispatcher_Poll(b *testing.B) {
+	const batch = 100
+	client, _, d := setupDispatcherTest(b)
+	pool := NewPool(batch, nil, d.queue, d.logger)
+	d.pool = pool
+	ctx := context.Background()
+
+	jobs := make([]engine.DeliveryJob, batch)
+	for i := range jobs {
+		jobs[i] = engine.DeliveryJob{
+			EventID:      "evt-bench",
+			SubscriberID: fmt.Sprintf("sub-%d", i),
+			EndpointURL:  "http://example.com/hook",
+			EventType:    "order.created",
+			Attempt:      1,
+			MaxRetries:   5,
+		}
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		if err := d.queue.Publish(ctx, "evt-bench", []byte(`{"order_id":42}`), jobs); err != nil {
+			b.Fatal(err)
+		}
+		b.StartTimer()
+
+		if n, _ := d.poll(ctx, batch); n != batch {
+			b.Fatalf("poll claimed %d jobs, want %d", n, batch)
+		}
+
+		b.StopTimer()
+		for len(pool.jobs) > 0 {
+			<-pool.jobs
+		}
+		client.FlushAll(ctx)
+		b.StartTimer()
+	}
+	b.ReportMetric(float64(batch*b.N)/b.Elapsed().Seconds(), "jobs/s")
+}
@@ -0,0 +1,51 @@
+package worker
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+)
+
+// classifyError works out why a request that got no response failed, from
+// the error returned by the HTTP client.
+func classifyError(err error) domain.FailureReason {
+	var blocked *blockedAddressError
+	var dnsErr *net.DNSError
+	var opErr *net.OpError
+	var certErr *tls.CertificateVerificationError
+	var recordErr tls.RecordHeaderError
+	var alertErr tls.AlertError
+	var authorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+
+	switch {
+	case errors.As(err, &blocked):
+		return domain.FailureBlockedAddress
+	case errors.As(err, &dnsErr):
+		return domain.FailureDNS
+	case errors.As(err, &opErr) && opErr.Op == "dial":
+		if opErr.Timeout() {
+			return domain.FailureConnectTimeout
+		}
+		return domain.FailureConnection
+	case errors.As(err, &certErr), errors.As(err, &recordErr), errors.As(err, &alertErr),
+		errors.As(err, &authorityErr), errors.As(err, &hostnameErr), errors.As(err, &invalidErr),
+		strings.Contains(err.Error(), "tls: "):
+		return domain.FailureTLS
+	case errors.Is(err, context.DeadlineExceeded), isTimeout(err):
+		return domain.FailureReadTimeout
+	default:
+		return domain.FailureConnection
+	}
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
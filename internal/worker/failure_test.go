@@ -0,0 +1,78 @@
+package worker
+
+import (
+	"context"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+)
+
+func TestClassifyError(t *testing.T) {
+	tlsServer := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	tlsServer.Config.ErrorLog = log.New(io.Discard, "", 0)
+	tlsServer.StartTLS()
+	defer tlsServer.Close()
+	slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer slowServer.Close()
+
+	// A port nothing listens on
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedURL := "http://" + lis.Addr().String()
+	lis.Close()
+
+	blocking := &http.Client{Transport: newHTTPTransport(TransportConfig{BlockPrivateIPs: true})}
+	client := &http.Client{Timeout: 5 * time.Second}
+	impatient := &http.Client{Timeout: 50 * time.Millisecond}
+
+	cases := []struct {
+		name   string
+		client *http.Client
+		url    string
+		want   domain.FailureReason
+	}{
+		{"unknown host", client, "http://does-not-exist.invalid/hook", domain.FailureDNS},
+		{"refused", client, closedURL, domain.FailureConnection},
+		{"untrusted certificate", client, tlsServer.URL, domain.FailureTLS},
+		{"slow response", impatient, slowServer.URL, domain.FailureReadTimeout},
+		{"private address", blocking, slowServer.URL, domain.FailureBlockedAddress},
+	}
+	for _, tc := range cases {
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, tc.url, nil)
+		resp, err := tc.client.Do(req)
+		if err == nil {
+			resp.Body.Close()
+			t.Errorf("%s: expected the request to fail", tc.name)
+			continue
+		}
+		if got := classifyError(err); got != tc.want {
+			t.Errorf("%s: classifyError(%v) = %s, want %s", tc.name, err, got, tc.want)
+		}
+	}
+}
+
+func TestStatusFailureReason(t *testing.T) {
+	for code, want := range map[int]domain.FailureReason{
+		400: domain.FailureHTTP4xx,
+		404: domain.FailureHTTP4xx,
+		413: domain.FailurePayloadTooLarge,
+		429: domain.FailureRateLimited,
+		500: domain.FailureHTTP5xx,
+		503: domain.FailureHTTP5xx,
+		304: domain.FailureHTTPOther,
+	} {
+		if got := domain.StatusFailureReason(code); got != want {
+			t.Errorf("StatusFailureReason(%d) = %s, want %s", code, got, want)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+)
+
+// LogSampling sets the fraction of delivery outcomes that are logged, from 0
+// (none) to 1 (all). Dead-lettered deliveries, and deliveries to subscribers
+// with debug logging, are always logged.
+type LogSampling struct {
+	Success float64 // successful deliveries
+	Failure float64 // failed attempts that will be retried
+}
+
+// SetLogSampling changes the delivery log sampling rates. It is safe to call
+// while deliveries are running.
+func (d *Deliverer) SetLogSampling(s LogSampling) {
+	d.logSampling.Store(&s)
+}
+
+// shouldLog reports whether an outcome of job is logged. Without sampling
+// rates every outcome is.
+func (d *Deliverer) shouldLog(job engine.DeliveryJob, success bool) bool {
+	s := d.logSampling.Load()
+	if job.DebugLogging || s == nil {
+		return true
+	}
+	rate := s.Failure
+	if success {
+		rate = s.Success
+	}
+	return rate >= 1 || d.rng.Float64() < rate
+}
+
+// jobLogger returns a logger whose lines carry job's delivery, event and
+// subscriber IDs.
+func (d *Deliverer) jobLogger(job engine.DeliveryJob) *slog.Logger {
+	return d.logger.With(
+		"delivery_id", job.DeliveryID,
+		"event_id", job.EventID,
+		"subscriber_id", job.SubscriberID,
+	)
+}
+
+// logDebug logs a detail of job's delivery at debug level, or at info level
+// when its subscriber has debug logging on, so the line gets through a
+// handler that drops debug logs.
+func (d *Deliverer) logDebug(ctx context.Context, job engine.DeliveryJob, msg string, args ...any) {
+	level := slog.LevelDebug
+	if job.DebugLogging {
+		level = slog.LevelInfo
+		args = append(args, "debug", true)
+	}
+	d.jobLogger(job).Log(ctx, level, msg, args...)
+}
+
+// responseDetails returns the response attributes added to the outcome logs
+// of subscribers with debug logging. Both are stored redacted.
+func responseDetails(job engine.DeliveryJob, responseBody string, responseHeaders map[string]string) []any {
+	if !job.DebugLogging {
+		return nil
+	}
+	return []any{"response_headers", responseHeaders, "response_body", responseBody, "debug", true}
+}
@@ -0,0 +1,90 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+)
+
+// logLines decodes the JSON log lines written to buf with the given message.
+func logLines(t *testing.T, buf *bytes.Buffer, msg string) []map[string]any {
+	t.Helper()
+	var lines []map[string]any
+	for _, raw := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var line map[string]any
+		if err := json.Unmarshal([]byte(raw), &line); err == nil && line["msg"] == msg {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func TestDeliverer_SamplesOutcomeLogs(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusOK)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(int(status.Load()))
+		w.Write([]byte("accepted"))
+	}))
+	defer server.Close()
+
+	_, cb, rl, hub, _ := setupDeliveryTest(t)
+	var buf bytes.Buffer
+	d := &Deliverer{
+		httpClient:     &http.Client{Timeout: 5 * time.Second},
+		bodyLimit:      64,
+		queue:          engine.NewMemoryQueue(),
+		circuitBreaker: cb,
+		rateLimiter:    rl,
+		hub:            hub,
+		logger:         slog.New(slog.NewJSONHandler(&buf, nil)),
+	}
+	d.SetLogSampling(LogSampling{Success: 0, Failure: 1})
+
+	job := engine.DeliveryJob{
+		EventID:      "evt-1",
+		SubscriberID: "sub-1",
+		EndpointURL:  server.URL,
+		Payload:      json.RawMessage(`{}`),
+		Attempt:      1,
+		MaxRetries:   3,
+	}
+	ctx := context.Background()
+
+	d.Deliver(ctx, job)
+	if lines := logLines(t, &buf, "delivery successful"); len(lines) != 0 {
+		t.Errorf("expected successes to be sampled out, got %v", lines)
+	}
+
+	status.Store(http.StatusBadGateway)
+	d.Deliver(ctx, job)
+	failed := logLines(t, &buf, "delivery failed, scheduling retry")
+	if len(failed) != 1 || failed[0]["delivery_id"] == "" || failed[0]["failure_reason"] != "http_5xx" {
+		t.Fatalf("failure logs = %v", failed)
+	}
+	if _, ok := failed[0]["response_body"]; ok {
+		t.Error("response body logged without debug logging")
+	}
+
+	// Debug logging bypasses sampling and adds request and response details
+	status.Store(http.StatusOK)
+	job.DebugLogging = true
+	d.Deliver(ctx, job)
+	sent := logLines(t, &buf, "sending delivery")
+	delivered := logLines(t, &buf, "delivery successful")
+	if len(sent) != 1 || sent[0]["endpoint_url"] != server.URL || sent[0]["level"] != "INFO" {
+		t.Errorf("request logs = %v", sent)
+	}
+	if len(delivered) != 1 || delivered[0]["response_body"] != "accepted" || delivered[0]["delivery_id"] != sent[0]["delivery_id"] {
+		t.Errorf("debug success logs = %v", delivered)
+	}
+}
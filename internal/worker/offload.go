@@ -0,0 +1,98 @@
+package worker
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+)
+
+// streamsPayload reports whether job's payload is streamed from object
+// storage rather than loaded into memory. Payloads kept there are, unless
+// they have to be converted to the subscriber's version first.
+func (d *Deliverer) streamsPayload(job engine.DeliveryJob) bool {
+	return job.PayloadRef != nil && !needsConversion(job)
+}
+
+// loadPayload returns job's payload in memory, fetching one kept in object
+// storage whole.
+func (d *Deliverer) loadPayload(ctx context.Context, job engine.DeliveryJob) (json.RawMessage, error) {
+	switch {
+	case job.PayloadRef != nil:
+		payload, err := d.openPayload(ctx, job.PayloadRef)()
+		if err != nil {
+			return nil, err
+		}
+		defer payload.Close()
+		return io.ReadAll(payload)
+	case len(job.Payload) > 0:
+		return job.Payload, nil
+	default:
+		return d.payloads.Resolve(ctx, job.EventID)
+	}
+}
+
+// openPayload returns a function opening the payload at ref, once for the
+// signature and once for each request sent.
+func (d *Deliverer) openPayload(ctx context.Context, ref *domain.PayloadRef) func() (io.ReadCloser, error) {
+	return func() (io.ReadCloser, error) {
+		if d.objects == nil {
+			return nil, fmt.Errorf("payload %s is in object storage, which is not configured", ref.Key)
+		}
+		return d.objects.GetObject(ctx, ref.Key)
+	}
+}
+
+// compressesStream reports whether job's streamed payload is gzipped, by
+// the rule compress applies to payloads in memory.
+func (d *Deliverer) compressesStream(job engine.DeliveryJob) bool {
+	return job.CompressPayload && !pushesToQueue(job) && d.gzipThreshold > 0 && job.PayloadRef.Size >= int64(d.gzipThreshold)
+}
+
+// streamBody makes req send job's payload straight from object storage,
+// reopening it for each redirect followed. A compressed payload is gzipped
+// on the fly and sent chunked, its length being unknown up front.
+func (d *Deliverer) streamBody(ctx context.Context, req *http.Request, job engine.DeliveryJob, compressed bool) error {
+	open := d.openPayload(ctx, job.PayloadRef)
+	size := job.PayloadRef.Size
+	if compressed {
+		open, size = gzipStream(open), -1
+	}
+	body, err := open()
+	if err != nil {
+		return err
+	}
+	req.Body = body
+	req.GetBody = open
+	req.ContentLength = size
+	return nil
+}
+
+// gzipStream returns a function opening what open does, gzipped as it is
+// read.
+func gzipStream(open func() (io.ReadCloser, error)) func() (io.ReadCloser, error) {
+	return func() (io.ReadCloser, error) {
+		src, err := open()
+		if err != nil {
+			return nil, err
+		}
+		r, w := io.Pipe()
+		go func() {
+			defer src.Close()
+			gz := gzip.NewWriter(w)
+			_, err := io.Copy(gz, src)
+			if err == nil {
+				err = gz.Close()
+			}
+			// Ends the request body, with err if reading or compressing
+			// failed; also ends the copy if the transport closes r early
+			w.CloseWithError(err)
+		}()
+		return r, nil
+	}
+}
@@ -0,0 +1,59 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+	ws "github.com/Priya8975/webhook-delivery-system/internal/websocket"
+)
+
+// MaxJobPanics is how many times handling a job may panic before the job is
+// treated as poison and dead-lettered instead of queued again.
+const MaxJobPanics = 3
+
+// handlePanic deals with a job whose handling panicked with p. The job is
+// queued again after the usual retry delay, without using up one of its
+// attempts, until it has panicked MaxJobPanics times. Then it is
+// dead-lettered with the panic message so it can't keep crashing workers.
+func (d *Deliverer) handlePanic(ctx context.Context, job engine.DeliveryJob, p any, stack []byte) {
+	job.Panics++
+	errMsg := fmt.Sprintf("panic: %v", p)
+
+	if job.Panics < MaxJobPanics {
+		nextRetry := d.now().Add(retryDelay(d.retry, job, d.rng))
+		if err := d.queue.Enqueue(ctx, job, nextRetry); err != nil {
+			d.jobLogger(job).Error("failed to requeue job after panic", "error", err)
+		}
+		d.jobLogger(job).Error("delivery panicked, requeued job",
+			"panic", errMsg,
+			"panics", job.Panics,
+			"next_retry_at", nextRetry.Format(time.RFC3339),
+			"stack", string(stack),
+		)
+		return
+	}
+
+	d.recordAttempt(ctx, job, time.Now(), nil, "", nil, domain.FailurePanic, errMsg, nil)
+	d.moveToDLQ(ctx, job, nil, domain.FailurePanic, errMsg)
+
+	d.hub.Broadcast(ws.DeliveryEvent{
+		Type:         "delivery_dlq",
+		DeliveryID:   job.DeliveryID,
+		EventID:      job.EventID,
+		SubscriberID: job.SubscriberID,
+		EndpointURL:  job.EndpointURL,
+		EventType:    job.EventType,
+		Attempt:      job.Attempt,
+		Error:        errMsg,
+		Timestamp:    time.Now(),
+	})
+
+	d.jobLogger(job).Error("delivery kept panicking, moved poison job to dead letter queue",
+		"panic", errMsg,
+		"panics", job.Panics,
+		"stack", string(stack),
+	)
+}
@@ -0,0 +1,121 @@
+package worker
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+)
+
+// payloadResolver loads event payloads referenced by delivery jobs.
+// Lookups go through an in-process LRU cache, then the copy the queue stored
+// at fan-out time, and finally the event store.
+type payloadResolver struct {
+	queue engine.Queue
+	store store.EventStore
+	cache *lruCache
+}
+
+func newPayloadResolver(queue engine.Queue, s store.EventStore, cacheSize int) *payloadResolver {
+	return &payloadResolver{
+		queue: queue,
+		store: s,
+		cache: newLRUCache(cacheSize),
+	}
+}
+
+// Resolve returns the payload for the job's event.
+func (p *payloadResolver) Resolve(ctx context.Context, eventID string) (json.RawMessage, error) {
+	if payload, ok := p.cache.Get(eventID); ok {
+		return payload, nil
+	}
+
+	data, err := p.queue.Payload(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if data != nil {
+		p.cache.Add(eventID, data)
+		return data, nil
+	}
+
+	// Queued copy expired — fall back to the source of truth
+	if p.store == nil {
+		return nil, fmt.Errorf("payload for event %s not found", eventID)
+	}
+	event, err := p.store.GetEvent(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if event == nil {
+		return nil, fmt.Errorf("event %s not found", eventID)
+	}
+
+	p.cache.Add(eventID, event.Payload)
+	return event.Payload, nil
+}
+
+// lruCache is a fixed-size, concurrency-safe LRU cache of payloads keyed by event ID.
+type lruCache struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[string]*list.Element
+}
+
+type lruEntry struct {
+	key   string
+	value json.RawMessage
+}
+
+func newLRUCache(capacity int) *lruCache {
+	return &lruCache{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+func (c *lruCache) Get(key string) (json.RawMessage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.ll.MoveToFront(el)
+		return el.Value.(*lruEntry).value, true
+	}
+	return nil, false
+}
+
+func (c *lruCache) Add(key string, value json.RawMessage) {
+	if c.capacity <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.ll.MoveToFront(el)
+		el.Value.(*lruEntry).value = value
+		return
+	}
+
+	c.items[key] = c.ll.PushFront(&lruEntry{key: key, value: value})
+
+	if c.ll.Len() > c.capacity {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry).key)
+	}
+}
+
+func (c *lruCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
@@ -0,0 +1,118 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestLRUCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := newLRUCache(2)
+
+	c.Add("a", json.RawMessage(`1`))
+	c.Add("b", json.RawMessage(`2`))
+	c.Get("a") // a is now most recent
+	c.Add("c", json.RawMessage(`3`))
+
+	if _, ok := c.Get("b"); ok {
+		t.Error("b should have been evicted")
+	}
+	if v, ok := c.Get("a"); !ok || string(v) != "1" {
+		t.Error("a should still be cached")
+	}
+	if c.Len() != 2 {
+		t.Errorf("expected 2 entries, got %d", c.Len())
+	}
+}
+
+func TestLRUCache_ZeroCapacityDisabled(t *testing.T) {
+	c := newLRUCache(0)
+	c.Add("a", json.RawMessage(`1`))
+
+	if _, ok := c.Get("a"); ok {
+		t.Error("zero-capacity cache should not store entries")
+	}
+}
+
+func TestPayloadResolver_FromRedisThenCache(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	ctx := context.Background()
+	client.Set(ctx, engine.PayloadKey("evt-1"), `{"ref":true}`, time.Hour)
+
+	p := newPayloadResolver(engine.NewRedisQueue(client, nil), nil, 10)
+
+	payload, err := p.Resolve(ctx, "evt-1")
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if string(payload) != `{"ref":true}` {
+		t.Errorf("payload = %s", payload)
+	}
+
+	// Second lookup must be served from the LRU even if Redis loses the key
+	mr.Del(engine.PayloadKey("evt-1"))
+	if _, err := p.Resolve(ctx, "evt-1"); err != nil {
+		t.Errorf("cached payload should resolve without Redis: %v", err)
+	}
+}
+
+func TestPayloadResolver_Missing(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	p := newPayloadResolver(engine.NewRedisQueue(client, nil), nil, 10)
+
+	if _, err := p.Resolve(context.Background(), "evt-missing"); err == nil {
+		t.Error("expected error for missing payload")
+	}
+}
+
+func TestDelivery_ResolvesPayloadByReference(t *testing.T) {
+	var receivedBody []byte
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf := make([]byte, 1024)
+		n, _ := r.Body.Read(buf)
+		receivedBody = buf[:n]
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, cb, rl, hub, logger := setupDeliveryTest(t)
+	client.Set(context.Background(), engine.PayloadKey("evt-ref"), `{"by":"reference"}`, time.Hour)
+
+	deliverer := &Deliverer{
+		httpClient:     &http.Client{Timeout: 5 * time.Second},
+		payloads:       newPayloadResolver(engine.NewRedisQueue(client, nil), nil, 10),
+		queue:          engine.NewRedisQueue(client, nil),
+		circuitBreaker: cb,
+		rateLimiter:    rl,
+		hub:            hub,
+		logger:         logger,
+	}
+
+	deliverer.Deliver(context.Background(), engine.DeliveryJob{
+		EventID:      "evt-ref",
+		SubscriberID: "sub-ref",
+		EndpointURL:  server.URL,
+		SecretKey:    "secret",
+		EventType:    "test.event",
+		Attempt:      1,
+		MaxRetries:   5,
+	})
+
+	if string(receivedBody) != `{"by":"reference"}` {
+		t.Errorf("endpoint received %q, want referenced payload", receivedBody)
+	}
+}
@@ -2,46 +2,240 @@ package worker
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"runtime/debug"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/Priya8975/webhook-delivery-system/internal/engine"
 )
 
-// Pool manages a fixed number of worker goroutines that process delivery jobs.
+// latencyEWMAWeight is how much each finished delivery moves the average
+// delivery latency used for autoscaling decisions.
+const latencyEWMAWeight = 0.1
+
+// Pool manages worker goroutines that process delivery jobs. It starts with
+// numWorkers and can be grown or shrunk at runtime with Resize.
 type Pool struct {
 	numWorkers int
 	jobs       chan engine.DeliveryJob
 	deliverer  *Deliverer
+	batcher    *Batcher
+	queue      engine.Queue
 	logger     *slog.Logger
 	wg         sync.WaitGroup
+
+	busy      atomic.Int64  // workers currently delivering
+	panics    atomic.Int64  // jobs whose handling panicked
+	slotFreed chan struct{} // signalled whenever a worker finishes a job
+
+	mu         sync.Mutex
+	ctx        context.Context
+	size       int           // target number of workers
+	nextID     int           // ID for the next spawned worker, for logs
+	shrink     chan struct{} // each token retires one worker
+	scaleUps   int64
+	scaleDowns int64
+	avgLatency time.Duration // EWMA of Deliver duration
+}
+
+// PoolStats is a point-in-time snapshot of the pool for metrics.
+type PoolStats struct {
+	Workers        int   `json:"workers"`
+	BusyWorkers    int64 `json:"busy_workers"`
+	BufferedJobs   int   `json:"buffered_jobs"`
+	BufferCapacity int   `json:"buffer_capacity"`
+	BatchedJobs    int   `json:"batched_jobs"` // waiting in the batcher for their batch to fill
+	AvgLatencyMs   int64 `json:"avg_latency_ms"`
+	ScaleUps       int64 `json:"scale_ups"`
+	ScaleDowns     int64 `json:"scale_downs"`
+	Panics         int64 `json:"panics"` // jobs whose handling panicked
 }
 
-// NewPool creates a worker pool with the given number of workers.
-func NewPool(numWorkers int, deliverer *Deliverer, logger *slog.Logger) *Pool {
+// NewPool creates a worker pool with the given number of workers. Finished
+// jobs are acknowledged on queue so they are not reassigned. A nil queue
+// (in tests) skips acknowledgement.
+func NewPool(numWorkers int, deliverer *Deliverer, queue engine.Queue, logger *slog.Logger) *Pool {
 	return &Pool{
 		numWorkers: numWorkers,
 		jobs:       make(chan engine.DeliveryJob, numWorkers*2),
 		deliverer:  deliverer,
+		queue:      queue,
 		logger:     logger,
+		slotFreed:  make(chan struct{}, 1),
+		size:       numWorkers,
+		shrink:     make(chan struct{}, 1024),
 	}
 }
 
-// Start launches all worker goroutines. They read from the jobs channel
-// until it is closed or the context is cancelled.
+// SetBatcher routes the jobs of subscribers that take batched deliveries
+// through b. Call it before Start.
+func (p *Pool) SetBatcher(b *Batcher) {
+	p.batcher = b
+}
+
+// Start launches the initial worker goroutines. They read from the jobs
+// channel until it is closed or the context is cancelled.
 func (p *Pool) Start(ctx context.Context) {
-	for i := 0; i < p.numWorkers; i++ {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.ctx = ctx
+	p.spawn(p.numWorkers)
+	p.logger.Info("worker pool started", "num_workers", p.numWorkers)
+}
+
+// Resize grows or shrinks the pool to n workers. New workers start
+// immediately; surplus workers exit once they finish their current job.
+func (p *Pool) Resize(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.ctx == nil || n < 1 || n == p.size {
+		return
+	}
+
+	if n > p.size {
+		need := n - p.size
+		// Cancel pending retirements before spawning fresh workers
+	cancelRetirements:
+		for need > 0 {
+			select {
+			case <-p.shrink:
+				need--
+			default:
+				break cancelRetirements
+			}
+		}
+		p.spawn(need)
+		p.scaleUps++
+	} else {
+		for i := 0; i < p.size-n; i++ {
+			select {
+			case p.shrink <- struct{}{}:
+			default:
+			}
+		}
+		p.scaleDowns++
+	}
+	p.size = n
+}
+
+// spawn starts n workers. Callers must hold p.mu.
+func (p *Pool) spawn(n int) {
+	for i := 0; i < n; i++ {
 		p.wg.Add(1)
-		go p.worker(ctx, i)
+		go p.worker(p.ctx, p.nextID)
+		p.nextID++
 	}
-	p.logger.Info("worker pool started", "num_workers", p.numWorkers)
+}
+
+// Size returns the current target number of workers.
+func (p *Pool) Size() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.size
+}
+
+// Busy returns the number of workers currently delivering a job.
+func (p *Pool) Busy() int {
+	return int(p.busy.Load())
+}
+
+// AvgLatency returns the moving average time a worker spends on one job,
+// or 0 before any job has finished.
+func (p *Pool) AvgLatency() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.avgLatency
+}
+
+// Stats returns a snapshot of pool size, load, and scaling activity.
+func (p *Pool) Stats() PoolStats {
+	var batched int
+	if p.batcher != nil {
+		batched = p.batcher.Buffered()
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return PoolStats{
+		Workers:        p.size,
+		BusyWorkers:    p.busy.Load(),
+		BufferedJobs:   len(p.jobs),
+		BufferCapacity: cap(p.jobs),
+		BatchedJobs:    batched,
+		AvgLatencyMs:   p.avgLatency.Milliseconds(),
+		ScaleUps:       p.scaleUps,
+		ScaleDowns:     p.scaleDowns,
+		Panics:         p.panics.Load(),
+	}
+}
+
+func (p *Pool) recordLatency(d time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.avgLatency == 0 {
+		p.avgLatency = d
+		return
+	}
+	p.avgLatency += time.Duration(latencyEWMAWeight * float64(d-p.avgLatency))
 }
 
 // Submit sends a job to the worker pool via the jobs channel.
+// It blocks until a slot is available.
 func (p *Pool) Submit(job engine.DeliveryJob) {
 	p.jobs <- job
 }
 
+// TrySubmit sends a job to the pool, waiting at most timeout for buffer space.
+// Returns false if the pool is still full, in which case the caller keeps
+// ownership of the job.
+func (p *Pool) TrySubmit(job engine.DeliveryJob, timeout time.Duration) bool {
+	select {
+	case p.jobs <- job:
+		return true
+	default:
+	}
+
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+
+	select {
+	case p.jobs <- job:
+		return true
+	case <-t.C:
+		return false
+	}
+}
+
+// FreeSlots returns how many more jobs the pool can accept without blocking:
+// idle workers plus free buffer space, minus jobs already waiting.
+func (p *Pool) FreeSlots() int {
+	free := p.Size() + cap(p.jobs) - int(p.busy.Load()) - len(p.jobs)
+	if free < 0 {
+		return 0
+	}
+	return free
+}
+
+// WaitForSlot blocks until a worker finishes a job, the timeout elapses,
+// or the context is cancelled.
+func (p *Pool) WaitForSlot(ctx context.Context, timeout time.Duration) {
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+
+	select {
+	case <-p.slotFreed:
+	case <-t.C:
+	case <-ctx.Done():
+	}
+}
+
 // Jobs returns the jobs channel for the dispatcher to send work into.
 func (p *Pool) Jobs() chan<- engine.DeliveryJob {
 	return p.jobs
@@ -54,16 +248,116 @@ func (p *Pool) Stop() {
 	p.logger.Info("worker pool stopped")
 }
 
-// worker is a single goroutine that processes jobs from the channel.
+// Drain waits for in-flight deliveries to finish and returns every job still
+// buffered in the channel or waiting for its batch to the delivery queue, so
+// nothing claimed by this instance is dropped on shutdown. Call it after
+// cancelling the context passed to Start and after the dispatcher has
+// stopped, and before Stop. Returns the number of jobs handed back.
+func (p *Pool) Drain() int {
+	p.wg.Wait()
+
+	returned := 0
+	if p.batcher != nil {
+		returned += p.batcher.Stop()
+	}
+	for {
+		select {
+		case job := <-p.jobs:
+			p.returnJob(job)
+			returned++
+		default:
+			p.logger.Info("worker pool drained", "jobs_returned", returned)
+			return returned
+		}
+	}
+}
+
+// returnJob puts an undelivered job back on the delivery queue at its
+// original ready time.
+func (p *Pool) returnJob(job engine.DeliveryJob) {
+	if p.queue == nil || job.Claim == "" {
+		return
+	}
+	if err := p.queue.Return(context.Background(), job.Claim); err != nil {
+		p.logger.Error("failed to return job to queue", "error", err, "event_id", job.EventID)
+	}
+}
+
+// ackJob releases a delivered job's claim.
+func (p *Pool) ackJob(ctx context.Context, job engine.DeliveryJob) {
+	if p.queue == nil || job.Claim == "" {
+		return
+	}
+	if err := p.queue.Ack(ctx, job.Claim); err != nil {
+		p.logger.Error("failed to acknowledge job", "error", err, "event_id", job.EventID)
+	}
+}
+
+// process delivers one job, or hands it to the batcher. A panic while doing
+// so is recovered so the worker carries on with the next job, and the job is
+// requeued, or dead-lettered once it has panicked MaxJobPanics times.
+func (p *Pool) process(ctx context.Context, id int, job engine.DeliveryJob) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		p.panics.Add(1)
+		stack := debug.Stack()
+		defer func() {
+			if r := recover(); r != nil {
+				p.logger.Error("handling a panicked job panicked", "worker_id", id, "event_id", job.EventID, "panic", fmt.Sprint(r))
+			}
+		}()
+		p.deliverer.handlePanic(ctx, job, r, stack)
+		p.ackJob(ctx, job)
+	}()
+
+	// Payloads in object storage go alone, as a batch would hold them in
+	// memory
+	if p.batcher != nil && job.BatchMaxEvents > 1 && job.PayloadRef == nil {
+		// The batcher releases the claim once the batch is sent
+		p.batcher.Add(ctx, job)
+		return
+	}
+	p.deliverer.Deliver(ctx, job)
+	// Any retry has been queued by now, so the claim can be released
+	p.ackJob(ctx, job)
+}
+
+// worker is a single goroutine that processes jobs from the channel until the
+// context is cancelled. A delivery already in progress when that happens runs
+// to completion (including queueing any retry) on a context that is detached
+// from cancellation, so shutdown never records a spurious failure.
 func (p *Pool) worker(ctx context.Context, id int) {
 	defer p.wg.Done()
 
-	for job := range p.jobs {
+	for {
 		select {
 		case <-ctx.Done():
 			return
-		default:
-			p.deliverer.Deliver(ctx, job)
+		case <-p.shrink:
+			return
+		case job, ok := <-p.jobs:
+			if !ok {
+				return
+			}
+			if ctx.Err() != nil {
+				// Lost the race with shutdown; Drain handles the rest
+				p.returnJob(job)
+				return
+			}
+
+			p.busy.Add(1)
+			start := time.Now()
+			p.process(context.WithoutCancel(ctx), id, job)
+			p.recordLatency(time.Since(start))
+			p.busy.Add(-1)
+
+			select {
+			case p.slotFreed <- struct{}{}:
+			default:
+			}
 		}
 	}
 }
@@ -0,0 +1,169 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestPool_TrySubmitWhenFull(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	// Not started: capacity is just the channel buffer (2 per worker)
+	pool := NewPool(1, nil, nil, logger)
+
+	if got := pool.FreeSlots(); got != 3 {
+		t.Fatalf("FreeSlots = %d, want 3 (1 worker + 2 buffered)", got)
+	}
+
+	for i := 0; i < 2; i++ {
+		if !pool.TrySubmit(engine.DeliveryJob{EventID: "evt"}, 10*time.Millisecond) {
+			t.Fatalf("TrySubmit %d rejected with buffer space available", i)
+		}
+	}
+
+	start := time.Now()
+	if pool.TrySubmit(engine.DeliveryJob{EventID: "evt"}, 20*time.Millisecond) {
+		t.Fatal("TrySubmit accepted a job into a full buffer")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("TrySubmit blocked for %v, expected it to give up after the timeout", elapsed)
+	}
+
+	if got := pool.FreeSlots(); got != 1 {
+		t.Errorf("FreeSlots = %d, want 1", got)
+	}
+}
+
+func TestPool_DrainReturnsBufferedJobs(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	ctx := context.Background()
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	cluster := engine.NewCluster(client, "test", 15*time.Second, logger)
+	pool := NewPool(1, nil, engine.NewRedisQueue(client, cluster), logger)
+
+	readyAt := time.Now().Add(-time.Minute)
+	engine.EnqueueJob(ctx, client, engine.DeliveryJob{EventID: "a"}, readyAt)
+	engine.EnqueueJob(ctx, client, engine.DeliveryJob{EventID: "b"}, readyAt)
+
+	claimed, _ := engine.ClaimReadyJobs(ctx, client, cluster.ClaimsKey(), time.Now(), 10, 0)
+	for _, c := range claimed {
+		pool.Submit(engine.DeliveryJob{EventID: "x", Claim: c.Member})
+	}
+
+	if n := pool.Drain(); n != 2 {
+		t.Fatalf("Drain returned %d jobs, want 2", n)
+	}
+
+	queued, _ := client.ZRangeWithScores(ctx, engine.DeliveryQueueKey, 0, -1).Result()
+	if len(queued) != 2 {
+		t.Fatalf("expected 2 jobs back in the queue, got %d", len(queued))
+	}
+	if got := time.UnixMicro(int64(queued[0].Score)); !got.Equal(readyAt.Truncate(time.Microsecond)) {
+		t.Errorf("job requeued at %v, want original ready time %v", got, readyAt)
+	}
+	if n := client.ZCard(ctx, cluster.ClaimsKey()).Val(); n != 0 {
+		t.Errorf("%d jobs still claimed after drain", n)
+	}
+}
+
+// panickingQueue panics when asked whether a job's subscriber is paused,
+// which Deliver does first, for the jobs of one subscriber.
+type panickingQueue struct {
+	*engine.MemoryQueue
+	subscriberID string
+}
+
+func (q panickingQueue) ParkIfPaused(ctx context.Context, job engine.DeliveryJob) (bool, error) {
+	if job.SubscriberID == q.subscriberID {
+		panic("poison job")
+	}
+	return q.MemoryQueue.ParkIfPaused(ctx, job)
+}
+
+func TestPool_RecoversFromPanickingJobs(t *testing.T) {
+	var delivered atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delivered.Add(1)
+	}))
+	defer server.Close()
+
+	_, cb, rl, hub, logger := setupDeliveryTest(t)
+	s := store.NewMemoryStore()
+	queue := panickingQueue{MemoryQueue: engine.NewMemoryQueue(), subscriberID: "sub-poison"}
+	deliverer := &Deliverer{
+		httpClient:     &http.Client{Timeout: 5 * time.Second},
+		store:          s,
+		queue:          queue,
+		circuitBreaker: cb,
+		rateLimiter:    rl,
+		hub:            hub,
+		retry:          RetryConfig{BaseDelay: time.Millisecond},
+		logger:         logger,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	pool := NewPool(1, deliverer, nil, logger)
+	pool.Start(ctx)
+
+	poison := engine.DeliveryJob{EventID: "evt-poison", SubscriberID: "sub-poison", Attempt: 1, MaxRetries: 5}
+	pool.Submit(poison)
+	last := poison
+	last.EventID = "evt-last-straw"
+	last.Panics = MaxJobPanics - 1
+	pool.Submit(last)
+	// The single worker survives to deliver the next job
+	pool.Submit(engine.DeliveryJob{EventID: "evt-ok", SubscriberID: "sub-ok", EndpointURL: server.URL, Payload: json.RawMessage(`{}`), Attempt: 1, MaxRetries: 5})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for delivered.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	pool.Stop()
+
+	if delivered.Load() != 1 {
+		t.Fatal("worker did not deliver a job after panics")
+	}
+	if got := pool.Stats().Panics; got != 2 {
+		t.Errorf("Stats().Panics = %d, want 2", got)
+	}
+
+	// The first panic requeues the job without using up an attempt
+	claimed, _ := queue.Claim(context.Background(), time.Now().Add(time.Minute), 10)
+	if len(claimed) != 1 {
+		t.Fatalf("requeued %d jobs, want 1", len(claimed))
+	}
+	var requeued engine.DeliveryJob
+	json.Unmarshal([]byte(claimed[0].Member), &requeued)
+	if requeued.EventID != "evt-poison" || requeued.Panics != 1 || requeued.Attempt != 1 {
+		t.Errorf("requeued job = %+v, want evt-poison with 1 panic at attempt 1", requeued)
+	}
+
+	// The last straw is dead-lettered with the panic message
+	letters, _ := s.ListDeadLetters(context.Background(), store.DeadLetterFilter{SubscriberID: "sub-poison"})
+	if len(letters) != 1 || letters[0].EventID != "evt-last-straw" {
+		t.Fatalf("dead letters = %+v, want evt-last-straw", letters)
+	}
+	if e := letters[0].LastError; e == nil || *e != "panic: poison job" {
+		t.Errorf("dead letter error = %v, want the panic message", e)
+	}
+	if r := letters[0].FailureReason; r == nil || *r != string(domain.FailurePanic) {
+		t.Errorf("dead letter failure reason = %v, want panic", r)
+	}
+}
@@ -0,0 +1,133 @@
+package worker
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+)
+
+// pubsubAudience is the audience of the tokens Pub/Sub accepts from
+// service accounts without an OAuth exchange.
+const pubsubAudience = "https://pubsub.googleapis.com/"
+
+// Self-signed tokens are valid for an hour, and replaced ten minutes
+// before they expire.
+const (
+	pubsubTokenLifetime = time.Hour
+	pubsubTokenRefresh  = 50 * time.Minute
+)
+
+// pubsubToken is a service account's current access token.
+type pubsubToken struct {
+	key      *domain.ServiceAccountKey
+	token    string
+	issuedAt time.Time
+}
+
+// pubsubMessage is a message of a Pub/Sub publish request.
+type pubsubMessage struct {
+	Data       string            `json:"data"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// pushPubSub publishes the payload to job's topic.
+func (d *Deliverer) pushPubSub(job *engine.DeliveryJob, req *http.Request, body []byte) (*http.Response, error) {
+	publishURL, err := domain.ParsePubSubTopicURL(job.EndpointURL)
+	if err != nil {
+		return nil, err
+	}
+	var serviceAccountKey string
+	if job.EndpointCredentials != nil {
+		serviceAccountKey = job.EndpointCredentials.ServiceAccountKey
+	}
+	token, err := d.queues.pubsubToken(serviceAccountKey, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(map[string][]pubsubMessage{
+		"messages": {{
+			Data:       base64.StdEncoding.EncodeToString(body),
+			Attributes: messageAttributes(req.Header),
+		}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encoding message: %w", err)
+	}
+	push, err := http.NewRequestWithContext(req.Context(), http.MethodPost, publishURL, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	push.Header.Set("Content-Type", "application/json")
+	push.Header.Set("Authorization", "Bearer "+token)
+	return d.pushClient().Do(push)
+}
+
+// pubsubToken returns an access token for the service account with the
+// JSON key serviceAccountKey: a JWT it signs itself, which Google APIs
+// accept in place of an OAuth token. Tokens and parsed keys are reused
+// until the token is due to be replaced.
+func (p *queuePushers) pubsubToken(serviceAccountKey string, now time.Time) (string, error) {
+	sum := sha256.Sum256([]byte(serviceAccountKey))
+	id := string(sum[:])
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	cached := p.tokens[id]
+	if cached != nil && now.Sub(cached.issuedAt) < pubsubTokenRefresh {
+		return cached.token, nil
+	}
+	if cached == nil {
+		key, err := domain.ParseServiceAccountKey(serviceAccountKey)
+		if err != nil {
+			return "", err
+		}
+		cached = &pubsubToken{key: key}
+	}
+	token, err := signServiceAccountJWT(cached.key, now)
+	if err != nil {
+		return "", err
+	}
+	cached.token, cached.issuedAt = token, now
+	if p.tokens == nil {
+		p.tokens = map[string]*pubsubToken{}
+	}
+	p.tokens[id] = cached
+	return token, nil
+}
+
+// signServiceAccountJWT returns a JWT for the Pub/Sub API signed by key
+// with RS256.
+func signServiceAccountJWT(key *domain.ServiceAccountKey, now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": key.PrivateKeyID})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{
+		"iss": key.ClientEmail,
+		"sub": key.ClientEmail,
+		"aud": pubsubAudience,
+		"iat": now.Unix(),
+		"exp": now.Add(pubsubTokenLifetime).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key.PrivateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("signing access token: %w", err)
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
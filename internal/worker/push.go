@@ -0,0 +1,100 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+)
+
+// queuePushers holds what deliveries to queue endpoints keep between
+// attempts. The zero value is ready to use.
+type queuePushers struct {
+	// dial connects to AMQP brokers, through the delivery transport's
+	// dialer so its DNS cache, IP preference and private address
+	// blocking apply. Nil uses a plain net.Dialer.
+	dial func(ctx context.Context, network, addr string) (net.Conn, error)
+
+	mu      sync.Mutex
+	tokens  map[string]*pubsubToken
+	brokers map[string]*amqpBroker
+}
+
+// Close closes the connections kept open for deliveries to AMQP
+// endpoints, once the workers have stopped.
+func (d *Deliverer) Close() {
+	d.queues.close()
+}
+
+// pushesToQueue reports whether job's endpoint is a queue rather than an
+// HTTP receiver.
+func pushesToQueue(job engine.DeliveryJob) bool {
+	return domain.EndpointTypeOrDefault(job.EndpointType) != domain.EndpointHTTP
+}
+
+// push delivers req to job's queue endpoint instead of sending it: its
+// body becomes the message and its headers, signature included, the
+// message's attributes. SQS and Pub/Sub are published to over HTTP, so
+// their responses are returned as they are. Redirects aren't followed.
+func (d *Deliverer) push(job *engine.DeliveryJob, req *http.Request) (*http.Response, error) {
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("reading payload: %w", err)
+	}
+	switch job.EndpointType {
+	case domain.EndpointSQS:
+		return d.pushSQS(job, req, body)
+	case domain.EndpointPubSub:
+		return d.pushPubSub(job, req, body)
+	case domain.EndpointAMQP:
+		return d.pushAMQP(job, req, body)
+	}
+	return nil, fmt.Errorf("unknown endpoint type %q", job.EndpointType)
+}
+
+// pushClient is the client queue endpoints are published to over HTTP:
+// the delivery client, without following redirects.
+func (d *Deliverer) pushClient() *http.Client {
+	client := *d.httpClient
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return &client
+}
+
+// messageAttributes returns the headers of a delivery request to carry as
+// a message's attributes. Attribute names are case-sensitive where header
+// names aren't, so they are lowercased, as in HTTP/2: x-webhook-id,
+// x-webhook-signature and so on.
+func messageAttributes(h http.Header) map[string]string {
+	attrs := make(map[string]string, len(h))
+	for name, values := range h {
+		if len(values) > 0 {
+			attrs[strings.ToLower(name)] = values[0]
+		}
+	}
+	return attrs
+}
+
+// syntheticResponse stands in for the response of a push that isn't made
+// over HTTP, so the attempt is recorded like any other.
+func syntheticResponse(req *http.Request, statusCode int, body string) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+		StatusCode:    statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"text/plain; charset=utf-8"}},
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
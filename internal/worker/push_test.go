@@ -0,0 +1,274 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+	"github.com/Priya8975/webhook-delivery-system/internal/sigv4"
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+)
+
+// pushTest delivers job with a deliverer recording into a memory store,
+// and returns the attempt it recorded.
+func pushTest(t *testing.T, job engine.DeliveryJob) domain.DeliveryAttempt {
+	t.Helper()
+	_, cb, rl, hub, logger := setupDeliveryTest(t)
+	s := store.NewMemoryStore()
+	deliverer := &Deliverer{
+		httpClient:     &http.Client{Timeout: 5 * time.Second},
+		gzipThreshold:  1,
+		bodyLimit:      1024,
+		store:          s,
+		queue:          engine.NewMemoryQueue(),
+		circuitBreaker: cb,
+		rateLimiter:    rl,
+		hub:            hub,
+		logger:         logger,
+	}
+	defer deliverer.Close()
+	deliverer.Deliver(context.Background(), job)
+
+	attempts, _ := s.ListDeliveryAttempts(context.Background(), store.DeliveryAttemptFilter{EventID: job.EventID})
+	if len(attempts) != 1 {
+		t.Fatalf("recorded %d attempts, want 1", len(attempts))
+	}
+	return attempts[0]
+}
+
+func TestDelivery_PushesToSQS(t *testing.T) {
+	var received sqsSendMessage
+	var target, authorization string
+	var resigned bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &received)
+		target, authorization = r.Header.Get("X-Amz-Target"), r.Header.Get("Authorization")
+
+		// Signing the request again as received gives the same signature
+		check, _ := http.NewRequest(r.Method, "http://"+r.Host+r.URL.Path, nil)
+		for _, name := range []string{"Content-Type", "X-Amz-Target", "X-Amz-Security-Token"} {
+			if v := r.Header.Get(name); v != "" {
+				check.Header.Set(name, v)
+			}
+		}
+		date, _ := time.Parse("20060102T150405Z", r.Header.Get("X-Amz-Date"))
+		sigv4.Sign(check, body, "sqs", "us-east-1", sigv4.Credentials{SecretAccessKey: "secret", AccessKeyID: "AKID", SessionToken: "token"}, date)
+		resigned = check.Header.Get("Authorization") == authorization
+
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		w.Write([]byte(`{"MessageId":"msg-1"}`))
+	}))
+	defer server.Close()
+
+	attempt := pushTest(t, engine.DeliveryJob{
+		EventID:             "evt-sqs",
+		SubscriberID:        "sub-sqs",
+		EndpointURL:         server.URL + "/123456789012/orders.fifo",
+		EndpointType:        domain.EndpointSQS,
+		EndpointCredentials: &domain.EndpointCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret", SessionToken: "token"},
+		Payload:             json.RawMessage(`{"order_id":"abc-123"}`),
+		SecretKey:           "signing-secret",
+		EventType:           "order.created",
+		CompressPayload:     true,
+		Attempt:             1,
+		MaxRetries:          3,
+	})
+
+	if attempt.Status != "success" || attempt.ResponseBody == nil || !strings.Contains(*attempt.ResponseBody, "msg-1") {
+		t.Fatalf("attempt = %+v, want a success with SQS's response", attempt)
+	}
+	if target != "AmazonSQS.SendMessage" {
+		t.Errorf("X-Amz-Target = %q", target)
+	}
+	if !strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=AKID/") || !strings.Contains(authorization, "/us-east-1/sqs/aws4_request") {
+		t.Errorf("Authorization = %q", authorization)
+	}
+	if !resigned {
+		t.Error("the request's signature doesn't match it")
+	}
+	// Queued uncompressed, whatever compress_payloads says
+	if received.MessageBody != `{"order_id":"abc-123"}` || received.QueueURL != server.URL+"/123456789012/orders.fifo" {
+		t.Errorf("message = %+v", received)
+	}
+	if received.MessageGroupID != "order.created" || received.MessageDeduplicationID != "evt-sqs" {
+		t.Errorf("FIFO group and deduplication IDs = %q, %q", received.MessageGroupID, received.MessageDeduplicationID)
+	}
+	if received.MessageAttributes["x-webhook-event"].StringValue != "order.created" || received.MessageAttributes["x-webhook-signature"].StringValue == "" {
+		t.Errorf("message attributes = %+v", received.MessageAttributes)
+	}
+}
+
+// testServiceAccountKey returns a service account's JSON key and its
+// private key.
+func TestSQSMessageAttributes_KeepsWebhookHeadersWithinLimit(t *testing.T) {
+	h := http.Header{}
+	h.Set("Content-Type", "application/json")
+	h.Set("X-Webhook-Signature", "sig")
+	h.Set("X-Webhook-Event", "order.created")
+	h.Set("X-Webhook-Event-Version", "1")
+	h.Set("X-Webhook-ID", "evt-1")
+	h.Set("X-Webhook-Delivery-ID", "dlv-1")
+	h.Set("X-Webhook-Attempt", "1")
+	h.Set("Svix-Id", "evt-1")
+	h.Set("Svix-Signature", "v1,sig")
+	h.Set("Svix-Timestamp", "1700000000")
+	h.Set("Traceparent", "00-trace-span-01")
+
+	attrs := sqsMessageAttributes(h)
+	if len(attrs) != sqsMaxMessageAttributes {
+		t.Fatalf("got %d attributes, want %d", len(attrs), sqsMaxMessageAttributes)
+	}
+	for _, name := range []string{"x-webhook-signature", "x-webhook-event", "x-webhook-id", "x-webhook-attempt", "content-type"} {
+		if _, ok := attrs[name]; !ok {
+			t.Errorf("attribute %s dropped: %v", name, attrs)
+		}
+	}
+	if _, ok := attrs["traceparent"]; ok {
+		t.Errorf("the last header by name should be dropped: %v", attrs)
+	}
+}
+
+func testServiceAccountKey(t *testing.T) (string, *rsa.PrivateKey) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, _ := x509.MarshalPKCS8PrivateKey(key)
+	file, _ := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"client_email":   "webhooks@acme.iam.gserviceaccount.com",
+		"private_key_id": "key-1",
+		"private_key":    string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+	})
+	return string(file), key
+}
+
+func TestDelivery_PushesToPubSub(t *testing.T) {
+	serviceAccountKey, key := testServiceAccountKey(t)
+	var path, token string
+	var received struct {
+		Messages []pubsubMessage `json:"messages"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		token = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte(`{"messageIds":["42"]}`))
+	}))
+	defer server.Close()
+
+	attempt := pushTest(t, engine.DeliveryJob{
+		EventID:             "evt-pubsub",
+		SubscriberID:        "sub-pubsub",
+		EndpointURL:         server.URL + "/v1/projects/acme/topics/orders",
+		EndpointType:        domain.EndpointPubSub,
+		EndpointCredentials: &domain.EndpointCredentials{ServiceAccountKey: serviceAccountKey},
+		Payload:             json.RawMessage(`{"order_id":"abc-123"}`),
+		SecretKey:           "signing-secret",
+		EventType:           "order.created",
+		Attempt:             1,
+		MaxRetries:          3,
+	})
+
+	if attempt.Status != "success" || attempt.ResponseBody == nil || !strings.Contains(*attempt.ResponseBody, "42") {
+		t.Fatalf("attempt = %+v, want a success with Pub/Sub's response", attempt)
+	}
+	if path != "/v1/projects/acme/topics/orders:publish" {
+		t.Errorf("published to %s", path)
+	}
+	if len(received.Messages) != 1 {
+		t.Fatalf("received %d messages, want 1", len(received.Messages))
+	}
+	data, _ := base64.StdEncoding.DecodeString(received.Messages[0].Data)
+	if string(data) != `{"order_id":"abc-123"}` || received.Messages[0].Attributes["x-webhook-id"] != "evt-pubsub" {
+		t.Errorf("message = %+v", received.Messages[0])
+	}
+
+	// The token is a JWT signed with the service account's key
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("token %q isn't a JWT", token)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature); err != nil {
+		t.Errorf("token signature: %v", err)
+	}
+	header, _ := base64.RawURLEncoding.DecodeString(parts[0])
+	claims, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	if !bytes.Contains(header, []byte(`"kid":"key-1"`)) {
+		t.Errorf("token header = %s", header)
+	}
+	if !bytes.Contains(claims, []byte(`"iss":"webhooks@acme.iam.gserviceaccount.com"`)) || !bytes.Contains(claims, []byte(`"aud":"`+pubsubAudience+`"`)) {
+		t.Errorf("token claims = %s", claims)
+	}
+}
+
+func TestQueuePushers_ReusesPubSubTokens(t *testing.T) {
+	serviceAccountKey, _ := testServiceAccountKey(t)
+	var p queuePushers
+	now := time.Now()
+	first, err := p.pubsubToken(serviceAccountKey, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := p.pubsubToken(serviceAccountKey, now.Add(time.Minute)); again != first {
+		t.Error("a fresh token was signed while the last one was still good")
+	}
+	if renewed, _ := p.pubsubToken(serviceAccountKey, now.Add(pubsubTokenRefresh)); renewed == first {
+		t.Error("a token due to be replaced was reused")
+	}
+	if _, err := p.pubsubToken(`{"type":"authorized_user"}`, now); err == nil {
+		t.Error("a key that isn't a service account's signed a token")
+	}
+}
+
+func TestDelivery_AMQPBrokerUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	attempt := pushTest(t, engine.DeliveryJob{
+		EventID:      "evt-amqp",
+		SubscriberID: "sub-amqp",
+		EndpointURL:  "amqp://" + addr + "/?exchange=webhooks",
+		EndpointType: domain.EndpointAMQP,
+		Payload:      json.RawMessage(`{"order_id":"abc-123"}`),
+		SecretKey:    "signing-secret",
+		EventType:    "order.created",
+		Attempt:      1,
+		MaxRetries:   3,
+	})
+	if attempt.Status != "failed" || attempt.FailureReason == nil || *attempt.FailureReason != string(domain.FailureConnection) {
+		t.Errorf("attempt = %+v, want a connection error", attempt)
+	}
+}
+
+func TestAMQPStatus(t *testing.T) {
+	for code, want := range map[int]int{403: 403, 404: 404, 312: 404, 406: 503, 541: 503} {
+		if got := amqpStatus(code); got != want {
+			t.Errorf("amqpStatus(%d) = %d, want %d", code, got, want)
+		}
+	}
+}
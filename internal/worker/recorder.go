@@ -0,0 +1,159 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+)
+
+// RecorderConfig tunes batching of delivery attempt inserts.
+type RecorderConfig struct {
+	// QueueSize bounds how many attempts can wait to be written. When the
+	// queue is full, Record blocks, so a slow database slows delivery
+	// rather than growing memory or dropping attempts.
+	QueueSize int
+	// BatchSize is the most attempts written by one INSERT.
+	BatchSize int
+	// FlushInterval is the longest an attempt waits for its batch to fill.
+	FlushInterval time.Duration
+}
+
+// AttemptMirror receives each batch of attempts the recorder writes, to copy
+// them elsewhere. Mirror must not block or keep recs.
+type AttemptMirror interface {
+	Mirror(recs []store.DeliveryAttemptRecord)
+}
+
+// AttemptRecorder takes delivery attempt inserts off the delivery path. Workers
+// hand attempts to Record, and a single background goroutine writes them to
+// the store in multi-row batches.
+//
+// Attempts are only held in memory, so those still queued when the process
+// dies are lost; Stop flushes everything queued before returning.
+type AttemptRecorder struct {
+	insert        func(ctx context.Context, recs []store.DeliveryAttemptRecord) error
+	queue         chan store.DeliveryAttemptRecord
+	batchSize     int
+	flushInterval time.Duration
+	mirror        AttemptMirror
+	logger        *slog.Logger
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	done      chan struct{}
+}
+
+func NewAttemptRecorder(s store.DeliveryStore, cfg RecorderConfig, logger *slog.Logger) *AttemptRecorder {
+	return newAttemptRecorder(s.InsertDeliveryAttempts, cfg, logger)
+}
+
+func newAttemptRecorder(insert func(context.Context, []store.DeliveryAttemptRecord) error, cfg RecorderConfig, logger *slog.Logger) *AttemptRecorder {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 10000
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 500
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = 200 * time.Millisecond
+	}
+	return &AttemptRecorder{
+		insert:        insert,
+		queue:         make(chan store.DeliveryAttemptRecord, cfg.QueueSize),
+		batchSize:     cfg.BatchSize,
+		flushInterval: cfg.FlushInterval,
+		logger:        logger,
+		done:          make(chan struct{}),
+	}
+}
+
+// SetMirror hands every batch of attempts to m as well as the store,
+// whether or not the store took them. Call it before Start.
+func (r *AttemptRecorder) SetMirror(m AttemptMirror) {
+	r.mirror = m
+}
+
+// Start launches the goroutine that writes queued attempts.
+func (r *AttemptRecorder) Start() {
+	r.startOnce.Do(func() {
+		go r.run()
+		r.logger.Info("attempt recorder started",
+			"queue_size", cap(r.queue),
+			"batch_size", r.batchSize,
+			"flush_interval", r.flushInterval.String(),
+		)
+	})
+}
+
+// Record queues an attempt to be written. It must not be called after Stop.
+func (r *AttemptRecorder) Record(rec store.DeliveryAttemptRecord) {
+	r.queue <- rec
+}
+
+// Pending returns the number of attempts waiting to be written.
+func (r *AttemptRecorder) Pending() int {
+	return len(r.queue)
+}
+
+// Stop writes every queued attempt and waits for the writer to exit. Call
+// it once the workers that record attempts have stopped.
+func (r *AttemptRecorder) Stop() {
+	r.stopOnce.Do(func() {
+		r.Start()
+		close(r.queue)
+		<-r.done
+		r.logger.Info("attempt recorder stopped")
+	})
+}
+
+func (r *AttemptRecorder) run() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]store.DeliveryAttemptRecord, 0, r.batchSize)
+	for {
+		select {
+		case rec, ok := <-r.queue:
+			if !ok {
+				r.flush(batch)
+				return
+			}
+			batch = append(batch, rec)
+			if len(batch) >= r.batchSize {
+				r.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				r.flush(batch)
+				batch = batch[:0]
+			}
+		}
+	}
+}
+
+// flush writes one batch. Inserts run without the server's context so that
+// attempts queued during shutdown are still written.
+func (r *AttemptRecorder) flush(batch []store.DeliveryAttemptRecord) {
+	if len(batch) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := r.insert(ctx, batch); err != nil {
+		r.logger.Error("failed to record delivery attempts",
+			"error", err,
+			"count", len(batch),
+		)
+	}
+	if r.mirror != nil {
+		r.mirror.Mirror(batch)
+	}
+}
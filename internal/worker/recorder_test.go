@@ -0,0 +1,138 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+)
+
+// batchCollector records the batches an AttemptRecorder writes.
+type batchCollector struct {
+	mu      sync.Mutex
+	batches [][]store.DeliveryAttemptRecord
+	err     error
+}
+
+func (c *batchCollector) insert(_ context.Context, recs []store.DeliveryAttemptRecord) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.batches = append(c.batches, append([]store.DeliveryAttemptRecord(nil), recs...))
+	return c.err
+}
+
+func (c *batchCollector) sizes() []int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var sizes []int
+	for _, b := range c.batches {
+		sizes = append(sizes, len(b))
+	}
+	return sizes
+}
+
+func newTestRecorder(c *batchCollector, cfg RecorderConfig) *AttemptRecorder {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	return newAttemptRecorder(c.insert, cfg, logger)
+}
+
+func TestAttemptRecorder_BatchesAndFlushesOnStop(t *testing.T) {
+	c := &batchCollector{}
+	r := newTestRecorder(c, RecorderConfig{BatchSize: 3, FlushInterval: time.Hour})
+	r.Start()
+
+	for i := 1; i <= 7; i++ {
+		r.Record(store.DeliveryAttemptRecord{EventID: "evt", AttemptNumber: i})
+	}
+	r.Stop()
+
+	sizes := c.sizes()
+	if len(sizes) != 3 || sizes[0] != 3 || sizes[1] != 3 || sizes[2] != 1 {
+		t.Fatalf("batch sizes = %v, want [3 3 1]", sizes)
+	}
+	if last := c.batches[2][0].AttemptNumber; last != 7 {
+		t.Errorf("last attempt written = %d, want 7", last)
+	}
+}
+
+func TestAttemptRecorder_FlushesPartialBatchOnInterval(t *testing.T) {
+	c := &batchCollector{}
+	r := newTestRecorder(c, RecorderConfig{BatchSize: 100, FlushInterval: 10 * time.Millisecond})
+	r.Start()
+	defer r.Stop()
+
+	r.Record(store.DeliveryAttemptRecord{EventID: "evt"})
+
+	deadline := time.Now().Add(time.Second)
+	for len(c.sizes()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("partial batch was not flushed by the interval")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestAttemptRecorder_ContinuesAfterInsertError(t *testing.T) {
+	c := &batchCollector{err: errors.New("connection refused")}
+	r := newTestRecorder(c, RecorderConfig{BatchSize: 1, FlushInterval: time.Hour})
+	r.Start()
+
+	r.Record(store.DeliveryAttemptRecord{EventID: "a"})
+	r.Record(store.DeliveryAttemptRecord{EventID: "b"})
+	r.Stop()
+
+	if got := len(c.sizes()); got != 2 {
+		t.Errorf("wrote %d batches, want 2", got)
+	}
+}
+
+func TestAttemptRecorder_MirrorsEveryBatch(t *testing.T) {
+	c := &batchCollector{err: errors.New("connection refused")}
+	mirrored := &batchCollector{}
+	r := newTestRecorder(c, RecorderConfig{BatchSize: 2, FlushInterval: time.Hour})
+	r.SetMirror(mirrorFunc(func(recs []store.DeliveryAttemptRecord) {
+		mirrored.insert(context.Background(), recs)
+	}))
+	r.Start()
+
+	for i := 1; i <= 3; i++ {
+		r.Record(store.DeliveryAttemptRecord{EventID: "evt", AttemptNumber: i})
+	}
+	r.Stop()
+
+	// Batches the store rejected are mirrored too
+	if sizes := mirrored.sizes(); len(sizes) != 2 || sizes[0] != 2 || sizes[1] != 1 {
+		t.Errorf("mirrored batch sizes = %v, want [2 1]", sizes)
+	}
+}
+
+type mirrorFunc func([]store.DeliveryAttemptRecord)
+
+func (f mirrorFunc) Mirror(recs []store.DeliveryAttemptRecord) { f(recs) }
+
+func BenchmarkAttemptRecorder_Record(b *testing.B) {
+	discard := func(context.Context, []store.DeliveryAttemptRecord) error { return nil }
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	r := newAttemptRecorder(discard, RecorderConfig{BatchSize: 100, FlushInterval: time.Second}, logger)
+	r.Start()
+	status := 200
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.Record(store.DeliveryAttemptRecord{
+			EventID:        "evt-bench",
+			SubscriberID:   "sub-bench",
+			AttemptNumber:  1,
+			Status:         "success",
+			HTTPStatusCode: &status,
+			ResponseTimeMs: 12,
+		})
+	}
+	r.Stop()
+}
@@ -0,0 +1,95 @@
+package worker
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// redactedText replaces every redacted match.
+const redactedText = "[REDACTED]"
+
+// redactionPresets are the built-in patterns selectable by name.
+var redactionPresets = map[string]*redaction{
+	"email": {re: regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)},
+	// Card numbers are confirmed with a Luhn check so that IDs and
+	// millisecond timestamps of the same length are left alone
+	"card":   {re: regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`), valid: luhnValid},
+	"ssn":    {re: regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)},
+	"bearer": {re: regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9\-._~+/]+=*`)},
+}
+
+type redaction struct {
+	re *regexp.Regexp
+	// valid, when set, filters matches; only those it accepts are redacted.
+	valid func(match string) bool
+}
+
+// Redactor masks sensitive data in endpoint responses before they are
+// stored. A nil Redactor leaves text unchanged.
+type Redactor struct {
+	redactions []*redaction
+}
+
+// NewRedactor builds a redactor from preset names (email, card, ssn, bearer)
+// and an optional custom regular expression. It returns nil when neither is
+// given.
+func NewRedactor(presets []string, pattern string) (*Redactor, error) {
+	var redactions []*redaction
+	for _, name := range presets {
+		preset, ok := redactionPresets[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown redaction preset %q", name)
+		}
+		redactions = append(redactions, preset)
+	}
+	if pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern: %w", err)
+		}
+		redactions = append(redactions, &redaction{re: re})
+	}
+
+	if len(redactions) == 0 {
+		return nil, nil
+	}
+	return &Redactor{redactions: redactions}, nil
+}
+
+// Redact returns s with every match replaced by [REDACTED].
+func (r *Redactor) Redact(s string) string {
+	if r == nil {
+		return s
+	}
+	for _, rd := range r.redactions {
+		s = rd.re.ReplaceAllStringFunc(s, func(match string) string {
+			if rd.valid != nil && !rd.valid(match) {
+				return match
+			}
+			return redactedText
+		})
+	}
+	return s
+}
+
+// luhnValid reports whether the digits in s pass the Luhn checksum.
+func luhnValid(s string) bool {
+	sum, double := 0, false
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
@@ -0,0 +1,58 @@
+package worker
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedactor_Presets(t *testing.T) {
+	r, err := NewRedactor([]string{"email", "Card", "ssn", "bearer"}, "")
+	if err != nil {
+		t.Fatalf("NewRedactor failed: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"email", `{"email":"jane.doe+test@example.co.uk"}`, `{"email":"[REDACTED]"}`},
+		{"card", "card 4111 1111 1111 1111 declined", "card [REDACTED] declined"},
+		{"non-luhn number kept", `{"ts":1718000000000,"order":1234567890123456}`, `{"ts":1718000000000,"order":1234567890123456}`},
+		{"ssn", "ssn=123-45-6789", "ssn=[REDACTED]"},
+		{"bearer", "invalid token: Bearer eyJhbGciOi.J9x-y_z=", "invalid token: [REDACTED]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.Redact(tt.in); got != tt.want {
+				t.Errorf("Redact(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactor_CustomPattern(t *testing.T) {
+	r, err := NewRedactor(nil, `acct_[0-9a-f]{8}`)
+	if err != nil {
+		t.Fatalf("NewRedactor failed: %v", err)
+	}
+	if got := r.Redact("account acct_deadbeef locked"); got != "account [REDACTED] locked" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestNewRedactor_Config(t *testing.T) {
+	if r, err := NewRedactor(nil, ""); r != nil || err != nil {
+		t.Errorf("empty config = %v, %v; want nil redactor", r, err)
+	}
+	var nilRedactor *Redactor
+	if got := nilRedactor.Redact("a@b.io"); got != "a@b.io" {
+		t.Errorf("nil redactor changed text: %q", got)
+	}
+	if _, err := NewRedactor([]string{"phone"}, ""); err == nil || !strings.Contains(err.Error(), "phone") {
+		t.Errorf("unknown preset error = %v", err)
+	}
+	if _, err := NewRedactor(nil, "("); err == nil {
+		t.Error("expected an error for an invalid pattern")
+	}
+}
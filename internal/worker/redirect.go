@@ -0,0 +1,108 @@
+package worker
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+)
+
+// maxRedirects is how many redirects one attempt follows before it fails
+// with the last redirect's response.
+const maxRedirects = 10
+
+// send sends req, following the redirects job's redirect policy allows.
+// Redirects are followed here rather than by the client, which would turn
+// the POST of a 301 or 302 into a GET without the payload. Each followed
+// redirect sends the same POST to its target, re-signed by resign under
+// domain.RedirectFollowResign. A redirect that isn't followed is returned
+// as the response, and fails the attempt like any other non-2xx status.
+// job.FinalURL is set to the URL of the response once a redirect has been
+// followed. Jobs for queue endpoints are pushed to the queue instead.
+func (d *Deliverer) send(job *engine.DeliveryJob, req *http.Request, resign func(http.Header) error) (*http.Response, error) {
+	if pushesToQueue(*job) {
+		return d.push(job, req)
+	}
+	client := *d.httpClient
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	policy := job.RedirectPolicy
+	if policy == "" {
+		policy = d.redirectPolicy
+	}
+	if policy == "" {
+		policy = domain.RedirectSameHost
+	}
+
+	for redirects := 0; ; redirects++ {
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if redirects > 0 {
+			job.FinalURL = req.URL.String()
+		}
+
+		target, ok := redirectTarget(resp)
+		if !ok || policy == domain.RedirectNever || redirects == maxRedirects {
+			return resp, nil
+		}
+		if policy == domain.RedirectSameHost && !sameHost(req.URL, target) {
+			return resp, nil
+		}
+		if req.GetBody == nil {
+			return resp, nil
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return resp, nil
+		}
+		io.CopyN(io.Discard, resp.Body, drainLimit)
+		resp.Body.Close()
+
+		next := req.Clone(req.Context())
+		next.URL = target
+		next.Host = ""
+		next.Body = body
+		if policy == domain.RedirectFollowResign {
+			if err := resign(next.Header); err != nil {
+				body.Close()
+				return nil, err
+			}
+		}
+		req = next
+	}
+}
+
+// redirectTarget returns where resp redirects to, if it is a redirect that
+// keeps the request's method and body. 303 See Other asks for a GET, which
+// would drop the payload, so it isn't one.
+func redirectTarget(resp *http.Response) (*url.URL, bool) {
+	switch resp.StatusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+	default:
+		return nil, false
+	}
+	location := resp.Header.Get("Location")
+	if location == "" {
+		return nil, false
+	}
+	target, err := resp.Request.URL.Parse(location)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") {
+		return nil, false
+	}
+	return target, true
+}
+
+// sameHost reports whether a redirect from one URL to another stays on the
+// same host, without dropping from https to http.
+func sameHost(from, to *url.URL) bool {
+	if from.Scheme == "https" && to.Scheme == "http" {
+		return false
+	}
+	return strings.EqualFold(from.Hostname(), to.Hostname())
+}
@@ -0,0 +1,193 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"golang.org/x/sync/singleflight"
+)
+
+// maxDNSCacheEntries is the size at which the cache sweeps out expired
+// entries as it stores new ones.
+const maxDNSCacheEntries = 4096
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598), which
+// net.IP.IsPrivate does not cover.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// dnsCache remembers successful lookups for a fixed TTL, whatever the TTL
+// of the records, so busy endpoints are not resolved on every new
+// connection. Concurrent lookups of the same host share one query.
+type dnsCache struct {
+	lookup func(ctx context.Context, host string) ([]net.IPAddr, error)
+	ttl    time.Duration
+
+	group   singleflight.Group
+	mu      sync.Mutex
+	entries map[string]dnsEntry
+}
+
+type dnsEntry struct {
+	ips     []net.IP
+	expires time.Time
+}
+
+func newDNSCache(ttl time.Duration) *dnsCache {
+	return &dnsCache{
+		lookup:  net.DefaultResolver.LookupIPAddr,
+		ttl:     ttl,
+		entries: make(map[string]dnsEntry),
+	}
+}
+
+// resolve returns the addresses of host, from the cache if they are fresh.
+func (c *dnsCache) resolve(ctx context.Context, host string) ([]net.IP, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[host]
+	c.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.ips, nil
+	}
+
+	v, err, _ := c.group.Do(host, func() (interface{}, error) {
+		addrs, err := c.lookup(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		ips := make([]net.IP, len(addrs))
+		for i, a := range addrs {
+			ips[i] = a.IP
+		}
+		c.store(host, ips)
+		return ips, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.([]net.IP), nil
+}
+
+func (c *dnsCache) store(host string, ips []net.IP) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if len(c.entries) >= maxDNSCacheEntries {
+		for h, e := range c.entries {
+			if !now.Before(e.expires) {
+				delete(c.entries, h)
+			}
+		}
+	}
+	c.entries[host] = dnsEntry{ips: ips, expires: now.Add(c.ttl)}
+}
+
+// isPrivateIP reports whether ip is one a webhook endpoint has no business
+// resolving to: loopback, private, link-local (which includes cloud
+// metadata services), shared, unspecified or multicast.
+func isPrivateIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() ||
+		ip.IsUnspecified() || sharedAddressSpace.Contains(ip)
+}
+
+// blockedAddressError is returned when an endpoint resolves to an address
+// isPrivateIP rejects.
+type blockedAddressError struct {
+	addr, host string
+	ip         net.IP
+}
+
+func (e *blockedAddressError) Error() string {
+	return fmt.Sprintf("dial %s: %s resolves to private address %s", e.addr, e.host, e.ip)
+}
+
+// deliveryDialer opens delivery connections. It resolves endpoints itself,
+// through the DNS cache if there is one, and with blockPrivate refuses
+// addresses that isPrivateIP rejects. The check runs on the address
+// actually dialed, so an endpoint cannot pass it with a public address and
+// then rebind its name to an internal one. Addresses are dialed in the
+// order ipPreference gives, racing the other family after fallbackDelay.
+type deliveryDialer struct {
+	dialer        *net.Dialer
+	dns           *dnsCache // nil resolves on every dial
+	blockPrivate  bool
+	ipPreference  string        // one of domain.IPPreferences; empty is auto
+	fallbackDelay time.Duration // 0 dials addresses one after another
+
+	// proxies holds the addresses of proxies chosen for requests. They
+	// are configured by operators and usually internal, so they are dialed
+	// unchecked; the proxy resolves the endpoint.
+	proxies sync.Map
+}
+
+// trackProxies wraps a transport's Proxy function so that the proxies it
+// picks are exempt from the private address check.
+func (d *deliveryDialer) trackProxies(choose func(*http.Request) (*url.URL, error)) func(*http.Request) (*url.URL, error) {
+	return func(req *http.Request) (*url.URL, error) {
+		proxy, err := choose(req)
+		if proxy != nil {
+			d.proxies.Store(proxyAddr(proxy), struct{}{})
+		}
+		return proxy, err
+	}
+}
+
+// proxyAddr returns the host:port the transport dials to reach proxy.
+func proxyAddr(proxy *url.URL) string {
+	port := proxy.Port()
+	if port == "" {
+		switch proxy.Scheme {
+		case "https":
+			port = "443"
+		case "socks5", "socks5h":
+			port = "1080"
+		default:
+			port = "80"
+		}
+	}
+	return net.JoinHostPort(proxy.Hostname(), port)
+}
+
+func (d *deliveryDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	if d.dns == nil && !d.blockPrivate && (d.ipPreference == "" || d.ipPreference == domain.IPPreferenceAuto) {
+		// The net.Dialer races address families itself, after its
+		// FallbackDelay
+		return d.dialer.DialContext(ctx, network, addr)
+	}
+	if _, ok := d.proxies.Load(addr); ok {
+		return d.dialer.DialContext(ctx, network, addr)
+	}
+
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	var ips []net.IP
+	if ip := net.ParseIP(host); ip != nil {
+		ips = []net.IP{ip}
+	} else if d.dns != nil {
+		ips, err = d.dns.resolve(ctx, host)
+	} else {
+		var addrs []net.IPAddr
+		addrs, err = net.DefaultResolver.LookupIPAddr(ctx, host)
+		for _, a := range addrs {
+			ips = append(ips, a.IP)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	primaries, fallbacks := splitAddrs(ips, d.ipPreference)
+	if len(primaries) == 0 && len(ips) > 0 {
+		return nil, noAddressError(host, d.ipPreference)
+	}
+	return d.dialAddrs(ctx, network, addr, host, port, primaries, fallbacks)
+}
@@ -0,0 +1,101 @@
+package worker
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDNSCache_ReusesLookupsUntilTheyExpire(t *testing.T) {
+	var lookups atomic.Int32
+	c := newDNSCache(50 * time.Millisecond)
+	c.lookup = func(ctx context.Context, host string) ([]net.IPAddr, error) {
+		lookups.Add(1)
+		return []net.IPAddr{{IP: net.ParseIP("203.0.113.7")}}, nil
+	}
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		ips, err := c.resolve(ctx, "hooks.example.com")
+		if err != nil || len(ips) != 1 || !ips[0].Equal(net.ParseIP("203.0.113.7")) {
+			t.Fatalf("resolve = %v, %v", ips, err)
+		}
+	}
+	if n := lookups.Load(); n != 1 {
+		t.Errorf("expected 1 lookup while cached, got %d", n)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	c.resolve(ctx, "hooks.example.com")
+	if n := lookups.Load(); n != 2 {
+		t.Errorf("expected the expired entry to be looked up again, got %d lookups", n)
+	}
+}
+
+func TestIsPrivateIP(t *testing.T) {
+	for _, addr := range []string{"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "100.64.0.1", "0.0.0.0", "::1", "fd00::1", "fe80::1", "::ffff:10.0.0.1"} {
+		if !isPrivateIP(net.ParseIP(addr)) {
+			t.Errorf("%s should be private", addr)
+		}
+	}
+	for _, addr := range []string{"203.0.113.7", "8.8.8.8", "2001:4860:4860::8888"} {
+		if isPrivateIP(net.ParseIP(addr)) {
+			t.Errorf("%s should be public", addr)
+		}
+	}
+}
+
+func TestNewHTTPTransport_BlocksPrivateIPs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+	client := &http.Client{Transport: newHTTPTransport(TransportConfig{BlockPrivateIPs: true, DNSCacheTTL: time.Minute})}
+
+	// A name that rebinds to loopback is refused at dial time
+	dialer := &deliveryDialer{dialer: &net.Dialer{}, dns: newDNSCache(time.Minute), blockPrivate: true}
+	dialer.dns.lookup = func(ctx context.Context, host string) ([]net.IPAddr, error) {
+		return []net.IPAddr{{IP: net.ParseIP("127.0.0.1")}}, nil
+	}
+	_, err := dialer.DialContext(context.Background(), "tcp", net.JoinHostPort("rebind.example.com", port))
+	if err == nil || !strings.Contains(err.Error(), "private address 127.0.0.1") {
+		t.Errorf("expected the rebound name to be refused, got %v", err)
+	}
+
+	if _, err := client.Get(server.URL); err == nil || !strings.Contains(err.Error(), "private address") {
+		t.Errorf("expected a loopback endpoint to be refused, got %v", err)
+	}
+
+	// Proxies are trusted, so an internal proxy can still be used
+	var hits atomic.Int32
+	proxy := proxyServer(t, &hits)
+	client = &http.Client{Transport: newHTTPTransport(TransportConfig{BlockPrivateIPs: true, ProxyURL: proxy})}
+	resp, err := client.Get("http://partner.invalid/hook")
+	if err != nil {
+		t.Fatalf("request through an internal proxy failed: %v", err)
+	}
+	resp.Body.Close()
+	if hits.Load() != 1 {
+		t.Errorf("proxy got %d requests, want 1", hits.Load())
+	}
+}
+
+func TestProxyAddr(t *testing.T) {
+	for raw, want := range map[string]string{
+		"http://egress.internal":          "egress.internal:80",
+		"https://egress.internal":         "egress.internal:443",
+		"socks5://egress.internal":        "egress.internal:1080",
+		"socks5h://user:pw@10.0.0.5:9050": "10.0.0.5:9050",
+	} {
+		u, _ := url.Parse(raw)
+		if got := proxyAddr(u); got != want {
+			t.Errorf("proxyAddr(%s) = %s, want %s", raw, got, want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package worker
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/pkg/webhook"
+)
+
+func TestSign_Formats(t *testing.T) {
+	m := signedMessage{
+		ID:        "evt-1",
+		Payload:   []byte(`{"order_id":"abc-123"}`),
+		Secret:    "test-secret",
+		Timestamp: time.Unix(1700000000, 0),
+	}
+	hmacOf := func(content string) []byte {
+		mac := hmac.New(sha256.New, []byte(m.Secret))
+		mac.Write([]byte(content))
+		return mac.Sum(nil)
+	}
+	payloadHex := hex.EncodeToString(hmacOf(string(m.Payload)))
+
+	tests := []struct {
+		format string
+		want   map[string]string
+	}{
+		{domain.SignatureStandard, map[string]string{"X-Webhook-Signature": payloadHex}},
+		{"", map[string]string{"X-Webhook-Signature": payloadHex}},
+		{domain.SignatureGitHub, map[string]string{"X-Hub-Signature-256": "sha256=" + payloadHex}},
+		{domain.SignatureStripe, map[string]string{
+			"Stripe-Signature": "t=1700000000,v1=" + hex.EncodeToString(hmacOf(`1700000000.{"order_id":"abc-123"}`)),
+		}},
+		{domain.SignatureSvix, map[string]string{
+			"Svix-Id":        "evt-1",
+			"Svix-Timestamp": "1700000000",
+			"Svix-Signature": "v1," + base64.StdEncoding.EncodeToString(hmacOf(`evt-1.1700000000.{"order_id":"abc-123"}`)),
+		}},
+	}
+	for _, tt := range tests {
+		h := http.Header{}
+		sign(h, tt.format, m)
+		if len(h) != len(tt.want) {
+			t.Errorf("%q: headers = %v, want only %v", tt.format, h, tt.want)
+		}
+		for name, want := range tt.want {
+			if got := h.Get(name); got != want {
+				t.Errorf("%q: %s = %q, want %q", tt.format, name, got, want)
+			}
+		}
+	}
+}
+
+func TestSign_StandardVerifies(t *testing.T) {
+	payload := []byte(`{"ok":true}`)
+	h := http.Header{}
+	sign(h, domain.SignatureStandard, signedMessage{Payload: payload, Secret: "s3cret"})
+	if !webhook.Verify(payload, h.Get(webhook.SignatureHeader), "s3cret") {
+		t.Error("standard signature does not verify with pkg/webhook")
+	}
+}
+
+func BenchmarkSign(b *testing.B) {
+	m := signedMessage{
+		ID:        "evt-bench",
+		Payload:   bytes.Repeat([]byte("x"), 1024),
+		Secret:    "whsec_benchmark",
+		Timestamp: time.Now(),
+	}
+	for _, format := range domain.SignatureFormats {
+		b.Run(format, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(m.Payload)))
+			for i := 0; i < b.N; i++ {
+				sign(http.Header{}, format, m)
+			}
+		})
+	}
+}
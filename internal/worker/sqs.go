@@ -0,0 +1,104 @@
+package worker
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+	"github.com/Priya8975/webhook-delivery-system/internal/sigv4"
+)
+
+// sqsMessageAttribute is a string attribute of an SQS message.
+type sqsMessageAttribute struct {
+	DataType    string `json:"DataType"`
+	StringValue string `json:"StringValue"`
+}
+
+// sqsSendMessage is the body of an SQS SendMessage request in the JSON
+// protocol.
+type sqsSendMessage struct {
+	QueueURL               string                         `json:"QueueUrl"`
+	MessageBody            string                         `json:"MessageBody"`
+	MessageAttributes      map[string]sqsMessageAttribute `json:"MessageAttributes,omitempty"`
+	MessageGroupID         string                         `json:"MessageGroupId,omitempty"`
+	MessageDeduplicationID string                         `json:"MessageDeduplicationId,omitempty"`
+}
+
+// sqsMaxMessageAttributes is the most attributes SQS accepts on a message.
+const sqsMaxMessageAttributes = 10
+
+// sqsMessageAttributes returns the message attributes for a delivery's
+// headers. SendMessage rejects a message with more than
+// sqsMaxMessageAttributes, so past that the x-webhook-* headers are kept
+// first and then the rest in name order.
+func sqsMessageAttributes(h http.Header) map[string]sqsMessageAttribute {
+	headers := messageAttributes(h)
+	names := slices.SortedFunc(maps.Keys(headers), func(a, b string) int {
+		aWebhook, bWebhook := strings.HasPrefix(a, "x-webhook-"), strings.HasPrefix(b, "x-webhook-")
+		if aWebhook != bWebhook {
+			if aWebhook {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a, b)
+	})
+
+	attrs := make(map[string]sqsMessageAttribute, min(len(names), sqsMaxMessageAttributes))
+	for _, name := range names[:min(len(names), sqsMaxMessageAttributes)] {
+		attrs[name] = sqsMessageAttribute{DataType: "String", StringValue: headers[name]}
+	}
+	return attrs
+}
+
+// pushSQS sends the payload to job's queue with SendMessage. FIFO queues
+// group messages by event type, keeping each type in order, and
+// deduplicate them by event ID, so a retry after a lost response isn't
+// queued twice.
+func (d *Deliverer) pushSQS(job *engine.DeliveryJob, req *http.Request, body []byte) (*http.Response, error) {
+	queue, err := domain.ParseSQSQueueURL(job.EndpointURL)
+	if err != nil {
+		return nil, err
+	}
+	creds := job.EndpointCredentials
+	if creds == nil {
+		creds = &domain.EndpointCredentials{}
+	}
+
+	msg := sqsSendMessage{
+		QueueURL:          queue.URL,
+		MessageBody:       string(body),
+		MessageAttributes: sqsMessageAttributes(req.Header),
+	}
+	if queue.FIFO {
+		msg.MessageGroupID = job.EventType
+		msg.MessageDeduplicationID = job.EventID
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("encoding message: %w", err)
+	}
+
+	// The JSON protocol takes every action at the root of the queue's host
+	endpoint, _ := url.Parse(queue.URL)
+	endpoint.Path, endpoint.RawPath = "/", ""
+	push, err := http.NewRequestWithContext(req.Context(), http.MethodPost, endpoint.String(), bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	push.Header.Set("Content-Type", "application/x-amz-json-1.0")
+	push.Header.Set("X-Amz-Target", "AmazonSQS.SendMessage")
+	sigv4.Sign(push, data, "sqs", queue.Region, sigv4.Credentials{
+		AccessKeyID:     creds.AccessKeyID,
+		SecretAccessKey: creds.SecretAccessKey,
+		SessionToken:    creds.SessionToken,
+	}, d.now())
+	return d.pushClient().Do(push)
+}
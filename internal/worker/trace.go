@@ -0,0 +1,114 @@
+package worker
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+)
+
+// attemptTrace records when each phase of an attempt's request started and
+// ended. The transport may call its hooks from the dialing goroutine, so
+// every access is locked.
+type attemptTrace struct {
+	mu                        sync.Mutex
+	dnsStart, dnsDone         time.Time
+	connectStart, connectDone time.Time
+	tlsStart, tlsDone         time.Time
+	wroteRequest, firstByte   time.Time
+	bodyDone                  time.Time
+	reused                    bool
+}
+
+type attemptTraceContextKey struct{}
+
+// withAttemptTrace returns a context carrying t, for recordAttempt to store
+// its timings with the attempt.
+func withAttemptTrace(ctx context.Context, t *attemptTrace) context.Context {
+	return context.WithValue(ctx, attemptTraceContextKey{}, t)
+}
+
+// requestContext returns a context that traces the request sent with it
+// into t. Only the request gets it: the store and Redis dial with the
+// delivery's context, and must not be traced.
+func (t *attemptTrace) requestContext(ctx context.Context) context.Context {
+	set := func(field *time.Time) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		*field = time.Now()
+	}
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.reused = info.Reused
+		},
+		DNSStart: func(httptrace.DNSStartInfo) { set(&t.dnsStart) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { set(&t.dnsDone) },
+		ConnectStart: func(string, string) {
+			// Dialing several addresses at once starts several connects;
+			// the phase runs from the first to the last to finish
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if t.connectStart.IsZero() {
+				t.connectStart = time.Now()
+			}
+		},
+		ConnectDone:          func(string, string, error) { set(&t.connectDone) },
+		TLSHandshakeStart:    func() { set(&t.tlsStart) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { set(&t.tlsDone) },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { set(&t.wroteRequest) },
+		GotFirstResponseByte: func() { set(&t.firstByte) },
+	})
+}
+
+// attemptTraceFrom returns the trace carried by ctx, if any.
+func attemptTraceFrom(ctx context.Context) *attemptTrace {
+	t, _ := ctx.Value(attemptTraceContextKey{}).(*attemptTrace)
+	return t
+}
+
+// readBody marks the response body as read.
+func (t *attemptTrace) readBody() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.bodyDone = time.Now()
+}
+
+// timings returns the duration of each phase reached, timing one that
+// never ended until now. It returns nil if the request wasn't sent.
+func (t *attemptTrace) timings() *domain.DeliveryTimings {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	phase := func(start, end time.Time) *int {
+		if start.IsZero() {
+			return nil
+		}
+		if end.IsZero() {
+			end = now
+		}
+		ms := int(end.Sub(start).Milliseconds())
+		return &ms
+	}
+
+	timings := &domain.DeliveryTimings{
+		DNSMs:      phase(t.dnsStart, t.dnsDone),
+		ConnectMs:  phase(t.connectStart, t.connectDone),
+		TLSMs:      phase(t.tlsStart, t.tlsDone),
+		TTFBMs:     phase(t.wroteRequest, t.firstByte),
+		BodyReadMs: phase(t.firstByte, t.bodyDone),
+		ConnReused: t.reused,
+	}
+	if *timings == (domain.DeliveryTimings{}) {
+		return nil
+	}
+	return timings
+}
@@ -0,0 +1,98 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+	"github.com/Priya8975/webhook-delivery-system/internal/store"
+)
+
+func TestDeliver_RecordsTimings(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	_, cb, rl, hub, logger := setupDeliveryTest(t)
+	s := store.NewMemoryStore()
+	deliverer := &Deliverer{
+		httpClient:     server.Client(),
+		store:          s,
+		queue:          engine.NewMemoryQueue(),
+		circuitBreaker: cb,
+		rateLimiter:    rl,
+		hub:            hub,
+		logger:         logger,
+	}
+
+	timings := func(eventID string) *domain.DeliveryTimings {
+		t.Helper()
+		deliverer.Deliver(context.Background(), engine.DeliveryJob{
+			EventID:      eventID,
+			SubscriberID: "sub-timings",
+			EndpointURL:  server.URL,
+			Payload:      json.RawMessage(`{}`),
+			SecretKey:    "secret",
+			Attempt:      1,
+			MaxRetries:   3,
+		})
+		attempts, _ := s.ListDeliveryAttempts(context.Background(), store.DeliveryAttemptFilter{EventID: eventID})
+		if len(attempts) != 1 || attempts[0].Timings == nil {
+			t.Fatalf("attempts = %+v, want one with timings", attempts)
+		}
+		return attempts[0].Timings
+	}
+
+	first := timings("evt-first")
+	if first.ConnReused || first.ConnectMs == nil || first.TLSMs == nil {
+		t.Errorf("first attempt timings = %+v, want a new connection with connect and TLS phases", first)
+	}
+	if first.TTFBMs == nil || *first.TTFBMs < 50 {
+		t.Errorf("ttfb_ms = %v, want at least the endpoint's 50ms", first.TTFBMs)
+	}
+	if first.BodyReadMs == nil {
+		t.Error("body_read_ms missing")
+	}
+
+	second := timings("evt-second")
+	if !second.ConnReused || second.ConnectMs != nil || second.TLSMs != nil {
+		t.Errorf("second attempt timings = %+v, want a reused connection without connect or TLS phases", second)
+	}
+}
+
+func TestAttemptTrace_FailedConnect(t *testing.T) {
+	// A listener that is closed straight away refuses the connection
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	trace := &attemptTrace{}
+	req, _ := http.NewRequestWithContext(trace.requestContext(context.Background()), http.MethodPost, url, nil)
+	if _, err := (&http.Client{Transport: &http.Transport{}}).Do(req); err == nil {
+		t.Fatal("request to a closed server succeeded")
+	}
+
+	timings := trace.timings()
+	if timings == nil || timings.ConnectMs == nil {
+		t.Fatalf("timings = %+v, want the failed connect timed", timings)
+	}
+	if timings.TTFBMs != nil || timings.BodyReadMs != nil {
+		t.Errorf("timings = %+v, want no phases after the connect", timings)
+	}
+}
+
+func TestAttemptTrace_NotSent(t *testing.T) {
+	if timings := (&attemptTrace{}).timings(); timings != nil {
+		t.Errorf("timings = %+v, want nil for a request never sent", timings)
+	}
+	if timings := attemptTraceFrom(context.Background()).timings(); timings != nil {
+		t.Errorf("timings = %+v, want nil without a trace", timings)
+	}
+}
@@ -0,0 +1,139 @@
+package worker
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// TransportConfig controls connection reuse and routing for outbound
+// deliveries.
+type TransportConfig struct {
+	MaxIdleConnsPerHost int           // idle keep-alive connections kept per endpoint host
+	MaxConnsPerHost     int           // 0 means unlimited
+	IdleConnTimeout     time.Duration // how long an idle connection stays in the pool
+	TLSHandshakeTimeout time.Duration
+	HTTP2               bool          // negotiate HTTP/2 via ALPN for TLS endpoints
+	ProxyURL            *url.URL      // nil uses HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+	LocalAddr           net.IP        // source address for outbound connections; nil lets the OS choose
+	DNSCacheTTL         time.Duration // how long endpoint lookups are reused; 0 resolves every dial
+	BlockPrivateIPs     bool          // refuse endpoints that resolve to internal addresses
+	DialTimeout         time.Duration // how long each address gets to accept a connection; 0 means 5s
+	IPPreference        string        // one of domain.IPPreferences; empty is auto
+	FallbackDelay       time.Duration // head start of the preferred IP family; 0 dials addresses one after another
+}
+
+// ResolveLocalAddr turns DELIVERY_LOCAL_ADDR into the IP to send
+// deliveries from. addr is either an IP address or the name of a network
+// interface, whose first IPv4 address is used (or its first address if it
+// has no IPv4 one).
+func ResolveLocalAddr(addr string) (net.IP, error) {
+	if ip := net.ParseIP(addr); ip != nil {
+		return ip, nil
+	}
+	iface, err := net.InterfaceByName(addr)
+	if err != nil {
+		return nil, fmt.Errorf("%q is neither an IP address nor a network interface", addr)
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, fmt.Errorf("listing addresses of %s: %w", addr, err)
+	}
+	var first net.IP
+	for _, a := range addrs {
+		ipNet, ok := a.(*net.IPNet)
+		if !ok {
+			continue
+		}
+		if ip4 := ipNet.IP.To4(); ip4 != nil {
+			return ip4, nil
+		}
+		if first == nil {
+			first = ipNet.IP
+		}
+	}
+	if first == nil {
+		return nil, fmt.Errorf("network interface %s has no IP address", addr)
+	}
+	return first, nil
+}
+
+type proxyContextKey struct{}
+
+// withProxy routes requests made with the returned context through proxy,
+// whatever the transport's own proxy setting.
+func withProxy(ctx context.Context, proxy *url.URL) context.Context {
+	return context.WithValue(ctx, proxyContextKey{}, proxy)
+}
+
+// deliveryProxy picks the proxy for a request: the one set by withProxy,
+// then the configured one, then the environment. The transport keys its
+// connection pool by proxy, so subscribers with different proxies share it
+// safely.
+func deliveryProxy(proxy *url.URL) func(*http.Request) (*url.URL, error) {
+	return func(req *http.Request) (*url.URL, error) {
+		if p, ok := req.Context().Value(proxyContextKey{}).(*url.URL); ok {
+			return p, nil
+		}
+		if proxy != nil {
+			return proxy, nil
+		}
+		return http.ProxyFromEnvironment(req)
+	}
+}
+
+// newHTTPTransport builds the shared transport used by every worker.
+// The stdlib default keeps only 2 idle connections per host, so with many
+// workers delivering to a handful of endpoints most requests would open a
+// fresh TCP+TLS connection. Sizing the idle pool to the worker count avoids
+// that churn.
+func newHTTPTransport(cfg TransportConfig) *http.Transport {
+	dialTimeout := cfg.DialTimeout
+	if dialTimeout <= 0 {
+		dialTimeout = 5 * time.Second
+	}
+	dialer := &deliveryDialer{
+		dialer: &net.Dialer{
+			Timeout:       dialTimeout,
+			KeepAlive:     30 * time.Second,
+			FallbackDelay: cfg.FallbackDelay,
+		},
+		blockPrivate:  cfg.BlockPrivateIPs,
+		ipPreference:  cfg.IPPreference,
+		fallbackDelay: cfg.FallbackDelay,
+	}
+	if cfg.FallbackDelay <= 0 {
+		// A zero FallbackDelay would mean the net package's default
+		dialer.dialer.FallbackDelay = -1
+	}
+	if cfg.LocalAddr != nil {
+		// Binding the source address picks the NIC on multi-homed hosts
+		dialer.dialer.LocalAddr = &net.TCPAddr{IP: cfg.LocalAddr}
+	}
+	if cfg.DNSCacheTTL > 0 {
+		dialer.dns = newDNSCache(cfg.DNSCacheTTL)
+	}
+
+	t := &http.Transport{
+		Proxy:                 dialer.trackProxies(deliveryProxy(cfg.ProxyURL)),
+		DialContext:           dialer.DialContext,
+		MaxIdleConns:          0, // bounded per host instead
+		MaxIdleConnsPerHost:   cfg.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       cfg.MaxConnsPerHost,
+		IdleConnTimeout:       cfg.IdleConnTimeout,
+		TLSHandshakeTimeout:   cfg.TLSHandshakeTimeout,
+		ExpectContinueTimeout: 1 * time.Second,
+		ForceAttemptHTTP2:     cfg.HTTP2,
+	}
+
+	if !cfg.HTTP2 {
+		// A non-nil empty map disables the automatic HTTP/2 upgrade.
+		t.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+
+	return t
+}
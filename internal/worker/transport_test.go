@@ -0,0 +1,148 @@
+package worker
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNewHTTPTransport_AppliesConfig(t *testing.T) {
+	tr := newHTTPTransport(TransportConfig{
+		MaxIdleConnsPerHost: 64,
+		MaxConnsPerHost:     128,
+		IdleConnTimeout:     45 * time.Second,
+		TLSHandshakeTimeout: 3 * time.Second,
+		HTTP2:               true,
+	})
+
+	if tr.MaxIdleConnsPerHost != 64 {
+		t.Errorf("MaxIdleConnsPerHost = %d, want 64", tr.MaxIdleConnsPerHost)
+	}
+	if tr.MaxConnsPerHost != 128 {
+		t.Errorf("MaxConnsPerHost = %d, want 128", tr.MaxConnsPerHost)
+	}
+	if tr.IdleConnTimeout != 45*time.Second {
+		t.Errorf("IdleConnTimeout = %v, want 45s", tr.IdleConnTimeout)
+	}
+	if tr.TLSHandshakeTimeout != 3*time.Second {
+		t.Errorf("TLSHandshakeTimeout = %v, want 3s", tr.TLSHandshakeTimeout)
+	}
+	if !tr.ForceAttemptHTTP2 || tr.TLSNextProto != nil {
+		t.Error("HTTP/2 should be enabled")
+	}
+}
+
+func TestNewHTTPTransport_DisableHTTP2(t *testing.T) {
+	tr := newHTTPTransport(TransportConfig{HTTP2: false})
+
+	if tr.ForceAttemptHTTP2 {
+		t.Error("ForceAttemptHTTP2 should be false")
+	}
+	if tr.TLSNextProto == nil || len(tr.TLSNextProto) != 0 {
+		t.Error("TLSNextProto should be a non-nil empty map to disable HTTP/2")
+	}
+}
+
+func TestNewHTTPTransport_ReusesConnections(t *testing.T) {
+	var newConns atomic.Int32
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.Config.ConnState = func(c net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			newConns.Add(1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	client := &http.Client{Transport: newHTTPTransport(TransportConfig{
+		MaxIdleConnsPerHost: 4,
+		IdleConnTimeout:     time.Minute,
+	})}
+
+	for i := 0; i < 10; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("request %d failed: %v", i, err)
+		}
+		resp.Body.Close()
+	}
+
+	if n := newConns.Load(); n != 1 {
+		t.Errorf("expected sequential requests to reuse 1 connection, got %d", n)
+	}
+}
+
+// proxyServer counts the requests it is asked to forward and answers them
+// itself.
+func proxyServer(t *testing.T, hits *atomic.Int32) *url.URL {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.IsAbs() {
+			hits.Add(1)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	u, _ := url.Parse(server.URL)
+	return u
+}
+
+func TestNewHTTPTransport_RoutesThroughProxy(t *testing.T) {
+	var global, perSubscriber atomic.Int32
+	globalProxy := proxyServer(t, &global)
+	subscriberProxy := proxyServer(t, &perSubscriber)
+	client := &http.Client{Transport: newHTTPTransport(TransportConfig{ProxyURL: globalProxy})}
+
+	// The endpoint is never reached directly; the proxies answer for it
+	endpoint := "http://partner.invalid/hook"
+	get := func(ctx context.Context) {
+		t.Helper()
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	get(context.Background())
+	get(withProxy(context.Background(), subscriberProxy))
+
+	if global.Load() != 1 || perSubscriber.Load() != 1 {
+		t.Errorf("global proxy got %d requests, subscriber proxy %d; want 1 each", global.Load(), perSubscriber.Load())
+	}
+}
+
+func TestNewHTTPTransport_BindsLocalAddr(t *testing.T) {
+	remote := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remote <- r.RemoteAddr
+	}))
+	defer server.Close()
+
+	local, err := ResolveLocalAddr("127.0.0.1")
+	if err != nil {
+		t.Fatalf("ResolveLocalAddr: %v", err)
+	}
+	client := &http.Client{Transport: newHTTPTransport(TransportConfig{LocalAddr: local})}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if host, _, _ := net.SplitHostPort(<-remote); host != "127.0.0.1" {
+		t.Errorf("request came from %s, want 127.0.0.1", host)
+	}
+	if _, err := ResolveLocalAddr("no-such-nic0"); err == nil {
+		t.Error("expected an unknown interface to be rejected")
+	}
+}
@@ -0,0 +1,98 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/internal/engine"
+)
+
+// verificationBodyLimit is how much of a handshake response is read.
+const verificationBodyLimit = 4096
+
+// verificationRequest is the body of the verification handshake.
+type verificationRequest struct {
+	Type         string `json:"type"`
+	SubscriberID string `json:"subscriber_id"`
+	Challenge    string `json:"challenge"`
+}
+
+// VerifyEndpoint runs the verification handshake against endpointURL on
+// behalf of sub. It POSTs a challenge, signed with sub's secret, and the
+// endpoint passes by answering 2xx with the challenge as its body, either
+// bare or as {"challenge": "..."}. The request goes out like a delivery:
+// through sub's proxy and the delivery transport's address checks.
+func (d *Deliverer) VerifyEndpoint(ctx context.Context, sub *domain.Subscriber, endpointURL string) error {
+	challenge, err := newChallenge()
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(verificationRequest{
+		Type:         domain.VerificationEventType,
+		SubscriberID: sub.ID,
+		Challenge:    challenge,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := newDeliveryRequest(ctx, engine.DeliveryJob{EndpointURL: endpointURL, ProxyURL: sub.ProxyURL}, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	sign(req.Header, sub.SignatureFormat, signedMessage{
+		ID:        challenge,
+		Payload:   body,
+		Secret:    sub.SecretKey,
+		Timestamp: time.Now(),
+	})
+	req.Header.Set("X-Webhook-Event", domain.VerificationEventType)
+	req.Header.Set("X-Webhook-ID", challenge)
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("endpoint responded with status %d", resp.StatusCode)
+	}
+	answer, err := io.ReadAll(io.LimitReader(resp.Body, verificationBodyLimit))
+	if err != nil {
+		return fmt.Errorf("reading response: %w", err)
+	}
+	if !echoesChallenge(answer, challenge) {
+		return fmt.Errorf("endpoint did not echo the challenge")
+	}
+	return nil
+}
+
+// echoesChallenge reports whether a handshake response body is challenge,
+// bare or as the challenge field of a JSON object.
+func echoesChallenge(body []byte, challenge string) bool {
+	body = bytes.TrimSpace(body)
+	if string(body) == challenge {
+		return true
+	}
+	var answer struct {
+		Challenge string `json:"challenge"`
+	}
+	return json.Unmarshal(body, &answer) == nil && answer.Challenge == challenge
+}
+
+func newChallenge() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating challenge: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
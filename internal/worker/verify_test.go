@@ -0,0 +1,63 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Priya8975/webhook-delivery-system/internal/domain"
+	"github.com/Priya8975/webhook-delivery-system/pkg/webhook"
+)
+
+func TestDeliverer_VerifyEndpoint(t *testing.T) {
+	sub := &domain.Subscriber{ID: "sub-1", SecretKey: "secret"}
+	d := &Deliverer{httpClient: &http.Client{Timeout: 5 * time.Second}}
+
+	for name, tc := range map[string]struct {
+		respond func(w http.ResponseWriter, payload []byte, challenge string)
+		wantErr string
+	}{
+		"answered by pkg/webhook": {respond: func(w http.ResponseWriter, payload []byte, challenge string) {
+			webhook.AnswerVerification(w, payload)
+		}},
+		"bare echo": {respond: func(w http.ResponseWriter, payload []byte, challenge string) {
+			fmt.Fprintln(w, challenge)
+		}},
+		"no echo": {respond: func(w http.ResponseWriter, payload []byte, challenge string) {
+			w.Write([]byte("ok"))
+		}, wantErr: "did not echo"},
+		"error status": {respond: func(w http.ResponseWriter, payload []byte, challenge string) {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, challenge)
+		}, wantErr: "status 404"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				payload, err := webhook.VerifyRequest(r, sub.SecretKey)
+				if err != nil {
+					t.Errorf("handshake signature: %v", err)
+				}
+				var req verificationRequest
+				json.Unmarshal(payload, &req)
+				if !webhook.IsVerification(r) || req.SubscriberID != sub.ID || req.Challenge == "" {
+					t.Errorf("handshake = %+v, headers %v", req, r.Header)
+				}
+				tc.respond(w, payload, req.Challenge)
+			}))
+			defer server.Close()
+
+			err := d.VerifyEndpoint(context.Background(), sub, server.URL)
+			if tc.wantErr == "" && err != nil {
+				t.Errorf("VerifyEndpoint: %v", err)
+			}
+			if tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+				t.Errorf("VerifyEndpoint = %v, want an error containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
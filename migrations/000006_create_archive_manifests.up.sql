@@ -0,0 +1,14 @@
+CREATE TABLE archive_manifests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    table_name VARCHAR(50) NOT NULL,
+    bucket VARCHAR(255) NOT NULL,
+    object_key TEXT NOT NULL,
+    row_count INT NOT NULL,
+    byte_size BIGINT NOT NULL,
+    sha256 VARCHAR(64) NOT NULL,
+    oldest_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    newest_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_archive_manifests_table ON archive_manifests(table_name, newest_at);
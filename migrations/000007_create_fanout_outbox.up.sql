@@ -0,0 +1,9 @@
+CREATE TABLE fanout_outbox (
+    event_id UUID PRIMARY KEY REFERENCES events(id) ON DELETE CASCADE,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    available_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_fanout_outbox_available ON fanout_outbox(available_at);
@@ -0,0 +1 @@
+ALTER TABLE subscribers ADD COLUMN compress_payloads BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_dlq_subscriber_created;
+DROP INDEX IF EXISTS idx_delivery_subscriber_created;
@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS idx_delivery_subscriber_created ON delivery_attempts(subscriber_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_dlq_subscriber_created ON dead_letter_queue(subscriber_id, created_at);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_delivery_created;
+DROP TABLE IF EXISTS delivery_metrics_hourly;
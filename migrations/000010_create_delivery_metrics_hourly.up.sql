@@ -0,0 +1,17 @@
+CREATE TABLE delivery_metrics_hourly (
+    hour TIMESTAMP WITH TIME ZONE NOT NULL,
+    subscriber_id UUID NOT NULL,
+    total_attempts INT NOT NULL DEFAULT 0,
+    success_count INT NOT NULL DEFAULT 0,
+    failed_count INT NOT NULL DEFAULT 0,
+    retry_count INT NOT NULL DEFAULT 0,
+    response_time_sum_ms BIGINT NOT NULL DEFAULT 0,
+    response_time_count INT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (hour, subscriber_id)
+);
+
+CREATE INDEX idx_metrics_hourly_subscriber ON delivery_metrics_hourly(subscriber_id, hour);
+
+-- Lets the rollup and live-hour queries range-scan recent attempts
+CREATE INDEX IF NOT EXISTS idx_delivery_created ON delivery_attempts(created_at);
@@ -0,0 +1,16 @@
+CREATE TABLE audit_log (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    actor VARCHAR(255) NOT NULL,
+    actor_key_id UUID,
+    action VARCHAR(64) NOT NULL,
+    entity_type VARCHAR(64) NOT NULL,
+    entity_id VARCHAR(255) NOT NULL,
+    details JSONB,
+    remote_addr VARCHAR(255),
+    request_id VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_audit_created ON audit_log(created_at);
+CREATE INDEX idx_audit_actor ON audit_log(actor, created_at);
+CREATE INDEX idx_audit_entity ON audit_log(entity_type, entity_id, created_at);
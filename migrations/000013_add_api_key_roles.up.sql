@@ -0,0 +1,5 @@
+-- Keys created before roles existed had full access, so they become admins.
+-- New keys default to the least privileged role.
+ALTER TABLE api_keys ADD COLUMN role VARCHAR(16) NOT NULL DEFAULT 'admin';
+ALTER TABLE api_keys ALTER COLUMN role SET DEFAULT 'viewer';
+ALTER TABLE api_keys ADD CONSTRAINT api_keys_role_check CHECK (role IN ('viewer', 'operator', 'admin'));
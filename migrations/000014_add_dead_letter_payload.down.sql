@@ -0,0 +1,7 @@
+ALTER TABLE dead_letter_queue
+    ADD CONSTRAINT dead_letter_queue_event_id_fkey FOREIGN KEY (event_id) REFERENCES events(id) NOT VALID;
+
+ALTER TABLE dead_letter_queue
+    DROP COLUMN IF EXISTS event_source,
+    DROP COLUMN IF EXISTS payload,
+    DROP COLUMN IF EXISTS event_type;
@@ -0,0 +1,13 @@
+-- Dead letters keep a snapshot of their event so they can be inspected and
+-- replayed after the retention archiver prunes the event row.
+ALTER TABLE dead_letter_queue
+    ADD COLUMN event_type VARCHAR(100),
+    ADD COLUMN payload JSONB,
+    ADD COLUMN event_source VARCHAR(100);
+
+UPDATE dead_letter_queue dl
+SET event_type = e.event_type, payload = e.payload, event_source = e.source
+FROM events e
+WHERE e.id = dl.event_id;
+
+ALTER TABLE dead_letter_queue DROP CONSTRAINT IF EXISTS dead_letter_queue_event_id_fkey;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_delivery_response_headers;
+ALTER TABLE delivery_attempts DROP COLUMN IF EXISTS response_headers;
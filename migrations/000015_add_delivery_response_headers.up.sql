@@ -0,0 +1,5 @@
+-- Selected response headers (Retry-After, Content-Type, request IDs) so a
+-- delivery can be matched to the consumer's own logs.
+ALTER TABLE delivery_attempts ADD COLUMN response_headers JSONB;
+
+CREATE INDEX IF NOT EXISTS idx_delivery_response_headers ON delivery_attempts USING GIN (response_headers jsonb_path_ops);
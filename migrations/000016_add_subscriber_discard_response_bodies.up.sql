@@ -0,0 +1 @@
+ALTER TABLE subscribers ADD COLUMN discard_response_bodies BOOLEAN NOT NULL DEFAULT false;
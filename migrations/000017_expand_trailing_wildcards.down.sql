@@ -0,0 +1,7 @@
+UPDATE subscriptions s
+SET event_type = left(s.event_type, -1)
+WHERE s.event_type LIKE '%.**'
+  AND NOT EXISTS (
+    SELECT 1 FROM subscriptions o
+    WHERE o.subscriber_id = s.subscriber_id AND o.event_type = left(s.event_type, -1)
+  );
@@ -0,0 +1,11 @@
+-- "prefix.*" used to match any number of trailing segments. It now matches
+-- exactly one, and "prefix.**" matches one or more, so rewrite existing
+-- subscriptions to keep delivering what they did before.
+UPDATE subscriptions s
+SET event_type = s.event_type || '*'
+WHERE s.event_type LIKE '%.*'
+  AND length(s.event_type) < 100
+  AND NOT EXISTS (
+    SELECT 1 FROM subscriptions o
+    WHERE o.subscriber_id = s.subscriber_id AND o.event_type = s.event_type || '*'
+  );
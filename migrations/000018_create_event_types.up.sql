@@ -0,0 +1,12 @@
+-- Descriptions and example payloads for the event type catalog. Types that
+-- have been published but never documented come from the events table.
+CREATE TABLE event_types (
+    name VARCHAR(100) PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT '',
+    example_payload JSONB,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Serves the catalog's per-type counts and latest payload lookups.
+CREATE INDEX idx_events_type_created ON events(event_type, created_at DESC);
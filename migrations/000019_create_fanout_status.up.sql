@@ -0,0 +1,14 @@
+-- Whether each event's delivery to each matching subscriber made it onto the
+-- queue. Failed rows are retried by the fan-out repair job at next_repair_at.
+CREATE TABLE fanout_status (
+    event_id UUID NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    subscriber_id UUID NOT NULL REFERENCES subscribers(id),
+    status VARCHAR(20) NOT NULL,
+    error TEXT,
+    attempts INT NOT NULL DEFAULT 1,
+    next_repair_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (event_id, subscriber_id)
+);
+
+CREATE INDEX idx_fanout_status_repair ON fanout_status(next_repair_at) WHERE status = 'failed';
@@ -0,0 +1 @@
+ALTER TABLE events ADD COLUMN subscriber_ids UUID[];
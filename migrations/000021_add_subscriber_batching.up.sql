@@ -0,0 +1,2 @@
+ALTER TABLE subscribers ADD COLUMN batch_max_events INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE subscribers ADD COLUMN batch_window_seconds INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1 @@
+ALTER TABLE subscribers ADD COLUMN proxy_url TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+ALTER TABLE dead_letter_queue DROP COLUMN IF EXISTS failure_reason;
+ALTER TABLE delivery_attempts DROP COLUMN IF EXISTS failure_reason;
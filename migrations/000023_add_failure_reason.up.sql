@@ -0,0 +1,2 @@
+ALTER TABLE delivery_attempts ADD COLUMN failure_reason TEXT;
+ALTER TABLE dead_letter_queue ADD COLUMN failure_reason TEXT;
@@ -0,0 +1 @@
+ALTER TABLE subscribers ADD COLUMN debug_logging BOOLEAN NOT NULL DEFAULT false;
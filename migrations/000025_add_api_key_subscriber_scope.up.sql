@@ -0,0 +1 @@
+ALTER TABLE api_keys ADD COLUMN subscriber_id UUID REFERENCES subscribers(id) ON DELETE CASCADE;
@@ -0,0 +1 @@
+ALTER TABLE subscribers ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
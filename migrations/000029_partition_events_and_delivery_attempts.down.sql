@@ -0,0 +1,34 @@
+-- Copies every row back into plain tables.
+CREATE TABLE events_unpartitioned (LIKE events INCLUDING DEFAULTS);
+INSERT INTO events_unpartitioned SELECT * FROM events;
+DROP TABLE events;
+ALTER TABLE events_unpartitioned RENAME TO events;
+ALTER TABLE events ADD PRIMARY KEY (id);
+ALTER TABLE events ALTER COLUMN created_at DROP NOT NULL;
+CREATE INDEX idx_events_type ON events(event_type);
+CREATE INDEX idx_events_created ON events(created_at);
+CREATE INDEX idx_events_type_created ON events(event_type, created_at DESC);
+
+CREATE TABLE delivery_attempts_unpartitioned (LIKE delivery_attempts INCLUDING DEFAULTS);
+INSERT INTO delivery_attempts_unpartitioned SELECT * FROM delivery_attempts;
+DROP TABLE delivery_attempts;
+ALTER TABLE delivery_attempts_unpartitioned RENAME TO delivery_attempts;
+ALTER TABLE delivery_attempts ADD PRIMARY KEY (id);
+ALTER TABLE delivery_attempts ALTER COLUMN created_at DROP NOT NULL;
+ALTER TABLE delivery_attempts ADD CONSTRAINT delivery_attempts_subscriber_id_fkey
+    FOREIGN KEY (subscriber_id) REFERENCES subscribers(id);
+CREATE INDEX idx_delivery_status ON delivery_attempts(status);
+CREATE INDEX idx_delivery_retry ON delivery_attempts(next_retry_at) WHERE status = 'failed';
+CREATE INDEX idx_delivery_event ON delivery_attempts(event_id);
+CREATE INDEX idx_delivery_subscriber ON delivery_attempts(subscriber_id);
+CREATE INDEX idx_delivery_subscriber_created ON delivery_attempts(subscriber_id, created_at);
+CREATE INDEX idx_delivery_created ON delivery_attempts(created_at);
+CREATE INDEX idx_delivery_response_headers ON delivery_attempts USING GIN (response_headers jsonb_path_ops);
+
+-- Rows pruned a partition at a time may have left references behind.
+ALTER TABLE delivery_attempts ADD CONSTRAINT delivery_attempts_event_id_fkey
+    FOREIGN KEY (event_id) REFERENCES events(id) NOT VALID;
+ALTER TABLE fanout_outbox ADD CONSTRAINT fanout_outbox_event_id_fkey
+    FOREIGN KEY (event_id) REFERENCES events(id) ON DELETE CASCADE NOT VALID;
+ALTER TABLE fanout_status ADD CONSTRAINT fanout_status_event_id_fkey
+    FOREIGN KEY (event_id) REFERENCES events(id) ON DELETE CASCADE NOT VALID;
@@ -0,0 +1,74 @@
+-- Partition events and delivery_attempts by month of created_at, so the
+-- archiver can prune a month with DROP TABLE instead of deleting it row by
+-- row, and each month's indexes stay small. The existing tables become the
+-- first partition of each, covering everything before next month, so no rows
+-- are copied. The server creates partitions for later months ahead of time.
+
+-- A foreign key can't reference a partitioned table by id alone. The
+-- archiver removes the fan-out rows of dropped events itself instead.
+ALTER TABLE delivery_attempts DROP CONSTRAINT IF EXISTS delivery_attempts_event_id_fkey;
+ALTER TABLE fanout_outbox DROP CONSTRAINT IF EXISTS fanout_outbox_event_id_fkey;
+ALTER TABLE fanout_status DROP CONSTRAINT IF EXISTS fanout_status_event_id_fkey;
+
+-- The partition key has to be part of the primary key.
+UPDATE events SET created_at = NOW() WHERE created_at IS NULL;
+ALTER TABLE events ALTER COLUMN created_at SET NOT NULL;
+ALTER TABLE events DROP CONSTRAINT events_pkey;
+ALTER TABLE events RENAME TO events_legacy;
+ALTER TABLE events_legacy ADD CONSTRAINT events_legacy_pkey PRIMARY KEY (id, created_at);
+ALTER INDEX idx_events_type RENAME TO events_legacy_event_type_idx;
+ALTER INDEX idx_events_created RENAME TO events_legacy_created_at_idx;
+ALTER INDEX idx_events_type_created RENAME TO events_legacy_event_type_created_at_idx;
+
+CREATE TABLE events (LIKE events_legacy INCLUDING DEFAULTS) PARTITION BY RANGE (created_at);
+ALTER TABLE events ADD PRIMARY KEY (id, created_at);
+CREATE INDEX idx_events_type ON events(event_type);
+CREATE INDEX idx_events_created ON events(created_at);
+CREATE INDEX idx_events_type_created ON events(event_type, created_at DESC);
+
+UPDATE delivery_attempts SET created_at = NOW() WHERE created_at IS NULL;
+ALTER TABLE delivery_attempts ALTER COLUMN created_at SET NOT NULL;
+ALTER TABLE delivery_attempts DROP CONSTRAINT delivery_attempts_pkey;
+ALTER TABLE delivery_attempts RENAME TO delivery_attempts_legacy;
+ALTER TABLE delivery_attempts_legacy ADD CONSTRAINT delivery_attempts_legacy_pkey PRIMARY KEY (id, created_at);
+ALTER TABLE delivery_attempts_legacy RENAME CONSTRAINT delivery_attempts_subscriber_id_fkey TO delivery_attempts_legacy_subscriber_id_fkey;
+ALTER INDEX idx_delivery_status RENAME TO delivery_attempts_legacy_status_idx;
+ALTER INDEX idx_delivery_retry RENAME TO delivery_attempts_legacy_next_retry_at_idx;
+ALTER INDEX idx_delivery_event RENAME TO delivery_attempts_legacy_event_id_idx;
+ALTER INDEX idx_delivery_subscriber RENAME TO delivery_attempts_legacy_subscriber_id_idx;
+ALTER INDEX idx_delivery_subscriber_created RENAME TO delivery_attempts_legacy_subscriber_id_created_at_idx;
+ALTER INDEX idx_delivery_created RENAME TO delivery_attempts_legacy_created_at_idx;
+ALTER INDEX idx_delivery_response_headers RENAME TO delivery_attempts_legacy_response_headers_idx;
+
+CREATE TABLE delivery_attempts (LIKE delivery_attempts_legacy INCLUDING DEFAULTS) PARTITION BY RANGE (created_at);
+ALTER TABLE delivery_attempts ADD PRIMARY KEY (id, created_at);
+ALTER TABLE delivery_attempts ADD CONSTRAINT delivery_attempts_subscriber_id_fkey
+    FOREIGN KEY (subscriber_id) REFERENCES subscribers(id);
+CREATE INDEX idx_delivery_status ON delivery_attempts(status);
+CREATE INDEX idx_delivery_retry ON delivery_attempts(next_retry_at) WHERE status = 'failed';
+CREATE INDEX idx_delivery_event ON delivery_attempts(event_id);
+CREATE INDEX idx_delivery_subscriber ON delivery_attempts(subscriber_id);
+CREATE INDEX idx_delivery_subscriber_created ON delivery_attempts(subscriber_id, created_at);
+CREATE INDEX idx_delivery_created ON delivery_attempts(created_at);
+CREATE INDEX idx_delivery_response_headers ON delivery_attempts USING GIN (response_headers jsonb_path_ops);
+
+-- Attaching reuses the legacy tables' matching indexes and constraints.
+-- Months are counted in UTC, whatever the session's time zone.
+DO $$
+DECLARE
+    next_month TIMESTAMP := date_trunc('month', NOW() AT TIME ZONE 'UTC') + INTERVAL '1 month';
+    month_start TIMESTAMP;
+    tbl TEXT;
+BEGIN
+    FOREACH tbl IN ARRAY ARRAY['events', 'delivery_attempts'] LOOP
+        EXECUTE format('ALTER TABLE %I ATTACH PARTITION %I FOR VALUES FROM (MINVALUE) TO (%L)',
+            tbl, tbl || '_legacy', next_month AT TIME ZONE 'UTC');
+
+        FOR i IN 0..2 LOOP
+            month_start := next_month + i * INTERVAL '1 month';
+            EXECUTE format('CREATE TABLE %I PARTITION OF %I FOR VALUES FROM (%L) TO (%L)',
+                tbl || '_p' || to_char(month_start, 'YYYY_MM'), tbl,
+                month_start AT TIME ZONE 'UTC', (month_start + INTERVAL '1 month') AT TIME ZONE 'UTC');
+        END LOOP;
+    END LOOP;
+END $$;
@@ -0,0 +1,6 @@
+CREATE INDEX IF NOT EXISTS idx_dlq_subscriber ON dead_letter_queue(subscriber_id);
+CREATE INDEX IF NOT EXISTS idx_delivery_subscriber ON delivery_attempts(subscriber_id);
+DROP INDEX IF EXISTS idx_dlq_subscriber_unresolved;
+DROP INDEX IF EXISTS idx_delivery_subscriber_status;
+CREATE INDEX IF NOT EXISTS idx_delivery_event ON delivery_attempts(event_id);
+DROP INDEX IF EXISTS idx_delivery_event_created;
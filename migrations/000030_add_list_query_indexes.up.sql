@@ -0,0 +1,19 @@
+-- Indexes matching the delivery and dead letter listings, which filter by
+-- event or subscriber and page newest first by (created_at, id), so they
+-- stop at the page size instead of sorting every matching row. Indexes on
+-- partitioned tables can't be built concurrently, so writes to
+-- delivery_attempts wait while these are built.
+
+-- An event's attempts, as shown on its detail page
+CREATE INDEX IF NOT EXISTS idx_delivery_event_created ON delivery_attempts(event_id, created_at DESC, id DESC);
+DROP INDEX IF EXISTS idx_delivery_event;
+
+-- A subscriber's attempts with a given status, e.g. its recent failures
+CREATE INDEX IF NOT EXISTS idx_delivery_subscriber_status ON delivery_attempts(subscriber_id, status, created_at DESC, id DESC);
+
+-- A subscriber's open dead letters, listed and resolved in bulk
+CREATE INDEX IF NOT EXISTS idx_dlq_subscriber_unresolved ON dead_letter_queue(subscriber_id, created_at DESC, id DESC) WHERE resolved_at IS NULL;
+
+-- Covered by the (subscriber_id, created_at) indexes
+DROP INDEX IF EXISTS idx_delivery_subscriber;
+DROP INDEX IF EXISTS idx_dlq_subscriber;
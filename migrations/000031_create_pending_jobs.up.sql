@@ -0,0 +1,11 @@
+-- Delivery jobs that could not be written to the queue, e.g. retries
+-- scheduled while Redis was down. They are queued again once it is back.
+CREATE TABLE pending_jobs (
+    id BIGSERIAL PRIMARY KEY,
+    event_id UUID NOT NULL,
+    subscriber_id UUID NOT NULL,
+    job JSONB NOT NULL,
+    ready_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,4 @@
+-- How long each phase of the attempt's request took (DNS, connect, TLS,
+-- time to first byte, body read), to tell a slow consumer from a slow
+-- network.
+ALTER TABLE delivery_attempts ADD COLUMN timings JSONB;
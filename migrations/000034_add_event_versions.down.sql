@@ -0,0 +1,3 @@
+ALTER TABLE subscribers DROP COLUMN IF EXISTS event_versions;
+ALTER TABLE event_types DROP COLUMN IF EXISTS current_version;
+ALTER TABLE events DROP COLUMN IF EXISTS version;
@@ -0,0 +1,6 @@
+-- Versioned event payloads. Events record the version they were published
+-- in, the catalog the current version of each type, and subscribers the
+-- version of each type they are pinned to, as {"order.created": 1}.
+ALTER TABLE events ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE event_types ADD COLUMN current_version INT NOT NULL DEFAULT 1;
+ALTER TABLE subscribers ADD COLUMN event_versions JSONB NOT NULL DEFAULT '{}';
@@ -0,0 +1,3 @@
+-- Sandbox subscribers have their deliveries recorded as simulated attempts
+-- instead of sent.
+ALTER TABLE subscribers ADD COLUMN sandbox BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,3 @@
+-- Lets support find events by a business identifier in their payload, e.g.
+-- payload @> '{"order_id": "abc-123"}'.
+CREATE INDEX IF NOT EXISTS idx_events_payload ON events USING GIN (payload jsonb_path_ops);
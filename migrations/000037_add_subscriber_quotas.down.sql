@@ -0,0 +1 @@
+ALTER TABLE subscribers DROP COLUMN IF EXISTS monthly_quota, DROP COLUMN IF EXISTS daily_quota;
@@ -0,0 +1,5 @@
+-- Caps on a subscriber's deliveries per UTC day and calendar month, 0 for
+-- none. Deliveries over a quota wait in the queue for the window to reset.
+ALTER TABLE subscribers
+    ADD COLUMN daily_quota INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN monthly_quota INTEGER NOT NULL DEFAULT 0;
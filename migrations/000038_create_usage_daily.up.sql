@@ -0,0 +1,14 @@
+-- Usage metered per subscriber per UTC day, for billing by consumption.
+-- Rows are incremented in the transaction that records the attempts and
+-- outlive the attempts themselves, so pruning old attempts keeps the usage.
+CREATE TABLE usage_daily (
+    day DATE NOT NULL,
+    subscriber_id UUID NOT NULL,
+    deliveries BIGINT NOT NULL DEFAULT 0,
+    successful BIGINT NOT NULL DEFAULT 0,
+    retries BIGINT NOT NULL DEFAULT 0,
+    payload_bytes BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (day, subscriber_id)
+);
+
+CREATE INDEX idx_usage_daily_subscriber ON usage_daily(subscriber_id, day);
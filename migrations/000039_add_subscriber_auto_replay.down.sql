@@ -0,0 +1 @@
+ALTER TABLE subscribers DROP COLUMN IF EXISTS auto_replay_dead_letters;
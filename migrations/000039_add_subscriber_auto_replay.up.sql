@@ -0,0 +1,3 @@
+-- Replays a subscriber's unresolved dead letters once its circuit breaker
+-- has closed after an outage and stayed closed for the healthy period.
+ALTER TABLE subscribers ADD COLUMN auto_replay_dead_letters BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,3 @@
+-- Picks the retry jitter strategy per subscriber; empty uses the server's
+-- RETRY_JITTER_STRATEGY.
+ALTER TABLE subscribers ADD COLUMN retry_jitter TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+ALTER TABLE subscribers DROP COLUMN IF EXISTS retry_for_duration;
+ALTER TABLE subscribers DROP COLUMN IF EXISTS max_retry_duration_seconds;
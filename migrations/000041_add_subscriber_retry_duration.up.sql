@@ -0,0 +1,5 @@
+-- Caps how long a delivery to the subscriber is retried, counted from its
+-- first failed attempt; 0 leaves only the attempt count. With
+-- retry_for_duration, deliveries keep retrying for the whole duration.
+ALTER TABLE subscribers ADD COLUMN max_retry_duration_seconds INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE subscribers ADD COLUMN retry_for_duration BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,2 @@
+ALTER TABLE subscribers DROP COLUMN IF EXISTS success_json_path;
+ALTER TABLE subscribers DROP COLUMN IF EXISTS success_body_contains;
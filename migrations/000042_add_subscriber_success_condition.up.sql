@@ -0,0 +1,5 @@
+-- What a 2xx response body must hold for a delivery to the subscriber to
+-- count as successful: a substring and a JSONPath condition, each empty
+-- when unused.
+ALTER TABLE subscribers ADD COLUMN success_body_contains TEXT NOT NULL DEFAULT '';
+ALTER TABLE subscribers ADD COLUMN success_json_path TEXT NOT NULL DEFAULT '';
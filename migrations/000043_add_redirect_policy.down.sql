@@ -0,0 +1,2 @@
+ALTER TABLE delivery_attempts DROP COLUMN IF EXISTS final_url;
+ALTER TABLE subscribers DROP COLUMN IF EXISTS redirect_policy;
@@ -0,0 +1,6 @@
+-- Which redirects deliveries to the subscriber follow; empty uses the
+-- server's DELIVERY_REDIRECT_POLICY.
+ALTER TABLE subscribers ADD COLUMN redirect_policy TEXT NOT NULL DEFAULT '';
+
+-- Where an attempt's response came from, when it followed redirects.
+ALTER TABLE delivery_attempts ADD COLUMN final_url TEXT;
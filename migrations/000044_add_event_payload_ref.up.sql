@@ -0,0 +1,4 @@
+-- Payloads over PAYLOAD_OFFLOAD_THRESHOLD_BYTES are kept in object storage
+-- under payload_ref, with payload left null.
+ALTER TABLE events ADD COLUMN payload_ref TEXT NOT NULL DEFAULT '';
+ALTER TABLE events ADD COLUMN payload_size BIGINT NOT NULL DEFAULT 0;
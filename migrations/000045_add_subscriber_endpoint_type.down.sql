@@ -0,0 +1,2 @@
+ALTER TABLE subscribers DROP COLUMN IF EXISTS endpoint_credentials;
+ALTER TABLE subscribers DROP COLUMN IF EXISTS endpoint_type;
@@ -0,0 +1,5 @@
+-- What endpoint_url points at: an HTTP receiver, or a queue (sqs, pubsub
+-- or amqp) deliveries are pushed into with the endpoint credentials, a
+-- JSON object or empty.
+ALTER TABLE subscribers ADD COLUMN endpoint_type TEXT NOT NULL DEFAULT 'http';
+ALTER TABLE subscribers ADD COLUMN endpoint_credentials TEXT NOT NULL DEFAULT '';
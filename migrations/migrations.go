@@ -0,0 +1,11 @@
+// Package migrations embeds the SQL schema migrations, so the server and
+// webhookctl can apply them without the files being shipped alongside.
+package migrations
+
+import "embed"
+
+// FS holds the PostgreSQL migrations at its root and the SQLite ones in
+// sqlite/.
+//
+//go:embed *.sql sqlite/*.sql
+var FS embed.FS
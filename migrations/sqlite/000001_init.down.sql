@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS audit_log;
+DROP TABLE IF EXISTS api_keys;
+DROP TABLE IF EXISTS fanout_outbox;
+DROP TABLE IF EXISTS dead_letter_queue;
+DROP TABLE IF EXISTS delivery_attempts;
+DROP TABLE IF EXISTS subscriptions;
+DROP TABLE IF EXISTS events;
+DROP TABLE IF EXISTS subscribers;
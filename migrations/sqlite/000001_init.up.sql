@@ -0,0 +1,115 @@
+-- SQLite schema for single-node deployments. It matches the Postgres schema
+-- after migration 000016, minus the hourly metrics rollup and archive
+-- manifests, which only the Postgres backend uses. Timestamps are written by
+-- the application in UTC so they sort and compare as text.
+
+CREATE TABLE subscribers (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    endpoint_url TEXT NOT NULL,
+    secret_key TEXT NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT 1,
+    rate_limit_per_second INTEGER NOT NULL DEFAULT 10,
+    compress_payloads BOOLEAN NOT NULL DEFAULT 0,
+    discard_response_bodies BOOLEAN NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+
+CREATE TABLE events (
+    id TEXT PRIMARY KEY,
+    event_type TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    source TEXT,
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX idx_events_type ON events(event_type);
+CREATE INDEX idx_events_created ON events(created_at);
+
+CREATE TABLE subscriptions (
+    id TEXT PRIMARY KEY,
+    subscriber_id TEXT NOT NULL REFERENCES subscribers(id) ON DELETE CASCADE,
+    event_type TEXT NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT 1,
+    created_at DATETIME NOT NULL,
+    UNIQUE(subscriber_id, event_type)
+);
+
+CREATE INDEX idx_subscriptions_type ON subscriptions(event_type);
+
+CREATE TABLE delivery_attempts (
+    id TEXT PRIMARY KEY,
+    event_id TEXT NOT NULL REFERENCES events(id),
+    subscriber_id TEXT NOT NULL REFERENCES subscribers(id),
+    attempt_number INTEGER NOT NULL DEFAULT 1,
+    status TEXT NOT NULL DEFAULT 'pending',
+    http_status_code INTEGER,
+    response_body TEXT,
+    response_headers TEXT,
+    response_time_ms INTEGER,
+    error_message TEXT,
+    next_retry_at DATETIME,
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX idx_delivery_event ON delivery_attempts(event_id);
+CREATE INDEX idx_delivery_subscriber_created ON delivery_attempts(subscriber_id, created_at);
+CREATE INDEX idx_delivery_created ON delivery_attempts(created_at);
+
+-- No foreign key on event_id: dead letters keep a snapshot of their event
+CREATE TABLE dead_letter_queue (
+    id TEXT PRIMARY KEY,
+    event_id TEXT NOT NULL,
+    subscriber_id TEXT NOT NULL REFERENCES subscribers(id),
+    event_type TEXT,
+    payload TEXT,
+    event_source TEXT,
+    total_attempts INTEGER NOT NULL,
+    last_error TEXT,
+    last_http_status INTEGER,
+    created_at DATETIME NOT NULL,
+    resolved_at DATETIME,
+    resolved_by TEXT
+);
+
+CREATE INDEX idx_dlq_unresolved ON dead_letter_queue(created_at) WHERE resolved_at IS NULL;
+CREATE INDEX idx_dlq_subscriber_created ON dead_letter_queue(subscriber_id, created_at);
+
+CREATE TABLE fanout_outbox (
+    event_id TEXT PRIMARY KEY REFERENCES events(id) ON DELETE CASCADE,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    available_at DATETIME NOT NULL,
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX idx_fanout_outbox_available ON fanout_outbox(available_at);
+
+CREATE TABLE api_keys (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    key_prefix TEXT NOT NULL,
+    key_hash TEXT NOT NULL UNIQUE,
+    role TEXT NOT NULL DEFAULT 'viewer' CHECK (role IN ('viewer', 'operator', 'admin')),
+    created_at DATETIME NOT NULL,
+    last_used_at DATETIME,
+    revoked_at DATETIME
+);
+
+CREATE TABLE audit_log (
+    id TEXT PRIMARY KEY,
+    actor TEXT NOT NULL,
+    actor_key_id TEXT,
+    action TEXT NOT NULL,
+    entity_type TEXT NOT NULL,
+    entity_id TEXT NOT NULL,
+    details TEXT,
+    remote_addr TEXT,
+    request_id TEXT,
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX idx_audit_created ON audit_log(created_at);
+CREATE INDEX idx_audit_actor ON audit_log(actor, created_at);
+CREATE INDEX idx_audit_entity ON audit_log(entity_type, entity_id, created_at);
@@ -0,0 +1,8 @@
+UPDATE subscriptions
+SET event_type = substr(event_type, 1, length(event_type) - 1)
+WHERE event_type LIKE '%.**'
+  AND NOT EXISTS (
+    SELECT 1 FROM subscriptions o
+    WHERE o.subscriber_id = subscriptions.subscriber_id
+      AND o.event_type = substr(subscriptions.event_type, 1, length(subscriptions.event_type) - 1)
+  );
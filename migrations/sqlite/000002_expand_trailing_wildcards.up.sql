@@ -0,0 +1,11 @@
+-- "prefix.*" used to match any number of trailing segments. It now matches
+-- exactly one, and "prefix.**" matches one or more, so rewrite existing
+-- subscriptions to keep delivering what they did before.
+UPDATE subscriptions
+SET event_type = event_type || '*'
+WHERE event_type LIKE '%.*'
+  AND length(event_type) < 100
+  AND NOT EXISTS (
+    SELECT 1 FROM subscriptions o
+    WHERE o.subscriber_id = subscriptions.subscriber_id AND o.event_type = subscriptions.event_type || '*'
+  );
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_events_type_created;
+DROP TABLE IF EXISTS event_types;
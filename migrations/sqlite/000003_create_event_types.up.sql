@@ -0,0 +1,9 @@
+CREATE TABLE event_types (
+    name TEXT PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT '',
+    example_payload TEXT,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+
+CREATE INDEX idx_events_type_created ON events(event_type, created_at DESC);
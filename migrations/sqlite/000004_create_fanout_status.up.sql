@@ -0,0 +1,12 @@
+CREATE TABLE fanout_status (
+    event_id TEXT NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    subscriber_id TEXT NOT NULL REFERENCES subscribers(id),
+    status TEXT NOT NULL,
+    error TEXT,
+    attempts INTEGER NOT NULL DEFAULT 1,
+    next_repair_at DATETIME,
+    updated_at DATETIME NOT NULL,
+    PRIMARY KEY (event_id, subscriber_id)
+);
+
+CREATE INDEX idx_fanout_status_repair ON fanout_status(next_repair_at) WHERE status = 'failed';
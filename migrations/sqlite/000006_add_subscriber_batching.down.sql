@@ -0,0 +1,2 @@
+ALTER TABLE subscribers DROP COLUMN batch_window_seconds;
+ALTER TABLE subscribers DROP COLUMN batch_max_events;
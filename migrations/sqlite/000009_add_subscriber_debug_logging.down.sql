@@ -0,0 +1 @@
+ALTER TABLE subscribers DROP COLUMN debug_logging;
@@ -0,0 +1,4 @@
+-- No REFERENCES clause: SQLite cannot drop a column used in a foreign key,
+-- which would leave the down migration needing a table rebuild. The API
+-- checks the subscriber exists when a scoped key is created.
+ALTER TABLE api_keys ADD COLUMN subscriber_id TEXT;
@@ -0,0 +1 @@
+ALTER TABLE subscribers ADD COLUMN is_system BOOLEAN NOT NULL DEFAULT 0;
@@ -0,0 +1 @@
+ALTER TABLE subscribers DROP COLUMN deleted_at;
@@ -0,0 +1 @@
+ALTER TABLE subscribers ADD COLUMN deleted_at DATETIME;
@@ -0,0 +1,4 @@
+DROP INDEX idx_dlq_subscriber_unresolved;
+DROP INDEX idx_delivery_subscriber_status;
+CREATE INDEX idx_delivery_event ON delivery_attempts(event_id);
+DROP INDEX idx_delivery_event_created;
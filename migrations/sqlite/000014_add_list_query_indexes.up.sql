@@ -0,0 +1,6 @@
+-- Indexes matching the delivery and dead letter listings, as in the
+-- PostgreSQL schema
+CREATE INDEX idx_delivery_event_created ON delivery_attempts(event_id, created_at DESC, id DESC);
+DROP INDEX idx_delivery_event;
+CREATE INDEX idx_delivery_subscriber_status ON delivery_attempts(subscriber_id, status, created_at DESC, id DESC);
+CREATE INDEX idx_dlq_subscriber_unresolved ON dead_letter_queue(subscriber_id, created_at DESC, id DESC) WHERE resolved_at IS NULL;
@@ -0,0 +1,9 @@
+CREATE TABLE pending_jobs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    event_id TEXT NOT NULL,
+    subscriber_id TEXT NOT NULL,
+    job TEXT NOT NULL,
+    ready_at DATETIME NOT NULL,
+    last_error TEXT,
+    created_at DATETIME NOT NULL
+);
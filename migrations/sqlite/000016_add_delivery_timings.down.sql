@@ -0,0 +1 @@
+ALTER TABLE delivery_attempts DROP COLUMN timings;
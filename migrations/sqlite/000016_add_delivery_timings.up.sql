@@ -0,0 +1 @@
+ALTER TABLE delivery_attempts ADD COLUMN timings TEXT;
@@ -0,0 +1 @@
+ALTER TABLE subscribers DROP COLUMN signature_format;
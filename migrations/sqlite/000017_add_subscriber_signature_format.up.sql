@@ -0,0 +1 @@
+ALTER TABLE subscribers ADD COLUMN signature_format TEXT NOT NULL DEFAULT 'standard';
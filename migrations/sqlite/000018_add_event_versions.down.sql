@@ -0,0 +1,3 @@
+ALTER TABLE subscribers DROP COLUMN event_versions;
+ALTER TABLE event_types DROP COLUMN current_version;
+ALTER TABLE events DROP COLUMN version;
@@ -0,0 +1,3 @@
+ALTER TABLE events ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE event_types ADD COLUMN current_version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE subscribers ADD COLUMN event_versions TEXT NOT NULL DEFAULT '{}';
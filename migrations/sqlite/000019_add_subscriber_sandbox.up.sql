@@ -0,0 +1 @@
+ALTER TABLE subscribers ADD COLUMN sandbox BOOLEAN NOT NULL DEFAULT 0;
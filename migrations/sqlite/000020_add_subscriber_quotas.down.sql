@@ -0,0 +1,2 @@
+ALTER TABLE subscribers DROP COLUMN monthly_quota;
+ALTER TABLE subscribers DROP COLUMN daily_quota;
@@ -0,0 +1,2 @@
+ALTER TABLE subscribers ADD COLUMN daily_quota INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE subscribers ADD COLUMN monthly_quota INTEGER NOT NULL DEFAULT 0;
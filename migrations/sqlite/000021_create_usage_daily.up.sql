@@ -0,0 +1,12 @@
+-- Usage metered per subscriber per UTC day. day is written as YYYY-MM-DD.
+CREATE TABLE usage_daily (
+    day TEXT NOT NULL,
+    subscriber_id TEXT NOT NULL,
+    deliveries INTEGER NOT NULL DEFAULT 0,
+    successful INTEGER NOT NULL DEFAULT 0,
+    retries INTEGER NOT NULL DEFAULT 0,
+    payload_bytes INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (day, subscriber_id)
+);
+
+CREATE INDEX idx_usage_daily_subscriber ON usage_daily(subscriber_id, day);
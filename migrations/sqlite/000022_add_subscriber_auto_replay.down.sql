@@ -0,0 +1 @@
+ALTER TABLE subscribers DROP COLUMN auto_replay_dead_letters;
@@ -0,0 +1 @@
+ALTER TABLE subscribers ADD COLUMN auto_replay_dead_letters BOOLEAN NOT NULL DEFAULT 0;
@@ -0,0 +1 @@
+ALTER TABLE subscribers ADD COLUMN retry_jitter TEXT NOT NULL DEFAULT '';
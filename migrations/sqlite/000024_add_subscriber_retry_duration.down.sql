@@ -0,0 +1,2 @@
+ALTER TABLE subscribers DROP COLUMN retry_for_duration;
+ALTER TABLE subscribers DROP COLUMN max_retry_duration_seconds;
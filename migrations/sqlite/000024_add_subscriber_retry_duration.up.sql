@@ -0,0 +1,2 @@
+ALTER TABLE subscribers ADD COLUMN max_retry_duration_seconds INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE subscribers ADD COLUMN retry_for_duration BOOLEAN NOT NULL DEFAULT 0;
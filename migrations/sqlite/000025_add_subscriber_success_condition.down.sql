@@ -0,0 +1,2 @@
+ALTER TABLE subscribers DROP COLUMN success_json_path;
+ALTER TABLE subscribers DROP COLUMN success_body_contains;
@@ -0,0 +1,2 @@
+ALTER TABLE subscribers ADD COLUMN success_body_contains TEXT NOT NULL DEFAULT '';
+ALTER TABLE subscribers ADD COLUMN success_json_path TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+ALTER TABLE delivery_attempts DROP COLUMN final_url;
+ALTER TABLE subscribers DROP COLUMN redirect_policy;
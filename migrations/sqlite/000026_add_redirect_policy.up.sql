@@ -0,0 +1,2 @@
+ALTER TABLE subscribers ADD COLUMN redirect_policy TEXT NOT NULL DEFAULT '';
+ALTER TABLE delivery_attempts ADD COLUMN final_url TEXT;
@@ -0,0 +1,2 @@
+ALTER TABLE events DROP COLUMN payload_size;
+ALTER TABLE events DROP COLUMN payload_ref;
@@ -0,0 +1,2 @@
+ALTER TABLE events ADD COLUMN payload_ref TEXT NOT NULL DEFAULT '';
+ALTER TABLE events ADD COLUMN payload_size INTEGER NOT NULL DEFAULT 0;
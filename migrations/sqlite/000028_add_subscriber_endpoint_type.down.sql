@@ -0,0 +1,2 @@
+ALTER TABLE subscribers DROP COLUMN endpoint_credentials;
+ALTER TABLE subscribers DROP COLUMN endpoint_type;
@@ -0,0 +1,2 @@
+ALTER TABLE subscribers ADD COLUMN endpoint_type TEXT NOT NULL DEFAULT 'http';
+ALTER TABLE subscribers ADD COLUMN endpoint_credentials TEXT NOT NULL DEFAULT '';
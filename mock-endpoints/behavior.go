@@ -0,0 +1,225 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Behavior describes how the mock server answers requests to one path.
+type Behavior struct {
+	// Status is the response code for requests that don't fail or get
+	// throttled. Defaults to 200.
+	Status int `json:"status,omitempty"`
+	// FailureRate is the fraction of requests, 0 to 1, answered with
+	// FailureStatus instead.
+	FailureRate   float64 `json:"failure_rate,omitempty"`
+	FailureStatus int     `json:"failure_status,omitempty"`
+	// Body is sent as the response body. Defaults to a small JSON object.
+	Body      string     `json:"body,omitempty"`
+	Latency   *Latency   `json:"latency,omitempty"`
+	RateLimit *RateLimit `json:"rate_limit,omitempty"`
+}
+
+// Latency is the delay before a response is written.
+type Latency struct {
+	// Distribution is "fixed" (the default), "uniform" or "normal".
+	Distribution string `json:"distribution,omitempty"`
+	// Ms is the fixed delay, or the mean of a normal distribution.
+	Ms int `json:"ms,omitempty"`
+	// MinMs and MaxMs bound a uniform distribution.
+	MinMs int `json:"min_ms,omitempty"`
+	MaxMs int `json:"max_ms,omitempty"`
+	// StddevMs is the spread of a normal distribution.
+	StddevMs int `json:"stddev_ms,omitempty"`
+}
+
+// RateLimit answers 429 with a Retry-After header once more than PerSecond
+// requests arrive within the same second.
+type RateLimit struct {
+	PerSecond         int `json:"per_second"`
+	RetryAfterSeconds int `json:"retry_after_seconds,omitempty"`
+}
+
+// presets are the built-in endpoints. POST /configure can override them, and
+// DELETE /configure puts them back.
+var presets = map[string]Behavior{
+	"/webhook/success": {},
+	"/webhook/slow":    {Latency: &Latency{Ms: 3000}},
+	"/webhook/fail":    {Status: 500},
+	"/webhook/flaky":   {FailureRate: 0.7},
+}
+
+func (b *Behavior) validate() error {
+	if b.Status != 0 && (b.Status < 100 || b.Status > 599) {
+		return fmt.Errorf("status must be an HTTP status code")
+	}
+	if b.FailureStatus != 0 && (b.FailureStatus < 100 || b.FailureStatus > 599) {
+		return fmt.Errorf("failure_status must be an HTTP status code")
+	}
+	if b.FailureRate < 0 || b.FailureRate > 1 {
+		return fmt.Errorf("failure_rate must be between 0 and 1")
+	}
+	if l := b.Latency; l != nil {
+		switch l.Distribution {
+		case "", "fixed", "normal":
+		case "uniform":
+			if l.MaxMs < l.MinMs {
+				return fmt.Errorf("latency.max_ms must not be less than latency.min_ms")
+			}
+		default:
+			return fmt.Errorf("latency.distribution must be fixed, uniform or normal")
+		}
+		if l.Ms < 0 || l.MinMs < 0 || l.StddevMs < 0 {
+			return fmt.Errorf("latency values must not be negative")
+		}
+	}
+	if rl := b.RateLimit; rl != nil && rl.PerSecond < 0 {
+		return fmt.Errorf("rate_limit.per_second must not be negative")
+	}
+	return nil
+}
+
+// delay draws a response delay from the distribution.
+func (l *Latency) delay() time.Duration {
+	if l == nil {
+		return 0
+	}
+	var ms float64
+	switch l.Distribution {
+	case "uniform":
+		ms = float64(l.MinMs) + rand.Float64()*float64(l.MaxMs-l.MinMs)
+	case "normal":
+		ms = math.Max(0, float64(l.Ms)+rand.NormFloat64()*float64(l.StddevMs))
+	default:
+		ms = float64(l.Ms)
+	}
+	return time.Duration(ms * float64(time.Millisecond))
+}
+
+// outcome is what a route decided to do with one request.
+type outcome struct {
+	status     int
+	body       string
+	delay      time.Duration
+	retryAfter int // seconds; set only for throttled requests
+}
+
+// route is a path's behavior plus the state its rate limit needs.
+type route struct {
+	behavior Behavior
+
+	mu          sync.Mutex
+	windowStart time.Time
+	windowCount int
+}
+
+func (rt *route) decide(now time.Time) outcome {
+	b := rt.behavior
+	if rl := b.RateLimit; rl != nil && rt.throttled(now, rl.PerSecond) {
+		retryAfter := rl.RetryAfterSeconds
+		if retryAfter == 0 {
+			retryAfter = 1
+		}
+		return outcome{status: 429, body: `{"error":"rate limited"}`, retryAfter: retryAfter}
+	}
+
+	out := outcome{status: b.Status, body: b.Body, delay: b.Latency.delay()}
+	if out.status == 0 {
+		out.status = 200
+	}
+	if b.FailureRate > 0 && rand.Float64() < b.FailureRate {
+		out.status = b.FailureStatus
+		if out.status == 0 {
+			out.status = 500
+		}
+	}
+	if out.body == "" {
+		if out.status < 300 {
+			out.body = `{"status":"received"}`
+		} else {
+			out.body = `{"error":"simulated failure"}`
+		}
+	}
+	return out
+}
+
+// throttled counts the request against the current one-second window and
+// reports whether it exceeds the limit.
+func (rt *route) throttled(now time.Time, perSecond int) bool {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	if now.Sub(rt.windowStart) >= time.Second {
+		rt.windowStart = now
+		rt.windowCount = 0
+	}
+	rt.windowCount++
+	return rt.windowCount > perSecond
+}
+
+// routes holds the behavior configured for each path.
+type routes struct {
+	mu     sync.RWMutex
+	byPath map[string]*route
+}
+
+func newRoutes() *routes {
+	r := &routes{}
+	r.reset("")
+	return r
+}
+
+func (r *routes) get(path string) (*route, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rt, ok := r.byPath[path]
+	return rt, ok
+}
+
+func (r *routes) set(path string, b Behavior) {
+	r.mu.Lock()
+	r.byPath[path] = &route{behavior: b}
+	r.mu.Unlock()
+}
+
+// reset restores a path to its preset, or removes it if it has none. An
+// empty path resets every route.
+func (r *routes) reset(path string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if path == "" {
+		r.byPath = make(map[string]*route, len(presets))
+		for p, b := range presets {
+			r.byPath[p] = &route{behavior: b}
+		}
+		return
+	}
+	if b, ok := presets[path]; ok {
+		r.byPath[path] = &route{behavior: b}
+		return
+	}
+	delete(r.byPath, path)
+}
+
+func (r *routes) all() map[string]Behavior {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make(map[string]Behavior, len(r.byPath))
+	for p, rt := range r.byPath {
+		out[p] = rt.behavior
+	}
+	return out
+}
+
+// normalizePath makes configured paths match request paths.
+func normalizePath(p string) string {
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	return p
+}
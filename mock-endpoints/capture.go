@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// maxCapturedBody caps how much of each request body the capture log keeps.
+const maxCapturedBody = 64 << 10
+
+// CapturedRequest is one request received on a webhook path, with the
+// response the mock server chose for it.
+type CapturedRequest struct {
+	Seq        int64             `json:"seq"`
+	ReceivedAt time.Time         `json:"received_at"`
+	Method     string            `json:"method"`
+	Path       string            `json:"path"`
+	Headers    map[string]string `json:"headers"`
+	Body       json.RawMessage   `json:"body,omitempty"`
+	// RawBody holds bodies that aren't valid JSON.
+	RawBody   string `json:"raw_body,omitempty"`
+	Status    int    `json:"status"`
+	LatencyMs int64  `json:"latency_ms"`
+}
+
+// captureLog keeps the most recent requests in a ring buffer.
+type captureLog struct {
+	mu      sync.Mutex
+	entries []CapturedRequest
+	next    int // index the next entry is written to once the buffer is full
+	seq     int64
+	limit   int
+}
+
+func newCaptureLog(limit int) *captureLog {
+	return &captureLog{limit: limit}
+}
+
+func (c *captureLog) add(r *http.Request, body []byte, status int, latency time.Duration) int64 {
+	headers := make(map[string]string, len(r.Header))
+	for k := range r.Header {
+		headers[k] = r.Header.Get(k)
+	}
+	entry := CapturedRequest{
+		ReceivedAt: time.Now().UTC(),
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		Headers:    headers,
+		Status:     status,
+		LatencyMs:  latency.Milliseconds(),
+	}
+	if len(body) > maxCapturedBody {
+		body = body[:maxCapturedBody]
+	}
+	if json.Valid(body) {
+		entry.Body = json.RawMessage(body)
+	} else {
+		entry.RawBody = string(body)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seq++
+	entry.Seq = c.seq
+	if len(c.entries) < c.limit {
+		c.entries = append(c.entries, entry)
+	} else {
+		c.entries[c.next] = entry
+		c.next = (c.next + 1) % c.limit
+	}
+	return entry.Seq
+}
+
+// list returns captured requests after sinceSeq, oldest first, optionally
+// only those for path. limit keeps the newest matches.
+func (c *captureLog) list(path string, sinceSeq int64, limit int) []CapturedRequest {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	out := make([]CapturedRequest, 0)
+	for i := range c.entries {
+		e := c.entries[(c.next+i)%len(c.entries)]
+		if e.Seq <= sinceSeq || (path != "" && e.Path != path) {
+			continue
+		}
+		out = append(out, e)
+	}
+	if limit > 0 && len(out) > limit {
+		out = out[len(out)-limit:]
+	}
+	return out
+}
+
+func (c *captureLog) clear() {
+	c.mu.Lock()
+	c.entries = nil
+	c.next = 0
+	c.mu.Unlock()
+}
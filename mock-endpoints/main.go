@@ -3,87 +3,196 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
-	"math/rand/v2"
 	"net/http"
 	"os"
+	"strconv"
+	"sync"
 	"sync/atomic"
 	"time"
 )
 
-var requestCount atomic.Int64
+// server is the mock consumer: webhook paths answer according to their
+// configured Behavior, and every request to them is captured.
+type server struct {
+	routes   *routes
+	captured *captureLog
 
-func main() {
-	port := "9090"
-	if p := os.Getenv("PORT"); p != "" {
-		port = p
+	requestCount atomic.Int64
+	mu           sync.Mutex
+	pathCounts   map[string]int64
+}
+
+func newServer(captureLimit int) *server {
+	return &server{
+		routes:     newRoutes(),
+		captured:   newCaptureLog(captureLimit),
+		pathCounts: make(map[string]int64),
 	}
+}
 
-	// Successful endpoint — always returns 200
-	http.HandleFunc("/webhook/success", func(w http.ResponseWriter, r *http.Request) {
-		count := requestCount.Add(1)
-		logRequest(r, count, 200)
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /configure", s.listConfig)
+	mux.HandleFunc("POST /configure", s.configure)
+	mux.HandleFunc("DELETE /configure", s.resetConfig)
+	mux.HandleFunc("GET /requests", s.listRequests)
+	mux.HandleFunc("DELETE /requests", s.clearRequests)
+	mux.HandleFunc("GET /stats", s.stats)
+	mux.HandleFunc("/", s.webhook)
+	return mux
+}
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(map[string]string{"status": "received"})
-	})
+// webhook answers a request on a configured path.
+func (s *server) webhook(w http.ResponseWriter, r *http.Request) {
+	rt, ok := s.routes.get(r.URL.Path)
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "no behavior configured for " + r.URL.Path})
+		return
+	}
+
+	start := time.Now()
+	body, _ := io.ReadAll(r.Body)
+	out := rt.decide(start)
+	if out.delay > 0 {
+		select {
+		case <-time.After(out.delay):
+		case <-r.Context().Done():
+		}
+	}
 
-	// Slow endpoint — delays 3 seconds before responding
-	http.HandleFunc("/webhook/slow", func(w http.ResponseWriter, r *http.Request) {
-		count := requestCount.Add(1)
-		time.Sleep(3 * time.Second)
-		logRequest(r, count, 200)
+	count := s.requestCount.Add(1)
+	s.mu.Lock()
+	s.pathCounts[r.URL.Path]++
+	s.mu.Unlock()
+	s.captured.add(r, body, out.status, time.Since(start))
+	logRequest(r, count, out.status)
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(map[string]string{"status": "received (slow)"})
-	})
+	if out.retryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(out.retryAfter))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(out.status)
+	io.WriteString(w, out.body)
+}
+
+type configureRequest struct {
+	Path string `json:"path"`
+	Behavior
+}
+
+// configure sets the behavior of a path, replacing any earlier one.
+func (s *server) configure(w http.ResponseWriter, r *http.Request) {
+	var req configureRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON: " + err.Error()})
+		return
+	}
+	if req.Path == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "path is required"})
+		return
+	}
+	path := normalizePath(req.Path)
+	if reservedPaths[path] {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": path + " is reserved"})
+		return
+	}
+	if err := req.Behavior.validate(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	s.routes.set(path, req.Behavior)
+	log.Printf("configured %s", path)
+	writeJSON(w, http.StatusOK, map[string]interface{}{"path": path, "behavior": req.Behavior})
+}
+
+var reservedPaths = map[string]bool{"/configure": true, "/requests": true, "/stats": true}
+
+func (s *server) listConfig(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.routes.all())
+}
+
+// resetConfig restores ?path= to its preset, or every path when none is
+// given.
+func (s *server) resetConfig(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Query().Get("path")
+	if path != "" {
+		path = normalizePath(path)
+	}
+	s.routes.reset(path)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listRequests returns captured requests, oldest first. ?path= filters by
+// path, ?since= skips entries up to that seq so callers can poll for new
+// ones, and ?limit= keeps the newest.
+func (s *server) listRequests(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	since, _ := strconv.ParseInt(q.Get("since"), 10, 64)
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	path := q.Get("path")
+	if path != "" {
+		path = normalizePath(path)
+	}
+	writeJSON(w, http.StatusOK, s.captured.list(path, since, limit))
+}
+
+func (s *server) clearRequests(w http.ResponseWriter, r *http.Request) {
+	s.captured.clear()
+	w.WriteHeader(http.StatusNoContent)
+}
 
-	// Failing endpoint — always returns 500
-	http.HandleFunc("/webhook/fail", func(w http.ResponseWriter, r *http.Request) {
-		count := requestCount.Add(1)
-		logRequest(r, count, 500)
+// stats shows request counts, in total and per path.
+func (s *server) stats(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	byPath := make(map[string]int64, len(s.pathCounts))
+	for p, n := range s.pathCounts {
+		byPath[p] = n
+	}
+	s.mu.Unlock()
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": "internal server error"})
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"total_requests": s.requestCount.Load(),
+		"by_path":        byPath,
 	})
+}
 
-	// Flaky endpoint — fails 70% of the time (good for circuit breaker testing)
-	http.HandleFunc("/webhook/flaky", func(w http.ResponseWriter, r *http.Request) {
-		count := requestCount.Add(1)
-
-		w.Header().Set("Content-Type", "application/json")
-		if rand.IntN(100) < 70 {
-			logRequest(r, count, 500)
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(map[string]string{"error": "random failure"})
-		} else {
-			logRequest(r, count, 200)
-			w.WriteHeader(http.StatusOK)
-			json.NewEncoder(w).Encode(map[string]string{"status": "received (flaky)"})
+func main() {
+	port := "9090"
+	if p := os.Getenv("PORT"); p != "" {
+		port = p
+	}
+	captureLimit := 1000
+	if v := os.Getenv("CAPTURE_LIMIT"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			captureLimit = n
 		}
-	})
+	}
 
-	// Stats endpoint — shows request count
-	http.HandleFunc("/stats", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]int64{"total_requests": requestCount.Load()})
-	})
+	s := newServer(captureLimit)
 
 	log.Printf("Mock endpoint server starting on :%s", port)
 	log.Printf("  POST /webhook/success  -> 200 OK")
 	log.Printf("  POST /webhook/slow     -> 200 OK (3s delay)")
 	log.Printf("  POST /webhook/fail     -> 500 Error")
 	log.Printf("  POST /webhook/flaky    -> 70%% fail, 30%% success")
+	log.Printf("  POST /configure        -> set a path's behavior")
+	log.Printf("  GET  /requests         -> captured requests (last %d)", captureLimit)
 	log.Printf("  GET  /stats            -> request count")
 
-	if err := http.ListenAndServe(":"+port, nil); err != nil {
+	if err := http.ListenAndServe(":"+port, s.handler()); err != nil {
 		log.Fatalf("server error: %v", err)
 	}
 }
 
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
 func logRequest(r *http.Request, count int64, status int) {
 	fmt.Printf("[#%d] %s %s -> %d | sig=%s event=%s id=%s attempt=%s\n",
 		count,
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func post(t *testing.T, url, body string) *http.Response {
+	t.Helper()
+	resp, err := http.Post(url, "application/json", bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatalf("POST %s: %v", url, err)
+	}
+	resp.Body.Close()
+	return resp
+}
+
+func TestServer_ConfigureAndCapture(t *testing.T) {
+	ts := httptest.NewServer(newServer(10).handler())
+	defer ts.Close()
+
+	if resp := post(t, ts.URL+"/orders", `{}`); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("unconfigured path: expected 404, got %d", resp.StatusCode)
+	}
+
+	resp := post(t, ts.URL+"/configure", `{"path":"orders","failure_rate":1,"failure_status":503,"latency":{"ms":20}}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("configure: expected 200, got %d", resp.StatusCode)
+	}
+
+	start := time.Now()
+	resp = post(t, ts.URL+"/orders", `{"order_id":42}`)
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected 503, got %d", resp.StatusCode)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("expected at least 20ms latency, took %v", elapsed)
+	}
+
+	res, err := http.Get(ts.URL + "/requests?path=/orders")
+	if err != nil {
+		t.Fatalf("GET /requests: %v", err)
+	}
+	defer res.Body.Close()
+	var captured []CapturedRequest
+	if err := json.NewDecoder(res.Body).Decode(&captured); err != nil {
+		t.Fatalf("decoding captured requests: %v", err)
+	}
+	if len(captured) != 1 {
+		t.Fatalf("expected 1 captured request, got %d", len(captured))
+	}
+	if captured[0].Status != 503 || string(captured[0].Body) != `{"order_id":42}` {
+		t.Errorf("unexpected capture: %+v", captured[0])
+	}
+}
+
+func TestServer_RateLimit(t *testing.T) {
+	ts := httptest.NewServer(newServer(10).handler())
+	defer ts.Close()
+
+	post(t, ts.URL+"/configure", `{"path":"/limited","rate_limit":{"per_second":2,"retry_after_seconds":7}}`)
+
+	var statuses []int
+	var retryAfter string
+	for i := 0; i < 3; i++ {
+		resp, err := http.Post(ts.URL+"/limited", "application/json", nil)
+		if err != nil {
+			t.Fatalf("POST: %v", err)
+		}
+		resp.Body.Close()
+		statuses = append(statuses, resp.StatusCode)
+		retryAfter = resp.Header.Get("Retry-After")
+	}
+	if statuses[0] != 200 || statuses[1] != 200 || statuses[2] != 429 {
+		t.Errorf("expected 200, 200, 429; got %v", statuses)
+	}
+	if retryAfter != "7" {
+		t.Errorf("expected Retry-After 7, got %q", retryAfter)
+	}
+}
+
+func TestServer_ResetRestoresPreset(t *testing.T) {
+	ts := httptest.NewServer(newServer(10).handler())
+	defer ts.Close()
+
+	post(t, ts.URL+"/configure", `{"path":"/webhook/success","status":500}`)
+	if resp := post(t, ts.URL+"/webhook/success", `{}`); resp.StatusCode != 500 {
+		t.Fatalf("expected override to return 500, got %d", resp.StatusCode)
+	}
+
+	req, _ := http.NewRequest(http.MethodDelete, ts.URL+"/configure?path=/webhook/success", nil)
+	if _, err := http.DefaultClient.Do(req); err != nil {
+		t.Fatalf("DELETE /configure: %v", err)
+	}
+	if resp := post(t, ts.URL+"/webhook/success", `{}`); resp.StatusCode != 200 {
+		t.Errorf("expected preset to return 200 after reset, got %d", resp.StatusCode)
+	}
+}
+
+func TestBehavior_Validate(t *testing.T) {
+	bad := []Behavior{
+		{Status: 42},
+		{FailureRate: 1.5},
+		{Latency: &Latency{Distribution: "poisson"}},
+		{Latency: &Latency{Distribution: "uniform", MinMs: 10, MaxMs: 5}},
+		{RateLimit: &RateLimit{PerSecond: -1}},
+	}
+	for _, b := range bad {
+		if err := b.validate(); err == nil {
+			t.Errorf("expected %+v to be invalid", b)
+		}
+	}
+}
+
+func TestCaptureLog_KeepsNewest(t *testing.T) {
+	c := newCaptureLog(3)
+	req := httptest.NewRequest(http.MethodPost, "/x", nil)
+	for i := 0; i < 5; i++ {
+		c.add(req, []byte("not json"), 200, 0)
+	}
+
+	got := c.list("", 0, 0)
+	if len(got) != 3 || got[0].Seq != 3 || got[2].Seq != 5 {
+		t.Fatalf("expected seqs 3..5, got %+v", got)
+	}
+	if got[0].RawBody != "not json" {
+		t.Errorf("expected raw body, got %q", got[0].RawBody)
+	}
+	if since := c.list("", 4, 0); len(since) != 1 || since[0].Seq != 5 {
+		t.Errorf("expected only seq 5 after since=4, got %+v", since)
+	}
+}
@@ -0,0 +1,124 @@
+// Package webhook contains helpers for receivers of webhook deliveries.
+package webhook
+
+import (
+	"compress/gzip"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// SignatureHeader carries the hex-encoded HMAC-SHA256 of the payload.
+const SignatureHeader = "X-Webhook-Signature"
+
+// DeliveryIDHeader carries a unique ID for each delivery attempt, unlike
+// X-Webhook-ID which stays the same across retries. Quote it when reporting
+// a problem with a delivery: it identifies the exact attempt.
+const DeliveryIDHeader = "X-Webhook-Delivery-ID"
+
+// EventVersionHeader carries the version of the event type's payload, the
+// one the subscriber is pinned to if it is older than the event's.
+const EventVersionHeader = "X-Webhook-Event-Version"
+
+// BatchSizeHeader is set on batched deliveries to the number of events in
+// the body, which is then a JSON array of BatchEvent signed as a whole.
+const BatchSizeHeader = "X-Webhook-Batch-Size"
+
+// BatchEvent is one event of a batched delivery.
+type BatchEvent struct {
+	// ID is the event ID, the same on every retry; deduplicate on it.
+	ID         string `json:"id"`
+	DeliveryID string `json:"delivery_id"`
+	EventType  string `json:"event_type"`
+	// Version is the version of the event type's payload.
+	Version int             `json:"version"`
+	Attempt int             `json:"attempt"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// IsBatch reports whether r is a batched delivery.
+func IsBatch(r *http.Request) bool {
+	return r.Header.Get(BatchSizeHeader) != ""
+}
+
+// ParseBatch decodes the payload returned by VerifyRequest for a batched
+// delivery.
+func ParseBatch(payload []byte) ([]BatchEvent, error) {
+	var events []BatchEvent
+	if err := json.Unmarshal(payload, &events); err != nil {
+		return nil, fmt.Errorf("webhook: decoding batch: %w", err)
+	}
+	return events, nil
+}
+
+// VerificationEvent is the X-Webhook-Event of the handshake sent before a
+// subscriber's endpoint URL is changed with verification. The endpoint
+// passes by echoing the challenge; AnswerVerification does that.
+const VerificationEvent = "webhook.verification"
+
+// IsVerification reports whether r is a verification handshake rather than
+// a delivery.
+func IsVerification(r *http.Request) bool {
+	return r.Header.Get("X-Webhook-Event") == VerificationEvent
+}
+
+// AnswerVerification responds to a verification handshake, given the
+// payload returned by VerifyRequest, by echoing its challenge.
+func AnswerVerification(w http.ResponseWriter, payload []byte) error {
+	var handshake struct {
+		Challenge string `json:"challenge"`
+	}
+	if err := json.Unmarshal(payload, &handshake); err != nil || handshake.Challenge == "" {
+		return errors.New("webhook: not a verification handshake")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(handshake)
+}
+
+// ErrInvalidSignature is returned when the signature does not match the payload.
+var ErrInvalidSignature = errors.New("webhook: invalid signature")
+
+// Verify reports whether signature is the HMAC-SHA256 of payload under secret.
+// The comparison is constant-time.
+func Verify(payload []byte, signature, secret string) bool {
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// VerifyRequest reads and verifies a webhook delivery, returning the payload.
+//
+// Large payloads may be sent with Content-Encoding: gzip. The signature is
+// always computed over the uncompressed JSON, so the body is decompressed
+// before verification and the uncompressed bytes are returned.
+func VerifyRequest(r *http.Request, secret string) ([]byte, error) {
+	var body io.Reader = r.Body
+	if strings.EqualFold(r.Header.Get("Content-Encoding"), "gzip") {
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return nil, fmt.Errorf("webhook: decompressing body: %w", err)
+		}
+		defer gz.Close()
+		body = gz
+	}
+
+	payload, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("webhook: reading body: %w", err)
+	}
+
+	if !Verify(payload, r.Header.Get(SignatureHeader), secret) {
+		return nil, ErrInvalidSignature
+	}
+	return payload, nil
+}
@@ -0,0 +1,101 @@
+package webhook
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+func sign(payload []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerify(t *testing.T) {
+	payload := []byte(`{"order_id":"abc"}`)
+	sig := sign(payload, "secret")
+
+	if !Verify(payload, sig, "secret") {
+		t.Error("valid signature should verify")
+	}
+	if Verify(payload, sig, "other-secret") {
+		t.Error("signature with wrong secret should not verify")
+	}
+	if Verify([]byte(`{"order_id":"xyz"}`), sig, "secret") {
+		t.Error("tampered payload should not verify")
+	}
+	if Verify(payload, "not-hex", "secret") {
+		t.Error("malformed signature should not verify")
+	}
+}
+
+func TestVerifyRequest_Plain(t *testing.T) {
+	payload := []byte(`{"test":true}`)
+	req := httptest.NewRequest("POST", "/webhook", bytes.NewReader(payload))
+	req.Header.Set(SignatureHeader, sign(payload, "secret"))
+
+	got, err := VerifyRequest(req, "secret")
+	if err != nil {
+		t.Fatalf("VerifyRequest failed: %v", err)
+	}
+	if !bytes.Equal(got, payload) {
+		t.Errorf("payload = %s, want %s", got, payload)
+	}
+}
+
+func TestVerifyRequest_Gzip(t *testing.T) {
+	payload := []byte(`{"items":["a","b","c"]}`)
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write(payload)
+	gz.Close()
+
+	req := httptest.NewRequest("POST", "/webhook", &buf)
+	req.Header.Set("Content-Encoding", "gzip")
+	// Signature is over the uncompressed bytes
+	req.Header.Set(SignatureHeader, sign(payload, "secret"))
+
+	got, err := VerifyRequest(req, "secret")
+	if err != nil {
+		t.Fatalf("VerifyRequest failed: %v", err)
+	}
+	if !bytes.Equal(got, payload) {
+		t.Errorf("payload = %s, want %s", got, payload)
+	}
+}
+
+func TestVerifyRequest_InvalidSignature(t *testing.T) {
+	req := httptest.NewRequest("POST", "/webhook", bytes.NewReader([]byte(`{}`)))
+	req.Header.Set(SignatureHeader, sign([]byte(`{"x":1}`), "secret"))
+
+	if _, err := VerifyRequest(req, "secret"); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature, got %v", err)
+	}
+}
+
+func TestParseBatch(t *testing.T) {
+	payload := []byte(`[{"id":"evt-1","delivery_id":"d-1","event_type":"order.created","attempt":1,"payload":{"n":1}},` +
+		`{"id":"evt-2","delivery_id":"d-2","event_type":"order.created","attempt":2,"payload":{"n":2}}]`)
+	req := httptest.NewRequest("POST", "/webhook", bytes.NewReader(payload))
+	req.Header.Set(SignatureHeader, sign(payload, "secret"))
+	req.Header.Set(BatchSizeHeader, "2")
+
+	body, err := VerifyRequest(req, "secret")
+	if err != nil || !IsBatch(req) {
+		t.Fatalf("VerifyRequest = %v, IsBatch = %t", err, IsBatch(req))
+	}
+	events, err := ParseBatch(body)
+	if err != nil {
+		t.Fatalf("ParseBatch failed: %v", err)
+	}
+	if len(events) != 2 || events[1].ID != "evt-2" || events[1].Attempt != 2 || string(events[1].Payload) != `{"n":2}` {
+		t.Errorf("unexpected events %+v", events)
+	}
+}
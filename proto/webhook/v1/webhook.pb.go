@@ -0,0 +1,1067 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: webhook/v1/webhook.proto
+
+package webhookv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type PublishEventRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	EventType string                 `protobuf:"bytes,1,opt,name=event_type,json=eventType,proto3" json:"event_type,omitempty"`
+	// JSON-encoded event payload.
+	Payload []byte `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"`
+	Source  string `protobuf:"bytes,3,opt,name=source,proto3" json:"source,omitempty"`
+	// Restricts fan-out to these subscribers, which must subscribe to the
+	// event type.
+	SubscriberIds []string `protobuf:"bytes,4,rep,name=subscriber_ids,json=subscriberIds,proto3" json:"subscriber_ids,omitempty"`
+	// Version of the event type's payload. Unset means 1.
+	Version       int32 `protobuf:"varint,5,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PublishEventRequest) Reset() {
+	*x = PublishEventRequest{}
+	mi := &file_webhook_v1_webhook_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PublishEventRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PublishEventRequest) ProtoMessage() {}
+
+func (x *PublishEventRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_webhook_v1_webhook_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PublishEventRequest.ProtoReflect.Descriptor instead.
+func (*PublishEventRequest) Descriptor() ([]byte, []int) {
+	return file_webhook_v1_webhook_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *PublishEventRequest) GetEventType() string {
+	if x != nil {
+		return x.EventType
+	}
+	return ""
+}
+
+func (x *PublishEventRequest) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *PublishEventRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *PublishEventRequest) GetSubscriberIds() []string {
+	if x != nil {
+		return x.SubscriberIds
+	}
+	return nil
+}
+
+func (x *PublishEventRequest) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type PublishEventResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	EventId          string                 `protobuf:"bytes,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	EventType        string                 `protobuf:"bytes,2,opt,name=event_type,json=eventType,proto3" json:"event_type,omitempty"`
+	DeliveriesQueued int32                  `protobuf:"varint,3,opt,name=deliveries_queued,json=deliveriesQueued,proto3" json:"deliveries_queued,omitempty"`
+	// Set when inline fan-out failed and was left to the outbox relay.
+	FanoutPending bool `protobuf:"varint,4,opt,name=fanout_pending,json=fanoutPending,proto3" json:"fanout_pending,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PublishEventResponse) Reset() {
+	*x = PublishEventResponse{}
+	mi := &file_webhook_v1_webhook_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PublishEventResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PublishEventResponse) ProtoMessage() {}
+
+func (x *PublishEventResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_webhook_v1_webhook_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PublishEventResponse.ProtoReflect.Descriptor instead.
+func (*PublishEventResponse) Descriptor() ([]byte, []int) {
+	return file_webhook_v1_webhook_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PublishEventResponse) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
+func (x *PublishEventResponse) GetEventType() string {
+	if x != nil {
+		return x.EventType
+	}
+	return ""
+}
+
+func (x *PublishEventResponse) GetDeliveriesQueued() int32 {
+	if x != nil {
+		return x.DeliveriesQueued
+	}
+	return 0
+}
+
+func (x *PublishEventResponse) GetFanoutPending() bool {
+	if x != nil {
+		return x.FanoutPending
+	}
+	return false
+}
+
+type PublishEventBatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Events        []*PublishEventRequest `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PublishEventBatchRequest) Reset() {
+	*x = PublishEventBatchRequest{}
+	mi := &file_webhook_v1_webhook_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PublishEventBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PublishEventBatchRequest) ProtoMessage() {}
+
+func (x *PublishEventBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_webhook_v1_webhook_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PublishEventBatchRequest.ProtoReflect.Descriptor instead.
+func (*PublishEventBatchRequest) Descriptor() ([]byte, []int) {
+	return file_webhook_v1_webhook_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PublishEventBatchRequest) GetEvents() []*PublishEventRequest {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+type PublishEventBatchResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// One result per request event, in order.
+	Results       []*PublishEventResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PublishEventBatchResponse) Reset() {
+	*x = PublishEventBatchResponse{}
+	mi := &file_webhook_v1_webhook_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PublishEventBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PublishEventBatchResponse) ProtoMessage() {}
+
+func (x *PublishEventBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_webhook_v1_webhook_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PublishEventBatchResponse.ProtoReflect.Descriptor instead.
+func (*PublishEventBatchResponse) Descriptor() ([]byte, []int) {
+	return file_webhook_v1_webhook_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PublishEventBatchResponse) GetResults() []*PublishEventResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type PublishEventResult struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Event *PublishEventResponse  `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	// Set instead of event when this event could not be stored.
+	Error         string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PublishEventResult) Reset() {
+	*x = PublishEventResult{}
+	mi := &file_webhook_v1_webhook_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PublishEventResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PublishEventResult) ProtoMessage() {}
+
+func (x *PublishEventResult) ProtoReflect() protoreflect.Message {
+	mi := &file_webhook_v1_webhook_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PublishEventResult.ProtoReflect.Descriptor instead.
+func (*PublishEventResult) Descriptor() ([]byte, []int) {
+	return file_webhook_v1_webhook_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *PublishEventResult) GetEvent() *PublishEventResponse {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *PublishEventResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type Subscriber struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	Id                    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name                  string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	EndpointUrl           string                 `protobuf:"bytes,3,opt,name=endpoint_url,json=endpointUrl,proto3" json:"endpoint_url,omitempty"`
+	IsActive              bool                   `protobuf:"varint,4,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
+	RateLimitPerSecond    int32                  `protobuf:"varint,5,opt,name=rate_limit_per_second,json=rateLimitPerSecond,proto3" json:"rate_limit_per_second,omitempty"`
+	CompressPayloads      bool                   `protobuf:"varint,6,opt,name=compress_payloads,json=compressPayloads,proto3" json:"compress_payloads,omitempty"`
+	EventTypes            []string               `protobuf:"bytes,7,rep,name=event_types,json=eventTypes,proto3" json:"event_types,omitempty"`
+	CreatedAt             *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt             *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	DiscardResponseBodies bool                   `protobuf:"varint,10,opt,name=discard_response_bodies,json=discardResponseBodies,proto3" json:"discard_response_bodies,omitempty"`
+	// Goes up with every change to the subscriber.
+	Version       int32 `protobuf:"varint,11,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Subscriber) Reset() {
+	*x = Subscriber{}
+	mi := &file_webhook_v1_webhook_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Subscriber) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Subscriber) ProtoMessage() {}
+
+func (x *Subscriber) ProtoReflect() protoreflect.Message {
+	mi := &file_webhook_v1_webhook_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Subscriber.ProtoReflect.Descriptor instead.
+func (*Subscriber) Descriptor() ([]byte, []int) {
+	return file_webhook_v1_webhook_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Subscriber) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Subscriber) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Subscriber) GetEndpointUrl() string {
+	if x != nil {
+		return x.EndpointUrl
+	}
+	return ""
+}
+
+func (x *Subscriber) GetIsActive() bool {
+	if x != nil {
+		return x.IsActive
+	}
+	return false
+}
+
+func (x *Subscriber) GetRateLimitPerSecond() int32 {
+	if x != nil {
+		return x.RateLimitPerSecond
+	}
+	return 0
+}
+
+func (x *Subscriber) GetCompressPayloads() bool {
+	if x != nil {
+		return x.CompressPayloads
+	}
+	return false
+}
+
+func (x *Subscriber) GetEventTypes() []string {
+	if x != nil {
+		return x.EventTypes
+	}
+	return nil
+}
+
+func (x *Subscriber) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Subscriber) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Subscriber) GetDiscardResponseBodies() bool {
+	if x != nil {
+		return x.DiscardResponseBodies
+	}
+	return false
+}
+
+func (x *Subscriber) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type CreateSubscriberRequest struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	Name                  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	EndpointUrl           string                 `protobuf:"bytes,2,opt,name=endpoint_url,json=endpointUrl,proto3" json:"endpoint_url,omitempty"`
+	EventTypes            []string               `protobuf:"bytes,3,rep,name=event_types,json=eventTypes,proto3" json:"event_types,omitempty"`
+	CompressPayloads      bool                   `protobuf:"varint,4,opt,name=compress_payloads,json=compressPayloads,proto3" json:"compress_payloads,omitempty"`
+	DiscardResponseBodies bool                   `protobuf:"varint,5,opt,name=discard_response_bodies,json=discardResponseBodies,proto3" json:"discard_response_bodies,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *CreateSubscriberRequest) Reset() {
+	*x = CreateSubscriberRequest{}
+	mi := &file_webhook_v1_webhook_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateSubscriberRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateSubscriberRequest) ProtoMessage() {}
+
+func (x *CreateSubscriberRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_webhook_v1_webhook_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateSubscriberRequest.ProtoReflect.Descriptor instead.
+func (*CreateSubscriberRequest) Descriptor() ([]byte, []int) {
+	return file_webhook_v1_webhook_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CreateSubscriberRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateSubscriberRequest) GetEndpointUrl() string {
+	if x != nil {
+		return x.EndpointUrl
+	}
+	return ""
+}
+
+func (x *CreateSubscriberRequest) GetEventTypes() []string {
+	if x != nil {
+		return x.EventTypes
+	}
+	return nil
+}
+
+func (x *CreateSubscriberRequest) GetCompressPayloads() bool {
+	if x != nil {
+		return x.CompressPayloads
+	}
+	return false
+}
+
+func (x *CreateSubscriberRequest) GetDiscardResponseBodies() bool {
+	if x != nil {
+		return x.DiscardResponseBodies
+	}
+	return false
+}
+
+type CreateSubscriberResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name  string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// Signing secret. Only returned here.
+	SecretKey     string `protobuf:"bytes,3,opt,name=secret_key,json=secretKey,proto3" json:"secret_key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateSubscriberResponse) Reset() {
+	*x = CreateSubscriberResponse{}
+	mi := &file_webhook_v1_webhook_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateSubscriberResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateSubscriberResponse) ProtoMessage() {}
+
+func (x *CreateSubscriberResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_webhook_v1_webhook_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateSubscriberResponse.ProtoReflect.Descriptor instead.
+func (*CreateSubscriberResponse) Descriptor() ([]byte, []int) {
+	return file_webhook_v1_webhook_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CreateSubscriberResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *CreateSubscriberResponse) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateSubscriberResponse) GetSecretKey() string {
+	if x != nil {
+		return x.SecretKey
+	}
+	return ""
+}
+
+type GetSubscriberRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSubscriberRequest) Reset() {
+	*x = GetSubscriberRequest{}
+	mi := &file_webhook_v1_webhook_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSubscriberRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSubscriberRequest) ProtoMessage() {}
+
+func (x *GetSubscriberRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_webhook_v1_webhook_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSubscriberRequest.ProtoReflect.Descriptor instead.
+func (*GetSubscriberRequest) Descriptor() ([]byte, []int) {
+	return file_webhook_v1_webhook_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetSubscriberRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetSubscriberResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Subscriber    *Subscriber            `protobuf:"bytes,1,opt,name=subscriber,proto3" json:"subscriber,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSubscriberResponse) Reset() {
+	*x = GetSubscriberResponse{}
+	mi := &file_webhook_v1_webhook_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSubscriberResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSubscriberResponse) ProtoMessage() {}
+
+func (x *GetSubscriberResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_webhook_v1_webhook_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSubscriberResponse.ProtoReflect.Descriptor instead.
+func (*GetSubscriberResponse) Descriptor() ([]byte, []int) {
+	return file_webhook_v1_webhook_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetSubscriberResponse) GetSubscriber() *Subscriber {
+	if x != nil {
+		return x.Subscriber
+	}
+	return nil
+}
+
+type ListSubscribersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSubscribersRequest) Reset() {
+	*x = ListSubscribersRequest{}
+	mi := &file_webhook_v1_webhook_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSubscribersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSubscribersRequest) ProtoMessage() {}
+
+func (x *ListSubscribersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_webhook_v1_webhook_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSubscribersRequest.ProtoReflect.Descriptor instead.
+func (*ListSubscribersRequest) Descriptor() ([]byte, []int) {
+	return file_webhook_v1_webhook_proto_rawDescGZIP(), []int{10}
+}
+
+type ListSubscribersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Subscribers   []*Subscriber          `protobuf:"bytes,1,rep,name=subscribers,proto3" json:"subscribers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSubscribersResponse) Reset() {
+	*x = ListSubscribersResponse{}
+	mi := &file_webhook_v1_webhook_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSubscribersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSubscribersResponse) ProtoMessage() {}
+
+func (x *ListSubscribersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_webhook_v1_webhook_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSubscribersResponse.ProtoReflect.Descriptor instead.
+func (*ListSubscribersResponse) Descriptor() ([]byte, []int) {
+	return file_webhook_v1_webhook_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ListSubscribersResponse) GetSubscribers() []*Subscriber {
+	if x != nil {
+		return x.Subscribers
+	}
+	return nil
+}
+
+type UpdateSubscriberRequest struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	Id                    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name                  *string                `protobuf:"bytes,2,opt,name=name,proto3,oneof" json:"name,omitempty"`
+	EndpointUrl           *string                `protobuf:"bytes,3,opt,name=endpoint_url,json=endpointUrl,proto3,oneof" json:"endpoint_url,omitempty"`
+	IsActive              *bool                  `protobuf:"varint,4,opt,name=is_active,json=isActive,proto3,oneof" json:"is_active,omitempty"`
+	RateLimitPerSecond    *int32                 `protobuf:"varint,5,opt,name=rate_limit_per_second,json=rateLimitPerSecond,proto3,oneof" json:"rate_limit_per_second,omitempty"`
+	CompressPayloads      *bool                  `protobuf:"varint,6,opt,name=compress_payloads,json=compressPayloads,proto3,oneof" json:"compress_payloads,omitempty"`
+	DiscardResponseBodies *bool                  `protobuf:"varint,7,opt,name=discard_response_bodies,json=discardResponseBodies,proto3,oneof" json:"discard_response_bodies,omitempty"`
+	// Only update the subscriber if it is still at this version; fails with
+	// ABORTED otherwise.
+	Version       *int32 `protobuf:"varint,8,opt,name=version,proto3,oneof" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateSubscriberRequest) Reset() {
+	*x = UpdateSubscriberRequest{}
+	mi := &file_webhook_v1_webhook_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateSubscriberRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateSubscriberRequest) ProtoMessage() {}
+
+func (x *UpdateSubscriberRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_webhook_v1_webhook_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateSubscriberRequest.ProtoReflect.Descriptor instead.
+func (*UpdateSubscriberRequest) Descriptor() ([]byte, []int) {
+	return file_webhook_v1_webhook_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *UpdateSubscriberRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UpdateSubscriberRequest) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *UpdateSubscriberRequest) GetEndpointUrl() string {
+	if x != nil && x.EndpointUrl != nil {
+		return *x.EndpointUrl
+	}
+	return ""
+}
+
+func (x *UpdateSubscriberRequest) GetIsActive() bool {
+	if x != nil && x.IsActive != nil {
+		return *x.IsActive
+	}
+	return false
+}
+
+func (x *UpdateSubscriberRequest) GetRateLimitPerSecond() int32 {
+	if x != nil && x.RateLimitPerSecond != nil {
+		return *x.RateLimitPerSecond
+	}
+	return 0
+}
+
+func (x *UpdateSubscriberRequest) GetCompressPayloads() bool {
+	if x != nil && x.CompressPayloads != nil {
+		return *x.CompressPayloads
+	}
+	return false
+}
+
+func (x *UpdateSubscriberRequest) GetDiscardResponseBodies() bool {
+	if x != nil && x.DiscardResponseBodies != nil {
+		return *x.DiscardResponseBodies
+	}
+	return false
+}
+
+func (x *UpdateSubscriberRequest) GetVersion() int32 {
+	if x != nil && x.Version != nil {
+		return *x.Version
+	}
+	return 0
+}
+
+type UpdateSubscriberResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Subscriber    *Subscriber            `protobuf:"bytes,1,opt,name=subscriber,proto3" json:"subscriber,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateSubscriberResponse) Reset() {
+	*x = UpdateSubscriberResponse{}
+	mi := &file_webhook_v1_webhook_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateSubscriberResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateSubscriberResponse) ProtoMessage() {}
+
+func (x *UpdateSubscriberResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_webhook_v1_webhook_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateSubscriberResponse.ProtoReflect.Descriptor instead.
+func (*UpdateSubscriberResponse) Descriptor() ([]byte, []int) {
+	return file_webhook_v1_webhook_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *UpdateSubscriberResponse) GetSubscriber() *Subscriber {
+	if x != nil {
+		return x.Subscriber
+	}
+	return nil
+}
+
+var File_webhook_v1_webhook_proto protoreflect.FileDescriptor
+
+const file_webhook_v1_webhook_proto_rawDesc = "" +
+	"\n" +
+	"\x18webhook/v1/webhook.proto\x12\n" +
+	"webhook.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xa7\x01\n" +
+	"\x13PublishEventRequest\x12\x1d\n" +
+	"\n" +
+	"event_type\x18\x01 \x01(\tR\teventType\x12\x18\n" +
+	"\apayload\x18\x02 \x01(\fR\apayload\x12\x16\n" +
+	"\x06source\x18\x03 \x01(\tR\x06source\x12%\n" +
+	"\x0esubscriber_ids\x18\x04 \x03(\tR\rsubscriberIds\x12\x18\n" +
+	"\aversion\x18\x05 \x01(\x05R\aversion\"\xa4\x01\n" +
+	"\x14PublishEventResponse\x12\x19\n" +
+	"\bevent_id\x18\x01 \x01(\tR\aeventId\x12\x1d\n" +
+	"\n" +
+	"event_type\x18\x02 \x01(\tR\teventType\x12+\n" +
+	"\x11deliveries_queued\x18\x03 \x01(\x05R\x10deliveriesQueued\x12%\n" +
+	"\x0efanout_pending\x18\x04 \x01(\bR\rfanoutPending\"S\n" +
+	"\x18PublishEventBatchRequest\x127\n" +
+	"\x06events\x18\x01 \x03(\v2\x1f.webhook.v1.PublishEventRequestR\x06events\"U\n" +
+	"\x19PublishEventBatchResponse\x128\n" +
+	"\aresults\x18\x01 \x03(\v2\x1e.webhook.v1.PublishEventResultR\aresults\"b\n" +
+	"\x12PublishEventResult\x126\n" +
+	"\x05event\x18\x01 \x01(\v2 .webhook.v1.PublishEventResponseR\x05event\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\xb9\x03\n" +
+	"\n" +
+	"Subscriber\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12!\n" +
+	"\fendpoint_url\x18\x03 \x01(\tR\vendpointUrl\x12\x1b\n" +
+	"\tis_active\x18\x04 \x01(\bR\bisActive\x121\n" +
+	"\x15rate_limit_per_second\x18\x05 \x01(\x05R\x12rateLimitPerSecond\x12+\n" +
+	"\x11compress_payloads\x18\x06 \x01(\bR\x10compressPayloads\x12\x1f\n" +
+	"\vevent_types\x18\a \x03(\tR\n" +
+	"eventTypes\x129\n" +
+	"\n" +
+	"created_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x126\n" +
+	"\x17discard_response_bodies\x18\n" +
+	" \x01(\bR\x15discardResponseBodies\x12\x18\n" +
+	"\aversion\x18\v \x01(\x05R\aversion\"\xd6\x01\n" +
+	"\x17CreateSubscriberRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12!\n" +
+	"\fendpoint_url\x18\x02 \x01(\tR\vendpointUrl\x12\x1f\n" +
+	"\vevent_types\x18\x03 \x03(\tR\n" +
+	"eventTypes\x12+\n" +
+	"\x11compress_payloads\x18\x04 \x01(\bR\x10compressPayloads\x126\n" +
+	"\x17discard_response_bodies\x18\x05 \x01(\bR\x15discardResponseBodies\"]\n" +
+	"\x18CreateSubscriberResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1d\n" +
+	"\n" +
+	"secret_key\x18\x03 \x01(\tR\tsecretKey\"&\n" +
+	"\x14GetSubscriberRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"O\n" +
+	"\x15GetSubscriberResponse\x126\n" +
+	"\n" +
+	"subscriber\x18\x01 \x01(\v2\x16.webhook.v1.SubscriberR\n" +
+	"subscriber\"\x18\n" +
+	"\x16ListSubscribersRequest\"S\n" +
+	"\x17ListSubscribersResponse\x128\n" +
+	"\vsubscribers\x18\x01 \x03(\v2\x16.webhook.v1.SubscriberR\vsubscribers\"\xd2\x03\n" +
+	"\x17UpdateSubscriberRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
+	"\x04name\x18\x02 \x01(\tH\x00R\x04name\x88\x01\x01\x12&\n" +
+	"\fendpoint_url\x18\x03 \x01(\tH\x01R\vendpointUrl\x88\x01\x01\x12 \n" +
+	"\tis_active\x18\x04 \x01(\bH\x02R\bisActive\x88\x01\x01\x126\n" +
+	"\x15rate_limit_per_second\x18\x05 \x01(\x05H\x03R\x12rateLimitPerSecond\x88\x01\x01\x120\n" +
+	"\x11compress_payloads\x18\x06 \x01(\bH\x04R\x10compressPayloads\x88\x01\x01\x12;\n" +
+	"\x17discard_response_bodies\x18\a \x01(\bH\x05R\x15discardResponseBodies\x88\x01\x01\x12\x1d\n" +
+	"\aversion\x18\b \x01(\x05H\x06R\aversion\x88\x01\x01B\a\n" +
+	"\x05_nameB\x0f\n" +
+	"\r_endpoint_urlB\f\n" +
+	"\n" +
+	"_is_activeB\x18\n" +
+	"\x16_rate_limit_per_secondB\x14\n" +
+	"\x12_compress_payloadsB\x1a\n" +
+	"\x18_discard_response_bodiesB\n" +
+	"\n" +
+	"\b_version\"R\n" +
+	"\x18UpdateSubscriberResponse\x126\n" +
+	"\n" +
+	"subscriber\x18\x01 \x01(\v2\x16.webhook.v1.SubscriberR\n" +
+	"subscriber2\xb5\x04\n" +
+	"\x0eWebhookService\x12Q\n" +
+	"\fPublishEvent\x12\x1f.webhook.v1.PublishEventRequest\x1a .webhook.v1.PublishEventResponse\x12`\n" +
+	"\x11PublishEventBatch\x12$.webhook.v1.PublishEventBatchRequest\x1a%.webhook.v1.PublishEventBatchResponse\x12]\n" +
+	"\x10CreateSubscriber\x12#.webhook.v1.CreateSubscriberRequest\x1a$.webhook.v1.CreateSubscriberResponse\x12T\n" +
+	"\rGetSubscriber\x12 .webhook.v1.GetSubscriberRequest\x1a!.webhook.v1.GetSubscriberResponse\x12Z\n" +
+	"\x0fListSubscribers\x12\".webhook.v1.ListSubscribersRequest\x1a#.webhook.v1.ListSubscribersResponse\x12]\n" +
+	"\x10UpdateSubscriber\x12#.webhook.v1.UpdateSubscriberRequest\x1a$.webhook.v1.UpdateSubscriberResponseBIZGgithub.com/Priya8975/webhook-delivery-system/proto/webhook/v1;webhookv1b\x06proto3"
+
+var (
+	file_webhook_v1_webhook_proto_rawDescOnce sync.Once
+	file_webhook_v1_webhook_proto_rawDescData []byte
+)
+
+func file_webhook_v1_webhook_proto_rawDescGZIP() []byte {
+	file_webhook_v1_webhook_proto_rawDescOnce.Do(func() {
+		file_webhook_v1_webhook_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_webhook_v1_webhook_proto_rawDesc), len(file_webhook_v1_webhook_proto_rawDesc)))
+	})
+	return file_webhook_v1_webhook_proto_rawDescData
+}
+
+var file_webhook_v1_webhook_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_webhook_v1_webhook_proto_goTypes = []any{
+	(*PublishEventRequest)(nil),       // 0: webhook.v1.PublishEventRequest
+	(*PublishEventResponse)(nil),      // 1: webhook.v1.PublishEventResponse
+	(*PublishEventBatchRequest)(nil),  // 2: webhook.v1.PublishEventBatchRequest
+	(*PublishEventBatchResponse)(nil), // 3: webhook.v1.PublishEventBatchResponse
+	(*PublishEventResult)(nil),        // 4: webhook.v1.PublishEventResult
+	(*Subscriber)(nil),                // 5: webhook.v1.Subscriber
+	(*CreateSubscriberRequest)(nil),   // 6: webhook.v1.CreateSubscriberRequest
+	(*CreateSubscriberResponse)(nil),  // 7: webhook.v1.CreateSubscriberResponse
+	(*GetSubscriberRequest)(nil),      // 8: webhook.v1.GetSubscriberRequest
+	(*GetSubscriberResponse)(nil),     // 9: webhook.v1.GetSubscriberResponse
+	(*ListSubscribersRequest)(nil),    // 10: webhook.v1.ListSubscribersRequest
+	(*ListSubscribersResponse)(nil),   // 11: webhook.v1.ListSubscribersResponse
+	(*UpdateSubscriberRequest)(nil),   // 12: webhook.v1.UpdateSubscriberRequest
+	(*UpdateSubscriberResponse)(nil),  // 13: webhook.v1.UpdateSubscriberResponse
+	(*timestamppb.Timestamp)(nil),     // 14: google.protobuf.Timestamp
+}
+var file_webhook_v1_webhook_proto_depIdxs = []int32{
+	0,  // 0: webhook.v1.PublishEventBatchRequest.events:type_name -> webhook.v1.PublishEventRequest
+	4,  // 1: webhook.v1.PublishEventBatchResponse.results:type_name -> webhook.v1.PublishEventResult
+	1,  // 2: webhook.v1.PublishEventResult.event:type_name -> webhook.v1.PublishEventResponse
+	14, // 3: webhook.v1.Subscriber.created_at:type_name -> google.protobuf.Timestamp
+	14, // 4: webhook.v1.Subscriber.updated_at:type_name -> google.protobuf.Timestamp
+	5,  // 5: webhook.v1.GetSubscriberResponse.subscriber:type_name -> webhook.v1.Subscriber
+	5,  // 6: webhook.v1.ListSubscribersResponse.subscribers:type_name -> webhook.v1.Subscriber
+	5,  // 7: webhook.v1.UpdateSubscriberResponse.subscriber:type_name -> webhook.v1.Subscriber
+	0,  // 8: webhook.v1.WebhookService.PublishEvent:input_type -> webhook.v1.PublishEventRequest
+	2,  // 9: webhook.v1.WebhookService.PublishEventBatch:input_type -> webhook.v1.PublishEventBatchRequest
+	6,  // 10: webhook.v1.WebhookService.CreateSubscriber:input_type -> webhook.v1.CreateSubscriberRequest
+	8,  // 11: webhook.v1.WebhookService.GetSubscriber:input_type -> webhook.v1.GetSubscriberRequest
+	10, // 12: webhook.v1.WebhookService.ListSubscribers:input_type -> webhook.v1.ListSubscribersRequest
+	12, // 13: webhook.v1.WebhookService.UpdateSubscriber:input_type -> webhook.v1.UpdateSubscriberRequest
+	1,  // 14: webhook.v1.WebhookService.PublishEvent:output_type -> webhook.v1.PublishEventResponse
+	3,  // 15: webhook.v1.WebhookService.PublishEventBatch:output_type -> webhook.v1.PublishEventBatchResponse
+	7,  // 16: webhook.v1.WebhookService.CreateSubscriber:output_type -> webhook.v1.CreateSubscriberResponse
+	9,  // 17: webhook.v1.WebhookService.GetSubscriber:output_type -> webhook.v1.GetSubscriberResponse
+	11, // 18: webhook.v1.WebhookService.ListSubscribers:output_type -> webhook.v1.ListSubscribersResponse
+	13, // 19: webhook.v1.WebhookService.UpdateSubscriber:output_type -> webhook.v1.UpdateSubscriberResponse
+	14, // [14:20] is the sub-list for method output_type
+	8,  // [8:14] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
+}
+
+func init() { file_webhook_v1_webhook_proto_init() }
+func file_webhook_v1_webhook_proto_init() {
+	if File_webhook_v1_webhook_proto != nil {
+		return
+	}
+	file_webhook_v1_webhook_proto_msgTypes[12].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_webhook_v1_webhook_proto_rawDesc), len(file_webhook_v1_webhook_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   14,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_webhook_v1_webhook_proto_goTypes,
+		DependencyIndexes: file_webhook_v1_webhook_proto_depIdxs,
+		MessageInfos:      file_webhook_v1_webhook_proto_msgTypes,
+	}.Build()
+	File_webhook_v1_webhook_proto = out.File
+	file_webhook_v1_webhook_proto_goTypes = nil
+	file_webhook_v1_webhook_proto_depIdxs = nil
+}